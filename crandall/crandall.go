@@ -0,0 +1,319 @@
+// Package crandall provides arithmetic modulo Crandall primes p = 2^k - c,
+// for a small c, in unsaturated limbs: n limbs of r < 64 bits each, such as
+// the five 51-bit limbs common for 2^255 - 19.
+//
+// Saturated 64-bit limbs must propagate a carry after every addition and
+// inside every multiplication. With r bits of each word in use, the spare
+// bits absorb those carries instead: Add is a plain limb-wise sum, the
+// products of a multiplication accumulate in 128-bit sums without any carry
+// between limbs, and one CarryPropagate pass at the end brings every limb
+// back below 2^(r+1). Since 2^(n·r) ≡ f = c·2^(n·r-k) mod p, a product term
+// that lands beyond the top limb wraps around to the bottom times f, which
+// is Crandall's reduction.
+//
+// Elements are slices of Limbs() uint64 values, with limbs below 2^(r+1)
+// ("tight") after Mul, Sub, CarryPropagate and Reduce, and below 2^(r+2)
+// ("loose") after Add of two tight elements. Mul, Sub and Neg accept loose
+// limbs, so an Add may feed a multiplication without carrying first, and
+// CarryPropagate and Reduce accept anything below 2^(r+4). Only Reduce gives
+// the canonical representative, with limbs below 2^r and value below p.
+//
+// None of the operations branches on, or indexes memory by, limb values.
+package crandall
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+)
+
+// ErrInvalidParameters is returned by New for parameters whose sums of
+// products would overflow the 128-bit accumulators or whose carries would
+// not settle in one pass.
+var ErrInvalidParameters = errors.New("crandall: invalid field parameters")
+
+// maxLimbs is the most limbs New accepts, enough for 9·58-bit limbs of
+// 2^521 - 1 or 12·43-bit ones.
+const maxLimbs = 12
+
+// Field is arithmetic modulo p = 2^k - c in n limbs of radix 2^r. It is
+// immutable and safe for concurrent use.
+type Field struct {
+	k, r uint
+	n    int
+	c    uint64
+	f    uint64 // c·2^(n·r-k) ≡ 2^(n·r) mod p, the wraparound factor
+	mask uint64 // 2^r - 1
+	top  uint   // bits of p in the top limb, k - (n-1)·r
+	p    *big.Int
+	// q is 8·(2^(n·r) - f), a multiple of p whose limbs are all at least
+	// 2^(r+2), so that x + q - y has no negative limb for loose y
+	q [maxLimbs]uint64
+}
+
+// New returns the Field of p = 2^k - c in the given number of limbs, of
+// r = ⌈k/limbs⌉ bits each: New(255, 19, 5) is the 5×51-bit field of
+// Curve25519, and New(521, 1, 9) the 9×58-bit field of P-521.
+//
+// It returns an error wrapping ErrInvalidParameters unless there are 2 to
+// 12 limbs, p > 1, r ≤ 60, f·2^(r+2) < 2^64, the sum of n loose products
+// times f fits in 127 bits, and 32·n·f² < 2^r, which is what lets the
+// carries of Mul settle in one pass. The last condition rules out too many
+// limbs for a large c: 2^255 - 19 works in 5 limbs but not in 10.
+func New(k uint, c uint64, limbs int) (*Field, error) {
+	if limbs < 2 || limbs > maxLimbs {
+		return nil, fmt.Errorf("%w: %d limbs, want 2 to %d", ErrInvalidParameters, limbs, maxLimbs)
+	}
+	n := uint(limbs)
+	r := (k + n - 1) / n
+	if k < 2 || c == 0 || r > 60 {
+		return nil, fmt.Errorf("%w: 2^%d - %d in %d limbs", ErrInvalidParameters, k, c, limbs)
+	}
+	p := new(big.Int).Lsh(big.NewInt(1), k)
+	p.Sub(p, new(big.Int).SetUint64(c))
+	if p.Cmp(big.NewInt(1)) <= 0 {
+		return nil, fmt.Errorf("%w: 2^%d - %d is not above 1", ErrInvalidParameters, k, c)
+	}
+	shift := n*r - k
+	if uint(bits.Len64(c))+shift+r+2 > 64 {
+		return nil, fmt.Errorf("%w: wraparound factor %d·2^%d too large for %d-bit limbs", ErrInvalidParameters, c, shift, r)
+	}
+	f := c << shift
+	nf := new(big.Int).Mul(big.NewInt(int64(n)), new(big.Int).SetUint64(f))
+	bound := new(big.Int).Mul(nf, new(big.Int).SetUint64(f))
+	bound.Lsh(bound, 5)
+	if uint(nf.BitLen())+2*r+4 > 127 || uint(bound.BitLen()) > r {
+		return nil, fmt.Errorf("%w: 2^%d - %d needs larger or fewer limbs than %d×%d bits", ErrInvalidParameters, k, c, limbs, r)
+	}
+
+	fd := &Field{
+		k: k, r: r, n: limbs, c: c, f: f,
+		mask: 1<<r - 1,
+		top:  k - (n-1)*r,
+		p:    p,
+	}
+	fd.q[0] = 8 * (1<<r - f)
+	for i := 1; i < limbs; i++ {
+		fd.q[i] = 8 * (1<<r - 1)
+	}
+	return fd, nil
+}
+
+// Modulus returns p.
+func (fd *Field) Modulus() *big.Int { return new(big.Int).Set(fd.p) }
+
+// Limbs returns n, the length of an element.
+func (fd *Field) Limbs() int { return fd.n }
+
+// Radix returns r, the bits of each limb.
+func (fd *Field) Radix() uint { return fd.r }
+
+// Mul sets z = x·y mod p with tight limbs, for loose x and y. z may be x,
+// y or both.
+//
+// The products x_i·y_j with i+j ≥ n are taken against f·y_j, which
+// folds their wraparound in without a separate pass, and every column
+// accumulates in 128 bits before the single carry pass.
+func (fd *Field) Mul(z, x, y []uint64) {
+	fd.checkLen("Mul", z, x, y)
+	n := fd.n
+	var yf [maxLimbs]uint64
+	for j := range n {
+		yf[j] = y[j] * fd.f
+	}
+	var acc [maxLimbs]uint128
+	for i := range n {
+		for j := range n {
+			if k := i + j; k < n {
+				acc[k] = acc[k].addMul(x[i], y[j])
+			} else {
+				acc[k-n] = acc[k-n].addMul(x[i], yf[j])
+			}
+		}
+	}
+	fd.carryWide(z, &acc)
+}
+
+// carryWide carries the 128-bit columns into r-bit limbs in z, one pass
+// from the bottom, then wraps the carry out of the top limb around times f
+// and carries once more into limb 1. New's bounds keep that last carry
+// below 2^r, so every limb ends below 2^(r+1).
+func (fd *Field) carryWide(z []uint64, acc *[maxLimbs]uint128) {
+	var c uint128
+	for k := range fd.n {
+		a := acc[k].add(c)
+		z[k] = a.lo & fd.mask
+		c = a.shr(fd.r)
+	}
+	t := c.mul64(fd.f).add(uint128{lo: z[0]})
+	z[0] = t.lo & fd.mask
+	z[1] += t.shr(fd.r).lo
+}
+
+// Add sets z = x + y limb by limb, without carrying: for tight x and y the
+// result is loose, ready for Mul or Sub but not for another Add. z may be
+// x, y or both.
+func (fd *Field) Add(z, x, y []uint64) {
+	fd.checkLen("Add", z, x, y)
+	for i := range z {
+		z[i] = x[i] + y[i]
+	}
+}
+
+// Sub sets z = x - y mod p with tight limbs, for loose x and y. It adds a
+// multiple of p to x that has every limb above y's, so no limb goes
+// negative, and carries. z may be x, y or both.
+func (fd *Field) Sub(z, x, y []uint64) {
+	fd.checkLen("Sub", z, x, y)
+	for i := range z {
+		z[i] = x[i] + fd.q[i] - y[i]
+	}
+	fd.CarryPropagate(z, z)
+}
+
+// Neg sets z = -x mod p with tight limbs, for loose x.
+func (fd *Field) Neg(z, x []uint64) {
+	fd.checkLen("Neg", z, x)
+	for i := range z {
+		z[i] = fd.q[i] - x[i]
+	}
+	fd.CarryPropagate(z, z)
+}
+
+// CarryPropagate sets z to x with tight limbs, for x with limbs below
+// 2^(r+4). Each limb keeps its low r bits and passes the rest up to the
+// next; the top limb's excess wraps around to limb 0 times f. The carries
+// are all taken from x before any is added, so there is no chain through
+// the limbs. z may be x.
+func (fd *Field) CarryPropagate(z, x []uint64) {
+	fd.checkLen("CarryPropagate", z, x)
+	n := fd.n
+	var c [maxLimbs]uint64
+	for i := range n {
+		c[i] = x[i] >> fd.r
+	}
+	z[0] = x[0]&fd.mask + c[n-1]*fd.f
+	for i := 1; i < n; i++ {
+		z[i] = x[i]&fd.mask + c[i-1]
+	}
+}
+
+// Reduce sets z to the canonical form of x mod p, with limbs below 2^r and
+// value in [0, p), for x with limbs below 2^(r+4). Elements compare equal
+// only once reduced. z may be x.
+//
+// After CarryPropagate the value is below 2^(n·r+2). Two sequential carry
+// passes, each wrapping its carry out of the top around times f, bring it
+// below 2^(n·r); two folds at bit k, times c, bring it below 2^k; and
+// adding c tells whether it is at least p, in which case that sum with bit
+// k cleared is the result.
+func (fd *Field) Reduce(z, x []uint64) {
+	fd.checkLen("Reduce", z, x)
+	n := fd.n
+	var l [maxLimbs]uint64
+	fd.CarryPropagate(l[:n], x)
+	for range 2 {
+		out := fd.carryChain(l[:n])
+		l[0] += out * fd.f
+	}
+	topMask := uint64(1)<<fd.top - 1
+	for range 2 {
+		hi := l[n-1] >> fd.top // 0 when k = n·r
+		l[n-1] &= topMask
+		l[0] += hi * fd.c
+		fd.carryChain(l[:n])
+	}
+	// w = l + c; l ≥ p iff w ≥ 2^k
+	var w [maxLimbs]uint64
+	copy(w[:n], l[:n])
+	w[0] += fd.c
+	out := fd.carryChain(w[:n])
+	geq := -(w[n-1]>>fd.top | out) // all ones iff l ≥ p
+	w[n-1] &= topMask
+	for i := range n {
+		z[i] = w[i]&geq | l[i]&^geq
+	}
+}
+
+// carryChain carries l sequentially from the bottom so that every limb is
+// below 2^r, and returns the carry out of the top limb.
+func (fd *Field) carryChain(l []uint64) uint64 {
+	var c uint64
+	for i := range l {
+		t := l[i] + c
+		l[i] = t & fd.mask
+		c = t >> fd.r
+	}
+	return c
+}
+
+// SetBig sets z to the canonical limbs of x mod p.
+func (fd *Field) SetBig(z []uint64, x *big.Int) {
+	fd.checkLen("SetBig", z)
+	v := new(big.Int).Mod(x, fd.p)
+	m := new(big.Int).SetUint64(fd.mask)
+	t := new(big.Int)
+	for i := range z {
+		z[i] = t.And(v, m).Uint64()
+		v.Rsh(v, fd.r)
+	}
+}
+
+// Big returns the value of x mod p, for x with limbs below 2^(r+4).
+func (fd *Field) Big(x []uint64) *big.Int {
+	var l [maxLimbs]uint64
+	fd.Reduce(l[:fd.n], x)
+	v := new(big.Int)
+	for i := fd.n - 1; i >= 0; i-- {
+		v.Lsh(v, fd.r).Or(v, new(big.Int).SetUint64(l[i]))
+	}
+	return v
+}
+
+// Equal reports whether x and y, with limbs below 2^(r+4), are the same
+// element, in constant time.
+func (fd *Field) Equal(x, y []uint64) bool {
+	var a, b [maxLimbs]uint64
+	fd.Reduce(a[:fd.n], x)
+	fd.Reduce(b[:fd.n], y)
+	var d uint64
+	for i := range fd.n {
+		d |= a[i] ^ b[i]
+	}
+	return d == 0
+}
+
+func (fd *Field) checkLen(op string, xs ...[]uint64) {
+	for _, x := range xs {
+		if len(x) != fd.n {
+			panic(fmt.Sprintf("crandall: %s: element has %d limbs, want %d", op, len(x), fd.n))
+		}
+	}
+}
+
+// uint128 is an unsigned 128-bit accumulator.
+type uint128 struct{ hi, lo uint64 }
+
+// addMul returns a + x·y.
+func (a uint128) addMul(x, y uint64) uint128 {
+	hi, lo := bits.Mul64(x, y)
+	lo, c := bits.Add64(lo, a.lo, 0)
+	return uint128{a.hi + hi + c, lo}
+}
+
+func (a uint128) add(b uint128) uint128 {
+	lo, c := bits.Add64(a.lo, b.lo, 0)
+	return uint128{a.hi + b.hi + c, lo}
+}
+
+// mul64 returns a·y, which must fit.
+func (a uint128) mul64(y uint64) uint128 {
+	hi, lo := bits.Mul64(a.lo, y)
+	return uint128{hi + a.hi*y, lo}
+}
+
+// shr returns a >> s for s in [1, 63].
+func (a uint128) shr(s uint) uint128 {
+	return uint128{a.hi >> s, a.lo>>s | a.hi<<(64-s)}
+}
@@ -0,0 +1,236 @@
+package crandall
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"testing"
+)
+
+var fields = []struct {
+	k     uint
+	c     uint64
+	limbs int
+}{
+	{61, 1, 2},
+	{127, 1, 3},
+	{130, 5, 3}, // Poly1305
+	{130, 5, 5},
+	{255, 19, 5}, // Curve25519
+	{255, 19, 6},
+	{414, 17, 9}, // Curve41417
+	{521, 1, 9},  // P-521
+	{521, 1, 12},
+}
+
+// elements returns limb vectors for fd: the canonical forms of 0, 1, p-1 and
+// random residues, then non-canonical ones with limbs up to the given
+// bound, including every limb at bound-1.
+func elements(rng *rand.Rand, fd *Field, bound uint64) [][]uint64 {
+	p := fd.Modulus()
+	var xs [][]uint64
+	for _, v := range []*big.Int{big.NewInt(0), big.NewInt(1), new(big.Int).Sub(p, big.NewInt(1))} {
+		x := make([]uint64, fd.Limbs())
+		fd.SetBig(x, v)
+		xs = append(xs, x)
+	}
+	for range 16 {
+		v := new(big.Int)
+		for range (p.BitLen() + 63) / 64 {
+			v.Lsh(v, 64).Add(v, new(big.Int).SetUint64(rng.Uint64()))
+		}
+		x := make([]uint64, fd.Limbs())
+		fd.SetBig(x, v)
+		xs = append(xs, x)
+	}
+	top := make([]uint64, fd.Limbs())
+	for i := range top {
+		top[i] = bound - 1
+	}
+	xs = append(xs, top)
+	for range 32 {
+		x := make([]uint64, fd.Limbs())
+		for i := range x {
+			x[i] = rng.Uint64N(bound)
+		}
+		xs = append(xs, x)
+	}
+	return xs
+}
+
+// value returns the integer x represents, without reducing it.
+func value(fd *Field, x []uint64) *big.Int {
+	v := new(big.Int)
+	for i := len(x) - 1; i >= 0; i-- {
+		v.Lsh(v, fd.Radix()).Add(v, new(big.Int).SetUint64(x[i]))
+	}
+	return v
+}
+
+func checkBound(t *testing.T, op string, x []uint64, bound uint64) {
+	t.Helper()
+	for i, l := range x {
+		if l >= bound {
+			t.Fatalf("%s: limb %d = %#x, want below %#x", op, i, l, bound)
+		}
+	}
+}
+
+func TestField(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range fields {
+		t.Run(fmt.Sprintf("2^%d-%d/limbs=%d", tc.k, tc.c, tc.limbs), func(t *testing.T) {
+			t.Parallel()
+			fd, err := New(tc.k, tc.c, tc.limbs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := new(big.Int).Lsh(big.NewInt(1), tc.k)
+			p.Sub(p, new(big.Int).SetUint64(tc.c))
+			r := fd.Radix()
+			if fd.Modulus().Cmp(p) != 0 || fd.Limbs() != tc.limbs || r != (tc.k+uint(tc.limbs)-1)/uint(tc.limbs) {
+				t.Fatalf("Modulus() = %x, Limbs() = %d, Radix() = %d", fd.Modulus(), fd.Limbs(), r)
+			}
+			tight, loose := uint64(1)<<(r+1), uint64(1)<<(r+2)
+			rng := rand.New(rand.NewPCG(uint64(tc.k), uint64(tc.limbs)))
+			xs := elements(rng, fd, loose)
+			mod := func(v *big.Int) *big.Int { return v.Mod(v, p) }
+			n := tc.limbs
+			z := make([]uint64, n)
+			for i, x := range xs {
+				y := xs[(i*7+3)%len(xs)]
+				vx, vy := value(fd, x), value(fd, y)
+
+				fd.Mul(z, x, y)
+				checkBound(t, "Mul", z, tight)
+				if got, want := value(fd, z), new(big.Int).Mul(vx, vy); mod(got).Cmp(mod(want)) != 0 {
+					t.Fatalf("Mul(%x, %x) = %x, want %x", x, y, got, want)
+				}
+				fd.Sub(z, x, y)
+				checkBound(t, "Sub", z, tight)
+				if got, want := value(fd, z), new(big.Int).Sub(vx, vy); mod(got).Cmp(mod(want)) != 0 {
+					t.Fatalf("Sub(%x, %x) = %x, want %x", x, y, got, want)
+				}
+				fd.Neg(z, x)
+				checkBound(t, "Neg", z, tight)
+				if got, want := value(fd, z), new(big.Int).Neg(vx); mod(got).Cmp(mod(want)) != 0 {
+					t.Fatalf("Neg(%x) = %x, want %x", x, got, want)
+				}
+
+				// The sum of two loose elements carries and reduces
+				fd.Add(z, x, y)
+				sum := new(big.Int).Add(vx, vy)
+				if got := value(fd, z); got.Cmp(sum) != 0 {
+					t.Fatalf("Add(%x, %x) = %x, want %x", x, y, got, sum)
+				}
+				c := make([]uint64, n)
+				fd.CarryPropagate(c, z)
+				checkBound(t, "CarryPropagate", c, tight)
+				if got := value(fd, c); mod(got).Cmp(mod(new(big.Int).Set(sum))) != 0 {
+					t.Fatalf("CarryPropagate(%x) = %x, want %x", z, got, sum)
+				}
+				fd.Reduce(z, z)
+				checkBound(t, "Reduce", z, 1<<r)
+				mod(sum)
+				if got := value(fd, z); got.Cmp(sum) != 0 {
+					t.Fatalf("Reduce(x + y) = %x, want %x", got, sum)
+				}
+				if got := fd.Big(c); got.Cmp(sum) != 0 || !fd.Equal(c, z) {
+					t.Fatalf("Big(%x) = %x, Equal = %v, want %x", c, got, fd.Equal(c, z), sum)
+				}
+			}
+
+			// A loose sum of two tight elements feeds Mul without a carry,
+			// and the aliased square of p-1 is 1
+			x := xs[2]
+			fd.Add(z, x, x)
+			fd.Mul(z, z, z)
+			if got, want := fd.Big(z), big.NewInt(4); got.Cmp(want) != 0 {
+				t.Errorf("(2(p-1))² = %v, want %v", got, want)
+			}
+			copy(z, x)
+			fd.Mul(z, z, z)
+			if got := fd.Big(z); got.Cmp(big.NewInt(1)) != 0 {
+				t.Errorf("Mul(x, x, x) for x = p-1 = %v, want 1", got)
+			}
+			// p, 2p and q are all 0
+			for _, v := range []*big.Int{p, new(big.Int).Lsh(p, 1)} {
+				for i := range z {
+					z[i] = new(big.Int).Rsh(v, uint(i)*r).Uint64()
+					if i < n-1 {
+						z[i] &= fd.mask
+					}
+				}
+				if fd.Reduce(z, z); !fd.Equal(z, make([]uint64, n)) || value(fd, z).Sign() != 0 {
+					t.Errorf("Reduce(%x) = %x, want 0", v, z)
+				}
+			}
+			if !fd.Equal(fd.q[:n], make([]uint64, n)) {
+				t.Errorf("Equal(q, 0) = false")
+			}
+		})
+	}
+}
+
+func TestNew_errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		k     uint
+		c     uint64
+		limbs int
+	}{
+		{255, 19, 1},
+		{255, 19, 13},
+		{255, 19, 10}, // 32·n·f² ≥ 2^r
+		{255, 0, 5},
+		{1, 1, 2},
+		{2, 5, 2},           // p < 0
+		{255, 19, 4},        // r = 64
+		{300, 1, 2},         // r = 150
+		{130, 1 << 60, 3},   // f·2^(r+2) ≥ 2^64
+		{127, 1<<20 + 1, 3}, // f² too large for 43-bit limbs
+	}
+	for _, tc := range tests {
+		if _, err := New(tc.k, tc.c, tc.limbs); !errors.Is(err, ErrInvalidParameters) {
+			t.Errorf("New(%d, %d, %d): error = %v, want %v", tc.k, tc.c, tc.limbs, err, ErrInvalidParameters)
+		}
+	}
+
+	fd, _ := New(255, 19, 5)
+	defer func() {
+		if recover() == nil {
+			t.Error("Mul with short operands did not panic")
+		}
+	}()
+	fd.Mul(make([]uint64, 5), make([]uint64, 4), make([]uint64, 5))
+}
+
+// BenchmarkMul compares a 5×51-bit product modulo 2^255 - 19, including its
+// carry, with math/big's multiplication and reduction.
+func BenchmarkMul(b *testing.B) {
+	fd, err := New(255, 19, 5)
+	if err != nil {
+		b.Fatal(err)
+	}
+	p := fd.Modulus()
+	rng := rand.New(rand.NewPCG(1, 2))
+	xs := elements(rng, fd, 1<<(fd.Radix()+1))
+	x, y := xs[5], xs[6]
+	b.Run("bits=255/impl=radix51", func(b *testing.B) {
+		z := make([]uint64, 5)
+		copy(z, x)
+		for b.Loop() {
+			fd.Mul(z, z, y)
+		}
+	})
+	b.Run("bits=255/impl=big", func(b *testing.B) {
+		z, vy := fd.Big(x), fd.Big(y)
+		for b.Loop() {
+			z.Mul(z, vy)
+			z.Mod(z, p)
+		}
+	})
+}
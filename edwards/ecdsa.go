@@ -0,0 +1,129 @@
+package edwards
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	// ErrRetry is returned by Sign when the nonce k produced r = 0 or
+	// s = 0; the caller must sign again with a fresh nonce.
+	ErrRetry = errors.New("edwards: nonce produced a zero signature component, retry with another k")
+	// ErrScalarRange is returned by Sign for a private key or nonce outside
+	// [1, L).
+	ErrScalarRange = errors.New("edwards: scalar out of range [1, L)")
+)
+
+// The functions below are the arithmetic core of ECDSA, transplanted to the
+// Edwards group: the conversion function is the affine x-coordinate of k·G
+// reduced mod L, as in ECDSA over short Weierstrass curves. Key generation,
+// nonce generation (e.g. RFC 6979) and hashing are the caller's business.
+// Signatures are not interchangeable with ECDSA over other curves or with
+// Ed25519 (EdDSA) signatures.
+
+// Sign computes the signature (r, s) of the digest under private key
+// d ∈ [1, L) with nonce k ∈ [1, L):
+//
+//	r = x(k·G) mod L
+//	s = k⁻¹ · (e + r·d) mod L
+//
+// where e is the digest truncated to the bit length of L. k must be secret,
+// uniformly random and never reused; revealing or repeating it reveals d.
+// The scalar multiplication by k is variable time like everything in this
+// package, so Sign is for testing and study, not for production keys.
+func (c *Curve) Sign(d, k *big.Int, digest []byte) (r, s *big.Int, err error) {
+	if !inScalarRange(c, d) || !inScalarRange(c, k) {
+		return nil, nil, ErrScalarRange
+	}
+	x, _ := c.Generator().ScalarMult(k).Affine()
+	r = x.Mod(x, c.L)
+	if r.Sign() == 0 {
+		return nil, nil, ErrRetry
+	}
+
+	s = new(big.Int).Mul(r, d)
+	s.Add(s, c.hashToScalar(digest))
+	s.Mul(s, c.invertScalar(k))
+	s.Mod(s, c.L)
+	if s.Sign() == 0 {
+		return nil, nil, ErrRetry
+	}
+	return r, s, nil
+}
+
+// Verify reports whether (r, s) is a valid signature of the digest under
+// public key q. It rejects r or s outside [1, L) and public keys that are
+// small-order or outside the prime-order subgroup, then checks that
+//
+//	x(u1·G + u2·q) mod L = r,  u1 = e·s⁻¹,  u2 = r·s⁻¹ mod L
+//
+// with the two scalar multiplications interleaved by DoubleScalarMult.
+func (c *Curve) Verify(q *Point, digest []byte, r, s *big.Int) bool {
+	if !inScalarRange(c, r) || !inScalarRange(c, s) {
+		return false
+	}
+	if q.IsSmallOrder() || !q.IsTorsionFree() {
+		return false
+	}
+	w := c.invertScalar(s)
+	u1 := new(big.Int).Mul(c.hashToScalar(digest), w)
+	u1.Mod(u1, c.L)
+	u2 := new(big.Int).Mul(r, w)
+	u2.Mod(u2, c.L)
+
+	p := DoubleScalarMult(u1, c.Generator(), u2, q)
+	if p.IsIdentity() {
+		return false
+	}
+	x, _ := p.Affine()
+	return x.Mod(x, c.L).Cmp(r) == 0
+}
+
+// DoubleScalarMult returns k1·p1 + k2·p2 for k1, k2 ≥ 0 by Shamir's trick:
+// one shared chain of doublings, adding p1, p2 or the precomputed p1 + p2
+// according to the bit pair, for max(len k1, len k2) doublings instead of
+// the sum. It is variable time, like ScalarMult, which is fine for
+// signature verification where all inputs are public.
+func DoubleScalarMult(k1 *big.Int, p1 *Point, k2 *big.Int, p2 *Point) *Point {
+	both := p1.Add(p2)
+	r := p1.c.Identity()
+	for i := max(k1.BitLen(), k2.BitLen()) - 1; i >= 0; i-- {
+		r = r.Add(r)
+		switch {
+		case k1.Bit(i) == 1 && k2.Bit(i) == 1:
+			r = r.Add(both)
+		case k1.Bit(i) == 1:
+			r = r.Add(p1)
+		case k2.Bit(i) == 1:
+			r = r.Add(p2)
+		}
+	}
+	return r
+}
+
+// hashToScalar converts a digest to an integer as ECDSA does: the leftmost
+// bits, as many as L has, read big-endian. The result may exceed L.
+func (c *Curve) hashToScalar(digest []byte) *big.Int {
+	n := c.L.BitLen()
+	if len(digest) > (n+7)/8 {
+		digest = digest[:(n+7)/8]
+	}
+	e := new(big.Int).SetBytes(digest)
+	if excess := len(digest)*8 - n; excess > 0 {
+		e.Rsh(e, uint(excess))
+	}
+	return e
+}
+
+// invertScalar returns k⁻¹ mod L by Fermat's little theorem, k^(L-2).
+// Unlike the extended Euclidean algorithm, whose number of steps depends on
+// the secret k, the exponentiation follows the public exponent L-2, so the
+// sequence of multiplications is the same for every nonce.
+func (c *Curve) invertScalar(k *big.Int) *big.Int {
+	e := new(big.Int).Sub(c.L, big.NewInt(2))
+	return new(big.Int).Exp(k, e, c.L)
+}
+
+func inScalarRange(c *Curve, x *big.Int) bool {
+	return x.Sign() > 0 && x.Cmp(c.L) < 0
+}
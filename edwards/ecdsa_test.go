@@ -0,0 +1,197 @@
+package edwards
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"math/rand/v2"
+	"testing"
+)
+
+// randomScalar returns a random scalar in [1, L).
+func randomScalar(rng *rand.Rand, c *Curve) *big.Int {
+	for {
+		words := make([]big.Word, (c.L.BitLen()+63)/64)
+		for i := range words {
+			words[i] = big.Word(rng.Uint64())
+		}
+		k := new(big.Int).SetBits(words)
+		k.Mod(k, c.L)
+		if k.Sign() > 0 {
+			return k
+		}
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	t.Parallel()
+
+	c := Ed25519()
+	rng := rand.New(rand.NewPCG(1, 1))
+	for i := range 10 {
+		d := randomScalar(rng, c)
+		q := c.Generator().ScalarMult(d)
+		digest := sha256.Sum256([]byte{byte(i)})
+
+		r, s, err := c.Sign(d, randomScalar(rng, c), digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !c.Verify(q, digest[:], r, s) {
+			t.Fatalf("Verify() rejected a valid signature")
+		}
+
+		other := sha256.Sum256([]byte{byte(i), 1})
+		if c.Verify(q, other[:], r, s) {
+			t.Error("Verify() accepted a signature for another digest")
+		}
+		if c.Verify(q.Add(c.Generator()), digest[:], r, s) {
+			t.Error("Verify() accepted a signature under another key")
+		}
+		if c.Verify(q, digest[:], new(big.Int).Add(r, big.NewInt(1)), s) {
+			t.Error("Verify() accepted a modified r")
+		}
+		if c.Verify(q, digest[:], r, new(big.Int).Sub(c.L, s)) {
+			t.Error("Verify() accepted a negated s")
+		}
+	}
+}
+
+func TestSign_knownAnswer(t *testing.T) {
+	t.Parallel()
+
+	c := Ed25519()
+	d, k := big.NewInt(0x1234567), big.NewInt(0xabcdef)
+	digest := []byte("sixteen byte msg")
+	r, s, err := c.Sign(d, k, digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Recompute from the definition with math/big directly
+	x, _ := c.Generator().ScalarMult(k).Affine()
+	wantR := new(big.Int).Mod(x, c.L)
+	wantS := new(big.Int).Mul(wantR, d)
+	wantS.Add(wantS, new(big.Int).SetBytes(digest))
+	wantS.Mul(wantS, new(big.Int).ModInverse(k, c.L))
+	wantS.Mod(wantS, c.L)
+	if r.Cmp(wantR) != 0 || s.Cmp(wantS) != 0 {
+		t.Errorf("Sign() = (%v, %v), want (%v, %v)", r, s, wantR, wantS)
+	}
+}
+
+func TestSign_errors(t *testing.T) {
+	t.Parallel()
+
+	c := Ed25519()
+	digest := []byte("digest")
+	for _, tc := range []struct {
+		name string
+		d, k *big.Int
+	}{
+		{"zero key", big.NewInt(0), big.NewInt(1)},
+		{"key = L", c.L, big.NewInt(1)},
+		{"zero nonce", big.NewInt(1), big.NewInt(0)},
+		{"negative nonce", big.NewInt(1), big.NewInt(-5)},
+	} {
+		if _, _, err := c.Sign(tc.d, tc.k, digest); !errors.Is(err, ErrScalarRange) {
+			t.Errorf("%s: Sign() error = %v, want %v", tc.name, err, ErrScalarRange)
+		}
+	}
+}
+
+func TestVerify_rejects(t *testing.T) {
+	t.Parallel()
+
+	c := Ed25519()
+	rng := rand.New(rand.NewPCG(2, 2))
+	d := randomScalar(rng, c)
+	q := c.Generator().ScalarMult(d)
+	digest := sha256.Sum256([]byte("message"))
+	r, s, err := c.Sign(d, randomScalar(rng, c), digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	torsion := decodeHex(t, c, smallOrderEncodings[4].enc)
+
+	tests := []struct {
+		name string
+		q    *Point
+		r, s *big.Int
+	}{
+		{"r = 0", q, big.NewInt(0), s},
+		{"s = 0", q, r, big.NewInt(0)},
+		{"r + L", q, new(big.Int).Add(r, c.L), s},
+		{"s + L", q, r, new(big.Int).Add(s, c.L)},
+		{"identity key", c.Identity(), r, s},
+		{"small-order key", torsion, r, s},
+		{"key with torsion component", q.Add(torsion), r, s},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if c.Verify(tc.q, digest[:], tc.r, tc.s) {
+				t.Error("Verify() = true")
+			}
+		})
+	}
+}
+
+func TestDoubleScalarMult(t *testing.T) {
+	t.Parallel()
+
+	c := Ed25519()
+	rng := rand.New(rand.NewPCG(3, 3))
+	g := c.Generator()
+	q := g.ScalarMult(randomScalar(rng, c))
+	for _, k := range [][2]*big.Int{
+		{big.NewInt(0), big.NewInt(0)},
+		{big.NewInt(1), big.NewInt(0)},
+		{big.NewInt(0), big.NewInt(7)},
+		{big.NewInt(5), big.NewInt(1 << 40)},
+		{randomScalar(rng, c), randomScalar(rng, c)},
+	} {
+		want := g.ScalarMult(k[0]).Add(q.ScalarMult(k[1]))
+		if got := DoubleScalarMult(k[0], g, k[1], q); !got.Equal(want) {
+			t.Errorf("DoubleScalarMult(%v, G, %v, Q) != %v·G + %v·Q", k[0], k[1], k[0], k[1])
+		}
+	}
+}
+
+func Test_hashToScalar(t *testing.T) {
+	t.Parallel()
+
+	c := Ed25519() // L has 253 bits
+	long := make([]byte, 64)
+	for i := range long {
+		long[i] = 0xff
+	}
+	want := new(big.Int).Lsh(big.NewInt(1), 253)
+	want.Sub(want, big.NewInt(1))
+	if got := c.hashToScalar(long); got.Cmp(want) != 0 {
+		t.Errorf("hashToScalar(64 × 0xff) = %x, want %x", got, want)
+	}
+	if got := c.hashToScalar([]byte{1, 2}); got.Int64() != 0x0102 {
+		t.Errorf("hashToScalar([1 2]) = %v, want 258", got)
+	}
+}
+
+// BenchmarkVerify compares Shamir's trick against two separate scalar
+// multiplications for the verification equation.
+func BenchmarkVerify(b *testing.B) {
+	c := Ed25519()
+	rng := rand.New(rand.NewPCG(4, 4))
+	g := c.Generator()
+	q := g.ScalarMult(randomScalar(rng, c))
+	u1, u2 := randomScalar(rng, c), randomScalar(rng, c)
+	b.Run("DoubleScalarMult", func(b *testing.B) {
+		for b.Loop() {
+			DoubleScalarMult(u1, g, u2, q)
+		}
+	})
+	b.Run("Separate", func(b *testing.B) {
+		for b.Loop() {
+			g.ScalarMult(u1).Add(q.ScalarMult(u2))
+		}
+	})
+}
@@ -0,0 +1,177 @@
+// Package edwards provides arithmetic on twisted Edwards curves
+// a·x² + y² = 1 + d·x²·y² over a prime field, together with the validation
+// that such curves need in practice.
+//
+// Edwards curves have a cofactor: the group of points has order h·ℓ for a
+// large prime ℓ and a small h (8 for Ed25519). Points outside the prime-order
+// subgroup, and in particular the few points of small order, let an attacker
+// leak a key modulo h or make a signature verify under several keys.
+// MulByCofactor, IsSmallOrder, IsTorsionFree and TorsionComponent expose the
+// torsion structure, and Decode rejects non-canonical encodings outright.
+//
+// The arithmetic uses math/big and is not constant time; it is meant for
+// validation and experimentation, not for handling secret scalars.
+package edwards
+
+import (
+	"math/big"
+)
+
+// Curve is a twisted Edwards curve with a prime-order subgroup of order L
+// and cofactor H, so that the curve has H·L points. The addition law is
+// complete, with no exceptional points, when A is a square and D a
+// non-square mod P, as for Ed25519.
+type Curve struct {
+	Name   string
+	P      *big.Int // field prime
+	A, D   *big.Int // curve coefficients mod P
+	L      *big.Int // prime order of the main subgroup
+	H      *big.Int // cofactor
+	Gx, Gy *big.Int // generator of the order-L subgroup
+}
+
+// Point is a curve point in extended coordinates (X : Y : Z : T) with
+// x = X/Z, y = Y/Z and x·y = T/Z. Points are immutable; all operations
+// return new ones.
+type Point struct {
+	c          *Curve
+	x, y, z, t *big.Int
+}
+
+// Ed25519 returns the curve of RFC 8032: edwards25519 with p = 2^255 - 19,
+// a = -1, d = -121665/121666, ℓ = 2^252 + 27742317777372353535851937790883648493
+// and cofactor 8.
+func Ed25519() *Curve {
+	p := new(big.Int).Lsh(big.NewInt(1), 255)
+	p.Sub(p, big.NewInt(19))
+	d := new(big.Int).ModInverse(big.NewInt(121666), p)
+	d.Mul(d, big.NewInt(-121665))
+	d.Mod(d, p)
+	l, _ := new(big.Int).SetString("27742317777372353535851937790883648493", 10)
+	l.Add(l, new(big.Int).Lsh(big.NewInt(1), 252))
+	// Gy = 4/5, Gx the even root
+	gy := new(big.Int).ModInverse(big.NewInt(5), p)
+	gy.Mul(gy, big.NewInt(4))
+	gy.Mod(gy, p)
+	c := &Curve{
+		Name: "Ed25519",
+		P:    p,
+		A:    new(big.Int).Sub(p, big.NewInt(1)),
+		D:    d,
+		L:    l,
+		H:    big.NewInt(8),
+		Gy:   gy,
+	}
+	c.Gx, _ = c.recoverX(gy, false)
+	return c
+}
+
+// Identity returns the neutral element (0, 1).
+func (c *Curve) Identity() *Point {
+	return &Point{c: c, x: new(big.Int), y: big.NewInt(1), z: big.NewInt(1), t: new(big.Int)}
+}
+
+// Generator returns the base point (Gx, Gy).
+func (c *Curve) Generator() *Point {
+	p, _ := c.NewPoint(c.Gx, c.Gy)
+	return p
+}
+
+// NewPoint returns the point (x, y), or ErrNotOnCurve if it does not
+// satisfy the curve equation. x and y are reduced mod P.
+func (c *Curve) NewPoint(x, y *big.Int) (*Point, error) {
+	x, y = new(big.Int).Mod(x, c.P), new(big.Int).Mod(y, c.P)
+	if !c.onCurve(x, y) {
+		return nil, ErrNotOnCurve
+	}
+	t := new(big.Int).Mul(x, y)
+	return &Point{c: c, x: x, y: y, z: big.NewInt(1), t: t.Mod(t, c.P)}, nil
+}
+
+// onCurve reports whether a·x² + y² = 1 + d·x²·y² for reduced x and y.
+func (c *Curve) onCurve(x, y *big.Int) bool {
+	x2 := new(big.Int).Mul(x, x)
+	y2 := new(big.Int).Mul(y, y)
+	lhs := new(big.Int).Mul(c.A, x2)
+	lhs.Add(lhs, y2)
+	rhs := new(big.Int).Mul(c.D, x2)
+	rhs.Mul(rhs, y2)
+	rhs.Add(rhs, big.NewInt(1))
+	return lhs.Sub(lhs, rhs).Mod(lhs, c.P).Sign() == 0
+}
+
+// Affine returns the affine coordinates (x, y).
+func (p *Point) Affine() (x, y *big.Int) {
+	zi := new(big.Int).ModInverse(p.z, p.c.P)
+	x = new(big.Int).Mul(p.x, zi)
+	y = new(big.Int).Mul(p.y, zi)
+	return x.Mod(x, p.c.P), y.Mod(y, p.c.P)
+}
+
+// Equal reports whether p and q are the same point.
+func (p *Point) Equal(q *Point) bool {
+	P := p.c.P
+	a, b := new(big.Int), new(big.Int)
+	if a.Mul(p.x, q.z).Sub(a, b.Mul(q.x, p.z)).Mod(a, P).Sign() != 0 {
+		return false
+	}
+	return a.Mul(p.y, q.z).Sub(a, b.Mul(q.y, p.z)).Mod(a, P).Sign() == 0
+}
+
+// IsIdentity reports whether p is the neutral element.
+func (p *Point) IsIdentity() bool {
+	return p.x.Sign() == 0 && new(big.Int).Sub(p.y, p.z).Sign() == 0
+}
+
+// Add returns p + q with the unified extended-coordinates formula of
+// Hisil–Wong–Carter–Dawson (add-2008-hwcd), complete on curves where A is a
+// square and D is not.
+func (p *Point) Add(q *Point) *Point {
+	c := p.c
+	P := c.P
+	mod := func(x *big.Int) *big.Int { return x.Mod(x, P) }
+
+	a := mod(new(big.Int).Mul(p.x, q.x))
+	b := mod(new(big.Int).Mul(p.y, q.y))
+	cc := mod(new(big.Int).Mul(p.t, q.t))
+	cc = mod(cc.Mul(cc, c.D))
+	d := mod(new(big.Int).Mul(p.z, q.z))
+	e := new(big.Int).Add(p.x, p.y)
+	e = mod(e.Mul(e, new(big.Int).Add(q.x, q.y)))
+	e = mod(e.Sub(e, a).Sub(e, b))
+	f := mod(new(big.Int).Sub(d, cc))
+	g := mod(new(big.Int).Add(d, cc))
+	h := mod(new(big.Int).Sub(b, new(big.Int).Mul(c.A, a)))
+
+	return &Point{
+		c: c,
+		x: mod(new(big.Int).Mul(e, f)),
+		y: mod(new(big.Int).Mul(g, h)),
+		z: mod(new(big.Int).Mul(f, g)),
+		t: mod(new(big.Int).Mul(e, h)),
+	}
+}
+
+// Neg returns -p = (-x, y).
+func (p *Point) Neg() *Point {
+	P := p.c.P
+	x := new(big.Int).Neg(p.x)
+	t := new(big.Int).Neg(p.t)
+	return &Point{c: p.c, x: x.Mod(x, P), y: new(big.Int).Set(p.y), z: new(big.Int).Set(p.z), t: t.Mod(t, P)}
+}
+
+// ScalarMult returns k·p for k ≥ 0 by double-and-add. It branches on the
+// bits of k and must not be used with secret scalars.
+func (p *Point) ScalarMult(k *big.Int) *Point {
+	if k.Sign() < 0 {
+		return p.Neg().ScalarMult(new(big.Int).Neg(k))
+	}
+	r := p.c.Identity()
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		r = r.Add(r)
+		if k.Bit(i) == 1 {
+			r = r.Add(p)
+		}
+	}
+	return r
+}
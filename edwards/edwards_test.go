@@ -0,0 +1,93 @@
+package edwards
+
+import (
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestEd25519(t *testing.T) {
+	t.Parallel()
+
+	c := Ed25519()
+	g := c.Generator()
+	if !c.onCurve(c.Gx, c.Gy) {
+		t.Fatal("generator is not on the curve")
+	}
+	// RFC 8032, section 5.1: Gx = 1511...2202
+	wantX, _ := new(big.Int).SetString("15112221349535400772501151409588531511454012693041857206046113283949847762202", 10)
+	if x, _ := g.Affine(); x.Cmp(wantX) != 0 {
+		t.Errorf("Gx = %v, want %v", x, wantX)
+	}
+	if !g.ScalarMult(c.L).IsIdentity() {
+		t.Error("L·G is not the identity")
+	}
+	if g.ScalarMult(new(big.Int).Sub(c.L, big.NewInt(1))).Equal(g) {
+		t.Error("(L-1)·G = G")
+	}
+}
+
+func TestNewPoint(t *testing.T) {
+	t.Parallel()
+
+	c := Ed25519()
+	tests := []struct {
+		name    string
+		x, y    *big.Int
+		wantErr error
+	}{
+		{"identity", big.NewInt(0), big.NewInt(1), nil},
+		{"order two", big.NewInt(0), big.NewInt(-1), nil},
+		{"generator", c.Gx, c.Gy, nil},
+		{"unreduced generator", new(big.Int).Add(c.Gx, c.P), c.Gy, nil},
+		{"off the curve", big.NewInt(1), big.NewInt(1), ErrNotOnCurve},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := c.NewPoint(tc.x, tc.y); err != tc.wantErr {
+				t.Errorf("NewPoint() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestGroupLaw(t *testing.T) {
+	t.Parallel()
+
+	c := Ed25519()
+	g := c.Generator()
+	id := c.Identity()
+	if !g.Add(id).Equal(g) || !id.Add(g).Equal(g) {
+		t.Error("G + O != G")
+	}
+	if !g.Add(g.Neg()).IsIdentity() {
+		t.Error("G + (-G) is not the identity")
+	}
+	if !g.ScalarMult(big.NewInt(-3)).Equal(g.ScalarMult(big.NewInt(3)).Neg()) {
+		t.Error("(-3)·G != -(3·G)")
+	}
+
+	err := quick.Check(func(a, b uint32) bool {
+		ka, kb := big.NewInt(int64(a)), big.NewInt(int64(b))
+		pa, pb := g.ScalarMult(ka), g.ScalarMult(kb)
+		sum := pa.Add(pb)
+		if !sum.Equal(g.ScalarMult(new(big.Int).Add(ka, kb))) || !sum.Equal(pb.Add(pa)) {
+			return false
+		}
+		x, y := sum.Affine()
+		return c.onCurve(x, y)
+	}, &quick.Config{MaxCount: 20})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func BenchmarkScalarMult(b *testing.B) {
+	c := Ed25519()
+	g := c.Generator()
+	k := new(big.Int).Sub(c.L, big.NewInt(12345))
+	for b.Loop() {
+		g.ScalarMult(k)
+	}
+}
@@ -0,0 +1,112 @@
+package edwards
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	// ErrEncoding is returned for an encoding of the wrong length.
+	ErrEncoding = errors.New("edwards: invalid point encoding length")
+	// ErrNonCanonical is returned for an encoding with y ≥ P, or with the
+	// sign bit set for x = 0: both decode to a point that has another,
+	// canonical encoding, which breaks byte-wise comparisons of keys.
+	ErrNonCanonical = errors.New("edwards: non-canonical point encoding")
+	// ErrNotOnCurve is returned when no curve point has the given y.
+	ErrNotOnCurve = errors.New("edwards: point is not on the curve")
+	// ErrSmallOrder is returned by Validate for a point of order dividing H.
+	ErrSmallOrder = errors.New("edwards: point has small order")
+	// ErrNotInSubgroup is returned by Validate for a point with a nonzero
+	// small-torsion component.
+	ErrNotInSubgroup = errors.New("edwards: point is not in the prime-order subgroup")
+)
+
+// EncodedLen returns the length of an encoded point: enough bytes for y
+// plus one bit for the sign of x.
+func (c *Curve) EncodedLen() int {
+	return (c.P.BitLen() + 1 + 7) / 8
+}
+
+// Encode returns the RFC 8032 encoding of p: y in little-endian order with
+// the top bit of the last byte set when x is odd.
+func (p *Point) Encode() []byte {
+	x, y := p.Affine()
+	n := p.c.EncodedLen()
+	b := y.FillBytes(make([]byte, n))
+	reverse(b)
+	b[n-1] |= byte(x.Bit(0)) << 7
+	return b
+}
+
+// Decode parses an encoding produced by Encode. It accepts only canonical
+// encodings, but any curve point, including small-order points and points
+// outside the prime-order subgroup; see Validate.
+func (c *Curve) Decode(b []byte) (*Point, error) {
+	n := c.EncodedLen()
+	if len(b) != n {
+		return nil, ErrEncoding
+	}
+	le := make([]byte, n)
+	copy(le, b)
+	sign := le[n-1] >> 7
+	le[n-1] &= 0x7f
+	reverse(le)
+	y := new(big.Int).SetBytes(le)
+	if y.Cmp(c.P) >= 0 {
+		return nil, ErrNonCanonical
+	}
+	x, err := c.recoverX(y, sign == 1)
+	if err != nil {
+		return nil, err
+	}
+	return c.NewPoint(x, y)
+}
+
+// Validate decodes b and accepts it only as an element of the prime-order
+// subgroup other than the identity: the checks a public key or signature
+// component needs before any group arithmetic.
+func (c *Curve) Validate(b []byte) (*Point, error) {
+	p, err := c.Decode(b)
+	if err != nil {
+		return nil, err
+	}
+	if p.IsSmallOrder() {
+		return nil, ErrSmallOrder
+	}
+	if !p.IsTorsionFree() {
+		return nil, ErrNotInSubgroup
+	}
+	return p, nil
+}
+
+// recoverX solves the curve equation for x: x² = (y² - 1) / (d·y² - a),
+// choosing the root whose parity matches odd.
+func (c *Curve) recoverX(y *big.Int, odd bool) (*big.Int, error) {
+	P := c.P
+	y2 := new(big.Int).Mul(y, y)
+	u := new(big.Int).Sub(y2, big.NewInt(1))
+	v := new(big.Int).Mul(c.D, y2)
+	v.Sub(v, c.A).Mod(v, P)
+	vi := new(big.Int).ModInverse(v, P)
+	if vi == nil {
+		return nil, ErrNotOnCurve
+	}
+	x2 := u.Mul(u, vi).Mod(u, P)
+	x := new(big.Int).ModSqrt(x2, P)
+	if x == nil {
+		return nil, ErrNotOnCurve
+	}
+	if x.Sign() == 0 && odd {
+		return nil, ErrNonCanonical
+	}
+	if (x.Bit(0) == 1) != odd {
+		x.Sub(P, x)
+	}
+	return x, nil
+}
+
+func reverse(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
@@ -0,0 +1,99 @@
+package edwards
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	c := Ed25519()
+	tests := []struct {
+		name    string
+		enc     string
+		wantErr error
+	}{
+		// RFC 8032, section 7.1, TEST 1 public key
+		{"public key", "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a", nil},
+		{"base point", "5866666666666666666666666666666666666666666666666666666666666666", nil},
+		{"identity", "0100000000000000000000000000000000000000000000000000000000000000", nil},
+		{"identity with sign bit", "0100000000000000000000000000000000000000000000000000000000000080", ErrNonCanonical},
+		{"y = p", "edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", ErrNonCanonical},
+		{"y = p + 1", "eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", ErrNonCanonical},
+		{"y = 2^255 - 1", "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", ErrNonCanonical},
+		{"y = 2, not on curve", "0200000000000000000000000000000000000000000000000000000000000000", ErrNotOnCurve},
+		{"too short", "0100", ErrEncoding},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			b, _ := hex.DecodeString(tc.enc)
+			p, err := c.Decode(b)
+			if err != tc.wantErr {
+				t.Fatalf("Decode() error = %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && !bytes.Equal(p.Encode(), b) {
+				t.Errorf("Encode(Decode(b)) = %x, want %s", p.Encode(), tc.enc)
+			}
+		})
+	}
+
+	if got := hex.EncodeToString(c.Generator().Encode()); got != "5866666666666666666666666666666666666666666666666666666666666666" {
+		t.Errorf("Generator().Encode() = %s", got)
+	}
+}
+
+func TestEncodeDecodeProperty(t *testing.T) {
+	t.Parallel()
+
+	c := Ed25519()
+	g := c.Generator()
+	err := quick.Check(func(k uint64) bool {
+		p := g.ScalarMult(new(big.Int).SetUint64(k))
+		q, err := c.Decode(p.Encode())
+		return err == nil && q.Equal(p)
+	}, &quick.Config{MaxCount: 20})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	c := Ed25519()
+	g := c.Generator()
+	mixed := g.Add(decodeHex(t, c, smallOrderEncodings[4].enc))
+	tests := []struct {
+		name    string
+		enc     []byte
+		wantErr error
+	}{
+		{"generator", g.Encode(), nil},
+		{"multiple of generator", g.ScalarMult(big.NewInt(424242)).Encode(), nil},
+		{"identity", c.Identity().Encode(), ErrSmallOrder},
+		{"order 8", mustHex(smallOrderEncodings[6].enc), ErrSmallOrder},
+		{"mixed order", mixed.Encode(), ErrNotInSubgroup},
+		{"non-canonical", mustHex("edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f"), ErrNonCanonical},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := c.Validate(tc.enc); err != tc.wantErr {
+				t.Errorf("Validate() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
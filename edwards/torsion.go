@@ -0,0 +1,37 @@
+package edwards
+
+import "math/big"
+
+// MulByCofactor returns H·p, which always lies in the prime-order subgroup.
+// Protocols that "clear the cofactor" this way accept every point but map
+// the H points differing by small torsion to the same result.
+func (p *Point) MulByCofactor() *Point {
+	return p.ScalarMult(p.c.H)
+}
+
+// IsSmallOrder reports whether p has order dividing H, i.e. whether it is
+// one of the H small-torsion points, the identity included. Such points make
+// any scalar multiple predictable and must be rejected as public keys.
+func (p *Point) IsSmallOrder() bool {
+	return p.MulByCofactor().IsIdentity()
+}
+
+// IsTorsionFree reports whether p lies in the prime-order subgroup, i.e.
+// whether L·p is the identity.
+func (p *Point) IsTorsionFree() bool {
+	return p.ScalarMult(p.c.L).IsIdentity()
+}
+
+// TorsionComponent returns the small-order part of p. The group of points
+// is the direct product of the order-L and the order-H subgroups, so p
+// splits uniquely as p = p_L + p_H; TorsionComponent returns p_H, and
+// p - p_H is torsion-free.
+//
+// p_H = e·p for the e with e ≡ 0 mod L and e ≡ 1 mod H, namely
+// e = L·(L⁻¹ mod H). It requires gcd(L, H) = 1, which holds whenever L is a
+// prime larger than H.
+func (p *Point) TorsionComponent() *Point {
+	c := p.c
+	e := new(big.Int).ModInverse(c.L, c.H)
+	return p.ScalarMult(e.Mul(e, c.L))
+}
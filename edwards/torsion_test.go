@@ -0,0 +1,88 @@
+package edwards
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+// smallOrderEncodings are the canonical encodings of the eight torsion
+// points of Ed25519, with their orders.
+var smallOrderEncodings = []struct {
+	enc   string
+	order int64
+}{
+	{"0100000000000000000000000000000000000000000000000000000000000000", 1},
+	{"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", 2},
+	{"0000000000000000000000000000000000000000000000000000000000000000", 4},
+	{"0000000000000000000000000000000000000000000000000000000000000080", 4},
+	{"26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc05", 8},
+	{"26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc85", 8},
+	{"c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a", 8},
+	{"c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac03fa", 8},
+}
+
+func decodeHex(t *testing.T, c *Curve, s string) *Point {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := c.Decode(b)
+	if err != nil {
+		t.Fatalf("Decode(%s) error = %v", s, err)
+	}
+	return p
+}
+
+func TestSmallOrder(t *testing.T) {
+	t.Parallel()
+
+	c := Ed25519()
+	for _, tc := range smallOrderEncodings {
+		p := decodeHex(t, c, tc.enc)
+		if !p.IsSmallOrder() {
+			t.Errorf("%s: IsSmallOrder() = false", tc.enc)
+		}
+		if !p.ScalarMult(big.NewInt(tc.order)).IsIdentity() {
+			t.Errorf("%s: order does not divide %d", tc.enc, tc.order)
+		}
+		if tc.order > 1 && p.ScalarMult(big.NewInt(tc.order/2)).IsIdentity() {
+			t.Errorf("%s: order divides %d", tc.enc, tc.order/2)
+		}
+		if tc.order > 1 && p.IsTorsionFree() {
+			t.Errorf("%s: IsTorsionFree() = true", tc.enc)
+		}
+		if !p.TorsionComponent().Equal(p) {
+			t.Errorf("%s: TorsionComponent() != p for a torsion point", tc.enc)
+		}
+	}
+	if c.Generator().IsSmallOrder() {
+		t.Error("generator reports small order")
+	}
+}
+
+func TestTorsionComponent(t *testing.T) {
+	t.Parallel()
+
+	c := Ed25519()
+	g := c.Generator().ScalarMult(big.NewInt(987654321))
+	for _, tc := range smallOrderEncodings {
+		tp := decodeHex(t, c, tc.enc)
+		mixed := g.Add(tp)
+
+		if got := mixed.TorsionComponent(); !got.Equal(tp) {
+			t.Errorf("%s: TorsionComponent() is not the torsion point added", tc.enc)
+		}
+		if !mixed.Add(mixed.TorsionComponent().Neg()).Equal(g) {
+			t.Errorf("%s: p - TorsionComponent(p) != prime-order part", tc.enc)
+		}
+		if got := mixed.IsTorsionFree(); got != (tc.order == 1) {
+			t.Errorf("%s: IsTorsionFree() = %v", tc.enc, got)
+		}
+		cleared := mixed.MulByCofactor()
+		if !cleared.IsTorsionFree() || !cleared.Equal(g.ScalarMult(c.H)) {
+			t.Errorf("%s: MulByCofactor() did not clear the torsion", tc.enc)
+		}
+	}
+}
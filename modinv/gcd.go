@@ -0,0 +1,113 @@
+package modinv
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+)
+
+// GCD returns gcd(x, N) for odd N > 0 by safegcd's divsteps, in time that
+// depends only on the bit length of N, apart from the conversion of x from
+// *big.Int as with Inverse. x may be negative or at least N. It returns an
+// error wrapping ErrInvalidModulus for an even or non-positive N.
+func GCD(x, N *big.Int) (*big.Int, error) {
+	if err := checkOdd(N); err != nil {
+		return nil, err
+	}
+	size := N.BitLen()
+	L := (size + 2 + limbBits - 1) / limbBits
+	f, g := toSigned30(N, L), toSigned30(new(big.Int).Mod(x, N), L)
+	delta := int32(1)
+	for range divstepBatches(size) {
+		var t transition
+		delta, t = divsteps30(delta, uint32(f[0]), uint32(g[0]))
+		updateFG(f, g, &t)
+	}
+	// f = ±gcd
+	neg := f[L-1] >> 31
+	for i := range L {
+		f[i] = (f[i] ^ neg) - neg
+	}
+	carry(f)
+	return fromSigned30(f), nil
+}
+
+// Jacobi returns the Jacobi symbol (x/N), 1, -1 or 0, for odd N > 0: the
+// Legendre symbol when N is prime, so that x is a square mod N iff it is 1.
+// The time depends only on the bit length of N, apart from the conversion
+// of x from *big.Int. It returns an error wrapping ErrInvalidModulus for
+// an even or non-positive N.
+//
+// Divsteps take f and g through negative values, where the reciprocity law
+// that tracks the symbol does not hold as such, so Jacobi runs a binary
+// GCD that keeps both operands non-negative instead, with b odd: if a is
+// odd, swap a and b when a < b and subtract b from a; then halve a. Each
+// step is done by masks on whole limbs, and a·b at least halves, so 2·bits
+// steps bring a to 0 whatever the operands. Along the way (a/b) changes
+// sign when a swap exchanges two numbers ≡ 3 mod 4 and when a halving
+// happens with b ≡ 3 or 5 mod 8.
+func Jacobi(x, N *big.Int) (int, error) {
+	if err := checkOdd(N); err != nil {
+		return 0, err
+	}
+	size := N.BitLen()
+	L := (size + 63) / 64
+	a, b := toWords(new(big.Int).Mod(x, N), L), toWords(N, L)
+	d := make([]uint64, L)
+	var sign uint64 // bit 1 set when the symbol is -1
+	for range 2 * size {
+		odd := -(a[0] & 1)
+		var borrow uint64
+		for i := range L {
+			d[i], borrow = bits.Sub64(a[i], b[i], borrow)
+		}
+		swap := odd & -borrow // a is odd and a < b
+		sign ^= a[0] & b[0] & 2 & swap
+		for i := range L {
+			t := (a[i] ^ b[i]) & swap
+			a[i] ^= t
+			b[i] ^= t
+		}
+		// a - b, now that a ≥ b, or a itself if a is even
+		borrow = 0
+		for i := range L {
+			a[i], borrow = bits.Sub64(a[i], b[i]&odd, borrow)
+		}
+		for i := range L - 1 {
+			a[i] = a[i]>>1 | a[i+1]<<63
+		}
+		a[L-1] >>= 1
+		// (2/b) = -1 iff bits 1 and 2 of b differ
+		sign ^= (b[0] ^ b[0]>>1) & 2
+	}
+	// a = 0 and b = gcd(x, N)
+	rest := b[0] ^ 1
+	for _, w := range b[1:] {
+		rest |= w
+	}
+	if rest != 0 {
+		return 0, nil
+	}
+	return 1 - int(sign), nil
+}
+
+// checkOdd returns an error wrapping ErrInvalidModulus unless N is odd and
+// positive.
+func checkOdd(N *big.Int) error {
+	if N.Sign() <= 0 || N.Bit(0) == 0 {
+		return fmt.Errorf("%w: N = %v, want an odd N > 0", ErrInvalidModulus, N)
+	}
+	return nil
+}
+
+// toWords returns the L 64-bit limbs of x ≥ 0, which must fit.
+func toWords(x *big.Int, L int) []uint64 {
+	buf := x.FillBytes(make([]byte, 8*L))
+	out := make([]uint64, L)
+	for i := range L {
+		for _, c := range buf[8*(L-1-i) : 8*(L-i)] {
+			out[i] = out[i]<<8 | uint64(c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,132 @@
+package modinv
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"testing"
+)
+
+// TestGCD_small checks every x in [-N, 2N) for every odd N below 300
+// against big.Int.GCD, and the same for Jacobi against big.Jacobi.
+func TestGCD_small(t *testing.T) {
+	t.Parallel()
+
+	for n := int64(1); n < 300; n += 2 {
+		N := big.NewInt(n)
+		for x := -n; x < 2*n; x++ {
+			checkGCD(t, big.NewInt(x), N)
+		}
+	}
+}
+
+func TestGCD_random(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(7, 8))
+	for _, bits := range []int{2, 30, 31, 63, 64, 65, 127, 128, 255, 256, 521, 1024, 2048} {
+		t.Run(fmt.Sprintf("bits=%d", bits), func(t *testing.T) {
+			t.Parallel()
+			for range 40 {
+				N := randInt(rng, bits)
+				N.SetBit(N, 0, 1)
+				checkGCD(t, randInt(rng, bits+rng.IntN(64)), N)
+				checkGCD(t, new(big.Int).Sub(N, big.NewInt(1)), N)
+				// Shared factors
+				g := randInt(rng, 1+rng.IntN(bits))
+				g.SetBit(g, 0, 1)
+				x := new(big.Int).Mul(g, randInt(rng, 1+rng.IntN(bits)))
+				checkGCD(t, x, new(big.Int).Mul(N, g))
+				checkGCD(t, new(big.Int).Mul(x, x), new(big.Int).Mul(N, g))
+			}
+		})
+	}
+}
+
+// TestJacobi_primes checks that the symbol of every square mod a prime is
+// 1, and that of a non-residue -1, for primes of several sizes.
+func TestJacobi_primes(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(9, 10))
+	for _, p := range []*big.Int{
+		big.NewInt(3),
+		big.NewInt(65537),
+		mustInt("0xffffffff00000001"),
+		mustInt("0x7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffed"),
+		mustInt("0xffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632551"),
+	} {
+		for range 20 {
+			x := new(big.Int).Mod(randInt(rng, p.BitLen()+8), p)
+			if x.Sign() == 0 {
+				continue
+			}
+			sq := new(big.Int).Mul(x, x)
+			if got, err := Jacobi(sq, p); err != nil || got != 1 {
+				t.Errorf("Jacobi(x², %v) = %d, %v, want 1", p, got, err)
+			}
+			want := 1
+			if new(big.Int).ModSqrt(x, p) == nil {
+				want = -1
+			}
+			if got, err := Jacobi(x, p); err != nil || got != want {
+				t.Errorf("Jacobi(%v, %v) = %d, %v, want %d", x, p, got, err, want)
+			}
+		}
+	}
+}
+
+func checkGCD(t *testing.T, x, N *big.Int) {
+	t.Helper()
+	got, err := GCD(x, N)
+	want := new(big.Int).GCD(nil, nil, new(big.Int).Mod(x, N), N)
+	if err != nil || got.Cmp(want) != 0 {
+		t.Fatalf("GCD(%v, %v) = %v, %v, want %v", x, N, got, err, want)
+	}
+	j, err := Jacobi(x, N)
+	if wantJ := big.Jacobi(new(big.Int).Mod(x, N), N); err != nil || j != wantJ {
+		t.Fatalf("Jacobi(%v, %v) = %d, %v, want %d", x, N, j, err, wantJ)
+	}
+}
+
+func TestGCD_errors(t *testing.T) {
+	t.Parallel()
+
+	for _, N := range []int64{0, -3, 4, 2} {
+		if _, err := GCD(big.NewInt(3), big.NewInt(N)); !errors.Is(err, ErrInvalidModulus) {
+			t.Errorf("GCD(3, %d): error = %v, want %v", N, err, ErrInvalidModulus)
+		}
+		if _, err := Jacobi(big.NewInt(3), big.NewInt(N)); !errors.Is(err, ErrInvalidModulus) {
+			t.Errorf("Jacobi(3, %d): error = %v, want %v", N, err, ErrInvalidModulus)
+		}
+	}
+}
+
+func BenchmarkJacobi(b *testing.B) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for _, bits := range []int{256, 2048} {
+		N := randInt(rng, bits)
+		N.SetBit(N, 0, 1)
+		x := randInt(rng, bits-1)
+		b.Run(fmt.Sprintf("bits=%d/impl=ct", bits), func(b *testing.B) {
+			for b.Loop() {
+				if _, err := Jacobi(x, N); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("bits=%d/impl=big", bits), func(b *testing.B) {
+			for b.Loop() {
+				big.Jacobi(x, N)
+			}
+		})
+		b.Run(fmt.Sprintf("bits=%d/impl=gcd", bits), func(b *testing.B) {
+			for b.Loop() {
+				if _, err := GCD(x, N); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package modinv
+
+import "math/big"
+
+// lehmerBits is the precision of the leading digits Lehmer's inner loop
+// works on. With 62 bits, a digit plus a cofactor, each below 2^62, cannot
+// overflow an int64.
+const lehmerBits = 62
+
+// lehmer is the extended Euclidean algorithm on N and a in [0, N) with
+// Lehmer's acceleration (Knuth, TAOCP vol. 2, 4.5.2, Algorithm L, and
+// Menezes et al., Handbook of Applied Cryptography, 14.57). It keeps the
+// same invariants as euclid, u·a ≡ r0 and v·a ≡ r1 mod N.
+//
+// Each round takes the leading lehmerBits bits of r0, and r1 shifted by
+// the same amount, and runs Euclid on them in single precision while the
+// quotients are certain to match the full-precision ones: q is accepted
+// only when both ends of the interval the true quotient lies in give it.
+// The collected steps form a matrix [[A, B], [C, D]] that is applied to
+// r0, r1 and the cofactors at once, replacing several long divisions with
+// four multiplications by a word. A round that collects no step falls back
+// to one full division.
+func lehmer(a, N *big.Int) (*big.Int, bool) {
+	r0, r1 := new(big.Int).Set(N), new(big.Int).Set(a)
+	u, v := new(big.Int), big.NewInt(1)
+	s0, t0, t1 := new(big.Int), new(big.Int), new(big.Int)
+	for r1.Sign() != 0 {
+		A, B, C, D, ok := lehmerSteps(r0, r1, t0)
+		if !ok {
+			t0.QuoRem(r0, r1, t1)
+			r0, r1, t1 = r1, t1, r0
+			u.Sub(u, s0.Mul(t0, v))
+			u, v = v, u
+			continue
+		}
+		combine(t0, t1, r0, r1, A, B, C, D, s0)
+		r0, r1, t0, t1 = t0, t1, r0, r1
+		combine(t0, t1, u, v, A, B, C, D, s0)
+		u, v, t0, t1 = t0, t1, u, v
+	}
+	if r0.Cmp(big.NewInt(1)) != 0 {
+		return nil, false
+	}
+	return u.Mod(u, N), true
+}
+
+// lehmerSteps runs Euclid on the leading digits of r0 ≥ r1 > 0 and returns
+// the matrix of the steps it is sure of, or false if there are none. t is
+// scratch space.
+func lehmerSteps(r0, r1, t *big.Int) (A, B, C, D int64, ok bool) {
+	shift := uint(max(r0.BitLen()-lehmerBits, 0))
+	x := int64(t.Rsh(r0, shift).Uint64())
+	y := int64(t.Rsh(r1, shift).Uint64())
+	A, B, C, D = 1, 0, 0, 1
+	// C and D have opposite signs after the first step, so y = 0 ends the
+	// loop
+	for y+C > 0 && y+D > 0 {
+		q := (x + A) / (y + C)
+		if q != (x+B)/(y+D) {
+			break
+		}
+		A, C = C, A-q*C
+		B, D = D, B-q*D
+		x, y = y, x-q*y
+	}
+	return A, B, C, D, B != 0
+}
+
+// combine sets z0 = A·x + B·y and z1 = C·x + D·y, with s as scratch space.
+// z0 and z1 must not alias x or y.
+func combine(z0, z1, x, y *big.Int, A, B, C, D int64, s *big.Int) {
+	z0.Mul(x, s.SetInt64(A))
+	z0.Add(z0, s.Mul(y, s.SetInt64(B)))
+	z1.Mul(x, s.SetInt64(C))
+	z1.Add(z1, s.Mul(y, s.SetInt64(D)))
+}
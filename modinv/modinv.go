@@ -0,0 +1,150 @@
+// Package modinv computes modular inverses of arbitrary-size integers with
+// a choice of algorithm: the extended Euclidean algorithm, the binary
+// (Stein) extended GCD, Lehmer's single-word acceleration of Euclid, and
+// Bernstein and Yang's constant-time safegcd.
+//
+// The first three branch on the operands and suit public values. SafeGCD
+// runs a number of steps fixed by the bit length of N, with no branch or
+// memory access that depends on x, which the standard library's
+// big.Int.ModInverse does not offer; it is the one to use for secrets such
+// as ECDSA nonces or blinding factors. GCD and Jacobi extend the same
+// guarantee to greatest common divisors and Jacobi (Legendre) symbols, for
+// square-root and primality computations on secret values.
+package modinv
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	// ErrNotInvertible is returned when x and N share a factor, x ≡ 0
+	// included.
+	ErrNotInvertible = errors.New("modinv: x is not invertible mod N")
+	// ErrInvalidModulus is returned for N ≤ 1, and for an even N with the
+	// algorithms that need an odd one.
+	ErrInvalidModulus = errors.New("modinv: invalid modulus")
+)
+
+// Algorithm selects how Inverse computes an inverse.
+type Algorithm uint8
+
+const (
+	// Euclid is the extended Euclidean algorithm on math/big, one long
+	// division per step. It accepts any N > 1.
+	Euclid Algorithm = iota
+	// Binary is the binary extended GCD, which replaces the divisions with
+	// shifts and subtractions and halves mod N along the way. N must be odd.
+	Binary
+	// Lehmer is Euclid's algorithm driven by the leading word of the
+	// operands: runs of quotients are found in single precision and applied
+	// to the full numbers as one 2×2 matrix. It accepts any N > 1.
+	Lehmer
+	// SafeGCD is Bernstein and Yang's divstep algorithm on signed 30-bit
+	// limbs, constant time for a given size of N. N must be odd.
+	SafeGCD
+)
+
+// Algorithms lists every Algorithm, in the order of the constants.
+var Algorithms = []Algorithm{Euclid, Binary, Lehmer, SafeGCD}
+
+func (a Algorithm) String() string {
+	switch a {
+	case Euclid:
+		return "euclid"
+	case Binary:
+		return "binary"
+	case Lehmer:
+		return "lehmer"
+	case SafeGCD:
+		return "safegcd"
+	}
+	return fmt.Sprintf("Algorithm(%d)", uint8(a))
+}
+
+// Inverse returns x⁻¹ mod N in [0, N), computed by alg. x may be negative
+// or at least N. It returns an error wrapping ErrInvalidModulus for a
+// modulus alg does not accept, and ErrNotInvertible when gcd(x, N) ≠ 1.
+//
+// With SafeGCD the time depends only on the bit length of N, apart from
+// the conversion of x from *big.Int, which normalizes away leading zero
+// words and so reveals the word length of x mod N.
+func Inverse(x, N *big.Int, alg Algorithm) (*big.Int, error) {
+	if N.Cmp(big.NewInt(1)) <= 0 {
+		return nil, fmt.Errorf("%w: N = %v, want N > 1", ErrInvalidModulus, N)
+	}
+	if N.Bit(0) == 0 && (alg == Binary || alg == SafeGCD) {
+		return nil, fmt.Errorf("%w: %v needs an odd N", ErrInvalidModulus, alg)
+	}
+	a := new(big.Int).Mod(x, N)
+	var (
+		z  *big.Int
+		ok bool
+	)
+	switch alg {
+	case Euclid:
+		z, ok = euclid(a, N)
+	case Binary:
+		z, ok = binary(a, N)
+	case Lehmer:
+		z, ok = lehmer(a, N)
+	case SafeGCD:
+		z, ok = safegcd(a, N)
+	default:
+		return nil, fmt.Errorf("modinv: unknown %v", alg)
+	}
+	if !ok {
+		return nil, ErrNotInvertible
+	}
+	return z, nil
+}
+
+// euclid runs the extended Euclidean algorithm on N and a in [0, N),
+// keeping only the cofactor of a: u·a ≡ r0 and v·a ≡ r1 mod N throughout.
+func euclid(a, N *big.Int) (*big.Int, bool) {
+	r0, r1 := new(big.Int).Set(N), new(big.Int).Set(a)
+	u, v := new(big.Int), big.NewInt(1)
+	q, r, t := new(big.Int), new(big.Int), new(big.Int)
+	for r1.Sign() != 0 {
+		q.QuoRem(r0, r1, r)
+		r0, r1, r = r1, r, r0
+		t.Mul(q, v)
+		u.Sub(u, t)
+		u, v = v, u
+	}
+	if r0.Cmp(big.NewInt(1)) != 0 {
+		return nil, false
+	}
+	return u.Mod(u, N), true
+}
+
+// binary is Stein's extended GCD for odd N and a in [0, N): with
+// x1·a ≡ u and x2·a ≡ v mod N and v odd, it strips the factors of two
+// from u, halving x1 mod N alongside, swaps so that u ≥ v and subtracts.
+// When u reaches 0, v is the GCD and x2 the inverse if that is 1.
+func binary(a, N *big.Int) (*big.Int, bool) {
+	u, v := new(big.Int).Set(a), new(big.Int).Set(N)
+	x1, x2 := big.NewInt(1), new(big.Int)
+	for u.Sign() != 0 {
+		for u.Bit(0) == 0 {
+			u.Rsh(u, 1)
+			if x1.Bit(0) == 1 {
+				x1.Add(x1, N)
+			}
+			x1.Rsh(x1, 1)
+		}
+		if u.Cmp(v) < 0 {
+			u, v = v, u
+			x1, x2 = x2, x1
+		}
+		u.Sub(u, v)
+		if x1.Sub(x1, x2); x1.Sign() < 0 {
+			x1.Add(x1, N)
+		}
+	}
+	if v.Cmp(big.NewInt(1)) != 0 {
+		return nil, false
+	}
+	return x2, true
+}
@@ -0,0 +1,183 @@
+package modinv
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"testing"
+)
+
+// randInt returns a random integer of exactly bits bits.
+func randInt(rng *rand.Rand, bits int) *big.Int {
+	x := new(big.Int)
+	for range (bits + 63) / 64 {
+		x.Lsh(x, 64).Or(x, new(big.Int).SetUint64(rng.Uint64()))
+	}
+	x.Rsh(x, uint(64*((bits+63)/64)-bits))
+	return x.SetBit(x, bits-1, 1)
+}
+
+func mustInt(s string) *big.Int {
+	x, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		panic("bad integer " + s)
+	}
+	return x
+}
+
+func TestInverse(t *testing.T) {
+	t.Parallel()
+
+	// Consecutive Fibonacci numbers, all of whose quotients are 1
+	fib0, fib1 := big.NewInt(0), big.NewInt(1)
+	for range 1500 {
+		fib0.Add(fib0, fib1)
+		fib0, fib1 = fib1, fib0
+	}
+	p256 := mustInt("0xffffffff00000001000000000000000000000000ffffffffffffffffffffffff")
+	n256 := mustInt("0xffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632551")
+	tests := []struct {
+		name string
+		x, N *big.Int
+	}{
+		{"3^-1 mod 7", big.NewInt(3), big.NewInt(7)},
+		{"1 mod 3", big.NewInt(1), big.NewInt(3)},
+		{"x = N-1", big.NewInt(10), big.NewInt(11)},
+		{"negative x", big.NewInt(-3), big.NewInt(7)},
+		{"x above N", big.NewInt(1000003), big.NewInt(1009)},
+		{"x = 2 mod 2^127-1", big.NewInt(2), mustInt("0x7fffffffffffffffffffffffffffffff")},
+		{"P-256 field", mustInt("0x6b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c296"), p256},
+		{"P-256 order", new(big.Int).Sub(n256, big.NewInt(2)), n256},
+		{"Fibonacci", fib0, fib1},
+		{"small x, large N", big.NewInt(3), new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 1024), big.NewInt(1))},
+		{"word boundary", new(big.Int).Lsh(big.NewInt(1), 64), new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(51))},
+	}
+	for _, tc := range tests {
+		for _, alg := range Algorithms {
+			t.Run(fmt.Sprintf("%s/%v", tc.name, alg), func(t *testing.T) {
+				t.Parallel()
+				got, err := Inverse(tc.x, tc.N, alg)
+				want := new(big.Int).ModInverse(tc.x, tc.N)
+				if err != nil || got.Cmp(want) != 0 {
+					t.Errorf("Inverse(%v, %v) = %v, %v, want %v", tc.x, tc.N, got, err, want)
+				}
+			})
+		}
+	}
+}
+
+// TestInverse_small checks every x for every modulus below 300, each
+// algorithm accepting it, against big.Int.ModInverse.
+func TestInverse_small(t *testing.T) {
+	t.Parallel()
+
+	for _, alg := range Algorithms {
+		t.Run(alg.String(), func(t *testing.T) {
+			t.Parallel()
+			for n := int64(2); n < 300; n++ {
+				N := big.NewInt(n)
+				if n%2 == 0 && (alg == Binary || alg == SafeGCD) {
+					continue
+				}
+				for x := range n {
+					checkInverse(t, big.NewInt(x), N, alg)
+				}
+			}
+		})
+	}
+}
+
+// TestInverse_random checks random operands of many sizes, including
+// sizes next to limb and word boundaries and moduli sharing a factor with
+// x.
+func TestInverse_random(t *testing.T) {
+	t.Parallel()
+
+	for _, alg := range Algorithms {
+		t.Run(alg.String(), func(t *testing.T) {
+			t.Parallel()
+			rng := rand.New(rand.NewPCG(uint64(alg), 1))
+			for _, bits := range []int{2, 29, 30, 31, 45, 46, 47, 60, 62, 63, 64, 65, 90, 127, 128, 129, 255, 256, 384, 521, 1024, 2048} {
+				for range 20 {
+					N := randInt(rng, bits)
+					if alg == Binary || alg == SafeGCD {
+						N.SetBit(N, 0, 1)
+					}
+					if N.Cmp(big.NewInt(1)) <= 0 {
+						continue
+					}
+					checkInverse(t, randInt(rng, bits+rng.IntN(64)), N, alg)
+					checkInverse(t, new(big.Int).Sub(N, big.NewInt(1)), N, alg)
+					// A shared factor
+					g := big.NewInt(int64(3 + 2*rng.IntN(100)))
+					checkInverse(t, g, new(big.Int).Mul(N, g), alg)
+				}
+			}
+		})
+	}
+}
+
+func checkInverse(t *testing.T, x, N *big.Int, alg Algorithm) {
+	t.Helper()
+	got, err := Inverse(x, N, alg)
+	want := new(big.Int).ModInverse(x, N)
+	if want == nil || new(big.Int).Mod(x, N).Sign() == 0 {
+		if !errors.Is(err, ErrNotInvertible) {
+			t.Fatalf("%v: Inverse(%v, %v) = %v, %v, want %v", alg, x, N, got, err, ErrNotInvertible)
+		}
+		return
+	}
+	if err != nil || got.Cmp(want) != 0 {
+		t.Fatalf("%v: Inverse(%v, %v) = %v, %v, want %v", alg, x, N, got, err, want)
+	}
+}
+
+func TestInverse_errors(t *testing.T) {
+	t.Parallel()
+
+	for _, alg := range Algorithms {
+		for _, N := range []int64{1, 0, -7} {
+			if _, err := Inverse(big.NewInt(3), big.NewInt(N), alg); !errors.Is(err, ErrInvalidModulus) {
+				t.Errorf("%v: Inverse(3, %d): error = %v, want %v", alg, N, err, ErrInvalidModulus)
+			}
+		}
+		want := error(nil)
+		if alg == Binary || alg == SafeGCD {
+			want = ErrInvalidModulus
+		}
+		if _, err := Inverse(big.NewInt(3), big.NewInt(16), alg); !errors.Is(err, want) {
+			t.Errorf("%v: Inverse(3, 16): error = %v, want %v", alg, err, want)
+		}
+	}
+	if _, err := Inverse(big.NewInt(3), big.NewInt(7), Algorithm(9)); err == nil {
+		t.Error("Inverse with an unknown algorithm succeeded")
+	}
+	if got := fmt.Sprint(Algorithms, Algorithm(9)); got != "[euclid binary lehmer safegcd] Algorithm(9)" {
+		t.Errorf("Algorithm strings = %s", got)
+	}
+}
+
+func BenchmarkInverse(b *testing.B) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for _, bits := range []int{256, 2048} {
+		N := randInt(rng, bits)
+		N.SetBit(N, 0, 1)
+		x := randInt(rng, bits-1)
+		for _, alg := range Algorithms {
+			b.Run(fmt.Sprintf("bits=%d/impl=%v", bits, alg), func(b *testing.B) {
+				for b.Loop() {
+					if _, err := Inverse(x, N, alg); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+		b.Run(fmt.Sprintf("bits=%d/impl=big", bits), func(b *testing.B) {
+			z := new(big.Int)
+			for b.Loop() {
+				z.ModInverse(x, N)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package modinv
+
+import "math/big"
+
+// limbBits is the radix of the signed limbs safegcd works on. With 30-bit
+// limbs and 30 divsteps per batch, every product of a transition matrix
+// entry and a limb, and the sums of them, fit in an int64.
+const (
+	limbBits = 30
+	limbMask = 1<<limbBits - 1
+)
+
+// safegcd is Bernstein and Yang's constant-time inverse ("Fast
+// constant-time gcd computation and modular inversion", 2019) for odd N
+// and a in [0, N), laid out as in libsecp256k1's modinv32.
+//
+// A divstep maps (δ, f, g), f odd, to
+//
+//	(1 - δ, g, (g - f)/2)  if δ > 0 and g is odd,
+//	(1 + δ, f, (g + f)/2)  if g is odd otherwise,
+//	(1 + δ, f, g/2)        if g is even.
+//
+// Starting from δ = 1, f = N and g = a, g reaches 0 within divstepBound
+// steps, and f is then ±gcd(a, N). d and e track f and g as multiples of
+// a mod N, d·a ≡ f and e·a ≡ g, so d is ±a⁻¹ in the end.
+//
+// The steps are taken 30 at a time on the low words of f and g alone,
+// which is all a divstep looks at, collecting a 2×2 transition matrix that
+// is then applied to the full f, g, d and e. Every batch runs the same
+// instructions, and the number of batches depends only on the bit length
+// of N.
+func safegcd(a, N *big.Int) (*big.Int, bool) {
+	size := N.BitLen()
+	// Room for d and e in (-2N, N), sign included
+	L := (size + 2 + limbBits - 1) / limbBits
+	n := toSigned30(N, L)
+	f, g := toSigned30(N, L), toSigned30(a, L)
+	d, e := make([]int32, L), make([]int32, L)
+	e[0] = 1
+	ninv := uint32(n[0]) // N⁻¹ mod 2^30, by Newton from 3 correct bits
+	for range 4 {
+		ninv *= 2 - uint32(n[0])*ninv
+	}
+
+	delta := int32(1)
+	for range divstepBatches(size) {
+		var t transition
+		delta, t = divsteps30(delta, uint32(f[0]), uint32(g[0]))
+		updateDE(d, e, &t, n, ninv)
+		updateFG(f, g, &t)
+	}
+	if !isUnit(f) {
+		return nil, false
+	}
+	normalize(d, f[L-1], n)
+	return fromSigned30(d), true
+}
+
+// divstepBound returns a number of divsteps after which g = 0 for any odd
+// f and 0 ≤ g < f < 2^size, from Theorem 11.2 of Bernstein and Yang.
+func divstepBound(size int) int {
+	if size < 46 {
+		return (49*size + 57 + 16) / 17
+	}
+	return (49*size + 80 + 16) / 17
+}
+
+// divstepBatches returns the number of 30-divstep batches that cover
+// divstepBound(size).
+func divstepBatches(size int) int {
+	return (divstepBound(size) + limbBits - 1) / limbBits
+}
+
+// transition is the matrix of 30 divsteps, scaled by 2^30:
+// 2^30·f' = u·f + v·g and 2^30·g' = q·f + r·g, with |u| + |v| ≤ 2^30 and
+// |q| + |r| ≤ 2^30.
+type transition struct{ u, v, q, r int32 }
+
+// divsteps30 runs 30 divsteps from δ on the low 32 bits of f and g and
+// returns the new δ and their transition matrix. Each step swaps and
+// negates by mask, so none of them branches.
+func divsteps30(delta int32, f0, g0 uint32) (int32, transition) {
+	u, v, q, r := int32(1), int32(0), int32(0), int32(1)
+	f, g := int32(f0), int32(g0)
+	for range limbBits {
+		odd := -(g & 1)
+		// (δ, f, g) = (-δ, g, -f) when δ > 0 and g is odd, so that the
+		// step below computes (g - f)/2
+		swap := -delta >> 31 & odd
+		x := (f ^ g) & swap
+		f, g = f^x, g^x
+		g = (g ^ swap) - swap
+		x = (u ^ q) & swap
+		u, q = u^x, q^x
+		q = (q ^ swap) - swap
+		x = (v ^ r) & swap
+		v, r = v^x, r^x
+		r = (r ^ swap) - swap
+		delta = (delta ^ swap) - swap
+
+		// g = (g + f)/2 if g is odd, g/2 otherwise; f's coefficients are
+		// doubled instead of halving g's, to keep them integers
+		g += f & odd
+		q += u & odd
+		r += v & odd
+		g >>= 1
+		u <<= 1
+		v <<= 1
+		delta++
+	}
+	return delta, transition{u, v, q, r}
+}
+
+// updateDE sets d, e = (u·d + v·e)/2^30, (q·d + r·e)/2^30 mod N. Each is
+// made divisible by adding a multiple of N chosen from its low limb, and
+// N more when d or e is negative, which keeps both in (-2N, N).
+func updateDE(d, e []int32, t *transition, n []int32, ninv uint32) {
+	L := len(d)
+	u, v, q, r := int64(t.u), int64(t.v), int64(t.q), int64(t.r)
+	sd, se := int64(d[L-1]>>31), int64(e[L-1]>>31)
+	md := u&sd + v&se
+	me := q&sd + r&se
+	cd := u*int64(d[0]) + v*int64(e[0])
+	ce := q*int64(d[0]) + r*int64(e[0])
+	// md, me ≡ -N⁻¹·cd, -N⁻¹·ce mod 2^30
+	md -= int64((ninv*uint32(cd) + uint32(md)) & limbMask)
+	me -= int64((ninv*uint32(ce) + uint32(me)) & limbMask)
+	cd += int64(n[0]) * md
+	ce += int64(n[0]) * me
+	cd >>= limbBits
+	ce >>= limbBits
+	for i := 1; i < L; i++ {
+		cd += u*int64(d[i]) + v*int64(e[i]) + int64(n[i])*md
+		ce += q*int64(d[i]) + r*int64(e[i]) + int64(n[i])*me
+		d[i-1] = int32(cd & limbMask)
+		e[i-1] = int32(ce & limbMask)
+		cd >>= limbBits
+		ce >>= limbBits
+	}
+	d[L-1], e[L-1] = int32(cd), int32(ce)
+}
+
+// updateFG sets f, g = (u·f + v·g)/2^30, (q·f + r·g)/2^30, divisions the
+// divsteps make exact.
+func updateFG(f, g []int32, t *transition) {
+	L := len(f)
+	u, v, q, r := int64(t.u), int64(t.v), int64(t.q), int64(t.r)
+	cf := u*int64(f[0]) + v*int64(g[0])
+	cg := q*int64(f[0]) + r*int64(g[0])
+	cf >>= limbBits
+	cg >>= limbBits
+	for i := 1; i < L; i++ {
+		cf += u*int64(f[i]) + v*int64(g[i])
+		cg += q*int64(f[i]) + r*int64(g[i])
+		f[i-1] = int32(cf & limbMask)
+		g[i-1] = int32(cg & limbMask)
+		cf >>= limbBits
+		cg >>= limbBits
+	}
+	f[L-1], g[L-1] = int32(cf), int32(cg)
+}
+
+// isUnit reports whether f is 1 or -1. Which one, or whether a has an
+// inverse at all, is not secret.
+func isUnit(f []int32) bool {
+	L := len(f)
+	var one, minusOne int32
+	one = f[0] ^ 1
+	minusOne = f[L-1] ^ -1
+	for i := 1; i < L; i++ {
+		one |= f[i]
+	}
+	for i := range L - 1 {
+		minusOne |= f[i] ^ limbMask
+	}
+	return one == 0 || minusOne == 0
+}
+
+// normalize brings d from (-2N, N) to d·sign(f) mod N in [0, N), with
+// every limb in [0, 2^30): add N if d is negative, negate if f is, carry,
+// and add N again if that is still negative.
+func normalize(d []int32, fTop int32, n []int32) {
+	L := len(d)
+	add := d[L-1] >> 31
+	for i := range L {
+		d[i] += n[i] & add
+	}
+	neg := fTop >> 31
+	for i := range L {
+		d[i] = (d[i] ^ neg) - neg
+	}
+	carry(d)
+	add = d[L-1] >> 31
+	for i := range L {
+		d[i] += n[i] & add
+	}
+	carry(d)
+}
+
+// carry brings every limb of d but the top one into [0, 2^30).
+func carry(d []int32) {
+	for i := range len(d) - 1 {
+		d[i+1] += d[i] >> limbBits
+		d[i] &= limbMask
+	}
+}
+
+// toSigned30 returns the L limbs of x ≥ 0, which must fit.
+func toSigned30(x *big.Int, L int) []int32 {
+	buf := x.FillBytes(make([]byte, (limbBits*L+7)/8))
+	out := make([]int32, L)
+	var acc uint64
+	var nb uint
+	j := 0
+	for k := len(buf) - 1; k >= 0; k-- {
+		acc |= uint64(buf[k]) << nb
+		nb += 8
+		if nb >= limbBits {
+			out[j] = int32(acc & limbMask)
+			acc >>= limbBits
+			nb -= limbBits
+			j++
+		}
+	}
+	if j < L {
+		out[j] = int32(acc)
+	}
+	return out
+}
+
+// fromSigned30 returns the value of d, whose limbs are all in [0, 2^30).
+func fromSigned30(d []int32) *big.Int {
+	buf := make([]byte, (limbBits*len(d)+7)/8)
+	var acc uint64
+	var nb uint
+	k := len(buf) - 1
+	for _, l := range d {
+		acc |= uint64(l) << nb
+		nb += limbBits
+		for nb >= 8 {
+			buf[k] = byte(acc)
+			acc >>= 8
+			nb -= 8
+			k--
+		}
+	}
+	if nb > 0 {
+		buf[k] = byte(acc)
+	}
+	return new(big.Int).SetBytes(buf)
+}
@@ -0,0 +1,121 @@
+package modinv
+
+import (
+	"math/big"
+	"math/bits"
+	"math/rand/v2"
+	"testing"
+)
+
+// divstep is one step of the definition, on exact integers.
+func divstep(delta, f, g int64) (int64, int64, int64) {
+	switch {
+	case delta > 0 && g&1 == 1:
+		return 1 - delta, g, (g - f) / 2
+	case g&1 == 1:
+		return 1 + delta, f, (g + f) / 2
+	}
+	return 1 + delta, f, g / 2
+}
+
+// TestDivstepBound runs divsteps to g = 0 for every odd f below 2^11 and
+// every g in [0, f), and checks that none needs more than divstepBound.
+func TestDivstepBound(t *testing.T) {
+	t.Parallel()
+
+	for f0 := int64(1); f0 < 1<<11; f0 += 2 {
+		bound := divstepBound(bits.Len64(uint64(f0)))
+		for g0 := range f0 {
+			delta, f, g := int64(1), f0, g0
+			steps := 0
+			for g != 0 {
+				delta, f, g = divstep(delta, f, g)
+				steps++
+			}
+			if steps > bound {
+				t.Fatalf("f = %d, g = %d: %d divsteps, bound %d", f0, g0, steps, bound)
+			}
+			if gcd := new(big.Int).GCD(nil, nil, big.NewInt(f0), big.NewInt(g0)); f != gcd.Int64() && f != -gcd.Int64() {
+				t.Fatalf("f = %d, g = %d: ended with f = %d, want ±%v", f0, g0, f, gcd)
+			}
+		}
+	}
+}
+
+// TestDivsteps30 checks the batched, branch-free steps and their matrix
+// against the definition on random 62-bit f and g.
+func TestDivsteps30(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(3, 4))
+	for range 10000 {
+		f0 := int64(rng.Uint64()>>2) | 1
+		g0 := int64(rng.Uint64() >> 2)
+		delta0 := int64(rng.IntN(64)) - 32
+		delta, f, g := delta0, f0, g0
+		for range limbBits {
+			delta, f, g = divstep(delta, f, g)
+		}
+		gotDelta, m := divsteps30(int32(delta0), uint32(f0), uint32(g0))
+		if int64(gotDelta) != delta {
+			t.Fatalf("divsteps30(%d, %#x, %#x): δ = %d, want %d", delta0, f0, g0, gotDelta, delta)
+		}
+		if n := abs(m.u) + abs(m.v); n > 1<<limbBits {
+			t.Fatalf("|u| + |v| = %d", n)
+		}
+		if n := abs(m.q) + abs(m.r); n > 1<<limbBits {
+			t.Fatalf("|q| + |r| = %d", n)
+		}
+		F, G := big.NewInt(f0), big.NewInt(g0)
+		wantF := new(big.Int).Lsh(big.NewInt(f), limbBits)
+		wantG := new(big.Int).Lsh(big.NewInt(g), limbBits)
+		gotF := new(big.Int).Add(new(big.Int).Mul(big.NewInt(int64(m.u)), F), new(big.Int).Mul(big.NewInt(int64(m.v)), G))
+		gotG := new(big.Int).Add(new(big.Int).Mul(big.NewInt(int64(m.q)), F), new(big.Int).Mul(big.NewInt(int64(m.r)), G))
+		if gotF.Cmp(wantF) != 0 || gotG.Cmp(wantG) != 0 {
+			t.Fatalf("divsteps30(%d, %#x, %#x): matrix %+v gives %v, %v, want %v, %v", delta0, f0, g0, m, gotF, gotG, wantF, wantG)
+		}
+	}
+}
+
+func abs(x int32) int64 {
+	if x < 0 {
+		return -int64(x)
+	}
+	return int64(x)
+}
+
+func TestSigned30(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(5, 6))
+	for _, size := range []int{1, 29, 30, 31, 64, 255, 256, 1000} {
+		x := randInt(rng, size)
+		L := (size + 2 + limbBits - 1) / limbBits
+		l := toSigned30(x, L)
+		for i, v := range l {
+			if v < 0 || v > limbMask {
+				t.Fatalf("%d bits: limb %d = %#x", size, i, v)
+			}
+		}
+		if got := fromSigned30(l); got.Cmp(x) != 0 {
+			t.Errorf("%d bits: round trip gives %x, want %x", size, got, x)
+		}
+	}
+
+	// isUnit recognizes 1 and -1 only
+	for _, v := range []int64{1, -1, 0, 2, -2, 1 << 30, -(1 << 30) - 1} {
+		l := make([]int32, 4)
+		l[0] = int32(v & limbMask)
+		c := v >> limbBits
+		for i := 1; i < len(l); i++ {
+			l[i] = int32(c & limbMask)
+			c >>= limbBits
+		}
+		if v < 0 {
+			l[len(l)-1] |= -1 << limbBits
+		}
+		if got, want := isUnit(l), v == 1 || v == -1; got != want {
+			t.Errorf("isUnit(%d) = %v, want %v", v, got, want)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/blck-snwmn/arithmetic-vault/modmatrix
+
+go 1.25.5
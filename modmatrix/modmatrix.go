@@ -0,0 +1,189 @@
+// Package modmatrix provides square matrices over Z/NZ and their powers.
+//
+// Raising a matrix to the e-th power by square-and-multiply takes O(log e)
+// matrix products, which makes the k-th term of a linear recurrence
+// (Fibonacci, tribonacci, any a_n = c_1 a_{n-1} + ... + c_d a_{n-d}) cost
+// O(d³ log k) instead of O(d k): the recurrence's companion matrix advances
+// the state by one step, so its k-th power advances it by k.
+package modmatrix
+
+import (
+	"errors"
+	"math/big"
+	"slices"
+)
+
+var (
+	// ErrNotSquare is returned for a matrix with no rows, a row of the wrong
+	// length, or a nil entry.
+	ErrNotSquare = errors.New("modmatrix: matrix is not square")
+	// ErrDimension is returned when the operands' sizes do not match, or a
+	// Recurrence coefficient or initial term is nil.
+	ErrDimension = errors.New("modmatrix: dimension mismatch")
+	// ErrModulus is returned for a modulus N < 1.
+	ErrModulus = errors.New("modmatrix: modulus must be positive")
+	// ErrNegativeExponent is returned for e < 0; matrices need not be invertible.
+	ErrNegativeExponent = errors.New("modmatrix: negative exponent")
+)
+
+// Matrix is a square matrix stored row by row.
+type Matrix [][]*big.Int
+
+// Identity returns the n×n identity matrix.
+func Identity(n int) Matrix {
+	m := zero(n)
+	for i := range n {
+		m[i][i].SetInt64(1)
+	}
+	return m
+}
+
+// Mul returns a*b with every entry reduced into [0, N).
+func Mul(a, b Matrix, N *big.Int) (Matrix, error) {
+	n, err := check(a, N)
+	if err != nil {
+		return nil, err
+	}
+	if bn, err := check(b, N); err != nil {
+		return nil, err
+	} else if bn != n {
+		return nil, ErrDimension
+	}
+	return mul(a, b, N), nil
+}
+
+// Pow returns M^e with every entry reduced into [0, N), by left-to-right
+// square-and-multiply. M^0 is the identity (reduced mod N, so all zeros for
+// N = 1).
+func Pow(M Matrix, e, N *big.Int) (Matrix, error) {
+	n, err := check(M, N)
+	if err != nil {
+		return nil, err
+	}
+	if e.Sign() < 0 {
+		return nil, ErrNegativeExponent
+	}
+	base := reduce(M, N)
+	one := reduce(Identity(n), N)
+	return pow(base, one, e, func(x, y Matrix) Matrix { return mul(x, y, N) }), nil
+}
+
+// Recurrence returns the k-th term (counting from a_0) mod N of the linear
+// recurrence a_n = c_1 a_{n-1} + c_2 a_{n-2} + ... + c_d a_{n-d}, where
+// coeffs = [c_1, ..., c_d] and initial = [a_0, ..., a_{d-1}].
+//
+// Fibonacci, for example, is coeffs [1, 1] with initial [0, 1].
+func Recurrence(coeffs, initial []*big.Int, k, N *big.Int) (*big.Int, error) {
+	d := len(coeffs)
+	if d == 0 || len(initial) != d {
+		return nil, ErrDimension
+	}
+	if slices.Contains(coeffs, nil) || slices.Contains(initial, nil) {
+		return nil, ErrDimension
+	}
+	if N.Sign() <= 0 {
+		return nil, ErrModulus
+	}
+	if k.Sign() < 0 {
+		return nil, ErrNegativeExponent
+	}
+
+	// Companion matrix: the first row applies the recurrence, the others
+	// shift the state (a_{n+d-1}, ..., a_n) down by one.
+	c := zero(d)
+	for j, cj := range coeffs {
+		c[0][j].Mod(cj, N)
+	}
+	for i := 1; i < d; i++ {
+		c[i][i-1].SetInt64(1)
+	}
+	ck, err := Pow(c, k, N)
+	if err != nil {
+		return nil, err
+	}
+
+	// a_k is the last component of C^k (a_{d-1}, ..., a_0)
+	sum := new(big.Int)
+	t := new(big.Int)
+	for j := range d {
+		sum.Add(sum, t.Mul(ck[d-1][j], initial[d-1-j]))
+	}
+	return sum.Mod(sum, N), nil
+}
+
+// pow computes x^e by left-to-right square-and-multiply in any monoid given
+// its identity and multiplication.
+func pow[T any](x, one T, e *big.Int, mul func(a, b T) T) T {
+	acc := one
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		acc = mul(acc, acc)
+		if e.Bit(i) == 1 {
+			acc = mul(acc, x)
+		}
+	}
+	return acc
+}
+
+// mul multiplies two n×n matrices mod N, accumulating each entry's dot
+// product unreduced and reducing once.
+func mul(a, b Matrix, N *big.Int) Matrix {
+	n := len(a)
+	c := zero(n)
+	t := new(big.Int)
+	for i := range n {
+		for j := range n {
+			sum := c[i][j]
+			for k := range n {
+				sum.Add(sum, t.Mul(a[i][k], b[k][j]))
+			}
+			sum.Mod(sum, N)
+		}
+	}
+	return c
+}
+
+// reduce returns a copy of m with every entry in [0, N).
+func reduce(m Matrix, N *big.Int) Matrix {
+	r := zero(len(m))
+	for i, row := range m {
+		for j, x := range row {
+			r[i][j].Mod(x, N)
+		}
+	}
+	return r
+}
+
+// zero returns the n×n zero matrix.
+func zero(n int) Matrix {
+	m := make(Matrix, n)
+	for i := range m {
+		m[i] = make([]*big.Int, n)
+		for j := range m[i] {
+			m[i][j] = new(big.Int)
+		}
+	}
+	return m
+}
+
+// check validates that m is square with non-nil entries and N ≥ 1, and
+// returns the dimension.
+func check(m Matrix, N *big.Int) (int, error) {
+	if N.Sign() <= 0 {
+		return 0, ErrModulus
+	}
+	n := len(m)
+	if n == 0 {
+		return 0, ErrNotSquare
+	}
+	for _, row := range m {
+		if len(row) != n {
+			return 0, ErrNotSquare
+		}
+		for _, x := range row {
+			if x == nil {
+				return 0, ErrNotSquare
+			}
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,222 @@
+package modmatrix
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func mat(rows ...[]int64) Matrix {
+	m := make(Matrix, len(rows))
+	for i, row := range rows {
+		m[i] = make([]*big.Int, len(row))
+		for j, x := range row {
+			m[i][j] = big.NewInt(x)
+		}
+	}
+	return m
+}
+
+func equal(a, b Matrix) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if len(a[i]) != len(b[i]) {
+			return false
+		}
+		for j := range a[i] {
+			if a[i][j].Cmp(b[i][j]) != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// fib returns F(k) mod N by iteration, as a reference.
+func fib(k int, N *big.Int) *big.Int {
+	a, b := big.NewInt(0), big.NewInt(1)
+	for range k {
+		a, b = b, new(big.Int).Add(a, b)
+	}
+	return a.Mod(a, N)
+}
+
+func TestPow(t *testing.T) {
+	t.Parallel()
+
+	N := big.NewInt(1_000_000_007)
+	tests := []struct {
+		name string
+		M    Matrix
+		e    int64
+		N    *big.Int
+		want Matrix
+	}{
+		{"e=0 is identity", mat([]int64{2, 3}, []int64{4, 5}), 0, N, mat([]int64{1, 0}, []int64{0, 1})},
+		{"e=1 reduces entries", mat([]int64{-1, 12}, []int64{4, 5}), 1, big.NewInt(7), mat([]int64{6, 5}, []int64{4, 5})},
+		{"Fibonacci", mat([]int64{1, 1}, []int64{1, 0}), 10, N, mat([]int64{89, 55}, []int64{55, 34})},
+		{"nilpotent", mat([]int64{0, 1}, []int64{0, 0}), 2, N, mat([]int64{0, 0}, []int64{0, 0})},
+		{"1x1", mat([]int64{3}), 5, N, mat([]int64{243})},
+		{"N = 1", mat([]int64{3}), 0, big.NewInt(1), mat([]int64{0})},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Pow(tc.M, big.NewInt(tc.e), tc.N)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !equal(got, tc.want) {
+				t.Errorf("Pow() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPowProperty(t *testing.T) {
+	t.Parallel()
+
+	// M^(a+b) = M^a * M^b
+	N := big.NewInt(998244353)
+	M := mat([]int64{1, 2, 3}, []int64{4, 5, 6}, []int64{7, 8, 10})
+	err := quick.Check(func(a, b uint32) bool {
+		ea, eb := big.NewInt(int64(a)), big.NewInt(int64(b))
+		pa, _ := Pow(M, ea, N)
+		pb, _ := Pow(M, eb, N)
+		prod, _ := Mul(pa, pb, N)
+		sum, _ := Pow(M, new(big.Int).Add(ea, eb), N)
+		return equal(prod, sum)
+	}, &quick.Config{MaxCount: 50})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	N := big.NewInt(7)
+	one := big.NewInt(1)
+	sq := mat([]int64{1, 2}, []int64{3, 4})
+	tests := []struct {
+		name    string
+		run     func() error
+		wantErr error
+	}{
+		{"empty", func() error { _, err := Pow(Matrix{}, one, N); return err }, ErrNotSquare},
+		{"ragged", func() error { _, err := Pow(mat([]int64{1, 2}, []int64{3}), one, N); return err }, ErrNotSquare},
+		{"nil entry", func() error { _, err := Pow(Matrix{{nil}}, one, N); return err }, ErrNotSquare},
+		{"zero modulus", func() error { _, err := Pow(sq, one, new(big.Int)); return err }, ErrModulus},
+		{"negative exponent", func() error { _, err := Pow(sq, big.NewInt(-1), N); return err }, ErrNegativeExponent},
+		{"Mul dimension", func() error { _, err := Mul(sq, mat([]int64{1}), N); return err }, ErrDimension},
+		{"Recurrence lengths", func() error {
+			_, err := Recurrence([]*big.Int{one}, []*big.Int{one, one}, one, N)
+			return err
+		}, ErrDimension},
+		{"Recurrence empty", func() error { _, err := Recurrence(nil, nil, one, N); return err }, ErrDimension},
+		{"Recurrence nil coefficient", func() error {
+			_, err := Recurrence([]*big.Int{one, nil}, []*big.Int{one, one}, one, N)
+			return err
+		}, ErrDimension},
+		{"Recurrence nil initial term", func() error {
+			_, err := Recurrence([]*big.Int{one, one}, []*big.Int{nil, one}, one, N)
+			return err
+		}, ErrDimension},
+		{"Recurrence modulus", func() error {
+			_, err := Recurrence([]*big.Int{one}, []*big.Int{one}, one, big.NewInt(-3))
+			return err
+		}, ErrModulus},
+		{"Recurrence negative k", func() error {
+			_, err := Recurrence([]*big.Int{one}, []*big.Int{one}, big.NewInt(-1), N)
+			return err
+		}, ErrNegativeExponent},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tc.run(); !errors.Is(err, tc.wantErr) {
+				t.Errorf("error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRecurrence(t *testing.T) {
+	t.Parallel()
+
+	ints := func(xs ...int64) []*big.Int {
+		r := make([]*big.Int, len(xs))
+		for i, x := range xs {
+			r[i] = big.NewInt(x)
+		}
+		return r
+	}
+	N := big.NewInt(1_000_000_007)
+	huge, _ := new(big.Int).SetString("1000000000000000000", 10)
+
+	tests := []struct {
+		name            string
+		coeffs, initial []*big.Int
+		k               *big.Int
+		N               *big.Int
+		want            *big.Int
+	}{
+		{"Fibonacci F(0)", ints(1, 1), ints(0, 1), big.NewInt(0), N, big.NewInt(0)},
+		{"Fibonacci F(1)", ints(1, 1), ints(0, 1), big.NewInt(1), N, big.NewInt(1)},
+		{"Fibonacci F(90)", ints(1, 1), ints(0, 1), big.NewInt(90), N, fib(90, N)},
+		// F(10^18) mod 10^9+7, a well-known value
+		{"Fibonacci F(10^18)", ints(1, 1), ints(0, 1), huge, N, big.NewInt(209783453)},
+		{"Tribonacci T(10)", ints(1, 1, 1), ints(0, 0, 1), big.NewInt(10), N, big.NewInt(81)},
+		{"geometric 3^20", ints(3), ints(1), big.NewInt(20), N, new(big.Int).Exp(big.NewInt(3), big.NewInt(20), N)},
+		{"negative coefficient", ints(2, -1), ints(5, 8), big.NewInt(10), N, big.NewInt(35)},
+		{"Lucas mod 10", ints(1, 1), ints(2, 1), big.NewInt(12), big.NewInt(10), big.NewInt(2)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Recurrence(tc.coeffs, tc.initial, tc.k, tc.N)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Cmp(tc.want) != 0 {
+				t.Errorf("Recurrence() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func BenchmarkRecurrence(b *testing.B) {
+	coeffs := []*big.Int{big.NewInt(1), big.NewInt(1)}
+	initial := []*big.Int{big.NewInt(0), big.NewInt(1)}
+	k, _ := new(big.Int).SetString("1000000000000000000", 10)
+	N := big.NewInt(1_000_000_007)
+	for b.Loop() {
+		if _, err := Recurrence(coeffs, initial, k, N); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPow(b *testing.B) {
+	const n = 8
+	M := make(Matrix, n)
+	for i := range M {
+		M[i] = make([]*big.Int, n)
+		for j := range M[i] {
+			M[i][j] = big.NewInt(int64(i*n + j))
+		}
+	}
+	N, _ := new(big.Int).SetString("fffffffffffffffffffffffffffffffeffffffffffffffff", 16)
+	e := new(big.Int).Lsh(big.NewInt(1), 128)
+	for b.Loop() {
+		if _, err := Pow(M, e, N); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package montgomery
+
+import "unsafe"
+
+// Aliasing rules for the limb-level kernels
+//
+// The kernels working on limb slices (montMulWords, addMulWords,
+// mulAddScalar, ctSwap, scatter and gather) follow the math/big convention:
+//
+//   - An output may be the very same slice as an input (same first element
+//     and length) where the kernel says so; montMulWords, for example,
+//     computes z = x*y*R⁻¹ in place for z == x, z == y or z == x == y.
+//   - An output must never partially overlap an input, e.g. z = x[1:].
+//   - Scratch buffers and accumulators must not share memory with any
+//     other argument.
+//
+// montMulWords, ctSwap, scatter and gather check the rules on entry at O(1)
+// cost and panic on a violation, since a partial overlap would otherwise
+// silently produce wrong limbs. addMulWords and mulAddScalar run once per
+// word inside REDC, where even that check costs ~20%; their callers allocate
+// the accumulator themselves, so they only document the rule.
+
+// overlap reports whether x and y share at least one element, comparing
+// the address ranges the two slices cover. Disjoint rows of one table do not
+// overlap, and neither do slices whose capacity was cut with a 3-index
+// slice expression unless their elements do.
+func overlap[T any](x, y []T) bool {
+	if len(x) == 0 || len(y) == 0 {
+		return false
+	}
+	size := unsafe.Sizeof(x[0])
+	xs, ys := uintptr(unsafe.Pointer(&x[0])), uintptr(unsafe.Pointer(&y[0]))
+	return xs < ys+uintptr(len(y))*size && ys < xs+uintptr(len(x))*size
+}
+
+// same reports whether x and y are the same slice: the same first element
+// and the same length.
+func same[T any](x, y []T) bool {
+	return len(x) == len(y) && (len(x) == 0 || &x[0] == &y[0])
+}
+
+// checkInPlace panics if out shares memory with in without being the same
+// slice, the one form of aliasing an in-place kernel supports.
+func checkInPlace[T any](kernel string, out, in []T) {
+	if overlap(out, in) && !same(out, in) {
+		panic("montgomery: " + kernel + ": output partially overlaps an input")
+	}
+}
+
+// checkDisjoint panics if buf shares memory with any of others.
+func checkDisjoint[T any](kernel string, buf []T, others ...[]T) {
+	for _, o := range others {
+		if overlap(buf, o) {
+			panic("montgomery: " + kernel + ": buffer aliases another argument")
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package montgomery
+
+import (
+	"math/big"
+	"slices"
+	"testing"
+)
+
+func TestOverlap(t *testing.T) {
+	t.Parallel()
+
+	buf := make([]uint64, 10)
+	other := make([]uint64, 10)
+	tests := []struct {
+		name   string
+		x, y   []uint64
+		overlp bool
+		same   bool
+	}{
+		{"identical", buf, buf, true, true},
+		{"same start, shorter", buf[:4], buf, true, false},
+		{"shifted", buf[1:], buf, true, false},
+		{"disjoint rows", buf[0:4], buf[4:8], false, false},
+		{"adjacent rows reversed", buf[4:8], buf[0:4], false, false},
+		{"touching rows", buf[0:5], buf[4:8], true, false},
+		{"capacity limited", buf[0:4:4], buf[2:6], true, false},
+		{"capacity limited, disjoint", buf[0:2:2], buf[2:6], false, false},
+		{"different arrays", buf, other, false, false},
+		{"empty", buf[3:3], buf, false, false},
+		{"nil", nil, buf, false, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := overlap(tc.x, tc.y); got != tc.overlp {
+				t.Errorf("overlap() = %v, want %v", got, tc.overlp)
+			}
+			if got := overlap(tc.y, tc.x); got != tc.overlp {
+				t.Errorf("overlap() reversed = %v, want %v", got, tc.overlp)
+			}
+			if got := same(tc.x, tc.y); got != tc.same {
+				t.Errorf("same() = %v, want %v", got, tc.same)
+			}
+		})
+	}
+}
+
+// Test_montMulWordsInPlace checks every supported form of aliasing against
+// the out-of-place result.
+func Test_montMulWordsInPlace(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	s := m.S
+	n := limbsPadded(N, s)
+	scratch := make([]uint64, s+2)
+
+	mul := func(x, y *big.Int) *big.Int {
+		z := make([]uint64, s)
+		montMulWords(z, limbsPadded(x, s), limbsPadded(y, s), n, m.NI, scratch)
+		return tobigInt(z)
+	}
+
+	tests := []struct {
+		name string
+		run  func() []uint64
+		want *big.Int
+	}{
+		{"z == x", func() []uint64 {
+			z := limbsPadded(x, s)
+			montMulWords(z, z, limbsPadded(y, s), n, m.NI, scratch)
+			return z
+		}, mul(x, y)},
+		{"z == y", func() []uint64 {
+			z := limbsPadded(y, s)
+			montMulWords(z, limbsPadded(x, s), z, n, m.NI, scratch)
+			return z
+		}, mul(x, y)},
+		{"z == x == y", func() []uint64 {
+			z := limbsPadded(x, s)
+			montMulWords(z, z, z, n, m.NI, scratch)
+			return z
+		}, mul(x, x)},
+		{"rows of one table", func() []uint64 {
+			table := make([]uint64, 3*s)
+			limbsPaddedInto(table[:s], x)
+			limbsPaddedInto(table[s:2*s], y)
+			montMulWords(table[2*s:], table[:s], table[s:2*s], n, m.NI, scratch)
+			return table[2*s:]
+		}, mul(x, y)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tobigInt(tc.run()); got.Cmp(tc.want) != 0 {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func Test_kernelAliasPanics(t *testing.T) {
+	t.Parallel()
+
+	const s = 4
+	n := []uint64{0xfffffffffffffffb, 0, 0, 0}
+	buf := make([]uint64, 4*s+2)
+	table := make([]uint64, s<<ctWindow)
+
+	tests := []struct {
+		name string
+		run  func()
+	}{
+		{"montMulWords z shifted over x", func() { montMulWords(buf[1:s+1], buf[:s], buf[2*s:3*s], n, 5, buf[3*s:]) }},
+		{"montMulWords scratch is x", func() { montMulWords(buf[:s], buf[s:2*s], buf[s:2*s], n, 5, buf[s:]) }},
+		{"montMulWords z is n", func() { montMulWords(n, buf[:s], buf[s:2*s], n, 5, buf[2*s:]) }},
+		{"ctSwap partial", func() { ctSwap(buf[:s], buf[1:s+1], ^uint64(0)) }},
+		{"scatter", func() { scatter(table, table[:s], 0) }},
+		{"gather", func() { gather(table[:s], table, 0) }},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("no panic on overlapping arguments")
+				}
+			}()
+			tc.run()
+		})
+	}
+}
+
+func Test_ctSwapSame(t *testing.T) {
+	t.Parallel()
+
+	a := []uint64{1, 2, 3}
+	ctSwap(a, a, ^uint64(0))
+	if !slices.Equal(a, []uint64{1, 2, 3}) {
+		t.Errorf("ctSwap(a, a) = %v, want unchanged", a)
+	}
+}
+
+func Test_limbsPaddedInto(t *testing.T) {
+	t.Parallel()
+
+	dst := []uint64{9, 9, 9, 9}
+	x := new(big.Int).SetUint64(7)
+	x.Lsh(x, 64)
+	if got := limbsPaddedInto(dst, x); !slices.Equal(got, []uint64{0, 7, 0, 0}) {
+		t.Errorf("limbsPaddedInto() = %v, want [0 7 0 0]", got)
+	}
+	if !slices.Equal(limbsPaddedInto(dst, new(big.Int)), []uint64{0, 0, 0, 0}) {
+		t.Errorf("limbsPaddedInto(0) = %v, want all zero", dst)
+	}
+}
@@ -0,0 +1,82 @@
+package montgomery
+
+import (
+	"math/big"
+	"testing"
+)
+
+// allocCeiling is a hot path and the most heap allocations one call may
+// make. The ceilings are what the code does today: lowering one after an
+// optimization is welcome, raising one needs a reason in the commit.
+type allocCeiling struct {
+	name string
+	max  float64
+	f    func()
+}
+
+// checkAllocs runs each case under testing.AllocsPerRun. Allocation counts
+// are process-wide, so callers must not be parallel tests; Go runs
+// sequential top-level tests before releasing the parallel ones, so the
+// counts are not polluted by them either.
+func checkAllocs(t *testing.T, cases []allocCeiling) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := testing.AllocsPerRun(20, tc.f); got > tc.max {
+				t.Errorf("%v allocs/op, ceiling %v", got, tc.max)
+			}
+		})
+	}
+}
+
+func TestAllocs(t *testing.T) {
+	x, y, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	e := new(big.Int).Sub(N, big.NewInt(1))
+	a, b := m.ToMont(x), m.ToMont(y)
+
+	s := m.S
+	xs, ys, ns := limbsPadded(x, s), limbsPadded(y, s), limbsPadded(N, s)
+	z, scratch := make([]uint64, s), make([]uint64, s+2)
+	fb := m.NewFixedBase(x, N.BitLen(), 4)
+	bitwise := NewMontgomeryBitwise(R, N)
+	dst := m.MulInto(new(big.Int), x, y)
+	x256, y256, R256, N256 := testParamsLarge(256)
+	m256 := NewMontgomeryCIOSWords(R256, N256)
+	xw, yw, zw := m256.ToWords(x256), m256.ToWords(y256), make([]uint64, m256.S)
+	tw := limbsPadded(new(big.Int).Mul(x256, y256), 2*m256.S)
+	dst256 := m256.MulInto(new(big.Int), x256, y256)
+
+	checkAllocs(t, []allocCeiling{
+		// The limb kernel works in caller-provided memory only, and the word
+		// API keeps its scratch on the stack for small moduli
+		{"montMulWords", 0, func() { montMulWords(z, xs, ys, ns, m.NI, scratch) }},
+		{"MulWords/256", 0, func() { m256.MulWords(zw, xw, yw) }},
+		{"ReduceWords/256", 0, func() { m256.ReduceWords(zw, tw) }},
+		{"AddWords/256", 0, func() { m256.AddWords(zw, xw, yw) }},
+		{"NegWords/256", 0, func() { m256.NegWords(zw, xw) }},
+		{"MulInto/256", 0, func() { m256.MulInto(dst256, x256, y256) }},
+
+		// The result of a REDC is its own accumulator
+		{"redcBigWords", 1, func() { m.redcBigWords(x, y) }},
+		{"redcSqr", 1, func() { m.redcSqr(x) }},
+		{"MulMont", 2, func() { m.MulMont(a, b) }},
+		{"SqrMont", 1, func() { m.SqrMont(a) }},
+		{"AddMont", 2, func() { m.AddMont(a, b) }},
+
+		// Two REDCs in pooled scratch, leaving only the result, or four, or
+		// two in a destination that has already grown
+		{"Mul", 2, func() { m.Mul(x, y) }},
+		{"Mul/256", 2, func() { m256.Mul(x256, y256) }},
+		{"Mul/Bitwise", 8, func() { bitwise.Mul(x, y) }},
+		{"MulInto", 0, func() { m.MulInto(dst, x, y) }},
+
+		// Exponentiation: the accumulators are reduced in place, so what
+		// remains is the window table and the conversions; the limb-based
+		// constant-time and fixed-base paths make a fixed number
+		{"Exp", 100, func() { m.Exp(x, e) }},
+		{"Exp/65537", 14, func() { m.Exp(x, big.NewInt(65537)) }},
+		{"ExpConstantTime", 12, func() { m.ExpConstantTime(x, e) }},
+		{"FixedBase.Exp", 4, func() { fb.Exp(e) }},
+	})
+}
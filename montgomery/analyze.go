@@ -0,0 +1,196 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Reduction is a modular reduction strategy that AnalyzeModulus can
+// recommend.
+type Reduction uint8
+
+const (
+	// ReductionGeneric is division-based reduction via math/big, the only
+	// option for an even modulus.
+	ReductionGeneric Reduction = iota
+	// ReductionMontgomery is interleaved word-level CIOS (MontgomeryCIOSWords).
+	ReductionMontgomery
+	// ReductionMontgomerySeparated is product-then-reduce REDC, which
+	// MontgomeryCIOSWords switches to from separatedThreshold words on.
+	ReductionMontgomerySeparated
+)
+
+func (r Reduction) String() string {
+	switch r {
+	case ReductionGeneric:
+		return "generic"
+	case ReductionMontgomery:
+		return "montgomery"
+	case ReductionMontgomerySeparated:
+		return "montgomery-separated"
+	}
+	return fmt.Sprintf("Reduction(%d)", uint8(r))
+}
+
+// smallFactorBound is the trial-division limit of AnalyzeModulus.
+const smallFactorBound = 1 << 10
+
+// nttFriendlyAdicity is the 2-adicity of N-1 from which AnalyzeModulus
+// reports a prime as NTT-friendly: it then has roots of unity of order
+// 2^20, enough for transforms of a million points.
+const nttFriendlyAdicity = 20
+
+// ModulusReport describes the exploitable structure of a modulus.
+type ModulusReport struct {
+	Bits int // bit length of N
+	// Words is the number of 64-bit words of the Montgomery radix R.
+	Words int
+
+	// PseudoMersenne reports N = 2^Bits - C with C of at most one word and
+	// half the bits of N, the Crandall form whose reduction folds the high
+	// part in times C: the moduli solinas.NewPseudoMersenne accepts.
+	PseudoMersenne bool
+	C              *big.Int
+
+	// TwoAdicity is the largest v with 2^v | N-1. For a prime N, Z_N has
+	// roots of unity of order 2^v, the limit on power-of-two NTT lengths.
+	TwoAdicity  uint
+	NTTFriendly bool
+
+	// SmallFactors lists the primes below 2^10 dividing N, in increasing order.
+	SmallFactors []uint64
+	Prime        bool // N is prime with probability 1 - 4^-20
+	SafePrime    bool // N and (N-1)/2 are both prime
+
+	// NI is -N⁻¹ mod 2^64, the per-word Montgomery constant. When it is 1
+	// (N ≡ -1 mod 2^64) every REDC step skips one multiplication.
+	NI                 uint64
+	MontgomeryFriendly bool
+
+	// Recommended is the best reduction strategy the vault implements for
+	// N, and Backend the registry name that Open needs for it.
+	Recommended Reduction
+	Backend     string
+	// Notes explains the recommendation and points out structure the
+	// vault has no specialized reduction for.
+	Notes []string
+}
+
+// AnalyzeModulus reports the structure of N > 1 relevant to choosing a
+// reduction algorithm and recommends one. The primality checks are
+// probabilistic and dominate the cost for large N.
+func AnalyzeModulus(N *big.Int) ModulusReport {
+	if N.Cmp(big.NewInt(1)) <= 0 {
+		panic("montgomery: AnalyzeModulus requires N > 1")
+	}
+	r := ModulusReport{
+		Bits:  N.BitLen(),
+		Words: (N.BitLen() + 63) / 64,
+	}
+
+	c := new(big.Int).Lsh(big.NewInt(1), uint(r.Bits))
+	c.Sub(c, N)
+	if c.BitLen() <= min(64, r.Bits/2) {
+		r.PseudoMersenne, r.C = true, c
+	}
+
+	nm1 := new(big.Int).Sub(N, big.NewInt(1))
+	if nm1.Sign() > 0 {
+		r.TwoAdicity = nm1.TrailingZeroBits()
+	}
+
+	for _, p := range smallPrimes(smallFactorBound) {
+		if new(big.Int).Mod(N, new(big.Int).SetUint64(p)).Sign() == 0 {
+			r.SmallFactors = append(r.SmallFactors, p)
+		}
+	}
+
+	r.Prime = N.ProbablyPrime(20)
+	if r.Prime && N.Bit(0) == 1 {
+		r.SafePrime = new(big.Int).Rsh(N, 1).ProbablyPrime(20)
+	}
+	r.NTTFriendly = r.Prime && r.TwoAdicity >= nttFriendlyAdicity
+
+	switch {
+	case N.Bit(0) == 0:
+		r.Recommended = ReductionGeneric
+		r.Notes = append(r.Notes, "N is even, so Montgomery reduction applies only to its odd part, as in EvenCtx")
+		return r
+	case r.Words >= separatedThreshold:
+		r.Recommended = ReductionMontgomerySeparated
+		r.Notes = append(r.Notes, fmt.Sprintf("%d words is past the separated REDC threshold of %d", r.Words, separatedThreshold))
+	default:
+		r.Recommended = ReductionMontgomery
+	}
+	r.Backend = "cioswords"
+
+	r.NI = newtonRaphsonInverse(N.Uint64())
+	r.MontgomeryFriendly = r.NI == 1
+	if r.MontgomeryFriendly {
+		r.Notes = append(r.Notes, "N ≡ -1 mod 2^64: the REDC quotient digit is the low word itself")
+	}
+	if r.PseudoMersenne {
+		r.Notes = append(r.Notes, fmt.Sprintf("N = 2^%d - %v: solinas.NewPseudoMersenne can reduce it without Montgomery form", r.Bits, r.C))
+	}
+	if len(r.SmallFactors) > 0 {
+		r.Notes = append(r.Notes, fmt.Sprintf("N has small factors %v; working modulo each factor (CRT) may be cheaper", r.SmallFactors))
+	}
+	return r
+}
+
+// String renders the report one property per line.
+func (r ModulusReport) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "bits: %d (%d words)\n", r.Bits, r.Words)
+	fmt.Fprintf(&sb, "prime: %v, safe prime: %v\n", r.Prime, r.SafePrime)
+	fmt.Fprintf(&sb, "2-adicity of N-1: %d (NTT-friendly: %v)\n", r.TwoAdicity, r.NTTFriendly)
+	if r.PseudoMersenne {
+		fmt.Fprintf(&sb, "pseudo-Mersenne: 2^%d - %v\n", r.Bits, r.C)
+	}
+	if len(r.SmallFactors) > 0 {
+		fmt.Fprintf(&sb, "small factors: %v\n", r.SmallFactors)
+	}
+	if r.Recommended != ReductionGeneric {
+		fmt.Fprintf(&sb, "NI: %#x (Montgomery-friendly: %v)\n", r.NI, r.MontgomeryFriendly)
+	}
+	fmt.Fprintf(&sb, "recommended: %v\n", r.Recommended)
+	for _, note := range r.Notes {
+		fmt.Fprintf(&sb, "  - %s\n", note)
+	}
+	return sb.String()
+}
+
+// OpenFor analyzes N and opens the recommended backend and reduction with
+// the smallest word-aligned R greater than N, or of at least 4N with
+// WithAlmostMontgomery. opts are applied after the recommendation, so an
+// explicit WithBackend or WithReduction still wins. An even N is opened as
+// an EvenCtx, with the recommendation for its odd part.
+func OpenFor(N *big.Int, opts ...Option) (ModMultiplier, error) {
+	if N.Cmp(big.NewInt(1)) <= 0 {
+		return nil, ErrInvalidParameters
+	}
+	R := wordAlignedR(N, opts)
+	q := new(big.Int).Rsh(N, N.TrailingZeroBits())
+	if q.Cmp(big.NewInt(1)) == 0 {
+		return Open(R, N, opts...)
+	}
+	r := AnalyzeModulus(q)
+	return Open(R, N, append([]Option{WithBackend(r.Backend), WithReduction(r.Recommended)}, opts...)...)
+}
+
+// smallPrimes returns the primes below bound by the sieve of Eratosthenes.
+func smallPrimes(bound int) []uint64 {
+	composite := make([]bool, bound)
+	var primes []uint64
+	for i := 2; i < bound; i++ {
+		if composite[i] {
+			continue
+		}
+		primes = append(primes, uint64(i))
+		for j := i * i; j < bound; j += i {
+			composite[j] = true
+		}
+	}
+	return primes
+}
@@ -0,0 +1,126 @@
+package montgomery
+
+import (
+	"math/big"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeModulus(t *testing.T) {
+	t.Parallel()
+
+	pow2 := func(k uint) *big.Int { return new(big.Int).Lsh(big.NewInt(1), k) }
+	sub := func(x *big.Int, c int64) *big.Int { return new(big.Int).Sub(x, big.NewInt(c)) }
+	// Goldilocks 2^64 - 2^32 + 1
+	goldilocks := new(big.Int).Add(sub(pow2(64), 1<<32), big.NewInt(1))
+
+	tests := []struct {
+		name         string
+		N            *big.Int
+		prime, safe  bool
+		pseudo       int64 // C, or 0 if not pseudo-Mersenne
+		adicity      uint
+		ntt          bool
+		smallFactors []uint64
+		friendly     bool
+		want         Reduction
+	}{
+		{name: "curve25519", N: sub(pow2(255), 19), prime: true, pseudo: 19, adicity: 2, want: ReductionMontgomery},
+		{name: "Mersenne 2^127-1", N: sub(pow2(127), 1), prime: true, pseudo: 1, adicity: 1, friendly: true, want: ReductionMontgomery},
+		{name: "Goldilocks", N: goldilocks, prime: true, pseudo: 1<<32 - 1, adicity: 32, ntt: true, want: ReductionMontgomery},
+		{name: "safe prime 23", N: big.NewInt(23), prime: true, safe: true, adicity: 1, smallFactors: []uint64{23}, want: ReductionMontgomery},
+		{name: "small factors", N: new(big.Int).Mul(big.NewInt(3*5*1009), sub(pow2(127), 1)), adicity: 5, smallFactors: []uint64{3, 5, 1009}, want: ReductionMontgomery},
+		{name: "even", N: pow2(10), smallFactors: []uint64{2}, want: ReductionGeneric},
+		{name: "8192-bit", N: sub(pow2(8192), 3), pseudo: 3, adicity: 2, smallFactors: []uint64{23}, want: ReductionMontgomerySeparated},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			r := AnalyzeModulus(tc.N)
+			if r.Prime != tc.prime || r.SafePrime != tc.safe {
+				t.Errorf("Prime, SafePrime = %v, %v; want %v, %v", r.Prime, r.SafePrime, tc.prime, tc.safe)
+			}
+			if r.PseudoMersenne != (tc.pseudo != 0) || (r.PseudoMersenne && r.C.Int64() != tc.pseudo) {
+				t.Errorf("PseudoMersenne, C = %v, %v; want C = %d", r.PseudoMersenne, r.C, tc.pseudo)
+			}
+			if r.TwoAdicity != tc.adicity || r.NTTFriendly != tc.ntt {
+				t.Errorf("TwoAdicity, NTTFriendly = %d, %v; want %d, %v", r.TwoAdicity, r.NTTFriendly, tc.adicity, tc.ntt)
+			}
+			if !slices.Equal(r.SmallFactors, tc.smallFactors) {
+				t.Errorf("SmallFactors = %v, want %v", r.SmallFactors, tc.smallFactors)
+			}
+			if r.MontgomeryFriendly != tc.friendly {
+				t.Errorf("MontgomeryFriendly = %v (NI %#x), want %v", r.MontgomeryFriendly, r.NI, tc.friendly)
+			}
+			if r.Recommended != tc.want {
+				t.Errorf("Recommended = %v, want %v", r.Recommended, tc.want)
+			}
+			if s := r.String(); !strings.Contains(s, "recommended: "+tc.want.String()) {
+				t.Errorf("String() = %q", s)
+			}
+		})
+	}
+}
+
+func TestOpenFor(t *testing.T) {
+	t.Parallel()
+
+	x, y, _, N := testParams2048()
+	m, err := OpenFor(N)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := new(big.Int).Mul(x, y)
+	if got := m.Mul(x, y); got.Cmp(want.Mod(want, N)) != 0 {
+		t.Errorf("Mul() = %v, want %v", got, want)
+	}
+	if _, ok := m.(*MontgomeryCIOSWords); !ok {
+		t.Errorf("OpenFor() = %T, want *MontgomeryCIOSWords", m)
+	}
+
+	m, err = OpenFor(N, WithBackend("cios"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.(*MontgomeryCIOS); !ok {
+		t.Errorf("OpenFor(WithBackend(cios)) = %T, want *MontgomeryCIOS", m)
+	}
+
+	for _, even := range []*big.Int{big.NewInt(1 << 10), new(big.Int).Lsh(N, 3)} {
+		m, err := OpenFor(even)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := m.(*EvenCtx); !ok {
+			t.Errorf("OpenFor(%v) = %T, want *EvenCtx", even, m)
+		}
+		a, b := new(big.Int).Mod(x, even), new(big.Int).Mod(y, even)
+		want := new(big.Int).Mul(a, b)
+		if got := m.Mul(a, b); got.Cmp(want.Mod(want, even)) != 0 {
+			t.Errorf("OpenFor(%v).Mul() = %v, want %v", even, got, want)
+		}
+	}
+	if _, err := OpenFor(big.NewInt(1)); err != ErrInvalidParameters {
+		t.Errorf("OpenFor(1) error = %v, want %v", err, ErrInvalidParameters)
+	}
+}
+
+func Test_smallPrimes(t *testing.T) {
+	t.Parallel()
+
+	if got, want := smallPrimes(30), []uint64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29}; !slices.Equal(got, want) {
+		t.Errorf("smallPrimes(30) = %v, want %v", got, want)
+	}
+	if got := len(smallPrimes(smallFactorBound)); got != 172 {
+		t.Errorf("len(smallPrimes(%d)) = %d, want 172", smallFactorBound, got)
+	}
+}
+
+func BenchmarkAnalyzeModulus(b *testing.B) {
+	_, _, _, N := testParams2048()
+	for b.Loop() {
+		AnalyzeModulus(N)
+	}
+}
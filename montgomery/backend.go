@@ -0,0 +1,250 @@
+package montgomery
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"sync"
+)
+
+// ModMultiplier is modular multiplication with a fixed odd modulus. All
+// the Montgomery implementations satisfy it, and out-of-tree backends
+// registered with Register must too.
+type ModMultiplier interface {
+	// Mul returns (x * y) mod N for x, y in [0, N).
+	Mul(x, y *big.Int) *big.Int
+	// Modulus returns N.
+	Modulus() *big.Int
+}
+
+// Backend constructs a ModMultiplier for R = 2^k and an odd modulus N < R.
+// Options not understood by a backend are ignored.
+type Backend func(R, N *big.Int, opts ...Option) (ModMultiplier, error)
+
+// BackendEnv is the environment variable Open consults when no WithBackend
+// option is given.
+const BackendEnv = "MONTGOMERY_BACKEND"
+
+// DefaultBackend is the backend Open uses when neither WithBackend nor
+// BackendEnv selects one.
+const DefaultBackend = "cioswords"
+
+// ErrUnknownBackend is returned by Open for a name that was never registered.
+var ErrUnknownBackend = errors.New("montgomery: unknown backend")
+
+var (
+	backendsMu sync.RWMutex
+	backends   = make(map[string]Backend)
+)
+
+func init() {
+	Register("bitwise", func(R, N *big.Int, opts ...Option) (ModMultiplier, error) {
+		return NewMontgomeryBitwiseChecked(R, N, opts...)
+	})
+	Register("cios", func(R, N *big.Int, opts ...Option) (ModMultiplier, error) {
+		return NewMontgomeryCIOSChecked(R, N, opts...)
+	})
+	Register("cioswords", func(R, N *big.Int, opts ...Option) (ModMultiplier, error) {
+		return NewMontgomeryCIOSWordsChecked(R, N, opts...)
+	})
+	Register("ct", func(R, N *big.Int, opts ...Option) (ModMultiplier, error) {
+		return NewMontgomeryCTChecked(R, N, opts...)
+	})
+	Register("carrysave", func(R, N *big.Int, opts ...Option) (ModMultiplier, error) {
+		return NewMontgomeryCarrySaveChecked(R, N, opts...)
+	})
+	Register("rns", func(R, N *big.Int, opts ...Option) (ModMultiplier, error) {
+		base, err := RNSBaseFor(N)
+		if err != nil {
+			return nil, err
+		}
+		return NewRNSMontgomery(base, N, opts...)
+	})
+	for _, method := range scanMethods {
+		Register(method.String(), func(R, N *big.Int, opts ...Option) (ModMultiplier, error) {
+			return NewMontgomeryScanChecked(R, N, method, opts...)
+		})
+	}
+}
+
+// Register makes a backend available to Open under name, in the manner of
+// database/sql drivers: an assembly, cgo, GPU or WASM-host implementation
+// registers itself from its package's init function, and the user selects
+// it by importing that package for its side effect. This package never
+// imports a backend.
+//
+// Register panics if name is empty, b is nil, or name is already registered.
+func Register(name string, b Backend) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	if name == "" {
+		panic("montgomery: Register with empty backend name")
+	}
+	if b == nil {
+		panic("montgomery: Register backend is nil")
+	}
+	if _, dup := backends[name]; dup {
+		panic("montgomery: Register called twice for backend " + name)
+	}
+	backends[name] = b
+}
+
+// Backends returns the sorted names of the registered backends.
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// WithBackend selects the backend Open constructs, overriding BackendEnv.
+// Constructors other than Open ignore it.
+func WithBackend(name string) Option {
+	return func(c *config) {
+		c.backend = name
+	}
+}
+
+// Open constructs a ModMultiplier from the backend named by WithBackend, or
+// else by the BackendEnv environment variable, or else DefaultBackend. All
+// opts, including WithBackend, are passed on to the backend.
+//
+// Open rejects R and N that no implementation accepts with an error
+// wrapping ErrInvalidParameters; backends may add their own requirements,
+// as the word-based built-ins do with ErrRNotWordAligned.
+//
+// An even N ≥ 2 is split as for EvenCtx, which Open returns with the odd
+// part built by the backend.
+func Open(R, N *big.Int, opts ...Option) (ModMultiplier, error) {
+	even := N != nil && N.Cmp(big.NewInt(1)) > 0 && N.Bit(0) == 0
+	vN := N
+	if even {
+		// N+1 is odd, and a power of two exceeds it exactly when it
+		// exceeds N
+		vN = new(big.Int).Add(N, big.NewInt(1))
+	}
+	if err := validateParams(R, vN, false); err != nil {
+		return nil, err
+	}
+
+	name := newConfig(opts).backend
+	if name == "" {
+		name = os.Getenv(BackendEnv)
+	}
+	if name == "" {
+		name = DefaultBackend
+	}
+
+	backendsMu.RLock()
+	b, ok := backends[name]
+	backendsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownBackend, name)
+	}
+	opts, cfg := withoutSelfTest(opts)
+	var m ModMultiplier
+	var err error
+	if even {
+		m, err = openEven(R, N, b, opts)
+	} else {
+		m, err = b(R, N, opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := selfTest(m, cfg); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Kind names one of the built-in implementations for New.
+type Kind uint8
+
+const (
+	KindBitwise   Kind = iota // MontgomeryBitwise
+	KindCIOS                  // MontgomeryCIOS
+	KindCIOSWords             // MontgomeryCIOSWords
+	KindCT                    // MontgomeryCT
+)
+
+// String returns the backend name the kind is registered under.
+func (k Kind) String() string {
+	switch k {
+	case KindBitwise:
+		return "bitwise"
+	case KindCIOS:
+		return "cios"
+	case KindCIOSWords:
+		return "cioswords"
+	case KindCT:
+		return "ct"
+	}
+	return fmt.Sprintf("Kind(%d)", uint8(k))
+}
+
+// New constructs the built-in implementation kind for N, with the smallest
+// word-aligned R greater than N (at least 4N with WithAlmostMontgomery), so
+// that implementations can be swapped by changing one argument. It is Open
+// with R chosen and the backend fixed: an even N gets an EvenCtx around
+// kind, invalid N is rejected with an error wrapping ErrInvalidParameters,
+// a WithBackend among opts is overridden, and an unknown kind returns
+// ErrUnknownBackend.
+func New(kind Kind, N *big.Int, opts ...Option) (ModMultiplier, error) {
+	return Open(wordAlignedR(N, opts), N, append(slices.Clip(opts), WithBackend(kind.String()))...)
+}
+
+// Modulus returns N.
+func (m *MontgomeryBitwise) Modulus() *big.Int { return new(big.Int).Set(m.N) }
+
+// Modulus returns N.
+func (m *MontgomeryCIOS) Modulus() *big.Int { return new(big.Int).Set(m.N) }
+
+// Modulus returns N.
+func (m *MontgomeryCIOSWords) Modulus() *big.Int { return new(big.Int).Set(m.N) }
+
+// The powers of R mod N that field code built on a context needs: R mod N
+// is the Montgomery form of 1, R² mod N brings a value into Montgomery form
+// with one REDC, and R³ mod N does the same for a value computed outside
+// that carries R⁻¹, such as the plain inverse of a Montgomery-form value:
+// REDC((a·R)⁻¹, R³) = a⁻¹·R. All are computed once at construction; the
+// accessors return copies.
+
+// RModN returns R mod N.
+func (m *MontgomeryBitwise) RModN() *big.Int { return new(big.Int).Set(m.r1) }
+
+// R2ModN returns R² mod N.
+func (m *MontgomeryBitwise) R2ModN() *big.Int { return new(big.Int).Set(m.RR) }
+
+// R3ModN returns R³ mod N.
+func (m *MontgomeryBitwise) R3ModN() *big.Int { return new(big.Int).Set(m.r3) }
+
+// RModN returns R mod N.
+func (m *MontgomeryCIOS) RModN() *big.Int { return new(big.Int).Set(m.r1) }
+
+// R2ModN returns R² mod N.
+func (m *MontgomeryCIOS) R2ModN() *big.Int { return new(big.Int).Set(m.RR) }
+
+// R3ModN returns R³ mod N.
+func (m *MontgomeryCIOS) R3ModN() *big.Int { return new(big.Int).Set(m.r3) }
+
+// RModN returns R mod N.
+func (m *MontgomeryCIOSWords) RModN() *big.Int { return new(big.Int).Set(m.r1) }
+
+// R2ModN returns R² mod N.
+func (m *MontgomeryCIOSWords) R2ModN() *big.Int { return new(big.Int).Set(m.RR) }
+
+// R3ModN returns R³ mod N.
+func (m *MontgomeryCIOSWords) R3ModN() *big.Int { return new(big.Int).Set(m.r3) }
+
+var (
+	_ ModMultiplier = (*MontgomeryBitwise)(nil)
+	_ ModMultiplier = (*MontgomeryCIOS)(nil)
+	_ ModMultiplier = (*MontgomeryCIOSWords)(nil)
+)
@@ -0,0 +1,265 @@
+package montgomery
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// countingBackend is an out-of-tree style backend that wraps CIOSWords.
+type countingBackend struct {
+	*MontgomeryCIOSWords
+	calls int
+}
+
+func (c *countingBackend) Mul(x, y *big.Int) *big.Int {
+	c.calls++
+	return c.MontgomeryCIOSWords.Mul(x, y)
+}
+
+func init() {
+	Register("test-counting", func(R, N *big.Int, opts ...Option) (ModMultiplier, error) {
+		return &countingBackend{MontgomeryCIOSWords: NewMontgomeryCIOSWords(R, N, opts...)}, nil
+	})
+	Register("test-failing", func(R, N *big.Int, opts ...Option) (ModMultiplier, error) {
+		return nil, errTestBackend
+	})
+	// An out-of-tree backend cannot read options, so only Open's self-test
+	// catches its bug
+	Register("test-faulty", func(R, N *big.Int, opts ...Option) (ModMultiplier, error) {
+		return brokenMul{NewMontgomeryCIOSWords(R, N)}, nil
+	})
+}
+
+var errTestBackend = errors.New("test backend unavailable")
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	want := new(big.Int).Mul(x, y)
+	want.Mod(want, N)
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		R, N    *big.Int
+		wantErr error
+	}{
+		{name: "bitwise", opts: []Option{WithBackend("bitwise")}, R: R, N: N},
+		{name: "cios", opts: []Option{WithBackend("cios")}, R: R, N: N},
+		{name: "cioswords", opts: []Option{WithBackend("cioswords")}, R: R, N: N},
+		{name: "ct", opts: []Option{WithBackend("ct")}, R: R, N: N},
+		{name: "carrysave", opts: []Option{WithBackend("carrysave")}, R: R, N: N},
+		{name: "fips", opts: []Option{WithBackend("fips")}, R: R, N: N},
+		{name: "rns", opts: []Option{WithBackend("rns")}, R: R, N: N},
+		{name: "registered", opts: []Option{WithBackend("test-counting")}, R: R, N: N},
+		{name: "with other options", opts: []Option{WithBackend("cios"), WithMemoryBudget(1 << 10)}, R: R, N: N},
+		{name: "unknown", opts: []Option{WithBackend("gpu")}, R: R, N: N, wantErr: ErrUnknownBackend},
+		{name: "backend error", opts: []Option{WithBackend("test-failing")}, R: R, N: N, wantErr: errTestBackend},
+		{name: "R <= even N", R: big.NewInt(8), N: big.NewInt(8), wantErr: ErrInvalidParameters},
+		{name: "N = 1", R: R, N: big.NewInt(1), wantErr: ErrInvalidParameters},
+		{name: "R not a power of two", R: new(big.Int).Add(R, big.NewInt(2)), N: N, wantErr: ErrInvalidParameters},
+		{name: "R <= N", R: big.NewInt(8), N: big.NewInt(9), wantErr: ErrInvalidParameters},
+		{name: "R not word aligned", opts: []Option{WithBackend("cios")}, R: new(big.Int).Lsh(R, 1), N: N, wantErr: ErrRNotWordAligned},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			m, err := Open(tc.R, tc.N, tc.opts...)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Open() error = %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := m.Mul(x, y); got.Cmp(want) != 0 {
+				t.Errorf("Mul() = %v, want %v", got, want)
+			}
+			if m.Modulus().Cmp(N) != 0 {
+				t.Errorf("Modulus() = %v, want %v", m.Modulus(), N)
+			}
+		})
+	}
+}
+
+// TestOpen_env cannot run in parallel because it sets the environment.
+func TestOpen_env(t *testing.T) {
+	_, _, R, N := testParams2048()
+
+	t.Setenv(BackendEnv, "test-counting")
+	m, err := Open(R, N)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.(*countingBackend); !ok {
+		t.Errorf("Open() with %s set = %T, want *countingBackend", BackendEnv, m)
+	}
+
+	// The option takes precedence over the environment
+	m, err = Open(R, N, WithBackend("cios"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.(*MontgomeryCIOS); !ok {
+		t.Errorf("Open(WithBackend(cios)) = %T, want *MontgomeryCIOS", m)
+	}
+
+	t.Setenv(BackendEnv, "")
+	m, err = Open(R, N)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.(*MontgomeryCIOSWords); !ok {
+		t.Errorf("Open() by default = %T, want *MontgomeryCIOSWords", m)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	x, y, _, N := testParams2048()
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+
+	tests := []struct {
+		name     string
+		kind     Kind
+		N        *big.Int
+		opts     []Option
+		wantType ModMultiplier
+		wantErr  error
+	}{
+		{name: "bitwise", kind: KindBitwise, N: N, wantType: (*MontgomeryBitwise)(nil)},
+		{name: "cios", kind: KindCIOS, N: N, wantType: (*MontgomeryCIOS)(nil)},
+		{name: "cioswords", kind: KindCIOSWords, N: N, wantType: (*MontgomeryCIOSWords)(nil)},
+		{name: "ct", kind: KindCT, N: N, wantType: (*MontgomeryCT)(nil)},
+		{name: "one-word N", kind: KindCIOSWords, N: N64, wantType: (*MontgomeryCIOSWords)(nil)},
+		{name: "kind overrides WithBackend", kind: KindCIOS, N: N, opts: []Option{WithBackend("bitwise")}, wantType: (*MontgomeryCIOS)(nil)},
+		{name: "unknown kind", kind: Kind(99), N: N, wantErr: ErrUnknownBackend},
+		{name: "even N", kind: KindCIOS, N: new(big.Int).Add(N, big.NewInt(1)), wantType: (*EvenCtx)(nil)},
+		{name: "N = 1", kind: KindCIOS, N: big.NewInt(1), wantErr: ErrModulusTooSmall},
+		{name: "negative N", kind: KindCIOS, N: big.NewInt(-7), wantErr: ErrModulusTooSmall},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			m, err := New(tc.kind, tc.N, tc.opts...)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("New() error = %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if reflect.TypeOf(m) != reflect.TypeOf(tc.wantType) {
+				t.Errorf("New() = %T, want %T", m, tc.wantType)
+			}
+			if m.Modulus().Cmp(tc.N) != 0 {
+				t.Errorf("Modulus() = %v, want %v", m.Modulus(), tc.N)
+			}
+			a, b := new(big.Int).Mod(x, tc.N), new(big.Int).Mod(y, tc.N)
+			w := new(big.Int).Mul(a, b)
+			if got := m.Mul(a, b); got.Cmp(w.Mod(w, tc.N)) != 0 {
+				t.Errorf("Mul() = %v, want %v", got, w)
+			}
+		})
+	}
+}
+
+func TestRPowers(t *testing.T) {
+	t.Parallel()
+
+	type rPowers interface {
+		RModN() *big.Int
+		R2ModN() *big.Int
+		R3ModN() *big.Int
+	}
+	for _, bits := range []int{64, 256, 2048} {
+		_, _, R, N := testParamsLarge(bits)
+		loaded := new(MontgomeryCIOSWords)
+		data, err := NewMontgomeryCIOSWords(R, N).MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := loaded.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		for name, m := range map[string]rPowers{
+			"bitwise":   NewMontgomeryBitwise(R, N),
+			"cios":      NewMontgomeryCIOS(R, N),
+			"cioswords": NewMontgomeryCIOSWords(R, N),
+			"ct":        NewMontgomeryCT(R, N),
+			"loaded":    loaded,
+		} {
+			for i, got := range []*big.Int{m.RModN(), m.R2ModN(), m.R3ModN()} {
+				want := new(big.Int).Exp(R, big.NewInt(int64(i+1)), N)
+				if got.Cmp(want) != 0 {
+					t.Errorf("%d bits/%s: R^%d mod N = %v, want %v", bits, name, i+1, got, want)
+				}
+				// the accessors return copies
+				got.SetInt64(3)
+			}
+			if m.RModN().Cmp(new(big.Int).Mod(R, N)) != 0 {
+				t.Errorf("%d bits/%s: RModN changed after modifying its result", bits, name)
+			}
+		}
+
+		// R³ brings the plain inverse of a Montgomery-form value back into
+		// Montgomery form with one REDC
+		m := NewMontgomeryCIOSWords(R, N)
+		a := new(big.Int).Sub(N, big.NewInt(2))
+		aMont := new(big.Int).Mod(new(big.Int).Mul(a, R), N)
+		z := m.ToWords(new(big.Int).ModInverse(aMont, N))
+		m.MulMontWords(z, z, m.ToWords(m.R3ModN()))
+		want := new(big.Int).ModInverse(a, N)
+		want.Mod(want.Mul(want, R), N)
+		if got := m.FromWords(z); got.Cmp(want) != 0 {
+			t.Errorf("%d bits: REDC((aR)⁻¹, R³) = %v, want %v", bits, got, want)
+		}
+	}
+}
+
+func TestKind_String(t *testing.T) {
+	t.Parallel()
+
+	// Every kind names a registered backend
+	for _, k := range []Kind{KindBitwise, KindCIOS, KindCIOSWords, KindCT} {
+		if !slices.Contains(Backends(), k.String()) {
+			t.Errorf("%v is not a registered backend", k)
+		}
+	}
+	if got := Kind(99).String(); got != "Kind(99)" {
+		t.Errorf("Kind(99).String() = %q", got)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	got := Backends()
+	for _, name := range []string{"bitwise", "carrysave", "cihs", "cios", "cioswords", "ct", "fios", "fips", "rns", "scan-cios", "sos", "test-counting"} {
+		if !slices.Contains(got, name) {
+			t.Errorf("Backends() = %v, missing %q", got, name)
+		}
+	}
+	if !slices.IsSorted(got) {
+		t.Errorf("Backends() = %v, want sorted", got)
+	}
+
+	mustPanic := func(name string, b Backend) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Register(%q) did not panic", name)
+			}
+		}()
+		Register(name, b)
+	}
+	noop := func(R, N *big.Int, opts ...Option) (ModMultiplier, error) { return nil, nil }
+	mustPanic("cios", noop)
+	mustPanic("", noop)
+	mustPanic("test-nil", nil)
+}
@@ -0,0 +1,54 @@
+package montgomery
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// MulBatch computes x·y mod N for every pair in pairs using bit-by-bit
+// Montgomery reduction. See mulBatch for details.
+func (m *MontgomeryBitwise) MulBatch(pairs [][2]*big.Int) []*big.Int {
+	return mulBatch(m.engine(), pairs)
+}
+
+// MulBatch computes x·y mod N for every pair in pairs using CIOS
+// Montgomery reduction. See mulBatch for details.
+func (m *MontgomeryCIOS) MulBatch(pairs [][2]*big.Int) []*big.Int {
+	return mulBatch(m.engine(), pairs)
+}
+
+// MulBatch computes x·y mod N for every pair in pairs using CIOS
+// Montgomery reduction on []uint64 words. See mulBatch for details.
+func (m *MontgomeryCIOSWords) MulBatch(pairs [][2]*big.Int) []*big.Int {
+	return mulBatch(m.engine(), pairs)
+}
+
+// mulBatch multiplies many pairs under one modulus, the shape of batch
+// verification, where the per-call overhead of Mul adds up.
+//
+// Each product takes the two REDCs of MulInto instead of the four of Mul,
+// since REDC(REDC(x, y), R²) = x·y never leaves plain form, and both run in
+// one working big.Int shared by the whole batch. The results are then
+// carved out of a single array of len(pairs)·S words, so the batch
+// allocates a fixed handful of times however long it is. Each result has
+// the capacity of its own slot only: growing one reallocates it rather
+// than overwriting its neighbour. Operands outside [0, N) are handled by
+// the context's InputPolicy.
+func mulBatch(eng engine, pairs [][2]*big.Int) []*big.Int {
+	words := int(eng.k+bits.UintSize-1) / bits.UintSize
+	slab := make([]big.Word, len(pairs)*words)
+	vals := make([]big.Int, len(pairs))
+	out := make([]*big.Int, len(pairs))
+
+	z := new(big.Int)
+	for i, p := range pairs {
+		x := eng.input.mustOperand(eng.n, "MulBatch", p[0])
+		y := eng.input.mustOperand(eng.n, "MulBatch", p[1])
+		z = eng.redcInto(z, x, y)
+		z = eng.redcInto(z, z, eng.rr)
+
+		slot := slab[i*words : (i+1)*words : (i+1)*words]
+		out[i] = vals[i].SetBits(slot[:copy(slot, z.Bits())])
+	}
+	return out
+}
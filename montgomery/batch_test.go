@@ -0,0 +1,140 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestMulBatch(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	nMinus1 := new(big.Int).Sub(N, big.NewInt(1))
+	mul := func(x, y *big.Int) *big.Int {
+		z := new(big.Int).Mul(x, y)
+		return z.Mod(z, N)
+	}
+	impls := map[string]func([][2]*big.Int) []*big.Int{
+		"Bitwise":   NewMontgomeryBitwise(R, N).MulBatch,
+		"CIOS":      NewMontgomeryCIOS(R, N).MulBatch,
+		"CIOSWords": NewMontgomeryCIOSWords(R, N).MulBatch,
+	}
+
+	tests := []struct {
+		name  string
+		pairs [][2]*big.Int
+	}{
+		{"empty", nil},
+		{"one pair", [][2]*big.Int{{x, y}}},
+		{"several", [][2]*big.Int{{x, y}, {y, x}, {x, x}, {nMinus1, nMinus1}}},
+		{"zero and one", [][2]*big.Int{{big.NewInt(0), x}, {big.NewInt(1), y}}},
+		{"same operand twice", [][2]*big.Int{{x, x}, {x, x}}},
+		{"unreduced", [][2]*big.Int{{new(big.Int).Add(x, N), new(big.Int).Neg(y)}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			for name, mulBatch := range impls {
+				got := mulBatch(tc.pairs)
+				if len(got) != len(tc.pairs) {
+					t.Fatalf("%s: MulBatch() returned %d results, want %d", name, len(got), len(tc.pairs))
+				}
+				for i, p := range tc.pairs {
+					if want := mul(p[0], p[1]); got[i].Cmp(want) != 0 {
+						t.Errorf("%s: MulBatch()[%d] = %v, want %v", name, i, got[i], want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMulBatch_independentResults(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	got := m.MulBatch([][2]*big.Int{{x, y}, {y, y}})
+	want1 := new(big.Int).Set(got[1])
+
+	// Growing a result past its slot must not spill into the next one
+	got[0].Lsh(got[0], 4096)
+	got[0].Add(got[0], got[0])
+	if got[1].Cmp(want1) != 0 {
+		t.Error("modifying one MulBatch result changed another")
+	}
+}
+
+func TestMulBatch_strict(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N, WithInputPolicy(InputStrict))
+	if !panics(func() { m.MulBatch([][2]*big.Int{{x, y}, {N, y}}) }) {
+		t.Error("MulBatch did not panic with ErrOperandRange for an operand equal to N")
+	}
+}
+
+func TestMulBatchProperty(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParamsLarge(256)
+	m := NewMontgomeryCIOSWords(R, N)
+	err := quick.Check(func(xs, ys [8][]byte) bool {
+		pairs := make([][2]*big.Int, len(xs))
+		for i := range pairs {
+			pairs[i] = [2]*big.Int{new(big.Int).SetBytes(xs[i]), new(big.Int).SetBytes(ys[i])}
+		}
+		got := m.MulBatch(pairs)
+		for i, p := range pairs {
+			if got[i].Cmp(m.Mul(p[0], p[1])) != 0 {
+				return false
+			}
+		}
+		return true
+	}, &quick.Config{MaxCount: 50})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// BenchmarkMulBatch compares MulBatch against calling Mul and MulInto per
+// pair, the way batch verification would without it.
+func BenchmarkMulBatch(b *testing.B) {
+	const n = 1000
+	for _, bits := range []int{256, 2048} {
+		_, _, R, N := testParamsLarge(bits)
+		m := NewMontgomeryCIOSWords(R, N)
+		pairs := make([][2]*big.Int, n)
+		for i := range pairs {
+			x, y := big.NewInt(int64(i+2)), new(big.Int).Sub(N, big.NewInt(int64(i+1)))
+			pairs[i] = [2]*big.Int{x.Exp(x, big.NewInt(1000), N), y}
+		}
+
+		b.Run(fmt.Sprintf("bits=%d/impl=MulBatch", bits), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				m.MulBatch(pairs)
+			}
+		})
+		b.Run(fmt.Sprintf("bits=%d/impl=Mul", bits), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				for _, p := range pairs {
+					m.Mul(p[0], p[1])
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("bits=%d/impl=MulInto", bits), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				for _, p := range pairs {
+					m.MulInto(new(big.Int), p[0], p[1])
+				}
+			}
+		})
+	}
+}
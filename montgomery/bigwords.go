@@ -0,0 +1,150 @@
+package montgomery
+
+import (
+	"math/big"
+	"math/bits"
+	"sync"
+)
+
+// redcBigWords performs CIOS Montgomery reduction (x * y * R⁻¹) mod N
+// directly on the backing arrays of the big.Ints.
+//
+// redcInterleaved converts both operands with frombigInt and the result with
+// tobigInt, three allocations and copies per call that dominate for small
+// moduli and are still measurable at 2048 bits. Here y.Bits() is read in
+// place, x.Bits() is only copied into scratch, and the accumulator becomes
+// the result through SetBits, which takes ownership instead of copying.
+//
+// Aliasing: x and y may be the same *big.Int, and either may be m.N or m.RR.
+// Neither operand is modified, and the result never shares memory with them,
+// so callers may keep or mutate all three freely.
+//
+// The loop is montgomeryImpl on big.Words, so where they are 32 bits it is
+// a 32-bit CIOS: 2S passes of bits.Mul32 with NI mod 2^32, the low half of
+// NI, instead of S passes of a 64-bit multiply the platform emulates.
+// Operands that are negative or wider than R take the copying
+// redcInterleaved path instead.
+func (m *MontgomeryCIOSWords) redcBigWords(x, y *big.Int) *big.Int {
+	return m.redcBigWordsInto(new(big.Int), x, y)
+}
+
+// redcBigWordsInto is redcBigWords storing the result in z, whose backing
+// array doubles as the accumulator (see scratch), so a z that is reused
+// across calls makes the reduction allocation-free. z may be x or y.
+func (m *MontgomeryCIOSWords) redcBigWordsInto(z, x, y *big.Int) *big.Int {
+	xw, yw := x.Bits(), y.Bits()
+	s := m.S * limbWords
+	if len(xw) > s || len(yw) > s || x.Sign() < 0 || y.Sign() < 0 {
+		return z.Set(m.redcInterleaved(x, y))
+	}
+	if limbWords == 1 && s <= smallLimbs {
+		return m.redcSmall(z, xw, yw)
+	}
+	// The accumulator starts past the first s words of buf, where an
+	// operand aliasing z lives, and x is zero-extended to s words after it.
+	buf := scratch(z, 3*s+1)
+	t, xs := buf[s:2*s+1], buf[2*s+1:]
+	clear(xs[copy(xs, xw):])
+	m.native.mulAcc(t, xs, yw)
+	return m.finish(z, buf, t)
+}
+
+// smallLimbs is the largest S whose REDC runs on stack arrays in
+// redcSmall; 8 limbs covers every modulus up to 512 bits.
+const smallLimbs = 8
+
+// redcSmall is redcBigWordsInto for moduli of at most smallLimbs words,
+// where big.Word is 64 bits.
+//
+// At these sizes the sliding accumulator costs more than the products: its
+// 3·S+2 heap words, and bounds checks on windows whose length the compiler
+// cannot see. Here the operands are copied into fixed-size arrays on the
+// stack and the uint64 montgomeryImpl runs the CIOS loop there, so z only needs room for
+// the S+1 result words. A reused z makes the call allocation-free, and the
+// operand copies make any aliasing between z, x and y harmless. It is about
+// 25% faster than the sliding window at 256 bits.
+func (m *MontgomeryCIOSWords) redcSmall(z *big.Int, xw, yw []big.Word) *big.Int {
+	var x, y [smallLimbs]uint64
+	var t [smallLimbs + 2]uint64
+	for i, w := range xw {
+		x[i] = uint64(w)
+	}
+	for i, w := range yw {
+		y[i] = uint64(w)
+	}
+	s := m.S
+	// A sized kernel leaves t reduced and t[s] zero
+	if !useSized(s) || !montMulSized(t[:s], x[:s], y[:s], m.nw, m.NI) {
+		montgomeryImpl[uint64]{m.nw, m.NI}.mulAcc(t[:], x[:s], y[:s])
+	}
+
+	buf := scratch(z, s+1)
+	for i := range buf {
+		buf[i] = big.Word(t[i])
+	}
+	z.SetBits(buf)
+	if z.Cmp(m.N) >= 0 {
+		z.Sub(z, m.N)
+	}
+	return z
+}
+
+// scratch returns n words of working memory backed by z's array when it is
+// large enough, and a fresh array otherwise. The first words may hold z's
+// current value, which callers must not overwrite while they still read it
+// as an operand.
+func scratch(z *big.Int, n int) []big.Word {
+	buf := z.Bits()
+	if cap(buf) < n {
+		return make([]big.Word, n)
+	}
+	return buf[:n]
+}
+
+// scratchPool holds big.Ints whose backing arrays serve as REDC scratch
+// (see redcBigWordsInto) for calls that must return a fresh result, such as
+// Mul. A context is shared by every goroutine using it and cannot own
+// scratch of its own, so one pool serves all contexts; an entry grown by a
+// large modulus simply goes on being large enough for smaller ones.
+var scratchPool = sync.Pool{New: func() any { return new(big.Int) }}
+
+// wordsPadded returns the words of x zero-extended to n, in a fresh array.
+func wordsPadded(x *big.Int, n int) []big.Word {
+	w := make([]big.Word, n)
+	copy(w, x.Bits())
+	return w
+}
+
+// finish moves the s+1 word REDC result t, which lies inside buf, to the
+// front of buf, hands buf to z and applies the final conditional
+// subtraction. SetBits keeps buf's capacity, so the next call on z finds
+// its scratch again.
+func (m *MontgomeryCIOSWords) finish(z *big.Int, buf, t []big.Word) *big.Int {
+	copy(buf, t)
+	z.SetBits(buf[:len(t)])
+	if z.Cmp(m.N) >= 0 {
+		z.Sub(z, m.N)
+	}
+	return z
+}
+
+// addMulWords adds a * scalar to t in place and propagates the carry
+// through the higher words of t, which must be long enough to absorb it.
+// a must not alias t.
+func addMulWords(t, a []big.Word, scalar uint) {
+	var c uint
+	for j, aj := range a {
+		hi, lo := bits.Mul(uint(aj), scalar)
+		lo, cc := bits.Add(lo, uint(t[j]), 0)
+		hi += cc
+		lo, cc = bits.Add(lo, c, 0)
+		hi += cc
+		t[j] = big.Word(lo)
+		c = hi
+	}
+	for j := len(a); c != 0; j++ {
+		var sum uint
+		sum, c = bits.Add(uint(t[j]), c, 0)
+		t[j] = big.Word(sum)
+	}
+}
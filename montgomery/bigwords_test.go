@@ -0,0 +1,232 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestMontgomeryCIOSWords_redcBigWords(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+	m64 := NewMontgomeryCIOSWords(new(big.Int).Lsh(big.NewInt(1), 64), N64)
+
+	tests := []struct {
+		name string
+		m    *MontgomeryCIOSWords
+		x, y *big.Int
+	}{
+		{"2048-bit", m, x, y},
+		{"zero", m, big.NewInt(0), y},
+		{"short operands", m, big.NewInt(3), big.NewInt(5)},
+		{"N - 1 squared", m, new(big.Int).Sub(N, big.NewInt(1)), new(big.Int).Sub(N, big.NewInt(1))},
+		{"operand is RR", m, x, m.RR},
+		{"operand is N", m, m.N, y},
+		{"x wider than R", m, new(big.Int).Lsh(x, 2048), y},
+		{"negative operand", m, new(big.Int).Neg(x), y},
+		{"one word", m64, new(big.Int).Sub(N64, big.NewInt(1)), big.NewInt(7)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			want := tc.m.redcInterleaved(tc.x, tc.y)
+			if got := tc.m.redcBigWords(tc.x, tc.y); got.Cmp(want) != 0 {
+				t.Errorf("redcBigWords() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMontgomeryCIOSWords_redcBigWordsAliasing(t *testing.T) {
+	t.Parallel()
+
+	x, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	xCopy := new(big.Int).Set(x)
+	nCopy := new(big.Int).Set(m.N)
+
+	// Squaring with both operands the same big.Int
+	z := m.redcBigWords(x, x)
+	if want := m.redcInterleaved(xCopy, xCopy); z.Cmp(want) != 0 {
+		t.Errorf("redcBigWords(x, x) = %v, want %v", z, want)
+	}
+	if x.Cmp(xCopy) != 0 || m.N.Cmp(nCopy) != 0 {
+		t.Fatal("redcBigWords modified an operand or N")
+	}
+
+	// Mutating the result must not affect the operands
+	z.Add(z, big.NewInt(1))
+	z.SetBit(z, 0, 0)
+	if x.Cmp(xCopy) != 0 || m.N.Cmp(nCopy) != 0 {
+		t.Error("result shares memory with an operand or N")
+	}
+}
+
+func TestMontgomeryCIOSWords_redcBigWordsInto(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	want := m.redcInterleaved(x, y)
+	wantSq := m.redcInterleaved(x, x)
+
+	tests := []struct {
+		name string
+		// run calls redcBigWordsInto on fresh copies of x and y
+		run  func(x, y *big.Int) *big.Int
+		want *big.Int
+	}{
+		{"fresh z", func(x, y *big.Int) *big.Int { return m.redcBigWordsInto(new(big.Int), x, y) }, want},
+		{"z is x", func(x, y *big.Int) *big.Int { return m.redcBigWordsInto(x, x, y) }, want},
+		{"z is y", func(x, y *big.Int) *big.Int { return m.redcBigWordsInto(y, x, y) }, want},
+		{"z is x and y", func(x, _ *big.Int) *big.Int { return m.redcBigWordsInto(x, x, x) }, wantSq},
+		{"z has grown", func(x, y *big.Int) *big.Int {
+			z := m.redcBigWordsInto(new(big.Int), y, y)
+			return m.redcBigWordsInto(z, x, y)
+		}, want},
+		{"z is small", func(x, y *big.Int) *big.Int { return m.redcBigWordsInto(big.NewInt(7), x, y) }, want},
+		{"z is a wide x", func(x, y *big.Int) *big.Int {
+			x.Lsh(x, 2048)
+			return m.redcBigWordsInto(x, x, y)
+		}, m.redcInterleaved(new(big.Int).Lsh(x, 2048), y)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tc.run(new(big.Int).Set(x), new(big.Int).Set(y)); got.Cmp(tc.want) != 0 {
+				t.Errorf("redcBigWordsInto() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMontgomeryCIOSWords_redcBigWordsProperty(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+
+	err := quick.Check(func(xBytes, yBytes []byte) bool {
+		x := new(big.Int).SetBytes(xBytes)
+		y := new(big.Int).SetBytes(yBytes)
+		x.Mod(x, N)
+		y.Mod(y, N)
+		return m.redcBigWords(x, y).Cmp(m.redcInterleaved(x, y)) == 0
+	}, &quick.Config{MaxCount: 200})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMontgomeryCIOSWords_redcSmall(t *testing.T) {
+	t.Parallel()
+
+	for _, bitSize := range []int{64, 128, 256, 384, 512} {
+		_, _, R, N := testParamsLarge(bitSize)
+		m := NewMontgomeryCIOSWords(R, N)
+		nMinus1 := new(big.Int).Sub(N, big.NewInt(1))
+		err := quick.Check(func(xBytes, yBytes []byte) bool {
+			x := new(big.Int).Mod(new(big.Int).SetBytes(xBytes), N)
+			y := new(big.Int).Mod(new(big.Int).SetBytes(yBytes), N)
+			want := m.redcInterleaved(x, y)
+			if m.redcBigWords(x, y).Cmp(want) != 0 {
+				return false
+			}
+			// z aliasing both operands, and a z whose array is reused
+			z := new(big.Int).Set(x)
+			if m.redcBigWordsInto(z, z, z).Cmp(m.redcInterleaved(x, x)) != 0 {
+				return false
+			}
+			return m.redcBigWordsInto(z, x, y).Cmp(want) == 0
+		}, &quick.Config{MaxCount: 50})
+		if err != nil {
+			t.Errorf("%d bits: %v", bitSize, err)
+		}
+		if got, want := m.redcBigWords(nMinus1, nMinus1), m.redcInterleaved(nMinus1, nMinus1); got.Cmp(want) != 0 {
+			t.Errorf("%d bits: redcBigWords(N-1, N-1) = %v, want %v", bitSize, got, want)
+		}
+	}
+}
+
+// TestMontgomeryCIOSWords_redcBigWordsOperands runs the fused loop of
+// montgomeryImpl above smallLimbs on operands shorter than the modulus and
+// at the extremes, where a dropped carry between the product and
+// reduction chains would show.
+func TestMontgomeryCIOSWords_redcBigWordsOperands(t *testing.T) {
+	t.Parallel()
+
+	type params struct{ R, N *big.Int }
+	var tests []params
+	for _, bitSize := range []int{576, 1024, 2048, 4096} {
+		_, _, R, N := testParamsLarge(bitSize)
+		tests = append(tests, params{R, N})
+	}
+	// N shorter than R: the modulus must be zero-extended to R's words
+	_, _, _, N576 := testParamsLarge(576)
+	tests = append(tests, params{new(big.Int).Lsh(big.NewInt(1), 1024), N576})
+
+	for _, tc := range tests {
+		R, N := tc.R, tc.N
+		bitSize := R.BitLen() - 1
+		m := NewMontgomeryCIOSWords(R, N)
+		nMinus1 := new(big.Int).Sub(N, big.NewInt(1))
+		short := new(big.Int).Lsh(big.NewInt(1), 100)
+		allOnes := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(N.BitLen()-1)), big.NewInt(1))
+		operands := []*big.Int{big.NewInt(0), big.NewInt(1), short, allOnes, nMinus1}
+		for _, x := range operands {
+			for _, y := range operands {
+				want := m.redcInterleaved(x, y)
+				if got := m.redcBigWords(x, y); got.Cmp(want) != 0 {
+					t.Errorf("%d bits: redcBigWords(%#x, %#x) = %v, want %v", bitSize, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkRedcSmall measures the stack-array path of redcBigWords for
+// moduli of up to smallLimbs words, with a fresh and a reused result.
+func BenchmarkRedcSmall(b *testing.B) {
+	for _, bits := range []int{64, 256, 512} {
+		x, y, R, N := testParamsLarge(bits)
+		m := NewMontgomeryCIOSWords(R, N)
+		b.Run(fmt.Sprintf("bits=%d/dst=fresh", bits), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				m.redcBigWords(x, y)
+			}
+		})
+		z := new(big.Int)
+		b.Run(fmt.Sprintf("bits=%d/dst=reused", bits), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				m.redcBigWordsInto(z, x, y)
+			}
+		})
+	}
+}
+
+// BenchmarkRedc2048 compares the copying and zero-copy interleaved CIOS paths.
+func BenchmarkRedc2048(b *testing.B) {
+	x, y, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+
+	b.Run("Interleaved", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			m.redcInterleaved(x, y)
+		}
+	})
+	b.Run("BigWords", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			m.redcBigWords(x, y)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package montgomery
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// blindBits is the size of the random multiplier r in e + r·order, the
+// usual 64 bits: enough that the exponent bits differ from call to call,
+// at the cost of 64 more squarings.
+const blindBits = 64
+
+// WithExponentBlinding makes the secret-exponent operations randomize
+// their exponent on every call: e is replaced by e + r·order for a fresh
+// 64-bit r read from random. The result is unchanged as long as order is a
+// multiple of the order of every base, such as λ(N) or φ(N) for RSA, or
+// the prime order of a subgroup. What changes is the bit pattern the
+// multiplications follow, so a side channel that leaks some exponent bits
+// per call learns bits of a different exponent every time, and cannot
+// combine traces. It is defence in depth on top of constant-time code,
+// against leaks that code does not model, such as power analysis.
+//
+// It applies to ExpConstantTime, ExpLadder, ExpDual and MontgomeryCT;
+// variable-time Exp, which is not meant for secrets, ignores it. CRT
+// blinds each prime's exponent with pᵢ-1 and needs no order, so order may
+// be nil there; elsewhere a nil order leaves the exponent as it is. A
+// failing random panics, since falling back to an unblinded exponent
+// would hide the failure.
+func WithExponentBlinding(random io.Reader, order *big.Int) Option {
+	return func(c *config) {
+		c.blind = blinding{random: random}
+		if order != nil {
+			c.blind.order = new(big.Int).Set(order)
+		}
+	}
+}
+
+// withoutBlinding clears WithExponentBlinding, for the contexts of a CRT,
+// which blinds their exponents itself.
+func withoutBlinding() Option {
+	return func(c *config) {
+		c.blind = blinding{}
+	}
+}
+
+// blinding is the setting of WithExponentBlinding.
+type blinding struct {
+	random io.Reader
+	order  *big.Int
+}
+
+// enabled reports whether apply changes exponents.
+func (b blinding) enabled() bool {
+	return b.random != nil && b.order != nil
+}
+
+// apply returns e + r·order for a fresh random r < 2^blindBits, or e
+// itself when blinding is off.
+func (b blinding) apply(e *big.Int) *big.Int {
+	if !b.enabled() {
+		return e
+	}
+	var buf [blindBits / 8]byte
+	if _, err := io.ReadFull(b.random, buf[:]); err != nil {
+		panic(fmt.Sprintf("montgomery: exponent blinding: %v", err))
+	}
+	r := new(big.Int).SetUint64(binary.LittleEndian.Uint64(buf[:]))
+	return r.Mul(r, b.order).Add(r, e)
+}
+
+// applyWords is apply on the little-endian limbs of a MontgomeryCT
+// exponent. The result has a length fixed by len(e) and the order, so
+// that it reveals nothing about r.
+func (b blinding) applyWords(e []uint64) []uint64 {
+	if !b.enabled() {
+		return e
+	}
+	words := max(len(e), (b.order.BitLen()+blindBits)/64+1) + 1
+	return limbsPadded(b.apply(tobigInt(e)), words)
+}
@@ -0,0 +1,186 @@
+package montgomery
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	mrand "math/rand/v2"
+	"testing"
+)
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+// failingReader fails every read with errNoEntropy.
+type failingReader struct{}
+
+var errNoEntropy = errors.New("no entropy")
+
+func (failingReader) Read([]byte) (int, error) { return 0, errNoEntropy }
+
+func TestWithExponentBlinding(t *testing.T) {
+	t.Parallel()
+
+	// compareParams returns a prime N, so p-1 is the group order
+	x, y, R, N := compareParams(512)
+	order := new(big.Int).Sub(N, big.NewInt(1))
+	exp := new(big.Int).Sub(N, big.NewInt(12345))
+	want := new(big.Int).Exp(x, exp, N)
+	wantY := new(big.Int).Exp(y, exp, N)
+
+	tests := []struct {
+		name      string
+		order     *big.Int
+		wantReads bool
+		exp       func(opts ...Option) *big.Int
+	}{
+		{"ExpConstantTime", order, true, func(opts ...Option) *big.Int {
+			return NewMontgomeryCIOSWords(R, N, opts...).ExpConstantTime(x, exp)
+		}},
+		{"ExpLadder", order, true, func(opts ...Option) *big.Int {
+			return NewMontgomeryCIOSWords(R, N, opts...).ExpLadder(x, exp)
+		}},
+		{"ExpDual", order, true, func(opts ...Option) *big.Int {
+			m := NewMontgomeryCIOSWords(R, N, opts...)
+			r0, r1 := ExpDual(m, m, x, exp, y, exp)
+			if r1.Cmp(wantY) != 0 {
+				return nil
+			}
+			return r0
+		}},
+		{"MontgomeryCT.Exp", order, true, func(opts ...Option) *big.Int {
+			return NewMontgomeryCT(R, N, opts...).Exp(x, exp)
+		}},
+		{"MontgomeryCT.ExpWords", order, true, func(opts ...Option) *big.Int {
+			m := NewMontgomeryCT(R, N, opts...)
+			z := make([]uint64, m.S)
+			m.ExpWords(z, limbsPadded(x, m.S), limbsPadded(exp, m.S))
+			return tobigInt(z)
+		}},
+		{"MontgomeryCT.ExpLadderWords", order, true, func(opts ...Option) *big.Int {
+			m := NewMontgomeryCT(R, N, opts...)
+			z := make([]uint64, m.S)
+			m.ExpLadderWords(z, limbsPadded(x, m.S), limbsPadded(exp, m.S))
+			return tobigInt(z)
+		}},
+		{"Exp ignores it", order, false, func(opts ...Option) *big.Int {
+			return NewMontgomeryCIOSWords(R, N, opts...).Exp(x, exp)
+		}},
+		{"nil order", nil, false, func(opts ...Option) *big.Int {
+			return NewMontgomeryCIOSWords(R, N, opts...).ExpConstantTime(x, exp)
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			r := &countingReader{r: mrand.NewChaCha8([32]byte{'b', 'l', 'i', 'n', 'd'})}
+			for range 3 {
+				if got := tc.exp(WithExponentBlinding(r, tc.order)); got == nil || got.Cmp(want) != 0 {
+					t.Fatalf("blinded = %v, want %v", got, want)
+				}
+			}
+			if (r.n > 0) != tc.wantReads {
+				t.Errorf("read %d random bytes, want reads %t", r.n, tc.wantReads)
+			}
+		})
+	}
+}
+
+func TestWithExponentBlinding_CRT(t *testing.T) {
+	t.Parallel()
+
+	p, q := testPrimes(512)
+	for _, primes := range [][]*big.Int{{p, q}, testPrimesK(3, 512)} {
+		t.Run(fmt.Sprintf("primes=%d", len(primes)), func(t *testing.T) {
+			t.Parallel()
+			n := big.NewInt(1)
+			for _, p := range primes {
+				n.Mul(n, p)
+			}
+			d := new(big.Int).Sub(n, big.NewInt(99))
+			x := new(big.Int).Rsh(n, 7)
+
+			r := &countingReader{r: mrand.NewChaCha8([32]byte{'c', 'r', 't'})}
+			c, err := NewCRTMulti(primes, d, WithExponentBlinding(r, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := c.Exp(x), new(big.Int).Exp(x, d, n); got.Cmp(want) != 0 {
+				t.Errorf("Exp() = %v, want %v", got, want)
+			}
+			// One r per prime, and none drawn again by the prime contexts
+			if want := len(primes) * blindBits / 8; r.n != want {
+				t.Errorf("read %d random bytes, want %d", r.n, want)
+			}
+		})
+	}
+}
+
+func Test_blinding_apply(t *testing.T) {
+	t.Parallel()
+
+	order := big.NewInt(1000003 - 1)
+	e := big.NewInt(777)
+	b := blinding{random: mrand.NewChaCha8([32]byte{}), order: order}
+	seen := map[string]bool{}
+	for range 8 {
+		got := b.apply(e)
+		if new(big.Int).Mod(got, order).Cmp(e) != 0 {
+			t.Errorf("apply(e) = %v is not e mod order", got)
+		}
+		if got.BitLen() > order.BitLen()+blindBits {
+			t.Errorf("apply(e) has %d bits, want at most %d", got.BitLen(), order.BitLen()+blindBits)
+		}
+		seen[got.String()] = true
+	}
+	if len(seen) < 8 {
+		t.Errorf("8 blindings gave %d distinct exponents", len(seen))
+	}
+
+	words := b.applyWords([]uint64{777})
+	if got := tobigInt(words); new(big.Int).Mod(got, order).Cmp(e) != 0 {
+		t.Errorf("applyWords(e) = %v is not e mod order", got)
+	}
+	for range 8 {
+		if got := len(b.applyWords([]uint64{777})); got != len(words) {
+			t.Errorf("applyWords length %d, want a fixed %d", got, len(words))
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("apply with a failing reader did not panic")
+		}
+	}()
+	blinding{random: failingReader{}, order: order}.apply(e)
+}
+
+// BenchmarkExponentBlinding measures the cost of the 64 extra exponent
+// bits. N stands in for the group order, which only the result depends on.
+func BenchmarkExponentBlinding(b *testing.B) {
+	x, _, R, N := testParams2048()
+	exp := new(big.Int).Sub(N, big.NewInt(12345))
+	for _, blind := range []bool{false, true} {
+		var opts []Option
+		if blind {
+			opts = append(opts, WithExponentBlinding(mrand.NewChaCha8([32]byte{}), N))
+		}
+		m := NewMontgomeryCIOSWords(R, N, opts...)
+		b.Run(fmt.Sprintf("blind=%t", blind), func(b *testing.B) {
+			for b.Loop() {
+				m.ExpConstantTime(x, exp)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+)
+
+// MontgomeryCarrySave is an experimental backend that runs CIOS with a
+// carry-save (redundant) accumulator, registered as "carrysave".
+//
+// In montMulWords every step of the inner loops adds the previous step's
+// carry, so the s multiply-adds of a row form one serial dependency chain:
+// a wide out-of-order core has the multipliers to start several products
+// per cycle but must wait for each carry. Here the carries never cross
+// columns. Each accumulator column keeps three words (see montMulWordsCS),
+// every multiply-add touches only its own column, and the row's s steps are
+// independent. The price is three times the accumulator traffic; the
+// columns are resolved into ordinary words once, at the end of each REDC.
+//
+// Whether that pays off is what the backend is for: BenchmarkCarrySave
+// compares it with montMulWords on the host at hand. On the amd64 machines
+// measured so far it has not: about 30% slower at 256 bits, 15% at 1024
+// and level from 2048 bits on, the extra loads and stores costing what the
+// shorter dependency chains save. It is not constant time, and it is not
+// meant to replace CIOSWords.
+type MontgomeryCarrySave struct {
+	R  *big.Int // R = 2^(64·S)
+	N  *big.Int // modulus (must be odd)
+	RR *big.Int // R² mod N (precomputed)
+	NI uint64   // -N^(-1) mod 2^64 (precomputed via Newton-Raphson)
+	S  int      // number of 64-bit words in R
+
+	n, rr, one []uint64 // N, R² mod N and 1 as S limbs
+	cfg        config
+}
+
+// NewMontgomeryCarrySave creates a new MontgomeryCarrySave instance with
+// precomputed values.
+//
+// R and N are not validated: an even N or an R of the wrong shape yields
+// a context whose results are silently wrong. Parameters not known to be
+// valid belong in NewMontgomeryCarrySaveChecked.
+func NewMontgomeryCarrySave(R, N *big.Int, opts ...Option) *MontgomeryCarrySave {
+	rr := new(big.Int).Mul(R, R)
+	rr = rr.Mod(rr, N)
+	s := R.BitLen() / 64
+	m := &MontgomeryCarrySave{
+		R:   new(big.Int).Set(R),
+		N:   new(big.Int).Set(N),
+		RR:  rr,
+		NI:  newtonRaphsonInverse(N.Uint64()),
+		S:   s,
+		n:   limbsPadded(N, s),
+		rr:  limbsPadded(rr, s),
+		one: limbsPadded(big.NewInt(1), s),
+		cfg: newConfig(opts),
+	}
+	mustSelfTest(m, m.cfg)
+	return m
+}
+
+// NewMontgomeryCarrySaveChecked is NewMontgomeryCarrySave that first
+// rejects invalid R and N, including R that is not a whole number of
+// 64-bit words, with an error wrapping ErrInvalidParameters.
+func NewMontgomeryCarrySaveChecked(R, N *big.Int, opts ...Option) (*MontgomeryCarrySave, error) {
+	opts, cfg := withoutSelfTest(opts)
+	if _, err := NewMontgomeryCIOSWordsChecked(R, N, opts...); err != nil {
+		return nil, err
+	}
+	m := NewMontgomeryCarrySave(R, N, opts...)
+	if err := selfTest(m, cfg); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MulWords sets z = (x * y) mod N with two carry-save REDCs, since
+// REDC(REDC(x, y), R²) = x·y. x, y and z are S limbs with x and y in
+// [0, N), which is not checked; z may be x, y or both.
+func (m *MontgomeryCarrySave) MulWords(z, x, y []uint64) {
+	m.checkLen("MulWords", z, x, y)
+	t := make([]uint64, 6*(m.S+1))
+	montMulWordsCS(z, x, y, m.n, m.NI, t)
+	montMulWordsCS(z, z, m.rr, m.n, m.NI, t)
+}
+
+// Mul returns (x * y) mod N. Operands outside [0, N) are handled by the
+// context's InputPolicy.
+func (m *MontgomeryCarrySave) Mul(x, y *big.Int) *big.Int {
+	x = m.cfg.input.mustOperand(m.N, "Mul", x)
+	y = m.cfg.input.mustOperand(m.N, "Mul", y)
+	z := make([]uint64, m.S)
+	m.MulWords(z, limbsPadded(x, m.S), limbsPadded(y, m.S))
+	return tobigInt(z)
+}
+
+// Exp computes base^exp mod N with the schedule of expMont, every
+// reduction carry-save. See expFull for the handling of negative and
+// unreduced operands.
+func (m *MontgomeryCarrySave) Exp(base, exp *big.Int) *big.Int {
+	return expFull(newEngine(m.redc, m.RR, m.N, m.R, m.cfg), base, exp)
+}
+
+// redc performs carry-save Montgomery reduction: (x * y * R⁻¹) mod N.
+func (m *MontgomeryCarrySave) redc(x, y *big.Int) *big.Int {
+	s := m.S
+	buf := make([]uint64, 3*s+6*(s+1))
+	z, xs, ys, t := buf[:s], buf[s:2*s], buf[2*s:3*s], buf[3*s:]
+	montMulWordsCS(z, limbsPaddedInto(xs, x), limbsPaddedInto(ys, y), m.n, m.NI, t)
+	return tobigInt(z)
+}
+
+// Modulus returns N.
+func (m *MontgomeryCarrySave) Modulus() *big.Int { return new(big.Int).Set(m.N) }
+
+// checkLen panics unless every slice has exactly S limbs.
+func (m *MontgomeryCarrySave) checkLen(op string, xs ...[]uint64) {
+	for _, x := range xs {
+		if len(x) != m.S {
+			panic(fmt.Sprintf("montgomery: %s: operand has %d limbs, want %d", op, len(x), m.S))
+		}
+	}
+}
+
+var _ ModMultiplier = (*MontgomeryCarrySave)(nil)
+
+// montMulWordsCS computes z = (x * y * R⁻¹) mod N like montMulWords, with
+// the accumulator in carry-save form. t is scratch of at least 6·(s+1)
+// words, holding three words a, b and o for each of 2s+2 columns, which
+// represent
+//
+//	T = Σ (a[j] + b[j]·2^64 + o[j]·2^128) · 2^(64j)
+//
+// Adding a product hi·2^64 + lo into column j touches a[j], b[j] and o[j]
+// only: the carry out of a[j] goes to b[j] and the one out of b[j] to o[j],
+// which stays a small count. Row i works on columns i to i+s-1, so the
+// division by 2^64 moves the window instead of the data, as in
+// redcBigWords; all that crosses columns is folding the b and o of the
+// cleared column i into column i+1, a constant two additions per row. z may
+// be x, y or both; t must not alias any other argument.
+func montMulWordsCS(z, x, y, n []uint64, ni uint64, t []uint64) {
+	s := len(n)
+	checkInPlace("montMulWordsCS", z, x)
+	checkInPlace("montMulWordsCS", z, y)
+	checkDisjoint("montMulWordsCS", z, n)
+	checkDisjoint("montMulWordsCS", t, z, x, y, n)
+	c := 2*s + 2
+	a, b, o := t[:c], t[c:2*c], t[2*c:3*c]
+	x, y, n = x[:s], y[:s], n[:s]
+	clear(t[:3*c])
+
+	for i := range s {
+		// T += x · y[i] · 2^(64i)
+		yi := y[i]
+		aw, bw, ow := a[i:i+s], b[i:i+s], o[i:i+s]
+		for j := range s {
+			hi, lo := bits.Mul64(x[j], yi)
+			var cc uint64
+			aw[j], cc = bits.Add64(aw[j], lo, 0)
+			bw[j], cc = bits.Add64(bw[j], hi, cc)
+			ow[j] += cc
+		}
+
+		// T += m · N · 2^(64i), which clears a[i]
+		m := aw[0] * ni
+		for j := range s {
+			hi, lo := bits.Mul64(m, n[j])
+			var cc uint64
+			aw[j], cc = bits.Add64(aw[j], lo, 0)
+			bw[j], cc = bits.Add64(bw[j], hi, cc)
+			ow[j] += cc
+		}
+
+		// Fold what is left of column i into column i+1
+		var cc uint64
+		a[i+1], cc = bits.Add64(a[i+1], b[i], 0)
+		b[i+1], cc = bits.Add64(b[i+1], o[i], cc)
+		o[i+1] += cc
+	}
+
+	// Resolve columns s to 2s into s+1 ordinary words: word k collects
+	// a[s+k], b[s+k-1] and o[s+k-2], with a carry of a few units. Column s
+	// holds everything below it, so b[s-1] and o[s-2] do not count.
+	r := a[s : 2*s+1]
+	var carry, top uint64
+	for k := range s + 1 {
+		w, cc := bits.Add64(r[k], carry, 0)
+		carry = cc
+		if k >= 1 {
+			w, cc = bits.Add64(w, b[s+k-1], 0)
+			carry += cc
+		}
+		if k >= 2 {
+			w, cc = bits.Add64(w, o[s+k-2], 0)
+			carry += cc
+		}
+		if k < s {
+			r[k] = w
+		} else {
+			top = w
+		}
+	}
+
+	// T < 2N; subtract N when T ≥ N.
+	var borrow uint64
+	for j := range s {
+		z[j], borrow = bits.Sub64(r[j], n[j], borrow)
+	}
+	if top == 0 && borrow == 1 {
+		copy(z, r[:s])
+	}
+}
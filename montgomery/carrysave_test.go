@@ -0,0 +1,110 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func Test_montMulWordsCS(t *testing.T) {
+	t.Parallel()
+
+	for _, bitSize := range []int{64, 128, 256, 1024, 2048} {
+		_, _, R, N := testParamsLarge(bitSize)
+		ref := NewMontgomeryCIOSWords(R, N)
+		s := ref.S
+		n := limbsPadded(N, s)
+		nMinus1 := limbsPadded(new(big.Int).Sub(N, big.NewInt(1)), s)
+		scratch := make([]uint64, 6*(s+1))
+
+		check := func(x, y []uint64, alias bool) bool {
+			want := make([]uint64, s)
+			montMulWords(want, x, y, n, ref.NI, make([]uint64, s+2))
+			z := make([]uint64, s)
+			if alias {
+				z = append([]uint64(nil), x...)
+				x = z
+			}
+			montMulWordsCS(z, x, y, n, ref.NI, scratch)
+			return tobigInt(z).Cmp(tobigInt(want)) == 0
+		}
+
+		// (N-1)² gives the largest columns and carries
+		if !check(nMinus1, nMinus1, false) {
+			t.Errorf("%d bits: montMulWordsCS(N-1, N-1) differs from montMulWords", bitSize)
+		}
+		err := quick.Check(func(xBytes, yBytes []byte, alias bool) bool {
+			x := new(big.Int).Mod(new(big.Int).SetBytes(xBytes), N)
+			y := new(big.Int).Mod(new(big.Int).SetBytes(yBytes), N)
+			return check(limbsPadded(x, s), limbsPadded(y, s), alias)
+		}, &quick.Config{MaxCount: 100})
+		if err != nil {
+			t.Errorf("%d bits: %v", bitSize, err)
+		}
+	}
+}
+
+func TestMontgomeryCarrySave(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	m := NewMontgomeryCarrySave(R, N)
+	e := new(big.Int).Sub(N, big.NewInt(12345))
+
+	tests := []struct {
+		name string
+		got  *big.Int
+		want *big.Int
+	}{
+		{"Mul", m.Mul(x, y), new(big.Int).Mod(new(big.Int).Mul(x, y), N)},
+		{"Mul unreduced", m.Mul(new(big.Int).Add(x, N), y), new(big.Int).Mod(new(big.Int).Mul(x, y), N)},
+		{"Exp", m.Exp(x, e), new(big.Int).Exp(x, e, N)},
+		{"Exp negative", m.Exp(x, big.NewInt(-3)), new(big.Int).Exp(new(big.Int).ModInverse(x, N), big.NewInt(3), N)},
+		{"Modulus", m.Modulus(), N},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if tc.got.Cmp(tc.want) != 0 {
+				t.Errorf("got %v, want %v", tc.got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewMontgomeryCarrySaveChecked(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	if _, err := NewMontgomeryCarrySaveChecked(R, N); err != nil {
+		t.Errorf("NewMontgomeryCarrySaveChecked() error = %v", err)
+	}
+	if _, err := NewMontgomeryCarrySaveChecked(new(big.Int).Lsh(R, 1), N); err == nil {
+		t.Error("NewMontgomeryCarrySaveChecked() accepted R that is not word aligned")
+	}
+}
+
+// BenchmarkCarrySave compares the carry-save kernel with montMulWords,
+// whose inner loops are serial carry chains.
+func BenchmarkCarrySave(b *testing.B) {
+	for _, bits := range []int{256, 1024, 2048, 4096} {
+		x, y, R, N := testParamsLarge(bits)
+		m := NewMontgomeryCarrySave(R, N)
+		s := m.S
+		xs, ys, z := limbsPadded(x, s), limbsPadded(y, s), make([]uint64, s)
+		t := make([]uint64, 6*(s+1))
+
+		b.Run(fmt.Sprintf("bits=%d/impl=carrysave", bits), func(b *testing.B) {
+			for b.Loop() {
+				montMulWordsCS(z, xs, ys, m.n, m.NI, t)
+			}
+		})
+		b.Run(fmt.Sprintf("bits=%d/impl=cios", bits), func(b *testing.B) {
+			for b.Loop() {
+				montMulWords(z, xs, ys, m.n, m.NI, t)
+			}
+		})
+	}
+}
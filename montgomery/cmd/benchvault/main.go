@@ -0,0 +1,264 @@
+// Command benchvault times every registered backend and math/big on the
+// same inputs and writes the results as CSV or JSON, so that comparisons
+// between strategies, machines and commits can be kept and processed.
+//
+// For each modulus size it draws a deterministic prime N and two operands
+// below it, checks that every contestant agrees with math/big, and times:
+//
+//   - Mul: x·y mod N, against big.Int Mul followed by Mod
+//   - Exp: x^(N-1) mod N, against big.Int.Exp
+//
+// Bitwise exponentiation takes seconds at 4096 bits, so it is only timed
+// with -all. Every row records the iterations run, ns/op, ops/sec, and
+// allocations and bytes per op; JSON adds the Go version and platform.
+// Progress goes to stderr.
+//
+//	go run ./cmd/benchvault > bench.csv
+//	go run ./cmd/benchvault -format json -bits 256,2048 -ops mul -benchtime 3s
+package main
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	mrand "math/rand/v2"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/blck-snwmn/arithmetic-vault/montgomery"
+)
+
+func main() {
+	testing.Init()
+	sizes := flag.String("bits", "256,512,1024,2048,4096", "comma-separated modulus sizes in bits")
+	ops := flag.String("ops", "mul,exp", "comma-separated operations to time: mul, exp")
+	backends := flag.String("backends", "", "comma-separated backends to time (default all registered)")
+	format := flag.String("format", "csv", "output format: csv or json")
+	benchtime := flag.String("benchtime", "1s", "time per benchmark, or a count such as 100x")
+	all := flag.Bool("all", false, "also time Exp on bitwise")
+	flag.Parse()
+
+	cfg, err := parseConfig(*sizes, *ops, *backends, *format)
+	if err == nil {
+		err = flag.Set("test.benchtime", *benchtime)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "benchvault:", err)
+		os.Exit(2)
+	}
+	cfg.all = *all
+
+	results, err := cfg.run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "benchvault:", err)
+		os.Exit(1)
+	}
+	if err := write(os.Stdout, cfg.format, results); err != nil {
+		fmt.Fprintln(os.Stderr, "benchvault:", err)
+		os.Exit(1)
+	}
+}
+
+// config is what to time.
+type config struct {
+	sizes    []int
+	ops      []string
+	backends []string
+	format   string
+	all      bool
+}
+
+func parseConfig(sizes, ops, backends, format string) (*config, error) {
+	cfg := &config{format: format}
+	for f := range strings.SplitSeq(sizes, ",") {
+		bits, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || bits < 8 {
+			return nil, fmt.Errorf("-bits: invalid size %q", f)
+		}
+		cfg.sizes = append(cfg.sizes, bits)
+	}
+	for op := range strings.SplitSeq(ops, ",") {
+		op = strings.TrimSpace(op)
+		if op != "mul" && op != "exp" {
+			return nil, fmt.Errorf("-ops: unknown operation %q", op)
+		}
+		cfg.ops = append(cfg.ops, op)
+	}
+	registered := montgomery.Backends()
+	cfg.backends = registered
+	if backends != "" {
+		cfg.backends = nil
+		for name := range strings.SplitSeq(backends, ",") {
+			name = strings.TrimSpace(name)
+			if !slices.Contains(registered, name) {
+				return nil, fmt.Errorf("-backends: %q is not registered (have %s)", name, strings.Join(registered, ", "))
+			}
+			cfg.backends = append(cfg.backends, name)
+		}
+	}
+	if format != "csv" && format != "json" {
+		return nil, fmt.Errorf("-format: unknown format %q", format)
+	}
+	return cfg, nil
+}
+
+// result is one row of the output.
+type result struct {
+	Op          string  `json:"op"`
+	Bits        int     `json:"bits"`
+	Impl        string  `json:"impl"`
+	Iterations  int     `json:"iterations"`
+	NsPerOp     float64 `json:"ns_per_op"`
+	OpsPerSec   float64 `json:"ops_per_sec"`
+	AllocsPerOp int64   `json:"allocs_per_op"`
+	BytesPerOp  int64   `json:"bytes_per_op"`
+}
+
+// contestant is one implementation under comparison. A nil operation is
+// not timed.
+type contestant struct {
+	name string
+	mul  func(x, y *big.Int) *big.Int
+	exp  func(x, e *big.Int) *big.Int
+}
+
+// run times every contestant at every size.
+func (cfg *config) run() ([]result, error) {
+	var results []result
+	for _, bits := range cfg.sizes {
+		x, y, N := params(bits)
+		e := new(big.Int).Sub(N, big.NewInt(1))
+		contestants, err := cfg.contestants(N)
+		if err != nil {
+			return nil, err
+		}
+		if err := agree(contestants, x, y, e); err != nil {
+			return nil, fmt.Errorf("%d bits: %w", bits, err)
+		}
+		for _, op := range cfg.ops {
+			for _, c := range contestants {
+				f := c.mul
+				arg := y
+				if op == "exp" {
+					f, arg = c.exp, e
+				}
+				if f == nil {
+					continue
+				}
+				r := testing.Benchmark(func(b *testing.B) {
+					b.ReportAllocs()
+					for b.Loop() {
+						f(x, arg)
+					}
+				})
+				row := result{
+					Op:          op,
+					Bits:        bits,
+					Impl:        c.name,
+					Iterations:  r.N,
+					NsPerOp:     float64(r.T.Nanoseconds()) / float64(r.N),
+					AllocsPerOp: r.AllocsPerOp(),
+					BytesPerOp:  r.AllocedBytesPerOp(),
+				}
+				row.OpsPerSec = 1e9 / row.NsPerOp
+				fmt.Fprintf(os.Stderr, "op=%s/bits=%d/impl=%s\t%.0f ns/op\t%d allocs/op\n", op, bits, c.name, row.NsPerOp, row.AllocsPerOp)
+				results = append(results, row)
+			}
+		}
+	}
+	return results, nil
+}
+
+// contestants opens N with every selected backend, on the smallest
+// R = 2^(64·s) above N, and adds math/big.
+func (cfg *config) contestants(N *big.Int) ([]contestant, error) {
+	R := new(big.Int).Lsh(big.NewInt(1), uint(64*((N.BitLen()+63)/64)))
+	var cs []contestant
+	for _, name := range cfg.backends {
+		m, err := montgomery.Open(R, N, montgomery.WithBackend(name))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		c := contestant{name: name, mul: m.Mul}
+		if e, ok := m.(interface{ Exp(x, e *big.Int) *big.Int }); ok && (cfg.all || name != "bitwise") {
+			c.exp = e.Exp
+		}
+		cs = append(cs, c)
+	}
+	return append(cs, contestant{
+		name: "big",
+		mul: func(x, y *big.Int) *big.Int {
+			z := new(big.Int).Mul(x, y)
+			return z.Mod(z, N)
+		},
+		exp: func(x, e *big.Int) *big.Int { return new(big.Int).Exp(x, e, N) },
+	}), nil
+}
+
+// agree checks every contestant against math/big, the last one, before any
+// timing: a comparison with a wrong implementation is meaningless.
+func agree(cs []contestant, x, y, e *big.Int) error {
+	ref := cs[len(cs)-1]
+	for _, c := range cs[:len(cs)-1] {
+		if c.mul(x, y).Cmp(ref.mul(x, y)) != 0 {
+			return fmt.Errorf("%s: Mul disagrees with math/big", c.name)
+		}
+		if c.exp != nil && c.exp(x, e).Cmp(ref.exp(x, e)) != 0 {
+			return fmt.Errorf("%s: Exp disagrees with math/big", c.name)
+		}
+	}
+	return nil
+}
+
+// params returns two operands and a prime modulus of the given size, the
+// same on every run.
+func params(bits int) (x, y, N *big.Int) {
+	rng := mrand.NewChaCha8([32]byte{byte(bits), byte(bits >> 8)})
+	N, err := rand.Prime(rng, bits)
+	if err != nil {
+		panic(err)
+	}
+	random := func() *big.Int {
+		b := make([]byte, (bits+7)/8)
+		rng.Read(b)
+		return new(big.Int).Mod(new(big.Int).SetBytes(b), N)
+	}
+	return random(), random(), N
+}
+
+// write encodes the results as CSV with a header row, or as JSON with the
+// Go version and platform they were measured on.
+func write(w io.Writer, format string, results []result) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			GoVersion string   `json:"go_version"`
+			GOOS      string   `json:"goos"`
+			GOARCH    string   `json:"goarch"`
+			CPUs      int      `json:"cpus"`
+			Results   []result `json:"results"`
+		}{runtime.Version(), runtime.GOOS, runtime.GOARCH, runtime.NumCPU(), results})
+	}
+	cw := csv.NewWriter(w)
+	rows := [][]string{{"op", "bits", "impl", "iterations", "ns_per_op", "ops_per_sec", "allocs_per_op", "bytes_per_op"}}
+	for _, r := range results {
+		rows = append(rows, []string{
+			r.Op, strconv.Itoa(r.Bits), r.Impl, strconv.Itoa(r.Iterations),
+			strconv.FormatFloat(r.NsPerOp, 'f', 1, 64),
+			strconv.FormatFloat(r.OpsPerSec, 'f', 1, 64),
+			strconv.FormatInt(r.AllocsPerOp, 10),
+			strconv.FormatInt(r.BytesPerOp, 10),
+		})
+	}
+	return errors.Join(cw.WriteAll(rows), cw.Error())
+}
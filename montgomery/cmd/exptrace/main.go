@@ -0,0 +1,82 @@
+// Command exptrace records the operation sequence of two modular
+// exponentiation strategies for the same inputs and prints a report of their
+// operation counts, memory traffic and first point of divergence.
+//
+// With -shape it instead traces each strategy for two random exponents of the
+// same size, which shows structurally whether its schedule depends on the
+// exponent.
+//
+//	go run ./cmd/exptrace -bits 2048 -a ladder -b window
+//	go run ./cmd/exptrace -bits 1024 -shape
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/big"
+	mrand "math/rand/v2"
+	"os"
+
+	"github.com/blck-snwmn/arithmetic-vault/montgomery"
+)
+
+func main() {
+	bits := flag.Int("bits", 2048, "modulus size in bits (a multiple of 64)")
+	a := flag.String("a", "ladder", "first strategy: binary, ladder or window")
+	b := flag.String("b", "window", "second strategy: binary, ladder or window")
+	shape := flag.Bool("shape", false, "compare each strategy against itself for two exponents")
+	seed := flag.Uint64("seed", 1, "seed for the modulus, base and exponents")
+	flag.Parse()
+
+	if err := run(*bits, *a, *b, *shape, *seed); err != nil {
+		fmt.Fprintln(os.Stderr, "exptrace:", err)
+		os.Exit(1)
+	}
+}
+
+func run(bits int, a, b string, shape bool, seed uint64) error {
+	if bits <= 0 || bits%64 != 0 {
+		return fmt.Errorf("-bits must be a positive multiple of 64, got %d", bits)
+	}
+	sa, err := montgomery.ParseStrategy(a)
+	if err != nil {
+		return err
+	}
+	sb, err := montgomery.ParseStrategy(b)
+	if err != nil {
+		return err
+	}
+
+	rng := mrand.New(mrand.NewPCG(seed, seed))
+	random := func() *big.Int {
+		words := make([]big.Word, bits/64)
+		for i := range words {
+			words[i] = big.Word(rng.Uint64())
+		}
+		return new(big.Int).SetBits(words)
+	}
+
+	// Odd modulus with the top bit set
+	N := random()
+	N.SetBit(N, bits-1, 1)
+	N.SetBit(N, 0, 1)
+	R := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	m := montgomery.NewMontgomeryCIOSWords(R, N)
+	base := new(big.Int).Mod(random(), N)
+	e1 := random()
+	e1.SetBit(e1, bits-1, 1)
+
+	if !shape {
+		fmt.Print(montgomery.Compare(m.Trace(sa, base, e1), m.Trace(sb, base, e1)))
+		return nil
+	}
+
+	e2 := random()
+	e2.SetBit(e2, bits-1, 1)
+	for _, s := range []montgomery.Strategy{sa, sb} {
+		fmt.Printf("%v, two exponents:\n", s)
+		fmt.Print(montgomery.Compare(m.Trace(s, base, e1), m.Trace(s, base, e2)))
+		fmt.Println()
+	}
+	return nil
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"math/big"
+	"strings"
+
+	"github.com/blck-snwmn/arithmetic-vault/montgomery"
+)
+
+// config is what one generated file is specialized to.
+type config struct {
+	Q       *big.Int // odd modulus, at least 3
+	Package string
+	Type    string
+	Source  string // the flags naming Q, recorded in the header
+}
+
+// generate returns the formatted source of the package described by c.
+func generate(c config) ([]byte, error) {
+	if c.Q.Cmp(big.NewInt(3)) < 0 || c.Q.Bit(0) == 0 {
+		return nil, fmt.Errorf("modulus must be odd and at least 3, got %v", c.Q)
+	}
+	if !token.IsIdentifier(c.Package) {
+		return nil, fmt.Errorf("invalid package name %q", c.Package)
+	}
+	if !token.IsIdentifier(c.Type) || !token.IsExported(c.Type) {
+		return nil, fmt.Errorf("invalid type name %q: want an exported identifier", c.Type)
+	}
+
+	k := (c.Q.BitLen() + 63) / 64
+	g := &gen{k: k, typ: c.Type, q: limbs(c.Q, k)}
+	R := new(big.Int).Lsh(big.NewInt(1), uint(64*k))
+	one := limbs(new(big.Int).Mod(R, c.Q), k)
+	rr := limbs(new(big.Int).Exp(R, big.NewInt(2), c.Q), k)
+	p := g.p
+
+	p("// Code generated by montgen %s -package %s; DO NOT EDIT.", c.Source, c.Package)
+	p("")
+	p("// Package %s is arithmetic modulo", c.Package)
+	p("//")
+	p("//\tq = 0x%x", c.Q)
+	p("//")
+	p("// on residues in Montgomery form, x·R mod q with R = 2^%d, held in %d", 64*k, k)
+	p("// 64-bit limbs. The limb loops are unrolled and no operation branches")
+	p("// on or indexes memory by the value of an element.")
+	p("package %s", c.Package)
+	p("")
+	p("import (")
+	p(`"encoding/binary"`)
+	p(`"math/bits"`)
+	p(")")
+	p("")
+	p("const (")
+	p("// Limbs is the number of 64-bit limbs in an %s.", c.Type)
+	p("Limbs = %d", k)
+	p("// Size is the length in bytes of the big-endian encoding of an %s.", c.Type)
+	p("Size = %d", (c.Q.BitLen()+7)/8)
+	p(")")
+	p("")
+	p("// The limbs of q, least significant first, and -q⁻¹ mod 2^64.")
+	p("const (")
+	for j, w := range g.q {
+		p("q%d = 0x%016x", j, w)
+	}
+	p("qInv = 0x%016x", montgomery.WordInverse(g.q[0], 64))
+	p(")")
+	p("")
+	p("// %s is a residue mod q in Montgomery form, as little-endian limbs", c.Type)
+	p("// below q. The zero value is 0.")
+	p("type %s [Limbs]uint64", c.Type)
+	p("")
+	p("// rSquare is R² mod q: multiplying by it takes a value into Montgomery form.")
+	p("var rSquare = %s{%s}", c.Type, hexList(rr))
+	p("")
+	p("// SetZero sets z to 0 and returns z.")
+	p("func (z *%s) SetZero() *%[1]s {", c.Type)
+	p("*z = %s{}", c.Type)
+	p("return z")
+	p("}")
+	p("")
+	p("// SetOne sets z to 1 and returns z.")
+	p("func (z *%s) SetOne() *%[1]s {", c.Type)
+	p("*z = %s{%s}", c.Type, hexList(one))
+	p("return z")
+	p("}")
+	p("")
+	p("// SetUint64 sets z to v mod q and returns z.")
+	p("func (z *%s) SetUint64(v uint64) *%[1]s {", c.Type)
+	p("*z = %s{v}", c.Type)
+	p("return z.Mul(z, &rSquare)")
+	p("}")
+	p("")
+	g.emitBytes()
+	p("")
+	p("// Equal reports whether z and x are the same residue.")
+	p("func (z *%s) Equal(x *%[1]s) bool {", c.Type)
+	p("return %s == 0", joinf(k, " | ", "z[%[1]d]^x[%[1]d]"))
+	p("}")
+	p("")
+	p("// IsZero reports whether z is 0.")
+	p("func (z *%s) IsZero() bool {", c.Type)
+	p("return %s == 0", joinf(k, " | ", "z[%d]"))
+	p("}")
+	p("")
+	g.emitAdd()
+	p("")
+	g.emitSub()
+	p("")
+	p("// Neg sets z to -x mod q and returns z.")
+	p("func (z *%s) Neg(x *%[1]s) *%[1]s {", c.Type)
+	p("return z.Sub(&%s{}, x)", c.Type)
+	p("}")
+	p("")
+	g.emitMul()
+	p("")
+	g.emitSquare()
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v", err)
+	}
+	return src, nil
+}
+
+type gen struct {
+	buf bytes.Buffer
+	k   int
+	typ string
+	q   []uint64
+}
+
+func (g *gen) p(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format+"\n", args...)
+}
+
+// emitBytes writes SetBytes and Bytes, the conversions to and from the
+// canonical big-endian encoding, which is Size bytes long.
+func (g *gen) emitBytes() {
+	p, k, typ := g.p, g.k, g.typ
+
+	p("// SetBytes sets z to the value of the big-endian encoding b and reports")
+	p("// whether b was canonical: Size bytes long, and the value below q. If not,")
+	p("// z is unchanged.")
+	p("func (z *%s) SetBytes(b []byte) bool {", typ)
+	p("if len(b) != Size {")
+	p("return false")
+	p("}")
+	p("var buf [Limbs * 8]byte")
+	p("copy(buf[Limbs*8-Size:], b)")
+	p("var t %s", typ)
+	for j := range k {
+		p("t[%d] = binary.BigEndian.Uint64(buf[%d:])", j, 8*(k-1-j))
+	}
+	p("var b0 uint64")
+	for j := range k {
+		p("_, b0 = bits.Sub64(t[%d], q%[1]d, b0)", j)
+	}
+	p("if b0 == 0 {")
+	p("return false")
+	p("}")
+	p("z.Mul(&t, &rSquare)")
+	p("return true")
+	p("}")
+	p("")
+	p("// Bytes returns the canonical big-endian encoding of z.")
+	p("func (z *%s) Bytes() [Size]byte {", typ)
+	p("var t %s", typ)
+	p("t.Mul(z, &%s{1})", typ)
+	p("var buf [Limbs * 8]byte")
+	for j := range k {
+		p("binary.BigEndian.PutUint64(buf[%d:], t[%d])", 8*(k-1-j), j)
+	}
+	p("return [Size]byte(buf[Limbs*8-Size:])")
+	p("}")
+}
+
+func (g *gen) emitAdd() {
+	p, k, typ := g.p, g.k, g.typ
+
+	p("// Add sets z to x + y mod q and returns z.")
+	p("func (z *%s) Add(x, y *%[1]s) *%[1]s {", typ)
+	p("var %s, c uint64", joinf(k, ", ", "t%d"))
+	for j := range k {
+		p("t%d, c = bits.Add64(x[%[1]d], y[%[1]d], c)", j)
+	}
+	g.reduceOnce("t", 0, "c")
+	p("return z")
+	p("}")
+}
+
+func (g *gen) emitSub() {
+	p, k, typ := g.p, g.k, g.typ
+
+	p("// Sub sets z to x - y mod q and returns z.")
+	p("func (z *%s) Sub(x, y *%[1]s) *%[1]s {", typ)
+	p("var %s, b uint64", joinf(k, ", ", "t%d"))
+	for j := range k {
+		p("t%d, b = bits.Sub64(x[%[1]d], y[%[1]d], b)", j)
+	}
+	p("// add q back when the subtraction borrowed")
+	p("mask := -b")
+	p("var c uint64")
+	for j := range k {
+		if g.q[j] == 0 {
+			p("z[%d], c = bits.Add64(t%[1]d, 0, c)", j)
+		} else {
+			p("z[%d], c = bits.Add64(t%[1]d, q%[1]d&mask, c)", j)
+		}
+	}
+	p("return z")
+	p("}")
+}
+
+// emitMul writes Mul: word-by-word CIOS with both loops unrolled, the
+// accumulator in t0..t<k>, and the multiply by a zero limb of q left out.
+func (g *gen) emitMul() {
+	p, k, typ := g.p, g.k, g.typ
+
+	p("// Mul sets z to the Montgomery product x·y·R⁻¹ mod q and returns z.")
+	p("func (z *%s) Mul(x, y *%[1]s) *%[1]s {", typ)
+	p("var %s uint64", joinf(k+1, ", ", "t%d"))
+	p("var hi, lo, mlo, m, c, d, cc, c1, c2 uint64")
+	for i := range k {
+		p("")
+		p("// t = (t + x·y[%d] + m·q) / 2^64", i)
+		p("hi, lo = bits.Mul64(x[0], y[%d])", i)
+		p("lo, cc = bits.Add64(lo, t0, 0)")
+		p("c = hi + cc")
+		p("m = lo * qInv")
+		p("hi, mlo = bits.Mul64(m, q0)")
+		p("_, cc = bits.Add64(mlo, lo, 0)")
+		p("d = hi + cc")
+		for j := 1; j < k; j++ {
+			p("hi, lo = bits.Mul64(x[%d], y[%d])", j, i)
+			p("lo, cc = bits.Add64(lo, t%d, 0)", j)
+			p("hi += cc")
+			p("lo, cc = bits.Add64(lo, c, 0)")
+			p("c = hi + cc")
+			if g.q[j] == 0 {
+				p("t%d, cc = bits.Add64(lo, d, 0)", j-1)
+				p("d = cc")
+				continue
+			}
+			p("hi, mlo = bits.Mul64(m, q%d)", j)
+			p("mlo, cc = bits.Add64(mlo, lo, 0)")
+			p("hi += cc")
+			p("t%d, cc = bits.Add64(mlo, d, 0)", j-1)
+			p("d = hi + cc")
+		}
+		p("t%d, c1 = bits.Add64(t%d, c, 0)", k-1, k)
+		p("t%d, c2 = bits.Add64(t%d, d, 0)", k-1, k-1)
+		p("t%d = c1 + c2", k)
+	}
+	p("")
+	g.reduceOnce("t", 0, fmt.Sprintf("t%d", k))
+	p("return z")
+	p("}")
+}
+
+// emitSquare writes Square: the cross products x[i]·x[j], i < j, once each,
+// doubled by a shift and added to the squares of the limbs, then the 2k-limb
+// product reduced a limb at a time, as in separated operand scanning.
+func (g *gen) emitSquare() {
+	p, k, typ := g.p, g.k, g.typ
+
+	p("// Square sets z to x·x·R⁻¹ mod q and returns z.")
+	p("func (z *%s) Square(x *%[1]s) *%[1]s {", typ)
+	p("var %s uint64", joinf(2*k, ", ", "p%d"))
+	p("var hi, lo, mlo, m, c, cc, c1, c2, ov uint64")
+	if k > 1 {
+		p("")
+		p("// cross products")
+	}
+	for i := 0; i < k-1; i++ {
+		p("c = 0")
+		for j := i + 1; j < k; j++ {
+			p("hi, lo = bits.Mul64(x[%d], x[%d])", i, j)
+			p("lo, cc = bits.Add64(lo, p%d, 0)", i+j)
+			p("hi += cc")
+			p("p%d, cc = bits.Add64(lo, c, 0)", i+j)
+			p("c = hi + cc")
+		}
+		p("p%d = c", i+k)
+	}
+	if k > 1 {
+		p("")
+		p("// doubled, plus the squares")
+		p("p%d = p%d >> 63", 2*k-1, 2*k-2)
+		for i := 2*k - 2; i > 0; i-- {
+			p("p%d = p%[1]d<<1 | p%d>>63", i, i-1)
+		}
+		p("p0 <<= 1")
+	}
+	p("cc = 0")
+	for i := range k {
+		p("hi, lo = bits.Mul64(x[%d], x[%[1]d])", i)
+		p("p%d, cc = bits.Add64(p%[1]d, lo, cc)", 2*i)
+		p("p%d, cc = bits.Add64(p%[1]d, hi, cc)", 2*i+1)
+	}
+	for i := range k {
+		p("")
+		p("// p += m·q·2^%d, clearing p%d", 64*i, i)
+		p("m = p%d * qInv", i)
+		p("hi, mlo = bits.Mul64(m, q0)")
+		p("_, cc = bits.Add64(mlo, p%d, 0)", i)
+		p("c = hi + cc")
+		for j := 1; j < k; j++ {
+			if g.q[j] == 0 {
+				p("p%d, c = bits.Add64(p%[1]d, c, 0)", i+j)
+				continue
+			}
+			p("hi, mlo = bits.Mul64(m, q%d)", j)
+			p("mlo, cc = bits.Add64(mlo, p%d, 0)", i+j)
+			p("hi += cc")
+			p("p%d, cc = bits.Add64(mlo, c, 0)", i+j)
+			p("c = hi + cc")
+		}
+		p("p%d, c1 = bits.Add64(p%[1]d, c, 0)", i+k)
+		p("p%d, c2 = bits.Add64(p%[1]d, ov, 0)", i+k)
+		p("ov = c1 + c2")
+	}
+	p("")
+	g.reduceOnce("p", k, "ov")
+	p("return z")
+	p("}")
+}
+
+// reduceOnce writes the masked subtraction that sets z to t mod q for a
+// value t below 2q, held in the limb variables <prefix><off>..<prefix><off+k-1>
+// with its bit above them in top.
+func (g *gen) reduceOnce(prefix string, off int, top string) {
+	p, k := g.p, g.k
+
+	p("// t < 2q; subtract q when t ≥ q, selected by mask rather than branch")
+	p("var %s, b uint64", joinf(k, ", ", "u%d"))
+	for j := range k {
+		p("u%d, b = bits.Sub64(%s%d, q%[1]d, b)", j, prefix, off+j)
+	}
+	p("keep := -((%s | (b ^ 1)) & 1)", top)
+	for j := range k {
+		p("z[%d] = u%[1]d&keep | %s%d&^keep", j, prefix, off+j)
+	}
+}
+
+// limbs returns x as k little-endian 64-bit limbs.
+func limbs(x *big.Int, k int) []uint64 {
+	w := make([]uint64, k)
+	for i := range w {
+		w[i] = new(big.Int).Rsh(x, uint(64*i)).Uint64()
+	}
+	return w
+}
+
+func hexList(w []uint64) string {
+	s := make([]string, len(w))
+	for i, x := range w {
+		s[i] = fmt.Sprintf("0x%016x", x)
+	}
+	return strings.Join(s, ", ")
+}
+
+// joinf formats each of 0..n-1 with format and joins the results with sep.
+func joinf(n int, sep, format string) string {
+	s := make([]string, n)
+	for i := range s {
+		s[i] = fmt.Sprintf(format, i)
+	}
+	return strings.Join(s, sep)
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blck-snwmn/arithmetic-vault/montgomery"
+)
+
+// checkTest is the test written next to each generated package, comparing
+// every operation with math/big on edge values and random elements.
+const checkTest = `package PKG
+
+import (
+	"math/big"
+	"math/rand/v2"
+	"testing"
+)
+
+var q, _ = new(big.Int).SetString("MODULUS", 16)
+
+var r = new(big.Int).Lsh(big.NewInt(1), 64*Limbs)
+
+func set(t *testing.T, v *big.Int) *Element {
+	var z Element
+	if !z.SetBytes(v.FillBytes(make([]byte, Size))) {
+		t.Fatalf("SetBytes rejected %v", v)
+	}
+	return &z
+}
+
+func get(z *Element) *big.Int {
+	b := z.Bytes()
+	return new(big.Int).SetBytes(b[:])
+}
+
+func TestAgainstBig(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	values := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2),
+		new(big.Int).Sub(q, big.NewInt(1)), new(big.Int).Sub(q, big.NewInt(2))}
+	for range 200 {
+		v := new(big.Int).Lsh(big.NewInt(int64(rng.Uint64()>>1)), uint(rng.IntN(64*Limbs)))
+		for range Limbs {
+			v.Lsh(v, 64).Or(v, new(big.Int).SetUint64(rng.Uint64()))
+		}
+		values = append(values, v.Mod(v, q))
+	}
+	for i, xv := range values {
+		yv := values[(i*7+3)%len(values)]
+		x, y := set(t, xv), set(t, yv)
+		if got := get(x); got.Cmp(xv) != 0 {
+			t.Fatalf("round trip of %v gave %v", xv, got)
+		}
+		mod := func(v *big.Int) *big.Int { return v.Mod(v, q) }
+		mont := func(v *big.Int) *big.Int { return mod(v.Mul(v, r)) }
+		cases := []struct {
+			op   string
+			got  *Element
+			want *big.Int
+		}{
+			{"Add", new(Element).Add(x, y), mod(new(big.Int).Add(xv, yv))},
+			{"Sub", new(Element).Sub(x, y), mod(new(big.Int).Sub(xv, yv))},
+			{"Neg", new(Element).Neg(x), mod(new(big.Int).Neg(xv))},
+			{"Mul", new(Element).Mul(x, y), mod(new(big.Int).Mul(xv, yv))},
+			{"Square", new(Element).Square(x), mod(new(big.Int).Mul(xv, xv))},
+		}
+		for _, c := range cases {
+			if got := get(c.got); got.Cmp(c.want) != 0 {
+				t.Errorf("%s(%v, %v) = %v, want %v", c.op, xv, yv, got, c.want)
+			}
+			if limbs(c.got[:]).Cmp(q) >= 0 {
+				t.Errorf("%s(%v, %v): limbs not reduced below q", c.op, xv, yv)
+			}
+		}
+		// the raw limbs are x·R mod q
+		if got := limbs(x[:]); got.Cmp(mont(new(big.Int).Set(xv))) != 0 {
+			t.Errorf("limbs of %v are %v, not x·R mod q", xv, got)
+		}
+		sq := new(Element).Set(x)
+		if sq.Square(sq); !sq.Equal(new(Element).Mul(x, x)) {
+			t.Errorf("Square(%v) in place differs from Mul", xv)
+		}
+		if x.IsZero() != (xv.Sign() == 0) || !x.Equal(set(t, xv)) {
+			t.Errorf("IsZero or Equal wrong for %v", xv)
+		}
+	}
+
+	var z Element
+	if z.SetBytes(q.FillBytes(make([]byte, Size))) || z.SetBytes(make([]byte, Size+1)) {
+		t.Error("SetBytes accepted q or a long encoding")
+	}
+	if got := get(new(Element).SetOne()); got.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("SetOne gave %v", got)
+	}
+	v := uint64(0xfedcba9876543210)
+	if got, want := get(new(Element).SetUint64(v)), new(big.Int).Mod(new(big.Int).SetUint64(v), q); got.Cmp(want) != 0 {
+		t.Errorf("SetUint64 gave %v, want %v", got, want)
+	}
+}
+
+func (z *Element) Set(x *Element) *Element { *z = *x; return z }
+
+func limbs(l []uint64) *big.Int {
+	v := new(big.Int)
+	for i := len(l) - 1; i >= 0; i-- {
+		v.Lsh(v, 64).Or(v, new(big.Int).SetUint64(l[i]))
+	}
+	return v
+}
+`
+
+// TestGenerate builds the package generated for each modulus and runs
+// checkTest against it.
+func TestGenerate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the generated packages with the go command")
+	}
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	named := func(name string) *big.Int {
+		p, err := montgomery.LookupParams(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p.N
+	}
+	hex := func(s string) *big.Int {
+		v, _ := new(big.Int).SetString(s, 16)
+		return v
+	}
+	moduli := map[string]*big.Int{
+		"p256":       named("p256"),
+		"p384":       named("p384"),
+		"p521":       named("p521"),
+		"curve25519": named("curve25519"),
+		"mersenne61": hex("1fffffffffffffff"),
+		"word":       hex("ffffffffffffffc5"),
+		"allones":    hex("ffffffffffffffffffffffffffffffff"),
+		"composite":  hex("9a3c5e7f1b2d4c6e8a0b1c2d3e4f506172839405a6b7c8d9"),
+		"three":      big.NewInt(3),
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module montgentest\n\ngo 1.25\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for name, q := range moduli {
+		src, err := generate(config{Q: q, Package: name, Type: "Element", Source: "-modulus " + q.String()})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		pkg := filepath.Join(dir, name)
+		test := strings.NewReplacer("PKG", name, "MODULUS", q.Text(16)).Replace(checkTest)
+		if err := os.Mkdir(pkg, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(pkg, "element.go"), src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(pkg, "element_test.go"), []byte(test), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command(goCmd, "test", "./...")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go test: %v\n%s", err, out)
+	}
+}
+
+func TestGenerate_invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		c    config
+	}{
+		{"even", config{Q: big.NewInt(1 << 20), Package: "fp", Type: "Element"}},
+		{"one", config{Q: big.NewInt(1), Package: "fp", Type: "Element"}},
+		{"negative", config{Q: big.NewInt(-7), Package: "fp", Type: "Element"}},
+		{"package", config{Q: big.NewInt(7), Package: "f-p", Type: "Element"}},
+		{"unexported", config{Q: big.NewInt(7), Package: "fp", Type: "element"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := generate(tt.c); err == nil {
+				t.Errorf("generate(%v) succeeded", tt.c.Q)
+			}
+		})
+	}
+}
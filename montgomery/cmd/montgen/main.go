@@ -0,0 +1,70 @@
+// Command montgen writes a standalone Go source file of Montgomery
+// arithmetic for one fixed odd modulus, in the manner of fiat-crypto: the
+// limbs of the modulus and -q⁻¹ mod 2^64 become constants, every loop over
+// limbs is unrolled, and the final subtractions are masked rather than
+// branched on, so Mul, Square, Add, Sub and Neg run in constant time. The
+// generated package imports only encoding/binary and math/bits, and has no
+// dependency on this module or on math/big.
+//
+// The modulus is either one of the named parameter sets of LookupParams or
+// given in decimal or 0x-prefixed hexadecimal:
+//
+//	go run ./cmd/montgen -name p256 -package p256 -o p256/element.go
+//	go run ./cmd/montgen -modulus 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001 -package fr
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/blck-snwmn/arithmetic-vault/montgomery"
+)
+
+func main() {
+	name := flag.String("name", "", "named modulus, as accepted by montgomery.LookupParams")
+	modulus := flag.String("modulus", "", "odd modulus in decimal or 0x-prefixed hexadecimal")
+	pkg := flag.String("package", "fp", "package name of the generated file")
+	typ := flag.String("type", "Element", "name of the generated element type")
+	out := flag.String("o", "", "output file (default standard output)")
+	flag.Parse()
+
+	if err := run(*name, *modulus, *pkg, *typ, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "montgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(name, modulus, pkg, typ, out string) error {
+	var q *big.Int
+	var source string
+	switch {
+	case name != "" && modulus != "":
+		return fmt.Errorf("-name and -modulus are mutually exclusive")
+	case name != "":
+		p, err := montgomery.LookupParams(name)
+		if err != nil {
+			return err
+		}
+		q, source = p.N, "-name "+name
+	case modulus != "":
+		var ok bool
+		if q, ok = new(big.Int).SetString(modulus, 0); !ok {
+			return fmt.Errorf("cannot parse -modulus %q", modulus)
+		}
+		source = "-modulus " + modulus
+	default:
+		return fmt.Errorf("one of -name or -modulus is required")
+	}
+
+	src, err := generate(config{Q: q, Package: pkg, Type: typ, Source: source})
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
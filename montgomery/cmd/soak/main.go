@@ -0,0 +1,386 @@
+// Command soak runs randomized cross-implementation checks for as long as
+// it is asked to, to qualify the assembly kernels and any registered
+// backend on the hardware at hand before trusting them.
+//
+// Every iteration draws a modulus size and shape, operands at the edges of
+// [0, N) and at random, and an exponent, and checks against math/big:
+//
+//   - Mul and Exp of every registered backend, through montgomery.SelfCheck
+//   - MulWords, ExpConstantTime and ExpLadder of MontgomeryCIOSWords
+//   - Vector.MulBatch
+//
+// Out-of-tree backends register themselves on import, so checking one takes
+// a copy of this command that imports its package for the side effect.
+//
+// A disagreement, error or panic is written as JSON to the -dump directory,
+// with the inputs, both results and the seed and iteration that replay it.
+// A progress line goes to stderr every -report, and the exit status is 1 if
+// anything failed.
+//
+//	go run ./cmd/soak -duration 8h
+//	go run ./cmd/soak -duration 10m -bits 256,384 -backends cioswords,ct
+//	go run ./cmd/soak -seed 42 -replay 1337
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	mrand "math/rand/v2"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/blck-snwmn/arithmetic-vault/montgomery"
+)
+
+func main() {
+	duration := flag.Duration("duration", time.Hour, "how long to run; 0 runs until interrupted")
+	sizes := flag.String("bits", "64,128,256,384,512,1024,2048,4096", "comma-separated modulus sizes in bits")
+	backends := flag.String("backends", "", "comma-separated backends to check (default all registered)")
+	expBits := flag.Int("exp-bits", 256, "maximum exponent size in bits")
+	workers := flag.Int("workers", runtime.GOMAXPROCS(0), "number of concurrent workers")
+	seed := flag.Uint64("seed", uint64(time.Now().UnixNano()), "seed of the run")
+	replay := flag.Uint64("replay", 0, "run only this iteration of -seed, as named in a dump")
+	dump := flag.String("dump", "soak-failures", "directory for failure dumps")
+	report := flag.Duration("report", time.Minute, "interval between progress lines")
+	maxFailures := flag.Int64("max-failures", 10, "stop after this many failures; 0 never stops")
+	flag.Parse()
+
+	s, err := newSoak(*sizes, *backends, *expBits, *seed, *dump)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "soak:", err)
+		os.Exit(2)
+	}
+	s.maxFailures = *maxFailures
+
+	if *replay != 0 {
+		s.iteration(*replay)
+		fmt.Fprintf(os.Stderr, "soak: iteration %d, %d checks, %d failures\n", *replay, s.checks.Load(), s.failures.Load())
+	} else {
+		s.run(*duration, *workers, *report)
+	}
+	if n := s.failures.Load(); n > 0 {
+		fmt.Fprintf(os.Stderr, "soak: %d failures, dumped to %s\n", n, s.dump)
+		os.Exit(1)
+	}
+}
+
+// soak is the configuration and counters of a run.
+type soak struct {
+	sizes       []int
+	backends    []string
+	expBits     int
+	seed        uint64
+	dump        string
+	maxFailures int64
+
+	next     atomic.Uint64 // last iteration handed out
+	checks   atomic.Uint64
+	failures atomic.Int64
+	stop     context.CancelFunc
+}
+
+func newSoak(sizes, backends string, expBits int, seed uint64, dump string) (*soak, error) {
+	s := &soak{expBits: expBits, seed: seed, dump: dump, stop: func() {}}
+	for f := range strings.SplitSeq(sizes, ",") {
+		bits, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || bits < 2 {
+			return nil, fmt.Errorf("-bits: invalid size %q", f)
+		}
+		s.sizes = append(s.sizes, bits)
+	}
+	if expBits < 1 {
+		return nil, fmt.Errorf("-exp-bits must be positive, got %d", expBits)
+	}
+
+	registered := montgomery.Backends()
+	s.backends = registered
+	if backends != "" {
+		s.backends = nil
+		for name := range strings.SplitSeq(backends, ",") {
+			name = strings.TrimSpace(name)
+			if !slices.Contains(registered, name) {
+				return nil, fmt.Errorf("-backends: %q is not registered (have %s)", name, strings.Join(registered, ", "))
+			}
+			s.backends = append(s.backends, name)
+		}
+	}
+	return s, nil
+}
+
+// run runs workers until the duration elapses, an interrupt arrives or
+// maxFailures is reached.
+func (s *soak) run(duration time.Duration, workers int, report time.Duration) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+	ctx, s.stop = context.WithCancel(ctx)
+	defer s.stop()
+
+	fmt.Fprintf(os.Stderr, "soak: seed %d, %d workers, bits %v, backends %s\n",
+		s.seed, workers, s.sizes, strings.Join(s.backends, ", "))
+	start := time.Now()
+	progress := func() {
+		fmt.Fprintf(os.Stderr, "soak: %v, %d iterations, %d checks, %d failures\n",
+			time.Since(start).Round(time.Second), s.next.Load(), s.checks.Load(), s.failures.Load())
+	}
+
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Go(func() {
+			for ctx.Err() == nil {
+				s.iteration(s.next.Add(1))
+			}
+		})
+	}
+	ticker := time.NewTicker(report)
+	defer ticker.Stop()
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for {
+		select {
+		case <-ticker.C:
+			progress()
+		case <-done:
+			progress()
+			return
+		}
+	}
+}
+
+// iteration runs every check on the modulus and operands drawn for
+// iteration it, which depend only on the seed and it.
+func (s *soak) iteration(it uint64) {
+	rng := mrand.New(mrand.NewPCG(s.seed, it))
+	bits := s.sizes[rng.IntN(len(s.sizes))]
+	N, shape := modulus(rng, bits)
+	R := new(big.Int).Lsh(big.NewInt(1), uint(64*((bits+63)/64)))
+	pairs := operands(rng, N)
+	exp := randomBits(rng, 1+rng.IntN(s.expBits))
+	base := pairs[len(pairs)-1][0]
+
+	c := &checker{s: s, it: it, bits: bits, shape: shape, N: N, R: R}
+	for _, name := range s.backends {
+		c.run("backend."+name, func() error {
+			m, err := montgomery.Open(R, N, montgomery.WithBackend(name))
+			if err != nil {
+				return err
+			}
+			sc := montgomery.NewSelfCheck(m, nil, 1)
+			for _, p := range pairs {
+				if _, err := sc.Mul(p[0], p[1]); err != nil {
+					return err
+				}
+			}
+			_, err = sc.Exp(base, exp)
+			return err
+		})
+	}
+
+	w := montgomery.NewMontgomeryCIOSWords(R, N)
+	c.run("cioswords.MulWords", func() error {
+		z := make([]uint64, w.S)
+		for _, p := range pairs {
+			w.MulWords(z, w.ToWords(p[0]), w.ToWords(p[1]))
+			if err := compare("MulWords", w.FromWords(z), mulMod(p[0], p[1], N), p[0], p[1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	want := new(big.Int).Exp(base, exp, N)
+	c.run("cioswords.ExpConstantTime", func() error {
+		return compare("ExpConstantTime", w.ExpConstantTime(base, exp), want, base, exp)
+	})
+	c.run("cioswords.ExpLadder", func() error {
+		return compare("ExpLadder", w.ExpLadder(base, exp), want, base, exp)
+	})
+
+	c.run("vector.MulBatch", func() error {
+		v, err := montgomery.NewVector(N)
+		if err != nil {
+			return err
+		}
+		for i, got := range v.MulBatch(pairs) {
+			if err := compare("MulBatch", got, mulMod(pairs[i][0], pairs[i][1], N), pairs[i][0], pairs[i][1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// modulus returns an odd modulus of exactly bits bits, or of fewer within
+// the same limbs, in one of the shapes most likely to expose carry bugs.
+func modulus(rng *mrand.Rand, bits int) (*big.Int, string) {
+	one := big.NewInt(1)
+	switch rng.IntN(5) {
+	case 0:
+		// All ones: every limb of N is 2^64-1
+		return new(big.Int).Sub(new(big.Int).Lsh(one, uint(bits)), one), "ones"
+	case 1:
+		// 2^(bits-1)+1: the final subtraction is rare
+		return new(big.Int).Add(new(big.Int).Lsh(one, uint(bits-1)), one), "low"
+	case 2:
+		// Well short of the top of its limbs, so R is far above N
+		if short := bits - 63; bits%64 == 0 && short >= 2 {
+			N := randomBits(rng, short)
+			return N.SetBit(N, 0, 1), "short"
+		}
+	}
+	N := randomBits(rng, bits)
+	return N.SetBit(N, 0, 1), "random"
+}
+
+// operands returns pairs in [0, N): the edges 0, 1, N-1 and N-2 against
+// random values and each other, then random pairs.
+func operands(rng *mrand.Rand, N *big.Int) [][2]*big.Int {
+	one := big.NewInt(1)
+	random := func() *big.Int {
+		return new(big.Int).Mod(randomBits(rng, N.BitLen()+64), N)
+	}
+	top := new(big.Int).Sub(N, one)
+	edges := []*big.Int{new(big.Int), one, top, new(big.Int).Mod(new(big.Int).Sub(top, one), N)}
+	pairs := [][2]*big.Int{
+		{top, top},
+		{edges[rng.IntN(len(edges))], random()},
+		{random(), edges[rng.IntN(len(edges))]},
+	}
+	for range 2 + rng.IntN(7) {
+		pairs = append(pairs, [2]*big.Int{random(), random()})
+	}
+	return pairs
+}
+
+// randomBits returns a random integer of exactly bits bits.
+func randomBits(rng *mrand.Rand, bits int) *big.Int {
+	b := make([]byte, (bits+7)/8)
+	for i := range b {
+		b[i] = byte(rng.Uint32())
+	}
+	x := new(big.Int).SetBytes(b)
+	x.Rsh(x, uint(8*len(b)-bits))
+	return x.SetBit(x, bits-1, 1)
+}
+
+func mulMod(x, y, N *big.Int) *big.Int {
+	z := new(big.Int).Mul(x, y)
+	return z.Mod(z, N)
+}
+
+func compare(op string, got, want *big.Int, inputs ...*big.Int) error {
+	if got.Cmp(want) != 0 {
+		return &montgomery.MismatchError{Op: op, Inputs: inputs, Got: got, Want: want}
+	}
+	return nil
+}
+
+// checker runs the checks of one iteration and dumps their failures.
+type checker struct {
+	s     *soak
+	it    uint64
+	bits  int
+	shape string
+	N, R  *big.Int
+}
+
+// failure is the JSON dump of a failed check. Numbers are hexadecimal.
+type failure struct {
+	Time      string   `json:"time"`
+	Seed      uint64   `json:"seed"`
+	Iteration uint64   `json:"iteration"`
+	Check     string   `json:"check"`
+	Bits      int      `json:"bits"`
+	Shape     string   `json:"shape"`
+	N         string   `json:"n"`
+	R         string   `json:"r"`
+	Op        string   `json:"op,omitempty"`
+	Inputs    []string `json:"inputs,omitempty"`
+	Got       string   `json:"got,omitempty"`
+	Want      string   `json:"want,omitempty"`
+	Error     string   `json:"error"`
+	Platform  string   `json:"platform"`
+}
+
+// run runs one check, turning a panic into a failure.
+func (c *checker) run(name string, check func() error) {
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return check()
+	}()
+	c.s.checks.Add(1)
+	if err != nil {
+		c.fail(name, err)
+	}
+}
+
+func (c *checker) fail(name string, err error) {
+	f := failure{
+		Time:      time.Now().UTC().Format(time.RFC3339),
+		Seed:      c.s.seed,
+		Iteration: c.it,
+		Check:     name,
+		Bits:      c.bits,
+		Shape:     c.shape,
+		N:         c.N.Text(16),
+		R:         c.R.Text(16),
+		Error:     err.Error(),
+		Platform:  fmt.Sprintf("%s/%s %s", runtime.GOOS, runtime.GOARCH, runtime.Version()),
+	}
+	var me *montgomery.MismatchError
+	if errors.As(err, &me) {
+		f.Op, f.Got, f.Want = me.Op, me.Got.Text(16), me.Want.Text(16)
+		for _, x := range me.Inputs {
+			f.Inputs = append(f.Inputs, x.Text(16))
+		}
+	}
+
+	// The numbers of a mismatch go to the dump only
+	msg := err.Error()
+	if f.Op != "" {
+		msg = f.Op + " mismatch"
+	}
+	n := c.s.failures.Add(1)
+	fmt.Fprintf(os.Stderr, "soak: FAIL %s at iteration %d (%d-bit %s N): %s\n", name, c.it, c.bits, c.shape, msg)
+	if err := c.s.write(f); err != nil {
+		fmt.Fprintln(os.Stderr, "soak: dump:", err)
+	}
+	if c.s.maxFailures > 0 && n >= c.s.maxFailures {
+		c.s.stop()
+	}
+}
+
+// write saves f as dump/<seed>-<iteration>-<check>.json.
+func (s *soak) write(f failure) error {
+	if err := os.MkdirAll(s.dump, 0o755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d-%d-%s.json", f.Seed, f.Iteration, f.Check)
+	return os.WriteFile(filepath.Join(s.dump, name), append(b, '\n'), 0o644)
+}
@@ -0,0 +1,176 @@
+package montgomery
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	mrand "math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// The benchmarks in this file run the same inputs through the vault's
+// implementations and through math/big and crypto/rsa, with sub-benchmark
+// names in benchstat's key=value form:
+//
+//	BenchmarkCompare/op=Exp/bits=2048/impl=cioswords
+//
+// so one run compares implementations side by side:
+//
+//	go test -run '^$' -bench Compare -count 10 > cmp.txt
+//	benchstat -col /impl cmp.txt
+//
+// and two runs on different commits compare one implementation over time.
+
+// compareSizes are the modulus sizes the comparison covers.
+var compareSizes = []int{256, 1024, 2048, 4096}
+
+// compareImpl is one contestant: a name for the impl= key and the
+// operations it offers. A nil operation is skipped.
+type compareImpl struct {
+	name string
+	mul  func(x, y *big.Int) *big.Int
+	exp  func(x, e *big.Int) *big.Int
+	inv  func(x *big.Int) *big.Int
+}
+
+// compareImpls returns the contestants for modulus N and R = 2^bits. The
+// vault inverts by Fermat's little theorem, x^(N-2), which needs N prime.
+func compareImpls(R, N *big.Int) []compareImpl {
+	nMinus2 := new(big.Int).Sub(N, big.NewInt(2))
+	var impls []compareImpl
+	for _, c := range montContexts(R, N) {
+		impl := compareImpl{name: strings.ToLower(c.name), mul: c.m.Mul}
+		// Bitwise exponentiation takes seconds at 4096 bits; only Mul is
+		// worth comparing.
+		if c.name != "Bitwise" {
+			impl.exp = c.m.Exp
+			impl.inv = func(x *big.Int) *big.Int { return c.m.Exp(x, nMinus2) }
+		}
+		impls = append(impls, impl)
+	}
+	return append(impls, compareImpl{
+		name: "big",
+		mul: func(x, y *big.Int) *big.Int {
+			z := new(big.Int).Mul(x, y)
+			return z.Mod(z, N)
+		},
+		exp: func(x, e *big.Int) *big.Int { return new(big.Int).Exp(x, e, N) },
+		inv: func(x *big.Int) *big.Int { return new(big.Int).ModInverse(x, N) },
+	})
+}
+
+// compareParams returns a deterministic prime modulus of the given size,
+// R = 2^bits and two operands below it.
+func compareParams(bits int) (x, y, R, N *big.Int) {
+	rng := mrand.NewChaCha8([32]byte{byte(bits), byte(bits >> 8)})
+	N, err := rand.Prime(rng, bits)
+	if err != nil {
+		panic(err)
+	}
+	random := func() *big.Int {
+		b := make([]byte, bits/8)
+		rng.Read(b)
+		return new(big.Int).Mod(new(big.Int).SetBytes(b), N)
+	}
+	return random(), random(), new(big.Int).Lsh(big.NewInt(1), uint(bits)), N
+}
+
+func TestCompareImpls(t *testing.T) {
+	t.Parallel()
+
+	// The contestants must agree, or the comparison is meaningless.
+	x, y, R, N := compareParams(256)
+	e := new(big.Int).Sub(N, big.NewInt(12345))
+	impls := compareImpls(R, N)
+	ref := impls[len(impls)-1]
+	for _, impl := range impls {
+		if impl.mul != nil && impl.mul(x, y).Cmp(ref.mul(x, y)) != 0 {
+			t.Errorf("%s: Mul disagrees with math/big", impl.name)
+		}
+		if impl.exp != nil && impl.exp(x, e).Cmp(ref.exp(x, e)) != 0 {
+			t.Errorf("%s: Exp disagrees with math/big", impl.name)
+		}
+		if impl.inv != nil && impl.inv(x).Cmp(ref.inv(x)) != 0 {
+			t.Errorf("%s: Inv disagrees with math/big", impl.name)
+		}
+	}
+}
+
+func BenchmarkCompare(b *testing.B) {
+	for _, bits := range compareSizes {
+		x, y, R, N := compareParams(bits)
+		e := new(big.Int).Sub(N, big.NewInt(1))
+		for _, impl := range compareImpls(R, N) {
+			name := func(op string) string {
+				return fmt.Sprintf("op=%s/bits=%d/impl=%s", op, bits, impl.name)
+			}
+			if impl.mul != nil {
+				b.Run(name("Mul"), func(b *testing.B) {
+					for b.Loop() {
+						impl.mul(x, y)
+					}
+				})
+			}
+			if impl.exp != nil {
+				b.Run(name("Exp"), func(b *testing.B) {
+					for b.Loop() {
+						impl.exp(x, e)
+					}
+				})
+			}
+			if impl.inv != nil {
+				b.Run(name("Inv"), func(b *testing.B) {
+					for b.Loop() {
+						impl.inv(x)
+					}
+				})
+			}
+		}
+	}
+}
+
+// BenchmarkCompareRSA measures the RSA public operation s^65537 mod n,
+// where crypto/rsa's own constant-time Montgomery arithmetic can be reached
+// through the public API: VerifyPKCS1v15 is one such exponentiation plus a
+// padding comparison that costs next to nothing.
+func BenchmarkCompareRSA(b *testing.B) {
+	for _, bits := range []int{2048, 4096} {
+		key, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			b.Fatal(err)
+		}
+		digest := sha256.Sum256([]byte("benchmark"))
+		sig, err := rsa.SignPKCS1v15(nil, key, crypto.SHA256, digest[:])
+		if err != nil {
+			b.Fatal(err)
+		}
+		s, e := new(big.Int).SetBytes(sig), big.NewInt(int64(key.E))
+		R := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+
+		name := func(impl string) string {
+			return fmt.Sprintf("op=ExpPublic/bits=%d/impl=%s", bits, impl)
+		}
+		b.Run(name("cioswords"), func(b *testing.B) {
+			m := NewMontgomeryCIOSWords(R, key.N)
+			for b.Loop() {
+				m.Exp(s, e)
+			}
+		})
+		b.Run(name("big"), func(b *testing.B) {
+			for b.Loop() {
+				new(big.Int).Exp(s, e, key.N)
+			}
+		})
+		b.Run(name("crypto-rsa"), func(b *testing.B) {
+			for b.Loop() {
+				if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Factor is a prime power P^E in the factorization of a composite modulus.
+type Factor struct {
+	P *big.Int // an odd prime
+	E int      // its exponent, at least 1
+}
+
+// CompositeCtx is arithmetic modulo a composite N = Π pᵢ^eᵢ whose
+// factorization is known, as it is to the key holder in RSA and Paillier
+// (N = p²q² there). Every operation runs on the prime-power components,
+// each with its own Montgomery context, and the results are recombined
+// with Garner's formula on the way out.
+//
+// The gain is in Exp. A component of a third of the size costs about a
+// twenty-seventh of the full exponentiation, and for a base coprime to p
+// the exponent shrinks modulo φ(p^e) = p^(e-1)·(p-1) too. Inv lifts the
+// inverse mod p to p^e by Hensel's lemma instead of running an extended
+// GCD on N. Mul gains nothing from the split and is there for
+// completeness.
+//
+// Operands may be any integer; they are reduced modulo each component
+// whatever the InputPolicy, since their residues are taken anyway. A
+// CompositeCtx is immutable and safe for concurrent use.
+type CompositeCtx struct {
+	n     *big.Int
+	comps []component
+	g     garner
+}
+
+// component is one prime power p^e of a CompositeCtx.
+type component struct {
+	p, pe *big.Int
+	e     int
+	phi   *big.Int   // φ(p^e) = p^(e-1)·(p-1)
+	lifts []*big.Int // p^2, p^4, … up to p^e: the moduli of the Hensel steps
+	m     *MontgomeryCIOSWords
+}
+
+// NewCompositeCtx precomputes arithmetic modulo the product of factors.
+// The primes must be odd and distinct; they are not tested for
+// primality. An even prime, a prime below 3 or an exponent below 1 is
+// rejected with an error wrapping ErrInvalidParameters, and repeated or
+// non-coprime primes with ErrNotInvertible. opts are passed to every
+// component context, each with the smallest word-aligned R above its p^e.
+func NewCompositeCtx(factors []Factor, opts ...Option) (*CompositeCtx, error) {
+	if len(factors) == 0 {
+		return nil, fmt.Errorf("%w: no factors", ErrInvalidParameters)
+	}
+	c := &CompositeCtx{n: big.NewInt(1), comps: make([]component, len(factors))}
+	moduli := make([]*big.Int, len(factors))
+	for i, f := range factors {
+		if f.E < 1 {
+			return nil, fmt.Errorf("%w: exponent %d of factor %d", ErrInvalidParameters, f.E, i)
+		}
+		if f.P.Bit(0) == 0 {
+			return nil, ErrEvenModulus
+		}
+		if f.P.Cmp(big.NewInt(3)) < 0 {
+			return nil, ErrModulusTooSmall
+		}
+		p := new(big.Int).Set(f.P)
+		pe := new(big.Int).Exp(p, big.NewInt(int64(f.E)), nil)
+		m, err := newWordAligned(pe, opts)
+		if err != nil {
+			return nil, err
+		}
+		phi := new(big.Int).Exp(p, big.NewInt(int64(f.E-1)), nil)
+		phi.Mul(phi, new(big.Int).Sub(p, big.NewInt(1)))
+		var lifts []*big.Int
+		for k := 2; k < 2*f.E; k *= 2 {
+			lifts = append(lifts, new(big.Int).Exp(p, big.NewInt(int64(min(k, f.E))), nil))
+		}
+		c.comps[i] = component{p: p, pe: pe, e: f.E, phi: phi, lifts: lifts, m: m}
+		moduli[i] = pe
+		c.n.Mul(c.n, pe)
+	}
+	g, err := newGarner(moduli)
+	if err != nil {
+		return nil, err
+	}
+	c.g = g
+	return c, nil
+}
+
+// Modulus returns N.
+func (c *CompositeCtx) Modulus() *big.Int { return new(big.Int).Set(c.n) }
+
+// Mul returns x·y mod N.
+func (c *CompositeCtx) Mul(x, y *big.Int) *big.Int {
+	return c.each(func(k *component) *big.Int {
+		return k.m.Mul(k.reduce(x), k.reduce(y))
+	})
+}
+
+// Exp returns x^d mod N with the semantics of big.Int.Exp: a negative d
+// raises the inverse of x, or yields nil if x is not invertible mod N.
+func (c *CompositeCtx) Exp(x, d *big.Int) *big.Int {
+	if d.Sign() < 0 {
+		if x = c.Inv(x); x == nil {
+			return nil
+		}
+		d = new(big.Int).Neg(d)
+	}
+	return c.each(func(k *component) *big.Int { return k.exp(k.reduce(x), d) })
+}
+
+// Inv returns x⁻¹ mod N, or nil if x shares a factor with N.
+func (c *CompositeCtx) Inv(x *big.Int) *big.Int {
+	r := make([]*big.Int, len(c.comps))
+	for i := range c.comps {
+		if r[i] = c.comps[i].inv(c.comps[i].reduce(x)); r[i] == nil {
+			return nil
+		}
+	}
+	return c.g.combine(r)
+}
+
+// each runs f on every component and recombines the residues.
+func (c *CompositeCtx) each(f func(k *component) *big.Int) *big.Int {
+	r := make([]*big.Int, len(c.comps))
+	for i := range c.comps {
+		r[i] = f(&c.comps[i])
+	}
+	return c.g.combine(r)
+}
+
+// reduce returns x mod p^e.
+func (k *component) reduce(x *big.Int) *big.Int {
+	return new(big.Int).Mod(x, k.pe)
+}
+
+// exp returns x^d mod p^e for x in [0, p^e) and d ≥ 0. Euler's theorem
+// shrinks d modulo φ(p^e) only for x coprime to p. A multiple of p has
+// x^d = 0 once d ≥ e, since p^d then divides it; below that d is small
+// and used as it is.
+func (k *component) exp(x, d *big.Int) *big.Int {
+	if new(big.Int).Mod(x, k.p).Sign() != 0 {
+		return k.m.Exp(x, new(big.Int).Mod(d, k.phi))
+	}
+	if d.Sign() > 0 && d.Cmp(big.NewInt(int64(k.e))) >= 0 {
+		return new(big.Int)
+	}
+	return k.m.Exp(x, d)
+}
+
+// inv returns x⁻¹ mod p^e for x in [0, p^e), or nil for a multiple of p.
+// The inverse mod p is lifted by Newton's iteration y ← y·(2 - x·y), each
+// step doubling the power of p it is correct modulo (Hensel's lemma).
+func (k *component) inv(x *big.Int) *big.Int {
+	y := new(big.Int).ModInverse(new(big.Int).Mod(x, k.p), k.p)
+	if y == nil {
+		return nil
+	}
+	two := big.NewInt(2)
+	for _, mod := range k.lifts {
+		t := new(big.Int).Mul(x, y)
+		t.Sub(two, t)
+		y.Mul(y, t).Mod(y, mod)
+	}
+	return y
+}
+
+// garner recombines residues modulo pairwise coprime moduli m₀, m₁, … into
+// the unique value below their product, in mixed radix: starting from
+// x = r₀, each step adds the multiple of m₀⋯mᵢ₋₁ that fixes the residue
+// mod mᵢ, using the precomputed cᵢ = (m₀⋯mᵢ₋₁)⁻¹ mod mᵢ.
+type garner struct {
+	m, c []*big.Int
+}
+
+// newGarner precomputes the recombination for copies of moduli, returning
+// ErrNotInvertible unless they are pairwise coprime.
+func newGarner(moduli []*big.Int) (garner, error) {
+	g := garner{m: make([]*big.Int, len(moduli)), c: make([]*big.Int, len(moduli))}
+	prod := big.NewInt(1)
+	for i, mi := range moduli {
+		g.m[i] = new(big.Int).Set(mi)
+		if i > 0 {
+			if g.c[i] = new(big.Int).ModInverse(prod, mi); g.c[i] == nil {
+				return garner{}, ErrNotInvertible
+			}
+		}
+		prod = new(big.Int).Mul(prod, mi)
+	}
+	return g, nil
+}
+
+// combine returns the x below Π mᵢ with x ≡ r[i] (mod m[i]), for every
+// r[i] in [0, m[i]).
+func (g garner) combine(r []*big.Int) *big.Int {
+	x := new(big.Int).Set(r[0])
+	prod := new(big.Int).Set(g.m[0])
+	u := new(big.Int)
+	for i := 1; i < len(r); i++ {
+		u.Sub(r[i], x)
+		u.Mul(u, g.c[i]).Mod(u, g.m[i])
+		x.Add(x, u.Mul(u, prod))
+		prod.Mul(prod, g.m[i])
+	}
+	return x
+}
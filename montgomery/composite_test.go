@@ -0,0 +1,191 @@
+package montgomery
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	mrand "math/rand/v2"
+	"testing"
+	"testing/quick"
+)
+
+// testFactors returns the factorization p²·q·r³ of a composite modulus,
+// with deterministic primes of the given size.
+func testFactors(bits int) []Factor {
+	rng := mrand.NewChaCha8([32]byte{'c', 'o', 'm', 'p', byte(bits), byte(bits >> 8)})
+	primes := make([]*big.Int, 0, 3)
+	for len(primes) < 3 {
+		p, err := rand.Prime(rng, bits)
+		if err != nil {
+			panic(err)
+		}
+		if !containsInt(primes, p) {
+			primes = append(primes, p)
+		}
+	}
+	return []Factor{{primes[0], 2}, {primes[1], 1}, {primes[2], 3}}
+}
+
+func containsInt(xs []*big.Int, y *big.Int) bool {
+	for _, x := range xs {
+		if x.Cmp(y) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCompositeCtx(t *testing.T) {
+	t.Parallel()
+
+	factors := testFactors(128)
+	c, err := NewCompositeCtx(factors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := c.Modulus()
+	p := factors[0].P
+	x := new(big.Int).Sub(n, big.NewInt(12345))
+	y := new(big.Int).Rsh(n, 3)
+	d := new(big.Int).Sub(n, big.NewInt(99))
+
+	mod := func(z *big.Int) *big.Int { return z.Mod(z, n) }
+	tests := []struct {
+		name string
+		got  func() *big.Int
+		want *big.Int
+	}{
+		{"Mul", func() *big.Int { return c.Mul(x, y) }, mod(new(big.Int).Mul(x, y))},
+		{"Mul unreduced", func() *big.Int { return c.Mul(new(big.Int).Neg(x), new(big.Int).Add(y, n)) }, mod(new(big.Int).Mul(new(big.Int).Neg(x), y))},
+		{"Exp", func() *big.Int { return c.Exp(x, d) }, new(big.Int).Exp(x, d, n)},
+		{"Exp zero exponent", func() *big.Int { return c.Exp(p, big.NewInt(0)) }, big.NewInt(1)},
+		{"Exp multiple of p, short exponent", func() *big.Int { return c.Exp(p, big.NewInt(1)) }, new(big.Int).Set(p)},
+		{"Exp multiple of p, long exponent", func() *big.Int { return c.Exp(p, d) }, new(big.Int).Exp(p, d, n)},
+		{"Exp negative", func() *big.Int { return c.Exp(x, big.NewInt(-5)) }, new(big.Int).Exp(new(big.Int).ModInverse(x, n), big.NewInt(5), n)},
+		{"Inv", func() *big.Int { return c.Inv(x) }, new(big.Int).ModInverse(x, n)},
+		{"Inv one", func() *big.Int { return c.Inv(big.NewInt(1)) }, big.NewInt(1)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tc.got(); got.Cmp(tc.want) != 0 {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCompositeCtx_notInvertible(t *testing.T) {
+	t.Parallel()
+
+	factors := testFactors(64)
+	c, err := NewCompositeCtx(factors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range []*big.Int{big.NewInt(0), factors[1].P, new(big.Int).Mul(factors[2].P, big.NewInt(5))} {
+		if got := c.Inv(x); got != nil {
+			t.Errorf("Inv(%v) = %v, want nil", x, got)
+		}
+		if got := c.Exp(x, big.NewInt(-1)); got != nil {
+			t.Errorf("Exp(%v, -1) = %v, want nil", x, got)
+		}
+	}
+}
+
+func TestCompositeCtxProperty(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewCompositeCtx(testFactors(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := c.Modulus()
+	err = quick.Check(func(xBytes, dBytes []byte) bool {
+		x := new(big.Int).SetBytes(xBytes)
+		d := new(big.Int).SetBytes(dBytes)
+		if c.Exp(x, d).Cmp(new(big.Int).Exp(x, d, n)) != 0 {
+			return false
+		}
+		want := new(big.Int).ModInverse(x, n)
+		got := c.Inv(x)
+		return (want == nil && got == nil) || (want != nil && got != nil && got.Cmp(want) == 0)
+	}, &quick.Config{MaxCount: 100})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewCompositeCtx_errors(t *testing.T) {
+	t.Parallel()
+
+	p := big.NewInt(1000003)
+	tests := []struct {
+		name    string
+		factors []Factor
+		wantErr error
+	}{
+		{"no factors", nil, ErrInvalidParameters},
+		{"zero exponent", []Factor{{p, 0}}, ErrInvalidParameters},
+		{"even prime", []Factor{{p, 1}, {big.NewInt(2), 3}}, ErrEvenModulus},
+		{"one", []Factor{{big.NewInt(1), 1}}, ErrModulusTooSmall},
+		{"repeated prime", []Factor{{p, 1}, {p, 2}}, ErrNotInvertible},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := NewCompositeCtx(tc.factors); !errors.Is(err, tc.wantErr) {
+				t.Errorf("NewCompositeCtx() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func Test_garner(t *testing.T) {
+	t.Parallel()
+
+	moduli := []*big.Int{big.NewInt(7), big.NewInt(11), big.NewInt(13), big.NewInt(15)}
+	g, err := newGarner(moduli)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x := int64(0); x < 7*11*13*15; x += 97 {
+		r := make([]*big.Int, len(moduli))
+		for i, m := range moduli {
+			r[i] = big.NewInt(x % m.Int64())
+		}
+		if got := g.combine(r); got.Int64() != x {
+			t.Errorf("combine(%v) = %v, want %d", r, got, x)
+		}
+	}
+}
+
+// BenchmarkCompositeCtx compares a Paillier-style exponentiation modulo
+// N = p²q² through the factorization with a single context for N.
+func BenchmarkCompositeCtx(b *testing.B) {
+	p, q := testPrimes(512)
+	c, err := NewCompositeCtx([]Factor{{p, 2}, {q, 2}})
+	if err != nil {
+		b.Fatal(err)
+	}
+	n := c.Modulus()
+	full, err := newWordAligned(n, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	x := new(big.Int).Sub(n, big.NewInt(99))
+	d := new(big.Int).Sub(n, big.NewInt(12345))
+
+	b.Run("impl=composite", func(b *testing.B) {
+		for b.Loop() {
+			c.Exp(x, d)
+		}
+	})
+	b.Run("impl=cioswords", func(b *testing.B) {
+		for b.Loop() {
+			full.Exp(x, d)
+		}
+	})
+}
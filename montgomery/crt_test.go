@@ -0,0 +1,311 @@
+package montgomery
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	mrand "math/rand/v2"
+	"testing"
+	"testing/quick"
+)
+
+func TestCRT_Exp(t *testing.T) {
+	t.Parallel()
+
+	p, q := testPrimes(512)
+	n := new(big.Int).Mul(p, q)
+	d := new(big.Int).Sub(n, big.NewInt(12345))
+	c, err := NewCRT(p, q, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Modulus().Cmp(n) != 0 {
+		t.Errorf("Modulus() = %v, want %v", c.Modulus(), n)
+	}
+
+	tests := []struct {
+		name string
+		x    *big.Int
+	}{
+		{"zero", big.NewInt(0)},
+		{"one", big.NewInt(1)},
+		{"multiple of p", new(big.Int).Mul(p, big.NewInt(7))},
+		{"multiple of q", new(big.Int).Set(q)},
+		{"N - 1", new(big.Int).Sub(n, big.NewInt(1))},
+		{"unreduced", new(big.Int).Add(n, big.NewInt(5))},
+		{"negative", big.NewInt(-5)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			want := new(big.Int).Exp(new(big.Int).Mod(tc.x, n), d, n)
+			if got := c.Exp(tc.x); got.Cmp(want) != 0 {
+				t.Errorf("Exp() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCRT_ExpProperty(t *testing.T) {
+	t.Parallel()
+
+	// An RSA round trip: (m^e)^d = m with d = e⁻¹ mod λ(N)
+	p, q := testPrimes(512)
+	n := new(big.Int).Mul(p, q)
+	one := big.NewInt(1)
+	pm1, qm1 := new(big.Int).Sub(p, one), new(big.Int).Sub(q, one)
+	lambda := new(big.Int).Div(new(big.Int).Mul(pm1, qm1), new(big.Int).GCD(nil, nil, pm1, qm1))
+	e := big.NewInt(65537)
+	d := new(big.Int).ModInverse(e, lambda)
+	c, err := NewCRT(p, q, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = quick.Check(func(mBytes []byte) bool {
+		m := new(big.Int).Mod(new(big.Int).SetBytes(mBytes), n)
+		return c.Exp(new(big.Int).Exp(m, e, n)).Cmp(m) == 0
+	}, &quick.Config{MaxCount: 50})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewCRT_errors(t *testing.T) {
+	t.Parallel()
+
+	p, q := testPrimes(256)
+	d := big.NewInt(65537)
+
+	tests := []struct {
+		name    string
+		p, q    *big.Int
+		wantErr error
+	}{
+		{"even p", new(big.Int).Add(p, big.NewInt(1)), q, ErrEvenModulus},
+		{"q = 1", p, big.NewInt(1), ErrModulusTooSmall},
+		{"p = q", p, p, ErrNotInvertible},
+		{"shared factor", new(big.Int).Mul(p, big.NewInt(3)), big.NewInt(3), ErrNotInvertible},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := NewCRT(tc.p, tc.q, d); !errors.Is(err, tc.wantErr) {
+				t.Errorf("NewCRT() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCRT_faultCheck(t *testing.T) {
+	t.Parallel()
+
+	p, q := testPrimes(512)
+	n := new(big.Int).Mul(p, q)
+	one := big.NewInt(1)
+	phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+	e := big.NewInt(65537)
+	d := new(big.Int).ModInverse(e, phi)
+	x := new(big.Int).Sub(n, big.NewInt(12345))
+	want := new(big.Int).Exp(x, d, n)
+
+	c, err := NewCRT(p, q, d, WithFaultCheck(e))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := c.ExpChecked(x); err != nil || got.Cmp(want) != 0 {
+		t.Errorf("ExpChecked() = %v, %v, want %v, nil", got, err, want)
+	}
+	if got := c.Exp(x); got.Cmp(want) != 0 {
+		t.Errorf("Exp() = %v, want %v", got, want)
+	}
+
+	// A fault in the exponentiation mod p: without the check, the result
+	// would reveal q as gcd(y^e - x, N), the Bellcore attack.
+	faulty := *c
+	faulty.ds = []*big.Int{new(big.Int).Xor(c.ds[0], one), c.ds[1]}
+	y := faulty.exp(x)
+	leak := new(big.Int).Exp(y, e, n)
+	leak.Sub(leak, x).GCD(nil, nil, leak, n)
+	if leak.Cmp(q) != 0 {
+		t.Fatalf("the simulated fault does not leak q; gcd = %v", leak)
+	}
+	if _, err := faulty.ExpChecked(x); !errors.Is(err, ErrFaultDetected) {
+		t.Errorf("faulty ExpChecked() error = %v, want %v", err, ErrFaultDetected)
+	}
+	if got := faulty.Exp(x); got != nil {
+		t.Errorf("faulty Exp() = %v, want nil", got)
+	}
+
+	// Without the option nothing is verified
+	unchecked, err := NewCRT(p, q, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unchecked.ds = faulty.ds
+	if _, err := unchecked.ExpChecked(x); err != nil {
+		t.Errorf("ExpChecked() without WithFaultCheck error = %v", err)
+	}
+}
+
+// BenchmarkCRT_faultCheck measures the cost of WithFaultCheck with
+// e = 65537 on 1024-bit primes.
+func BenchmarkCRT_faultCheck(b *testing.B) {
+	p, q := testPrimes(1024)
+	one := big.NewInt(1)
+	phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+	e := big.NewInt(65537)
+	d := new(big.Int).ModInverse(e, phi)
+	x := new(big.Int).Sub(p, big.NewInt(99))
+
+	for _, check := range []bool{false, true} {
+		var opts []Option
+		if check {
+			opts = append(opts, WithFaultCheck(e))
+		}
+		c, err := NewCRT(p, q, d, opts...)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprintf("check=%t", check), func(b *testing.B) {
+			for b.Loop() {
+				c.Exp(x)
+			}
+		})
+	}
+}
+
+// testPrimesK returns k distinct deterministic primes of the given size.
+func testPrimesK(k, bits int) []*big.Int {
+	rng := mrand.NewChaCha8([32]byte{'m', 'p', byte(k), byte(bits), byte(bits >> 8)})
+	primes := make([]*big.Int, 0, k)
+	for len(primes) < k {
+		p, err := rand.Prime(rng, bits)
+		if err != nil {
+			panic(err)
+		}
+		if !containsInt(primes, p) {
+			primes = append(primes, p)
+		}
+	}
+	return primes
+}
+
+func TestCRTMulti(t *testing.T) {
+	t.Parallel()
+
+	// Primes of different sizes exercise ExpDual's sequential fallback
+	mixed := append(testPrimesK(2, 256), testPrimesK(1, 320)...)
+	tests := []struct {
+		name   string
+		primes []*big.Int
+	}{
+		{"three primes", testPrimesK(3, 256)},
+		{"four primes", testPrimesK(4, 192)},
+		{"five primes", testPrimesK(5, 128)},
+		{"mixed sizes", mixed},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			n := big.NewInt(1)
+			for _, p := range tc.primes {
+				n.Mul(n, p)
+			}
+			d := new(big.Int).Sub(n, big.NewInt(12345))
+			c, err := NewCRTMulti(tc.primes, d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Modulus().Cmp(n) != 0 {
+				t.Errorf("Modulus() = %v, want %v", c.Modulus(), n)
+			}
+			err = quick.Check(func(xBytes []byte) bool {
+				x := new(big.Int).SetBytes(xBytes)
+				return c.Exp(x).Cmp(new(big.Int).Exp(x, d, n)) == 0
+			}, &quick.Config{MaxCount: 20})
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestNewCRTMulti_errors(t *testing.T) {
+	t.Parallel()
+
+	primes := testPrimesK(3, 128)
+	d := big.NewInt(65537)
+	tests := []struct {
+		name    string
+		primes  []*big.Int
+		wantErr error
+	}{
+		{"no primes", nil, ErrInvalidParameters},
+		{"one prime", primes[:1], ErrInvalidParameters},
+		{"even prime", []*big.Int{primes[0], big.NewInt(2), primes[1]}, ErrEvenModulus},
+		{"repeated prime", []*big.Int{primes[0], primes[1], primes[0]}, ErrNotInvertible},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := NewCRTMulti(tc.primes, d); !errors.Is(err, tc.wantErr) {
+				t.Errorf("NewCRTMulti() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// BenchmarkCRTMulti exponentiates modulo a 3072-bit N split into two,
+// three and four primes, as multi-prime RSA allows.
+func BenchmarkCRTMulti(b *testing.B) {
+	for _, k := range []int{2, 3, 4} {
+		primes := testPrimesK(k, 3072/k)
+		n := big.NewInt(1)
+		for _, p := range primes {
+			n.Mul(n, p)
+		}
+		d := new(big.Int).Sub(n, big.NewInt(12345))
+		x := new(big.Int).Sub(n, big.NewInt(99))
+		c, err := NewCRTMulti(primes, d)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprintf("primes=%d", k), func(b *testing.B) {
+			for b.Loop() {
+				c.Exp(x)
+			}
+		})
+	}
+}
+
+func BenchmarkCRT(b *testing.B) {
+	p, q := testPrimes(1024)
+	n := new(big.Int).Mul(p, q)
+	d := new(big.Int).Sub(n, big.NewInt(12345))
+	x := new(big.Int).Sub(n, big.NewInt(99))
+	c, err := NewCRT(p, q, d)
+	if err != nil {
+		b.Fatal(err)
+	}
+	full, err := newWordAligned(n, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("CRT", func(b *testing.B) {
+		for b.Loop() {
+			c.Exp(x)
+		}
+	})
+	b.Run("full", func(b *testing.B) {
+		for b.Loop() {
+			full.ExpConstantTime(x, d)
+		}
+	})
+}
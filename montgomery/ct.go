@@ -0,0 +1,179 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// MontgomeryCT is Montgomery multiplication for secret operands: every
+// reduction runs the same instruction and memory access sequence whatever
+// the values, so its timing depends only on the size of N.
+//
+// The other implementations branch on data. MontgomeryBitwise adds N when
+// the low bit of the accumulator is set, and all of them subtract N only
+// when the result is at least N, which is the classic timing leak of
+// Montgomery multiplication in RSA and signing code. MontgomeryCT works on
+// fixed-size limb slices with CIOS, where m·N is added unconditionally, and
+// computes the final subtraction every time, keeping the right result by
+// mask (see montMulWords).
+//
+// MulWords, ExpWords, ExpLadderWords, Select and Equal are the
+// constant-time interface. Mul and Exp accept *big.Int for convenience,
+// but math/big normalizes away leading zero words, so converting to and
+// from big.Int reveals the operands' word lengths and the InputPolicy check
+// compares them with N; code that must not leak even that should keep its
+// secrets in limbs.
+type MontgomeryCT struct {
+	R  *big.Int // R = 2^(64·S)
+	N  *big.Int // modulus (must be odd)
+	RR *big.Int // R² mod N (precomputed)
+	NI uint64   // -N^(-1) mod 2^64 (precomputed via Newton-Raphson)
+	S  int      // number of 64-bit words in R
+
+	n, rr, one []uint64 // N, R² mod N and 1 as S limbs
+	w          *MontgomeryCIOSWords
+	cfg        config
+}
+
+// NewMontgomeryCT creates a new MontgomeryCT instance with precomputed
+// values.
+//
+// R and N are not validated: an even N or an R of the wrong shape yields
+// a context whose results are silently wrong. Parameters not known to be
+// valid belong in NewMontgomeryCTChecked.
+func NewMontgomeryCT(R, N *big.Int, opts ...Option) *MontgomeryCT {
+	// The test runs on the constant-time kernels rather than CIOSWords'
+	opts, cfg := withoutSelfTest(opts)
+	m := newMontgomeryCT(NewMontgomeryCIOSWords(R, N, opts...))
+	mustSelfTest(m, cfg)
+	return m
+}
+
+func newMontgomeryCT(w *MontgomeryCIOSWords) *MontgomeryCT {
+	s := w.S
+	return &MontgomeryCT{
+		R:   w.R,
+		N:   w.N,
+		RR:  w.RR,
+		NI:  w.NI,
+		S:   s,
+		n:   limbsPadded(w.N, s),
+		rr:  limbsPadded(w.RR, s),
+		one: limbsPadded(big.NewInt(1), s),
+		w:   w,
+		cfg: w.cfg,
+	}
+}
+
+// NewMontgomeryCTChecked is NewMontgomeryCT that first rejects invalid R
+// and N, including R that is not a whole number of 64-bit words, with an
+// error wrapping ErrInvalidParameters.
+func NewMontgomeryCTChecked(R, N *big.Int, opts ...Option) (*MontgomeryCT, error) {
+	opts, cfg := withoutSelfTest(opts)
+	w, err := NewMontgomeryCIOSWordsChecked(R, N, opts...)
+	if err != nil {
+		return nil, err
+	}
+	m := newMontgomeryCT(w)
+	if err := selfTest(m, cfg); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MulWords sets z = (x * y) mod N in constant time. x, y and z are S
+// little-endian 64-bit limbs; x and y must be in [0, N), which is not
+// checked, since checking would branch on them. z may be x, y or both.
+//
+// It takes four REDCs, two into Montgomery form and two out of it: the
+// same work as Mul on the other implementations, none of it
+// data-dependent.
+func (m *MontgomeryCT) MulWords(z, x, y []uint64) {
+	m.checkLen("MulWords", z, x, y)
+	s := m.S
+	t := make([]uint64, 3*s+2)
+	xm, ym, t := t[:s], t[s:2*s], t[2*s:]
+	montMulWords(xm, x, m.rr, m.n, m.NI, t)
+	montMulWords(ym, y, m.rr, m.n, m.NI, t)
+	montMulWords(z, xm, ym, m.n, m.NI, t)
+	montMulWords(z, z, m.one, m.n, m.NI, t)
+}
+
+// ExpWords sets z = base^exp mod N with the fixed-window, masked-lookup
+// schedule of ExpConstantTime. base and z are S limbs, base in [0, N), and
+// exp is any number of limbs; only len(exp) is revealed, never its bits.
+// z may be base.
+func (m *MontgomeryCT) ExpWords(z, base, exp []uint64) {
+	m.checkLen("ExpWords", z, base)
+	exp = m.cfg.blind.applyWords(exp)
+	copy(z, m.w.expConstantTimeWords(base, exp, max(64*len(exp), 64*m.S), nil))
+}
+
+// Select sets z to a if cond is 1 and to b if cond is 0, reading both and
+// keeping one by mask, like crypto/subtle.ConstantTimeSelect. cond must be
+// 0 or 1. a, b and z are S limbs, in or out of Montgomery form, and z may
+// be a or b. Together with Equal it is what constant-time scalar
+// multiplication and exponentiation built on MulWords need in place of a
+// branch.
+func (m *MontgomeryCT) Select(z []uint64, cond int, a, b []uint64) {
+	m.checkLen("Select", z, a, b)
+	mask := ctMask(uint64(cond))
+	for i := range z {
+		z[i] = a[i]&mask | b[i]&^mask
+	}
+}
+
+// Equal returns 1 if the S-limb values a and b are equal and 0 otherwise,
+// reading every limb, like crypto/subtle.ConstantTimeCompare. Montgomery
+// form is a bijection, so elements compare as they are. MontElement.Equal
+// is not constant time.
+func (m *MontgomeryCT) Equal(a, b []uint64) int {
+	m.checkLen("Equal", a, b)
+	var d uint64
+	for i := range a {
+		d |= a[i] ^ b[i]
+	}
+	return int(ctEq(d, 0) & 1)
+}
+
+// Mul returns (x * y) mod N. Only the reduction is constant time; see
+// MontgomeryCT for what the big.Int conversions reveal. Operands outside
+// [0, N) are handled by the context's InputPolicy.
+func (m *MontgomeryCT) Mul(x, y *big.Int) *big.Int {
+	x = m.cfg.input.mustOperand(m.N, "Mul", x)
+	y = m.cfg.input.mustOperand(m.N, "Mul", y)
+	z := make([]uint64, m.S)
+	m.MulWords(z, limbsPadded(x, m.S), limbsPadded(y, m.S))
+	return tobigInt(z)
+}
+
+// Exp returns base^exp mod N as ExpConstantTime, including its inverse
+// for a negative exp. See MontgomeryCT for what the big.Int conversions
+// reveal.
+func (m *MontgomeryCT) Exp(base, exp *big.Int) *big.Int {
+	return m.w.ExpConstantTime(base, exp)
+}
+
+// Modulus returns N.
+func (m *MontgomeryCT) Modulus() *big.Int { return new(big.Int).Set(m.N) }
+
+// RModN returns R mod N, the Montgomery form of 1.
+func (m *MontgomeryCT) RModN() *big.Int { return m.w.RModN() }
+
+// R2ModN returns R² mod N.
+func (m *MontgomeryCT) R2ModN() *big.Int { return m.w.R2ModN() }
+
+// R3ModN returns R³ mod N.
+func (m *MontgomeryCT) R3ModN() *big.Int { return m.w.R3ModN() }
+
+// checkLen panics unless every slice has exactly S limbs. Lengths are
+// public, so the check does not leak.
+func (m *MontgomeryCT) checkLen(op string, xs ...[]uint64) {
+	for _, x := range xs {
+		if len(x) != m.S {
+			panic(fmt.Sprintf("montgomery: %s: operand has %d limbs, want %d", op, len(x), m.S))
+		}
+	}
+}
+
+var _ ModMultiplier = (*MontgomeryCT)(nil)
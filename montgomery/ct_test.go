@@ -0,0 +1,213 @@
+package montgomery
+
+import (
+	"errors"
+	"math/big"
+	"slices"
+	"testing"
+	"testing/quick"
+)
+
+func TestMontgomeryCT_Mul(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+	R64 := new(big.Int).Lsh(big.NewInt(1), 64)
+
+	tests := []struct {
+		name string
+		R, N *big.Int
+		x, y *big.Int
+	}{
+		{"2048-bit", R, N, x, y},
+		{"zero", R, N, big.NewInt(0), y},
+		{"one", R, N, big.NewInt(1), y},
+		{"N - 1 squared", R, N, new(big.Int).Sub(N, big.NewInt(1)), new(big.Int).Sub(N, big.NewInt(1))},
+		{"unreduced", R, N, new(big.Int).Add(N, x), new(big.Int).Neg(y)},
+		{"one word", R64, N64, new(big.Int).Sub(N64, big.NewInt(1)), big.NewInt(7)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			m := NewMontgomeryCT(tc.R, tc.N)
+			want := new(big.Int).Mul(tc.x, tc.y)
+			want.Mod(want, tc.N)
+			if got := m.Mul(tc.x, tc.y); got.Cmp(want) != 0 {
+				t.Errorf("Mul() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMontgomeryCT_MulWordsAliasing(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	m := NewMontgomeryCT(R, N)
+	want := new(big.Int).Mul(x, y)
+	want.Mod(want, N)
+	wantSq := new(big.Int).Mul(x, x)
+	wantSq.Mod(wantSq, N)
+
+	xs, ys := limbsPadded(x, m.S), limbsPadded(y, m.S)
+	z := make([]uint64, m.S)
+	m.MulWords(z, xs, ys)
+	if got := tobigInt(z); got.Cmp(want) != 0 {
+		t.Errorf("MulWords(z, x, y) = %v, want %v", got, want)
+	}
+	m.MulWords(ys, xs, ys)
+	if got := tobigInt(ys); got.Cmp(want) != 0 {
+		t.Errorf("MulWords(y, x, y) = %v, want %v", got, want)
+	}
+	m.MulWords(xs, xs, xs)
+	if got := tobigInt(xs); got.Cmp(wantSq) != 0 {
+		t.Errorf("MulWords(x, x, x) = %v, want %v", got, wantSq)
+	}
+}
+
+func TestMontgomeryCT_MulWordsLength(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	m := NewMontgomeryCT(R, N)
+	defer func() {
+		if recover() == nil {
+			t.Error("MulWords with short operand did not panic")
+		}
+	}()
+	m.MulWords(make([]uint64, m.S), make([]uint64, m.S-1), make([]uint64, m.S))
+}
+
+func TestMontgomeryCT_MulProperty(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	m := NewMontgomeryCT(R, N)
+
+	err := quick.Check(func(xBytes, yBytes []byte) bool {
+		x := new(big.Int).Mod(new(big.Int).SetBytes(xBytes), N)
+		y := new(big.Int).Mod(new(big.Int).SetBytes(yBytes), N)
+		want := new(big.Int).Mul(x, y)
+		return m.Mul(x, y).Cmp(want.Mod(want, N)) == 0
+	}, &quick.Config{MaxCount: 200})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMontgomeryCT_Exp(t *testing.T) {
+	t.Parallel()
+
+	x, _, R, N := testParams2048()
+	m := NewMontgomeryCT(R, N)
+
+	tests := []struct {
+		name string
+		exp  *big.Int
+	}{
+		{"zero", big.NewInt(0)},
+		{"65537", big.NewInt(65537)},
+		{"N - 1", new(big.Int).Sub(N, big.NewInt(1))},
+		{"wider than R", new(big.Int).Lsh(N, 100)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			want := new(big.Int).Exp(x, tc.exp, N)
+			if got := m.Exp(x, tc.exp); got.Cmp(want) != 0 {
+				t.Errorf("Exp() = %v, want %v", got, want)
+			}
+
+			// ExpWords with the exponent as limbs and z aliasing base
+			z := limbsPadded(x, m.S)
+			m.ExpWords(z, z, limbsPadded(tc.exp, (tc.exp.BitLen()+63)/64))
+			if got := tobigInt(z); got.Cmp(want) != 0 {
+				t.Errorf("ExpWords() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMontgomeryCT_SelectEqual(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParamsLarge(256)
+	m := NewMontgomeryCT(R, N)
+	a, b := limbsPadded(x, m.S), limbsPadded(y, m.S)
+
+	tests := []struct {
+		name string
+		cond int
+		want []uint64
+	}{
+		{"cond 1", 1, a},
+		{"cond 0", 0, b},
+	}
+	for _, tc := range tests {
+		z := make([]uint64, m.S)
+		m.Select(z, tc.cond, a, b)
+		if m.Equal(z, tc.want) != 1 || tobigInt(z).Cmp(tobigInt(tc.want)) != 0 {
+			t.Errorf("%s: Select() = %x, want %x", tc.name, z, tc.want)
+		}
+		// z aliasing an operand
+		za := slices.Clone(a)
+		m.Select(za, tc.cond, za, b)
+		if !slices.Equal(za, tc.want) {
+			t.Errorf("%s: Select(a, a, b) = %x, want %x", tc.name, za, tc.want)
+		}
+	}
+
+	if m.Equal(a, b) != 0 {
+		t.Error("Equal(x, y) = 1 for x != y")
+	}
+	// Values differing in one limb only, the top or the bottom
+	for _, i := range []int{0, m.S - 1} {
+		c := slices.Clone(a)
+		c[i] ^= 1 << 63
+		if m.Equal(a, c) != 0 || m.Equal(a, slices.Clone(a)) != 1 {
+			t.Errorf("Equal misses a difference in limb %d", i)
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Equal with a short operand did not panic")
+		}
+	}()
+	m.Equal(a, b[1:])
+}
+
+func TestNewMontgomeryCTChecked(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	if _, err := NewMontgomeryCTChecked(R, N); err != nil {
+		t.Errorf("valid parameters: %v", err)
+	}
+	if _, err := NewMontgomeryCTChecked(new(big.Int).Lsh(R, 1), N); !errors.Is(err, ErrRNotWordAligned) {
+		t.Errorf("R not word aligned: error = %v, want ErrRNotWordAligned", err)
+	}
+	if _, err := NewMontgomeryCTChecked(R, new(big.Int).Add(N, big.NewInt(1))); !errors.Is(err, ErrEvenModulus) {
+		t.Errorf("even N: error = %v, want ErrEvenModulus", err)
+	}
+}
+
+func BenchmarkMontgomeryCT(b *testing.B) {
+	x, y, R, N := testParams2048()
+	m := NewMontgomeryCT(R, N)
+	xs, ys := limbsPadded(x, m.S), limbsPadded(y, m.S)
+	z := make([]uint64, m.S)
+
+	b.Run("MulWords", func(b *testing.B) {
+		for b.Loop() {
+			m.MulWords(z, xs, ys)
+		}
+	})
+	b.Run("Mul", func(b *testing.B) {
+		for b.Loop() {
+			m.Mul(x, y)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+//go:build ctaudit
+
+package montgomery
+
+import (
+	"math/big"
+	"sync"
+	"unsafe"
+)
+
+// The ctaudit build tag turns on a structural check of the constant-time
+// paths, complementing Trace, which compares operation schedules. A test
+// marks operands secret with auditSecret, auditTaint carries the mark from
+// the inputs of a computation to a result, and every annotated branch
+// passes the operands its condition reads to auditBranch, which records the
+// site and whether any of them is secret. A test then asserts that a
+// constant-time operation recorded no secret-dependent branch.
+//
+// Go has no branch instrumentation, so the audit sees the conditionals
+// annotated by hand: those of the kernels the constant-time paths run,
+// whose conditions read only lengths and indices, and the data-dependent
+// ones of the variable-time paths, which show that the audit catches a
+// leak. Argument checks that panic are not annotated, nor is assembly.
+// Without the tag the hooks are empty and compile away.
+//
+// The state is global: audit tests must not call t.Parallel.
+
+// auditRecord is one branch taken while auditing.
+type auditRecord struct {
+	site   string
+	secret bool // some operand of the condition was marked secret
+}
+
+var audit struct {
+	sync.Mutex
+	on      bool
+	secret  []auditSpan
+	records []auditRecord
+}
+
+// auditSpan is the memory of a marked operand. keep holds the operand so
+// that its memory cannot be freed and reused by a public value while
+// auditing.
+type auditSpan struct {
+	start, end uintptr
+	keep       any
+}
+
+// auditStart clears the marks and records and starts recording.
+func auditStart() {
+	audit.Lock()
+	defer audit.Unlock()
+	audit.on, audit.secret, audit.records = true, nil, nil
+}
+
+// auditStop stops recording and returns the branches taken since
+// auditStart.
+func auditStop() []auditRecord {
+	audit.Lock()
+	defer audit.Unlock()
+	records := audit.records
+	audit.on, audit.secret, audit.records = false, nil, nil
+	return records
+}
+
+// auditSecret marks the memory of xs secret. Each x is a []uint64, a
+// []big.Word, a []uint of window digits or a *big.Int.
+func auditSecret(xs ...any) {
+	audit.Lock()
+	defer audit.Unlock()
+	if audit.on {
+		markSecret(xs)
+	}
+}
+
+// auditTaint marks dst secret if any of srcs is.
+func auditTaint(dst any, srcs ...any) {
+	audit.Lock()
+	defer audit.Unlock()
+	if audit.on && anySecret(srcs) {
+		markSecret([]any{dst})
+	}
+}
+
+// auditBranch records a branch at site whose condition reads operands.
+func auditBranch(site string, operands ...any) {
+	audit.Lock()
+	defer audit.Unlock()
+	if audit.on {
+		audit.records = append(audit.records, auditRecord{site: site, secret: anySecret(operands)})
+	}
+}
+
+func markSecret(xs []any) {
+	for _, x := range xs {
+		if start, end := spanOf(x); start < end {
+			audit.secret = append(audit.secret, auditSpan{start, end, x})
+		}
+	}
+}
+
+func anySecret(xs []any) bool {
+	for _, x := range xs {
+		start, end := spanOf(x)
+		for _, s := range audit.secret {
+			if start < s.end && s.start < end {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// spanOf returns the address range of x's elements, empty for an empty x.
+func spanOf(x any) (start, end uintptr) {
+	switch x := x.(type) {
+	case []uint64:
+		return sliceSpan(x)
+	case []big.Word:
+		return sliceSpan(x)
+	case []uint:
+		return sliceSpan(x)
+	case *big.Int:
+		return sliceSpan(x.Bits())
+	}
+	panic("montgomery: audit operand is not limbs or a *big.Int")
+}
+
+func sliceSpan[W limbWord](x []W) (start, end uintptr) {
+	if len(x) == 0 {
+		return 0, 0
+	}
+	start = uintptr(unsafe.Pointer(unsafe.SliceData(x)))
+	return start, start + uintptr(len(x))*unsafe.Sizeof(x[0])
+}
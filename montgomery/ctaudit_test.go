@@ -0,0 +1,113 @@
+//go:build ctaudit
+
+package montgomery
+
+import (
+	"math/big"
+	"testing"
+)
+
+// The audit tests share global state, so none of them calls t.Parallel.
+
+// auditRun marks secrets secret, runs f and returns the branches taken.
+func auditRun(f func(), secrets ...any) []auditRecord {
+	auditStart()
+	auditSecret(secrets...)
+	f()
+	return auditStop()
+}
+
+// TestAudit_constantTime runs the constant-time operations on secret
+// operands and checks that none of the branches they take reads one.
+func TestAudit_constantTime(t *testing.T) {
+	base, y, R, N := testParams2048()
+	exp := new(big.Int).Sub(N, big.NewInt(2))
+	ct := NewMontgomeryCT(R, N)
+	w := NewMontgomeryCIOSWords(R, N)
+	s := ct.S
+	x, yw, e := limbsPadded(base, s), limbsPadded(y, s), limbsPadded(exp, s)
+	z := make([]uint64, s)
+
+	tests := []struct {
+		name string
+		f    func()
+	}{
+		{"MontgomeryCT.MulWords", func() { ct.MulWords(z, x, yw) }},
+		{"MontgomeryCT.ExpWords", func() { ct.ExpWords(z, x, e) }},
+		{"MontgomeryCT.ExpLadderWords", func() { ct.ExpLadderWords(z, x, e) }},
+		{"MontgomeryCT.Select", func() { ct.Select(z, int(x[0]&1), x, yw) }},
+		{"MontgomeryCT.Equal", func() { ct.Equal(x, yw) }},
+		{"MontgomeryCIOSWords.ExpConstantTime", func() { w.ExpConstantTime(base, exp) }},
+		{"MontgomeryCIOSWords.ExpLadder", func() { w.ExpLadder(base, exp) }},
+		{"mulAccGo", func() {
+			k := montgomeryImpl[uint64]{ct.n, ct.NI}
+			k.mulAccGo(make([]uint64, s+1), x, yw)
+		}},
+	}
+
+	// A single product may run entirely in assembly and record nothing, so
+	// only the whole set must have exercised the hooks.
+	recorded := 0
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			records := auditRun(tc.f, x, yw, e, base, exp)
+			recorded += len(records)
+			for _, r := range records {
+				if r.secret {
+					t.Fatalf("branch %q reads a secret operand", r.site)
+				}
+			}
+		})
+	}
+	if recorded == 0 {
+		t.Fatal("no branches recorded; the hooks are not running")
+	}
+}
+
+// TestAudit_variableTime checks that the audit catches the secret-dependent
+// branches of the variable-time operations.
+func TestAudit_variableTime(t *testing.T) {
+	base, y, R, N := testParams2048()
+	exp := new(big.Int).Sub(N, big.NewInt(2))
+	w := NewMontgomeryCIOSWords(R, N)
+	sliding := NewMontgomeryCIOSWords(R, N, WithWindowStrategy(WindowSliding))
+	bitwise := NewMontgomeryBitwise(R, N)
+
+	tests := []struct {
+		name     string
+		f        func()
+		wantSite string
+	}{
+		{"Trace binary", func() { w.Trace(StrategyBinary, base, exp) }, "expBinary: exponent bit"},
+		{"Exp fixed window", func() { w.Exp(base, exp) }, "expSchedule: zero digit"},
+		{"Exp sliding window", func() { sliding.Exp(base, exp) }, "expOdd: exponent bit"},
+		{"MontgomeryBitwise.Mul", func() { bitwise.Mul(base, y) }, "MontgomeryBitwise.reduce: low bit"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, r := range auditRun(tc.f, base, y, exp) {
+				if r.secret && r.site == tc.wantSite {
+					return
+				}
+			}
+			t.Errorf("no secret-dependent branch recorded at %q", tc.wantSite)
+		})
+	}
+}
+
+// TestAudit_public checks that branches on unmarked operands are recorded
+// as public.
+func TestAudit_public(t *testing.T) {
+	_, _, R, N := testParams2048()
+	w := NewMontgomeryCIOSWords(R, N)
+	records := auditRun(func() { w.Trace(StrategyBinary, big.NewInt(3), big.NewInt(65537)) })
+	if len(records) == 0 {
+		t.Fatal("no branches recorded")
+	}
+	for _, r := range records {
+		if r.secret {
+			t.Errorf("branch %q recorded as secret with nothing marked", r.site)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package montgomery
+
+import "math/big"
+
+// ctWindow is the fixed window width of ExpConstantTime, matching the
+// 5-bit windows of OpenSSL's mont5 exponentiation.
+const ctWindow = 5
+
+// ExpConstantTime computes base^exp mod N with a fixed-window schedule whose
+// table lookups do not leak the exponent through the cache.
+//
+// Windowed exponentiation is fast because it multiplies by precomputed
+// powers base^d, but indexing the table by the secret digit d touches a
+// digit-dependent cache line. Following mont5, the table is stored
+// interleaved (limb j of every entry is adjacent, see scatter) and every
+// lookup reads all entries and keeps the wanted one by mask (see gather).
+//
+// The window is fixed rather than sliding: a sliding window's squaring and
+// multiply pattern itself reveals where the exponent's zero runs are. Every
+// window costs exactly ctWindow squarings and one multiplication, even for a
+// zero digit, and the number of windows depends only on max(exp.BitLen(),
+// 64*S) so exponents below R share one schedule. The base is treated as
+// public; one outside [0, N) is handled by the InputPolicy. A negative
+// exponent raises the inverse of the base, as with Exp, which reveals its
+// sign but not its bits. With WithExponentBlinding the exponent is
+// randomized first.
+func (m *MontgomeryCIOSWords) ExpConstantTime(base, exp *big.Int) *big.Int {
+	base = m.cfg.input.mustOperand(m.N, "ExpConstantTime", base)
+	if base, exp = invertBase(m.N, base, exp); base == nil {
+		return nil
+	}
+	return m.expConstantTime(base, m.cfg.blind.apply(exp), nil)
+}
+
+// expConstantTime is ExpConstantTime with every operation reported to rec,
+// which may be nil. Trace uses it so the recorded schedule is the real one.
+func (m *MontgomeryCIOSWords) expConstantTime(base, exp *big.Int, rec *recorder) *big.Int {
+	base = m.cfg.input.mustOperand(m.N, "ExpConstantTime", base)
+	e := limbsPadded(exp, (exp.BitLen()+63)/64)
+	return tobigInt(m.expConstantTimeWords(limbsPadded(base, m.S), e, max(exp.BitLen(), 64*m.S), rec))
+}
+
+// expConstantTimeWords is the limb-level core of expConstantTime: base is S
+// limbs in [0, N) and exp is scanned over nbits bits, which must cover all
+// of its set bits. The result is a fresh slice of S limbs.
+func (m *MontgomeryCIOSWords) expConstantTimeWords(base, exp []uint64, nbits int, rec *recorder) []uint64 {
+	s := m.S
+	mul := m.mulWordsKernel()
+	t := make([]uint64, s+2)
+	n := limbsPadded(m.N, s)
+	rr := limbsPadded(m.RR, s)
+	one := limbsPadded(big.NewInt(1), s)
+
+	// table[i] = base^i in Montgomery form, scattered into the interleaved layout
+	const size = 1 << ctWindow
+	table := make([]uint64, size*s)
+	entry := make([]uint64, s)
+	montMulWords(entry, one, rr, n, m.NI, t) // 1 * R mod N
+	rec.mul(OpMultiply, s)
+	scatter(table, entry, 0)
+	rec.store(s)
+	baseMont := make([]uint64, s)
+	mul(baseMont, base, rr, n, m.NI, t)
+	rec.mul(OpMultiply, s)
+	copy(entry, baseMont)
+	scatter(table, entry, 1)
+	rec.store(s)
+	for i := 2; i < size; i++ {
+		mul(entry, entry, baseMont, n, m.NI, t)
+		rec.mul(OpMultiply, s)
+		scatter(table, entry, i)
+		rec.store(s)
+	}
+
+	windows := (nbits + ctWindow - 1) / ctWindow
+
+	acc := make([]uint64, s)
+	gather(acc, table, 0)
+	rec.load(size, s)
+	for i := windows - 1; i >= 0; i-- {
+		for range ctWindow {
+			mul(acc, acc, acc, n, m.NI, t) // square
+			rec.mul(OpSquare, s)
+		}
+		var d uint64
+		for b := ctWindow - 1; b >= 0; b-- {
+			d = d<<1 | limbBit(exp, i*ctWindow+b)
+		}
+		gather(entry, table, d)
+		rec.load(size, s)
+		mul(acc, acc, entry, n, m.NI, t) // multiply, also for d = 0
+		rec.mul(OpMultiply, s)
+	}
+
+	// Convert back from Montgomery form, with the full reduction that also
+	// makes an almost-Montgomery result canonical
+	montMulWords(acc, acc, one, n, m.NI, t)
+	rec.mul(OpMultiply, s)
+	return acc
+}
+
+// limbBit returns bit i of the little-endian limbs x, or 0 past their end.
+// The index is public; the bit is read without branching on its value.
+func limbBit(x []uint64, i int) uint64 {
+	auditBranch("limbBit: past the end of x")
+	if i/64 >= len(x) {
+		return 0
+	}
+	return x[i/64] >> (i % 64) & 1
+}
+
+// scatter stores entry as element i of an interleaved table with
+// 2^ctWindow elements: limb j of element i lives at table[j<<ctWindow + i].
+// entry must not alias table.
+func scatter(table, entry []uint64, i int) {
+	checkDisjoint("scatter", entry, table)
+	for j, limb := range entry {
+		table[j<<ctWindow+i] = limb
+	}
+}
+
+// gather loads element idx of an interleaved table into dst in constant
+// time: every element is read and all but the wanted one are masked off,
+// so the memory access pattern is independent of idx. dst must not alias
+// table.
+func gather(dst, table []uint64, idx uint64) {
+	checkDisjoint("gather", dst, table)
+	const size = 1 << ctWindow
+	for j := range dst {
+		row := table[j*size : (j+1)*size]
+		var limb uint64
+		for i, v := range row {
+			limb |= v & ctEq(uint64(i), idx)
+		}
+		dst[j] = limb
+	}
+}
@@ -0,0 +1,154 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestMontgomeryCIOSWords_ExpConstantTime(t *testing.T) {
+	t.Parallel()
+
+	x2048, _, R2048, N2048 := testParams2048()
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+	R64 := new(big.Int).Lsh(big.NewInt(1), 64)
+
+	tests := []struct {
+		name string
+		base *big.Int
+		exp  *big.Int
+		R    *big.Int
+		N    *big.Int
+	}{
+		{"2^10 mod N64", big.NewInt(2), big.NewInt(10), R64, N64},
+		{"exp=0", big.NewInt(12345), big.NewInt(0), R64, N64},
+		{"exp=1", big.NewInt(12345), big.NewInt(1), R64, N64},
+		{"base=0", big.NewInt(0), big.NewInt(7), R64, N64},
+		{"base >= N", new(big.Int).Add(N64, big.NewInt(3)), big.NewInt(5), R64, N64},
+		{"exponent wider than R", big.NewInt(3), new(big.Int).Lsh(big.NewInt(1), 100), R64, N64},
+		{"2048-bit", x2048, new(big.Int).Sub(N2048, big.NewInt(1)), R2048, N2048},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			m := NewMontgomeryCIOSWords(tc.R, tc.N)
+			want := new(big.Int).Exp(tc.base, tc.exp, tc.N)
+			if got := m.ExpConstantTime(tc.base, tc.exp); got.Cmp(want) != 0 {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMontgomeryCIOSWords_ExpConstantTimeAMM(t *testing.T) {
+	t.Parallel()
+
+	x, _, R, N := testParamsAMM()
+	m := NewMontgomeryCIOSWords(R, N, WithAlmostMontgomery())
+	if !m.amm {
+		t.Fatal("almost Montgomery mode not enabled for 4N ≤ R")
+	}
+
+	tests := []struct {
+		name string
+		base *big.Int
+		exp  *big.Int
+	}{
+		{"exp=0", x, big.NewInt(0)},
+		{"base=0", big.NewInt(0), big.NewInt(7)},
+		{"base=N-1", new(big.Int).Sub(N, big.NewInt(1)), big.NewInt(3)},
+		{"dense", x, new(big.Int).Sub(N, big.NewInt(1))},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			want := new(big.Int).Exp(tc.base, tc.exp, N)
+			if got := m.ExpConstantTime(tc.base, tc.exp); got.Cmp(want) != 0 {
+				t.Errorf("ExpConstantTime() = %v, want %v", got, want)
+			}
+			fb := m.NewFixedBase(tc.base, N.BitLen(), 4)
+			if got := fb.Exp(tc.exp); got.Cmp(want) != 0 {
+				t.Errorf("FixedBase.Exp() = %v, want %v", got, want)
+			}
+		})
+	}
+
+	// Without room for AMM the plain constructor keeps full reductions
+	_, _, R2048, N2048 := testParams2048()
+	if NewMontgomeryCIOSWords(R2048, N2048, WithAlmostMontgomery()).amm {
+		t.Error("almost Montgomery mode enabled for R < 4N")
+	}
+}
+
+func TestMontgomeryCIOSWords_ExpConstantTimeProperty(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+
+	err := quick.Check(func(baseBytes, expBytes []byte) bool {
+		base := new(big.Int).SetBytes(baseBytes)
+		exp := new(big.Int).SetBytes(expBytes)
+		base.Mod(base, N)
+		return m.ExpConstantTime(base, exp).Cmp(new(big.Int).Exp(base, exp, N)) == 0
+	}, &quick.Config{MaxCount: 10})
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_scatterGather(t *testing.T) {
+	t.Parallel()
+
+	const s = 3
+	table := make([]uint64, s<<ctWindow)
+	for i := range 1 << ctWindow {
+		scatter(table, []uint64{uint64(i), uint64(i) << 8, uint64(i) << 16}, i)
+	}
+
+	dst := make([]uint64, s)
+	for i := range uint64(1 << ctWindow) {
+		gather(dst, table, i)
+		if dst[0] != i || dst[1] != i<<8 || dst[2] != i<<16 {
+			t.Errorf("gather(%d) = %v", i, dst)
+		}
+	}
+}
+
+func BenchmarkExpConstantTime(b *testing.B) {
+	base, _, R, N := testParams2048()
+	exp := new(big.Int).Sub(N, big.NewInt(1))
+	m := NewMontgomeryCIOSWords(R, N)
+
+	b.Run("ExpConstantTime", func(b *testing.B) {
+		for b.Loop() {
+			m.ExpConstantTime(base, exp)
+		}
+	})
+
+	b.Run("modExp", func(b *testing.B) {
+		for b.Loop() {
+			m.modExp(base, exp)
+		}
+	})
+
+	// Full reductions against almost Montgomery multiplication on the same
+	// modulus, which needs 4N ≤ R
+	base, _, R, N = testParamsAMM()
+	for _, amm := range []bool{false, true} {
+		var opts []Option
+		if amm {
+			opts = append(opts, WithAlmostMontgomery())
+		}
+		m := NewMontgomeryCIOSWords(R, N, opts...)
+		b.Run(fmt.Sprintf("ExpConstantTime/amm=%t", amm), func(b *testing.B) {
+			for b.Loop() {
+				m.ExpConstantTime(base, exp)
+			}
+		})
+	}
+}
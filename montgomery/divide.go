@@ -0,0 +1,103 @@
+package montgomery
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrNotInvertible is returned by NewDivisor for a divisor that shares a
+// factor with N, or is zero, and so has no inverse mod N.
+var ErrNotInvertible = errors.New("montgomery: divisor not invertible mod N")
+
+// Halve returns x/2 mod N, that is x·2⁻¹, by adding N to an odd x and
+// shifting: no inversion and no multiplication. Operands outside [0, N)
+// are handled by the context's InputPolicy.
+func (m *MontgomeryBitwise) Halve(x *big.Int) *big.Int {
+	return halve(m.N, m.cfg.input.mustOperand(m.N, "Halve", x))
+}
+
+// HalveMont returns a/2 in Montgomery form. Halving commutes with the
+// factor R, so it is the same operation as Halve.
+func (m *MontgomeryBitwise) HalveMont(a MontElement) MontElement {
+	return MontElement{halve(m.N, a.val())}
+}
+
+// NewDivisor precomputes division by c mod N; see Divisor.
+func (m *MontgomeryBitwise) NewDivisor(c uint64) (*Divisor, error) {
+	return newDivisor(m.engine(), c)
+}
+
+// Halve returns x/2 mod N. See MontgomeryBitwise.Halve.
+func (m *MontgomeryCIOS) Halve(x *big.Int) *big.Int {
+	return halve(m.N, m.cfg.input.mustOperand(m.N, "Halve", x))
+}
+
+// HalveMont returns a/2 in Montgomery form.
+func (m *MontgomeryCIOS) HalveMont(a MontElement) MontElement {
+	return MontElement{halve(m.N, a.val())}
+}
+
+// NewDivisor precomputes division by c mod N; see Divisor.
+func (m *MontgomeryCIOS) NewDivisor(c uint64) (*Divisor, error) {
+	return newDivisor(m.engine(), c)
+}
+
+// Halve returns x/2 mod N. See MontgomeryBitwise.Halve.
+func (m *MontgomeryCIOSWords) Halve(x *big.Int) *big.Int {
+	return halve(m.N, m.cfg.input.mustOperand(m.N, "Halve", x))
+}
+
+// HalveMont returns a/2 in Montgomery form.
+func (m *MontgomeryCIOSWords) HalveMont(a MontElement) MontElement {
+	return MontElement{halve(m.N, a.val())}
+}
+
+// NewDivisor precomputes division by c mod N; see Divisor.
+func (m *MontgomeryCIOSWords) NewDivisor(c uint64) (*Divisor, error) {
+	return newDivisor(m.engine(), c)
+}
+
+// halve returns x/2 mod N for x in [0, N). N is odd, so exactly one of x
+// and x + N is even, and (x + N)/2 < N.
+func halve(n, x *big.Int) *big.Int {
+	z := new(big.Int).Set(x)
+	if z.Bit(0) == 1 {
+		z.Add(z, n)
+	}
+	return z.Rsh(z, 1)
+}
+
+// Divisor divides by a fixed small constant c mod N, as EC formulas and
+// polynomial interpolation do with 2, 3 or the factorials of the nodes.
+//
+// It holds c⁻¹·R mod N, computed once with a single inversion, so that a
+// division is one REDC: REDC(x, c⁻¹·R) = x·c⁻¹·R·R⁻¹ = x·c⁻¹. The same
+// holds with x in Montgomery form, so Div and DivMont cost the same. A
+// Divisor is immutable and safe for concurrent use.
+type Divisor struct {
+	c   uint64
+	inv *big.Int // c⁻¹·R mod N
+	eng engine
+}
+
+func newDivisor(eng engine, c uint64) (*Divisor, error) {
+	inv := new(big.Int).ModInverse(new(big.Int).SetUint64(c), eng.n)
+	if inv == nil {
+		return nil, ErrNotInvertible
+	}
+	return &Divisor{c: c, inv: toMont(eng, inv).v, eng: eng}, nil
+}
+
+// C returns the divisor c.
+func (d *Divisor) C() uint64 { return d.c }
+
+// Div returns x/c mod N, that is x·c⁻¹. Operands outside [0, N) are
+// handled by the context's InputPolicy.
+func (d *Divisor) Div(x *big.Int) *big.Int {
+	return d.eng.redc(d.eng.input.mustOperand(d.eng.n, "Div", x), d.inv)
+}
+
+// DivMont returns a/c in Montgomery form.
+func (d *Divisor) DivMont(a MontElement) MontElement {
+	return MontElement{d.eng.redc(a.val(), d.inv)}
+}
@@ -0,0 +1,155 @@
+package montgomery
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestHalve(t *testing.T) {
+	t.Parallel()
+
+	x, _, R, N := testParams2048()
+	inv2 := new(big.Int).ModInverse(big.NewInt(2), N)
+
+	tests := []struct {
+		name string
+		x    *big.Int
+	}{
+		{"2048-bit", x},
+		{"zero", big.NewInt(0)},
+		{"one", big.NewInt(1)},
+		{"two", big.NewInt(2)},
+		{"N - 1", new(big.Int).Sub(N, big.NewInt(1))},
+		{"N - 2", new(big.Int).Sub(N, big.NewInt(2))},
+		{"negative", big.NewInt(-3)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			want := new(big.Int).Mul(tc.x, inv2)
+			want.Mod(want, N)
+			for _, impl := range montContexts(R, N) {
+				if got := impl.m.Halve(tc.x); got.Cmp(want) != 0 {
+					t.Errorf("%s: Halve() = %v, want %v", impl.name, got, want)
+				}
+				if got := impl.m.FromMont(impl.m.HalveMont(impl.m.ToMont(tc.x))); got.Cmp(want) != 0 {
+					t.Errorf("%s: HalveMont() = %v, want %v", impl.name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDivisor(t *testing.T) {
+	t.Parallel()
+
+	x, _, R, N := testParams2048()
+
+	tests := []struct {
+		name    string
+		c       uint64
+		wantErr error
+	}{
+		{"one", 1, nil},
+		{"two", 2, nil},
+		{"three", 3, nil},
+		{"factorial", 3628800, nil},
+		{"max", 1<<64 - 1, nil},
+		{"zero", 0, ErrNotInvertible},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			for _, impl := range montContexts(R, N) {
+				d, err := impl.m.NewDivisor(tc.c)
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("%s: NewDivisor(%d) error = %v, want %v", impl.name, tc.c, err, tc.wantErr)
+				}
+				if err != nil {
+					continue
+				}
+				if d.C() != tc.c {
+					t.Errorf("%s: C() = %d, want %d", impl.name, d.C(), tc.c)
+				}
+				// (x / c) * c = x
+				c := new(big.Int).SetUint64(tc.c)
+				got := d.Div(x)
+				if back := new(big.Int).Mul(got, c); back.Mod(back, N).Cmp(x) != 0 {
+					t.Errorf("%s: Div(x) * %d != x", impl.name, tc.c)
+				}
+				if gotMont := impl.m.FromMont(d.DivMont(impl.m.ToMont(x))); gotMont.Cmp(got) != 0 {
+					t.Errorf("%s: DivMont() = %v, want %v", impl.name, gotMont, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDivisor_notInvertible(t *testing.T) {
+	t.Parallel()
+
+	// N = 3 · 5 · 7 · 11 is odd but composite
+	N := big.NewInt(1155)
+	m := NewMontgomeryCIOSWords(new(big.Int).Lsh(big.NewInt(1), 64), N)
+	for _, c := range []uint64{3, 21, 1155, 2310} {
+		if _, err := m.NewDivisor(c); !errors.Is(err, ErrNotInvertible) {
+			t.Errorf("NewDivisor(%d) error = %v, want ErrNotInvertible", c, err)
+		}
+	}
+	d, err := m.NewDivisor(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Div(big.NewInt(2)); got.Int64() != 578 { // 4 · 578 = 2312 ≡ 2
+		t.Errorf("Div(2) by 4 = %v, want 578", got)
+	}
+}
+
+func TestHalveProperty(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+
+	// Halving then doubling is the identity
+	err := quick.Check(func(xBytes []byte) bool {
+		x := new(big.Int).Mod(new(big.Int).SetBytes(xBytes), N)
+		h := m.Halve(x)
+		h.Lsh(h, 1).Mod(h, N)
+		return h.Cmp(x) == 0
+	}, &quick.Config{MaxCount: 200})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func BenchmarkDivide(b *testing.B) {
+	x, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	a := m.ToMont(x)
+	d, err := m.NewDivisor(3)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("Halve", func(b *testing.B) {
+		for b.Loop() {
+			m.HalveMont(a)
+		}
+	})
+	b.Run("Divisor", func(b *testing.B) {
+		for b.Loop() {
+			d.DivMont(a)
+		}
+	})
+	b.Run("ModInverse", func(b *testing.B) {
+		three := big.NewInt(3)
+		for b.Loop() {
+			m.MulMont(a, m.ToMont(new(big.Int).ModInverse(three, N)))
+		}
+	})
+}
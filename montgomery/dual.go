@@ -0,0 +1,183 @@
+package montgomery
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// dualLane is the fixed part of one lane of montMulWordsDual: a modulus as
+// S limbs, its word constant and the lane's own s+2 words of scratch.
+type dualLane struct {
+	n  []uint64
+	ni uint64
+	t  []uint64
+}
+
+// montMulWordsDual computes z0 = x0·y0·R⁻¹ mod N0 and z1 = x1·y1·R⁻¹ mod N1
+// for two moduli of the same S limbs in one pass, with the CIOS loops of
+// montMulWords fused: every step of the inner loops handles word j of both
+// lanes. The two multiply-add chains are independent, so an out-of-order
+// core overlaps one lane's multiplier latency with the other's carries,
+// which a single chain cannot do. The tail is montMulWords' masked
+// subtraction, so the dual kernel is constant time as well.
+//
+// Each lane follows the aliasing rules of montMulWords; the lanes must not
+// share memory with each other.
+func montMulWordsDual(z0, x0, y0, z1, x1, y1 []uint64, l0, l1 *dualLane) {
+	s := len(l0.n)
+	n0, n1 := l0.n[:s], l1.n[:s]
+	x0, y0, x1, y1 = x0[:s], y0[:s], x1[:s], y1[:s]
+	t0, t1 := l0.t[:s+2], l1.t[:s+2]
+	checkInPlace("montMulWordsDual", z0, x0)
+	checkInPlace("montMulWordsDual", z0, y0)
+	checkInPlace("montMulWordsDual", z1, x1)
+	checkInPlace("montMulWordsDual", z1, y1)
+	checkDisjoint("montMulWordsDual", t0, z0, x0, y0, n0, t1, z1)
+	checkDisjoint("montMulWordsDual", t1, z1, x1, y1, n1, z0)
+	clear(t0)
+	clear(t1)
+
+	for i := range s {
+		// t += x * y[i], both lanes
+		var c0, c1 uint64
+		a0, a1 := y0[i], y1[i]
+		for j := range s {
+			hi0, lo0 := bits.Mul64(x0[j], a0)
+			hi1, lo1 := bits.Mul64(x1[j], a1)
+			lo0, cc0 := bits.Add64(lo0, t0[j], 0)
+			lo1, cc1 := bits.Add64(lo1, t1[j], 0)
+			hi0 += cc0
+			hi1 += cc1
+			lo0, cc0 = bits.Add64(lo0, c0, 0)
+			lo1, cc1 = bits.Add64(lo1, c1, 0)
+			t0[j], c0 = lo0, hi0+cc0
+			t1[j], c1 = lo1, hi1+cc1
+		}
+		var cc0, cc1 uint64
+		t0[s], cc0 = bits.Add64(t0[s], c0, 0)
+		t1[s], cc1 = bits.Add64(t1[s], c1, 0)
+		t0[s+1], t1[s+1] = cc0, cc1
+
+		// t = (t + m * N) / 2^64, both lanes
+		m0, m1 := t0[0]*l0.ni, t1[0]*l1.ni
+		hi0, lo0 := bits.Mul64(m0, n0[0])
+		hi1, lo1 := bits.Mul64(m1, n1[0])
+		_, cc0 = bits.Add64(lo0, t0[0], 0)
+		_, cc1 = bits.Add64(lo1, t1[0], 0)
+		c0, c1 = hi0+cc0, hi1+cc1
+		for j := 1; j < s; j++ {
+			hi0, lo0 = bits.Mul64(m0, n0[j])
+			hi1, lo1 = bits.Mul64(m1, n1[j])
+			lo0, cc0 = bits.Add64(lo0, t0[j], 0)
+			lo1, cc1 = bits.Add64(lo1, t1[j], 0)
+			hi0 += cc0
+			hi1 += cc1
+			lo0, cc0 = bits.Add64(lo0, c0, 0)
+			lo1, cc1 = bits.Add64(lo1, c1, 0)
+			t0[j-1], c0 = lo0, hi0+cc0
+			t1[j-1], c1 = lo1, hi1+cc1
+		}
+		t0[s-1], cc0 = bits.Add64(t0[s], c0, 0)
+		t1[s-1], cc1 = bits.Add64(t1[s], c1, 0)
+		t0[s] = t0[s+1] + cc0
+		t1[s] = t1[s+1] + cc1
+	}
+
+	// t < 2N; subtract N when t ≥ N, selected by mask, both lanes
+	var b0, b1 uint64
+	for j := range s {
+		z0[j], b0 = bits.Sub64(t0[j], n0[j], b0)
+		z1[j], b1 = bits.Sub64(t1[j], n1[j], b1)
+	}
+	k0, k1 := ctMask(t0[s]|(b0^1)), ctMask(t1[s]|(b1^1))
+	for j := range s {
+		z0[j] = z0[j]&k0 | t0[j]&^k0
+		z1[j] = z1[j]&k1 | t1[j]&^k1
+	}
+}
+
+// ExpDual computes base0^exp0 mod N0 and base1^exp1 mod N1, the two
+// half-size exponentiations of RSA-CRT, in lockstep with the fixed-window,
+// masked-lookup schedule of ExpConstantTime, multiplying through
+// montMulWordsDual so that the two independent chains hide each other's
+// multiply latency. Both exponents are scanned over the same number of
+// windows, so neither length is revealed beyond the larger one.
+//
+// The interleaving needs moduli of the same word count, as CRT halves are;
+// otherwise ExpDual runs the two ExpConstantTime calls one after the other.
+// Bases outside [0, N) are handled by each context's InputPolicy. A
+// negative exponent raises the inverse of its base, as with
+// ExpConstantTime, and a lane whose base has no inverse returns nil. Each
+// exponent is blinded by its context's WithExponentBlinding.
+func ExpDual(m0, m1 *MontgomeryCIOSWords, base0, exp0, base1, exp1 *big.Int) (r0, r1 *big.Int) {
+	if m0.S != m1.S {
+		return m0.ExpConstantTime(base0, exp0), m1.ExpConstantTime(base1, exp1)
+	}
+	base0 = m0.cfg.input.mustOperand(m0.N, "ExpDual", base0)
+	base1 = m1.cfg.input.mustOperand(m1.N, "ExpDual", base1)
+	// A lane without an inverse runs on 0 so the other keeps its schedule,
+	// and its result is dropped
+	inv0, inv1 := true, true
+	if base0, exp0 = invertBase(m0.N, base0, exp0); base0 == nil {
+		base0, inv0 = new(big.Int), false
+	}
+	if base1, exp1 = invertBase(m1.N, base1, exp1); base1 == nil {
+		base1, inv1 = new(big.Int), false
+	}
+	exp0, exp1 = m0.cfg.blind.apply(exp0), m1.cfg.blind.apply(exp1)
+	s := m0.S
+	nbits := max(exp0.BitLen(), exp1.BitLen(), 64*s)
+	e0 := limbsPadded(exp0, (exp0.BitLen()+63)/64)
+	e1 := limbsPadded(exp1, (exp1.BitLen()+63)/64)
+
+	l0 := &dualLane{n: m0.nw, ni: m0.NI, t: make([]uint64, s+2)}
+	l1 := &dualLane{n: m1.nw, ni: m1.NI, t: make([]uint64, s+2)}
+
+	// Per lane: table[i] = base^i in Montgomery form, interleaved as in
+	// ExpConstantTime
+	const size = 1 << ctWindow
+	table0, table1 := make([]uint64, size*s), make([]uint64, size*s)
+	entry0, entry1 := make([]uint64, s), make([]uint64, s)
+	bm0, bm1 := make([]uint64, s), make([]uint64, s)
+	montMulWordsDual(entry0, m0.onew, m0.rrw, entry1, m1.onew, m1.rrw, l0, l1)
+	scatter(table0, entry0, 0)
+	scatter(table1, entry1, 0)
+	montMulWordsDual(bm0, limbsPadded(base0, s), m0.rrw, bm1, limbsPadded(base1, s), m1.rrw, l0, l1)
+	copy(entry0, bm0)
+	copy(entry1, bm1)
+	scatter(table0, entry0, 1)
+	scatter(table1, entry1, 1)
+	for i := 2; i < size; i++ {
+		montMulWordsDual(entry0, entry0, bm0, entry1, entry1, bm1, l0, l1)
+		scatter(table0, entry0, i)
+		scatter(table1, entry1, i)
+	}
+
+	windows := (nbits + ctWindow - 1) / ctWindow
+	acc0, acc1 := make([]uint64, s), make([]uint64, s)
+	gather(acc0, table0, 0)
+	gather(acc1, table1, 0)
+	for i := windows - 1; i >= 0; i-- {
+		for range ctWindow {
+			montMulWordsDual(acc0, acc0, acc0, acc1, acc1, acc1, l0, l1) // square
+		}
+		var d0, d1 uint64
+		for b := ctWindow - 1; b >= 0; b-- {
+			d0 = d0<<1 | limbBit(e0, i*ctWindow+b)
+			d1 = d1<<1 | limbBit(e1, i*ctWindow+b)
+		}
+		gather(entry0, table0, d0)
+		gather(entry1, table1, d1)
+		montMulWordsDual(acc0, acc0, entry0, acc1, acc1, entry1, l0, l1) // multiply
+	}
+
+	// Convert back from Montgomery form
+	montMulWordsDual(acc0, acc0, m0.onew, acc1, acc1, m1.onew, l0, l1)
+	if inv0 {
+		r0 = tobigInt(acc0)
+	}
+	if inv1 {
+		r1 = tobigInt(acc1)
+	}
+	return r0, r1
+}
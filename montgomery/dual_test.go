@@ -0,0 +1,134 @@
+package montgomery
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	mrand "math/rand/v2"
+	"testing"
+	"testing/quick"
+)
+
+// testPrimes returns two distinct deterministic primes of the given size.
+func testPrimes(bits int) (p, q *big.Int) {
+	rng := mrand.NewChaCha8([32]byte{'c', 'r', 't', byte(bits), byte(bits >> 8)})
+	for {
+		var err error
+		if p, err = rand.Prime(rng, bits); err != nil {
+			panic(err)
+		}
+		if q, err = rand.Prime(rng, bits); err != nil {
+			panic(err)
+		}
+		if p.Cmp(q) != 0 {
+			return p, q
+		}
+	}
+}
+
+func Test_montMulWordsDual(t *testing.T) {
+	t.Parallel()
+
+	p, q := testPrimes(1024)
+	mp, err := newWordAligned(p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mq, err := newWordAligned(q, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := mp.S
+	l0 := &dualLane{n: mp.nw, ni: mp.NI, t: make([]uint64, s+2)}
+	l1 := &dualLane{n: mq.nw, ni: mq.NI, t: make([]uint64, s+2)}
+	scratch := make([]uint64, s+2)
+
+	err = quick.Check(func(a, b, c, d []byte, alias bool) bool {
+		x0 := limbsPadded(new(big.Int).Mod(new(big.Int).SetBytes(a), p), s)
+		y0 := limbsPadded(new(big.Int).Mod(new(big.Int).SetBytes(b), p), s)
+		x1 := limbsPadded(new(big.Int).Mod(new(big.Int).SetBytes(c), q), s)
+		y1 := limbsPadded(new(big.Int).Mod(new(big.Int).SetBytes(d), q), s)
+		want0, want1 := make([]uint64, s), make([]uint64, s)
+		montMulWords(want0, x0, y0, mp.nw, mp.NI, scratch)
+		montMulWords(want1, x1, y1, mq.nw, mq.NI, scratch)
+
+		z0, z1 := make([]uint64, s), make([]uint64, s)
+		if alias {
+			z0, z1 = x0, y1
+		}
+		montMulWordsDual(z0, x0, y0, z1, x1, y1, l0, l1)
+		return tobigInt(z0).Cmp(tobigInt(want0)) == 0 && tobigInt(z1).Cmp(tobigInt(want1)) == 0
+	}, &quick.Config{MaxCount: 100})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExpDual(t *testing.T) {
+	t.Parallel()
+
+	p, q := testPrimes(1024)
+	mp, _ := newWordAligned(p, nil)
+	mq, _ := newWordAligned(q, nil)
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+	m64, _ := newWordAligned(N64, nil)
+	x := new(big.Int).Lsh(big.NewInt(0xabcdef), 900)
+
+	tests := []struct {
+		name       string
+		m0, m1     *MontgomeryCIOSWords
+		b0, e0     *big.Int
+		b1, e1     *big.Int
+		interleave bool
+	}{
+		{"same size", mp, mq, x, new(big.Int).Sub(p, big.NewInt(2)), big.NewInt(5), new(big.Int).Sub(q, big.NewInt(2)), true},
+		{"exponents of different lengths", mp, mq, x, big.NewInt(65537), x, new(big.Int).Lsh(q, 3), true},
+		{"zero exponent and base", mp, mq, big.NewInt(0), big.NewInt(9), x, big.NewInt(0), true},
+		{"unreduced bases", mp, mq, new(big.Int).Add(p, x), big.NewInt(3), new(big.Int).Neg(x), big.NewInt(3), true},
+		{"different sizes", mp, m64, x, big.NewInt(1234567), big.NewInt(3), big.NewInt(1234567), false},
+		{"negative exponents", mp, mq, x, big.NewInt(-65537), big.NewInt(5), new(big.Int).Neg(x), true},
+		{"negative exponent, non-invertible base", mp, mq, new(big.Int).Set(p), big.NewInt(-3), x, big.NewInt(-3), true},
+		{"negative exponents, different sizes", mp, m64, x, big.NewInt(-3), big.NewInt(5), big.NewInt(-3), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tc.m0.S == tc.m1.S; got != tc.interleave {
+				t.Fatalf("interleaved = %v, want %v", got, tc.interleave)
+			}
+			want0 := tc.m0.ExpConstantTime(tc.b0, tc.e0)
+			want1 := tc.m1.ExpConstantTime(tc.b1, tc.e1)
+			got0, got1 := ExpDual(tc.m0, tc.m1, tc.b0, tc.e0, tc.b1, tc.e1)
+			same := func(got, want *big.Int) bool {
+				return (got == nil) == (want == nil) && (got == nil || got.Cmp(want) == 0)
+			}
+			if !same(got0, want0) || !same(got1, want1) {
+				t.Errorf("ExpDual() = %v, %v, want %v, %v", got0, got1, want0, want1)
+			}
+		})
+	}
+}
+
+func BenchmarkExpDual(b *testing.B) {
+	for _, bits := range []int{512, 1024, 2048} {
+		p, q := testPrimes(bits)
+		mp, _ := newWordAligned(p, nil)
+		mq, _ := newWordAligned(q, nil)
+		x := new(big.Int).Sub(p, big.NewInt(3))
+		y := new(big.Int).Sub(q, big.NewInt(5))
+		ep, eq := new(big.Int).Sub(p, big.NewInt(2)), new(big.Int).Sub(q, big.NewInt(2))
+
+		b.Run(fmt.Sprintf("bits=%d/impl=dual", bits), func(b *testing.B) {
+			for b.Loop() {
+				ExpDual(mp, mq, x, ep, y, eq)
+			}
+		})
+		b.Run(fmt.Sprintf("bits=%d/impl=sequential", bits), func(b *testing.B) {
+			for b.Loop() {
+				mp.ExpConstantTime(x, ep)
+				mq.ExpConstantTime(y, eq)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package montgomery
+
+import "math/big"
+
+// MontElement is a residue mod N held in Montgomery form, x·R mod N.
+//
+// Mul converts both operands into Montgomery form and the result back out
+// on every call, three REDCs of overhead around the one that does the work.
+// Chained computations instead convert once with ToMont, stay in Montgomery
+// form through MulMont, AddMont, SubMont and NegMont, and convert the final
+// result with FromMont.
+//
+// A MontElement is only meaningful with the context that created it, or one
+// with the same R and N. It is immutable, so copies may be shared freely;
+// the zero value is the element 0.
+type MontElement struct {
+	v *big.Int // x·R mod N in [0, N); nil means 0
+}
+
+// Equal reports whether a and b represent the same residue. Montgomery form
+// is a bijection, so no conversion is needed.
+func (a MontElement) Equal(b MontElement) bool {
+	return a.val().Cmp(b.val()) == 0
+}
+
+func (a MontElement) val() *big.Int {
+	if a.v == nil {
+		return new(big.Int)
+	}
+	return a.v
+}
+
+// ToMont converts x into Montgomery form; see InputPolicy for x outside
+// [0, N).
+func (m *MontgomeryBitwise) ToMont(x *big.Int) MontElement { return toMont(m.engine(), x) }
+
+// FromMont converts a out of Montgomery form into [0, N).
+func (m *MontgomeryBitwise) FromMont(a MontElement) *big.Int { return fromMont(m.engine(), a) }
+
+// MulMont returns a·b in Montgomery form with a single REDC.
+func (m *MontgomeryBitwise) MulMont(a, b MontElement) MontElement {
+	return MontElement{m.redc(a.val(), b.val())}
+}
+
+// AddMont returns a+b in Montgomery form.
+func (m *MontgomeryBitwise) AddMont(a, b MontElement) MontElement { return addMont(m.N, a, b) }
+
+// SubMont returns a-b in Montgomery form.
+func (m *MontgomeryBitwise) SubMont(a, b MontElement) MontElement { return subMont(m.N, a, b) }
+
+// NegMont returns -a in Montgomery form.
+func (m *MontgomeryBitwise) NegMont(a MontElement) MontElement { return negMont(m.N, a) }
+
+// ToMont converts x into Montgomery form; see InputPolicy for x outside
+// [0, N).
+func (m *MontgomeryCIOS) ToMont(x *big.Int) MontElement { return toMont(m.engine(), x) }
+
+// FromMont converts a out of Montgomery form into [0, N).
+func (m *MontgomeryCIOS) FromMont(a MontElement) *big.Int { return fromMont(m.engine(), a) }
+
+// MulMont returns a·b in Montgomery form with a single REDC.
+func (m *MontgomeryCIOS) MulMont(a, b MontElement) MontElement {
+	return MontElement{m.redc(a.val(), b.val())}
+}
+
+// AddMont returns a+b in Montgomery form.
+func (m *MontgomeryCIOS) AddMont(a, b MontElement) MontElement { return addMont(m.N, a, b) }
+
+// SubMont returns a-b in Montgomery form.
+func (m *MontgomeryCIOS) SubMont(a, b MontElement) MontElement { return subMont(m.N, a, b) }
+
+// NegMont returns -a in Montgomery form.
+func (m *MontgomeryCIOS) NegMont(a MontElement) MontElement { return negMont(m.N, a) }
+
+// ToMont converts x into Montgomery form; see InputPolicy for x outside
+// [0, N).
+func (m *MontgomeryCIOSWords) ToMont(x *big.Int) MontElement { return toMont(m.engine(), x) }
+
+// FromMont converts a out of Montgomery form into [0, N).
+func (m *MontgomeryCIOSWords) FromMont(a MontElement) *big.Int { return fromMont(m.engine(), a) }
+
+// MulMont returns a·b in Montgomery form with a single REDC.
+func (m *MontgomeryCIOSWords) MulMont(a, b MontElement) MontElement {
+	return MontElement{m.redc(a.val(), b.val())}
+}
+
+// AddMont returns a+b in Montgomery form.
+func (m *MontgomeryCIOSWords) AddMont(a, b MontElement) MontElement { return addMont(m.N, a, b) }
+
+// SubMont returns a-b in Montgomery form.
+func (m *MontgomeryCIOSWords) SubMont(a, b MontElement) MontElement { return subMont(m.N, a, b) }
+
+// NegMont returns -a in Montgomery form.
+func (m *MontgomeryCIOSWords) NegMont(a MontElement) MontElement { return negMont(m.N, a) }
+
+// toMont returns x·R mod N as REDC(x mod N, R²). Bringing x into range
+// first, under the InputPolicy, keeps the REDC kernels on non-negative
+// operands below R.
+func toMont(eng engine, x *big.Int) MontElement {
+	return MontElement{eng.redc(eng.input.mustOperand(eng.n, "ToMont", x), eng.rr)}
+}
+
+// fromMont returns a·R⁻¹ mod N as REDC(a, 1).
+func fromMont(eng engine, a MontElement) *big.Int {
+	return eng.redc(a.val(), big.NewInt(1))
+}
+
+// addMont adds in Montgomery form, which is ordinary modular addition since
+// x·R + y·R = (x+y)·R.
+func addMont(n *big.Int, a, b MontElement) MontElement {
+	z := new(big.Int).Add(a.val(), b.val())
+	if z.Cmp(n) >= 0 {
+		z.Sub(z, n)
+	}
+	return MontElement{z}
+}
+
+func subMont(n *big.Int, a, b MontElement) MontElement {
+	z := new(big.Int).Sub(a.val(), b.val())
+	if z.Sign() < 0 {
+		z.Add(z, n)
+	}
+	return MontElement{z}
+}
+
+// negMont negates in Montgomery form, as 0 - a.
+func negMont(n *big.Int, a MontElement) MontElement {
+	return subMont(n, MontElement{}, a)
+}
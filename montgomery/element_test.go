@@ -0,0 +1,197 @@
+package montgomery
+
+import (
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+// montContext is the API shared by all three implementations, so the
+// per-operation tests cover a new implementation once it is listed in
+// montContexts.
+type montContext interface {
+	Mul(x, y *big.Int) *big.Int
+	MulChecked(x, y *big.Int) (*big.Int, error)
+	Exp(base, exp *big.Int) *big.Int
+	ExpChecked(base, exp *big.Int) (*big.Int, error)
+	Halve(x *big.Int) *big.Int
+	HalveMont(a MontElement) MontElement
+	NewDivisor(c uint64) (*Divisor, error)
+	Reduce(t *big.Int) *big.Int
+	ToMont(x *big.Int) MontElement
+	FromMont(a MontElement) *big.Int
+	MulMont(a, b MontElement) MontElement
+	AddMont(a, b MontElement) MontElement
+	SubMont(a, b MontElement) MontElement
+	NegMont(a MontElement) MontElement
+	NewOddPowers(g *big.Int, w int) *OddPowers
+}
+
+// montContexts returns every implementation for R and N, built with opts.
+func montContexts(R, N *big.Int, opts ...Option) []struct {
+	name string
+	m    montContext
+} {
+	return []struct {
+		name string
+		m    montContext
+	}{
+		{"Bitwise", NewMontgomeryBitwise(R, N, opts...)},
+		{"CIOS", NewMontgomeryCIOS(R, N, opts...)},
+		{"CIOSWords", NewMontgomeryCIOSWords(R, N, opts...)},
+	}
+}
+
+// montConstructors returns the constructor of every ModMultiplier
+// implementation, the three of montContexts plus CT and CarrySave, for
+// tests that build a context under recover or with varying parameters.
+func montConstructors() []struct {
+	name string
+	new  func(R, N *big.Int, opts ...Option) ModMultiplier
+} {
+	return []struct {
+		name string
+		new  func(R, N *big.Int, opts ...Option) ModMultiplier
+	}{
+		{"Bitwise", func(R, N *big.Int, opts ...Option) ModMultiplier { return NewMontgomeryBitwise(R, N, opts...) }},
+		{"CIOS", func(R, N *big.Int, opts ...Option) ModMultiplier { return NewMontgomeryCIOS(R, N, opts...) }},
+		{"CIOSWords", func(R, N *big.Int, opts ...Option) ModMultiplier { return NewMontgomeryCIOSWords(R, N, opts...) }},
+		{"CT", func(R, N *big.Int, opts ...Option) ModMultiplier { return NewMontgomeryCT(R, N, opts...) }},
+		{"CarrySave", func(R, N *big.Int, opts ...Option) ModMultiplier { return NewMontgomeryCarrySave(R, N, opts...) }},
+	}
+}
+
+// montFromModulus returns the FromModulus constructor of each montContexts
+// implementation, returning the context with the R it chose.
+func montFromModulus() []struct {
+	name string
+	new  func(N *big.Int, opts ...Option) (ModMultiplier, *big.Int, error)
+} {
+	return []struct {
+		name string
+		new  func(N *big.Int, opts ...Option) (ModMultiplier, *big.Int, error)
+	}{
+		{"Bitwise", func(N *big.Int, opts ...Option) (ModMultiplier, *big.Int, error) {
+			m, err := NewMontgomeryBitwiseFromModulus(N, opts...)
+			if err != nil {
+				return nil, nil, err
+			}
+			return m, m.R, nil
+		}},
+		{"CIOS", func(N *big.Int, opts ...Option) (ModMultiplier, *big.Int, error) {
+			m, err := NewMontgomeryCIOSFromModulus(N, opts...)
+			if err != nil {
+				return nil, nil, err
+			}
+			return m, m.R, nil
+		}},
+		{"CIOSWords", func(N *big.Int, opts ...Option) (ModMultiplier, *big.Int, error) {
+			m, err := NewMontgomeryCIOSWordsFromModulus(N, opts...)
+			if err != nil {
+				return nil, nil, err
+			}
+			return m, m.R, nil
+		}},
+	}
+}
+
+func TestToMontFromMont(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	inputs := []*big.Int{
+		big.NewInt(0), big.NewInt(1), x, y,
+		new(big.Int).Neg(x),
+		new(big.Int).Add(y, N),
+		new(big.Int).Sub(N, big.NewInt(1)),
+	}
+	for _, impl := range montContexts(R, N) {
+		t.Run(impl.name, func(t *testing.T) {
+			t.Parallel()
+			for _, in := range inputs {
+				a := impl.m.ToMont(in)
+				want := new(big.Int).Lsh(in, uint(R.BitLen()-1))
+				want.Mod(want, N)
+				if a.val().Cmp(want) != 0 {
+					t.Errorf("ToMont(%v) = %v, want x·R mod N = %v", in, a.val(), want)
+				}
+				if got, want := impl.m.FromMont(a), new(big.Int).Mod(in, N); got.Cmp(want) != 0 {
+					t.Errorf("FromMont(ToMont(%v)) = %v, want %v", in, got, want)
+				}
+			}
+			if got := impl.m.FromMont(MontElement{}); got.Sign() != 0 {
+				t.Errorf("FromMont(zero value) = %v, want 0", got)
+			}
+		})
+	}
+}
+
+func TestMontElement_arithmetic(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	for _, impl := range montContexts(R, N) {
+		t.Run(impl.name, func(t *testing.T) {
+			t.Parallel()
+			m := impl.m
+			err := quick.Check(func(a, b, c uint64) bool {
+				x, y, z := new(big.Int).SetUint64(a), new(big.Int).SetUint64(b), new(big.Int).SetUint64(c)
+				xm, ym, zm := m.ToMont(x), m.ToMont(y), m.ToMont(z)
+
+				// x·y + z - x, both ways
+				got := m.FromMont(m.SubMont(m.AddMont(m.MulMont(xm, ym), zm), xm))
+				want := new(big.Int).Mul(x, y)
+				want.Add(want, z).Sub(want, x).Mod(want, N)
+				if got.Cmp(want) != 0 {
+					return false
+				}
+				// -(x·y) + z
+				got = m.FromMont(m.AddMont(m.NegMont(m.MulMont(xm, ym)), zm))
+				want.Mul(x, y).Sub(z, want).Mod(want, N)
+				return got.Cmp(want) == 0
+			}, &quick.Config{MaxCount: 50})
+			if err != nil {
+				t.Error(err)
+			}
+
+			one := m.ToMont(big.NewInt(1))
+			zero := MontElement{}
+			if !m.SubMont(one, one).Equal(zero) || !m.AddMont(zero, one).Equal(one) {
+				t.Error("zero value does not behave as 0")
+			}
+			if !m.MulMont(one, m.ToMont(big.NewInt(5))).Equal(m.ToMont(big.NewInt(5))) {
+				t.Error("1·5 != 5 in Montgomery form")
+			}
+			if !m.SubMont(zero, one).Equal(m.ToMont(big.NewInt(-1))) {
+				t.Error("0 - 1 != -1 in Montgomery form")
+			}
+			if !m.NegMont(zero).Equal(zero) || !m.NegMont(m.NegMont(one)).Equal(one) {
+				t.Error("NegMont does not fix 0 or is not an involution")
+			}
+		})
+	}
+}
+
+// BenchmarkChainedMul compares a chain of 64 multiplications through Mul
+// against the same chain kept in Montgomery form.
+func BenchmarkChainedMul(b *testing.B) {
+	x, y, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	b.Run("Mul", func(b *testing.B) {
+		for b.Loop() {
+			acc := x
+			for range 64 {
+				acc = m.Mul(acc, y)
+			}
+		}
+	})
+	b.Run("MulMont", func(b *testing.B) {
+		for b.Loop() {
+			acc, ym := m.ToMont(x), m.ToMont(y)
+			for range 64 {
+				acc = m.MulMont(acc, ym)
+			}
+			m.FromMont(acc)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// EvenCtx is arithmetic modulo an even N = 2^k·q, q odd, which Montgomery
+// reduction cannot handle on its own: -N⁻¹ mod R does not exist. The odd
+// part gets a Montgomery context, the power of two is plain arithmetic
+// that truncates products to k bits, and the two results are recombined
+// with the CRT:
+//
+//	x = a + q·((b − a)·q⁻¹ mod 2^k)   for x ≡ a mod q, x ≡ b mod 2^k
+//
+// Open and New return an EvenCtx for an even N, so callers that pass
+// moduli through without looking at them get correct results instead of
+// the silently wrong ones of a plain constructor. For N = 2^k there is no
+// odd part and no Montgomery context.
+//
+// An EvenCtx is immutable and safe for concurrent use if the odd part is.
+type EvenCtx struct {
+	N    *big.Int      // modulus
+	Q    *big.Int      // odd part of N
+	K    uint          // N = 2^K·Q, K ≥ 1
+	odd  ModMultiplier // arithmetic mod Q; nil when Q = 1
+	qInv *big.Int      // Q⁻¹ mod 2^K
+	cfg  config
+}
+
+// NewEvenCtx is Open for an even N with the smallest word-aligned R
+// greater than N, returning the EvenCtx itself. opts select the backend of
+// the odd part as for Open. It returns an error wrapping
+// ErrInvalidParameters for an odd N or one below 2.
+func NewEvenCtx(N *big.Int, opts ...Option) (*EvenCtx, error) {
+	if N == nil || N.Cmp(big.NewInt(1)) <= 0 {
+		return nil, ErrModulusTooSmall
+	}
+	if N.Bit(0) == 1 {
+		return nil, fmt.Errorf("%w: N is odd", ErrInvalidParameters)
+	}
+	m, err := Open(wordAlignedR(N, opts), N, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return m.(*EvenCtx), nil
+}
+
+// openEven splits the even N ≥ 2 and builds its odd part, if any, with
+// backend b and R, which Open has checked is greater than N.
+func openEven(R, N *big.Int, b Backend, opts []Option) (*EvenCtx, error) {
+	k := N.TrailingZeroBits()
+	c := &EvenCtx{
+		N:   new(big.Int).Set(N),
+		Q:   new(big.Int).Rsh(N, k),
+		K:   k,
+		cfg: newConfig(opts),
+	}
+	if c.Q.Cmp(big.NewInt(1)) > 0 {
+		odd, err := b(R, c.Q, opts...)
+		if err != nil {
+			return nil, err
+		}
+		c.odd = odd
+	}
+	c.qInv = new(big.Int).ModInverse(c.Q, new(big.Int).Lsh(big.NewInt(1), k))
+	return c, nil
+}
+
+// Modulus returns N.
+func (c *EvenCtx) Modulus() *big.Int { return new(big.Int).Set(c.N) }
+
+// Mul returns (x * y) mod N. Operands outside [0, N) are handled by the
+// context's InputPolicy.
+func (c *EvenCtx) Mul(x, y *big.Int) *big.Int {
+	x = c.cfg.input.mustOperand(c.N, "Mul", x)
+	y = c.cfg.input.mustOperand(c.N, "Mul", y)
+	var a *big.Int
+	if c.odd != nil {
+		a = c.odd.Mul(new(big.Int).Mod(x, c.Q), new(big.Int).Mod(y, c.Q))
+	}
+	b := c.low(new(big.Int).Mul(c.low(x), c.low(y)))
+	return c.combine(a, b)
+}
+
+// Exp returns base^exp mod N with the semantics of big.Int.Exp: any base is
+// reduced into [0, N), and a negative exponent raises the inverse, or
+// returns nil if there is none. The odd part uses its context's Exp where
+// it has one.
+func (c *EvenCtx) Exp(base, exp *big.Int) *big.Int {
+	g := new(big.Int).Mod(base, c.N)
+	if exp.Sign() < 0 {
+		if g.ModInverse(g, c.N) == nil {
+			return nil
+		}
+		exp = new(big.Int).Neg(exp)
+	}
+	var a *big.Int
+	if c.odd != nil {
+		gq := new(big.Int).Mod(g, c.Q)
+		if e, ok := c.odd.(exponentiator); ok {
+			a = e.Exp(gq, exp)
+		} else {
+			a = expMul(c.odd, gq, exp)
+		}
+	}
+
+	// Square-and-multiply with products truncated to K bits
+	gb, b := c.low(g), big.NewInt(1)
+	for i := exp.BitLen() - 1; i >= 0; i-- {
+		b = c.low(b.Mul(b, b))
+		if exp.Bit(i) == 1 {
+			b = c.low(b.Mul(b, gb))
+		}
+	}
+	return c.combine(a, c.low(b))
+}
+
+// combine returns the x in [0, N) with x ≡ a mod Q and x ≡ b mod 2^K, for
+// a in [0, Q), or nil for Q = 1, and b in [0, 2^K).
+func (c *EvenCtx) combine(a, b *big.Int) *big.Int {
+	if a == nil {
+		return b
+	}
+	h := new(big.Int).Sub(b, a)
+	h = c.low(h.Mul(h, c.qInv))
+	return h.Add(h.Mul(h, c.Q), a)
+}
+
+// low returns x mod 2^K, for x of any sign, in a new big.Int.
+func (c *EvenCtx) low(x *big.Int) *big.Int {
+	z := new(big.Int).Set(x)
+	if z.Sign() < 0 || uint(z.BitLen()) > c.K {
+		mask := new(big.Int).Lsh(big.NewInt(1), c.K)
+		z.Mod(z, mask)
+	}
+	return z
+}
+
+// expMul returns base^exp mod N, for exp ≥ 0, by square-and-multiply on
+// m.Mul, for ModMultipliers without an Exp of their own.
+func expMul(m ModMultiplier, base, exp *big.Int) *big.Int {
+	z := new(big.Int).Mod(big.NewInt(1), m.Modulus())
+	for i := exp.BitLen() - 1; i >= 0; i-- {
+		z = m.Mul(z, z)
+		if exp.Bit(i) == 1 {
+			z = m.Mul(z, base)
+		}
+	}
+	return z
+}
+
+var _ ModMultiplier = (*EvenCtx)(nil)
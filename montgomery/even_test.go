@@ -0,0 +1,170 @@
+package montgomery
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestEvenCtx(t *testing.T) {
+	t.Parallel()
+
+	_, _, _, n256 := testParamsLarge(256)
+	_, _, _, n2048 := testParamsLarge(2048)
+	tests := []struct {
+		name string
+		N    *big.Int
+	}{
+		{"2", big.NewInt(2)},
+		{"4", big.NewInt(4)},
+		{"6", big.NewInt(6)},
+		{"2^64", new(big.Int).Lsh(big.NewInt(1), 64)},
+		{"2^65·3", new(big.Int).Lsh(big.NewInt(3), 65)},
+		// the power of two in the low bit only, and in whole words
+		{"2·q 256", new(big.Int).Lsh(n256, 1)},
+		{"2^128·q 256", new(big.Int).Lsh(n256, 128)},
+		{"2·q 2048", new(big.Int).Lsh(n2048, 1)},
+		{"q-1 2048", new(big.Int).Sub(n2048, big.NewInt(1))},
+	}
+	for _, tc := range tests {
+		for _, kind := range []Kind{KindBitwise, KindCIOS, KindCIOSWords, KindCT} {
+			t.Run(fmt.Sprintf("%s/%v", tc.name, kind), func(t *testing.T) {
+				t.Parallel()
+				N := tc.N
+				m, err := New(kind, N)
+				if err != nil {
+					t.Fatal(err)
+				}
+				c, ok := m.(*EvenCtx)
+				if !ok {
+					t.Fatalf("New() = %T, want *EvenCtx", m)
+				}
+				if c.Modulus().Cmp(N) != 0 {
+					t.Errorf("Modulus() = %v, want %v", c.Modulus(), N)
+				}
+				nMinus1 := new(big.Int).Sub(N, big.NewInt(1))
+				check := func(x, y *big.Int) bool {
+					want := new(big.Int).Mod(new(big.Int).Mul(x, y), N)
+					if got := c.Mul(x, y); got.Cmp(want) != 0 {
+						t.Errorf("Mul(%v, %v) = %v, want %v", x, y, got, want)
+						return false
+					}
+					e := new(big.Int).Add(y, big.NewInt(3))
+					if got, want := c.Exp(x, e), new(big.Int).Exp(x, e, N); got.Cmp(want) != 0 {
+						t.Errorf("Exp(%v, %v) = %v, want %v", x, e, got, want)
+						return false
+					}
+					return true
+				}
+				check(nMinus1, nMinus1)
+				check(big.NewInt(0), nMinus1)
+				check(nMinus1, big.NewInt(0))
+				err = quick.Check(func(xBytes, yBytes []byte) bool {
+					x := new(big.Int).Mod(new(big.Int).SetBytes(xBytes), N)
+					y := new(big.Int).Mod(new(big.Int).SetBytes(yBytes), N)
+					return check(x, y)
+				}, &quick.Config{MaxCount: 10})
+				if err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
+
+func TestEvenCtx_Exp(t *testing.T) {
+	t.Parallel()
+
+	N := big.NewInt(2 * 2 * 2 * 3 * 5 * 7)
+	c, err := NewEvenCtx(N)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x := range int64(N.Int64()) {
+		for _, e := range []int64{-3, -1, 0, 1, 2, 7, 1 << 40} {
+			xb, eb := big.NewInt(x), big.NewInt(e)
+			want := new(big.Int).Exp(xb, eb, N)
+			if got := c.Exp(xb, eb); (got == nil) != (want == nil) || got != nil && got.Cmp(want) != 0 {
+				t.Errorf("Exp(%d, %d) = %v, want %v", x, e, got, want)
+			}
+		}
+	}
+	// Bases outside [0, N) are reduced, as by big.Int.Exp
+	if got, want := c.Exp(big.NewInt(-11), big.NewInt(5)), new(big.Int).Exp(big.NewInt(-11), big.NewInt(5), N); got.Cmp(want) != 0 {
+		t.Errorf("Exp(-11, 5) = %v, want %v", got, want)
+	}
+}
+
+func TestEvenCtx_inputPolicy(t *testing.T) {
+	t.Parallel()
+
+	N := big.NewInt(24)
+	c, err := NewEvenCtx(N)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Mul(big.NewInt(25), big.NewInt(-5)); got.Cmp(big.NewInt(19)) != 0 {
+		t.Errorf("Mul(25, -5) = %v, want 19", got)
+	}
+	strict, err := NewEvenCtx(N, WithInputPolicy(InputStrict))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !panics(func() { strict.Mul(N, big.NewInt(1)) }) {
+		t.Error("Mul(N, 1) under InputStrict did not panic with ErrOperandRange")
+	}
+}
+
+func TestNewEvenCtx_errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		N    *big.Int
+		opts []Option
+		want error
+	}{
+		{"odd", big.NewInt(15), nil, ErrInvalidParameters},
+		{"zero", big.NewInt(0), nil, ErrModulusTooSmall},
+		{"negative", big.NewInt(-4), nil, ErrModulusTooSmall},
+		{"unknown backend", big.NewInt(12), []Option{WithBackend("gpu")}, ErrUnknownBackend},
+		// the odd part's backend fails even for N = 2^k, which has none
+		{"unknown backend, power of two", big.NewInt(16), []Option{WithBackend("gpu")}, ErrUnknownBackend},
+		{"backend error", big.NewInt(12), []Option{WithBackend("test-failing")}, errTestBackend},
+		{"faulty backend", big.NewInt(12), []Option{WithBackend("test-faulty"), WithSelfTest(8)}, ErrSelfTest},
+	}
+	for _, tc := range tests {
+		if _, err := NewEvenCtx(tc.N, tc.opts...); !errors.Is(err, tc.want) {
+			t.Errorf("%s: error = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+// BenchmarkEvenCtx compares Mul mod 2N, an EvenCtx around cioswords, with
+// Mul mod the odd N alone.
+func BenchmarkEvenCtx(b *testing.B) {
+	for _, bits := range []int{256, 2048} {
+		x, y, _, N := testParamsLarge(bits)
+		even := new(big.Int).Lsh(N, 1)
+		c, err := NewEvenCtx(even)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprintf("bits=%d/impl=even", bits), func(b *testing.B) {
+			for b.Loop() {
+				c.Mul(x, y)
+			}
+		})
+		m, err := New(KindCIOSWords, N)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprintf("bits=%d/impl=odd", bits), func(b *testing.B) {
+			for b.Loop() {
+				m.Mul(x, y)
+			}
+		})
+	}
+}
@@ -0,0 +1,374 @@
+package montgomery
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// redcFunc performs Montgomery reduction (x * y * R⁻¹) mod N.
+//
+// Every implementation in this package provides one, which lets the
+// exponentiation helpers below be shared instead of duplicated per type.
+type redcFunc func(x, y *big.Int) *big.Int
+
+// engine bundles what the shared helpers need from an implementation.
+type engine struct {
+	redc redcFunc
+	// redcInto is redc storing into z, which may be x or y. Implementations
+	// without a scratch-reusing kernel ignore z and return a fresh big.Int,
+	// so callers must always use the return value.
+	redcInto func(z, x, y *big.Int) *big.Int
+	// sqr is redcInto(z, x, x), possibly by a dedicated squaring kernel.
+	sqr func(z, x *big.Int) *big.Int
+	rr  *big.Int // R² mod N
+	n   *big.Int // modulus
+	k   uint     // R = 2^k
+
+	input InputPolicy
+
+	// strategy and window are the WithWindowStrategy and WithWindowSize
+	// settings; window 0 means chosen per exponent.
+	strategy WindowStrategy
+	window   int
+	tables   *tableCache // nil unless WithTableCache
+
+	// maxEntries is how many Montgomery elements window tables may hold at
+	// once under the memory budget; 0 means unlimited.
+	maxEntries int
+}
+
+// newEngine builds the engine for an implementation with the given reduction.
+func newEngine(redc redcFunc, rr, n, r *big.Int, cfg config) engine {
+	k := uint(r.BitLen() - 1)
+	return engine{
+		redc:       redc,
+		redcInto:   func(_, x, y *big.Int) *big.Int { return redc(x, y) },
+		sqr:        func(_, x *big.Int) *big.Int { return redc(x, x) },
+		rr:         rr,
+		n:          n,
+		k:          k,
+		input:      cfg.input,
+		strategy:   cfg.window,
+		window:     cfg.windowSize,
+		tables:     cfg.tables,
+		maxEntries: cfg.maxEntries(int(k+7) / 8),
+	}
+}
+
+func (m *MontgomeryBitwise) engine() engine {
+	return newEngine(m.redc, m.RR, m.N, m.R, m.cfg)
+}
+
+func (m *MontgomeryCIOS) engine() engine {
+	return newEngine(m.redc, m.RR, m.N, m.R, m.cfg)
+}
+
+func (m *MontgomeryCIOSWords) engine() engine {
+	eng := newEngine(m.redc, m.RR, m.N, m.R, m.cfg)
+	eng.redcInto = m.redcInto
+	eng.sqr = m.redcSqrInto
+	return eng
+}
+
+// Exp computes base^exp mod N using bit-by-bit Montgomery reduction, with
+// the sparse fast path and window schedule of expMont. See expFull for the
+// handling of negative and unreduced operands.
+func (m *MontgomeryBitwise) Exp(base, exp *big.Int) *big.Int {
+	return expFull(m.engine(), base, exp)
+}
+
+// Exp computes base^exp mod N using CIOS Montgomery reduction, with the
+// sparse fast path and window schedule of expMont. See expFull for the
+// handling of negative and unreduced operands.
+func (m *MontgomeryCIOS) Exp(base, exp *big.Int) *big.Int {
+	return expFull(m.engine(), base, exp)
+}
+
+// Exp computes base^exp mod N using CIOS Montgomery reduction on []uint64
+// words, with the sparse fast path and window schedule of expMont. See
+// expFull for the handling of negative and unreduced operands.
+func (m *MontgomeryCIOSWords) Exp(base, exp *big.Int) *big.Int {
+	return expFull(m.engine(), base, exp)
+}
+
+// expFull computes base^exp mod N for any exponent with the semantics of
+// big.Int.Exp: a negative exponent raises the inverse of the base, or
+// yields nil if the base is not invertible mod N. A base outside [0, N) is
+// handled by the InputPolicy, which under the default reduces it like
+// big.Int.Exp does. The base is reduced before it is inverted, where
+// big.Int.Exp inverts |base| and so loses the sign of a negative base under
+// an odd negative exponent. The whole chain, conversions included, stays in
+// Montgomery form, so the cost over expMont is at most one division.
+func expFull(eng engine, base, exp *big.Int) *big.Int {
+	b := eng.input.mustOperand(eng.n, "Exp", base)
+	if b, exp = invertBase(eng.n, b, exp); b == nil {
+		return nil
+	}
+	return expMont(eng, b, exp)
+}
+
+// invertBase turns base^exp for a negative exp into (base⁻¹)^-exp, the
+// semantics of big.Int.Exp, for exponentiations whose schedules read the
+// exponent's magnitude through Bits. base must be in [0, N). The inverse
+// is nil if base is not invertible mod N. A non-negative exp returns base
+// and exp unchanged.
+func invertBase(n, base, exp *big.Int) (*big.Int, *big.Int) {
+	if exp.Sign() >= 0 {
+		return base, exp
+	}
+	return new(big.Int).ModInverse(base, n), new(big.Int).Neg(exp)
+}
+
+// ExpBatch computes base^e mod N for every base in bases using bit-by-bit
+// Montgomery reduction. See expBatchFull for details.
+func (m *MontgomeryBitwise) ExpBatch(bases []*big.Int, e *big.Int) []*big.Int {
+	return expBatchFull(m.engine(), bases, e)
+}
+
+// ExpBatch computes base^e mod N for every base in bases using CIOS
+// Montgomery reduction. See expBatchFull for details.
+func (m *MontgomeryCIOS) ExpBatch(bases []*big.Int, e *big.Int) []*big.Int {
+	return expBatchFull(m.engine(), bases, e)
+}
+
+// ExpBatch computes base^e mod N for every base in bases using CIOS
+// Montgomery reduction on []uint64 words. See expBatchFull for details.
+func (m *MontgomeryCIOSWords) ExpBatch(bases []*big.Int, e *big.Int) []*big.Int {
+	return expBatchFull(m.engine(), bases, e)
+}
+
+// expBatchFull is expBatch for any exponent and any bases, with the
+// semantics of Exp for each: bases are brought into [0, N) by the
+// InputPolicy, and a negative e raises their inverses, with a nil result
+// for every base that has none.
+func expBatchFull(eng engine, bases []*big.Int, e *big.Int) []*big.Int {
+	bases = reduceBases(eng, bases)
+	if e.Sign() >= 0 {
+		return expBatch(eng, bases, e)
+	}
+	// A base without an inverse runs as 0, which no inverse is, and its
+	// result is dropped
+	inv := make([]*big.Int, len(bases))
+	for i, b := range bases {
+		if inv[i], _ = invertBase(eng.n, b, e); inv[i] == nil {
+			inv[i] = new(big.Int)
+		}
+	}
+	results := expBatch(eng, inv, new(big.Int).Neg(e))
+	for i := range results {
+		if inv[i].Sign() == 0 {
+			results[i] = nil
+		}
+	}
+	return results
+}
+
+// reduceBases returns the bases brought into [0, N) under the InputPolicy.
+// The REDC kernels assume non-negative operands, and a negative base would
+// otherwise come out wrong. Bases already in range are not copied.
+func reduceBases(eng engine, bases []*big.Int) []*big.Int {
+	r := make([]*big.Int, len(bases))
+	for i, b := range bases {
+		r[i] = eng.input.mustOperand(eng.n, "ExpBatch", b)
+	}
+	return r
+}
+
+// expBatch computes base^e mod N for many bases sharing one exponent e ≥ 0.
+//
+// The exponent is recoded into fixed-width windows once and the resulting
+// square-and-multiply schedule is replayed for every base in lockstep, in the
+// spirit of a vector addition chain. Only the per-base window tables are
+// private; the recoding and the control flow are paid for once per batch,
+// which is the common shape of batch verification and accumulator witness
+// updates.
+func expBatch(eng engine, bases []*big.Int, e *big.Int) []*big.Int {
+	w, group := planWindow(eng, len(bases), e)
+	digits := recodeFixedWindow(e, w)
+	auditTaint(digits, e)
+
+	results := make([]*big.Int, 0, len(bases))
+	for start := 0; start < len(bases); start += group {
+		end := min(start+group, len(bases))
+		results = append(results, expSchedule(eng, bases[start:end], digits, w)...)
+	}
+	return results
+}
+
+// planWindow picks the window width w and how many of the n bases are
+// processed together so that their tables fit in the memory budget.
+//
+// Under a budget the window shrinks first, since the group size only costs
+// memory locality while the window costs multiplies; then the group shrinks,
+// down to one base at a time with a 1-bit window.
+func planWindow(eng engine, n int, e *big.Int) (w, group int) {
+	w = windowSize(e.BitLen())
+	if eng.window > 0 {
+		w = eng.window
+	} else if isSparseExponent(e) {
+		// A 1-bit window degenerates to plain square-and-multiply and
+		// needs no table beyond the base itself.
+		w = 1
+	}
+
+	group = n
+	if eng.maxEntries > 0 {
+		for w > 1 && n<<w > eng.maxEntries {
+			w--
+		}
+		group = max(min(n, eng.maxEntries>>w), 1)
+	}
+	return w, group
+}
+
+// expSchedule runs one recoded fixed-window schedule for a group of bases.
+func expSchedule(eng engine, bases []*big.Int, digits []uint, w int) []*big.Int {
+	redc := eng.redc
+
+	// Montgomery form of 1: 1 * R mod N
+	oneMont := redc(big.NewInt(1), eng.rr)
+
+	// Per-base tables of base^0 .. base^(2^w - 1) in Montgomery form
+	tables := make([][]*big.Int, len(bases))
+	for j, base := range bases {
+		table := make([]*big.Int, 1<<w)
+		table[0] = oneMont
+		table[1] = redc(base, eng.rr)
+		for i := 2; i < len(table); i++ {
+			table[i] = redc(table[i-1], table[1])
+		}
+		tables[j] = table
+	}
+
+	// Each accumulator is its own big.Int, updated in place through
+	// redcInto so that its storage is reused across the whole schedule.
+	acc := make([]*big.Int, len(bases))
+	for j := range acc {
+		acc[j] = new(big.Int).Set(oneMont)
+	}
+
+	// Shared schedule: every accumulator sees the same squarings and the
+	// same window digits, only the table it multiplies from differs.
+	for i, d := range digits {
+		for j := range acc {
+			if i > 0 {
+				for range w {
+					acc[j] = eng.sqr(acc[j], acc[j])
+				}
+			}
+			auditBranch("expSchedule: zero digit", digits)
+			if d != 0 {
+				acc[j] = eng.redcInto(acc[j], acc[j], tables[j][d]) // multiply
+			}
+		}
+	}
+
+	// Convert back from Montgomery form
+	one := big.NewInt(1)
+	for j := range acc {
+		acc[j] = eng.redcInto(acc[j], acc[j], one)
+	}
+	return acc
+}
+
+// expMont computes base^e mod N for e ≥ 0 using Montgomery multiplication.
+// This demonstrates Montgomery's amortized advantage: conversion cost
+// is paid once at start/end, while many multiplications happen efficiently.
+//
+// Sparse exponents, which include the RSA public exponents 3 and 65537, go
+// through expSparse and skip window precomputation entirely, unless
+// WithWindowSize fixed the window; everything else uses a cached table
+// under WithTableCache, else the fixed-window schedule of expBatch, or
+// expSliding under WindowSliding.
+func expMont(eng engine, base, e *big.Int) *big.Int {
+	if eng.window == 0 && isSparseExponent(e) {
+		return expSparse(eng, base, e)
+	}
+	if eng.tables != nil {
+		return eng.tables.exp(eng, base, e)
+	}
+	if eng.strategy == WindowSliding {
+		return expSliding(eng, base, e)
+	}
+	return expBatch(eng, []*big.Int{base}, e)[0]
+}
+
+// expSparse computes base^e mod N by left-to-right square-and-multiply.
+//
+// It starts from the base itself rather than from 1, so e = 2 costs a single
+// squaring, e = 3 a squaring and a multiply, and e = 65537 sixteen squarings
+// and one multiply, plus the two domain conversions.
+func expSparse(eng engine, base, e *big.Int) *big.Int {
+	redc, rr := eng.redc, eng.rr
+	one := big.NewInt(1)
+	if e.Sign() == 0 {
+		// Montgomery form of 1 converted straight back: 1 mod N
+		return redc(redc(one, rr), one)
+	}
+
+	// Convert base to Montgomery form (1 conversion)
+	baseMont := redc(base, rr)
+
+	// result is updated in place and must not share storage with baseMont
+	result := new(big.Int).Set(baseMont)
+	for i := e.BitLen() - 2; i >= 0; i-- {
+		result = eng.sqr(result, result)
+		if e.Bit(i) == 1 {
+			result = eng.redcInto(result, result, baseMont) // multiply
+		}
+	}
+
+	// Convert back from Montgomery form (1 conversion)
+	return eng.redcInto(result, result, one)
+}
+
+// isSparseExponent reports whether plain square-and-multiply needs no more
+// multiplies than the fixed-window method, counting the 2^w - 2 multiplies
+// the window table costs to build.
+func isSparseExponent(e *big.Int) bool {
+	n := e.BitLen()
+	w := windowSize(n)
+	windowMuls := (1 << w) - 2 + (n+w-1)/w
+	return hammingWeight(e) <= windowMuls
+}
+
+// hammingWeight returns the number of set bits in |e|.
+func hammingWeight(e *big.Int) int {
+	weight := 0
+	for _, word := range e.Bits() {
+		weight += bits.OnesCount(uint(word))
+	}
+	return weight
+}
+
+// windowSize picks the fixed window width for an exponent of the given bit
+// length, balancing the 2^w table cost against the number of multiplies.
+func windowSize(bitLen int) int {
+	switch {
+	case bitLen > 768:
+		return 5
+	case bitLen > 256:
+		return 4
+	case bitLen > 64:
+		return 3
+	case bitLen > 16:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// recodeFixedWindow splits e into w-bit digits, most significant first.
+// The zero exponent is recoded as an empty digit string.
+func recodeFixedWindow(e *big.Int, w int) []uint {
+	n := (e.BitLen() + w - 1) / w
+	digits := make([]uint, n)
+	for i := range n {
+		var d uint
+		for b := w - 1; b >= 0; b-- {
+			d = d<<1 | e.Bit(i*w+b)
+		}
+		digits[n-1-i] = d
+	}
+	return digits
+}
@@ -0,0 +1,352 @@
+package montgomery
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestExpBatch(t *testing.T) {
+	t.Parallel()
+
+	x2048, y2048, R2048, N2048 := testParams2048()
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+	R64 := new(big.Int).Lsh(big.NewInt(1), 64)
+
+	tests := []struct {
+		name  string
+		bases []*big.Int
+		exp   *big.Int
+		R     *big.Int
+		N     *big.Int
+	}{
+		{
+			name:  "small bases",
+			bases: []*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(7), big.NewInt(12345)},
+			exp:   big.NewInt(65537),
+			R:     R64,
+			N:     N64,
+		},
+		{
+			name:  "exp=0",
+			bases: []*big.Int{big.NewInt(0), big.NewInt(5)},
+			exp:   big.NewInt(0),
+			R:     R64,
+			N:     N64,
+		},
+		{
+			name:  "exp=1",
+			bases: []*big.Int{big.NewInt(0), big.NewInt(5), new(big.Int).Sub(N64, big.NewInt(1))},
+			exp:   big.NewInt(1),
+			R:     R64,
+			N:     N64,
+		},
+		{
+			name:  "wide exponent",
+			bases: []*big.Int{big.NewInt(3), big.NewInt(0x123456789abcdef)},
+			exp:   new(big.Int).Sub(N64, big.NewInt(2)),
+			R:     R64,
+			N:     N64,
+		},
+		{
+			name:  "empty batch",
+			bases: nil,
+			exp:   big.NewInt(3),
+			R:     R64,
+			N:     N64,
+		},
+		{
+			name:  "2048-bit cryptographic scale",
+			bases: []*big.Int{x2048, y2048},
+			exp:   big.NewInt(0xdeadbeef),
+			R:     R2048,
+			N:     N2048,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			check := func(t *testing.T, got []*big.Int) {
+				t.Helper()
+				if len(got) != len(tc.bases) {
+					t.Fatalf("got %d results, want %d", len(got), len(tc.bases))
+				}
+				for i, base := range tc.bases {
+					want := new(big.Int).Exp(base, tc.exp, tc.N)
+					if got[i].Cmp(want) != 0 {
+						t.Errorf("base[%d]: got %v, want %v", i, got[i], want)
+					}
+				}
+			}
+
+			t.Run("Bitwise", func(t *testing.T) {
+				t.Parallel()
+				m := NewMontgomeryBitwise(tc.R, tc.N)
+				check(t, m.ExpBatch(tc.bases, tc.exp))
+			})
+
+			t.Run("CIOS", func(t *testing.T) {
+				t.Parallel()
+				m := NewMontgomeryCIOS(tc.R, tc.N)
+				check(t, m.ExpBatch(tc.bases, tc.exp))
+			})
+
+			t.Run("CIOSWords", func(t *testing.T) {
+				t.Parallel()
+				m := NewMontgomeryCIOSWords(tc.R, tc.N)
+				check(t, m.ExpBatch(tc.bases, tc.exp))
+			})
+		})
+	}
+}
+
+func TestExpSparse(t *testing.T) {
+	t.Parallel()
+
+	x2048, _, R2048, N2048 := testParams2048()
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+	R64 := new(big.Int).Lsh(big.NewInt(1), 64)
+
+	lowWeight := new(big.Int).Lsh(big.NewInt(1), 2047)
+	lowWeight.SetBit(lowWeight, 1024, 1).SetBit(lowWeight, 0, 1)
+
+	tests := []struct {
+		name string
+		base *big.Int
+		exp  *big.Int
+		R    *big.Int
+		N    *big.Int
+	}{
+		{"e=2", big.NewInt(12345), big.NewInt(2), R64, N64},
+		{"e=3", big.NewInt(12345), big.NewInt(3), R64, N64},
+		{"e=65537", big.NewInt(12345), big.NewInt(65537), R64, N64},
+		{"e=65537 2048-bit", x2048, big.NewInt(65537), R2048, N2048},
+		{"e=3 2048-bit", x2048, big.NewInt(3), R2048, N2048},
+		{"low Hamming weight 2048-bit exponent", x2048, lowWeight, R2048, N2048},
+		{"base=0", big.NewInt(0), big.NewInt(65537), R64, N64},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if !isSparseExponent(tc.exp) {
+				t.Fatalf("isSparseExponent(%v) = false; want true", tc.exp)
+			}
+			want := new(big.Int).Exp(tc.base, tc.exp, tc.N)
+
+			t.Run("Bitwise", func(t *testing.T) {
+				t.Parallel()
+				m := NewMontgomeryBitwise(tc.R, tc.N)
+				if got := m.modExp(tc.base, tc.exp); got.Cmp(want) != 0 {
+					t.Errorf("got %v, want %v", got, want)
+				}
+			})
+
+			t.Run("CIOS", func(t *testing.T) {
+				t.Parallel()
+				m := NewMontgomeryCIOS(tc.R, tc.N)
+				if got := m.modExp(tc.base, tc.exp); got.Cmp(want) != 0 {
+					t.Errorf("got %v, want %v", got, want)
+				}
+			})
+
+			t.Run("CIOSWords", func(t *testing.T) {
+				t.Parallel()
+				m := NewMontgomeryCIOSWords(tc.R, tc.N)
+				if got := m.modExp(tc.base, tc.exp); got.Cmp(want) != 0 {
+					t.Errorf("got %v, want %v", got, want)
+				}
+			})
+		})
+	}
+}
+
+func Test_isSparseExponent(t *testing.T) {
+	t.Parallel()
+
+	_, _, _, N := testParams2048()
+	dense := new(big.Int).Sub(N, big.NewInt(1))
+
+	tests := []struct {
+		name string
+		e    *big.Int
+		want bool
+	}{
+		{"zero", big.NewInt(0), true},
+		{"2", big.NewInt(2), true},
+		{"3", big.NewInt(3), true},
+		{"65537", big.NewInt(65537), true},
+		{"2^2048 - 1", new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 2048), big.NewInt(1)), false},
+		{"dense 2048-bit", dense, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := isSparseExponent(tc.e); got != tc.want {
+				t.Errorf("isSparseExponent(%v) = %v; want %v", tc.e, got, tc.want)
+			}
+		})
+	}
+}
+
+func Test_recodeFixedWindow(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		e    int64
+		w    int
+		want []uint
+	}{
+		{"zero", 0, 3, []uint{}},
+		{"single window", 5, 3, []uint{5}},
+		{"0b110_101", 0b110101, 3, []uint{6, 5}},
+		{"partial top window", 0b1_0000, 2, []uint{1, 0, 0}},
+		{"w=1 is binary", 0b1011, 1, []uint{1, 0, 1, 1}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got := recodeFixedWindow(big.NewInt(tc.e), tc.w)
+			if len(got) != len(tc.want) {
+				t.Fatalf("recodeFixedWindow(%d, %d) = %v; want %v", tc.e, tc.w, got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("recodeFixedWindow(%d, %d) = %v; want %v", tc.e, tc.w, got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestExp(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	w := NewMontgomeryCIOSWords(R, N)
+	impls := []struct {
+		name string
+		exp  func(base, exp *big.Int) *big.Int
+	}{
+		{"Bitwise", NewMontgomeryBitwise(R, N).Exp},
+		{"CIOS", NewMontgomeryCIOS(R, N).Exp},
+		{"CIOSWords", w.Exp},
+		// The exponentiations that scan the exponent's limbs
+		{"ExpConstantTime", w.ExpConstantTime},
+		{"ExpLadder", w.ExpLadder},
+		{"CT", NewMontgomeryCT(R, N).Exp},
+		{"ExpDual", func(base, exp *big.Int) *big.Int { _, r := ExpDual(w, w, x, y, base, exp); return r }},
+		{"ExpBatch", func(base, exp *big.Int) *big.Int { return w.ExpBatch([]*big.Int{x, base}, exp)[1] }},
+		{"FixedBase", func(base, exp *big.Int) *big.Int { return w.NewFixedBase(base, 2048, 4).Exp(exp) }},
+	}
+	tests := []struct {
+		name      string
+		base, exp *big.Int
+	}{
+		{"Fermat inverse", x, new(big.Int).Sub(N, big.NewInt(2))},
+		{"zero exponent", x, big.NewInt(0)},
+		{"public exponent", y, big.NewInt(65537)},
+		{"negative base", new(big.Int).Neg(x), big.NewInt(65537)},
+		{"negative base, full exponent", new(big.Int).Neg(y), x},
+		{"base above N", new(big.Int).Add(x, N), y},
+		{"base far above N", new(big.Int).Lsh(x, 3000), big.NewInt(3)},
+		{"negative exponent", x, big.NewInt(-65537)},
+		{"negative exponent, negative base", new(big.Int).Neg(y), new(big.Int).Neg(x)},
+		{"odd negative exponent, negative base", new(big.Int).Neg(y), big.NewInt(-65537)},
+		{"zero base", big.NewInt(0), y},
+		{"non-invertible base", new(big.Int).Set(N), big.NewInt(-1)},
+	}
+	for _, impl := range impls {
+		for _, tc := range tests {
+			t.Run(impl.name+"/"+tc.name, func(t *testing.T) {
+				t.Parallel()
+				// big.Int.Exp drops the sign of a negative base for an odd
+				// negative exponent, so the base is reduced first
+				want := new(big.Int).Exp(new(big.Int).Mod(tc.base, N), tc.exp, N)
+				got := impl.exp(tc.base, tc.exp)
+				if (got == nil) != (want == nil) || (got != nil && got.Cmp(want) != 0) {
+					t.Errorf("Exp() = %v, want %v", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestExpBatch_unreducedBases(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	bases := []*big.Int{new(big.Int).Neg(x), new(big.Int).Add(y, N), big.NewInt(-1)}
+	e := big.NewInt(1<<20 + 12345)
+	for _, m := range []interface {
+		ExpBatch([]*big.Int, *big.Int) []*big.Int
+	}{NewMontgomeryBitwise(R, N), NewMontgomeryCIOS(R, N), NewMontgomeryCIOSWords(R, N)} {
+		for i, got := range m.ExpBatch(bases, e) {
+			if want := new(big.Int).Exp(bases[i], e, N); got.Cmp(want) != 0 {
+				t.Errorf("%T.ExpBatch()[%d] = %v, want %v", m, i, got, want)
+			}
+		}
+		// A negative exponent inverts every base, and a base without an
+		// inverse yields nil without spoiling the others
+		neg := new(big.Int).Neg(e)
+		for i, got := range m.ExpBatch(append(bases, new(big.Int).Set(N)), neg) {
+			if i == len(bases) {
+				if got != nil {
+					t.Errorf("%T.ExpBatch(N, -e) = %v, want nil", m, got)
+				}
+				continue
+			}
+			if want := new(big.Int).Exp(new(big.Int).Mod(bases[i], N), neg, N); got.Cmp(want) != 0 {
+				t.Errorf("%T.ExpBatch(-e)[%d] = %v, want %v", m, i, got, want)
+			}
+		}
+	}
+}
+
+// BenchmarkExpBatch compares the shared-schedule batch against one modExp per base.
+func BenchmarkExpBatch(b *testing.B) {
+	x, y, R, N := testParams2048()
+	bases := []*big.Int{x, y, new(big.Int).Add(x, y), new(big.Int).Sub(x, y)}
+	exp := new(big.Int).Lsh(big.NewInt(1), 255)
+	exp.Sub(exp, big.NewInt(19))
+
+	b.Run("ExpBatch", func(b *testing.B) {
+		m := NewMontgomeryCIOSWords(R, N)
+		for b.Loop() {
+			m.ExpBatch(bases, exp)
+		}
+	})
+
+	b.Run("modExp", func(b *testing.B) {
+		m := NewMontgomeryCIOSWords(R, N)
+		for b.Loop() {
+			for _, base := range bases {
+				m.modExp(base, exp)
+			}
+		}
+	})
+}
+
+// BenchmarkExpPublic measures the RSA public-exponent fast path.
+func BenchmarkExpPublic(b *testing.B) {
+	base, _, R, N := testParams2048()
+	e := big.NewInt(65537)
+
+	b.Run("Montgomery/CIOSWords", func(b *testing.B) {
+		m := NewMontgomeryCIOSWords(R, N)
+		for b.Loop() {
+			m.modExp(base, e)
+		}
+	})
+
+	b.Run("BigInt/Exp", func(b *testing.B) {
+		for b.Loop() {
+			new(big.Int).Exp(base, e, N)
+		}
+	})
+}
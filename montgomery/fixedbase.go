@@ -0,0 +1,205 @@
+package montgomery
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// FixedBase is a precomputed table for exponentiating one fixed base g, such
+// as a Diffie-Hellman generator, with exponents of up to MaxBits bits.
+//
+// The exponent is split into w-bit windows and row i of the table holds
+// g^(d * 2^(w*i)) for every digit d, so Exp needs one multiplication per
+// window and no squarings at all. Building the table costs about as much as
+// MaxBits/w * 2^w multiplications; MarshalBinary lets a service ship it
+// instead of recomputing it at startup.
+type FixedBase struct {
+	m       *MontgomeryCIOSWords
+	g       *big.Int
+	w       int
+	maxBits int
+	table   []uint64 // windows * 2^w entries of S words, in Montgomery form
+	n, one  []uint64 // N and 1 as S limbs, kept so Exp allocates only its result
+}
+
+// NewFixedBase precomputes the table for base g, exponents up to maxBits
+// bits and window width w in [1, 8].
+func (m *MontgomeryCIOSWords) NewFixedBase(g *big.Int, maxBits, w int) *FixedBase {
+	if w < 1 || w > 8 {
+		panic(fmt.Sprintf("montgomery: fixed-base window %d out of range [1, 8]", w))
+	}
+	if maxBits < 1 {
+		panic(fmt.Sprintf("montgomery: fixed-base exponent size %d must be positive", maxBits))
+	}
+	g = new(big.Int).Set(m.cfg.input.mustOperand(m.N, "NewFixedBase", g))
+
+	s := m.S
+	n := limbsPadded(m.N, s)
+	t := make([]uint64, s+2)
+	windows := (maxBits + w - 1) / w
+	size := 1 << w
+	table := make([]uint64, windows*size*s)
+
+	one := make([]uint64, s)
+	montMulWords(one, limbsPadded(big.NewInt(1), s), limbsPadded(m.RR, s), n, m.NI, t)
+	// step = g^(2^(w*i)) in Montgomery form for the current row i
+	step := make([]uint64, s)
+	montMulWords(step, limbsPadded(g, s), limbsPadded(m.RR, s), n, m.NI, t)
+
+	for i := range windows {
+		row := table[i*size*s : (i+1)*size*s]
+		copy(row[:s], one)
+		copy(row[s:2*s], step)
+		for d := 2; d < size; d++ {
+			montMulWords(row[d*s:(d+1)*s], row[(d-1)*s:d*s], step, n, m.NI, t)
+		}
+		// Next row's step is step^(2^w) = row[size-1] * step
+		montMulWords(step, row[(size-1)*s:], step, n, m.NI, t)
+	}
+
+	return &FixedBase{m: m, g: g, w: w, maxBits: maxBits, table: table, n: n, one: limbsPadded(big.NewInt(1), s)}
+}
+
+// Base returns g reduced mod N.
+func (f *FixedBase) Base() *big.Int { return new(big.Int).Set(f.g) }
+
+// MaxBits returns the largest exponent size the table covers.
+func (f *FixedBase) MaxBits() int { return f.maxBits }
+
+// Exp returns g^e mod N. Exponents wider than MaxBits fall back to
+// ordinary Montgomery exponentiation. A negative e returns the inverse of
+// g^-e, or nil if g is not invertible, as big.Int.Exp does.
+func (f *FixedBase) Exp(e *big.Int) *big.Int {
+	if e.Sign() < 0 {
+		z := f.Exp(new(big.Int).Neg(e))
+		return z.ModInverse(z, f.m.N)
+	}
+	if e.BitLen() > f.maxBits {
+		return f.m.modExp(f.g, e)
+	}
+	m := f.m
+	s := m.S
+	n := f.n
+	t := make([]uint64, s+2)
+	size := 1 << f.w
+	windows := len(f.table) / (size * s)
+
+	acc := make([]uint64, s)
+	copy(acc, f.table[:s]) // row 0, digit 0: 1 in Montgomery form
+	mul := m.mulWordsKernel()
+	for i := range windows {
+		var d int
+		for b := f.w - 1; b >= 0; b-- {
+			d = d<<1 | int(e.Bit(i*f.w+b))
+		}
+		off := (i*size + d) * s
+		mul(acc, acc, f.table[off:off+s], n, m.NI, t)
+	}
+
+	// Convert back from Montgomery form, fully reduced
+	montMulWords(acc, acc, f.one, n, m.NI, t)
+	return tobigInt(acc)
+}
+
+// fixedBaseMagic and fixedBaseVersion identify the serialized table format:
+//
+//	magic    [4]byte "AVFB"
+//	version  uint8
+//	w        uint8
+//	maxBits  uint32
+//	S        uint32   words per element
+//	N        [S]uint64
+//	g        [S]uint64
+//	table    [windows * 2^w * S]uint64
+//
+// All integers are little-endian, and table entries are in Montgomery form
+// for R = 2^(64*S).
+const (
+	fixedBaseMagic   = "AVFB"
+	fixedBaseVersion = 1
+	fixedBaseHeader  = 4 + 1 + 1 + 4 + 4
+)
+
+var (
+	// ErrTableFormat is returned when serialized table data is malformed.
+	ErrTableFormat = errors.New("montgomery: malformed table data")
+	// ErrTableVersion is returned for table data in an unsupported format version.
+	ErrTableVersion = errors.New("montgomery: unsupported table version")
+)
+
+// MarshalBinary encodes the table in a versioned binary format.
+func (f *FixedBase) MarshalBinary() ([]byte, error) {
+	s := f.m.S
+	buf := make([]byte, 0, fixedBaseHeader+8*(2*s+len(f.table)))
+	buf = append(buf, fixedBaseMagic...)
+	buf = append(buf, fixedBaseVersion, byte(f.w))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(f.maxBits))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(s))
+	for _, x := range [][]uint64{limbsPadded(f.m.N, s), limbsPadded(f.g, s), f.table} {
+		for _, word := range x {
+			buf = binary.LittleEndian.AppendUint64(buf, word)
+		}
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a table produced by MarshalBinary, replacing f. The
+// Montgomery context is rebuilt from the stored modulus.
+//
+// The data is checked for consistency (sizes, an odd modulus, entries below
+// N, and that the first row matches g), but not recomputed in full; load
+// tables only from sources as trusted as the code itself.
+func (f *FixedBase) UnmarshalBinary(data []byte) error {
+	if len(data) < fixedBaseHeader || string(data[:4]) != fixedBaseMagic {
+		return ErrTableFormat
+	}
+	if data[4] != fixedBaseVersion {
+		return fmt.Errorf("%w: %d", ErrTableVersion, data[4])
+	}
+	w := int(data[5])
+	maxBits := int(binary.LittleEndian.Uint32(data[6:]))
+	s := int(binary.LittleEndian.Uint32(data[10:]))
+	if w < 1 || w > 8 || maxBits < 1 || s < 1 {
+		return ErrTableFormat
+	}
+	windows := (maxBits + w - 1) / w
+	entries := windows << w
+	body := data[fixedBaseHeader:]
+	// Compared by division so forged sizes cannot overflow
+	n := len(body) / 8
+	if len(body)%8 != 0 || n < 2*s || (n-2*s)%s != 0 || (n-2*s)/s != entries {
+		return ErrTableFormat
+	}
+
+	words := make([]uint64, n)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(body[8*i:])
+	}
+	N := tobigInt(words[:s])
+	g := tobigInt(words[s : 2*s])
+	table := words[2*s:]
+	if N.Bit(0) == 0 || N.Cmp(big.NewInt(1)) <= 0 || g.Cmp(N) >= 0 {
+		return ErrTableFormat
+	}
+	for i := range entries {
+		if tobigInt(table[i*s:(i+1)*s]).Cmp(N) >= 0 {
+			return ErrTableFormat
+		}
+	}
+
+	R := new(big.Int).Lsh(big.NewInt(1), uint(64*s))
+	m := NewMontgomeryCIOSWords(R, N)
+	gMont := new(big.Int).Lsh(g, uint(64*s))
+	gMont.Mod(gMont, N)
+	if tobigInt(table[:s]).Cmp(m.r1) != 0 || tobigInt(table[s:2*s]).Cmp(gMont) != 0 {
+		return ErrTableFormat
+	}
+
+	*f = FixedBase{
+		m: m, g: g, w: w, maxBits: maxBits, table: table,
+		n: limbsPadded(N, s), one: limbsPadded(big.NewInt(1), s),
+	}
+	return nil
+}
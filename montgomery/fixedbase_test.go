@@ -0,0 +1,172 @@
+package montgomery
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestFixedBase_Exp(t *testing.T) {
+	t.Parallel()
+
+	x, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+	m64 := NewMontgomeryCIOSWords(new(big.Int).Lsh(big.NewInt(1), 64), N64)
+
+	tests := []struct {
+		name    string
+		m       *MontgomeryCIOSWords
+		g       *big.Int
+		maxBits int
+		w       int
+		e       *big.Int
+	}{
+		{"exp=0", m64, big.NewInt(5), 64, 4, big.NewInt(0)},
+		{"exp=1", m64, big.NewInt(5), 64, 4, big.NewInt(1)},
+		{"w=1", m64, big.NewInt(5), 64, 1, big.NewInt(65537)},
+		{"w does not divide maxBits", m64, big.NewInt(5), 64, 5, new(big.Int).SetUint64(1<<64 - 1)},
+		{"g >= N", m64, new(big.Int).Add(N64, big.NewInt(7)), 64, 4, big.NewInt(12345)},
+		{"exponent wider than table", m64, big.NewInt(5), 16, 4, big.NewInt(1 << 20)},
+		{"2048-bit, 256-bit exponent", m, x, 256, 6, new(big.Int).Rsh(N, 1792)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			f := tc.m.NewFixedBase(tc.g, tc.maxBits, tc.w)
+			want := new(big.Int).Exp(tc.g, tc.e, tc.m.N)
+			if got := f.Exp(tc.e); got.Cmp(want) != 0 {
+				t.Errorf("Exp() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestFixedBase_ExpProperty(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	g := big.NewInt(2)
+	f := m.NewFixedBase(g, 256, 4)
+
+	err := quick.Check(func(eBytes [32]byte) bool {
+		e := new(big.Int).SetBytes(eBytes[:])
+		return f.Exp(e).Cmp(new(big.Int).Exp(g, e, N)) == 0
+	}, &quick.Config{MaxCount: 20})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFixedBase_MarshalBinary(t *testing.T) {
+	t.Parallel()
+
+	x, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	f := m.NewFixedBase(x, 160, 5)
+
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded FixedBase
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+	if loaded.Base().Cmp(f.Base()) != 0 || loaded.MaxBits() != 160 {
+		t.Errorf("loaded table for g=%v maxBits=%d", loaded.Base(), loaded.MaxBits())
+	}
+	e := new(big.Int).Rsh(N, 2048-160)
+	if got, want := loaded.Exp(e), f.Exp(e); got.Cmp(want) != 0 {
+		t.Errorf("loaded Exp() = %v, want %v", got, want)
+	}
+	again, _ := loaded.MarshalBinary()
+	if !bytes.Equal(again, data) {
+		t.Error("re-marshalled table differs from the original encoding")
+	}
+}
+
+func TestFixedBase_UnmarshalBinaryErrors(t *testing.T) {
+	t.Parallel()
+
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+	m := NewMontgomeryCIOSWords(new(big.Int).Lsh(big.NewInt(1), 64), N64)
+	data, _ := m.NewFixedBase(big.NewInt(5), 16, 4).MarshalBinary()
+
+	modify := func(f func(b []byte) []byte) []byte {
+		return f(bytes.Clone(data))
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"empty", nil, ErrTableFormat},
+		{"bad magic", modify(func(b []byte) []byte { b[0] = 'X'; return b }), ErrTableFormat},
+		{"future version", modify(func(b []byte) []byte { b[4] = 2; return b }), ErrTableVersion},
+		{"window 0", modify(func(b []byte) []byte { b[5] = 0; return b }), ErrTableFormat},
+		{"truncated", data[:len(data)-8], ErrTableFormat},
+		{"trailing byte", append(bytes.Clone(data), 0), ErrTableFormat},
+		{"huge sizes", modify(func(b []byte) []byte {
+			binary.LittleEndian.PutUint32(b[6:], 1<<32-1)
+			binary.LittleEndian.PutUint32(b[10:], 1<<32-1)
+			return b
+		}), ErrTableFormat},
+		{"even modulus", modify(func(b []byte) []byte { b[fixedBaseHeader] ^= 1; return b }), ErrTableFormat},
+		{"entry not below N", modify(func(b []byte) []byte {
+			binary.LittleEndian.PutUint64(b[len(b)-8:], 1<<64-1)
+			return b
+		}), ErrTableFormat},
+		{"first row does not match g", modify(func(b []byte) []byte {
+			binary.LittleEndian.PutUint64(b[fixedBaseHeader+8:], 6)
+			return b
+		}), ErrTableFormat},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var f FixedBase
+			if err := f.UnmarshalBinary(tc.data); !errors.Is(err, tc.wantErr) {
+				t.Errorf("UnmarshalBinary() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func BenchmarkFixedBase(b *testing.B) {
+	x, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	e := new(big.Int).Rsh(N, 2048-256)
+	f := m.NewFixedBase(x, 256, 6)
+	data, _ := f.MarshalBinary()
+
+	b.Run("Exp", func(b *testing.B) {
+		for b.Loop() {
+			f.Exp(e)
+		}
+	})
+	b.Run("ExpConstantTime", func(b *testing.B) {
+		for b.Loop() {
+			m.ExpConstantTime(x, e)
+		}
+	})
+	b.Run("NewFixedBase", func(b *testing.B) {
+		for b.Loop() {
+			m.NewFixedBase(x, 256, 6)
+		}
+	})
+	b.Run("UnmarshalBinary", func(b *testing.B) {
+		for b.Loop() {
+			var g FixedBase
+			if err := g.UnmarshalBinary(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package montgomery
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// ErrNoPrime is returned by GenerateFriendlyPrime when no candidate of the
+// requested form passes the primality test within its attempt limit.
+var ErrNoPrime = errors.New("montgomery: no prime of the requested form found")
+
+// PrimalityTest reports whether n is prime, or an error if it could not
+// decide. The Miller-Rabin test of the rabin module fits as
+//
+//	func(n *big.Int) (bool, error) { return rabin.ProbablyPrime(rand.Reader, n, 20) }
+type PrimalityTest func(n *big.Int) (bool, error)
+
+// FriendlySpec describes the primes GenerateFriendlyPrime searches for:
+// odd primes of exactly Bits bits whose Montgomery constant makes the REDC
+// quotient digit cheap. Exactly one of MinusOneWords and NIWeight must be
+// positive.
+type FriendlySpec struct {
+	Bits int
+
+	// MinusOneWords asks for N ≡ -1 mod 2^(64·MinusOneWords): the low
+	// words of N are all ones, so NI is 1, the quotient digit is the low
+	// word of the accumulator itself, and the full-width -N⁻¹ mod R of
+	// separated REDC is 1 in those words as well.
+	MinusOneWords int
+	// NIWeight asks for NI = -N⁻¹ mod 2^64 with at most NIWeight bits set,
+	// so that the quotient digit t·NI is that many shifts and adds. It
+	// leaves more freedom in the low word of N than MinusOneWords.
+	NIWeight int
+
+	// Test decides primality; nil means big.Int.ProbablyPrime(20).
+	Test PrimalityTest
+}
+
+// friendlyAttempts is how many candidates per bit of N GenerateFriendlyPrime
+// tries. About one in Bits·ln(2)/2 odd candidates is prime, so the chance
+// of running out for a form that has primes at all is below e^-90.
+const friendlyAttempts = 64
+
+// sieveProduct is 3·5·7·…·47, the odd primes whose product fits in a word:
+// one reduction modulo it rules out most candidates before the primality
+// test.
+const sieveProduct = 3 * 5 * 7 * 11 * 13 * 17 * 19 * 23 * 29 * 31 * 37 * 41 * 43 * 47
+
+// GenerateFriendlyPrime returns a random prime of the form spec describes,
+// for protocols that choose their own moduli and want the Montgomery
+// reduction to be as cheap as possible; AnalyzeModulus reports such an N
+// as MontgomeryFriendly when NI is 1. Randomness is read from random, or
+// crypto/rand.Reader when random is nil.
+//
+// Candidates fix the top bit and the low words the form requires, draw
+// the bits in between, and are screened by trial division before
+// spec.Test runs. It returns an error wrapping ErrInvalidParameters if the
+// form leaves no bits to draw, ErrNoPrime if 64·Bits candidates are all
+// composite, and the error of the random source or of spec.Test otherwise.
+func GenerateFriendlyPrime(random io.Reader, spec FriendlySpec) (*big.Int, error) {
+	return GenerateFriendlyPrimeContext(context.Background(), random, spec)
+}
+
+// GenerateFriendlyPrimeContext is GenerateFriendlyPrime with cancellation,
+// for large Bits or a slow spec.Test. ctx is checked once per candidate,
+// and a cancelled search returns ctx.Err().
+func GenerateFriendlyPrimeContext(ctx context.Context, random io.Reader, spec FriendlySpec) (*big.Int, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	if (spec.MinusOneWords > 0) == (spec.NIWeight > 0) {
+		return nil, fmt.Errorf("%w: exactly one of MinusOneWords = %d and NIWeight = %d must be positive", ErrInvalidParameters, spec.MinusOneWords, spec.NIWeight)
+	}
+	low := 64 * max(spec.MinusOneWords, 1) // bits fixed by the form
+	if spec.Bits <= low {
+		return nil, fmt.Errorf("%w: %d-bit primes have no room above %d fixed low bits", ErrInvalidParameters, spec.Bits, low)
+	}
+	test := spec.Test
+	if test == nil {
+		test = func(n *big.Int) (bool, error) { return n.ProbablyPrime(20), nil }
+	}
+
+	top := new(big.Int).Lsh(big.NewInt(1), uint(spec.Bits-1))
+	span := new(big.Int).Lsh(big.NewInt(1), uint(spec.Bits-1-low))
+	minusOne := new(big.Int).Lsh(big.NewInt(1), uint(low))
+	minusOne.Sub(minusOne, big.NewInt(1))
+	prod := new(big.Int).SetUint64(sieveProduct)
+	n, r := new(big.Int), new(big.Int)
+	for range friendlyAttempts * spec.Bits {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		m, err := rand.Int(random, span)
+		if err != nil {
+			return nil, err
+		}
+		n.Lsh(m, uint(low)).Add(n, top)
+		if spec.MinusOneWords > 0 {
+			n.Add(n, minusOne)
+		} else {
+			ni, err := lowWeightWord(random, spec.NIWeight)
+			if err != nil {
+				return nil, err
+			}
+			n.Add(n, new(big.Int).SetUint64(wordInverse(ni, 64)))
+		}
+		if !sieved(r.Mod(n, prod).Uint64()) {
+			continue
+		}
+		ok, err := test(n)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return new(big.Int).Set(n), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %d candidates of %d bits tried", ErrNoPrime, friendlyAttempts*spec.Bits, spec.Bits)
+}
+
+// lowWeightWord returns a random odd word with at most weight bits set:
+// bit 0 and up to weight-1 others at random positions.
+func lowWeightWord(random io.Reader, weight int) (uint64, error) {
+	pos := make([]byte, min(weight, 64)-1)
+	if _, err := io.ReadFull(random, pos); err != nil {
+		return 0, err
+	}
+	w := uint64(1)
+	for _, p := range pos {
+		w |= 1 << (p & 63)
+	}
+	return w, nil
+}
+
+// sieved reports whether r, a candidate modulo sieveProduct, has none of
+// its primes as a factor.
+func sieved(r uint64) bool {
+	for _, p := range []uint64{3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47} {
+		if r%p == 0 {
+			return false
+		}
+	}
+	return true
+}
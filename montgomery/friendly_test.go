@@ -0,0 +1,148 @@
+package montgomery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	mrand "math/rand/v2"
+	"testing"
+)
+
+func TestGenerateFriendlyPrime(t *testing.T) {
+	t.Parallel()
+
+	tests := []FriendlySpec{
+		{Bits: 72, NIWeight: 1}, // NI = 1, as with MinusOneWords = 1
+		{Bits: 128, MinusOneWords: 1},
+		{Bits: 256, MinusOneWords: 1},
+		{Bits: 256, MinusOneWords: 3},
+		{Bits: 521, MinusOneWords: 2},
+		{Bits: 130, NIWeight: 2},
+		{Bits: 256, NIWeight: 3},
+		{Bits: 384, NIWeight: 8},
+		{Bits: 1024, MinusOneWords: 1},
+	}
+	for _, spec := range tests {
+		t.Run(fmt.Sprintf("bits=%d/words=%d/weight=%d", spec.Bits, spec.MinusOneWords, spec.NIWeight), func(t *testing.T) {
+			t.Parallel()
+			rng := mrand.NewChaCha8([32]byte{byte(spec.Bits), byte(spec.MinusOneWords), byte(spec.NIWeight)})
+			N, err := GenerateFriendlyPrime(rng, spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if N.BitLen() != spec.Bits || !N.ProbablyPrime(20) {
+				t.Fatalf("N = %x is not a %d-bit prime", N, spec.Bits)
+			}
+			r := AnalyzeModulus(N)
+			if spec.MinusOneWords > 0 {
+				np1 := new(big.Int).Add(N, big.NewInt(1))
+				if np1.TrailingZeroBits() < uint(64*spec.MinusOneWords) || !r.MontgomeryFriendly {
+					t.Errorf("N = %x is not -1 mod 2^%d", N, 64*spec.MinusOneWords)
+				}
+			} else if w := bits.OnesCount64(r.NI); w > spec.NIWeight {
+				t.Errorf("NI = %#x has weight %d, want at most %d", r.NI, w, spec.NIWeight)
+			}
+
+			// The prime works as a modulus
+			m, err := OpenFor(N)
+			if err != nil {
+				t.Fatal(err)
+			}
+			x := new(big.Int).Sub(N, big.NewInt(2))
+			if got := m.Mul(x, x); got.Cmp(big.NewInt(4)) != 0 {
+				t.Errorf("Mul(N-2, N-2) = %v, want 4", got)
+			}
+		})
+	}
+}
+
+func TestGenerateFriendlyPrime_test(t *testing.T) {
+	t.Parallel()
+
+	// The test decides, and only sieved candidates reach it
+	var calls int
+	spec := FriendlySpec{Bits: 192, MinusOneWords: 1, Test: func(n *big.Int) (bool, error) {
+		calls++
+		if new(big.Int).GCD(nil, nil, n, big.NewInt(3*5*7*11*13*17*19*23*29*31*37*41*43*47)).Cmp(big.NewInt(1)) != 0 {
+			t.Errorf("Test called on %v, which has a small factor", n)
+		}
+		return calls == 3, nil
+	}}
+	N, err := GenerateFriendlyPrime(mrand.NewChaCha8([32]byte{1}), spec)
+	if err != nil || calls != 3 || N.BitLen() != 192 {
+		t.Errorf("GenerateFriendlyPrime = %v, %v after %d calls, want a 192-bit N after 3", N, err, calls)
+	}
+
+	errTest := errors.New("test failed")
+	spec.Test = func(*big.Int) (bool, error) { return false, errTest }
+	if _, err := GenerateFriendlyPrime(nil, spec); !errors.Is(err, errTest) {
+		t.Errorf("GenerateFriendlyPrime: error = %v, want %v", err, errTest)
+	}
+	spec.Test = func(*big.Int) (bool, error) { return false, nil }
+	if _, err := GenerateFriendlyPrime(nil, spec); !errors.Is(err, ErrNoPrime) {
+		t.Errorf("GenerateFriendlyPrime: error = %v, want %v", err, ErrNoPrime)
+	}
+}
+
+func TestGenerateFriendlyPrimeContext(t *testing.T) {
+	t.Parallel()
+
+	// Cancelling from the test stops the search at the next candidate
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls int
+	spec := FriendlySpec{Bits: 192, MinusOneWords: 1, Test: func(*big.Int) (bool, error) {
+		calls++
+		cancel()
+		return false, nil
+	}}
+	if _, err := GenerateFriendlyPrimeContext(ctx, mrand.NewChaCha8([32]byte{2}), spec); !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("GenerateFriendlyPrimeContext: error = %v after %d calls, want %v after 1", err, calls, context.Canceled)
+	}
+}
+
+func TestGenerateFriendlyPrime_errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []FriendlySpec{
+		{Bits: 256},
+		{Bits: 256, MinusOneWords: 1, NIWeight: 2},
+		{Bits: 64, NIWeight: 1},
+		{Bits: 128, MinusOneWords: 2},
+		{Bits: -1, MinusOneWords: 1},
+	}
+	for _, spec := range tests {
+		if _, err := GenerateFriendlyPrime(nil, spec); !errors.Is(err, ErrInvalidParameters) {
+			t.Errorf("GenerateFriendlyPrime(%+v): error = %v, want %v", spec, err, ErrInvalidParameters)
+		}
+	}
+
+	// 2^65 - 1, a multiple of 31, is the only candidate
+	if _, err := GenerateFriendlyPrime(nil, FriendlySpec{Bits: 65, MinusOneWords: 1}); !errors.Is(err, ErrNoPrime) {
+		t.Errorf("GenerateFriendlyPrime(2^65 - 1): error = %v, want %v", err, ErrNoPrime)
+	}
+	if _, err := GenerateFriendlyPrime(failingReader{}, FriendlySpec{Bits: 128, NIWeight: 2}); !errors.Is(err, errNoEntropy) {
+		t.Errorf("GenerateFriendlyPrime with a failing random source: error = %v, want %v", err, errNoEntropy)
+	}
+}
+
+func BenchmarkGenerateFriendlyPrime(b *testing.B) {
+	for _, bits := range []int{256, 1024} {
+		for _, spec := range []FriendlySpec{{Bits: bits, MinusOneWords: 1}, {Bits: bits, NIWeight: 4}} {
+			name := "minusone"
+			if spec.NIWeight > 0 {
+				name = "lowweight"
+			}
+			b.Run(fmt.Sprintf("bits=%d/impl=%s", bits, name), func(b *testing.B) {
+				rng := mrand.NewChaCha8([32]byte{})
+				for b.Loop() {
+					if _, err := GenerateFriendlyPrime(rng, spec); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package montgomery
+
+import "math/big"
+
+// wordAlignedR returns the smallest 2^(64·s) greater than N. When opts
+// ask for WithAlmostMontgomery, it is the smallest one of at least 4N
+// instead, a word more for an N within two bits of a word boundary, so that
+// constructors choosing R themselves never defeat the option.
+func wordAlignedR(N *big.Int, opts []Option) *big.Int {
+	bits := N.BitLen()
+	if newConfig(opts).amm {
+		bits += 2
+	}
+	return new(big.Int).Lsh(big.NewInt(1), uint(64*((bits+63)/64)))
+}
+
+// validModulus reports whether N is odd and greater than 1.
+func validModulus(N *big.Int) bool {
+	return N != nil && N.Bit(0) == 1 && N.Cmp(big.NewInt(1)) > 0
+}
+
+// NewMontgomeryBitwiseFromModulus is NewMontgomeryBitwise with
+// R = 2^(64·⌈bitlen(N)/64⌉), the smallest whole number of 64-bit words
+// above N, derived from N so that it cannot be mismatched. With
+// WithAlmostMontgomery, R is the smallest such power of at least 4N. It returns
+// ErrInvalidParameters unless N is odd and greater than 1, and the failure
+// of a WithSelfTest as an error.
+func NewMontgomeryBitwiseFromModulus(N *big.Int, opts ...Option) (*MontgomeryBitwise, error) {
+	if !validModulus(N) {
+		return nil, ErrInvalidParameters
+	}
+	opts, cfg := withoutSelfTest(opts)
+	m := NewMontgomeryBitwise(wordAlignedR(N, opts), N, opts...)
+	if err := selfTest(m, cfg); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// NewMontgomeryCIOSFromModulus is NewMontgomeryCIOS with R derived from N
+// as in NewMontgomeryBitwiseFromModulus.
+func NewMontgomeryCIOSFromModulus(N *big.Int, opts ...Option) (*MontgomeryCIOS, error) {
+	if !validModulus(N) {
+		return nil, ErrInvalidParameters
+	}
+	opts, cfg := withoutSelfTest(opts)
+	m := NewMontgomeryCIOS(wordAlignedR(N, opts), N, opts...)
+	if err := selfTest(m, cfg); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// NewMontgomeryCIOSWordsFromModulus is NewMontgomeryCIOSWords with R
+// derived from N as in NewMontgomeryBitwiseFromModulus.
+func NewMontgomeryCIOSWordsFromModulus(N *big.Int, opts ...Option) (*MontgomeryCIOSWords, error) {
+	if !validModulus(N) {
+		return nil, ErrInvalidParameters
+	}
+	opts, cfg := withoutSelfTest(opts)
+	m := NewMontgomeryCIOSWords(wordAlignedR(N, opts), N, opts...)
+	if err := selfTest(m, cfg); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
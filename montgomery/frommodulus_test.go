@@ -0,0 +1,106 @@
+package montgomery
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestNewFromModulus(t *testing.T) {
+	t.Parallel()
+
+	_, _, _, N2048 := testParams2048()
+	tests := []struct {
+		name  string
+		N     *big.Int
+		words int
+	}{
+		{"3", big.NewInt(3), 1},
+		{"2^64-1", new(big.Int).SetUint64(1<<64 - 1), 1},
+		{"2^64+1", new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(1)), 2},
+		{"2^255-19", new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19)), 4},
+		{"2048-bit", N2048, 32},
+	}
+	for _, tc := range tests {
+		for _, c := range montFromModulus() {
+			t.Run(tc.name+"/"+c.name, func(t *testing.T) {
+				t.Parallel()
+				m, R, err := c.new(tc.N)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := new(big.Int).Lsh(big.NewInt(1), uint(64*tc.words)); R.Cmp(want) != 0 {
+					t.Errorf("R = 2^%d, want 2^%d", R.BitLen()-1, want.BitLen()-1)
+				}
+				x := new(big.Int).Sub(tc.N, big.NewInt(2))
+				y := new(big.Int).Rsh(tc.N, 1)
+				want := new(big.Int).Mul(x, y)
+				want.Mod(want, tc.N)
+				if got := m.Mul(x, y); got.Cmp(want) != 0 {
+					t.Errorf("Mul(%v, %v) = %v, want %v", x, y, got, want)
+				}
+			})
+		}
+	}
+
+	for _, N := range []*big.Int{nil, big.NewInt(-3), big.NewInt(0), big.NewInt(1), big.NewInt(10)} {
+		for _, c := range montFromModulus() {
+			if _, _, err := c.new(N); !errors.Is(err, ErrInvalidParameters) {
+				t.Errorf("%s(%v): error = %v, want %v", c.name, N, err, ErrInvalidParameters)
+			}
+		}
+	}
+}
+
+// TestWordAlignedR_almostMontgomery checks that the constructors choosing R
+// themselves leave room for AMM: P-256 fills its four words, so its 4N
+// needs a fifth.
+func TestWordAlignedR_almostMontgomery(t *testing.T) {
+	t.Parallel()
+
+	p, err := LookupParams("p256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	N := p.N
+	R256, R320 := new(big.Int).Lsh(big.NewInt(1), 256), new(big.Int).Lsh(big.NewInt(1), 320)
+	if got := wordAlignedR(N, nil); got.Cmp(R256) != 0 {
+		t.Errorf("wordAlignedR without AMM = 2^%d, want 2^256", got.BitLen()-1)
+	}
+	if got := wordAlignedR(N, []Option{WithAlmostMontgomery()}); got.Cmp(R320) != 0 {
+		t.Errorf("wordAlignedR with AMM = 2^%d, want 2^320", got.BitLen()-1)
+	}
+
+	x := new(big.Int).Sub(N, big.NewInt(3))
+	e := new(big.Int).Rsh(N, 1)
+	want := new(big.Int).Exp(x, e, N)
+
+	words, err := NewMontgomeryCIOSWordsFromModulus(N, WithAlmostMontgomery())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !words.amm {
+		t.Error("NewMontgomeryCIOSWordsFromModulus dropped AMM")
+	}
+	if got := words.ExpConstantTime(x, e); got.Cmp(want) != 0 {
+		t.Errorf("FromModulus: ExpConstantTime = %x, want %x", got, want)
+	}
+
+	ct, err := New(KindCT, N, WithAlmostMontgomery())
+	if err != nil {
+		t.Fatalf("New(KindCT): %v", err)
+	}
+	if c := ct.(*MontgomeryCT); !c.w.amm {
+		t.Error("New(KindCT) dropped AMM")
+	} else if got := c.Exp(x, e); got.Cmp(want) != 0 {
+		t.Errorf("New(KindCT): Exp = %x, want %x", got, want)
+	}
+	m, err := OpenFor(N, WithAlmostMontgomery())
+	if err != nil {
+		t.Fatalf("OpenFor: %v", err)
+	}
+	wantMul := new(big.Int).Mul(x, e)
+	if got := m.Mul(x, e); got.Cmp(wantMul.Mod(wantMul, N)) != 0 {
+		t.Errorf("OpenFor: Mul = %x, want %x", got, wantMul)
+	}
+}
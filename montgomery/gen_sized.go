@@ -0,0 +1,118 @@
+//go:build ignore
+
+// gen_sized writes sized.go, the fixed-size CIOS kernels. Run it with
+// go generate after changing sizedLimbs or the emitted code.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"strings"
+)
+
+// sizedLimbs are the limb counts that get a kernel: 256-, 384- and 512-bit
+// moduli.
+var sizedLimbs = []int{4, 6, 8}
+
+func main() {
+	var b bytes.Buffer
+	p := func(format string, args ...any) { fmt.Fprintf(&b, format+"\n", args...) }
+
+	p("// Code generated by gen_sized.go; DO NOT EDIT.")
+	p("")
+	p("package montgomery")
+	p("")
+	p(`import "math/bits"`)
+	p("")
+	p("// montMulSized is montMulWords for s = len(n) in %s limbs, on kernels", list(sizedLimbs))
+	p("// specialized to one size each, and reports whether s had one. t is")
+	p("// not needed: the accumulator lives in local variables.")
+	p("func montMulSized(z, x, y, n []uint64, ni uint64) bool {")
+	p("switch len(n) {")
+	for _, k := range sizedLimbs {
+		p("case %d:", k)
+		p("montMul%d((*[%[1]d]uint64)(z), (*[%[1]d]uint64)(x), (*[%[1]d]uint64)(y), (*[%[1]d]uint64)(n), ni)", k)
+		p("return true")
+	}
+	p("}")
+	p("return false")
+	p("}")
+
+	for _, k := range sizedLimbs {
+		emit(p, k)
+	}
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("sized.go", src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// emit writes montMul<k>: the rows of mulAccGo in a loop, each one's loop
+// over j unrolled, so the accumulator t0..t<k> and the limbs of x and N
+// need no bounds checks, then the masked subtraction of montMulWords.
+func emit(p func(string, ...any), k int) {
+	vars := func(name string, n int) string {
+		s := make([]string, n)
+		for i := range s {
+			s[i] = fmt.Sprintf("%s%d", name, i)
+		}
+		return strings.Join(s, ", ")
+	}
+
+	p("")
+	p("// montMul%d is montMulWords for %d limbs.", k, k)
+	p("func montMul%d(z, x, y, n *[%[1]d]uint64, ni uint64) {", k)
+	p("var %s uint64", vars("t", k+1))
+	p("for _, yi := range y {")
+	p("hi, lo := bits.Mul64(x[0], yi)")
+	p("lo, cc := bits.Add64(lo, t0, 0)")
+	p("c := hi + cc")
+	p("m := lo * ni")
+	p("hi, mlo := bits.Mul64(m, n[0])")
+	p("_, cc = bits.Add64(mlo, lo, 0)")
+	p("d := hi + cc")
+	for j := 1; j < k; j++ {
+		p("hi, lo = bits.Mul64(x[%d], yi)", j)
+		p("lo, cc = bits.Add64(lo, t%d, 0)", j)
+		p("hi += cc")
+		p("lo, cc = bits.Add64(lo, c, 0)")
+		p("c = hi + cc")
+		p("hi, mlo = bits.Mul64(m, n[%d])", j)
+		p("mlo, cc = bits.Add64(mlo, lo, 0)")
+		p("hi += cc")
+		p("t%d, cc = bits.Add64(mlo, d, 0)", j-1)
+		p("d = hi + cc")
+	}
+	p("var c1, c2 uint64")
+	p("t%d, c1 = bits.Add64(t%d, c, 0)", k-1, k)
+	p("t%d, c2 = bits.Add64(t%d, d, 0)", k-1, k-1)
+	p("t%d = c1 + c2", k)
+	p("}")
+	p("")
+	p("// t < 2N; subtract N when t ≥ N, selected by mask rather than branch")
+	p("var b uint64")
+	for j := range k {
+		p("u%d, b := bits.Sub64(t%[1]d, n[%[1]d], b)", j)
+	}
+	p("keep := ctMask(t%d | (b ^ 1))", k)
+	for j := range k {
+		p("z[%d] = u%[1]d&keep | t%[1]d&^keep", j)
+	}
+	p("}")
+}
+
+// list returns the sizes as "4, 6 or 8".
+func list(ks []int) string {
+	s := make([]string, len(ks))
+	for i, k := range ks {
+		s[i] = fmt.Sprint(k)
+	}
+	return strings.Join(s[:len(s)-1], ", ") + " or " + s[len(s)-1]
+}
@@ -0,0 +1,110 @@
+package montgomery
+
+import (
+	"math"
+	"math/bits"
+)
+
+// limbWord is the type of a limb: uint32 or uint64, or a type such as
+// big.Word whose width is the platform's.
+type limbWord interface {
+	~uint | ~uint32 | ~uint64
+}
+
+// montgomeryImpl is the CIOS kernel on limbs of type W: N as s limbs and
+// NI = -N⁻¹ mod 2^w, for the width w of W, which is NI mod 2^32 where W is
+// 32 bits. One code path serves both widths: MontgomeryCIOSWords runs it
+// on big.Words, the platform's native width, for its big.Int operations and
+// on uint64 for the []uint64 limb API, which keeps 64-bit limbs everywhere.
+//
+// The one copy has a price: against hand-specialized loops it measures up
+// to 8% slower on a single 2048-bit Mul on amd64, and 2-3% on Exp. On
+// amd64 CPUs with ADX and on arm64, the 64-bit instantiations run
+// mulAccADX (mulacc_amd64.s) or mulAccARM64 (mulacc_arm64.s) instead,
+// unless built with the purego tag.
+type montgomeryImpl[W limbWord] struct {
+	n  []W
+	ni W
+}
+
+// mulAcc leaves x·y·2^(-w·s) plus a multiple of N, below x·y/2^(w·s) + N,
+// in t[:s+1], for s = len(k.n). x must have s limbs and y at most s, the
+// missing ones being zero; t is at least s+1 limbs of scratch, aliasing none
+// of them. Beyond the length of y, the sequence of instructions and memory
+// accesses depends only on s.
+//
+// Each pass fuses the product and the reduction in one loop over j,
+// carrying c for x·y[i] and d for m·N: m depends only on the low limb of
+// t + x·y[i], which the loop computes first. Separate product and
+// reduction loops would load and store t twice; fusing them takes about
+// 20% off a 2048-bit Mul on amd64.
+func (k montgomeryImpl[W]) mulAcc(t, x, y []W) {
+	if mulAccAsm(t, x, y, k.n, k.ni) {
+		return
+	}
+	k.mulAccGo(t, x, y)
+}
+
+// mulAccGo is mulAcc in Go, for every platform and limb width.
+func (k montgomeryImpl[W]) mulAccGo(t, x, y []W) {
+	// Generic helpers would pass a dictionary into the loop; these
+	// closures inline, and w32 is constant in each instantiation.
+	w32 := uint64(^W(0)) == math.MaxUint32
+	mulW := func(x, y W) (hi, lo W) {
+		if w32 {
+			h, l := bits.Mul32(uint32(x), uint32(y))
+			return W(h), W(l)
+		}
+		h, l := bits.Mul64(uint64(x), uint64(y))
+		return W(h), W(l)
+	}
+	addW := func(x, y, carry W) (sum, carryOut W) {
+		if w32 {
+			s, c := bits.Add32(uint32(x), uint32(y), uint32(carry))
+			return W(s), W(c)
+		}
+		s, c := bits.Add64(uint64(x), uint64(y), uint64(carry))
+		return W(s), W(c)
+	}
+
+	n := k.n
+	s := len(n)
+	x, t = x[:s], t[:s+1] // bounds hints for the loops below
+	clear(t)
+
+	for i := range s {
+		var yi W
+		auditBranch("mulAcc: past the end of y")
+		if i < len(y) {
+			yi = y[i]
+		}
+		hi, lo := mulW(x[0], yi)
+		lo, cc := addW(lo, t[0], 0)
+		c := hi + cc
+		m := lo * k.ni
+		hi, mlo := mulW(m, n[0])
+		_, cc = addW(mlo, lo, 0)
+		d := hi + cc
+		for j := 1; j < s; j++ {
+			// u = t[j] + x[j]·y[i] + c
+			hi, lo = mulW(x[j], yi)
+			lo, cc = addW(lo, t[j], 0)
+			hi += cc
+			lo, cc = addW(lo, c, 0)
+			c = hi + cc
+			// t[j-1] = u + m·N[j] + d
+			hi, mlo = mulW(m, n[j])
+			mlo, cc = addW(mlo, lo, 0)
+			hi += cc
+			mlo, cc = addW(mlo, d, 0)
+			d = hi + cc
+			t[j-1] = mlo
+		}
+		// t[s-1:s+1] = t[s] + c + d; t stays below 2R, so the top carry is
+		// at most one
+		top, c1 := addW(t[s], c, 0)
+		top, c2 := addW(top, d, 0)
+		t[s-1] = top
+		t[s] = c1 + c2
+	}
+}
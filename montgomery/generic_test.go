@@ -0,0 +1,117 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"testing"
+	"testing/quick"
+)
+
+// limbsOf returns the low s limbs of x as W, little-endian.
+func limbsOf[W limbWord](x *big.Int, s int) []W {
+	w := bits.Len64(uint64(^W(0)))
+	mask := new(big.Int).SetUint64(uint64(^W(0)))
+	z := make([]W, s)
+	v := new(big.Int).Set(x)
+	for i := range z {
+		z[i] = W(new(big.Int).And(v, mask).Uint64())
+		v.Rsh(v, uint(w))
+	}
+	return z
+}
+
+// fromLimbs is the inverse of limbsOf.
+func fromLimbs[W limbWord](z []W) *big.Int {
+	w := bits.Len64(uint64(^W(0)))
+	x := new(big.Int)
+	for i := len(z) - 1; i >= 0; i-- {
+		x.Lsh(x, uint(w)).Or(x, new(big.Int).SetUint64(uint64(z[i])))
+	}
+	return x
+}
+
+// checkMulAcc runs montgomeryImpl[W].mulAcc on random operands below N and
+// checks the result against math/big: congruent to x·y·R⁻¹ for R = 2^(w·s)
+// and below x·y/R + N, as montMulWords' final subtraction needs.
+func checkMulAcc[W limbWord](t *testing.T, N *big.Int) {
+	t.Helper()
+	w := bits.Len64(uint64(^W(0)))
+	s := (N.BitLen() + w - 1) / w
+	R := new(big.Int).Lsh(big.NewInt(1), uint(w*s))
+	rInv := new(big.Int).ModInverse(R, N)
+	k := montgomeryImpl[W]{limbsOf[W](N, s), W(WordInverse(N.Uint64(), uint(w)))}
+	scratch := make([]W, s+1)
+
+	check := func(x, y *big.Int, ys int) bool {
+		k.mulAcc(scratch, limbsOf[W](x, s), limbsOf[W](y, ys))
+		got := fromLimbs(scratch)
+		want := new(big.Int).Mul(x, y)
+		bound := new(big.Int).Add(new(big.Int).Div(want, R), N)
+		want.Mul(want, rInv).Mod(want, N)
+		return new(big.Int).Mod(got, N).Cmp(want) == 0 && got.Cmp(bound) < 0
+	}
+	nMinus1 := new(big.Int).Sub(N, big.NewInt(1))
+	if !check(nMinus1, nMinus1, s) {
+		t.Errorf("mulAcc(N-1, N-1) wrong")
+	}
+	// y given in fewer limbs than s, the rest implied zero
+	if !check(nMinus1, big.NewInt(12345), 1) {
+		t.Errorf("mulAcc(N-1, 12345) with a one-limb y wrong")
+	}
+	err := quick.Check(func(xBytes, yBytes []byte) bool {
+		x := new(big.Int).Mod(new(big.Int).SetBytes(xBytes), N)
+		y := new(big.Int).Mod(new(big.Int).SetBytes(yBytes), N)
+		return check(x, y, s)
+	}, &quick.Config{MaxCount: 100})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// TestMontgomeryImpl runs the kernel at both widths on every platform, so
+// the 32-bit instantiation that 386, arm and mips use for big.Words is
+// covered by 64-bit test runs too.
+func TestMontgomeryImpl(t *testing.T) {
+	t.Parallel()
+
+	for _, bitSize := range []int{64, 96, 256, 1024, 2048} {
+		_, _, _, N := testParamsLarge(bitSize)
+		t.Run(fmt.Sprintf("bits=%d/w=32", bitSize), func(t *testing.T) {
+			t.Parallel()
+			checkMulAcc[uint32](t, N)
+		})
+		t.Run(fmt.Sprintf("bits=%d/w=64", bitSize), func(t *testing.T) {
+			t.Parallel()
+			checkMulAcc[uint64](t, N)
+		})
+		t.Run(fmt.Sprintf("bits=%d/w=big.Word", bitSize), func(t *testing.T) {
+			t.Parallel()
+			checkMulAcc[big.Word](t, N)
+		})
+	}
+}
+
+// BenchmarkMontgomeryImpl compares the widths on the running platform;
+// where 64-bit multiplication is emulated, w=32 is the faster one.
+func BenchmarkMontgomeryImpl(b *testing.B) {
+	for _, bitSize := range []int{256, 2048} {
+		x, y, _, N := testParamsLarge(bitSize)
+		b.Run(fmt.Sprintf("bits=%d/w=32", bitSize), func(b *testing.B) {
+			benchMulAcc[uint32](b, x, y, N)
+		})
+		b.Run(fmt.Sprintf("bits=%d/w=64", bitSize), func(b *testing.B) {
+			benchMulAcc[uint64](b, x, y, N)
+		})
+	}
+}
+
+func benchMulAcc[W limbWord](b *testing.B, x, y, N *big.Int) {
+	w := bits.Len64(uint64(^W(0)))
+	s := (N.BitLen() + w - 1) / w
+	k := montgomeryImpl[W]{limbsOf[W](N, s), W(WordInverse(N.Uint64(), uint(w)))}
+	xs, ys, t := limbsOf[W](x, s), limbsOf[W](y, s), make([]W, s+1)
+	for b.Loop() {
+		k.mulAcc(t, xs, ys)
+	}
+}
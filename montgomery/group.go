@@ -0,0 +1,262 @@
+package montgomery
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// ErrGroupOrder is returned by CompositeCtx.Group when some pᵢ-1 cannot be
+// factored, so element orders and generators cannot be certified.
+var ErrGroupOrder = errors.New("montgomery: cannot factor the group order")
+
+// rhoSteps bounds the Pollard rho search for each factor of a pᵢ-1.
+const rhoSteps = 1 << 20
+
+// Group is the structure of the unit group Z_N^* of a CompositeCtx.
+//
+// For an odd prime p, Z_{p^e}^* is cyclic of order φ(p^e) = p^(e-1)·(p-1),
+// so the CRT splits Z_N^* into one cyclic factor per prime power. Group
+// normalizes those into invariant factors and, with the primes dividing
+// each φ(pᵢ^eᵢ), tests element orders and primitive roots. Those primes
+// are what knowing the factorization of N does not give for free: Group
+// finds them by trial division and Pollard's rho, which is quick when
+// each pᵢ-1 has at most one large prime factor, as for safe primes, and
+// fails for RSA-size primes in general.
+type Group struct {
+	c          *CompositeCtx
+	primes     [][]*big.Int // the distinct primes dividing each φ(pᵢ^eᵢ)
+	invariants []*big.Int
+}
+
+// Group returns the structure of Z_N^*, or an error wrapping ErrGroupOrder
+// if some pᵢ-1 cannot be factored.
+func (c *CompositeCtx) Group() (*Group, error) {
+	g := &Group{c: c, primes: make([][]*big.Int, len(c.comps))}
+	for i, k := range c.comps {
+		ps, err := factorize(new(big.Int).Sub(k.p, big.NewInt(1)), rhoSteps)
+		if err != nil {
+			return nil, fmt.Errorf("%w: p%d-1: %v", ErrGroupOrder, i, err)
+		}
+		if k.e > 1 && !containsPrime(ps, k.p) {
+			ps = append(ps, k.p)
+		}
+		g.primes[i] = ps
+	}
+	g.invariants = invariantFactors(c.comps)
+	return g, nil
+}
+
+// Order returns |Z_N^*| = φ(N).
+func (g *Group) Order() *big.Int {
+	o := big.NewInt(1)
+	for _, k := range g.c.comps {
+		o.Mul(o, k.phi)
+	}
+	return o
+}
+
+// Exponent returns λ(N), the Carmichael function: the largest element
+// order, which every element order divides.
+func (g *Group) Exponent() *big.Int {
+	return new(big.Int).Set(g.invariants[len(g.invariants)-1])
+}
+
+// InvariantFactors returns d₁ | d₂ | … | dₖ with Z_N^* ≅ Z_d₁ × … × Z_dₖ.
+// The group is cyclic exactly when there is a single factor.
+func (g *Group) InvariantFactors() []*big.Int {
+	ds := make([]*big.Int, len(g.invariants))
+	for i, d := range g.invariants {
+		ds[i] = new(big.Int).Set(d)
+	}
+	return ds
+}
+
+// ElementOrder returns the multiplicative order of x mod N, or nil if x
+// shares a factor with N. The order is taken in each component, starting
+// from φ(pᵢ^eᵢ) and dividing out every prime q while x^(o/q) stays 1, and
+// the orders are combined by their lcm.
+func (g *Group) ElementOrder(x *big.Int) *big.Int {
+	order := big.NewInt(1)
+	for i := range g.c.comps {
+		k := &g.c.comps[i]
+		xi := k.reduce(x)
+		if new(big.Int).Mod(xi, k.p).Sign() == 0 {
+			return nil
+		}
+		o := k.order(xi, g.primes[i])
+		order = lcm(order, o)
+	}
+	return order
+}
+
+// Generators returns one random element per prime power, gᵢ ≡ a primitive
+// root mod pᵢ^eᵢ and gᵢ ≡ 1 mod every other component, so that gᵢ has
+// order φ(pᵢ^eᵢ) and together they generate Z_N^* as the direct product
+// of their cyclic subgroups. Their product has order λ(N). Randomness is
+// read from r, or crypto/rand.Reader when r is nil; about φ(p-1)/(p-1) of
+// the residues are primitive roots, so only a few samples are needed.
+func (g *Group) Generators(r io.Reader) ([]*big.Int, error) {
+	if r == nil {
+		r = rand.Reader
+	}
+	one := big.NewInt(1)
+	gens := make([]*big.Int, len(g.c.comps))
+	for i := range g.c.comps {
+		k := &g.c.comps[i]
+		var root *big.Int
+		for root == nil {
+			x, err := rand.Int(r, k.pe)
+			if err != nil {
+				return nil, err
+			}
+			if new(big.Int).Mod(x, k.p).Sign() != 0 && k.order(x, g.primes[i]).Cmp(k.phi) == 0 {
+				root = x
+			}
+		}
+		residues := make([]*big.Int, len(g.c.comps))
+		for j := range residues {
+			residues[j] = one
+		}
+		residues[i] = root
+		gens[i] = g.c.g.combine(residues)
+	}
+	return gens, nil
+}
+
+// order returns the order of the unit x in Z_{p^e}^*, given the distinct
+// primes dividing φ(p^e).
+func (k *component) order(x *big.Int, primes []*big.Int) *big.Int {
+	o := new(big.Int).Set(k.phi)
+	q, r := new(big.Int), new(big.Int)
+	for _, p := range primes {
+		for {
+			q.QuoRem(o, p, r)
+			if r.Sign() != 0 || k.m.Exp(x, q).Cmp(big.NewInt(1)) != 0 {
+				break
+			}
+			o.Set(q)
+		}
+	}
+	return o
+}
+
+// invariantFactors returns the invariant factors of Π Z_φ(pᵢ^eᵢ). Replacing
+// every pair (dᵢ, dⱼ), i < j, by (gcd, lcm) keeps the group and leaves each
+// dᵢ dividing all later ones; factors of 1 are trivial and dropped.
+func invariantFactors(comps []component) []*big.Int {
+	ds := make([]*big.Int, len(comps))
+	for i, k := range comps {
+		ds[i] = new(big.Int).Set(k.phi)
+	}
+	for i := range ds {
+		for j := i + 1; j < len(ds); j++ {
+			d := new(big.Int).GCD(nil, nil, ds[i], ds[j])
+			ds[i], ds[j] = d, lcm(ds[i], ds[j])
+		}
+	}
+	out := ds[:0]
+	for _, d := range ds {
+		if d.Cmp(big.NewInt(1)) != 0 {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// lcm returns the least common multiple of positive a and b.
+func lcm(a, b *big.Int) *big.Int {
+	d := new(big.Int).GCD(nil, nil, a, b)
+	return d.Mul(new(big.Int).Quo(a, d), b)
+}
+
+// factorize returns the distinct prime factors of n ≥ 1: small ones by
+// trial division, then Pollard's rho with Brent's cycle detection on
+// whatever composite cofactor is left, spending at most steps iterations
+// per split.
+func factorize(n *big.Int, steps int) ([]*big.Int, error) {
+	var primes []*big.Int
+	n = new(big.Int).Set(n)
+	r := new(big.Int)
+	for d := int64(2); d < 1<<10 && n.Cmp(big.NewInt(1)) > 0; d++ {
+		bd := big.NewInt(d)
+		if r.Mod(n, bd); r.Sign() != 0 {
+			continue
+		}
+		primes = append(primes, bd)
+		for r.Mod(n, bd).Sign() == 0 {
+			n.Quo(n, bd)
+		}
+	}
+
+	pending := []*big.Int{n}
+	for len(pending) > 0 {
+		m := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		switch {
+		case m.Cmp(big.NewInt(1)) == 0 || containsPrime(primes, m):
+		case m.ProbablyPrime(20):
+			primes = append(primes, m)
+		default:
+			d := pollardRho(m, steps)
+			if d == nil {
+				return nil, fmt.Errorf("no factor of the %d-bit cofactor %v in %d steps", m.BitLen(), m, steps)
+			}
+			pending = append(pending, d, new(big.Int).Quo(m, d))
+		}
+	}
+	return primes, nil
+}
+
+// pollardRho returns a nontrivial factor of the odd composite n, or nil if
+// none turns up within steps iterations of x ← x² + c for c = 1, 2, ….
+// Brent's variant compares against a saved point at powers of two and
+// batches the gcds over products of 128 differences.
+func pollardRho(n *big.Int, steps int) *big.Int {
+	one := big.NewInt(1)
+	for c := int64(1); steps > 0; c++ {
+		bc := big.NewInt(c)
+		f := func(z, x *big.Int) { z.Mul(x, x).Add(z, bc).Mod(z, n) }
+		x, y, ys := big.NewInt(2), big.NewInt(2), new(big.Int)
+		q, d, t := big.NewInt(1), big.NewInt(1), new(big.Int)
+		for r := 1; d.Cmp(one) == 0 && steps > 0; r *= 2 {
+			x.Set(y)
+			for range r {
+				f(y, y)
+			}
+			for k := 0; k < r && d.Cmp(one) == 0 && steps > 0; k += 128 {
+				ys.Set(y)
+				for range min(128, r-k) {
+					f(y, y)
+					q.Mul(q, t.Sub(x, y).Abs(t)).Mod(q, n)
+					steps--
+				}
+				d.GCD(nil, nil, q, n)
+			}
+		}
+		if d.Cmp(n) == 0 {
+			// The batch overshot: step through it one difference at a time
+			for {
+				f(ys, ys)
+				if d.GCD(nil, nil, t.Sub(x, ys).Abs(t), n); d.Cmp(one) != 0 {
+					break
+				}
+			}
+		}
+		if d.Cmp(one) != 0 && d.Cmp(n) != 0 {
+			return d
+		}
+	}
+	return nil
+}
+
+func containsPrime(ps []*big.Int, p *big.Int) bool {
+	for _, q := range ps {
+		if q.Cmp(p) == 0 {
+			return true
+		}
+	}
+	return false
+}
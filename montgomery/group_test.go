@@ -0,0 +1,259 @@
+package montgomery
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	mrand "math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// bruteOrder returns the order of x mod n by repeated multiplication, or 0
+// if x is not a unit.
+func bruteOrder(x, n int64) int64 {
+	y := x % n
+	for o := int64(1); o <= n; o++ {
+		if y == 1 {
+			return o
+		}
+		y = y * x % n
+	}
+	return 0
+}
+
+func TestGroup(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		factors    []Factor
+		order      int64
+		invariants []int64
+	}{
+		{"prime", []Factor{{big.NewInt(23), 1}}, 22, []int64{22}},
+		{"prime power", []Factor{{big.NewInt(7), 2}}, 42, []int64{42}},
+		{"two primes", []Factor{{big.NewInt(5), 1}, {big.NewInt(7), 1}}, 24, []int64{2, 12}},
+		{"coprime orders", []Factor{{big.NewInt(3), 1}, {big.NewInt(11), 2}}, 220, []int64{2, 110}},
+		{"three components", []Factor{{big.NewInt(3), 2}, {big.NewInt(5), 1}, {big.NewInt(7), 1}}, 144, []int64{2, 6, 12}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := NewCompositeCtx(tc.factors)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g, err := c.Group()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := g.Order(); got.Int64() != tc.order {
+				t.Errorf("Order() = %v, want %d", got, tc.order)
+			}
+			var got []int64
+			for _, d := range g.InvariantFactors() {
+				got = append(got, d.Int64())
+			}
+			if !slices.Equal(got, tc.invariants) {
+				t.Errorf("InvariantFactors() = %v, want %v", got, tc.invariants)
+			}
+			if got, want := g.Exponent().Int64(), tc.invariants[len(tc.invariants)-1]; got != want {
+				t.Errorf("Exponent() = %d, want %d", got, want)
+			}
+
+			n := c.Modulus().Int64()
+			for x := int64(0); x < n; x++ {
+				got := g.ElementOrder(big.NewInt(x))
+				if want := bruteOrder(x, n); want == 0 && got != nil || want != 0 && (got == nil || got.Int64() != want) {
+					t.Fatalf("ElementOrder(%d) = %v, want %d", x, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGroup_Generators(t *testing.T) {
+	t.Parallel()
+
+	for _, factors := range [][]Factor{
+		{{big.NewInt(3), 2}, {big.NewInt(5), 1}, {big.NewInt(7), 1}},
+		testFactors(64),
+	} {
+		c, err := NewCompositeCtx(factors)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g, err := c.Group()
+		if err != nil {
+			t.Fatal(err)
+		}
+		gens, err := g.Generators(mrand.NewChaCha8([32]byte{'g', 'e', 'n'}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		prod := big.NewInt(1)
+		for i, x := range gens {
+			if got, want := g.ElementOrder(x), c.comps[i].phi; got.Cmp(want) != 0 {
+				t.Errorf("ElementOrder(generator %d) = %v, want φ(p^e) = %v", i, got, want)
+			}
+			prod = c.Mul(prod, x)
+		}
+		if got, want := g.ElementOrder(prod), g.Exponent(); got.Cmp(want) != 0 {
+			t.Errorf("ElementOrder(Π generators) = %v, want λ(N) = %v", got, want)
+		}
+
+		// At the small size the generators must reach every unit
+		n := c.Modulus()
+		if !n.IsInt64() || n.Int64() > 1000 {
+			continue
+		}
+		span := map[int64]bool{1: true}
+		for _, x := range gens {
+			for y := range span {
+				for z := y * x.Int64() % n.Int64(); !span[z]; z = z * x.Int64() % n.Int64() {
+					span[z] = true
+				}
+			}
+		}
+		if got, want := int64(len(span)), g.Order().Int64(); got != want {
+			t.Errorf("generators span %d elements, want φ(N) = %d", got, want)
+		}
+	}
+}
+
+func TestGroup_ElementOrder(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewCompositeCtx(testFactors(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := c.Group()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, lambda := c.Modulus(), g.Exponent()
+	one := big.NewInt(1)
+	for _, x := range []*big.Int{big.NewInt(2), big.NewInt(-1), new(big.Int).Sub(n, big.NewInt(12345))} {
+		o := g.ElementOrder(x)
+		if new(big.Int).Mod(lambda, o).Sign() != 0 {
+			t.Errorf("ElementOrder(%v) = %v does not divide λ(N) = %v", x, o, lambda)
+		}
+		if c.Exp(x, o).Cmp(one) != 0 {
+			t.Errorf("%v^ElementOrder(%v) != 1", x, x)
+		}
+		// ord(x^k) = ord(x)/gcd(ord(x), k)
+		for _, k := range []int64{2, 3, 1000} {
+			bk := big.NewInt(k)
+			want := new(big.Int).Quo(o, new(big.Int).GCD(nil, nil, o, bk))
+			if got := g.ElementOrder(c.Exp(x, bk)); got.Cmp(want) != 0 {
+				t.Errorf("ElementOrder(%v^%d) = %v, want %v", x, k, got, want)
+			}
+		}
+	}
+	if got := g.ElementOrder(c.comps[0].p); got != nil {
+		t.Errorf("ElementOrder(p) = %v, want nil", got)
+	}
+}
+
+func Test_factorize(t *testing.T) {
+	t.Parallel()
+
+	m61 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 61), big.NewInt(1))
+	m31 := big.NewInt(1<<31 - 1)
+	ints := func(xs ...int64) []*big.Int {
+		var out []*big.Int
+		for _, x := range xs {
+			out = append(out, big.NewInt(x))
+		}
+		return out
+	}
+
+	tests := []struct {
+		name    string
+		n       *big.Int
+		steps   int
+		want    []*big.Int
+		wantErr bool
+	}{
+		{name: "one", n: big.NewInt(1), steps: rhoSteps},
+		{name: "smooth", n: big.NewInt(1 << 10 * 243 * 49), steps: rhoSteps, want: ints(2, 3, 7)},
+		{name: "prime", n: m61, steps: rhoSteps, want: []*big.Int{m61}},
+		{name: "rho", n: big.NewInt(1000003 * 1000033 * 6), steps: rhoSteps, want: ints(2, 3, 1000003, 1000033)},
+		{name: "square", n: big.NewInt(1000003 * 1000003), steps: rhoSteps, want: ints(1000003)},
+		{name: "two large primes", n: new(big.Int).Mul(m61, m31), steps: rhoSteps, want: []*big.Int{m31, m61}},
+		{name: "out of steps", n: new(big.Int).Mul(m61, m31), steps: 1 << 8, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := factorize(tc.n, tc.steps)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("factorize() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			slices.SortFunc(got, (*big.Int).Cmp)
+			if !slices.EqualFunc(got, tc.want, func(a, b *big.Int) bool { return a.Cmp(b) == 0 }) {
+				t.Errorf("factorize() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCompositeCtx_Group_error(t *testing.T) {
+	t.Parallel()
+
+	// p-1 = 2k·q₁·q₂ with two 70-bit primes is out of reach of the rho
+	// budget
+	next := func(x *big.Int) *big.Int {
+		for !x.ProbablyPrime(20) {
+			x.Add(x, big.NewInt(1))
+		}
+		return x
+	}
+	q1 := next(new(big.Int).Lsh(big.NewInt(1), 70))
+	q2 := next(new(big.Int).Lsh(big.NewInt(3), 69))
+	q := new(big.Int).Mul(q1, q2)
+	p := new(big.Int).Add(q, big.NewInt(1))
+	for k := int64(2); !p.ProbablyPrime(20); k += 2 {
+		p.Add(new(big.Int).Mul(q, big.NewInt(k)), big.NewInt(1))
+	}
+
+	c, err := NewCompositeCtx([]Factor{{p, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Group(); !errors.Is(err, ErrGroupOrder) {
+		t.Errorf("Group() error = %v, want %v", err, ErrGroupOrder)
+	}
+}
+
+func BenchmarkGroup(b *testing.B) {
+	c, err := NewCompositeCtx(testFactors(64))
+	if err != nil {
+		b.Fatal(err)
+	}
+	g, err := c.Group()
+	if err != nil {
+		b.Fatal(err)
+	}
+	x := new(big.Int).Sub(c.Modulus(), big.NewInt(12345))
+	rng := mrand.NewChaCha8([32]byte{})
+
+	for _, bc := range []struct {
+		op string
+		f  func()
+	}{
+		{"Group", func() { c.Group() }},
+		{"ElementOrder", func() { g.ElementOrder(x) }},
+		{"Generators", func() { g.Generators(rng) }},
+	} {
+		b.Run(fmt.Sprintf("op=%s", bc.op), func(b *testing.B) {
+			for b.Loop() {
+				bc.f()
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package montgomery
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// These helpers convert residues mod N to and from the element formats of
+// two widely used libraries, so that values can cross between them and the
+// vault without a detour through big.Int strings:
+//
+//   - gnark-crypto field elements (fp.Element, fr.Element) are
+//     k = ⌈bits(N)/64⌉ little-endian 64-bit limbs in Montgomery form under
+//     2^(64·k), and Element.Bytes and SetBytesCanonical use the canonical
+//     value in 8·k big-endian bytes.
+//   - filippo.io/edwards25519 encodes field.Element and Scalar as the
+//     canonical value in 32 little-endian bytes.
+//
+// Neither library is imported. The decoders accept only canonical
+// encodings, of the exact length and below N, and report anything else as
+// ErrEncoding.
+
+// ErrEncoding is returned for an element encoding of the wrong length or
+// with a value not below N.
+var ErrEncoding = errors.New("montgomery: invalid element encoding")
+
+// gnarkLimbs is the number of limbs in a gnark-crypto element mod N.
+func (m *MontgomeryCIOSWords) gnarkLimbs() int {
+	return (m.N.BitLen() + 63) / 64
+}
+
+// GnarkLimbs returns x as the limbs of a gnark-crypto element, x·2^(64·k)
+// mod N for k = ⌈bits(N)/64⌉, ready to convert with [k]uint64(limbs).
+// Operands outside [0, N) are handled by the context's InputPolicy.
+//
+// When R = 2^(64·k), the smallest R the word-level API accepts for N, this
+// is the Montgomery form of ToMontWords: gnark elements and the limbs of
+// MulMontWords are then interchangeable without any conversion.
+func (m *MontgomeryCIOSWords) GnarkLimbs(x *big.Int) []uint64 {
+	x = m.cfg.input.mustOperand(m.N, "GnarkLimbs", x)
+	k := m.gnarkLimbs()
+	if k == m.S {
+		z := limbsPadded(x, k)
+		m.ToMontWords(z, z)
+		return z
+	}
+	v := new(big.Int).Lsh(x, uint(64*k))
+	return limbsPadded(v.Mod(v, m.N), k)
+}
+
+// FromGnarkLimbs returns the residue held by the limbs of a gnark-crypto
+// element, as produced by GnarkLimbs or taken from an fp.Element or
+// fr.Element.
+func (m *MontgomeryCIOSWords) FromGnarkLimbs(limbs []uint64) (*big.Int, error) {
+	k := m.gnarkLimbs()
+	if len(limbs) != k {
+		return nil, fmt.Errorf("%w: %d limbs, want %d", ErrEncoding, len(limbs), k)
+	}
+	v, err := m.canonical(tobigInt(limbs))
+	if err != nil {
+		return nil, err
+	}
+	if k == m.S {
+		z := slices.Clone(limbs)
+		m.FromMontWords(z, z)
+		return tobigInt(z), nil
+	}
+	// x = v·2^(-64·k) mod N
+	rInv := new(big.Int).Lsh(big.NewInt(1), uint(64*k))
+	rInv.ModInverse(rInv, m.N)
+	return v.Mod(v.Mul(v, rInv), m.N), nil
+}
+
+// GnarkBytes returns x in 8·k big-endian bytes, the encoding of
+// gnark-crypto's Element.Bytes and Marshal. Operands outside [0, N) are
+// handled by the context's InputPolicy.
+func (m *MontgomeryCIOSWords) GnarkBytes(x *big.Int) []byte {
+	x = m.cfg.input.mustOperand(m.N, "GnarkBytes", x)
+	return x.FillBytes(make([]byte, 8*m.gnarkLimbs()))
+}
+
+// FromGnarkBytes decodes 8·k big-endian bytes, as SetBytesCanonical does.
+func (m *MontgomeryCIOSWords) FromGnarkBytes(b []byte) (*big.Int, error) {
+	if n := 8 * m.gnarkLimbs(); len(b) != n {
+		return nil, fmt.Errorf("%w: %d bytes, want %d", ErrEncoding, len(b), n)
+	}
+	return m.canonical(new(big.Int).SetBytes(b))
+}
+
+// Edwards25519Bytes returns x in ⌈bits(N)/8⌉ little-endian bytes. For the
+// field prime 2^255-19 and the group order of edwards25519 this is the
+// 32-byte encoding of field.Element.Bytes and Scalar.Bytes. Operands
+// outside [0, N) are handled by the context's InputPolicy.
+func (m *MontgomeryCIOSWords) Edwards25519Bytes(x *big.Int) []byte {
+	x = m.cfg.input.mustOperand(m.N, "Edwards25519Bytes", x)
+	b := x.FillBytes(make([]byte, (m.N.BitLen()+7)/8))
+	slices.Reverse(b)
+	return b
+}
+
+// FromEdwards25519Bytes decodes ⌈bits(N)/8⌉ little-endian bytes, as
+// Scalar.SetCanonicalBytes does. field.Element.SetBytes is laxer: it
+// ignores the top bit and reduces values not below 2^255-19, which this
+// rejects.
+func (m *MontgomeryCIOSWords) FromEdwards25519Bytes(b []byte) (*big.Int, error) {
+	if n := (m.N.BitLen() + 7) / 8; len(b) != n {
+		return nil, fmt.Errorf("%w: %d bytes, want %d", ErrEncoding, len(b), n)
+	}
+	be := slices.Clone(b)
+	slices.Reverse(be)
+	return m.canonical(new(big.Int).SetBytes(be))
+}
+
+// canonical returns v if it lies below N, else an ErrEncoding.
+func (m *MontgomeryCIOSWords) canonical(v *big.Int) (*big.Int, error) {
+	if v.Cmp(m.N) >= 0 {
+		return nil, fmt.Errorf("%w: value not below N", ErrEncoding)
+	}
+	return v, nil
+}
@@ -0,0 +1,159 @@
+package montgomery
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"testing"
+	"testing/quick"
+)
+
+// Moduli of the libraries the interop helpers target
+var (
+	bn254R, _   = new(big.Int).SetString("21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
+	ed25519L, _ = new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+)
+
+// interopContext returns a word-level context for N with R = 2^(64·(k+extra)),
+// k being the limbs of N: extra = 0 matches gnark-crypto's Montgomery form.
+func interopContext(N *big.Int, extra int) *MontgomeryCIOSWords {
+	k := (N.BitLen() + 63) / 64
+	return NewMontgomeryCIOSWords(new(big.Int).Lsh(big.NewInt(1), uint(64*(k+extra))), N)
+}
+
+func decodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestMontgomeryCIOSWords_gnarkKnownAnswer(t *testing.T) {
+	t.Parallel()
+
+	for _, extra := range []int{0, 1} {
+		t.Run(fmt.Sprintf("extra=%d", extra), func(t *testing.T) {
+			t.Parallel()
+			m := interopContext(bn254R, extra)
+
+			// fr.One() of gnark-crypto's bn254 package
+			one := []uint64{12436184717236109307, 3962172157175319849, 7381016538464732718, 1011752739694698287}
+			if got := m.GnarkLimbs(big.NewInt(1)); !slices.Equal(got, one) {
+				t.Errorf("GnarkLimbs(1) = %v, want %v", got, one)
+			}
+			if x, err := m.FromGnarkLimbs(one); err != nil || x.Cmp(big.NewInt(1)) != 0 {
+				t.Errorf("FromGnarkLimbs(fr.One()) = %v, %v, want 1", x, err)
+			}
+
+			top := new(big.Int).Sub(bn254R, big.NewInt(1))
+			want := decodeHex("30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000000")
+			if got := m.GnarkBytes(top); !slices.Equal(got, want) {
+				t.Errorf("GnarkBytes(r-1) = %x, want %x", got, want)
+			}
+		})
+	}
+}
+
+func TestMontgomeryCIOSWords_edwards25519KnownAnswer(t *testing.T) {
+	t.Parallel()
+
+	p, err := LookupParams("curve25519")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fe := interopContext(p.N, 0)
+	// The y coordinate 4/5 of the base point, whose RFC 8032 encoding is
+	// the field element's with a clear sign bit
+	y := new(big.Int).ModInverse(big.NewInt(5), p.N)
+	y.Mod(y.Lsh(y, 2), p.N)
+	want := decodeHex("5866666666666666666666666666666666666666666666666666666666666666")
+	if got := fe.Edwards25519Bytes(y); !slices.Equal(got, want) {
+		t.Errorf("Edwards25519Bytes(4/5) = %x, want %x", got, want)
+	}
+
+	sc := interopContext(ed25519L, 0)
+	top := new(big.Int).Sub(ed25519L, big.NewInt(1))
+	want = decodeHex("ecd3f55c1a631258d69cf7a2def9de1400000000000000000000000000000010")
+	if got := sc.Edwards25519Bytes(top); !slices.Equal(got, want) {
+		t.Errorf("Edwards25519Bytes(l-1) = %x, want %x", got, want)
+	}
+}
+
+// TestMontgomeryCIOSWords_interopRoundTrip checks every encoder against its
+// decoder, with R matching gnark-crypto's and with a larger one.
+func TestMontgomeryCIOSWords_interopRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, N := range []*big.Int{bn254R, ed25519L} {
+		for _, extra := range []int{0, 1} {
+			t.Run(fmt.Sprintf("bits=%d/extra=%d", N.BitLen(), extra), func(t *testing.T) {
+				t.Parallel()
+				m := interopContext(N, extra)
+				err := quick.Check(func(xBytes []byte) bool {
+					x := new(big.Int).Mod(new(big.Int).SetBytes(xBytes), N)
+					fromLimbs, err1 := m.FromGnarkLimbs(m.GnarkLimbs(x))
+					fromBE, err2 := m.FromGnarkBytes(m.GnarkBytes(x))
+					fromLE, err3 := m.FromEdwards25519Bytes(m.Edwards25519Bytes(x))
+					return errors.Join(err1, err2, err3) == nil &&
+						fromLimbs.Cmp(x) == 0 && fromBE.Cmp(x) == 0 && fromLE.Cmp(x) == 0
+				}, nil)
+				if err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
+
+// TestMontgomeryCIOSWords_gnarkLimbsAreMontWords checks that with
+// R = 2^(64·k) gnark-crypto limbs feed the word-level API unchanged.
+func TestMontgomeryCIOSWords_gnarkLimbsAreMontWords(t *testing.T) {
+	t.Parallel()
+
+	m := interopContext(bn254R, 0)
+	err := quick.Check(func(xBytes, yBytes []byte) bool {
+		x := new(big.Int).Mod(new(big.Int).SetBytes(xBytes), bn254R)
+		y := new(big.Int).Mod(new(big.Int).SetBytes(yBytes), bn254R)
+		z := make([]uint64, m.S)
+		m.MulMontWords(z, m.GnarkLimbs(x), m.GnarkLimbs(y))
+		got, err := m.FromGnarkLimbs(z)
+		return err == nil && got.Cmp(m.Mul(x, y)) == 0
+	}, nil)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMontgomeryCIOSWords_interopRejects(t *testing.T) {
+	t.Parallel()
+
+	m := interopContext(bn254R, 0)
+	nLimbs := limbsPadded(bn254R, 4)
+	nBE := bn254R.FillBytes(make([]byte, 32))
+	nLE := slices.Clone(nBE)
+	slices.Reverse(nLE)
+
+	tests := []struct {
+		name string
+		f    func() (*big.Int, error)
+	}{
+		{"limbs short", func() (*big.Int, error) { return m.FromGnarkLimbs(nLimbs[:3]) }},
+		{"limbs N", func() (*big.Int, error) { return m.FromGnarkLimbs(nLimbs) }},
+		{"big-endian long", func() (*big.Int, error) { return m.FromGnarkBytes(make([]byte, 33)) }},
+		{"big-endian N", func() (*big.Int, error) { return m.FromGnarkBytes(nBE) }},
+		{"little-endian short", func() (*big.Int, error) { return m.FromEdwards25519Bytes(nil) }},
+		{"little-endian N", func() (*big.Int, error) { return m.FromEdwards25519Bytes(nLE) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if x, err := tt.f(); !errors.Is(err, ErrEncoding) {
+				t.Errorf("got %v, %v, want ErrEncoding", x, err)
+			}
+		})
+	}
+}
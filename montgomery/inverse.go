@@ -0,0 +1,111 @@
+package montgomery
+
+import "math/big"
+
+// Inv returns x⁻¹ mod N by the Kaliski Montgomery inverse, or nil if x
+// shares a factor with N. Operands outside [0, N) are handled by the
+// context's InputPolicy.
+func (m *MontgomeryBitwise) Inv(x *big.Int) *big.Int { return inv(m.engine(), x) }
+
+// InvMont returns a⁻¹ in Montgomery form, and false if a is not
+// invertible. See montInverse.
+func (m *MontgomeryBitwise) InvMont(a MontElement) (MontElement, bool) {
+	return invMont(m.engine(), a)
+}
+
+// Inv returns x⁻¹ mod N, or nil. See MontgomeryBitwise.Inv.
+func (m *MontgomeryCIOS) Inv(x *big.Int) *big.Int { return inv(m.engine(), x) }
+
+// InvMont returns a⁻¹ in Montgomery form, and false if a is not
+// invertible. See montInverse.
+func (m *MontgomeryCIOS) InvMont(a MontElement) (MontElement, bool) {
+	return invMont(m.engine(), a)
+}
+
+// Inv returns x⁻¹ mod N, or nil. See MontgomeryBitwise.Inv.
+func (m *MontgomeryCIOSWords) Inv(x *big.Int) *big.Int { return inv(m.engine(), x) }
+
+// InvMont returns a⁻¹ in Montgomery form, and false if a is not
+// invertible. See montInverse.
+func (m *MontgomeryCIOSWords) InvMont(a MontElement) (MontElement, bool) {
+	return invMont(m.engine(), a)
+}
+
+// inv returns x⁻¹ = REDC(REDC(x⁻¹·R², 1), 1), with x⁻¹·R² from
+// montInverse on x itself: the plain and the Montgomery-form inverse are
+// the same computation with two REDCs between them.
+func inv(eng engine, x *big.Int) *big.Int {
+	x = eng.input.mustOperand(eng.n, "Inv", x)
+	t, ok := montInverse(eng, x)
+	if !ok {
+		return nil
+	}
+	one := big.NewInt(1)
+	return eng.redc(eng.redc(t, one), one)
+}
+
+func invMont(eng engine, a MontElement) (MontElement, bool) {
+	t, ok := montInverse(eng, a.val())
+	if !ok {
+		return MontElement{}, false
+	}
+	return MontElement{t}, true
+}
+
+// montInverse returns a⁻¹·R² mod N for a in [0, N), which for a = x·R in
+// Montgomery form is (x⁻¹)·R: the inverse, still in Montgomery form.
+//
+// Kaliski's algorithm works in two phases. The first, almostInverse, is a
+// binary extended GCD that needs only shifts, additions and subtractions
+// and yields a⁻¹·2^k for some k between the bit lengths of N and 2·R's.
+// The second fixes up the power of two, here with one shift and one
+// division: a⁻¹·2^k·2^(2m-k) = a⁻¹·R² for R = 2^m. Like math/big, it
+// branches on its operand and is not constant time.
+func montInverse(eng engine, a *big.Int) (*big.Int, bool) {
+	r, k, ok := almostInverse(a, eng.n)
+	if !ok {
+		return nil, false
+	}
+	r.Lsh(r, 2*eng.k-uint(k))
+	return r.Mod(r, eng.n), true
+}
+
+// almostInverse is phase one of the Kaliski inverse: for odd n > 1 and a
+// in [0, n) it returns r = a⁻¹·2^k mod n, with len(n) ≤ k ≤ len(n) + len(a),
+// or ok = false when gcd(a, n) ≠ 1.
+//
+// Every step halves u or v and keeps the invariants
+//
+//	n = u·s + v·r,  a·r ≡ -u·2^k,  a·s ≡ v·2^k  (mod n)
+//
+// so on exit, with v = 0 and u = gcd(a, n) = 1, r ≡ -a⁻¹·2^k.
+func almostInverse(a, n *big.Int) (r *big.Int, k int, ok bool) {
+	u, v := new(big.Int).Set(n), new(big.Int).Set(a)
+	r, s := new(big.Int), big.NewInt(1)
+	for v.Sign() > 0 {
+		switch {
+		case u.Bit(0) == 0:
+			u.Rsh(u, 1)
+			s.Lsh(s, 1)
+		case v.Bit(0) == 0:
+			v.Rsh(v, 1)
+			r.Lsh(r, 1)
+		case u.Cmp(v) > 0:
+			u.Sub(u, v).Rsh(u, 1)
+			r.Add(r, s)
+			s.Lsh(s, 1)
+		default:
+			v.Sub(v, u).Rsh(v, 1)
+			s.Add(s, r)
+			r.Lsh(r, 1)
+		}
+		k++
+	}
+	if u.Cmp(big.NewInt(1)) != 0 {
+		return nil, 0, false
+	}
+	if r.Cmp(n) >= 0 {
+		r.Sub(r, n)
+	}
+	return r.Sub(n, r), k, true
+}
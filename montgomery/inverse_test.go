@@ -0,0 +1,128 @@
+package montgomery
+
+import (
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestInv(t *testing.T) {
+	t.Parallel()
+
+	x, _, R, N := testParams2048()
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+	R64 := new(big.Int).Lsh(big.NewInt(1), 64)
+	// N = 3·5·7·…: 15 and 0 have no inverse
+	composite := big.NewInt(3 * 5 * 7 * 11 * 13)
+
+	tests := []struct {
+		name string
+		R, N *big.Int
+		x    *big.Int
+	}{
+		{"2048-bit", R, N, x},
+		{"one", R, N, big.NewInt(1)},
+		{"N - 1", R, N, new(big.Int).Sub(N, big.NewInt(1))},
+		{"power of two", R, N, new(big.Int).Lsh(big.NewInt(1), 2000)},
+		{"one word", R64, N64, big.NewInt(2)},
+		{"unreduced", R, N, new(big.Int).Add(x, N)},
+		{"negative", R, N, new(big.Int).Neg(x)},
+		{"zero", R, N, big.NewInt(0)},
+		{"shares a factor", R64, composite, big.NewInt(15)},
+		{"coprime to a composite", R64, composite, big.NewInt(16)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			want := new(big.Int).ModInverse(new(big.Int).Mod(tc.x, tc.N), tc.N)
+			for name, got := range map[string]*big.Int{
+				"Bitwise":   NewMontgomeryBitwise(tc.R, tc.N).Inv(tc.x),
+				"CIOS":      NewMontgomeryCIOS(tc.R, tc.N).Inv(tc.x),
+				"CIOSWords": NewMontgomeryCIOSWords(tc.R, tc.N).Inv(tc.x),
+			} {
+				if (got == nil) != (want == nil) || (got != nil && got.Cmp(want) != 0) {
+					t.Errorf("%s: Inv() = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestInvMont(t *testing.T) {
+	t.Parallel()
+
+	for _, bitSize := range []int{64, 256, 2048} {
+		_, _, R, N := testParamsLarge(bitSize)
+		m := NewMontgomeryCIOSWords(R, N)
+		one := m.ToMont(big.NewInt(1))
+		err := quick.Check(func(xBytes []byte) bool {
+			a := m.ToMont(new(big.Int).SetBytes(xBytes))
+			ai, ok := m.InvMont(a)
+			want := new(big.Int).ModInverse(m.FromMont(a), N)
+			if !ok {
+				return want == nil
+			}
+			// a·a⁻¹ = 1 without leaving Montgomery form
+			return m.MulMont(a, ai).Equal(one) && m.FromMont(ai).Cmp(want) == 0
+		}, &quick.Config{MaxCount: 50})
+		if err != nil {
+			t.Errorf("%d bits: %v", bitSize, err)
+		}
+		if _, ok := m.InvMont(MontElement{}); ok {
+			t.Errorf("%d bits: InvMont(0) reported an inverse", bitSize)
+		}
+	}
+}
+
+func Test_almostInverse(t *testing.T) {
+	t.Parallel()
+
+	_, _, _, N := testParams2048()
+	err := quick.Check(func(xBytes []byte) bool {
+		a := new(big.Int).Mod(new(big.Int).SetBytes(xBytes), N)
+		r, k, ok := almostInverse(a, N)
+		if !ok {
+			return new(big.Int).GCD(nil, nil, a, N).Cmp(big.NewInt(1)) != 0
+		}
+		// r = a⁻¹·2^k with len(N) ≤ k ≤ len(N) + len(a)
+		got := new(big.Int).Mul(r, a)
+		got.Mod(got, N)
+		want := new(big.Int).Lsh(big.NewInt(1), uint(k))
+		want.Mod(want, N)
+		return got.Cmp(want) == 0 && r.Cmp(N) < 0 && k >= N.BitLen() && k <= N.BitLen()+a.BitLen()
+	}, &quick.Config{MaxCount: 100})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// BenchmarkInv compares the Kaliski inverse with big.Int.ModInverse and
+// with Fermat inversion x^(N-2), both at 2048 bits.
+func BenchmarkInv(b *testing.B) {
+	x, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	a := m.ToMont(x)
+	nMinus2 := new(big.Int).Sub(N, big.NewInt(2))
+
+	b.Run("impl=kaliski", func(b *testing.B) {
+		for b.Loop() {
+			m.Inv(x)
+		}
+	})
+	b.Run("impl=kaliski-mont", func(b *testing.B) {
+		for b.Loop() {
+			m.InvMont(a)
+		}
+	})
+	b.Run("impl=big", func(b *testing.B) {
+		for b.Loop() {
+			new(big.Int).ModInverse(x, N)
+		}
+	})
+	b.Run("impl=fermat", func(b *testing.B) {
+		for b.Loop() {
+			m.Exp(x, nMinus2)
+		}
+	})
+}
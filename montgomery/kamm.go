@@ -0,0 +1,188 @@
+package montgomery
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// KAMM, the Karatsuba algorithm for Montgomery multiplication, separates
+// the two halves of REDC again: the full product x·y comes from Karatsuba,
+// and montReduceWords reduces it word by word. Interleaved CIOS spends s²
+// multiplications on the product and s² on the reduction; Karatsuba brings
+// the first half down to about s^1.58, so from a few thousand bits on the
+// product stops being the dominant cost.
+//
+// Unlike the separated path of separated.go, which leaves the product and
+// the reduction to big.Int, every loop here has a length that depends only
+// on s, and the signs of Karatsuba's middle term are applied with masks,
+// so montMulWordsKaratsuba keeps the constant-time guarantees of
+// montMulWords and can stand in for it in ExpConstantTime and ExpLadder.
+
+// karatsubaThreshold is the default number of 64-bit words in R (48 words
+// = 3072 bits) from which MontgomeryCIOSWords multiplies with KAMM instead
+// of interleaved CIOS, on platforms where mulAcc has no assembly. Against
+// the assembly kernels KAMM, all in Go, is still 40% slower at 16384 bits,
+// so there it is off unless WithKaratsuba asks for it. BenchmarkKaratsuba
+// shows the crossover on the host at hand.
+const karatsubaThreshold = 48
+
+// useKaratsuba reports whether a context of s limbs multiplies with KAMM,
+// given the threshold set by WithKaratsuba: 0 for the default, negative for
+// never.
+func useKaratsuba(s, threshold int) bool {
+	switch {
+	case threshold < 0:
+		return false
+	case threshold > 0:
+		return s >= threshold
+	}
+	return !hasMulAccAsm && s >= karatsubaThreshold
+}
+
+// karatsubaBase is the operand length, in limbs, below which mulKaratsuba
+// falls back to schoolbook multiplication.
+const karatsubaBase = 16
+
+// montMulWordsKaratsuba is montMulWords by KAMM: z = (x * y * R⁻¹) mod N for
+// x, y in [0, N), with t scratch of at least kammScratch(s) words. z may be
+// x, y or both; t must not alias any other argument.
+func montMulWordsKaratsuba(z, x, y, n []uint64, ni uint64, t []uint64) {
+	s := len(n)
+	t = t[:kammScratch(s)]
+	checkInPlace("montMulWordsKaratsuba", z, x)
+	checkInPlace("montMulWordsKaratsuba", z, y)
+	checkDisjoint("montMulWordsKaratsuba", z, n)
+	checkDisjoint("montMulWordsKaratsuba", t, z, x, y, n)
+	p := t[:2*s]
+	mulKaratsuba(p, x[:s], y[:s], t[2*s:])
+	montReduceWords(z, p, n, ni)
+}
+
+// kammScratch returns the scratch montMulWordsKaratsuba needs for s limbs:
+// the double-width product and the scratch of mulKaratsuba.
+func kammScratch(s int) int {
+	return 2*s + karatsubaScratch(s)
+}
+
+// karatsubaScratch returns the scratch mulKaratsuba needs for operands of n
+// limbs.
+func karatsubaScratch(n int) int {
+	if n < karatsubaBase {
+		return 0
+	}
+	m := n - n/2
+	return 4*m + 1 + karatsubaScratch(m)
+}
+
+// mulKaratsuba sets z = x·y, where x and y have n limbs and z has 2n, with
+// t scratch of karatsubaScratch(n) words. z must not overlap x, y or t.
+//
+// Splitting x = xh·B + xl and y = yh·B + yl at B = 2^(64·⌊n/2⌋), the middle
+// term is xl·yh + xh·yl = xl·yl + xh·yh − (xl − xh)(yl − yh). The
+// subtractive form needs three half-size products of |xl − xh| and
+// |yl − yh| and no carry word, and the sign of the third product is
+// applied by mask, so the sequence of operations depends only on n.
+func mulKaratsuba(z, x, y, t []uint64) {
+	n := len(x)
+	if n < karatsubaBase {
+		mulSchoolbook(z, x, y)
+		return
+	}
+	h := n / 2
+	m := n - h // m = h or h+1
+	xl, xh := x[:h], x[h:]
+	yl, yh := y[:h], y[h:]
+
+	// z = xh·yh·B² + xl·yl
+	mulKaratsuba(z[:2*h], xl, yl, t)
+	mulKaratsuba(z[2*h:], xh, yh, t)
+
+	// p = |xl − xh|·|yl − yh|, negative in the middle term when the signs agree
+	p, dx, dy := t[:2*m], t[2*m:3*m], t[3*m:4*m]
+	neg := subAbs(dx, xl, xh) ^ subAbs(dy, yl, yh) ^ 1
+	mulKaratsuba(p, dx, dy, t[4*m+1:])
+
+	// mid = xl·yl + xh·yh ∓ p in 2m+1 words, reusing dx and dy
+	mid := t[2*m : 4*m+1]
+	var c uint64
+	for i := range 2 * m {
+		var lo uint64
+		if i < 2*h {
+			lo = z[i]
+		}
+		mid[i], c = bits.Add64(z[2*h+i], lo, c)
+	}
+	mid[2*m] = c
+	// Subtracting p is adding its two's complement, ^p + 1
+	mask := ctMask(neg)
+	c = neg
+	for i := range 2 * m {
+		mid[i], c = bits.Add64(mid[i], p[i]^mask, c)
+	}
+	mid[2*m] += mask + c
+
+	// z += mid·B; the sum fits, so the last carry is zero
+	c = 0
+	for i := range 2*m + 1 {
+		z[h+i], c = bits.Add64(z[h+i], mid[i], c)
+	}
+	for i := h + 2*m + 1; i < 2*n; i++ {
+		z[i], c = bits.Add64(z[i], 0, c)
+	}
+}
+
+// subAbs sets d = |a − b|, where b and d have the same length and a is at
+// most as long, and returns 1 if a < b and 0 otherwise, without branching.
+func subAbs(d, a, b []uint64) uint64 {
+	var borrow uint64
+	for i := range d {
+		var ai uint64
+		if i < len(a) {
+			ai = a[i]
+		}
+		d[i], borrow = bits.Sub64(ai, b[i], borrow)
+	}
+	// Negate a negative difference: ^d + 1
+	mask := ctMask(borrow)
+	c := borrow
+	for i := range d {
+		d[i], c = bits.Add64(d[i]^mask, 0, c)
+	}
+	return borrow
+}
+
+// mulSchoolbook sets z = x·y, where z has len(x)+len(y) limbs and must not
+// overlap x or y.
+func mulSchoolbook(z, x, y []uint64) {
+	clear(z)
+	for i, yi := range y {
+		var c uint64
+		for j, xj := range x {
+			hi, lo := bits.Mul64(xj, yi)
+			lo, cc := bits.Add64(lo, z[i+j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			z[i+j] = lo
+			c = hi
+		}
+		z[i+len(x)] = c
+	}
+}
+
+// redcKaratsuba is redc by KAMM, storing the result in z, which may be x or
+// y. Operands that montMulWordsKaratsuba cannot take, negative, wider than
+// R, or with a product of N·R or more, take redcBigWordsInto instead.
+func (m *MontgomeryCIOSWords) redcKaratsuba(z, x, y *big.Int) *big.Int {
+	s := m.S
+	if x.Sign() < 0 || y.Sign() < 0 || x.BitLen() > 64*s || y.BitLen() > 64*s ||
+		(x.Cmp(m.N) >= 0 && y.Cmp(m.N) >= 0) {
+		return m.redcBigWordsInto(z, x, y)
+	}
+	buf := make([]uint64, 3*s+kammScratch(s))
+	xs, ys, zs := buf[:s], buf[s:2*s], buf[2*s:3*s]
+	limbsPaddedInto(xs, x)
+	limbsPaddedInto(ys, y)
+	montMulWordsKaratsuba(zs, xs, ys, m.nw, m.NI, buf[3*s:])
+	return z.Set(tobigInt(zs))
+}
@@ -0,0 +1,180 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"testing"
+	"testing/quick"
+)
+
+func TestMulKaratsuba(t *testing.T) {
+	t.Parallel()
+
+	// Odd and even lengths around the base case, and lengths that recurse
+	// through both m = h and m = h+1 splits
+	for _, n := range []int{1, 15, 16, 17, 31, 33, 47, 64, 65, 100, 128} {
+		t.Run(fmt.Sprintf("limbs=%d", n), func(t *testing.T) {
+			t.Parallel()
+			scratch := make([]uint64, karatsubaScratch(n))
+			check := func(x, y []uint64) bool {
+				z := make([]uint64, 2*n)
+				mulKaratsuba(z, x, y, scratch)
+				want := new(big.Int).Mul(tobigInt(x), tobigInt(y))
+				return tobigInt(z).Cmp(want) == 0
+			}
+
+			ones := slices.Repeat([]uint64{^uint64(0)}, n)
+			zero := make([]uint64, n)
+			// all ones maximizes every carry; zero halves make the
+			// differences vanish, and one-sided halves fix their signs
+			lowOnly, highOnly := make([]uint64, n), make([]uint64, n)
+			copy(lowOnly, ones[:n/2])
+			copy(highOnly[n/2:], ones)
+			for _, c := range [][2][]uint64{
+				{ones, ones}, {ones, zero}, {lowOnly, highOnly},
+				{highOnly, lowOnly}, {lowOnly, lowOnly}, {highOnly, highOnly},
+			} {
+				if !check(c[0], c[1]) {
+					t.Errorf("%x · %x wrong", c[0], c[1])
+				}
+			}
+			err := quick.Check(func(xBytes, yBytes [1024]byte) bool {
+				x := limbsPadded(new(big.Int).SetBytes(xBytes[:8*n]), n)
+				y := limbsPadded(new(big.Int).SetBytes(yBytes[:8*n]), n)
+				return check(x, y)
+			}, &quick.Config{MaxCount: 50})
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestMontMulWordsKaratsuba(t *testing.T) {
+	t.Parallel()
+
+	for _, bitSize := range []int{64, 1024, 2048, 4096} {
+		_, _, R, random := testParamsLarge(bitSize)
+		high := new(big.Int).Sub(R, big.NewInt(1))
+		for _, N := range []*big.Int{random, high} {
+			ref := NewMontgomeryCIOSWords(R, N)
+			s := ref.S
+			n := limbsPadded(N, s)
+			scratch := make([]uint64, kammScratch(s))
+			check := func(x, y []uint64, alias bool) bool {
+				want := make([]uint64, s)
+				montMulSlice(want, x, y, n, ref.NI, make([]uint64, s+2))
+				z := make([]uint64, s)
+				if alias {
+					z = slices.Clone(x)
+					x = z
+				}
+				montMulWordsKaratsuba(z, x, y, n, ref.NI, scratch)
+				return slices.Equal(z, want)
+			}
+
+			nMinus1 := limbsPadded(new(big.Int).Sub(N, big.NewInt(1)), s)
+			if !check(nMinus1, nMinus1, false) || !check(nMinus1, nMinus1, true) {
+				t.Errorf("%d bits, N = %x: differs from montMulSlice on N-1", bitSize, N)
+			}
+			err := quick.Check(func(xBytes, yBytes []byte, alias bool) bool {
+				x := new(big.Int).Mod(new(big.Int).SetBytes(xBytes), N)
+				y := new(big.Int).Mod(new(big.Int).SetBytes(yBytes), N)
+				return check(limbsPadded(x, s), limbsPadded(y, s), alias)
+			}, &quick.Config{MaxCount: 50})
+			if err != nil {
+				t.Errorf("%d bits, N = %x: %v", bitSize, N, err)
+			}
+		}
+	}
+}
+
+func TestMontgomeryCIOSWords_karatsuba(t *testing.T) {
+	t.Parallel()
+
+	for _, bitSize := range []int{128, 2048} {
+		x, y, R, N := testParamsLarge(bitSize)
+		m := NewMontgomeryCIOSWords(R, N, WithKaratsuba(1))
+		if !m.kamm {
+			t.Fatalf("%d bits: KAMM not selected", bitSize)
+		}
+		e := new(big.Int).Rsh(y, 1)
+		wantMul := new(big.Int).Mod(new(big.Int).Mul(x, y), N)
+		wantExp := new(big.Int).Exp(x, e, N)
+
+		if got := m.Mul(x, y); got.Cmp(wantMul) != 0 {
+			t.Errorf("%d bits: Mul = %x, want %x", bitSize, got, wantMul)
+		}
+		if got := m.MulInto(new(big.Int), x, y); got.Cmp(wantMul) != 0 {
+			t.Errorf("%d bits: MulInto = %x, want %x", bitSize, got, wantMul)
+		}
+		z := make([]uint64, m.S)
+		m.MulWords(z, m.ToWords(x), m.ToWords(y))
+		if got := m.FromWords(z); got.Cmp(wantMul) != 0 {
+			t.Errorf("%d bits: MulWords = %x, want %x", bitSize, got, wantMul)
+		}
+		for name, exp := range map[string]func(base, exp *big.Int) *big.Int{
+			"Exp":             m.Exp,
+			"ExpConstantTime": m.ExpConstantTime,
+			"ExpLadder":       m.ExpLadder,
+			"FixedBase": func(base, exp *big.Int) *big.Int {
+				return m.NewFixedBase(base, exp.BitLen(), 4).Exp(exp)
+			},
+		} {
+			if got := exp(x, e); got.Cmp(wantExp) != 0 {
+				t.Errorf("%d bits: %s = %x, want %x", bitSize, name, got, wantExp)
+			}
+		}
+		// Operands at or above N, which KAMM hands to CIOS
+		if got := m.redc(N, N); got.Cmp(new(big.Int)) != 0 {
+			t.Errorf("%d bits: redc(N, N) = %x, want 0", bitSize, got)
+		}
+	}
+}
+
+func Test_useKaratsuba(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		s, threshold int
+		want         bool
+	}{
+		{s: 4, threshold: 1, want: true},
+		{s: 32, threshold: 33, want: false},
+		{s: 33, threshold: 33, want: true},
+		{s: 1024, threshold: -1, want: false},
+		{s: karatsubaThreshold - 1, threshold: 0, want: false},
+		{s: karatsubaThreshold, threshold: 0, want: !hasMulAccAsm},
+	}
+	for _, tt := range tests {
+		if got := useKaratsuba(tt.s, tt.threshold); got != tt.want {
+			t.Errorf("useKaratsuba(%d, %d) = %v, want %v", tt.s, tt.threshold, got, tt.want)
+		}
+	}
+	if cfg := newConfig([]Option{WithKaratsuba(0)}); cfg.karatsuba >= 0 {
+		t.Errorf("WithKaratsuba(0) left threshold %d, want it off", cfg.karatsuba)
+	}
+}
+
+func BenchmarkKaratsuba(b *testing.B) {
+	for _, bits := range []int{1024, 2048, 3072, 4096, 8192, 16384} {
+		x, y, R, N := testParamsLarge(bits)
+		m := NewMontgomeryCIOSWords(R, N)
+		s := m.S
+		z, ys := limbsPadded(x, s), limbsPadded(y, s)
+
+		t := make([]uint64, s+2)
+		b.Run(fmt.Sprintf("bits=%d/impl=cios", bits), func(b *testing.B) {
+			for b.Loop() {
+				montMulWords(z, z, ys, m.nw, m.NI, t)
+			}
+		})
+		u := make([]uint64, kammScratch(s))
+		b.Run(fmt.Sprintf("bits=%d/impl=kamm", bits), func(b *testing.B) {
+			for b.Loop() {
+				montMulWordsKaratsuba(z, z, ys, m.nw, m.NI, u)
+			}
+		})
+	}
+}
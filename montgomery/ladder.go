@@ -0,0 +1,81 @@
+package montgomery
+
+import "math/big"
+
+// ExpLadder computes base^exp mod N with the Montgomery ladder, for secret
+// exponents.
+//
+// The ladder keeps r0 = base^k and r1 = base^(k+1) for the exponent prefix
+// k read so far, and every bit costs exactly one multiplication r0·r1 and
+// one squaring, whichever bit it is; the bit only decides which register
+// receives which result, and that is done by masked swaps rather than
+// branches. There is no table, so unlike ExpConstantTime nothing depends on
+// memory access patterns either, at the price of a multiplication per bit
+// instead of one per 5-bit window: about 1.4x the time of ExpConstantTime.
+// Like it, the ladder walks max(exp.BitLen(), 64·S) bits so that exponents
+// below R share one schedule, and the base is treated as public; one
+// outside [0, N) is handled by the InputPolicy. A negative exponent raises
+// the inverse of the base, as with ExpConstantTime. With
+// WithExponentBlinding the exponent is randomized first.
+func (m *MontgomeryCIOSWords) ExpLadder(base, exp *big.Int) *big.Int {
+	base = m.cfg.input.mustOperand(m.N, "ExpLadder", base)
+	if base, exp = invertBase(m.N, base, exp); base == nil {
+		return nil
+	}
+	return m.expLadder(base, m.cfg.blind.apply(exp), nil)
+}
+
+// expLadder is ExpLadder with every operation reported to rec, which may
+// be nil. Trace uses it so the recorded schedule is the real one.
+func (m *MontgomeryCIOSWords) expLadder(base, exp *big.Int, rec *recorder) *big.Int {
+	base = m.cfg.input.mustOperand(m.N, "ExpLadder", base)
+	e := limbsPadded(exp, (exp.BitLen()+63)/64)
+	return tobigInt(m.expLadderWords(limbsPadded(base, m.S), e, max(exp.BitLen(), 64*m.S), rec))
+}
+
+// expLadderWords is the limb-level core of expLadder: base is S limbs in
+// [0, N) and exp is scanned over nbits bits, which must cover all of its
+// set bits. The result is a fresh slice of S limbs.
+func (m *MontgomeryCIOSWords) expLadderWords(base, exp []uint64, nbits int, rec *recorder) []uint64 {
+	s := m.S
+	mul := m.mulWordsKernel()
+	t := make([]uint64, s+2)
+	n := limbsPadded(m.N, s)
+	rr := limbsPadded(m.RR, s)
+	one := limbsPadded(big.NewInt(1), s)
+
+	// Invariant: r1 = r0 * base
+	r0 := make([]uint64, s)
+	montMulWords(r0, one, rr, n, m.NI, t)
+	rec.mul(OpMultiply, s)
+	r1 := make([]uint64, s)
+	mul(r1, base, rr, n, m.NI, t)
+	rec.mul(OpMultiply, s)
+
+	for i := nbits - 1; i >= 0; i-- {
+		mask := ctMask(limbBit(exp, i))
+		ctSwap(r0, r1, mask)
+		rec.swap(s)
+		mul(r1, r0, r1, n, m.NI, t)
+		rec.mul(OpMultiply, s)
+		mul(r0, r0, r0, n, m.NI, t)
+		rec.mul(OpSquare, s)
+		ctSwap(r0, r1, mask)
+		rec.swap(s)
+	}
+
+	// Convert back from Montgomery form, with the full reduction that also
+	// makes an almost-Montgomery result canonical
+	montMulWords(r0, r0, one, n, m.NI, t)
+	rec.mul(OpMultiply, s)
+	return r0
+}
+
+// ExpLadderWords sets z = base^exp mod N with the Montgomery ladder of
+// ExpLadder. base and z are S limbs, base in [0, N), and exp is any number
+// of limbs; only len(exp) is revealed, never its bits. z may be base.
+func (m *MontgomeryCT) ExpLadderWords(z, base, exp []uint64) {
+	m.checkLen("ExpLadderWords", z, base)
+	exp = m.cfg.blind.applyWords(exp)
+	copy(z, m.w.expLadderWords(base, exp, max(64*len(exp), 64*m.S), nil))
+}
@@ -0,0 +1,134 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"testing"
+	"testing/quick"
+)
+
+func TestMontgomeryCIOSWords_ExpLadder(t *testing.T) {
+	t.Parallel()
+
+	x2048, _, R2048, N2048 := testParams2048()
+	xAMM, _, RAMM, NAMM := testParamsAMM()
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+	R64 := new(big.Int).Lsh(big.NewInt(1), 64)
+
+	tests := []struct {
+		name string
+		base *big.Int
+		exp  *big.Int
+		R, N *big.Int
+		opts []Option
+	}{
+		{"2^10 mod N64", big.NewInt(2), big.NewInt(10), R64, N64, nil},
+		{"exp=0", big.NewInt(12345), big.NewInt(0), R64, N64, nil},
+		{"exp=1", big.NewInt(12345), big.NewInt(1), R64, N64, nil},
+		{"base=0", big.NewInt(0), big.NewInt(7), R64, N64, nil},
+		{"base >= N", new(big.Int).Add(N64, big.NewInt(3)), big.NewInt(5), R64, N64, nil},
+		{"exponent wider than R", big.NewInt(3), new(big.Int).Lsh(big.NewInt(1), 100), R64, N64, nil},
+		{"2048-bit", x2048, new(big.Int).Sub(N2048, big.NewInt(1)), R2048, N2048, nil},
+		{"almost Montgomery", xAMM, new(big.Int).Sub(NAMM, big.NewInt(1)), RAMM, NAMM, []Option{WithAlmostMontgomery()}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			m := NewMontgomeryCIOSWords(tc.R, tc.N, tc.opts...)
+			want := new(big.Int).Exp(tc.base, tc.exp, tc.N)
+			if got := m.ExpLadder(tc.base, tc.exp); got.Cmp(want) != 0 {
+				t.Errorf("ExpLadder() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMontgomeryCIOSWords_ExpLadderProperty(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParamsLarge(256)
+	m := NewMontgomeryCIOSWords(R, N)
+	f := func(b, e uint64, shift uint8) bool {
+		base := new(big.Int).Lsh(new(big.Int).SetUint64(b), uint(shift))
+		exp := new(big.Int).Lsh(new(big.Int).SetUint64(e), uint(shift))
+		return m.ExpLadder(base, exp).Cmp(new(big.Int).Exp(base, exp, N)) == 0
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMontgomeryCIOSWords_ExpLadderStrict(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N, WithInputPolicy(InputStrict))
+	if !panics(func() { m.ExpLadder(N, big.NewInt(3)) }) {
+		t.Error("ExpLadder(N, 3) under InputStrict did not panic with ErrOperandRange")
+	}
+}
+
+// TestMontgomeryCIOSWords_ExpLadderSchedule checks that the operation
+// sequence does not depend on the exponent bits.
+func TestMontgomeryCIOSWords_ExpLadderSchedule(t *testing.T) {
+	t.Parallel()
+
+	x, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	ops := func(e *big.Int) []Op {
+		var ops []Op
+		for _, ev := range m.Trace(StrategyLadder, x, e).Events {
+			ops = append(ops, ev.Op)
+		}
+		return ops
+	}
+	if !slices.Equal(ops(big.NewInt(1)), ops(new(big.Int).Sub(N, big.NewInt(1)))) {
+		t.Error("ladder schedules differ for exponents 1 and N-1")
+	}
+}
+
+func TestMontgomeryCT_ExpLadderWords(t *testing.T) {
+	t.Parallel()
+
+	x, _, R, N := testParams2048()
+	m := NewMontgomeryCT(R, N)
+	for _, exp := range []*big.Int{big.NewInt(0), big.NewInt(65537), new(big.Int).Sub(N, big.NewInt(2))} {
+		want := new(big.Int).Exp(x, exp, N)
+		// z aliasing base
+		z := limbsPadded(x, m.S)
+		m.ExpLadderWords(z, z, limbsPadded(exp, (exp.BitLen()+63)/64))
+		if got := tobigInt(z); got.Cmp(want) != 0 {
+			t.Errorf("ExpLadderWords(x, %v) = %v, want %v", exp, got, want)
+		}
+	}
+
+	defer func() {
+		if err, _ := recover().(string); err == "" {
+			t.Error("ExpLadderWords with short operand did not panic")
+		}
+	}()
+	m.ExpLadderWords(make([]uint64, m.S-1), make([]uint64, m.S), []uint64{3})
+}
+
+func BenchmarkExpLadder(b *testing.B) {
+	base, _, R, N := testParams2048()
+	exp := new(big.Int).Sub(N, big.NewInt(1))
+	m := NewMontgomeryCIOSWords(R, N)
+
+	for _, bc := range []struct {
+		impl string
+		f    func(base, exp *big.Int) *big.Int
+	}{
+		{"ExpLadder", m.ExpLadder},
+		{"ExpConstantTime", m.ExpConstantTime},
+		{"Exp", m.Exp},
+	} {
+		b.Run(fmt.Sprintf("impl=%s", bc.impl), func(b *testing.B) {
+			for b.Loop() {
+				bc.f(base, exp)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// SmallModuli multiplies many independent pairs, each under its own odd
+// modulus below 2^64: the residues of an RNS base, the primes of a batch
+// CRT, a sieve's small primes. Every modulus n gets its Montgomery
+// constants for R = 2^64, and element i of every operand slice belongs to
+// modulus i.
+//
+// A one-word REDC is a short dependent chain of three multiplications, so
+// a loop over one modulus at a time leaves the multiplier idle while each
+// chain resolves. The kernel instead runs laneWidth moduli per iteration in
+// straight-line code, the word-parallel analogue of SIMD lanes: the chains
+// are independent, and the processor overlaps them.
+//
+// Operands must be below their modulus and are not checked. Outputs may be
+// the same slice as any input. A SmallModuli is safe for concurrent use.
+type SmallModuli struct {
+	n, ni, rr, one []uint64
+}
+
+// laneWidth is the number of moduli the kernel handles per iteration.
+// Eight measures slightly faster than four on amd64, and about 1.5 times
+// as fast as one.
+const laneWidth = 8
+
+// NewSmallModuli precomputes the constants of every modulus. It returns an
+// error wrapping ErrEvenModulus or ErrModulusTooSmall, with the index of
+// the first modulus that is even or at most 1.
+func NewSmallModuli(moduli []uint64) (*SmallModuli, error) {
+	for i, n := range moduli {
+		switch {
+		case n <= 1:
+			return nil, fmt.Errorf("%w: modulus %d", ErrModulusTooSmall, i)
+		case n&1 == 0:
+			return nil, fmt.Errorf("%w: modulus %d", ErrEvenModulus, i)
+		}
+	}
+	return newSmallModuli(append([]uint64(nil), moduli...)), nil
+}
+
+// newSmallModuli assembles a SmallModuli for odd moduli above 1, keeping
+// the slice.
+func newSmallModuli(moduli []uint64) *SmallModuli {
+	k := len(moduli)
+	b := &SmallModuli{
+		n:   moduli,
+		ni:  make([]uint64, k),
+		rr:  make([]uint64, k),
+		one: make([]uint64, k),
+	}
+	for i, n := range moduli {
+		b.ni[i] = WordInverse(n, 64)
+		r := bits.Rem64(1, 0, n) // 2^64 mod n
+		hi, lo := bits.Mul64(r, r)
+		b.rr[i] = bits.Rem64(hi, lo, n)
+		b.one[i] = 1
+	}
+	return b
+}
+
+// Len returns the number of moduli.
+func (b *SmallModuli) Len() int { return len(b.n) }
+
+// Moduli returns a copy of the moduli.
+func (b *SmallModuli) Moduli() []uint64 { return append([]uint64(nil), b.n...) }
+
+// Mul sets z[i] = x[i]·y[i] mod n[i] for every modulus, with two REDCs
+// each, since REDC(REDC(x, y), R²) = x·y.
+func (b *SmallModuli) Mul(z, x, y []uint64) {
+	b.checkLen("Mul", z, x, y)
+	redcLanes(z, x, y, b.n, b.ni)
+	redcLanes(z, z, b.rr, b.n, b.ni)
+}
+
+// MulMont sets z[i] = x[i]·y[i]·2⁻⁶⁴ mod n[i], the product of values in
+// Montgomery form, with a single REDC each.
+func (b *SmallModuli) MulMont(z, x, y []uint64) {
+	b.checkLen("MulMont", z, x, y)
+	redcLanes(z, x, y, b.n, b.ni)
+}
+
+// ToMont sets z[i] = x[i]·2⁶⁴ mod n[i], x in Montgomery form.
+func (b *SmallModuli) ToMont(z, x []uint64) {
+	b.checkLen("ToMont", z, x)
+	redcLanes(z, x, b.rr, b.n, b.ni)
+}
+
+// FromMont sets z[i] = x[i]·2⁻⁶⁴ mod n[i], x out of Montgomery form.
+func (b *SmallModuli) FromMont(z, x []uint64) {
+	b.checkLen("FromMont", z, x)
+	redcLanes(z, x, b.one, b.n, b.ni)
+}
+
+// checkLen panics unless every slice has one element per modulus.
+func (b *SmallModuli) checkLen(op string, xs ...[]uint64) {
+	for _, x := range xs {
+		if len(x) != len(b.n) {
+			panic(fmt.Sprintf("montgomery: SmallModuli.%s: operand has %d elements, want %d", op, len(x), len(b.n)))
+		}
+	}
+}
+
+// redcLanes sets z[i] = x[i]·y[i]·2⁻⁶⁴ mod n[i], laneWidth moduli per
+// iteration and the remainder one at a time. All slices have len(n)
+// elements; z may alias x or y element for element.
+func redcLanes(z, x, y, n, ni []uint64) {
+	k := len(n)
+	z, x, y, ni = z[:k], x[:k], y[:k], ni[:k]
+	i := 0
+	for ; i+laneWidth <= k; i += laneWidth {
+		// Fixed-length windows let the compiler drop the bounds checks
+		zl := z[i : i+laneWidth : i+laneWidth]
+		xl := x[i : i+laneWidth : i+laneWidth]
+		yl := y[i : i+laneWidth : i+laneWidth]
+		nl := n[i : i+laneWidth : i+laneWidth]
+		il := ni[i : i+laneWidth : i+laneWidth]
+		z0 := redc1(xl[0], yl[0], nl[0], il[0])
+		z1 := redc1(xl[1], yl[1], nl[1], il[1])
+		z2 := redc1(xl[2], yl[2], nl[2], il[2])
+		z3 := redc1(xl[3], yl[3], nl[3], il[3])
+		z4 := redc1(xl[4], yl[4], nl[4], il[4])
+		z5 := redc1(xl[5], yl[5], nl[5], il[5])
+		z6 := redc1(xl[6], yl[6], nl[6], il[6])
+		z7 := redc1(xl[7], yl[7], nl[7], il[7])
+		zl[0], zl[1], zl[2], zl[3] = z0, z1, z2, z3
+		zl[4], zl[5], zl[6], zl[7] = z4, z5, z6, z7
+	}
+	for ; i < k; i++ {
+		z[i] = redc1(x[i], y[i], n[i], ni[i])
+	}
+}
+
+// redc1 returns x·y·2⁻⁶⁴ mod n for y < n < 2^64 odd, any x and ni = -n⁻¹
+// mod 2^64, without branches. The sum x·y + m·n can exceed 2^128 when n ≥
+// 2^63, so the carry out of the high word takes part in the final
+// subtraction.
+func redc1(x, y, n, ni uint64) uint64 {
+	hi, lo := bits.Mul64(x, y)
+	mh, ml := bits.Mul64(lo*ni, n)
+	_, c := bits.Add64(lo, ml, 0) // zero, with a carry unless lo is
+	t, top := bits.Add64(hi, mh, c)
+	s, borrow := bits.Sub64(t, n, 0)
+	// t + top·2^64 ≥ n iff the top bit is set or the subtraction did not
+	// borrow
+	keep := ctMask(top | (borrow ^ 1))
+	return s&keep | t&^keep
+}
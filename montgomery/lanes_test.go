@@ -0,0 +1,233 @@
+package montgomery
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"testing/quick"
+)
+
+// testSmallModuli returns k odd moduli below 2^bits with operands below
+// them.
+func testSmallModuli(k, bits int) (moduli, x, y []uint64) {
+	rng := rand.New(rand.NewPCG(uint64(k), uint64(bits)))
+	moduli, x, y = make([]uint64, k), make([]uint64, k), make([]uint64, k)
+	for i := range moduli {
+		n := rng.Uint64()>>(64-bits) | 1<<(bits-1) | 1
+		moduli[i] = n
+		x[i], y[i] = rng.Uint64N(n), rng.Uint64N(n)
+	}
+	return moduli, x, y
+}
+
+func TestSmallModuli(t *testing.T) {
+	t.Parallel()
+
+	mixed, _, _ := testSmallModuli(15, 64)
+	mixed = append(mixed, 3, 1<<63+1, 1<<64-59, 65537)
+	tests := []struct {
+		name   string
+		moduli []uint64
+	}{
+		{"none", nil},
+		{"one", []uint64{1<<61 - 1}},
+		{"one short of a lane", []uint64{3, 5, 7, 9, 11, 13, 15}},
+		{"top bit set", []uint64{1<<64 - 1, 1<<64 - 59, 1<<63 + 1, 1<<63 + 3, 1<<64 - 83}},
+		{"mixed sizes", mixed},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			b, err := NewSmallModuli(tc.moduli)
+			if err != nil {
+				t.Fatal(err)
+			}
+			k := b.Len()
+			rng := rand.New(rand.NewPCG(uint64(k), 1))
+			operand := func() []uint64 {
+				x := make([]uint64, k)
+				for i, n := range tc.moduli {
+					x[i] = rng.Uint64N(n)
+				}
+				return x
+			}
+			// The largest operands take the longest carry chains
+			top := make([]uint64, k)
+			for i, n := range tc.moduli {
+				top[i] = n - 1
+			}
+			for _, xy := range [][2][]uint64{{top, top}, {operand(), operand()}, {operand(), top}} {
+				x, y := xy[0], xy[1]
+				z := make([]uint64, k)
+				b.Mul(z, x, y)
+				for i, n := range tc.moduli {
+					want := new(big.Int).Mul(new(big.Int).SetUint64(x[i]), new(big.Int).SetUint64(y[i]))
+					want.Mod(want, new(big.Int).SetUint64(n))
+					if z[i] != want.Uint64() {
+						t.Errorf("Mul: %d·%d mod %d = %d, want %d", x[i], y[i], n, z[i], want)
+					}
+				}
+				// The same product through Montgomery form, in place
+				xm, ym := slices.Clone(x), slices.Clone(y)
+				b.ToMont(xm, xm)
+				b.ToMont(ym, ym)
+				b.MulMont(xm, xm, ym)
+				b.FromMont(xm, xm)
+				if !slices.Equal(xm, z) {
+					t.Errorf("ToMont, MulMont, FromMont = %v, want %v", xm, z)
+				}
+			}
+		})
+	}
+}
+
+func TestSmallModuli_property(t *testing.T) {
+	t.Parallel()
+
+	moduli, _, _ := testSmallModuli(13, 64)
+	b, err := NewSmallModuli(moduli)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = quick.Check(func(x, y [13]uint64) bool {
+		for i, n := range moduli {
+			x[i] %= n
+			y[i] %= n
+		}
+		z := make([]uint64, len(moduli))
+		b.Mul(z, x[:], y[:])
+		for i, n := range moduli {
+			want := new(big.Int).Mul(new(big.Int).SetUint64(x[i]), new(big.Int).SetUint64(y[i]))
+			if z[i] != want.Mod(want, new(big.Int).SetUint64(n)).Uint64() {
+				return false
+			}
+		}
+		return true
+	}, &quick.Config{MaxCount: 500})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewSmallModuli_errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		moduli  []uint64
+		wantErr error
+	}{
+		{"even", []uint64{3, 5, 8}, ErrEvenModulus},
+		{"one", []uint64{1, 3}, ErrModulusTooSmall},
+		{"zero", []uint64{0}, ErrModulusTooSmall},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := NewSmallModuli(tc.moduli); !errors.Is(err, tc.wantErr) || !errors.Is(err, ErrInvalidParameters) {
+				t.Errorf("NewSmallModuli(%v) error = %v, want %v", tc.moduli, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSmallModuli_ownership(t *testing.T) {
+	t.Parallel()
+
+	moduli := []uint64{3, 5, 7}
+	b, err := NewSmallModuli(moduli)
+	if err != nil {
+		t.Fatal(err)
+	}
+	moduli[0] = 9
+	got := b.Moduli()
+	got[1] = 11
+	if want := []uint64{3, 5, 7}; !slices.Equal(b.Moduli(), want) {
+		t.Errorf("Moduli() = %v after modifying the argument and a result, want %v", b.Moduli(), want)
+	}
+}
+
+func TestSmallModuli_length(t *testing.T) {
+	t.Parallel()
+
+	b, err := NewSmallModuli([]uint64{3, 5, 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	short, ok := make([]uint64, 2), make([]uint64, 3)
+	for name, f := range map[string]func(){
+		"Mul":      func() { b.Mul(ok, ok, short) },
+		"MulMont":  func() { b.MulMont(short, ok, ok) },
+		"ToMont":   func() { b.ToMont(ok, short) },
+		"FromMont": func() { b.FromMont(short, ok) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s with a short operand did not panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+// BenchmarkSmallModuli compares the lane kernel with one modulus per
+// iteration, with a MontgomeryCIOSWords context per modulus, and with
+// math/big, for 1024 independent products.
+func BenchmarkSmallModuli(b *testing.B) {
+	const k = 1024
+	for _, bits := range []int{32, 64} {
+		moduli, x, y := testSmallModuli(k, bits)
+		s, err := NewSmallModuli(moduli)
+		if err != nil {
+			b.Fatal(err)
+		}
+		z := make([]uint64, k)
+		b.Run(fmt.Sprintf("bits=%d/impl=lanes", bits), func(b *testing.B) {
+			for b.Loop() {
+				s.MulMont(z, x, y)
+			}
+		})
+		b.Run(fmt.Sprintf("bits=%d/impl=scalar", bits), func(b *testing.B) {
+			for b.Loop() {
+				for i := range moduli {
+					z[i] = redc1(x[i], y[i], s.n[i], s.ni[i])
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("bits=%d/impl=lanes/op=mul", bits), func(b *testing.B) {
+			for b.Loop() {
+				s.Mul(z, x, y)
+			}
+		})
+		R := new(big.Int).Lsh(big.NewInt(1), 64)
+		ctxs := make([]*MontgomeryCIOSWords, k)
+		xs, ys := make([]*big.Int, k), make([]*big.Int, k)
+		for i, n := range moduli {
+			ctxs[i] = NewMontgomeryCIOSWords(R, new(big.Int).SetUint64(n))
+			xs[i], ys[i] = new(big.Int).SetUint64(x[i]), new(big.Int).SetUint64(y[i])
+		}
+		b.Run(fmt.Sprintf("bits=%d/impl=cioswords/op=mul", bits), func(b *testing.B) {
+			zb := new(big.Int)
+			for b.Loop() {
+				for i, m := range ctxs {
+					m.MulInto(zb, xs[i], ys[i])
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("bits=%d/impl=bigint/op=mul", bits), func(b *testing.B) {
+			zb, nb := new(big.Int), new(big.Int)
+			for b.Loop() {
+				for i, n := range moduli {
+					zb.Mul(xs[i], ys[i]).Mod(zb, nb.SetUint64(n))
+				}
+			}
+		})
+	}
+}
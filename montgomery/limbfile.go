@@ -0,0 +1,114 @@
+package montgomery
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"unsafe"
+)
+
+// ErrMapUnsupported is returned by CreateLimbFile and OpenLimbFile on
+// platforms without memory-mapped files.
+var ErrMapUnsupported = errors.New("montgomery: memory-mapped limb files are not supported on this platform")
+
+// LimbFile is a file of 64-bit limbs mapped into memory, for operands too
+// large for RAM. Its Limbs are an ordinary []uint64 that the word API, the
+// NTT multiplier and Squarer accept like any other, while the kernel pages
+// them between the file and its cache as they are touched.
+//
+// Limbs are stored in the host's byte order, least significant limb first.
+type LimbFile struct {
+	f      *os.File
+	data   []byte
+	remove bool // the file is scratch, removed on Close
+}
+
+// CreateLimbFile creates or truncates the file at path to n zero limbs and
+// maps it.
+func CreateLimbFile(path string, n int) (*LimbFile, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return newLimbFile(f, n, false)
+}
+
+// OpenLimbFile maps the existing file at path, whose size must be a whole
+// number of limbs, for reading and writing.
+func OpenLimbFile(path string) (*LimbFile, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, errors.Join(err, f.Close())
+	}
+	if fi.Size()%8 != 0 {
+		return nil, errors.Join(fmt.Errorf("montgomery: %s has %d bytes, not a whole number of limbs", path, fi.Size()), f.Close())
+	}
+	return mapLimbFile(f, int(fi.Size()/8), false)
+}
+
+// tempLimbFile creates a scratch LimbFile of n limbs in dir, removed on
+// Close.
+func tempLimbFile(dir string, n int) (*LimbFile, error) {
+	f, err := os.CreateTemp(dir, "montgomery-*.limbs")
+	if err != nil {
+		return nil, err
+	}
+	l, err := newLimbFile(f, n, true)
+	if err != nil {
+		return nil, errors.Join(err, os.Remove(f.Name()))
+	}
+	return l, nil
+}
+
+// newLimbFile sizes the open file f to n limbs and maps it, closing f on
+// failure.
+func newLimbFile(f *os.File, n int, remove bool) (*LimbFile, error) {
+	if err := f.Truncate(int64(n) * 8); err != nil {
+		return nil, errors.Join(err, f.Close())
+	}
+	return mapLimbFile(f, n, remove)
+}
+
+// mapLimbFile maps the n limbs of f, closing f on failure. An empty file
+// is not mapped; its Limbs are nil.
+func mapLimbFile(f *os.File, n int, remove bool) (*LimbFile, error) {
+	l := &LimbFile{f: f, remove: remove}
+	if n > 0 {
+		data, err := mapFile(f, n*8)
+		if err != nil {
+			return nil, errors.Join(err, f.Close())
+		}
+		l.data = data
+	}
+	return l, nil
+}
+
+// Limbs returns the mapped limbs. They stay valid until Close.
+func (l *LimbFile) Limbs() []uint64 {
+	if len(l.data) == 0 {
+		return nil
+	}
+	// mmap returns page-aligned memory, so the cast is aligned
+	return unsafe.Slice((*uint64)(unsafe.Pointer(&l.data[0])), len(l.data)/8)
+}
+
+// Sync flushes the limbs written so far to stable storage.
+func (l *LimbFile) Sync() error { return l.f.Sync() }
+
+// Close unmaps and closes the file, and removes it if it is scratch.
+func (l *LimbFile) Close() error {
+	var err error
+	if l.data != nil {
+		err = unmapFile(l.data)
+		l.data = nil
+	}
+	err = errors.Join(err, l.f.Close())
+	if l.remove {
+		err = errors.Join(err, os.Remove(l.f.Name()))
+	}
+	return err
+}
@@ -0,0 +1,17 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package montgomery
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile maps the first size bytes of f for reading and writing, shared
+// with the file.
+func mapFile(f *os.File, size int) ([]byte, error) {
+	return syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+}
+
+// unmapFile releases a mapping returned by mapFile.
+func unmapFile(data []byte) error { return syscall.Munmap(data) }
@@ -0,0 +1,12 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+
+package montgomery
+
+import "os"
+
+// mapFile reports ErrMapUnsupported: this platform has no mmap the
+// standard library exposes.
+func mapFile(f *os.File, size int) ([]byte, error) { return nil, ErrMapUnsupported }
+
+// unmapFile is never reached, since mapFile maps nothing.
+func unmapFile(data []byte) error { return nil }
@@ -0,0 +1,100 @@
+package montgomery
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// createLimbFile is CreateLimbFile that skips the test where files cannot
+// be mapped.
+func createLimbFile(t *testing.T, path string, n int) *LimbFile {
+	t.Helper()
+	l, err := CreateLimbFile(path, n)
+	if errors.Is(err, ErrMapUnsupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestLimbFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		limbs []uint64
+	}{
+		{"empty", nil},
+		{"one", []uint64{1<<64 - 1}},
+		{"page and a half", make([]uint64, 768)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			for i := range tc.limbs {
+				tc.limbs[i] = uint64(i)*0x9e3779b97f4a7c15 + 1
+			}
+			path := filepath.Join(t.TempDir(), "x.limbs")
+			l := createLimbFile(t, path, len(tc.limbs))
+			if got := l.Limbs(); len(got) != len(tc.limbs) || slices.ContainsFunc(got, func(w uint64) bool { return w != 0 }) {
+				t.Fatalf("new file holds %x, want %d zero limbs", got, len(tc.limbs))
+			}
+			copy(l.Limbs(), tc.limbs)
+			if err := l.Sync(); err != nil {
+				t.Fatal(err)
+			}
+			if err := l.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			l, err := OpenLimbFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer l.Close()
+			if got := l.Limbs(); !slices.Equal(got, tc.limbs) {
+				t.Errorf("reopened file holds %x, want %x", got, tc.limbs)
+			}
+		})
+	}
+}
+
+func TestOpenLimbFile_errors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if _, err := OpenLimbFile(filepath.Join(dir, "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file: err = %v, want os.ErrNotExist", err)
+	}
+	ragged := filepath.Join(dir, "ragged")
+	if err := os.WriteFile(ragged, make([]byte, 12), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenLimbFile(ragged); err == nil {
+		t.Error("12-byte file: no error")
+	}
+}
+
+func TestTempLimbFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	l, err := tempLimbFile(dir, 10)
+	if errors.Is(err, ErrMapUnsupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("scratch file left behind: %v", entries)
+	}
+}
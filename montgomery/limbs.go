@@ -0,0 +1,130 @@
+package montgomery
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+//go:generate go run gen_sized.go
+
+// montMulWords computes z = (x * y * R⁻¹) mod N on fixed-width limbs, where
+// x, y, n and z have exactly s = len(n) words and R = 2^(64*s).
+//
+// This is textbook CIOS with a branch-free tail: the final conditional
+// subtraction is always computed and the result picked with a mask, so the
+// instruction and memory access sequence depends only on s. x and y must be
+// in [0, N) and t is scratch of at least s+2 words. z may be x, y or both
+// (see alias.go); t must not alias any other argument. For 4 and 6 limbs,
+// and 8 where mulAcc has no assembly, it runs the unrolled kernels of
+// sized.go instead, which need no t.
+func montMulWords(z, x, y, n []uint64, ni uint64, t []uint64) {
+	s := len(n)
+	t = t[:s+2]
+	checkInPlace("montMulWords", z, x)
+	checkInPlace("montMulWords", z, y)
+	checkDisjoint("montMulWords", z, n)
+	checkDisjoint("montMulWords", t, z, x, y, n)
+	if useSized(s) && montMulSized(z, x, y, n, ni) {
+		return
+	}
+	montMulSlice(z, x, y, n, ni, t)
+}
+
+// useSized reports whether montMulWords prefers a kernel of sized.go, where
+// there is one for s limbs: at 8 limbs the assembly is faster, by 13% with
+// ADX.
+func useSized(s int) bool {
+	return s < 8 || !hasMulAccAsm
+}
+
+// montMulSlice is montMulWords on the slice-based kernel, for any s.
+func montMulSlice(z, x, y, n []uint64, ni uint64, t []uint64) {
+	s := len(n)
+	t = t[:s+2]
+	montgomeryImpl[uint64]{n, ni}.mulAcc(t, x, y)
+
+	// t < 2N; subtract N when t ≥ N, selected by mask rather than branch.
+	var borrow uint64
+	for j := range s {
+		z[j], borrow = bits.Sub64(t[j], n[j], borrow)
+	}
+	// t ≥ N iff the top word is set or the subtraction did not borrow
+	keep := ctMask(t[s] | (borrow ^ 1))
+	for j := range s {
+		z[j] = z[j]&keep | t[j]&^keep
+	}
+}
+
+// montMulWordsAMM is almost Montgomery multiplication: montMulWords without
+// the final subtraction, so z = x·y·R⁻¹ mod N only up to one extra N, in
+// [0, 2N). It needs 4N ≤ R, which keeps every accumulator below
+// (2N·2N + R·N)/R ≤ 2N: inputs in [0, 2N) then give outputs in [0, 2N), so
+// whole chains run without ever subtracting, and a single reduction on the
+// way out of Montgomery form (montMulWords by 1) makes the result
+// canonical. Aliasing rules are those of montMulWords.
+func montMulWordsAMM(z, x, y, n []uint64, ni uint64, t []uint64) {
+	s := len(n)
+	t = t[:s+2]
+	checkInPlace("montMulWordsAMM", z, x)
+	checkInPlace("montMulWordsAMM", z, y)
+	checkDisjoint("montMulWordsAMM", z, n)
+	checkDisjoint("montMulWordsAMM", t, z, x, y, n)
+	montgomeryImpl[uint64]{n, ni}.mulAcc(t, x, y)
+	copy(z, t[:s])
+}
+
+// ctMask returns all ones if b == 1 and zero if b == 0, without branching.
+func ctMask(b uint64) uint64 {
+	return -(b & 1)
+}
+
+// ctEq returns all ones if a == b and zero otherwise, without branching.
+func ctEq(a, b uint64) uint64 {
+	d := a ^ b
+	// (d | -d) has the top bit set iff d != 0
+	return ((d | -d) >> 63) - 1
+}
+
+// limbsPadded returns x as exactly s little-endian 64-bit limbs.
+// x must satisfy 0 ≤ x < 2^(64*s).
+func limbsPadded(x *big.Int, s int) []uint64 {
+	return limbsPaddedInto(make([]uint64, s), x)
+}
+
+// limbsPaddedInto is limbsPadded writing into dst, all of whose len(dst)
+// limbs are overwritten, so loops can reuse one buffer. It returns dst.
+func limbsPaddedInto(dst []uint64, x *big.Int) []uint64 {
+	words := x.Bits()
+	for i := range dst {
+		dst[i] = limb(words, i)
+	}
+	return dst
+}
+
+// limbsLess reports whether x < y for little-endian limbs of equal length.
+// It returns at the first limb that differs, so it is not constant time.
+func limbsLess(x, y []uint64) bool {
+	for i := len(x) - 1; i >= 0; i-- {
+		if x[i] != y[i] {
+			return x[i] < y[i]
+		}
+	}
+	return false
+}
+
+// limbWords is the number of big.Words in a 64-bit limb: 1, or 2 on
+// platforms where big.Word is 32 bits.
+const limbWords = 64 / bits.UintSize
+
+// limb returns the 64-bit limb i of the little-endian words, or 0 past
+// their end.
+func limb(words []big.Word, i int) uint64 {
+	var v uint64
+	for j := range limbWords {
+		auditBranch("limb: past the end of words")
+		if k := i*limbWords + j; k < len(words) {
+			v |= uint64(words[k]) << (bits.UintSize * j)
+		}
+	}
+	return v
+}
@@ -0,0 +1,130 @@
+package montgomery
+
+import (
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func Test_montMulWords(t *testing.T) {
+	t.Parallel()
+
+	_, _, R2048, N2048 := testParams2048()
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+	R64 := new(big.Int).Lsh(big.NewInt(1), 64)
+
+	tests := []struct {
+		name string
+		R    *big.Int
+		N    *big.Int
+	}{
+		{"64-bit", R64, N64},
+		{"2048-bit", R2048, N2048},
+		{"N shorter than R", new(big.Int).Lsh(big.NewInt(1), 192), N64},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ref := NewMontgomeryCIOSWords(tc.R, tc.N)
+			s := ref.S
+			n := limbsPadded(tc.N, s)
+			scratch := make([]uint64, s+2)
+
+			err := quick.Check(func(xBytes, yBytes []byte, alias bool) bool {
+				x := new(big.Int).SetBytes(xBytes)
+				y := new(big.Int).SetBytes(yBytes)
+				x.Mod(x, tc.N)
+				y.Mod(y, tc.N)
+
+				xx := limbsPadded(x, s)
+				z := make([]uint64, s)
+				if alias {
+					z = xx
+				}
+				montMulWords(z, xx, limbsPadded(y, s), n, ref.NI, scratch)
+				return tobigInt(z).Cmp(ref.redc(x, y)) == 0
+			}, &quick.Config{MaxCount: 200})
+
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// testParamsAMM returns 2048-bit-R parameters with 4N ≤ R, as almost
+// Montgomery multiplication needs.
+func testParamsAMM() (x, y, R, N *big.Int) {
+	x, y, R, N = testParams2048()
+	N = new(big.Int).Rsh(N, 2)
+	N.SetBit(N, 0, 1)
+	return x.Mod(x, N), y.Mod(y, N), R, N
+}
+
+func Test_montMulWordsAMM(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParamsAMM()
+	ref := NewMontgomeryCIOSWords(R, N)
+	s := ref.S
+	n := limbsPadded(N, s)
+	twoN := new(big.Int).Lsh(N, 1)
+	scratch := make([]uint64, s+2)
+
+	// Inputs anywhere in [0, 2N), including the top of the range, give
+	// outputs in [0, 2N) congruent to the exact product
+	top := new(big.Int).Sub(twoN, big.NewInt(1))
+	err := quick.Check(func(xBytes, yBytes []byte, xTop, alias bool) bool {
+		x := new(big.Int).Mod(new(big.Int).SetBytes(xBytes), twoN)
+		y := new(big.Int).Mod(new(big.Int).SetBytes(yBytes), twoN)
+		if xTop {
+			x.Set(top)
+		}
+		xx := limbsPadded(x, s)
+		z := make([]uint64, s)
+		if alias {
+			z = xx
+		}
+		montMulWordsAMM(z, xx, limbsPadded(y, s), n, ref.NI, scratch)
+		got := tobigInt(z)
+		want := ref.redc(new(big.Int).Mod(x, N), new(big.Int).Mod(y, N))
+		return got.Cmp(twoN) < 0 && new(big.Int).Mod(got, N).Cmp(want) == 0
+	}, &quick.Config{MaxCount: 200})
+	if err != nil {
+		t.Error(err)
+	}
+
+	// A long chain never leaves [0, 2N)
+	acc := limbsPadded(top, s)
+	for range 1000 {
+		montMulWordsAMM(acc, acc, acc, n, ref.NI, scratch)
+		if tobigInt(acc).Cmp(twoN) >= 0 {
+			t.Fatal("AMM chain left [0, 2N)")
+		}
+	}
+}
+
+func Test_ctEq(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b uint64
+		want uint64
+	}{
+		{0, 0, ^uint64(0)},
+		{1, 0, 0},
+		{0, 1, 0},
+		{1 << 63, 1 << 63, ^uint64(0)},
+		{1 << 63, 0, 0},
+		{^uint64(0), ^uint64(0), ^uint64(0)},
+		{^uint64(0), 0, 0},
+	}
+
+	for _, tc := range tests {
+		if got := ctEq(tc.a, tc.b); got != tc.want {
+			t.Errorf("ctEq(%#x, %#x) = %#x; want %#x", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package montgomery
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// contextMagic and contextVersion identify the serialized context format:
+//
+//	magic    [4]byte "AVMC"
+//	version  uint8
+//	k        uint32   R = 2^k
+//	NI       uint64   -N⁻¹ mod 2^64
+//	N        [S]uint64
+//	RR       [S]uint64 R² mod N
+//
+// with S = ⌈k/64⌉ and all integers little-endian. Options are not part of
+// the format.
+const (
+	contextMagic   = "AVMC"
+	contextVersion = 1
+	contextHeader  = 4 + 1 + 4 + 8
+)
+
+var (
+	// ErrContextFormat is returned when serialized context data is malformed.
+	ErrContextFormat = errors.New("montgomery: malformed context data")
+	// ErrContextVersion is returned for context data in an unsupported
+	// format version.
+	ErrContextVersion = errors.New("montgomery: unsupported context version")
+)
+
+// MarshalBinary encodes R, N and the precomputed R² mod N and -N⁻¹ mod 2^64
+// in a versioned binary format, so that a process can load them with
+// UnmarshalBinary or Load instead of recomputing them.
+func (m *MontgomeryBitwise) MarshalBinary() ([]byte, error) {
+	return marshalContext(m.R, m.N, m.RR, newtonRaphsonInverse(m.N.Uint64())), nil
+}
+
+// MarshalBinary encodes the context; see MontgomeryBitwise.MarshalBinary.
+func (m *MontgomeryCIOS) MarshalBinary() ([]byte, error) {
+	return marshalContext(m.R, m.N, m.RR, m.NI), nil
+}
+
+// MarshalBinary encodes the context; see MontgomeryBitwise.MarshalBinary.
+func (m *MontgomeryCIOSWords) MarshalBinary() ([]byte, error) {
+	return marshalContext(m.R, m.N, m.RR, m.NI), nil
+}
+
+// MarshalBinary encodes the context; see MontgomeryBitwise.MarshalBinary.
+func (m *MontgomeryCT) MarshalBinary() ([]byte, error) {
+	return marshalContext(m.R, m.N, m.RR, m.NI), nil
+}
+
+// UnmarshalBinary decodes a context produced by any of the MarshalBinary
+// methods, replacing m. Options are not encoded, so m has the defaults;
+// Load takes them as arguments.
+//
+// The data is checked for consistency (sizes, an odd N below R, RR below N
+// and NI matching N), but RR is not recomputed, since that is the cost
+// loading saves; load contexts only from sources as trusted as the code
+// itself.
+func (m *MontgomeryBitwise) UnmarshalBinary(data []byte) error {
+	R, N, rr, _, err := unmarshalContext(data, false)
+	if err != nil {
+		return err
+	}
+	*m = *newMontgomeryBitwise(R, N, rr, nil)
+	return nil
+}
+
+// UnmarshalBinary decodes a context, replacing m; see
+// MontgomeryBitwise.UnmarshalBinary.
+func (m *MontgomeryCIOS) UnmarshalBinary(data []byte) error {
+	R, N, rr, ni, err := unmarshalContext(data, true)
+	if err != nil {
+		return err
+	}
+	*m = *newMontgomeryCIOS(R, N, rr, ni, nil)
+	return nil
+}
+
+// UnmarshalBinary decodes a context, replacing m; see
+// MontgomeryBitwise.UnmarshalBinary.
+func (m *MontgomeryCIOSWords) UnmarshalBinary(data []byte) error {
+	R, N, rr, ni, err := unmarshalContext(data, true)
+	if err != nil {
+		return err
+	}
+	*m = *newMontgomeryCIOSWords(R, N, rr, ni, nil)
+	return nil
+}
+
+// UnmarshalBinary decodes a context, replacing m; see
+// MontgomeryBitwise.UnmarshalBinary.
+func (m *MontgomeryCT) UnmarshalBinary(data []byte) error {
+	R, N, rr, ni, err := unmarshalContext(data, true)
+	if err != nil {
+		return err
+	}
+	*m = *newMontgomeryCT(newMontgomeryCIOSWords(R, N, rr, ni, nil))
+	return nil
+}
+
+// Load constructs the built-in implementation kind from data produced by
+// MarshalBinary, with opts, as New would for the encoded N. The data is
+// checked as by UnmarshalBinary. The encoded R must suit kind: the
+// word-based kinds reject one that is not 2^(64·s) with ErrRNotWordAligned.
+func Load(kind Kind, data []byte, opts ...Option) (ModMultiplier, error) {
+	R, N, rr, ni, err := unmarshalContext(data, kind != KindBitwise)
+	if err != nil {
+		return nil, err
+	}
+	var m ModMultiplier
+	switch kind {
+	case KindBitwise:
+		m = newMontgomeryBitwise(R, N, rr, opts)
+	case KindCIOS:
+		m = newMontgomeryCIOS(R, N, rr, ni, opts)
+	case KindCIOSWords:
+		m = newMontgomeryCIOSWords(R, N, rr, ni, opts)
+	case KindCT:
+		m = newMontgomeryCT(newMontgomeryCIOSWords(R, N, rr, ni, opts))
+	default:
+		return nil, fmt.Errorf("%w %v", ErrUnknownBackend, kind)
+	}
+	// RR is not recomputed, so the self-test is what catches a wrong one
+	if err := selfTest(m, newConfig(opts)); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func marshalContext(R, N, rr *big.Int, ni uint64) []byte {
+	k := R.BitLen() - 1
+	s := (k + 63) / 64
+	buf := make([]byte, 0, contextHeader+16*s)
+	buf = append(buf, contextMagic...)
+	buf = append(buf, contextVersion)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(k))
+	buf = binary.LittleEndian.AppendUint64(buf, ni)
+	for _, x := range []*big.Int{N, rr} {
+		for _, word := range limbsPadded(x, s) {
+			buf = binary.LittleEndian.AppendUint64(buf, word)
+		}
+	}
+	return buf
+}
+
+// unmarshalContext decodes and checks data, returning errors that wrap
+// ErrInvalidParameters for R and N no context accepts, or with
+// wordAligned, for R that is not a whole number of words.
+func unmarshalContext(data []byte, wordAligned bool) (R, N, rr *big.Int, ni uint64, err error) {
+	if len(data) < contextHeader || string(data[:4]) != contextMagic {
+		return nil, nil, nil, 0, ErrContextFormat
+	}
+	if data[4] != contextVersion {
+		return nil, nil, nil, 0, fmt.Errorf("%w: %d", ErrContextVersion, data[4])
+	}
+	k := uint64(binary.LittleEndian.Uint32(data[5:]))
+	ni = binary.LittleEndian.Uint64(data[9:])
+	body := data[contextHeader:]
+	// Compared by division so forged sizes cannot overflow
+	s := (k + 63) / 64
+	if len(body)%16 != 0 || uint64(len(body)/16) != s {
+		return nil, nil, nil, 0, ErrContextFormat
+	}
+
+	words := make([]uint64, 2*s)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(body[8*i:])
+	}
+	R = new(big.Int).Lsh(big.NewInt(1), uint(k))
+	N, rr = tobigInt(words[:s]), tobigInt(words[s:])
+	if err := validateParams(R, N, wordAligned); err != nil {
+		return nil, nil, nil, 0, err
+	}
+	// NI·N ≡ -1 (mod 2^64) needs only the low word of N
+	if rr.Cmp(N) >= 0 || words[0]*ni != ^uint64(0) {
+		return nil, nil, nil, 0, ErrContextFormat
+	}
+	return R, N, rr, ni, nil
+}
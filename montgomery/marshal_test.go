@@ -0,0 +1,218 @@
+package montgomery
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"testing"
+)
+
+// contextCodec is what every serializable context provides.
+type contextCodec interface {
+	ModMultiplier
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+func TestMarshalBinary(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	_, _, R4096, N4096 := testParamsLarge(4096)
+	N255 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	R256 := new(big.Int).Lsh(big.NewInt(1), 256)
+	// The bit-serial REDC takes any R = 2^k
+	Rodd := new(big.Int).Lsh(big.NewInt(1), 2050)
+
+	tests := []struct {
+		name   string
+		m      contextCodec
+		loaded contextCodec
+	}{
+		{"bitwise", NewMontgomeryBitwise(R, N), new(MontgomeryBitwise)},
+		{"bitwise, R not word aligned", NewMontgomeryBitwise(Rodd, N), new(MontgomeryBitwise)},
+		{"cios", NewMontgomeryCIOS(R, N), new(MontgomeryCIOS)},
+		{"cioswords", NewMontgomeryCIOSWords(R, N), new(MontgomeryCIOSWords)},
+		{"cioswords/256", NewMontgomeryCIOSWords(R256, N255), new(MontgomeryCIOSWords)},
+		{"cioswords/4096", NewMontgomeryCIOSWords(R4096, N4096), new(MontgomeryCIOSWords)},
+		{"ct", NewMontgomeryCT(R, N), new(MontgomeryCT)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			data, err := tc.m.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tc.loaded.UnmarshalBinary(data); err != nil {
+				t.Fatalf("UnmarshalBinary() error = %v", err)
+			}
+			n := tc.m.Modulus()
+			if tc.loaded.Modulus().Cmp(n) != 0 {
+				t.Fatalf("loaded Modulus() = %v, want %v", tc.loaded.Modulus(), n)
+			}
+			a, b := new(big.Int).Mod(x, n), new(big.Int).Mod(y, n)
+			if got, want := tc.loaded.Mul(a, b), tc.m.Mul(a, b); got.Cmp(want) != 0 {
+				t.Errorf("loaded Mul() = %v, want %v", got, want)
+			}
+			again, _ := tc.loaded.MarshalBinary()
+			if !bytes.Equal(again, data) {
+				t.Error("re-marshalled context differs from the original encoding")
+			}
+		})
+	}
+}
+
+// TestMarshalBinary_interchangeable loads one encoding into every kind:
+// the precomputed values do not depend on the implementation.
+func TestMarshalBinary_interchangeable(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	data, _ := NewMontgomeryCIOS(R, N).MarshalBinary()
+	want := new(big.Int).Mul(x, y)
+	want.Mod(want, N)
+	e := big.NewInt(65537)
+	wantExp := new(big.Int).Exp(x, e, N)
+
+	var (
+		bitwise MontgomeryBitwise
+		words   MontgomeryCIOSWords
+		ct      MontgomeryCT
+	)
+	for _, u := range []contextCodec{&bitwise, &words, &ct} {
+		if err := u.UnmarshalBinary(data); err != nil {
+			t.Fatalf("%T.UnmarshalBinary() error = %v", u, err)
+		}
+		if got := u.Mul(x, y); got.Cmp(want) != 0 {
+			t.Errorf("%T.Mul() = %v, want %v", u, got, want)
+		}
+	}
+	for _, got := range []*big.Int{bitwise.Exp(x, e), words.Exp(x, e), words.ExpConstantTime(x, e), ct.Exp(x, e)} {
+		if got.Cmp(wantExp) != 0 {
+			t.Errorf("Exp() = %v, want %v", got, wantExp)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	x, _, R, N := testParams2048()
+	data, _ := NewMontgomeryCIOSWords(R, N).MarshalBinary()
+	odd, _ := NewMontgomeryBitwise(new(big.Int).Lsh(R, 1), N).MarshalBinary()
+
+	tests := []struct {
+		name    string
+		kind    Kind
+		data    []byte
+		opts    []Option
+		wantErr error
+	}{
+		{name: "bitwise", kind: KindBitwise, data: data},
+		{name: "cios", kind: KindCIOS, data: data},
+		{name: "cioswords", kind: KindCIOSWords, data: data, opts: []Option{WithInputPolicy(InputStrict)}},
+		{name: "ct", kind: KindCT, data: data, opts: []Option{WithInputPolicy(InputStrict)}},
+		{name: "bitwise, R not word aligned", kind: KindBitwise, data: odd},
+		{name: "cioswords, R not word aligned", kind: KindCIOSWords, data: odd, wantErr: ErrRNotWordAligned},
+		{name: "unknown kind", kind: Kind(99), data: data, wantErr: ErrUnknownBackend},
+		{name: "malformed", kind: KindCIOS, data: data[:10], wantErr: ErrContextFormat},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			m, err := Load(tc.kind, tc.data, tc.opts...)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Load() error = %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			want := new(big.Int).Mul(x, x)
+			if got := m.Mul(x, x); got.Cmp(want.Mod(want, N)) != 0 {
+				t.Errorf("Mul() = %v, want %v", got, want)
+			}
+			// Options apply to the loaded context
+			if len(tc.opts) > 0 && !panics(func() { m.Mul(N, x) }) {
+				t.Error("Mul(N, x) did not panic under InputStrict")
+			}
+		})
+	}
+}
+
+func TestUnmarshalBinary_errors(t *testing.T) {
+	t.Parallel()
+
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+	data, _ := NewMontgomeryCIOSWords(new(big.Int).Lsh(big.NewInt(1), 64), N64).MarshalBinary()
+
+	modify := func(f func(b []byte) []byte) []byte {
+		return f(bytes.Clone(data))
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"empty", nil, ErrContextFormat},
+		{"bad magic", modify(func(b []byte) []byte { b[0] = 'X'; return b }), ErrContextFormat},
+		{"future version", modify(func(b []byte) []byte { b[4] = 2; return b }), ErrContextVersion},
+		{"truncated", data[:len(data)-8], ErrContextFormat},
+		{"trailing byte", append(bytes.Clone(data), 0), ErrContextFormat},
+		{"huge R", modify(func(b []byte) []byte { binary.LittleEndian.PutUint32(b[5:], 1<<32-1); return b }), ErrContextFormat},
+		{"R = 1", modify(func(b []byte) []byte { binary.LittleEndian.PutUint32(b[5:], 0); return b[:contextHeader] }), ErrInvalidParameters},
+		{"R not word aligned", modify(func(b []byte) []byte {
+			// R = 2^100 with N and RR zero-extended to two words
+			binary.LittleEndian.PutUint32(b[5:], 100)
+			h, n, rr := b[:contextHeader], b[contextHeader:contextHeader+8], b[contextHeader+8:]
+			return slices.Concat(h, n, make([]byte, 8), rr, make([]byte, 8))
+		}), ErrRNotWordAligned},
+		{"even N", modify(func(b []byte) []byte { b[contextHeader] ^= 1; return b }), ErrEvenModulus},
+		{"N = 1", modify(func(b []byte) []byte {
+			binary.LittleEndian.PutUint64(b[contextHeader:], 1)
+			return b
+		}), ErrModulusTooSmall},
+		{"RR not below N", modify(func(b []byte) []byte {
+			binary.LittleEndian.PutUint64(b[contextHeader+8:], 1<<64-1)
+			return b
+		}), ErrContextFormat},
+		{"NI does not match N", modify(func(b []byte) []byte { b[9] ^= 2; return b }), ErrContextFormat},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var m MontgomeryCIOSWords
+			if err := m.UnmarshalBinary(tc.data); !errors.Is(err, tc.wantErr) {
+				t.Errorf("UnmarshalBinary() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// BenchmarkLoad compares constructing a context with loading its
+// precomputed values.
+func BenchmarkLoad(b *testing.B) {
+	for _, bits := range []int{2048, 4096} {
+		_, _, R, N := testParamsLarge(bits)
+		data, _ := NewMontgomeryCIOSWords(R, N).MarshalBinary()
+		b.Run(fmt.Sprintf("bits=%d/impl=new", bits), func(b *testing.B) {
+			for b.Loop() {
+				NewMontgomeryCIOSWords(R, N)
+			}
+		})
+		b.Run(fmt.Sprintf("bits=%d/impl=load", bits), func(b *testing.B) {
+			for b.Loop() {
+				if _, err := Load(KindCIOSWords, data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -9,9 +9,27 @@
 //   - MontgomeryBitwise: Basic bit-by-bit REDC algorithm
 //   - MontgomeryCIOS: CIOS algorithm (word-by-word) using big.Int internally
 //   - MontgomeryCIOSWords: CIOS algorithm using []uint64 for better performance
+//   - MontgomeryCT: constant-time CIOS on fixed-size limbs for secret operands
+//
+// Constructors and options copy every big.Int they keep, and every big.Int
+// an operation returns is newly allocated, so callers may modify their
+// arguments and results afterwards without affecting a context. The
+// exported fields (R, N, RR) are the exception: every operation reads them
+// in place, so they must be treated as read-only; Modulus returns a copy of
+// N. Contexts are not modified after construction and are safe for
+// concurrent use: one context may be shared by any number of goroutines.
+// Working memory belongs to the call, from its own allocations, the caller's
+// destination or a package-wide sync.Pool; the only state a context shares
+// between calls is the optional table cache (WithTableCache), which locks.
+//
+// The package does not assume 64-bit words. Where big.Word is 32 bits, the
+// big.Int paths of MontgomeryCIOSWords reduce on 32-bit words with NI mod
+// 2^32, so they multiply with bits.Mul32 rather than an emulated
+// bits.Mul64; the []uint64 limb API keeps 64-bit limbs on every platform.
 package montgomery
 
 import (
+	"fmt"
 	"math/big"
 	"math/bits"
 )
@@ -21,21 +39,48 @@ type MontgomeryBitwise struct {
 	R  *big.Int // R = 2^k
 	N  *big.Int // modulus (must be odd)
 	RR *big.Int // R² mod N (precomputed)
+
+	r1, r3 *big.Int // R mod N and R³ mod N
+	cfg    config
 }
 
 // NewMontgomeryBitwise creates a new MontgomeryBitwise instance with precomputed R² mod N.
-func NewMontgomeryBitwise(R, N *big.Int) *MontgomeryBitwise {
+//
+// R and N are not validated: an even N or an R of the wrong shape yields
+// a context whose results are silently wrong. Parameters not known to be
+// valid belong in NewMontgomeryBitwiseChecked.
+func NewMontgomeryBitwise(R, N *big.Int, opts ...Option) *MontgomeryBitwise {
+	m := newMontgomeryBitwise(new(big.Int).Set(R), new(big.Int).Set(N), rSquared(R, N), opts)
+	mustSelfTest(m, m.cfg)
+	return m
+}
+
+// newMontgomeryBitwise assembles a context from precomputed values, which
+// it keeps without copying.
+func newMontgomeryBitwise(R, N, rr *big.Int, opts []Option) *MontgomeryBitwise {
+	r1, r3 := rPowers(R, N, rr)
+	return &MontgomeryBitwise{R: R, N: N, RR: rr, r1: r1, r3: r3, cfg: newConfig(opts)}
+}
+
+// rSquared returns R² mod N.
+func rSquared(R, N *big.Int) *big.Int {
 	rr := new(big.Int).Mul(R, R)
-	rr = rr.Mod(rr, N)
-	return &MontgomeryBitwise{
-		R:  new(big.Int).Set(R),
-		N:  new(big.Int).Set(N),
-		RR: rr,
-	}
+	return rr.Mod(rr, N)
+}
+
+// rPowers returns R mod N and R³ mod N, given rr = R² mod N.
+func rPowers(R, N, rr *big.Int) (r1, r3 *big.Int) {
+	r1 = new(big.Int).Mod(R, N)
+	r3 = new(big.Int).Mul(rr, r1)
+	return r1, r3.Mod(r3, N)
 }
 
 // Mul computes (x * y) mod N using bit-by-bit Montgomery multiplication.
+// Operands outside [0, N) are handled by the context's InputPolicy.
 func (m *MontgomeryBitwise) Mul(x, y *big.Int) *big.Int {
+	x = m.cfg.input.mustOperand(m.N, "Mul", x)
+	y = m.cfg.input.mustOperand(m.N, "Mul", y)
+
 	// Convert to Montgomery form using precomputed R²
 	xMont := m.redc(x, m.RR)
 	yMont := m.redc(y, m.RR)
@@ -51,42 +96,16 @@ func (m *MontgomeryBitwise) Mul(x, y *big.Int) *big.Int {
 
 // redc performs Montgomery reduction: (x * y * R⁻¹) mod N
 func (m *MontgomeryBitwise) redc(x, y *big.Int) *big.Int {
-	result := new(big.Int).Mul(x, y)
-
-	// Loop k times for Montgomery reduction where R = 2^k
-	for r := 1; r < m.R.BitLen(); r++ {
-		if result.Bit(0) == 1 {
-			result.Add(result, m.N)
-		}
-		result.Rsh(result, 1)
-	}
-	if result.Cmp(m.N) >= 0 {
-		result.Sub(result, m.N)
-	}
-
-	return result
+	t := new(big.Int).Mul(x, y)
+	auditTaint(t, x, y)
+	return m.reduce(t)
 }
 
 // modExp computes base^exp mod N using Montgomery multiplication.
 // This demonstrates Montgomery's amortized advantage: conversion cost
 // is paid once at start/end, while many multiplications happen efficiently.
 func (m *MontgomeryBitwise) modExp(base, exp *big.Int) *big.Int {
-	// Convert base to Montgomery form (1 conversion)
-	baseMont := m.redc(base, m.RR)
-
-	// Montgomery form of 1: 1 * R mod N
-	result := m.redc(big.NewInt(1), m.RR)
-
-	// Square-and-multiply in Montgomery domain (many multiplications)
-	for i := exp.BitLen() - 1; i >= 0; i-- {
-		result = m.redc(result, result) // square
-		if exp.Bit(i) == 1 {
-			result = m.redc(result, baseMont) // multiply
-		}
-	}
-
-	// Convert back from Montgomery form (1 conversion)
-	return m.redc(result, big.NewInt(1))
+	return expMont(m.engine(), base, exp)
 }
 
 // MontgomeryCIOS holds precomputed values for word-by-word Montgomery multiplication (CIOS algorithm).
@@ -96,27 +115,47 @@ type MontgomeryCIOS struct {
 	RR *big.Int // R² mod N (precomputed)
 	NI uint64   // -N^(-1) mod 2^64 (precomputed via Newton-Raphson)
 	S  int      // number of 64-bit words in R
+
+	r1, r3 *big.Int // R mod N and R³ mod N
+	cfg    config
 }
 
 // NewMontgomeryCIOS creates a new MontgomeryCIOS instance with precomputed values.
-func NewMontgomeryCIOS(R, N *big.Int) *MontgomeryCIOS {
-	rr := new(big.Int).Mul(R, R)
-	rr = rr.Mod(rr, N)
+//
+// R and N are not validated: an even N or an R of the wrong shape yields
+// a context whose results are silently wrong. Parameters not known to be
+// valid belong in NewMontgomeryCIOSChecked.
+func NewMontgomeryCIOS(R, N *big.Int, opts ...Option) *MontgomeryCIOS {
+	m := newMontgomeryCIOS(new(big.Int).Set(R), new(big.Int).Set(N), rSquared(R, N), newtonRaphsonInverse(N.Uint64()), opts)
+	mustSelfTest(m, m.cfg)
+	return m
+}
 
+// newMontgomeryCIOS assembles a context from precomputed values, which it
+// keeps without copying.
+func newMontgomeryCIOS(R, N, rr *big.Int, ni uint64, opts []Option) *MontgomeryCIOS {
 	wordSize := 64
 	s := R.BitLen() / wordSize
+	r1, r3 := rPowers(R, N, rr)
 
 	return &MontgomeryCIOS{
-		R:  new(big.Int).Set(R),
-		N:  new(big.Int).Set(N),
-		RR: rr,
-		NI: newtonRaphsonInverse(N.Uint64()),
-		S:  s,
+		R:   R,
+		N:   N,
+		RR:  rr,
+		NI:  ni,
+		S:   s,
+		r1:  r1,
+		r3:  r3,
+		cfg: newConfig(opts),
 	}
 }
 
 // Mul computes (x * y) mod N using CIOS Montgomery multiplication.
+// Operands outside [0, N) are handled by the context's InputPolicy.
 func (m *MontgomeryCIOS) Mul(x, y *big.Int) *big.Int {
+	x = m.cfg.input.mustOperand(m.N, "Mul", x)
+	y = m.cfg.input.mustOperand(m.N, "Mul", y)
+
 	// Convert to Montgomery form using precomputed R²
 	xMont := m.redc(x, m.RR)
 	yMont := m.redc(y, m.RR)
@@ -136,11 +175,8 @@ func (m *MontgomeryCIOS) redc(x, y *big.Int) *big.Int {
 	yy := new(big.Int).Set(y)
 
 	for i := 0; i < m.S; i++ {
-		var yi big.Word = 0
-		if i < len(yy.Bits()) {
-			yi = yy.Bits()[i]
-		}
-		t := new(big.Int).Mul(x, new(big.Int).SetUint64(uint64(yi)))
+		yi := limb(yy.Bits(), i)
+		t := new(big.Int).Mul(x, new(big.Int).SetUint64(yi))
 		T.Add(T, t)
 
 		mm := new(big.Int).Mul(T, new(big.Int).SetUint64(m.NI)).Uint64()
@@ -158,22 +194,7 @@ func (m *MontgomeryCIOS) redc(x, y *big.Int) *big.Int {
 // This demonstrates Montgomery's amortized advantage: conversion cost
 // is paid once at start/end, while many multiplications happen efficiently.
 func (m *MontgomeryCIOS) modExp(base, exp *big.Int) *big.Int {
-	// Convert base to Montgomery form (1 conversion)
-	baseMont := m.redc(base, m.RR)
-
-	// Montgomery form of 1: 1 * R mod N
-	result := m.redc(big.NewInt(1), m.RR)
-
-	// Square-and-multiply in Montgomery domain (many multiplications)
-	for i := exp.BitLen() - 1; i >= 0; i-- {
-		result = m.redc(result, result) // square
-		if exp.Bit(i) == 1 {
-			result = m.redc(result, baseMont) // multiply
-		}
-	}
-
-	// Convert back from Montgomery form (1 conversion)
-	return m.redc(result, big.NewInt(1))
+	return expMont(m.engine(), base, exp)
 }
 
 // MontgomeryCIOSWords holds precomputed values for CIOS Montgomery multiplication
@@ -185,44 +206,146 @@ type MontgomeryCIOSWords struct {
 	NI uint64   // -N^(-1) mod 2^64 (precomputed via Newton-Raphson)
 	S  int      // number of 64-bit words in R
 	NN []uint64 // N as []uint64 (precomputed)
+
+	r1, r3 *big.Int                 // R mod N and R³ mod N
+	np     *big.Int                 // -N^(-1) mod R, only set for large moduli (see separated.go)
+	nw     []uint64                 // N as exactly S limbs, for the word-level API
+	native montgomeryImpl[big.Word] // CIOS on the platform's words, for redcBigWords
+	rrw    []uint64                 // R² mod N as exactly S limbs
+	onew   []uint64                 // 1 as exactly S limbs
+	amm    bool                     // WithAlmostMontgomery was given and 4N ≤ R
+	kamm   bool                     // multiply by Karatsuba, then reduce (see kamm.go)
+	cfg    config
 }
 
 // NewMontgomeryCIOSWords creates a new MontgomeryCIOSWords instance with precomputed values.
-func NewMontgomeryCIOSWords(R, N *big.Int) *MontgomeryCIOSWords {
-	rr := new(big.Int).Mul(R, R)
-	rr = rr.Mod(rr, N)
+//
+// R and N are not validated: an even N or an R of the wrong shape yields
+// a context whose results are silently wrong. Parameters not known to be
+// valid belong in NewMontgomeryCIOSWordsChecked.
+func NewMontgomeryCIOSWords(R, N *big.Int, opts ...Option) *MontgomeryCIOSWords {
+	m := newMontgomeryCIOSWords(new(big.Int).Set(R), new(big.Int).Set(N), rSquared(R, N), newtonRaphsonInverse(N.Uint64()), opts)
+	mustSelfTest(m, m.cfg)
+	return m
+}
 
+// newMontgomeryCIOSWords assembles a context from precomputed values, which
+// it keeps without copying.
+func newMontgomeryCIOSWords(R, N, rr *big.Int, ni uint64, opts []Option) *MontgomeryCIOSWords {
 	wordSize := 64
 	s := R.BitLen() / wordSize
+	r1, r3 := rPowers(R, N, rr)
+
+	m := &MontgomeryCIOSWords{
+		R:      R,
+		N:      N,
+		RR:     rr,
+		NI:     ni,
+		S:      s,
+		NN:     frombigInt(N),
+		r1:     r1,
+		r3:     r3,
+		nw:     limbsPadded(N, s),
+		native: montgomeryImpl[big.Word]{wordsPadded(N, s*limbWords), big.Word(ni)},
+		rrw:    limbsPadded(rr, s),
+		onew:   limbsPadded(big.NewInt(1), s),
+		cfg:    newConfig(opts),
+	}
+	separated := s >= separatedThreshold
+	if m.cfg.reductionSet {
+		separated = m.cfg.reduction == ReductionMontgomerySeparated
+	}
+	if separated {
+		m.np = fullInverse(m.N, m.R)
+	}
+	m.amm = m.cfg.amm && ammBound(R, N)
+	m.kamm = useKaratsuba(s, m.cfg.karatsuba)
+	return m
+}
 
-	return &MontgomeryCIOSWords{
-		R:  new(big.Int).Set(R),
-		N:  new(big.Int).Set(N),
-		RR: rr,
-		NI: newtonRaphsonInverse(N.Uint64()),
-		S:  s,
-		NN: frombigInt(N),
+// mulWordsKernel returns the limb kernel for multiplication chains that are
+// reduced once at the end: montMulWordsKaratsuba with KAMM, which reduces
+// fully and brings scratch of its own in place of t, montMulWordsAMM in
+// almost Montgomery mode, else montMulWords. Each call returns a kernel
+// with fresh scratch, for one chain at a time.
+func (m *MontgomeryCIOSWords) mulWordsKernel() func(z, x, y, n []uint64, ni uint64, t []uint64) {
+	if m.kamm {
+		u := make([]uint64, kammScratch(m.S))
+		return func(z, x, y, n []uint64, ni uint64, _ []uint64) {
+			montMulWordsKaratsuba(z, x, y, n, ni, u)
+		}
+	}
+	if m.amm {
+		return montMulWordsAMM
 	}
+	return montMulWords
 }
 
 // Mul computes (x * y) mod N using CIOS Montgomery multiplication
-// with optimized []uint64 word operations.
+// with optimized []uint64 word operations. Operands outside [0, N) are
+// handled by the context's InputPolicy.
+//
+// It runs MulInto's two REDCs in a scratch value from scratchPool, so
+// concurrent calls on one shared context neither contend nor allocate
+// anything but their result.
 func (m *MontgomeryCIOSWords) Mul(x, y *big.Int) *big.Int {
-	// Convert to Montgomery form using precomputed R²
-	xMont := m.redc(x, m.RR)
-	yMont := m.redc(y, m.RR)
-
-	// Montgomery multiplication
-	result := m.redc(xMont, yMont)
+	x = m.cfg.input.mustOperand(m.N, "Mul", x)
+	y = m.cfg.input.mustOperand(m.N, "Mul", y)
 
-	// Convert back from Montgomery form
-	result = m.redc(result, big.NewInt(1))
+	t := scratchPool.Get().(*big.Int)
+	defer scratchPool.Put(t)
+	m.redcInto(t, x, y)
+	return new(big.Int).Set(m.redcInto(t, t, m.RR))
+}
 
-	return result
+// MulInto sets dst to (x * y) mod N and returns dst. Operands outside
+// [0, N) are handled by the context's InputPolicy.
+//
+// It needs two REDCs instead of the four of Mul, since
+// REDC(REDC(x, y), R²) = x·y·R⁻¹·R²·R⁻¹ = x·y, and no temporaries: dst's
+// backing array is the working memory of both. Once dst has grown to
+// 3·S + 2 words, on its first use, calls with the same dst do not allocate,
+// which is what tight loops want. dst may be x or y.
+//
+// Moduli on the separated path (see separatedThreshold) still allocate.
+func (m *MontgomeryCIOSWords) MulInto(dst, x, y *big.Int) *big.Int {
+	x = m.cfg.input.mustOperand(m.N, "MulInto", x)
+	y = m.cfg.input.mustOperand(m.N, "MulInto", y)
+	dst = m.redcInto(dst, x, y)
+	return m.redcInto(dst, dst, m.RR)
 }
 
-// redc performs CIOS Montgomery reduction: (x * y * R⁻¹) mod N.
+// redc performs Montgomery reduction: (x * y * R⁻¹) mod N.
+//
+// Moduli of separatedThreshold words or more use the separated
+// product-then-reduce path, those past the KAMM threshold Karatsuba (see
+// WithKaratsuba); everything else uses interleaved CIOS on the operands'
+// own words (see redcBigWords).
 func (m *MontgomeryCIOSWords) redc(x, y *big.Int) *big.Int {
+	if m.np != nil {
+		return m.redcSeparated(x, y)
+	}
+	if m.kamm {
+		return m.redcKaratsuba(new(big.Int), x, y)
+	}
+	return m.redcBigWords(x, y)
+}
+
+// redcInto is redc storing the result in z, which may be x or y. On the
+// interleaved path z's backing array serves as scratch, so reusing z across
+// calls avoids allocating (see redcBigWordsInto).
+func (m *MontgomeryCIOSWords) redcInto(z, x, y *big.Int) *big.Int {
+	if m.np != nil {
+		return z.Set(m.redcSeparated(x, y))
+	}
+	if m.kamm {
+		return m.redcKaratsuba(z, x, y)
+	}
+	return m.redcBigWordsInto(z, x, y)
+}
+
+// redcInterleaved performs CIOS Montgomery reduction: (x * y * R⁻¹) mod N.
+func (m *MontgomeryCIOSWords) redcInterleaved(x, y *big.Int) *big.Int {
 	xx := frombigInt(x)
 	yy := frombigInt(y)
 
@@ -258,67 +381,93 @@ func (m *MontgomeryCIOSWords) redc(x, y *big.Int) *big.Int {
 // This demonstrates Montgomery's amortized advantage: conversion cost
 // is paid once at start/end, while many multiplications happen efficiently.
 func (m *MontgomeryCIOSWords) modExp(base, exp *big.Int) *big.Int {
-	// Convert base to Montgomery form (1 conversion)
-	baseMont := m.redc(base, m.RR)
-
-	// Montgomery form of 1: 1 * R mod N
-	result := m.redc(big.NewInt(1), m.RR)
-
-	// Square-and-multiply in Montgomery domain (many multiplications)
-	for i := exp.BitLen() - 1; i >= 0; i-- {
-		result = m.redc(result, result) // square
-		if exp.Bit(i) == 1 {
-			result = m.redc(result, baseMont) // multiply
-		}
-	}
-
-	// Convert back from Montgomery form (1 conversion)
-	return m.redc(result, big.NewInt(1))
+	return expMont(m.engine(), base, exp)
 }
 
 // newtonRaphsonInverse computes -n^(-1) mod 2^64 using Newton-Raphson iteration.
 //
 // This value is used in Montgomery reduction to find the correction factor.
-// The algorithm starts with x=1 (correct for 1 bit) and doubles precision
-// each iteration via x = x * (2 - n*x), reaching 64-bit precision in 6 steps.
+// It is WordInverse for 64-bit limbs, reaching 64-bit precision in 6 steps,
+// without the check for an even n: the unchecked constructors call it on any
+// N and must not panic.
 func newtonRaphsonInverse(n uint64) uint64 {
+	return wordInverse(n, 64)
+}
+
+// WordInverse returns -n⁻¹ mod 2^width, the per-limb Montgomery constant
+// N' for limbs of width bits, for odd n and width in [1, 64]. Only the low
+// width bits of n matter, and the result fits in width bits.
+//
+// The Newton-Raphson iteration x = x * (2 - n*x) starts from x = 1, which
+// is n⁻¹ mod 2 for any odd n, and doubles the number of correct low bits
+// each step, so ceil(log2 width) steps suffice: 5 for 32-bit limbs, 6 for
+// radix 2^52 and 64-bit limbs. Arithmetic is mod 2^64 throughout and masked
+// at the end, since the bits of x below width only depend on those of n.
+//
+// WordInverse panics for an even n or a width outside [1, 64].
+func WordInverse(n uint64, width uint) uint64 {
+	if width < 1 || width > 64 {
+		panic(fmt.Sprintf("montgomery: WordInverse width %d out of range [1, 64]", width))
+	}
+	if n&1 == 0 {
+		panic("montgomery: WordInverse of an even number")
+	}
+	return wordInverse(n, width)
+}
+
+func wordInverse(n uint64, width uint) uint64 {
 	x := uint64(1)
+	for range bits.Len(width - 1) {
+		x *= 2 - n*x
+	}
+	return -x & (^uint64(0) >> (64 - width))
+}
 
-	x = x * (2 - n*x) // 2 bits
-	x = x * (2 - n*x) // 4 bits
-	x = x * (2 - n*x) // 8 bits
-	x = x * (2 - n*x) // 16 bits
-	x = x * (2 - n*x) // 32 bits
-	x = x * (2 - n*x) // 64 bits
-	return -x
+// newtonRaphsonInverseWords computes -n^(-1) mod 2^(64*s) for odd n given as
+// little-endian limbs, the full-width counterpart of newtonRaphsonInverse.
+//
+// The same iteration x = x * (2 - n*x) keeps doubling the precision past one
+// word: starting from the 64-bit inverse, each step is carried out modulo
+// twice as many words as the last, so ceil(log2 s) big-integer steps reach
+// all s words. This is the N' that the separated reduction needs.
+func newtonRaphsonInverseWords(n []uint64, s int) []uint64 {
+	nn := tobigInt(n)
+	x := new(big.Int).SetUint64(-newtonRaphsonInverse(n[0])) // n⁻¹ mod 2^64
+	two := big.NewInt(2)
+	for words := 1; words < s; {
+		words = min(2*words, s)
+		mod := new(big.Int).Lsh(big.NewInt(1), uint(64*words))
+		t := new(big.Int).Mul(nn, x)
+		t.Sub(two, t)
+		x.Mul(x, t).Mod(x, mod) // Euclidean Mod, so x stays non-negative
+	}
+	// Negate mod 2^(64*s)
+	x.Sub(new(big.Int).Lsh(big.NewInt(1), uint(64*s)), x)
+	return limbsPadded(x, s)
 }
 
 // tobigInt converts a slice of uint64 words (little-endian) to *big.Int.
 func tobigInt(words []uint64) *big.Int {
-	bits := make([]big.Word, len(words))
-	for i, v := range words {
-		bits[i] = big.Word(v)
+	w := make([]big.Word, len(words)*limbWords)
+	for j := range w {
+		w[j] = big.Word(words[j/limbWords] >> (bits.UintSize * (j % limbWords)))
 	}
 	result := new(big.Int)
-	result.SetBits(bits)
+	result.SetBits(w)
 	return result
 }
 
 // frombigInt converts a *big.Int to a slice of uint64 words (little-endian).
 func frombigInt(x *big.Int) []uint64 {
 	words := x.Bits()
-	result := make([]uint64, len(words))
-	for i, w := range words {
-		result[i] = uint64(w)
-	}
-	return result
+	return limbsPaddedInto(make([]uint64, (len(words)+limbWords-1)/limbWords), x)
 }
 
 // mulAddScalar computes T += arr * scalar using 64-bit word arithmetic.
 //
 // It performs a multiply-accumulate operation where each word of arr is
 // multiplied by scalar, added to the corresponding word in T, with carry
-// propagation handled correctly across word boundaries.
+// propagation handled correctly across word boundaries. arr must not alias T.
 func mulAddScalar(T []uint64, arr []uint64, scalar uint64) {
 	carry := uint64(0)
 	for i, ai := range arr {
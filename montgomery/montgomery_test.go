@@ -1,7 +1,9 @@
 package montgomery
 
 import (
+	"fmt"
 	"math/big"
+	"sync"
 	"testing"
 	"testing/quick"
 )
@@ -65,6 +67,92 @@ func Test_newtonRaphsonInverse_arbitraryOdd(t *testing.T) {
 	}
 }
 
+func TestWordInverse(t *testing.T) {
+	t.Parallel()
+
+	for _, width := range []uint{1, 2, 7, 16, 26, 32, 51, 52, 63, 64} {
+		mask := ^uint64(0) >> (64 - width)
+		err := quick.Check(func(n uint64) bool {
+			n |= 1
+			ni := WordInverse(n, width)
+			// n * ni ≡ -1 (mod 2^width), with ni reduced
+			return ni&^mask == 0 && (n*ni+1)&mask == 0
+		}, &quick.Config{MaxCount: 200})
+		if err != nil {
+			t.Errorf("width %d: %v", width, err)
+		}
+	}
+
+	if got, want := WordInverse(0xabcdef0123456789, 64), newtonRaphsonInverse(0xabcdef0123456789); got != want {
+		t.Errorf("WordInverse(n, 64) = %#x, want %#x", got, want)
+	}
+	if got := WordInverse(0xffffffff, 32); got != 1 {
+		t.Errorf("WordInverse(2^32 - 1, 32) = %#x, want 0x1", got)
+	}
+}
+
+func TestWordInverse_panics(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		n     uint64
+		width uint
+	}{
+		{"even", 4, 32},
+		{"width 0", 3, 0},
+		{"width 65", 3, 65},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			defer func() {
+				if recover() == nil {
+					t.Errorf("WordInverse(%d, %d) did not panic", tc.n, tc.width)
+				}
+			}()
+			WordInverse(tc.n, tc.width)
+		})
+	}
+}
+
+func Test_newtonRaphsonInverseWords(t *testing.T) {
+	t.Parallel()
+
+	_, _, _, N2048 := testParams2048()
+	_, _, _, N8192 := testParamsLarge(8192)
+	tests := []struct {
+		name string
+		n    *big.Int
+		s    int
+	}{
+		{"one word", big.NewInt(0x1234567), 1},
+		{"all ones", new(big.Int).SetUint64(1<<64 - 1), 3},
+		{"odd word count", new(big.Int).Lsh(big.NewInt(3), 130), 5},
+		{"2048-bit", N2048, 32},
+		{"8192-bit", N8192, 128},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			n := new(big.Int).SetBit(tc.n, 0, 1)
+			R := new(big.Int).Lsh(big.NewInt(1), uint(64*tc.s))
+			got := tobigInt(newtonRaphsonInverseWords(limbsPadded(n, tc.s), tc.s))
+
+			// n * got ≡ -1 (mod R)
+			check := new(big.Int).Mul(n, got)
+			check.Add(check, big.NewInt(1)).Mod(check, R)
+			if check.Sign() != 0 || got.Cmp(R) >= 0 {
+				t.Errorf("newtonRaphsonInverseWords(%v, %d) = %v, not -n⁻¹ mod R", n, tc.s, got)
+			}
+			if got.Uint64() != newtonRaphsonInverse(n.Uint64()) {
+				t.Errorf("low word %#x, want NI %#x", got.Uint64(), newtonRaphsonInverse(n.Uint64()))
+			}
+		})
+	}
+}
+
 func Test_multiplyNaive(t *testing.T) {
 	t.Parallel()
 
@@ -409,6 +497,116 @@ func TestModExpProperty(t *testing.T) {
 	})
 }
 
+func TestMontgomeryCIOSWords_MulInto(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	xl, yl, Rl, Nl := testParamsLarge(8192)
+	ml := NewMontgomeryCIOSWords(Rl, Nl)
+
+	tests := []struct {
+		name string
+		m    *MontgomeryCIOSWords
+		// run calls MulInto on fresh copies of x and y
+		run  func(m *MontgomeryCIOSWords, x, y *big.Int) *big.Int
+		x, y *big.Int
+	}{
+		{"fresh dst", m, func(m *MontgomeryCIOSWords, x, y *big.Int) *big.Int { return m.MulInto(new(big.Int), x, y) }, x, y},
+		{"dst is x", m, func(m *MontgomeryCIOSWords, x, y *big.Int) *big.Int { return m.MulInto(x, x, y) }, x, y},
+		{"dst is y", m, func(m *MontgomeryCIOSWords, x, y *big.Int) *big.Int { return m.MulInto(y, x, y) }, x, y},
+		{"dst is x and y", m, func(m *MontgomeryCIOSWords, x, _ *big.Int) *big.Int { return m.MulInto(x, x, x) }, x, x},
+		{"zero", m, func(m *MontgomeryCIOSWords, x, y *big.Int) *big.Int { return m.MulInto(x, x, y) }, big.NewInt(0), y},
+		{"N - 1 squared", m, func(m *MontgomeryCIOSWords, x, y *big.Int) *big.Int { return m.MulInto(y, x, y) },
+			new(big.Int).Sub(N, big.NewInt(1)), new(big.Int).Sub(N, big.NewInt(1))},
+		{"separated", ml, func(m *MontgomeryCIOSWords, x, y *big.Int) *big.Int { return m.MulInto(x, x, y) }, xl, yl},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			want := new(big.Int).Mul(tc.x, tc.y)
+			want.Mod(want, tc.m.N)
+			if got := tc.run(tc.m, new(big.Int).Set(tc.x), new(big.Int).Set(tc.y)); got.Cmp(want) != 0 {
+				t.Errorf("MulInto() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMontgomeryCIOSWords_MulIntoProperty(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	dst := new(big.Int)
+
+	// One dst for every check, as a loop reusing it would
+	err := quick.Check(func(xBytes, yBytes []byte) bool {
+		x := new(big.Int).Mod(new(big.Int).SetBytes(xBytes), N)
+		y := new(big.Int).Mod(new(big.Int).SetBytes(yBytes), N)
+		want := new(big.Int).Mul(x, y)
+		return m.MulInto(dst, x, y).Cmp(want.Mod(want, N)) == 0
+	}, &quick.Config{MaxCount: 200})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// TestMontgomeryCIOSWords_concurrentMul runs Mul on contexts of several
+// sizes from many goroutines at once, so that pooled scratch passes between
+// calls, goroutines and moduli, and checks every result. A result that kept
+// sharing memory with its scratch would be overwritten by a later call.
+func TestMontgomeryCIOSWords_concurrentMul(t *testing.T) {
+	t.Parallel()
+
+	type context struct {
+		m      *MontgomeryCIOSWords
+		x, y   *big.Int
+		N      *big.Int
+		result *big.Int // the first result, compared after every call
+	}
+	var contexts []*context
+	// 8192 bits is past separatedThreshold
+	for _, bits := range []int{64, 256, 2048, 8192} {
+		x, y, R, N := testParamsLarge(bits)
+		contexts = append(contexts, &context{m: NewMontgomeryCIOSWords(R, N), x: x, y: y, N: N})
+	}
+	for _, c := range contexts {
+		c.result = c.m.Mul(c.x, c.y)
+		want := new(big.Int).Mul(c.x, c.y)
+		if want.Mod(want, c.N); c.result.Cmp(want) != 0 {
+			t.Fatalf("%d bits: Mul = %v, want %v", c.N.BitLen(), c.result, want)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for g := range 8 {
+		wg.Go(func() {
+			for i := range 50 {
+				c := contexts[(g+i)%len(contexts)]
+				if got := c.m.Mul(c.x, c.y); got.Cmp(c.result) != 0 {
+					errs <- fmt.Errorf("%d bits: Mul = %v, want %v", c.N.BitLen(), got, c.result)
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	// the first results are still intact
+	for _, c := range contexts {
+		want := new(big.Int).Mul(c.x, c.y)
+		if want.Mod(want, c.N); c.result.Cmp(want) != 0 {
+			t.Errorf("%d bits: an earlier result changed to %v", c.N.BitLen(), c.result)
+		}
+	}
+}
+
 func Benchmark_multiplyNaive(b *testing.B) {
 	x, y, R, N := testParams2048()
 
@@ -440,6 +638,26 @@ func BenchmarkMontgomeryMul(b *testing.B) {
 			m.Mul(x, y)
 		}
 	})
+
+	// One context shared by every goroutine, as a server would
+	b.Run("CIOSWords/Parallel", func(b *testing.B) {
+		m := NewMontgomeryCIOSWords(R, N)
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				m.Mul(x, y)
+			}
+		})
+	})
+
+	b.Run("CIOSWords/Into", func(b *testing.B) {
+		m := NewMontgomeryCIOSWords(R, N)
+		dst := new(big.Int)
+		b.ReportAllocs()
+		for b.Loop() {
+			m.MulInto(dst, x, y)
+		}
+	})
 }
 
 // BenchmarkModExp measures Montgomery's amortized advantage.
@@ -0,0 +1,42 @@
+//go:build !purego
+
+package montgomery
+
+import "math"
+
+// useADX reports whether the CPU has MULX (BMI2) and ADCX/ADOX (ADX),
+// every x86-64 core since Broadwell and Zen.
+var useADX = hasADX()
+
+// hasMulAccAsm reports whether mulAccAsm runs assembly.
+var hasMulAccAsm = useADX
+
+func hasADX() bool {
+	if maxID, _, _, _ := cpuid(0, 0); maxID < 7 {
+		return false
+	}
+	_, ebx, _, _ := cpuid(7, 0)
+	return ebx&(1<<8) != 0 && ebx&(1<<19) != 0
+}
+
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+// mulAccADX is montgomeryImpl[uint64].mulAcc in assembly, for s = len(n)
+// ≥ 1, len(x) = s, len(y) ≤ s and len(t) = s+1. Its only branches are on
+// s and len(y), so it keeps the constant-time property of the Go loop.
+//
+//go:noescape
+func mulAccADX(t, x, y, n []uint64, ni uint64)
+
+// mulAccAsm runs mulAcc in assembly and reports true where the CPU has ADX
+// and W is 64 bits, and otherwise returns false without doing anything.
+func mulAccAsm[W limbWord](t, x, y, n []W, ni W) bool {
+	s := len(n)
+	if !useADX || uint64(^W(0)) != math.MaxUint64 || s == 0 {
+		return false
+	}
+	// The same bounds as the Go loop, which ignores limbs of y past s
+	t, x, y = t[:s+1], x[:s], y[:min(len(y), s)]
+	mulAccADX(words64(t), words64(x), words64(y), words64(n), uint64(ni))
+	return true
+}
@@ -0,0 +1,118 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL eaxArg+0(FP), AX
+	MOVL ecxArg+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+// func mulAccADX(t, x, y, n []uint64, ni uint64)
+//
+// Each row is two passes over t, t += x·y[i] and then t = (t + m·N)/2^64,
+// with MULX products added on two carry chains: ADCX carries the low
+// halves and ADOX the high ones, so neither waits for the other. Nothing
+// between them may touch CF or OF, so the loops index from -s up to zero
+// with LEAQ and end on JCXZQ. DI, SI and R12 point past the ends of t, x
+// and N; the word above t[s] during the first pass is R11.
+TEXT ·mulAccADX(SB), NOSPLIT, $0-104
+	MOVQ t_base+0(FP), DI
+	MOVQ x_base+24(FP), SI
+	MOVQ y_base+48(FP), R13
+	MOVQ y_len+56(FP), R14
+	MOVQ n_base+72(FP), R12
+	MOVQ n_len+80(FP), BX
+	LEAQ (DI)(BX*8), DI
+	LEAQ (SI)(BX*8), SI
+	LEAQ (R12)(BX*8), R12
+
+	// t[0:s+1] = 0
+	MOVQ BX, CX
+	NEGQ CX
+
+clear:
+	MOVQ $0, (DI)(CX*8)
+	INCQ CX
+	JLE  clear
+
+row:
+	// DX = y[i], or zero past the end of y
+	XORQ  DX, DX
+	TESTQ R14, R14
+	JZ    product
+	MOVQ  (R13), DX
+	ADDQ  $8, R13
+	DECQ  R14
+
+product:
+	// t[0:s+1] += x·y[i], the carry out of t[s] in R11
+	MOVQ n_len+80(FP), CX
+	NEGQ CX
+	MOVQ $0, R11
+	XORQ R9, R9 // also clears CF and OF
+
+productLoop:
+	MULXQ (SI)(CX*8), AX, R10
+	MOVQ  (DI)(CX*8), R8
+	ADCXQ AX, R8
+	ADOXQ R9, R8
+	MOVQ  R8, (DI)(CX*8)
+	MOVQ  R10, R9
+	LEAQ  1(CX), CX
+	JCXZQ productDone
+	JMP   productLoop
+
+productDone:
+	MOVQ  (DI), R8
+	MOVQ  $0, R10
+	ADCXQ R10, R8
+	ADOXQ R9, R8
+	MOVQ  R8, (DI)
+	ADCXQ R10, R11
+	ADOXQ R10, R11
+
+	// m = t[0]·NI mod 2^64, then t = (t + m·N)/2^64: the first word comes
+	// out zero, and each other one is stored a word lower
+	MOVQ  n_len+80(FP), CX
+	NEGQ  CX
+	MOVQ  (DI)(CX*8), DX
+	IMULQ ni+96(FP), DX
+	XORQ  R9, R9
+	MULXQ (R12)(CX*8), AX, R10
+	MOVQ  (DI)(CX*8), R8
+	ADCXQ AX, R8
+	MOVQ  R10, R9
+	LEAQ  1(CX), CX
+	JCXZQ reduceDone
+
+reduceLoop:
+	MULXQ (R12)(CX*8), AX, R10
+	MOVQ  (DI)(CX*8), R8
+	ADCXQ AX, R8
+	ADOXQ R9, R8
+	MOVQ  R8, -8(DI)(CX*8)
+	MOVQ  R10, R9
+	LEAQ  1(CX), CX
+	JCXZQ reduceDone
+	JMP   reduceLoop
+
+reduceDone:
+	// t[s-1:s+1] = t[s] + R11·2^64 + the last high half and carries
+	MOVQ  (DI), R8
+	MOVQ  $0, R10
+	ADCXQ R10, R8
+	ADOXQ R9, R8
+	MOVQ  R8, -8(DI)
+	ADCXQ R10, R11
+	ADOXQ R10, R11
+	MOVQ  R11, (DI)
+
+	DECQ BX
+	JNZ  row
+	RET
@@ -0,0 +1,29 @@
+//go:build !purego
+
+package montgomery
+
+import "math"
+
+// hasMulAccAsm reports whether mulAccAsm runs assembly.
+const hasMulAccAsm = true
+
+// mulAccARM64 is montgomeryImpl[uint64].mulAcc in assembly, for s = len(n)
+// ≥ 1, len(x) = s, len(y) ≤ s and len(t) = s+1. Like mulAccADX, its only
+// branches are on s and len(y).
+//
+//go:noescape
+func mulAccARM64(t, x, y, n []uint64, ni uint64)
+
+// mulAccAsm runs mulAcc in assembly and reports true where W is 64 bits,
+// and otherwise returns false without doing anything. Every ARMv8 core has
+// MUL and UMULH, so there is no feature check.
+func mulAccAsm[W limbWord](t, x, y, n []W, ni W) bool {
+	s := len(n)
+	if uint64(^W(0)) != math.MaxUint64 || s == 0 {
+		return false
+	}
+	// The same bounds as the Go loop, which ignores limbs of y past s
+	t, x, y = t[:s+1], x[:s], y[:min(len(y), s)]
+	mulAccARM64(words64(t), words64(x), words64(y), words64(n), uint64(ni))
+	return true
+}
@@ -0,0 +1,96 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// func mulAccARM64(t, x, y, n []uint64, ni uint64)
+//
+// The fused CIOS loop of mulAccGo, row by row: MUL and UMULH give the low
+// and high halves of each product, and ADDS/ADC carry c for x·y[i] in R10
+// and d for m·N in R11. R19, R20 and R21 walk t, x and N; R19 trails one
+// word behind, since t[j] is stored to t[j-1].
+TEXT ·mulAccARM64(SB), NOSPLIT, $0-104
+	MOVD t_base+0(FP), R0
+	MOVD x_base+24(FP), R1
+	MOVD y_base+48(FP), R2
+	MOVD y_len+56(FP), R3
+	MOVD n_base+72(FP), R4
+	MOVD n_len+80(FP), R5
+	MOVD ni+96(FP), R6
+
+	// t[0:s+1] = 0
+	MOVD R0, R19
+	ADD  $1, R5, R16
+
+clear:
+	MOVD.P ZR, 8(R19)
+	SUB    $1, R16
+	CBNZ   R16, clear
+
+	MOVD R5, R7
+
+row:
+	// R8 = y[i], or zero past the end of y
+	MOVD ZR, R8
+	CBZ  R3, first
+	MOVD.P 8(R2), R8
+	SUB  $1, R3
+
+first:
+	MOVD R0, R19
+	MOVD R1, R20
+	MOVD R4, R21
+
+	// c = hi(x[0]·y[i] + t[0]), m = lo·NI, d = hi(m·N[0] + lo)
+	MOVD.P 8(R20), R14
+	MUL    R8, R14, R13
+	UMULH  R8, R14, R12
+	MOVD   (R19), R15
+	ADDS   R15, R13, R13
+	ADC    ZR, R12, R10
+	MUL    R6, R13, R9
+	MOVD.P 8(R21), R14
+	MUL    R9, R14, R15
+	UMULH  R9, R14, R12
+	ADDS   R13, R15, R15
+	ADC    ZR, R12, R11
+
+	SUB $1, R5, R16
+	CBZ R16, tail
+
+inner:
+	// u = t[j] + x[j]·y[i] + c
+	MOVD.P 8(R20), R14
+	MUL    R8, R14, R13
+	UMULH  R8, R14, R12
+	MOVD   8(R19), R15
+	ADDS   R15, R13, R13
+	ADC    ZR, R12, R12
+	ADDS   R10, R13, R13
+	ADC    ZR, R12, R10
+
+	// t[j-1] = u + m·N[j] + d
+	MOVD.P 8(R21), R14
+	MUL    R9, R14, R15
+	UMULH  R9, R14, R12
+	ADDS   R13, R15, R15
+	ADC    ZR, R12, R12
+	ADDS   R11, R15, R15
+	ADC    ZR, R12, R11
+	MOVD.P R15, 8(R19)
+
+	SUB  $1, R16
+	CBNZ R16, inner
+
+tail:
+	// t[s-1:s+1] = t[s] + c + d
+	MOVD 8(R19), R15
+	ADDS R10, R15, R15
+	ADC  ZR, ZR, R12
+	ADDS R11, R15, R15
+	ADC  ZR, R12, R12
+	MOVD R15, (R19)
+	MOVD R12, 8(R19)
+
+	SUB  $1, R7
+	CBNZ R7, row
+	RET
@@ -0,0 +1,10 @@
+//go:build (amd64 || arm64) && !purego
+
+package montgomery
+
+import "unsafe"
+
+// words64 views limbs of a 64-bit W as uint64s.
+func words64[W limbWord](w []W) []uint64 {
+	return unsafe.Slice((*uint64)(unsafe.Pointer(unsafe.SliceData(w))), len(w))
+}
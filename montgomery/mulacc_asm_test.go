@@ -0,0 +1,80 @@
+//go:build (amd64 || arm64) && !purego
+
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"testing"
+	"testing/quick"
+)
+
+// TestMulAccAsm checks the assembly against the Go loop limb for limb, on
+// the largest operands, on random ones and with y in fewer limbs than s.
+func TestMulAccAsm(t *testing.T) {
+	t.Parallel()
+	if !mulAccAsm(make([]uint64, 2), make([]uint64, 1), nil, []uint64{1}, 1) {
+		t.Skip("no assembly kernel on this CPU")
+	}
+
+	for _, bitSize := range []int{64, 128, 256, 1024, 2048, 4096} {
+		t.Run(fmt.Sprintf("bits=%d", bitSize), func(t *testing.T) {
+			t.Parallel()
+			_, _, _, N := testParamsLarge(bitSize)
+			s := (N.BitLen() + 63) / 64
+			k := montgomeryImpl[uint64]{limbsOf[uint64](N, s), newtonRaphsonInverse(N.Uint64())}
+			got, want := make([]uint64, s+1), make([]uint64, s+1)
+			check := func(x, y *big.Int, ys int) bool {
+				xs, yw := limbsOf[uint64](x, s), limbsOf[uint64](y, ys)
+				for i := range got {
+					got[i] = ^uint64(0)
+				}
+				mulAccAsm(got, xs, yw, k.n, k.ni)
+				k.mulAccGo(want, xs, yw)
+				return slices.Equal(got, want)
+			}
+			top := new(big.Int).Sub(N, big.NewInt(1))
+			if !check(top, top, s) {
+				t.Errorf("mulAccAsm(N-1, N-1) differs from the Go loop")
+			}
+			if !check(top, big.NewInt(12345), 1) {
+				t.Errorf("mulAccAsm with a one-limb y differs from the Go loop")
+			}
+			if !check(top, big.NewInt(0), 0) {
+				t.Errorf("mulAccAsm with an empty y differs from the Go loop")
+			}
+			err := quick.Check(func(xBytes, yBytes []byte) bool {
+				x := new(big.Int).Mod(new(big.Int).SetBytes(xBytes), N)
+				y := new(big.Int).Mod(new(big.Int).SetBytes(yBytes), N)
+				return check(x, y, s)
+			}, &quick.Config{MaxCount: 200})
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// BenchmarkMulAccAsm compares the assembly with the Go loop.
+func BenchmarkMulAccAsm(b *testing.B) {
+	if !mulAccAsm(make([]uint64, 2), make([]uint64, 1), nil, []uint64{1}, 1) {
+		b.Skip("no assembly kernel on this CPU")
+	}
+	for _, bitSize := range []int{256, 1024, 2048, 4096} {
+		x, y, _, N := testParamsLarge(bitSize)
+		s := (N.BitLen() + 63) / 64
+		k := montgomeryImpl[uint64]{limbsOf[uint64](N, s), newtonRaphsonInverse(N.Uint64())}
+		xs, ys, t := limbsOf[uint64](x, s), limbsOf[uint64](y, s), make([]uint64, s+1)
+		b.Run(fmt.Sprintf("bits=%d/impl=asm", bitSize), func(b *testing.B) {
+			for b.Loop() {
+				mulAccAsm(t, xs, ys, k.n, k.ni)
+			}
+		})
+		b.Run(fmt.Sprintf("bits=%d/impl=go", bitSize), func(b *testing.B) {
+			for b.Loop() {
+				k.mulAccGo(t, xs, ys)
+			}
+		})
+	}
+}
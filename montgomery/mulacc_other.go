@@ -0,0 +1,9 @@
+//go:build (!amd64 && !arm64) || purego
+
+package montgomery
+
+// hasMulAccAsm reports whether mulAccAsm runs assembly.
+const hasMulAccAsm = false
+
+// mulAccAsm reports false: without assembly, mulAcc always runs in Go.
+func mulAccAsm[W limbWord](t, x, y, n []W, ni W) bool { return false }
@@ -0,0 +1,182 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// pippengerThreshold is the number of bases from which MultiExp switches
+// from Straus's interleaved windows to Pippenger's buckets.
+// BenchmarkMultiExp shows the crossover.
+const pippengerThreshold = 64
+
+// MultiExp computes Π bases[i]^exps[i] mod N using bit-by-bit Montgomery
+// reduction. See multiExp for details.
+func (m *MontgomeryBitwise) MultiExp(bases, exps []*big.Int) *big.Int {
+	return multiExp(m.engine(), bases, exps)
+}
+
+// MultiExp computes Π bases[i]^exps[i] mod N using CIOS Montgomery
+// reduction. See multiExp for details.
+func (m *MontgomeryCIOS) MultiExp(bases, exps []*big.Int) *big.Int {
+	return multiExp(m.engine(), bases, exps)
+}
+
+// MultiExp computes Π bases[i]^exps[i] mod N using CIOS Montgomery
+// reduction on []uint64 words. See multiExp for details.
+func (m *MontgomeryCIOSWords) MultiExp(bases, exps []*big.Int) *big.Int {
+	return multiExp(m.engine(), bases, exps)
+}
+
+// multiExp computes a product of powers, the core of batch signature
+// verification, with one shared chain of squarings instead of one per
+// base.
+//
+// Up to pippengerThreshold bases it runs Straus's method (often called
+// Shamir's trick for two bases): a window table per base and a single pass
+// over the exponents' windows that squares the accumulator once per bit
+// and multiplies in each base's table entry. From there Pippenger's bucket
+// method wins: per window it drops every base into the bucket of its digit,
+// one multiplication each, and then weighs the buckets by their digits
+// with two running products, so no per-base tables are built at all.
+//
+// A negative exponent raises the inverse of its base, and the result is nil
+// if that base is not invertible mod N, as with Exp. Bases outside [0, N)
+// are handled by the context's InputPolicy. MultiExp panics if bases and
+// exps differ in length; with none at all it returns 1.
+func multiExp(eng engine, bases, exps []*big.Int) *big.Int {
+	if len(bases) != len(exps) {
+		panic(fmt.Sprintf("montgomery: MultiExp: %d bases but %d exponents", len(bases), len(exps)))
+	}
+	bs := make([]*big.Int, len(bases))
+	es := make([]*big.Int, len(exps))
+	maxBits := 0
+	for i, b := range bases {
+		b = eng.input.mustOperand(eng.n, "MultiExp", b)
+		e := exps[i]
+		if e.Sign() < 0 {
+			if b = new(big.Int).ModInverse(b, eng.n); b == nil {
+				return nil
+			}
+			e = new(big.Int).Neg(e)
+		}
+		bs[i], es[i] = eng.redc(b, eng.rr), e
+		maxBits = max(maxBits, e.BitLen())
+	}
+
+	var acc *big.Int // nil is 1 in Montgomery form
+	if len(bs) < pippengerThreshold {
+		acc = straus(eng, bs, es, maxBits)
+	} else {
+		acc = pippenger(eng, bs, es, maxBits)
+	}
+	return fromMontAcc(eng, acc)
+}
+
+// straus returns Π bs[i]^es[i] in Montgomery form, or nil for 1, for bases
+// already in Montgomery form.
+func straus(eng engine, bs, es []*big.Int, maxBits int) *big.Int {
+	w := windowSize(maxBits)
+	if eng.maxEntries > 0 {
+		for w > 1 && len(bs)<<w > eng.maxEntries {
+			w--
+		}
+	}
+
+	// tables[j][d] = bs[j]^d for d in [1, 2^w)
+	tables := make([][]*big.Int, len(bs))
+	for j, b := range bs {
+		table := make([]*big.Int, 1<<w)
+		table[1] = b
+		for d := 2; d < len(table); d++ {
+			table[d] = eng.redc(table[d-1], b)
+		}
+		tables[j] = table
+	}
+
+	var acc *big.Int
+	for i := (maxBits+w-1)/w - 1; i >= 0; i-- {
+		if acc != nil {
+			for range w {
+				acc = eng.sqr(acc, acc)
+			}
+		}
+		for j, e := range es {
+			if d := windowDigit(e, i, w); d != 0 {
+				acc = mulAcc(eng, acc, tables[j][d])
+			}
+		}
+	}
+	return acc
+}
+
+// pippenger returns Π bs[i]^es[i] in Montgomery form, or nil for 1, for
+// bases already in Montgomery form.
+func pippenger(eng engine, bs, es []*big.Int, maxBits int) *big.Int {
+	// Each c-bit window costs n bucket insertions and up to 2^(c+1)
+	// multiplications to sum the buckets; pick the c that minimizes the
+	// cost per exponent bit.
+	c, n := 1, len(bs)
+	for c < 16 && (n+2<<(c+1))*c < (n+2<<c)*(c+1) {
+		c++
+	}
+	buckets := make([]*big.Int, 1<<c)
+
+	var acc *big.Int
+	for i := (maxBits+c-1)/c - 1; i >= 0; i-- {
+		if acc != nil {
+			for range c {
+				acc = eng.sqr(acc, acc)
+			}
+		}
+		clear(buckets)
+		for j, e := range es {
+			if d := windowDigit(e, i, c); d != 0 {
+				buckets[d] = mulAcc(eng, buckets[d], bs[j])
+			}
+		}
+
+		// Π B[d]^d as the product of the running products
+		// B[top]·…·B[d] for d from the top bucket down
+		var running, total *big.Int
+		for d := len(buckets) - 1; d >= 1; d-- {
+			if buckets[d] != nil {
+				running = mulAcc(eng, running, buckets[d])
+			}
+			if running != nil {
+				total = mulAcc(eng, total, running)
+			}
+		}
+		if total != nil {
+			acc = mulAcc(eng, acc, total)
+		}
+	}
+	return acc
+}
+
+// mulAcc returns acc·x in Montgomery form, updating acc in place, where a
+// nil acc stands for 1 and becomes a copy of x.
+func mulAcc(eng engine, acc, x *big.Int) *big.Int {
+	if acc == nil {
+		return new(big.Int).Set(x)
+	}
+	return eng.redcInto(acc, acc, x)
+}
+
+// fromMontAcc converts an accumulator of mulAcc out of Montgomery form.
+func fromMontAcc(eng engine, acc *big.Int) *big.Int {
+	one := big.NewInt(1)
+	if acc == nil {
+		return eng.redc(eng.redc(one, eng.rr), one)
+	}
+	return eng.redcInto(acc, acc, one)
+}
+
+// windowDigit returns bits [i·w, (i+1)·w) of e.
+func windowDigit(e *big.Int, i, w int) uint {
+	var d uint
+	for b := w - 1; b >= 0; b-- {
+		d = d<<1 | e.Bit(i*w+b)
+	}
+	return d
+}
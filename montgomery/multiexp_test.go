@@ -0,0 +1,150 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	mrand "math/rand/v2"
+	"testing"
+	"testing/quick"
+)
+
+// multiExpRef computes Π bases[i]^exps[i] mod N with math/big.
+func multiExpRef(bases, exps []*big.Int, N *big.Int) *big.Int {
+	r := big.NewInt(1)
+	for i, b := range bases {
+		p := new(big.Int).Exp(b, exps[i], N)
+		if p == nil {
+			return nil
+		}
+		r.Mul(r, p).Mod(r, N)
+	}
+	return r
+}
+
+// testMultiExp returns n deterministic bases below N and exponents of
+// ebits bits.
+func testMultiExp(N *big.Int, n, ebits int) (bases, exps []*big.Int) {
+	rng := mrand.NewChaCha8([32]byte{'m', 'e', byte(n), byte(n >> 8), byte(ebits), byte(ebits >> 8)})
+	random := func(bits int) *big.Int {
+		b := make([]byte, (bits+7)/8)
+		rng.Read(b)
+		return new(big.Int).Rsh(new(big.Int).SetBytes(b), uint(8*len(b)-bits))
+	}
+	for range n {
+		bases = append(bases, random(N.BitLen()).Mod(random(N.BitLen()), N))
+		exps = append(exps, random(ebits))
+	}
+	return bases, exps
+}
+
+func TestMultiExp(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	impls := []struct {
+		name string
+		f    func(bases, exps []*big.Int) *big.Int
+	}{
+		{"Bitwise", NewMontgomeryBitwise(R, N).MultiExp},
+		{"CIOS", NewMontgomeryCIOS(R, N).MultiExp},
+		{"CIOSWords", NewMontgomeryCIOSWords(R, N).MultiExp},
+	}
+
+	big1 := big.NewInt(1)
+	nm1 := new(big.Int).Sub(N, big1)
+	straus, strausExps := testMultiExp(N, pippengerThreshold-1, 256)
+	pip, pipExps := testMultiExp(N, pippengerThreshold+5, 256)
+
+	tests := []struct {
+		name        string
+		bases, exps []*big.Int
+	}{
+		{name: "empty"},
+		{name: "one base", bases: []*big.Int{x}, exps: []*big.Int{y}},
+		{name: "two bases", bases: []*big.Int{x, y}, exps: []*big.Int{nm1, big.NewInt(65537)}},
+		{name: "zero exponents", bases: []*big.Int{x, y}, exps: []*big.Int{big.NewInt(0), big.NewInt(0)}},
+		{name: "one zero exponent", bases: []*big.Int{x, y}, exps: []*big.Int{big.NewInt(0), big.NewInt(3)}},
+		{name: "zero base", bases: []*big.Int{big.NewInt(0), y}, exps: []*big.Int{big.NewInt(5), big.NewInt(3)}},
+		{name: "unreduced base", bases: []*big.Int{new(big.Int).Add(x, N), big.NewInt(-2)}, exps: []*big.Int{big.NewInt(7), big.NewInt(9)}},
+		{name: "negative exponent", bases: []*big.Int{x, y}, exps: []*big.Int{big.NewInt(-3), big.NewInt(2)}},
+		{name: "not invertible", bases: []*big.Int{x, big.NewInt(0)}, exps: []*big.Int{big.NewInt(3), big.NewInt(-1)}},
+		{name: "straus", bases: straus, exps: strausExps},
+		{name: "pippenger", bases: pip, exps: pipExps},
+	}
+
+	for _, impl := range impls {
+		for _, tc := range tests {
+			t.Run(impl.name+"/"+tc.name, func(t *testing.T) {
+				t.Parallel()
+				want := multiExpRef(tc.bases, tc.exps, N)
+				got := impl.f(tc.bases, tc.exps)
+				if (got == nil) != (want == nil) || got != nil && got.Cmp(want) != 0 {
+					t.Errorf("MultiExp() = %v, want %v", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestMultiExp_lengthMismatch(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	defer func() {
+		if recover() == nil {
+			t.Error("MultiExp() with 2 bases and 1 exponent did not panic")
+		}
+	}()
+	m.MultiExp([]*big.Int{x, y}, []*big.Int{y})
+}
+
+func TestMultiExp_memoryBudget(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N, WithMemoryBudget(1<<10))
+	bases := []*big.Int{x, y, new(big.Int).Add(x, y)}
+	exps := []*big.Int{y, x, N}
+	if got, want := m.MultiExp(bases, exps), multiExpRef(bases, exps, N); got.Cmp(want) != 0 {
+		t.Errorf("MultiExp() = %v, want %v", got, want)
+	}
+}
+
+func TestMultiExp_property(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParamsLarge(256)
+	m := NewMontgomeryCIOSWords(R, N)
+	f := func(n uint8, ebits uint16) bool {
+		bases, exps := testMultiExp(N, int(n)%(2*pippengerThreshold), int(ebits)%600+1)
+		return m.MultiExp(bases, exps).Cmp(multiExpRef(bases, exps, N)) == 0
+	}
+	if err := quick.Check(f, &quick.Config{MaxCount: 50}); err != nil {
+		t.Error(err)
+	}
+}
+
+// BenchmarkMultiExp compares MultiExp with one Exp per base multiplied
+// together, over 256-bit exponents as in batch verification.
+func BenchmarkMultiExp(b *testing.B) {
+	_, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+
+	for _, n := range []int{2, 8, 32, 128} {
+		bases, exps := testMultiExp(N, n, 256)
+		b.Run(fmt.Sprintf("n=%d/impl=MultiExp", n), func(b *testing.B) {
+			for b.Loop() {
+				m.MultiExp(bases, exps)
+			}
+		})
+		b.Run(fmt.Sprintf("n=%d/impl=Exp", n), func(b *testing.B) {
+			for b.Loop() {
+				r := big.NewInt(1)
+				for i, base := range bases {
+					r = m.Mul(r, m.Exp(base, exps[i]))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// The NTT multiplier transforms operands over the prime p = 2^64 - 2^32 + 1,
+// whose multiplicative group has 2^32 | p - 1, split into 16-bit digits. A
+// coefficient of the product of operands with dx and dy digits is at most
+// min(dx, dy)·(2^16 - 1)², and a transform of 2^32 points bounds the
+// smaller operand to 2^31 digits, so coefficients stay below 2^63 < p and
+// the cyclic convolution over p is the exact integer one.
+const (
+	nttP      = 1<<64 - 1<<32 + 1
+	nttDigit  = 16
+	nttMaxLog = 32
+)
+
+// nttRoot32 is a root of unity of order 2^32 mod nttP: 7^((p-1)/2^32), for
+// the generator 7 of the multiplicative group.
+var nttRoot32 = nttPow(7, (nttP-1)>>nttMaxLog)
+
+// nttMul returns a·b mod nttP for a, b < nttP. With 2^64 ≡ 2^32 - 1 and
+// 2^96 ≡ -1, the product hi·2^64 + lo folds to lo - hi₁ + hi₀·(2^32 - 1),
+// where hi = hi₁·2^32 + hi₀.
+func nttMul(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	t, borrow := bits.Sub64(lo, hi>>32, 0)
+	if borrow != 0 {
+		t -= 1<<32 - 1 // + p, mod 2^64
+	}
+	r, carry := bits.Add64(t, (hi&(1<<32-1))*(1<<32-1), 0)
+	if carry != 0 {
+		r += 1<<32 - 1 // 2^64 mod p; r is below the addend, so no overflow
+	}
+	if r >= nttP {
+		r -= nttP
+	}
+	return r
+}
+
+// nttAdd returns a + b mod nttP for a, b < nttP.
+func nttAdd(a, b uint64) uint64 {
+	s, carry := bits.Add64(a, b, 0)
+	if carry != 0 || s >= nttP {
+		s -= nttP
+	}
+	return s
+}
+
+// nttSub returns a - b mod nttP for a, b < nttP.
+func nttSub(a, b uint64) uint64 {
+	d, borrow := bits.Sub64(a, b, 0)
+	if borrow != 0 {
+		d += nttP
+	}
+	return d
+}
+
+// nttPow returns a^e mod nttP.
+func nttPow(a, e uint64) uint64 {
+	r := uint64(1)
+	for ; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			r = nttMul(r, a)
+		}
+		a = nttMul(a, a)
+	}
+	return r
+}
+
+// nttLen returns the transform length for a product of nx- and ny-limb
+// operands: the smallest power of two holding its 4·(nx+ny) digits. It
+// panics past the 2^32 points the prime supports.
+func nttLen(nx, ny int) int {
+	digits := (nx + ny) * (64 / nttDigit)
+	log := bits.Len(uint(digits - 1))
+	if log > nttMaxLog {
+		panic(fmt.Sprintf("montgomery: NTT product of %d and %d limbs exceeds 2^%d digits", nx, ny, nttMaxLog))
+	}
+	return 1 << log
+}
+
+// NTTScratchLen returns the number of limbs of scratch MulNTT needs for
+// operands of nx and ny limbs.
+func NTTScratchLen(nx, ny int) int {
+	if nx == 0 || ny == 0 {
+		return 0
+	}
+	return 2 * nttLen(nx, ny)
+}
+
+// MulNTT sets z = x·y for little-endian 64-bit limbs with a number-theoretic
+// transform, in O(n log n) word operations where the CIOS kernels take
+// O(n²). z needs at least len(x)+len(y) limbs, all of which are written,
+// and scratch at least NTTScratchLen(len(x), len(y)). z must not overlap x,
+// y or scratch; x and y may be the same slice, which saves a transform.
+//
+// Every pass of the transform walks its array as two sequential streams,
+// and the twiddle factors are generated on the way rather than tabulated,
+// so z, x, y and scratch may be the Limbs of LimbFiles: operands larger
+// than RAM page through the kernel's cache instead of living on the heap.
+func MulNTT(z, x, y, scratch []uint64) {
+	if len(z) < len(x)+len(y) {
+		panic(fmt.Sprintf("montgomery: MulNTT: z has %d limbs, want %d", len(z), len(x)+len(y)))
+	}
+	if len(x) == 0 || len(y) == 0 {
+		clear(z)
+		return
+	}
+	n := nttLen(len(x), len(y))
+	if len(scratch) < 2*n {
+		panic(fmt.Sprintf("montgomery: MulNTT: scratch has %d limbs, want %d", len(scratch), 2*n))
+	}
+	a, b := scratch[:n], scratch[n:2*n]
+	nttLoad(a, x)
+	if len(x) == len(y) && &x[0] == &y[0] {
+		b = a
+	} else {
+		nttLoad(b, y)
+	}
+	for i := range a {
+		a[i] = nttMul(a[i], b[i])
+	}
+	nttStore(z, a)
+}
+
+// nttLoad splits x into digits, zero-padded to len(a), and transforms them
+// into a, in bit-reversed order.
+func nttLoad(a, x []uint64) {
+	const per = 64 / nttDigit
+	for i, w := range x {
+		for j := range per {
+			a[i*per+j] = w >> (j * nttDigit) & (1<<nttDigit - 1)
+		}
+	}
+	clear(a[len(x)*per:])
+	nttForward(a)
+}
+
+// nttStore transforms a, in bit-reversed order, back into coefficients,
+// overwriting it, and writes their carried sum into z. The inverse
+// transform's scaling by 1/len(a) is folded into the carry pass.
+func nttStore(z, a []uint64) {
+	const per = 64 / nttDigit
+	nttInverse(a)
+	nInv := nttP - (nttP-1)/uint64(len(a))
+	// The carry stays below 2^48 and a coefficient below 2^63, so their
+	// sum fits in a word
+	var carry uint64
+	for i := range z {
+		var w uint64
+		for j := range per {
+			if k := i*per + j; k < len(a) {
+				carry += nttMul(a[k], nInv)
+			}
+			w |= carry & (1<<nttDigit - 1) << (j * nttDigit)
+			carry >>= nttDigit
+		}
+		z[i] = w
+	}
+}
+
+// nttForward transforms a in place by decimation in frequency: natural
+// order in, bit-reversed order out. len(a) is a power of two.
+func nttForward(a []uint64) {
+	n := len(a)
+	for half := n / 2; half >= 1; half /= 2 {
+		w := nttPow(nttRoot32, uint64(1)<<nttMaxLog/uint64(2*half))
+		for start := 0; start < n; start += 2 * half {
+			lo, hi := a[start:start+half], a[start+half:start+2*half]
+			wj := uint64(1)
+			for j := range lo {
+				u, v := lo[j], hi[j]
+				lo[j] = nttAdd(u, v)
+				hi[j] = nttMul(nttSub(u, v), wj)
+				wj = nttMul(wj, w)
+			}
+		}
+	}
+}
+
+// nttInverse undoes nttForward up to a factor len(a) by decimation in time:
+// bit-reversed order in, natural order out.
+func nttInverse(a []uint64) {
+	n := len(a)
+	for half := 1; half < n; half *= 2 {
+		w := nttPow(nttRoot32, nttP-1-uint64(1)<<nttMaxLog/uint64(2*half))
+		for start := 0; start < n; start += 2 * half {
+			lo, hi := a[start:start+half], a[start+half:start+2*half]
+			wj := uint64(1)
+			for j := range lo {
+				u, v := lo[j], nttMul(hi[j], wj)
+				lo[j] = nttAdd(u, v)
+				hi[j] = nttSub(u, v)
+				wj = nttMul(wj, w)
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"testing/quick"
+)
+
+func TestNTTArith(t *testing.T) {
+	t.Parallel()
+
+	p := new(big.Int).SetUint64(nttP)
+	vals := []uint64{0, 1, 2, 1<<32 - 1, 1 << 32, 1<<32 + 1, 1 << 63, nttP - 2, nttP - 1}
+	rng := rand.New(rand.NewPCG(1, 2))
+	for range 32 {
+		vals = append(vals, rng.Uint64N(nttP))
+	}
+	for _, a := range vals {
+		for _, b := range vals {
+			A, B := new(big.Int).SetUint64(a), new(big.Int).SetUint64(b)
+			want := new(big.Int).Mul(A, B)
+			if got := nttMul(a, b); got != want.Mod(want, p).Uint64() {
+				t.Errorf("nttMul(%d, %d) = %d, want %d", a, b, got, want)
+			}
+			want.Add(A, B)
+			if got := nttAdd(a, b); got != want.Mod(want, p).Uint64() {
+				t.Errorf("nttAdd(%d, %d) = %d, want %d", a, b, got, want)
+			}
+			want.Sub(A, B)
+			if got := nttSub(a, b); got != want.Mod(want, p).Uint64() {
+				t.Errorf("nttSub(%d, %d) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+	if nttPow(nttRoot32, 1<<31) != nttP-1 {
+		t.Error("nttRoot32 does not have order 2^32")
+	}
+}
+
+func TestNTT_roundTrip(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(3, 4))
+	for _, n := range []int{1, 2, 8, 1024} {
+		a := make([]uint64, n)
+		for i := range a {
+			a[i] = rng.Uint64N(nttP)
+		}
+		got := slices.Clone(a)
+		nttForward(got)
+		nttInverse(got)
+		nInv := nttP - (nttP-1)/uint64(n)
+		for i := range got {
+			if v := nttMul(got[i], nInv); v != a[i] {
+				t.Fatalf("n=%d: coefficient %d = %d after the round trip, want %d", n, i, v, a[i])
+			}
+		}
+	}
+}
+
+func TestMulNTT(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(5, 6))
+	random := func(n int) []uint64 {
+		x := make([]uint64, n)
+		for i := range x {
+			x[i] = rng.Uint64()
+		}
+		return x
+	}
+	ones := func(n int) []uint64 {
+		x := make([]uint64, n)
+		for i := range x {
+			x[i] = 1<<64 - 1
+		}
+		return x
+	}
+	tests := []struct {
+		name string
+		x, y []uint64
+	}{
+		{"empty", nil, random(3)},
+		{"one limb", random(1), random(1)},
+		{"unequal", random(3), random(17)},
+		{"all ones", ones(64), ones(64)},
+		{"all ones, unequal", ones(5), ones(300)},
+		{"large", random(1000), random(1000)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			want := new(big.Int).Mul(tobigInt(tc.x), tobigInt(tc.y))
+			z := random(len(tc.x) + len(tc.y) + 2) // garbage, including past the product
+			MulNTT(z, tc.x, tc.y, make([]uint64, NTTScratchLen(len(tc.x), len(tc.y))))
+			if got := tobigInt(z); got.Cmp(want) != 0 {
+				t.Errorf("MulNTT = %x, want %x", got, want)
+			}
+			// Squaring shares one transform between the operands
+			want.Mul(tobigInt(tc.y), tobigInt(tc.y))
+			z = make([]uint64, 2*len(tc.y))
+			MulNTT(z, tc.y, tc.y, make([]uint64, NTTScratchLen(len(tc.y), len(tc.y))))
+			if got := tobigInt(z); got.Cmp(want) != 0 {
+				t.Errorf("MulNTT(y, y) = %x, want %x", got, want)
+			}
+		})
+	}
+
+	t.Run("quick", func(t *testing.T) {
+		t.Parallel()
+		f := func(x, y []uint64) bool {
+			z := make([]uint64, len(x)+len(y))
+			MulNTT(z, x, y, make([]uint64, NTTScratchLen(len(x), len(y))))
+			return tobigInt(z).Cmp(new(big.Int).Mul(tobigInt(x), tobigInt(y))) == 0
+		}
+		if err := quick.Check(f, nil); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+// BenchmarkMulNTT compares MulNTT with big.Int.Mul, whose Karatsuba
+// multiplication it overtakes at about 2^17 limbs on amd64.
+func BenchmarkMulNTT(b *testing.B) {
+	rng := rand.New(rand.NewPCG(7, 8))
+	for _, limbs := range []int{1 << 10, 1 << 14, 1 << 17} {
+		x, y := make([]uint64, limbs), make([]uint64, limbs)
+		for i := range x {
+			x[i], y[i] = rng.Uint64(), rng.Uint64()
+		}
+		z, scratch := make([]uint64, 2*limbs), make([]uint64, NTTScratchLen(limbs, limbs))
+		b.Run(fmt.Sprintf("limbs=%d/impl=ntt", limbs), func(b *testing.B) {
+			for b.Loop() {
+				MulNTT(z, x, y, scratch)
+			}
+		})
+		X, Y, Z := tobigInt(x), tobigInt(y), new(big.Int)
+		b.Run(fmt.Sprintf("limbs=%d/impl=big", limbs), func(b *testing.B) {
+			for b.Loop() {
+				Z.Mul(X, Y)
+			}
+		})
+	}
+}
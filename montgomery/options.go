@@ -0,0 +1,118 @@
+package montgomery
+
+import (
+	"io"
+	"math/big"
+)
+
+// Option configures optional behaviour of a Montgomery context at
+// construction, e.g. NewMontgomeryCIOSWords(R, N, WithMemoryBudget(1<<20)).
+type Option func(*config)
+
+// config holds the settings applied by Options. The zero value is the default.
+type config struct {
+	memoryBudget int    // bytes for precomputed tables per call; 0 means unlimited
+	backend      string // backend name for Open; empty means BackendEnv or the default
+	reduction    Reduction
+	reductionSet bool // reduction was chosen by WithReduction rather than by size
+	input        InputPolicy
+	amm          bool     // almost Montgomery multiplication was requested
+	faultCheck   *big.Int // public exponent CRT results are verified with; nil means none
+	window       WindowStrategy
+	windowSize   int // window width in bits; 0 means chosen per exponent
+	tableCache   int // bases whose window tables Exp keeps; 0 means none
+	tables       *tableCache
+	blind        blinding
+	selfTest     int       // random multiplications checked at construction; 0 means none
+	random       io.Reader // randomness for SelfCheck and the self-test; nil means crypto/rand.Reader
+	karatsuba    int       // limbs from which MontgomeryCIOSWords uses KAMM; 0 means the default, negative never
+	scratchDir   string    // directory Squarer maps its scratch LimbFiles in; empty means the heap
+}
+
+// newConfig applies opts in order to the default configuration.
+func newConfig(opts []Option) config {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	// Every context gets a cache of its own, even if opts are shared
+	cfg.tables = newTableCache(cfg.tableCache)
+	return cfg
+}
+
+// WithMemoryBudget bounds the memory, in bytes, that algorithms with a
+// time/memory trade-off may spend on precomputed tables within one call.
+//
+// Window exponentiation shrinks its window, and ExpBatch processes the bases
+// in smaller groups, until the tables fit. The algorithms never fail for
+// lack of budget; they only get slower, down to plain square-and-multiply on
+// one base at a time. A budget of 0 or less means unlimited.
+func WithMemoryBudget(bytes int) Option {
+	return func(c *config) {
+		c.memoryBudget = max(bytes, 0)
+	}
+}
+
+// maxEntries returns how many Montgomery elements of elemBytes bytes each
+// fit in the memory budget, or 0 if the budget is unlimited. A non-zero
+// budget always admits at least one element.
+func (c config) maxEntries(elemBytes int) int {
+	if c.memoryBudget == 0 {
+		return 0
+	}
+	return max(c.memoryBudget/max(elemBytes, 1), 1)
+}
+
+// WithReduction forces MontgomeryCIOSWords to use interleaved CIOS
+// (ReductionMontgomery) or the separated product-then-reduce REDC
+// (ReductionMontgomerySeparated) regardless of the modulus size. Forcing the
+// separated strategy precomputes the full-width -N⁻¹ mod R, as needed by
+// multiplication paths that produce the whole product before reducing. Other
+// values select interleaved CIOS; other implementations ignore the option.
+func WithReduction(r Reduction) Option {
+	return func(c *config) {
+		c.reduction, c.reductionSet = r, true
+	}
+}
+
+// WithKaratsuba makes MontgomeryCIOSWords multiply by KAMM, a Karatsuba
+// product followed by a word-by-word reduction, for moduli whose R has at
+// least limbs 64-bit words, and by interleaved CIOS below. A limbs of 0 or
+// less turns KAMM off. Without the option KAMM starts at 48 limbs on
+// platforms whose CIOS kernel is in Go, and is off where it is in assembly.
+// Other implementations ignore the option.
+func WithKaratsuba(limbs int) Option {
+	return func(c *config) {
+		c.karatsuba = limbs
+		if limbs <= 0 {
+			c.karatsuba = -1
+		}
+	}
+}
+
+// WithAlmostMontgomery makes the limb-based exponentiations of
+// MontgomeryCIOSWords (ExpConstantTime, FixedBase.Exp) and of MontgomeryCT
+// use almost Montgomery multiplication: intermediate results stay in
+// [0, 2N) instead of [0, N), so no multiplication of the chain performs, or
+// masks, a final subtraction, and one full reduction on the way out makes
+// the result canonical.
+//
+// AMM needs 4N ≤ R. The plain constructors silently keep full reductions
+// when R is too small; the Checked ones reject it with ErrAMMBound.
+func WithAlmostMontgomery() Option {
+	return func(c *config) {
+		c.amm = true
+	}
+}
+
+// WithFaultCheck makes CRT verify every result y = x^d mod N by raising it
+// to the public exponent e and comparing y^e with x, so that a fault
+// injected into one of the prime exponentiations is caught before the
+// result is released. e must be the public exponent belonging to the
+// private exponent d, or every result fails. Contexts other than CRT ignore
+// the option.
+func WithFaultCheck(e *big.Int) Option {
+	return func(c *config) {
+		c.faultCheck = new(big.Int).Set(e)
+	}
+}
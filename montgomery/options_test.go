@@ -0,0 +1,114 @@
+package montgomery
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestWithMemoryBudget(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	bases := []*big.Int{x, y, new(big.Int).Add(x, y), big.NewInt(3)}
+	exp := new(big.Int).Rsh(N, 1536) // dense 512-bit exponent
+
+	tests := []struct {
+		name   string
+		budget int
+	}{
+		{"unlimited", 0},
+		{"negative means unlimited", -1},
+		{"less than one element", 1},
+		{"two elements", 2 * 256},
+		{"ten elements", 10 * 256},
+		{"generous", 1 << 20},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := NewMontgomeryCIOSWords(R, N, WithMemoryBudget(tc.budget))
+			got := m.ExpBatch(bases, exp)
+			for i, base := range bases {
+				want := new(big.Int).Exp(base, exp, N)
+				if got[i].Cmp(want) != 0 {
+					t.Errorf("ExpBatch base[%d]: got %v, want %v", i, got[i], want)
+				}
+			}
+
+			want := new(big.Int).Exp(x, exp, N)
+			if got := m.modExp(x, exp); got.Cmp(want) != 0 {
+				t.Errorf("modExp: got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func Test_planWindow(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	dense := new(big.Int).Rsh(N, 1536) // 512-bit exponent, window 4 when unlimited
+
+	tests := []struct {
+		name      string
+		budget    int
+		n         int
+		wantW     int
+		wantGroup int
+	}{
+		{"unlimited", 0, 8, 4, 8},
+		{"fits exactly", 8 * 16 * 256, 8, 4, 8},
+		{"window shrinks first", 8 * 8 * 256, 8, 3, 8},
+		{"down to 1-bit window", 8 * 2 * 256, 8, 1, 8},
+		{"then the group shrinks", 4 * 256, 8, 1, 2},
+		{"one base at a time", 1, 8, 1, 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := NewMontgomeryCIOSWords(R, N, WithMemoryBudget(tc.budget))
+			w, group := planWindow(m.engine(), tc.n, dense)
+			if w != tc.wantW || group != tc.wantGroup {
+				t.Errorf("planWindow() = (%d, %d); want (%d, %d)", w, group, tc.wantW, tc.wantGroup)
+			}
+		})
+	}
+}
+
+func TestWithReduction(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	_, _, RL, NL := testParamsLarge(8192)
+	tests := []struct {
+		name          string
+		R, N          *big.Int
+		opts          []Option
+		wantSeparated bool
+	}{
+		{"small default", R, N, nil, false},
+		{"small forced separated", R, N, []Option{WithReduction(ReductionMontgomerySeparated)}, true},
+		{"large default", RL, NL, nil, true},
+		{"large forced interleaved", RL, NL, []Option{WithReduction(ReductionMontgomery)}, false},
+		{"generic means interleaved", RL, NL, []Option{WithReduction(ReductionGeneric)}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			m := NewMontgomeryCIOSWords(tc.R, tc.N, tc.opts...)
+			if got := m.np != nil; got != tc.wantSeparated {
+				t.Errorf("separated = %v, want %v", got, tc.wantSeparated)
+			}
+			a, b := new(big.Int).Mod(x, tc.N), new(big.Int).Mod(y, tc.N)
+			want := new(big.Int).Mul(a, b)
+			if got := m.Mul(a, b); got.Cmp(want.Mod(want, tc.N)) != 0 {
+				t.Errorf("Mul() = %v, want %v", got, want)
+			}
+		})
+	}
+}
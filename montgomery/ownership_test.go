@@ -0,0 +1,243 @@
+package montgomery
+
+import (
+	"math/big"
+	mrand "math/rand/v2"
+	"sync"
+	"testing"
+)
+
+// clobber overwrites every x in place, as a caller reusing its big.Ints
+// would.
+func clobber(xs ...*big.Int) {
+	for _, x := range xs {
+		x.SetInt64(3)
+	}
+}
+
+// TestOwnership_arguments modifies every big.Int handed to a constructor or
+// option after the call and checks that the context still computes what it
+// did before.
+func TestOwnership_arguments(t *testing.T) {
+	t.Parallel()
+
+	_, _, R2048, N2048 := testParams2048()
+	p, q := testPrimes(256)
+	factors := testFactors(64)
+	n := new(big.Int).Mul(p, q)
+	x := new(big.Int).Sub(n, big.NewInt(12345))
+	e := big.NewInt(65537)
+	d := new(big.Int).ModInverse(e, new(big.Int).Mul(new(big.Int).Sub(p, big.NewInt(1)), new(big.Int).Sub(q, big.NewInt(1))))
+	clone := func(x *big.Int) *big.Int { return new(big.Int).Set(x) }
+
+	tests := []struct {
+		name string
+		// build constructs from its own copies of the arguments and returns
+		// the computation to compare and the arguments to clobber
+		build func() (func() *big.Int, []*big.Int)
+	}{
+		{"NewMontgomeryBitwise", func() (func() *big.Int, []*big.Int) {
+			R, N := clone(R2048), clone(N2048)
+			m := NewMontgomeryBitwise(R, N)
+			return func() *big.Int { return m.Mul(x, x) }, []*big.Int{R, N}
+		}},
+		{"NewMontgomeryCIOS", func() (func() *big.Int, []*big.Int) {
+			R, N := clone(R2048), clone(N2048)
+			m := NewMontgomeryCIOS(R, N)
+			return func() *big.Int { return m.Mul(x, x) }, []*big.Int{R, N}
+		}},
+		{"NewMontgomeryCIOSWords", func() (func() *big.Int, []*big.Int) {
+			R, N := clone(R2048), clone(N2048)
+			m := NewMontgomeryCIOSWords(R, N)
+			return func() *big.Int { return m.Exp(x, e) }, []*big.Int{R, N}
+		}},
+		{"NewMontgomeryCT", func() (func() *big.Int, []*big.Int) {
+			R, N := clone(R2048), clone(N2048)
+			m := NewMontgomeryCT(R, N)
+			return func() *big.Int { return m.Mul(x, x) }, []*big.Int{R, N}
+		}},
+		{"NewMontgomeryCarrySave", func() (func() *big.Int, []*big.Int) {
+			R, N := clone(R2048), clone(N2048)
+			m := NewMontgomeryCarrySave(R, N)
+			return func() *big.Int { return m.Mul(x, x) }, []*big.Int{R, N}
+		}},
+		{"NewCRT", func() (func() *big.Int, []*big.Int) {
+			p, q, d, e := clone(p), clone(q), clone(d), clone(e)
+			c, err := NewCRT(p, q, d, WithFaultCheck(e))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return func() *big.Int { return c.Exp(x) }, []*big.Int{p, q, d, e}
+		}},
+		{"NewCRTMulti blinded", func() (func() *big.Int, []*big.Int) {
+			primes, d, order := []*big.Int{clone(p), clone(q)}, clone(d), clone(n)
+			c, err := NewCRTMulti(primes, d, WithExponentBlinding(mrand.NewChaCha8([32]byte{}), order))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return func() *big.Int { return c.Exp(x) }, []*big.Int{primes[0], primes[1], d, order}
+		}},
+		{"NewCompositeCtx", func() (func() *big.Int, []*big.Int) {
+			fs := make([]Factor, len(factors))
+			args := make([]*big.Int, len(factors))
+			for i, f := range factors {
+				fs[i] = Factor{clone(f.P), f.E}
+				args[i] = fs[i].P
+			}
+			c, err := NewCompositeCtx(fs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return func() *big.Int { return c.Exp(x, e) }, args
+		}},
+		{"NewFixedBase", func() (func() *big.Int, []*big.Int) {
+			g := clone(x)
+			f := NewMontgomeryCIOSWords(R2048, N2048).NewFixedBase(g, 64, 4)
+			return func() *big.Int { return f.Exp(e) }, []*big.Int{g}
+		}},
+		{"NewOddPowers", func() (func() *big.Int, []*big.Int) {
+			g := clone(x)
+			o := NewMontgomeryCIOS(R2048, N2048).NewOddPowers(g, 4)
+			return func() *big.Int { return o.Exp(e) }, []*big.Int{g}
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			f, args := tc.build()
+			want := f()
+			clobber(args...)
+			if got := f(); got == nil || got.Cmp(want) != 0 {
+				t.Errorf("after modifying the arguments got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// TestOwnership_results checks that no result aliases an argument or a
+// context's own state: modifying one changes neither the arguments nor
+// the next result.
+func TestOwnership_results(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N, WithTableCache(4))
+	ct := NewMontgomeryCT(R, N)
+	c, err := NewCompositeCtx(testFactors(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	group, err := c.Group()
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := new(big.Int).Sub(N, big.NewInt(2))
+	fb := m.NewFixedBase(x, 64, 4)
+	op := m.NewOddPowers(x, 4)
+
+	tests := []struct {
+		name string
+		f    func(x, e *big.Int) *big.Int
+	}{
+		{"Modulus", func(x, e *big.Int) *big.Int { return m.Modulus() }},
+		{"CT.Modulus", func(x, e *big.Int) *big.Int { return ct.Modulus() }},
+		{"CompositeCtx.Modulus", func(x, e *big.Int) *big.Int { return c.Modulus() }},
+		{"Mul", m.Mul},
+		{"Exp", m.Exp},
+		{"ExpConstantTime", m.ExpConstantTime},
+		{"ExpLadder", m.ExpLadder},
+		{"CT.Exp", ct.Exp},
+		{"MultiExp", func(x, e *big.Int) *big.Int { return m.MultiExp([]*big.Int{x}, []*big.Int{e}) }},
+		{"ExpBatch", func(x, e *big.Int) *big.Int { return m.ExpBatch([]*big.Int{x}, e)[0] }},
+		{"FromMont", func(x, e *big.Int) *big.Int { return m.FromMont(m.ToMont(x)) }},
+		{"Halve", func(x, e *big.Int) *big.Int { return m.Halve(x) }},
+		{"FixedBase.Base", func(x, e *big.Int) *big.Int { return fb.Base() }},
+		{"FixedBase.Exp", func(x, e *big.Int) *big.Int { return fb.Exp(e) }},
+		{"OddPowers.Base", func(x, e *big.Int) *big.Int { return op.Base() }},
+		{"OddPowers.Exp", func(x, e *big.Int) *big.Int { return op.Exp(e) }},
+		{"CompositeCtx.Exp", c.Exp},
+		{"Group.Order", func(x, e *big.Int) *big.Int { return group.Order() }},
+		{"Group.Exponent", func(x, e *big.Int) *big.Int { return group.Exponent() }},
+		{"Group.InvariantFactors", func(x, e *big.Int) *big.Int { return group.InvariantFactors()[0] }},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			// Exponents 0 and 1 are where a shortcut returning an argument
+			// would show up
+			for _, ei := range []int64{0, 1, 65537} {
+				x, e := big.NewInt(12345), big.NewInt(ei)
+				want := tc.f(x, e)
+				got := tc.f(x, e)
+				clobber(got)
+				if x.Int64() != 12345 || e.Int64() != ei {
+					t.Fatalf("e = %d: modifying the result changed an argument", ei)
+				}
+				if again := tc.f(x, e); again.Cmp(want) != 0 {
+					t.Fatalf("e = %d: after modifying a result got %v, want %v", ei, again, want)
+				}
+			}
+		})
+	}
+}
+
+// TestOwnership_concurrent shares one context of each kind between
+// goroutines while the caller reuses the big.Ints it constructed them
+// from. It only has teeth under the race detector, which reports a context
+// that reads a caller's value or state written by another operation; CI
+// runs it there through make test-race.
+func TestOwnership_concurrent(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	p, q := testPrimes(256)
+	R, N, p, q = new(big.Int).Set(R), new(big.Int).Set(N), new(big.Int).Set(p), new(big.Int).Set(q)
+	d := new(big.Int).Rsh(q, 1)
+	words := NewMontgomeryCIOSWords(R, N, WithTableCache(2))
+	ct := NewMontgomeryCT(R, N)
+	cios := NewMontgomeryCIOS(R, N)
+	crt, err := NewCRT(p, q, d, WithExponentBlinding(lockedReader(1), p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fb := words.NewFixedBase(big.NewInt(5), 256, 4)
+
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Go(func() {
+			x := big.NewInt(int64(1000 + i%2))
+			e := big.NewInt(65537)
+			for range 5 {
+				crt.Exp(x)
+				words.Mul(x, x)
+				words.Exp(x, e)
+				words.ExpConstantTime(x, e)
+				words.InvalidateTable(x)
+				ct.Exp(x, e)
+				cios.Exp(x, e)
+				fb.Exp(e)
+			}
+		})
+	}
+	wg.Go(func() { clobber(R, N, p, q, d) })
+	wg.Wait()
+}
+
+// lockedReader is a deterministic random source that may be shared between
+// goroutines.
+func lockedReader(seed byte) *syncReader {
+	return &syncReader{r: mrand.NewChaCha8([32]byte{seed})}
+}
+
+type syncReader struct {
+	mu sync.Mutex
+	r  *mrand.ChaCha8
+}
+
+func (s *syncReader) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.Read(p)
+}
@@ -0,0 +1,235 @@
+package montgomery
+
+import (
+	"errors"
+	"math/big"
+	"slices"
+	"testing"
+)
+
+// fixedPi returns ⌊π·2^prec⌋ by Machin's formula, π = 16·atan(1/5) - 4·atan(1/239).
+func fixedPi(prec uint) *big.Int {
+	const guard = 64
+	atanInv := func(x int64) *big.Int {
+		one := new(big.Int).Lsh(big.NewInt(1), prec+guard)
+		x2 := big.NewInt(x * x)
+		term := one.Quo(one, big.NewInt(x))
+		sum := new(big.Int).Set(term)
+		q := new(big.Int)
+		for k := int64(1); term.Sign() != 0; k++ {
+			term.Quo(term, x2)
+			q.Quo(term, big.NewInt(2*k+1))
+			if k%2 == 1 {
+				sum.Sub(sum, q)
+			} else {
+				sum.Add(sum, q)
+			}
+		}
+		return sum
+	}
+	pi := new(big.Int).Mul(atanInv(5), big.NewInt(16))
+	pi.Sub(pi, new(big.Int).Mul(atanInv(239), big.NewInt(4)))
+	return pi.Rsh(pi, guard)
+}
+
+// fixedE returns ⌊e·2^prec⌋ from the series Σ 1/k!.
+func fixedE(prec uint) *big.Int {
+	const guard = 64
+	term := new(big.Int).Lsh(big.NewInt(1), prec+guard)
+	sum := new(big.Int)
+	for k := int64(1); term.Sign() != 0; k++ {
+		sum.Add(sum, term)
+		term.Quo(term, big.NewInt(k))
+	}
+	return sum.Rsh(sum, guard)
+}
+
+// pow2 returns the sum of 2^k over the given terms k ≥ 0, less 2^k for
+// each term -(k+1), so that -1 subtracts 2^0.
+func pow2(terms ...int) *big.Int {
+	sum := new(big.Int)
+	for _, k := range terms {
+		if k < 0 {
+			sum.Sub(sum, new(big.Int).Lsh(big.NewInt(1), uint(-k-1)))
+		} else {
+			sum.Add(sum, new(big.Int).Lsh(big.NewInt(1), uint(k)))
+		}
+	}
+	return sum
+}
+
+// TestLookupParams rederives every constant from its defining formula
+// rather than comparing hex against hex.
+func TestLookupParams(t *testing.T) {
+	t.Parallel()
+
+	const prec = 8192
+	// RFC 3526 and RFC 7919 both take N = 2^b - 2^(b-64) - 1 +
+	// 2^64·(⌊2^(b-130)·c⌋ + k), with c = π and c = e respectively
+	rfc := func(c *big.Int) func(b uint, k int64) *big.Int {
+		return func(b uint, k int64) *big.Int {
+			n := new(big.Int).Rsh(c, prec-(b-130))
+			n.Add(n, big.NewInt(k)).Lsh(n, 64)
+			return n.Add(n, pow2(int(b), -(int(b)-64)-1, -1))
+		}
+	}
+	modp, ffdhe := rfc(fixedPi(prec)), rfc(fixedE(prec))
+
+	tests := []struct {
+		name string
+		want *big.Int
+	}{
+		{"modp1536", modp(1536, 741804)},
+		{"modp2048", modp(2048, 124476)},
+		{"modp3072", modp(3072, 1690314)},
+		{"modp4096", modp(4096, 240904)},
+		{"modp6144", modp(6144, 929484)},
+		{"modp8192", modp(8192, 4743158)},
+		{"ffdhe2048", ffdhe(2048, 560316)},
+		{"ffdhe3072", ffdhe(3072, 2625351)},
+		{"ffdhe4096", ffdhe(4096, 5736041)},
+		{"ffdhe6144", ffdhe(6144, 15705020)},
+		{"ffdhe8192", ffdhe(8192, 10965728)},
+		{"p192", pow2(192, -65, -1)},
+		{"p224", pow2(224, -97, 0)},
+		{"p256", pow2(256, -225, 192, 96, -1)},
+		{"p384", pow2(384, -129, -97, 32, -1)},
+		{"p521", pow2(521, -1)},
+		{"curve25519", new(big.Int).Sub(pow2(255), big.NewInt(19))},
+		{"curve448", pow2(448, -225, -1)},
+		{"ntt167772161", big.NewInt(5<<25 + 1)},
+		{"ntt469762049", big.NewInt(7<<26 + 1)},
+		{"ntt998244353", big.NewInt(119<<23 + 1)},
+		{"babybear", big.NewInt(15<<27 + 1)},
+		{"goldilocks", pow2(64, -33, 0)},
+	}
+
+	if got := len(ParamNames()); got != len(tests) {
+		t.Errorf("len(ParamNames()) = %d, want %d", got, len(tests))
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			p, err := LookupParams(tc.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Name != tc.name || p.Source == "" {
+				t.Errorf("Name = %q, Source = %q", p.Name, p.Source)
+			}
+			if p.N.Cmp(tc.want) != 0 {
+				t.Fatalf("N = %x, want %x", p.N, tc.want)
+			}
+			if !p.N.ProbablyPrime(0) {
+				t.Errorf("N is not prime")
+			}
+			if p.G == nil {
+				if p.Q != nil {
+					t.Errorf("Q = %v without a generator", p.Q)
+				}
+				return
+			}
+			if new(big.Int).Exp(p.G, p.Q, p.N).Cmp(big.NewInt(1)) != 0 {
+				t.Errorf("G^Q ≠ 1")
+			}
+			// G has order exactly Q: no G^(Q/q) is 1 for a prime q | Q
+			var factors []*big.Int
+			if p.Q.Bit(0) == 1 {
+				factors = []*big.Int{p.Q}
+				if !p.Q.ProbablyPrime(0) {
+					t.Errorf("Q is not prime")
+				}
+			} else if factors, err = factorize(p.Q, rhoSteps); err != nil {
+				t.Fatal(err)
+			}
+			for _, q := range factors {
+				d := new(big.Int).Quo(p.Q, q)
+				if new(big.Int).Exp(p.G, d, p.N).Cmp(big.NewInt(1)) == 0 {
+					t.Errorf("G^(Q/%v) = 1", q)
+				}
+			}
+		})
+	}
+}
+
+func TestLookupParams_copies(t *testing.T) {
+	t.Parallel()
+
+	p, err := LookupParams("modp2048")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := new(big.Int).Set(p.N)
+	p.N.SetInt64(7)
+	p.G.SetInt64(3)
+	p.Q.SetInt64(5)
+	p, err = LookupParams("modp2048")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.N.Cmp(want) != 0 || p.G.Int64() != 2 || p.Q.Cmp(new(big.Int).Rsh(want, 1)) != 0 {
+		t.Errorf("LookupParams() returned the registry's own big.Ints")
+	}
+}
+
+func TestLookupParams_unknown(t *testing.T) {
+	t.Parallel()
+
+	if _, err := LookupParams("modp1024"); !errors.Is(err, ErrUnknownParams) {
+		t.Errorf("LookupParams() error = %v, want %v", err, ErrUnknownParams)
+	}
+	if _, err := OpenNamed("P-256"); !errors.Is(err, ErrUnknownParams) {
+		t.Errorf("OpenNamed() error = %v, want %v", err, ErrUnknownParams)
+	}
+	if !slices.IsSorted(ParamNames()) {
+		t.Errorf("ParamNames() = %v, want sorted", ParamNames())
+	}
+}
+
+func TestOpenNamed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "ntt998244353"},
+		{name: "goldilocks"},
+		{name: "curve25519"},
+		{name: "p521", opts: []Option{WithBackend("cios")}},
+		{name: "ffdhe3072"},
+		{name: "modp2048", opts: []Option{WithBackend("ct")}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			m, err := OpenNamed(tc.name, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, _ := LookupParams(tc.name)
+			if m.Modulus().Cmp(p.N) != 0 {
+				t.Fatalf("Modulus() = %v, want %v", m.Modulus(), p.N)
+			}
+			x := new(big.Int).Sub(p.N, big.NewInt(2))
+			y := new(big.Int).Rsh(p.N, 1)
+			want := new(big.Int).Mul(x, y)
+			if got := m.Mul(x, y); got.Cmp(want.Mod(want, p.N)) != 0 {
+				t.Errorf("Mul() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func BenchmarkOpenNamed(b *testing.B) {
+	for _, name := range []string{"p256", "modp2048", "ffdhe8192"} {
+		b.Run("params="+name, func(b *testing.B) {
+			for b.Loop() {
+				if _, err := OpenNamed(name); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Plantard32 is Plantard's modular multiplication for an odd modulus q
+// below 2^30, the size of NTT coefficient moduli such as Kyber's 3329 and
+// Dilithium's 8380417, in the signed variant of Huang et al. (TCHES 2022).
+//
+// Where Montgomery reduction of a·b needs three multiplications, Plantard's
+// needs two once one factor is precomputed, as NTT twiddle factors are:
+// with w = b·(-2^64)·q⁻¹ mod 2^64 from Twiddle,
+//
+//	a·b mod q = (((a·w mod± 2^64) >> 32 + 2^α) · q) >> 32
+//
+// in signed 64-bit arithmetic, with no correction step. Results are the
+// signed representatives in [-⌊q/2⌋, ⌊q/2⌋], and inputs may be any signed
+// values up to Bound = q·2^α in absolute value, where α = 31 - bitlen(q) is
+// the headroom q leaves in 32 bits. Sums of several products therefore need
+// no reduction in between, which is what NTT butterflies exploit.
+//
+// Operands outside the bound are not checked and give wrong results. A
+// Plantard32 is immutable and safe for concurrent use.
+type Plantard32 struct {
+	q     int32
+	alpha uint
+	qInv  uint64 // q⁻¹ mod 2^64
+	negR  int64  // -2^64 mod q, in [0, q)
+	k     int32  // (-2^64)² mod q, signed, undoing two reductions
+}
+
+// NewPlantard32 precomputes the constants for q. It returns an error
+// wrapping ErrEvenModulus or ErrModulusTooSmall for an even q or one below
+// 3, and ErrInvalidParameters for q ≥ 2^30, which leaves Plantard's
+// reduction no headroom.
+func NewPlantard32(q uint32) (*Plantard32, error) {
+	switch {
+	case q <= 1:
+		return nil, ErrModulusTooSmall
+	case q&1 == 0:
+		return nil, ErrEvenModulus
+	case q >= 1<<30:
+		return nil, fmt.Errorf("%w: Plantard32 modulus must be below 2^30, got %d", ErrInvalidParameters, q)
+	}
+	n := uint64(q)
+	negR := n - bits.Rem64(1, 0, n)
+	hi, lo := bits.Mul64(negR, negR)
+	return &Plantard32{
+		q:     int32(q),
+		alpha: uint(31 - bits.Len32(q)),
+		qInv:  -newtonRaphsonInverse(n),
+		negR:  int64(negR),
+		k:     int32(centered(bits.Rem64(hi, lo, n), n)),
+	}, nil
+}
+
+// Modulus returns q.
+func (p *Plantard32) Modulus() int32 { return p.q }
+
+// Alpha returns α, the log2 of the input headroom.
+func (p *Plantard32) Alpha() uint { return p.alpha }
+
+// Bound returns q·2^α, the largest operand magnitude Mul and MulTwiddle
+// accept.
+func (p *Plantard32) Bound() int32 { return p.q << p.alpha }
+
+// Reduce returns c·(-2^-64) mod q in [-⌊q/2⌋, ⌊q/2⌋], for |c| ≤ Bound()².
+// It is the reduction step of Mul on its own, for callers that accumulate
+// products before reducing; the factor -2^-64 is then folded into one of
+// the operands, as Twiddle does.
+func (p *Plantard32) Reduce(c int64) int32 {
+	return p.finish(int64(uint64(c)*p.qInv) >> 32)
+}
+
+// Mul returns a·b mod q in [-⌊q/2⌋, ⌊q/2⌋], for |a|, |b| ≤ Bound(). With
+// neither factor precomputed it takes two reductions; MulTwiddle takes one.
+func (p *Plantard32) Mul(a, b int32) int32 {
+	return p.Reduce(int64(p.Reduce(int64(a)*int64(b))) * int64(p.k))
+}
+
+// Twiddle returns the precomputed form of b for MulTwiddle,
+// b·(-2^64)·q⁻¹ mod 2^64. b may be any int32.
+func (p *Plantard32) Twiddle(b int32) uint64 {
+	v := int64(b) % int64(p.q)
+	if v < 0 {
+		v += int64(p.q)
+	}
+	v = v * p.negR % int64(p.q)
+	return uint64(v) * p.qInv
+}
+
+// MulTwiddle returns a·b mod q in [-⌊q/2⌋, ⌊q/2⌋], for |a| ≤ Bound() and
+// w = Twiddle(b): two multiplications and no branches.
+func (p *Plantard32) MulTwiddle(a int32, w uint64) int32 {
+	return p.finish(int64(uint64(int64(a))*w) >> 32)
+}
+
+// finish is the second half of a reduction: ((t + 2^α)·q) >> 32 for the
+// high half t of the first product.
+func (p *Plantard32) finish(t int64) int32 {
+	return int32((t + 1<<p.alpha) * int64(p.q) >> 32)
+}
+
+// Plantard64 is Plantard32 for an odd modulus q below 2^62, on signed
+// 64-bit values with 128-bit intermediate products: α = 63 - bitlen(q),
+// twiddles are b·(-2^128)·q⁻¹ mod 2^128, and results are in
+// [-⌊q/2⌋, ⌊q/2⌋].
+//
+// Without a native 128-bit multiply the first product costs a full and a
+// half multiplication, so it gives up much of the advantage over a one-word
+// Montgomery REDC that Plantard32 has.
+type Plantard64 struct {
+	q     int64
+	alpha uint
+	qInv  [2]uint64 // q⁻¹ mod 2^128, low word first
+	negR  uint64    // -2^128 mod q
+	k     int64     // (-2^128)² mod q, signed
+}
+
+// NewPlantard64 precomputes the constants for q, with the errors of
+// NewPlantard32 for q ≥ 2^62.
+func NewPlantard64(q uint64) (*Plantard64, error) {
+	switch {
+	case q <= 1:
+		return nil, ErrModulusTooSmall
+	case q&1 == 0:
+		return nil, ErrEvenModulus
+	case q >= 1<<62:
+		return nil, fmt.Errorf("%w: Plantard64 modulus must be below 2^62, got %d", ErrInvalidParameters, q)
+	}
+	// q⁻¹ mod 2^64 lifted to 2^128 by one Newton step: with q·x0 = 1 + h·2^64,
+	// x0·(2 - q·x0) = x0 - x0·h·2^64
+	x0 := -newtonRaphsonInverse(q)
+	h, _ := bits.Mul64(q, x0)
+	r := bits.Rem64(1, 0, q) // 2^64 mod q
+	hi, lo := bits.Mul64(r, r)
+	negR := q - bits.Rem64(hi, lo, q)
+	hi, lo = bits.Mul64(negR, negR)
+	return &Plantard64{
+		q:     int64(q),
+		alpha: uint(63 - bits.Len64(q)),
+		qInv:  [2]uint64{x0, -x0 * h},
+		negR:  negR,
+		k:     centered(bits.Rem64(hi, lo, q), q),
+	}, nil
+}
+
+// Modulus returns q.
+func (p *Plantard64) Modulus() int64 { return p.q }
+
+// Alpha returns α, the log2 of the input headroom.
+func (p *Plantard64) Alpha() uint { return p.alpha }
+
+// Bound returns q·2^α, the largest operand magnitude Mul and MulTwiddle
+// accept.
+func (p *Plantard64) Bound() int64 { return p.q << p.alpha }
+
+// Reduce returns c·(-2^-128) mod q in [-⌊q/2⌋, ⌊q/2⌋] for the signed
+// 128-bit c = hi·2^64 + lo with |c| ≤ Bound()², as bits.Mul64 lays out a
+// product.
+func (p *Plantard64) Reduce(hi int64, lo uint64) int64 {
+	// The high word of c·q⁻¹ mod 2^128
+	h, _ := bits.Mul64(lo, p.qInv[0])
+	h += lo*p.qInv[1] + uint64(hi)*p.qInv[0]
+	return p.finish(int64(h))
+}
+
+// Mul returns a·b mod q in [-⌊q/2⌋, ⌊q/2⌋], for |a|, |b| ≤ Bound().
+func (p *Plantard64) Mul(a, b int64) int64 {
+	r := p.Reduce(mulSigned64(a, b))
+	return p.Reduce(mulSigned64(r, p.k))
+}
+
+// Twiddle returns the precomputed form of b for MulTwiddle,
+// b·(-2^128)·q⁻¹ mod 2^128, low word first. b may be any int64.
+func (p *Plantard64) Twiddle(b int64) [2]uint64 {
+	v := b % p.q
+	if v < 0 {
+		v += p.q
+	}
+	hi, lo := bits.Mul64(uint64(v), p.negR)
+	u := bits.Rem64(hi, lo, uint64(p.q))
+	hi, lo = bits.Mul64(u, p.qInv[0])
+	return [2]uint64{lo, hi + u*p.qInv[1]}
+}
+
+// MulTwiddle returns a·b mod q in [-⌊q/2⌋, ⌊q/2⌋], for |a| ≤ Bound() and
+// w = Twiddle(b), without branches.
+func (p *Plantard64) MulTwiddle(a int64, w [2]uint64) int64 {
+	// The high word of a·w mod 2^128, a sign-extended to 128 bits
+	h, _ := bits.Mul64(uint64(a), w[0])
+	h += uint64(a)*w[1] - w[0]&uint64(a>>63)
+	return p.finish(int64(h))
+}
+
+// finish returns ((t + 2^α)·q) >> 64 as the high word of the signed
+// product t·q plus 2^α·q.
+func (p *Plantard64) finish(t int64) int64 {
+	hi, lo := mulSigned64(t, p.q)
+	_, c := bits.Add64(lo, uint64(p.q)<<p.alpha, 0)
+	return hi + int64(c)
+}
+
+// mulSigned64 returns the signed 128-bit product a·b as its high and low
+// words.
+func mulSigned64(a, b int64) (int64, uint64) {
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	hi -= uint64(b)&uint64(a>>63) + uint64(a)&uint64(b>>63)
+	return int64(hi), lo
+}
+
+// centered returns the representative of v in [0, q) for odd q in
+// [-⌊q/2⌋, ⌊q/2⌋].
+func centered(v, q uint64) int64 {
+	if v > q/2 {
+		return int64(v) - int64(q)
+	}
+	return int64(v)
+}
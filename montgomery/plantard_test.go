@@ -0,0 +1,232 @@
+package montgomery
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	mrand "math/rand/v2"
+	"testing"
+)
+
+// plantardOperands returns the edges of [-bound, bound] and random values
+// inside it.
+func plantardOperands(rng *mrand.Rand, bound int64) []int64 {
+	xs := []int64{0, 1, -1, 2, -bound, bound, -bound + 1, bound - 1}
+	for range 200 {
+		x := rng.Int64N(bound + 1)
+		if rng.IntN(2) == 0 {
+			x = -x
+		}
+		xs = append(xs, x)
+	}
+	return xs
+}
+
+// checkPlantard reports got unless it is the centered representative of
+// want mod q.
+func checkPlantard(t *testing.T, op string, got int64, want *big.Int, q int64) {
+	t.Helper()
+	w := new(big.Int).Mod(want, big.NewInt(q)).Int64()
+	if w > q/2 {
+		w -= q
+	}
+	if got != w {
+		t.Errorf("q = %d: %s = %d, want %d", q, op, got, w)
+	}
+}
+
+func TestPlantard32(t *testing.T) {
+	t.Parallel()
+
+	for _, q := range []uint32{3, 5, 3329, 7681, 12289, 8380417, 1<<30 - 35} {
+		t.Run(fmt.Sprint(q), func(t *testing.T) {
+			t.Parallel()
+			p, err := NewPlantard32(q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := int64(p.Bound()), int64(q)<<(31-bitLen64(uint64(q))); got != want || p.Modulus() != int32(q) {
+				t.Fatalf("Modulus() = %d, Bound() = %d, want %d, %d", p.Modulus(), got, q, want)
+			}
+			Q := int64(q)
+			rng := mrand.New(mrand.NewPCG(uint64(q), 1))
+			xs := plantardOperands(rng, int64(p.Bound()))
+			// -2^-64 mod q, the factor Reduce leaves
+			negRInv := new(big.Int).ModInverse(new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 64)), big.NewInt(Q))
+			for i, a := range xs {
+				b := xs[(i*7+3)%len(xs)]
+				A, B := big.NewInt(a), big.NewInt(b)
+				ab := new(big.Int).Mul(A, B)
+				checkPlantard(t, fmt.Sprintf("Mul(%d, %d)", a, b), int64(p.Mul(int32(a), int32(b))), ab, Q)
+				checkPlantard(t, fmt.Sprintf("MulTwiddle(%d, Twiddle(%d))", a, b), int64(p.MulTwiddle(int32(a), p.Twiddle(int32(b)))), ab, Q)
+				checkPlantard(t, fmt.Sprintf("Reduce(%d·%d)", a, b), int64(p.Reduce(a*b)), ab.Mul(ab, negRInv), Q)
+			}
+			// Twiddle takes any int32
+			for _, b := range []int32{-1 << 31, 1<<31 - 1} {
+				checkPlantard(t, fmt.Sprintf("MulTwiddle(1, Twiddle(%d))", b), int64(p.MulTwiddle(1, p.Twiddle(b))), big.NewInt(int64(b)), Q)
+			}
+		})
+	}
+}
+
+func TestPlantard64(t *testing.T) {
+	t.Parallel()
+
+	for _, q := range []uint64{3, 3329, 8380417, 1<<31 - 1, 0x0fffffff00000001, 1<<61 - 1, 1<<62 - 57} {
+		t.Run(fmt.Sprint(q), func(t *testing.T) {
+			t.Parallel()
+			p, err := NewPlantard64(q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := p.Bound(), int64(q)<<(63-bitLen64(q)); got != want || p.Modulus() != int64(q) {
+				t.Fatalf("Modulus() = %d, Bound() = %d, want %d, %d", p.Modulus(), got, q, want)
+			}
+			Q := int64(q)
+			rng := mrand.New(mrand.NewPCG(q, 2))
+			xs := plantardOperands(rng, p.Bound())
+			negRInv := new(big.Int).ModInverse(new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 128)), big.NewInt(Q))
+			for i, a := range xs {
+				b := xs[(i*7+3)%len(xs)]
+				A, B := big.NewInt(a), big.NewInt(b)
+				ab := new(big.Int).Mul(A, B)
+				checkPlantard(t, fmt.Sprintf("Mul(%d, %d)", a, b), p.Mul(a, b), ab, Q)
+				checkPlantard(t, fmt.Sprintf("MulTwiddle(%d, Twiddle(%d))", a, b), p.MulTwiddle(a, p.Twiddle(b)), ab, Q)
+				hi, lo := mulSigned64(a, b)
+				checkPlantard(t, fmt.Sprintf("Reduce(%d·%d)", a, b), p.Reduce(hi, lo), ab.Mul(ab, negRInv), Q)
+			}
+			for _, b := range []int64{-1 << 63, 1<<63 - 1} {
+				checkPlantard(t, fmt.Sprintf("MulTwiddle(1, Twiddle(%d))", b), p.MulTwiddle(1, p.Twiddle(b)), big.NewInt(b), Q)
+			}
+		})
+	}
+}
+
+func TestNewPlantard_errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		q    uint64
+		want error
+	}{
+		{"zero", 0, ErrModulusTooSmall},
+		{"one", 1, ErrModulusTooSmall},
+		{"even", 3328, ErrEvenModulus},
+		{"too large", 1<<30 + 3, ErrInvalidParameters},
+	}
+	for _, tc := range tests {
+		if _, err := NewPlantard32(uint32(tc.q)); !errors.Is(err, tc.want) {
+			t.Errorf("NewPlantard32(%d): error = %v, want %v", tc.q, err, tc.want)
+		}
+		q := tc.q
+		if tc.name == "too large" {
+			q = 1<<62 + 3
+		}
+		if _, err := NewPlantard64(q); !errors.Is(err, tc.want) {
+			t.Errorf("NewPlantard64(%d): error = %v, want %v", q, err, tc.want)
+		}
+	}
+	// The largest moduli are accepted
+	if _, err := NewPlantard32(1<<30 - 1); err != nil {
+		t.Errorf("NewPlantard32(2^30-1): %v", err)
+	}
+	if _, err := NewPlantard64(1<<62 - 1); err != nil {
+		t.Errorf("NewPlantard64(2^62-1): %v", err)
+	}
+}
+
+func bitLen64(x uint64) uint { return uint(bits.Len64(x)) }
+
+// BenchmarkPlantard multiplies an NTT coefficient by a fixed twiddle
+// factor, the inner operation of a butterfly, with Plantard's reduction
+// against signed Montgomery and Barrett reductions of the same width and
+// the % operator. Each iteration chains 256 multiplications, so the
+// latency of the reduction is what is measured.
+func BenchmarkPlantard(b *testing.B) {
+	const q = 8380417 // Dilithium
+	p, err := NewPlantard32(q)
+	if err != nil {
+		b.Fatal(err)
+	}
+	const twiddle = 1753
+	w := p.Twiddle(twiddle)
+	// Montgomery with R = 2^32: b in Montgomery form, qInv = q⁻¹ mod 2^32
+	qInv32 := int32(uint32(-newtonRaphsonInverse(q)))
+	bMont := int32(int64(twiddle) << 32 % q)
+	// Barrett with the precomputed quotient of b·2^32 by q, as Shoup
+	bShoup := uint64(twiddle) << 32 / q
+	var sink int32
+	b.Run("bits=32/impl=plantard", func(b *testing.B) {
+		a := int32(12345)
+		for b.Loop() {
+			for range 256 {
+				a = p.MulTwiddle(a, w)
+			}
+		}
+		sink += a
+	})
+	b.Run("bits=32/impl=montgomery", func(b *testing.B) {
+		a := int32(12345)
+		for b.Loop() {
+			for range 256 {
+				c := int64(a) * int64(bMont)
+				m := int32(c) * qInv32
+				a = int32((c - int64(m)*q) >> 32)
+			}
+		}
+		sink += a
+	})
+	b.Run("bits=32/impl=barrett", func(b *testing.B) {
+		a := uint32(12345)
+		for b.Loop() {
+			for range 256 {
+				hi := uint64(a) * bShoup >> 32
+				r := uint32(uint64(a)*twiddle - hi*q)
+				r -= q & uint32(int32(q-1-r)>>31)
+				a = r
+			}
+		}
+		sink += int32(a)
+	})
+	b.Run("bits=32/impl=rem", func(b *testing.B) {
+		a := int64(12345)
+		for b.Loop() {
+			for range 256 {
+				a = a * twiddle % q
+			}
+		}
+		sink += int32(a)
+	})
+
+	const q64 = 1<<61 - 1
+	p64, err := NewPlantard64(q64)
+	if err != nil {
+		b.Fatal(err)
+	}
+	w64 := p64.Twiddle(twiddle)
+	ni := newtonRaphsonInverse(q64)
+	r := bits.Rem64(twiddle, 0, q64) // twiddle·2^64 mod q, in Montgomery form
+	var sink64 int64
+	b.Run("bits=64/impl=plantard", func(b *testing.B) {
+		a := int64(12345)
+		for b.Loop() {
+			for range 256 {
+				a = p64.MulTwiddle(a, w64)
+			}
+		}
+		sink64 += a
+	})
+	b.Run("bits=64/impl=montgomery", func(b *testing.B) {
+		a := uint64(12345)
+		for b.Loop() {
+			for range 256 {
+				a = redc1(a, r, q64, ni)
+			}
+		}
+		sink64 += int64(a)
+	})
+	_ = sink
+	_ = sink64
+}
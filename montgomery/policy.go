@@ -0,0 +1,134 @@
+package montgomery
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// InputPolicy decides what a context does with operands outside [0, N).
+//
+// The REDC kernels are only correct for operands in range, so every entry
+// point taking plain integers (Mul, MulInto, MulBatch, Exp, ExpBatch,
+// MultiExp, ExpConstantTime, ToMont, NewFixedBase and NewOddPowers)
+// applies the policy before reducing; Reduce applies it with N·R as the
+// bound. The word-level API of MontgomeryCIOSWords (MulWords,
+// MulMontWords, ToMontWords, FromMontWords, AddWords, SubWords, NegWords
+// and ReduceWords) applies it too. MontElement operands are always in range and are never checked,
+// and neither are MontgomeryCT's limbs, since the check would branch on
+// them. The check itself is a sign test and one comparison; only a
+// permissive reduction costs a division.
+type InputPolicy uint8
+
+const (
+	// InputPermissive reduces out-of-range operands mod N on entry, the
+	// convenient choice for scripts and the default.
+	InputPermissive InputPolicy = iota
+	// InputStrict rejects out-of-range operands, for libraries that want
+	// an unnormalized value to fail fast rather than be silently reduced.
+	// MulChecked and ExpChecked return an error wrapping ErrOperandRange;
+	// the methods without an error result panic with it.
+	InputStrict
+)
+
+func (p InputPolicy) String() string {
+	switch p {
+	case InputPermissive:
+		return "permissive"
+	case InputStrict:
+		return "strict"
+	}
+	return fmt.Sprintf("InputPolicy(%d)", uint8(p))
+}
+
+// ErrOperandRange is wrapped by the errors InputStrict reports for an
+// operand that is negative or not below N.
+var ErrOperandRange = errors.New("montgomery: operand outside [0, N)")
+
+// WithInputPolicy sets how the context treats operands outside [0, N); the
+// default is InputPermissive.
+func WithInputPolicy(p InputPolicy) Option {
+	return func(c *config) {
+		c.input = p
+	}
+}
+
+// operand applies p to the operand x of op: x itself if it lies in [0, N),
+// else a reduced copy under InputPermissive or an error under InputStrict.
+// Callers must not modify the result, which may be x.
+func (p InputPolicy) operand(n *big.Int, op string, x *big.Int) (*big.Int, error) {
+	if x.Sign() >= 0 && x.Cmp(n) < 0 {
+		return x, nil
+	}
+	if p == InputStrict {
+		if x.Sign() < 0 {
+			return nil, fmt.Errorf("%w: %s operand is negative", ErrOperandRange, op)
+		}
+		return nil, fmt.Errorf("%w: %s operand has %d bits, N has %d", ErrOperandRange, op, x.BitLen(), n.BitLen())
+	}
+	return new(big.Int).Mod(x, n), nil
+}
+
+// mustOperand is operand for methods without an error result, which panic
+// under InputStrict.
+func (p InputPolicy) mustOperand(n *big.Int, op string, x *big.Int) *big.Int {
+	x, err := p.operand(n, op, x)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+// MulChecked is Mul returning an error instead of panicking for an operand
+// that InputStrict rejects. Under InputPermissive it never fails.
+func (m *MontgomeryBitwise) MulChecked(x, y *big.Int) (*big.Int, error) {
+	return mulChecked(m.cfg.input, m.N, m.Mul, x, y)
+}
+
+// MulChecked is Mul returning an error instead of panicking for an operand
+// that InputStrict rejects. Under InputPermissive it never fails.
+func (m *MontgomeryCIOS) MulChecked(x, y *big.Int) (*big.Int, error) {
+	return mulChecked(m.cfg.input, m.N, m.Mul, x, y)
+}
+
+// MulChecked is Mul returning an error instead of panicking for an operand
+// that InputStrict rejects. Under InputPermissive it never fails.
+func (m *MontgomeryCIOSWords) MulChecked(x, y *big.Int) (*big.Int, error) {
+	return mulChecked(m.cfg.input, m.N, m.Mul, x, y)
+}
+
+func mulChecked(p InputPolicy, n *big.Int, mul func(x, y *big.Int) *big.Int, x, y *big.Int) (*big.Int, error) {
+	for _, v := range []*big.Int{x, y} {
+		if _, err := p.operand(n, "Mul", v); err != nil {
+			return nil, err
+		}
+	}
+	return mul(x, y), nil
+}
+
+// ExpChecked is Exp returning an error instead of panicking for a base that
+// InputStrict rejects. The exponent is never out of range: a negative one
+// raises the inverse, and a base without one yields nil and no error, as
+// with Exp.
+func (m *MontgomeryBitwise) ExpChecked(base, exp *big.Int) (*big.Int, error) {
+	return expChecked(m.engine(), base, exp)
+}
+
+// ExpChecked is Exp returning an error instead of panicking for a base that
+// InputStrict rejects. See MontgomeryBitwise.ExpChecked.
+func (m *MontgomeryCIOS) ExpChecked(base, exp *big.Int) (*big.Int, error) {
+	return expChecked(m.engine(), base, exp)
+}
+
+// ExpChecked is Exp returning an error instead of panicking for a base that
+// InputStrict rejects. See MontgomeryBitwise.ExpChecked.
+func (m *MontgomeryCIOSWords) ExpChecked(base, exp *big.Int) (*big.Int, error) {
+	return expChecked(m.engine(), base, exp)
+}
+
+func expChecked(eng engine, base, exp *big.Int) (*big.Int, error) {
+	if _, err := eng.input.operand(eng.n, "Exp", base); err != nil {
+		return nil, err
+	}
+	return expFull(eng, base, exp), nil
+}
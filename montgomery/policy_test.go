@@ -0,0 +1,160 @@
+package montgomery
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+// panics reports whether f panics with an error wrapping ErrOperandRange.
+func panics(f func()) (ok bool) {
+	defer func() {
+		err, _ := recover().(error)
+		ok = errors.Is(err, ErrOperandRange)
+	}()
+	f()
+	return false
+}
+
+func TestInputPolicy_String(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		p    InputPolicy
+		want string
+	}{
+		{InputPermissive, "permissive"},
+		{InputStrict, "strict"},
+		{InputPolicy(7), "InputPolicy(7)"},
+	}
+	for _, tc := range tests {
+		if got := tc.p.String(); got != tc.want {
+			t.Errorf("String() = %q, want %q", got, tc.want)
+		}
+	}
+}
+
+func TestInputPolicy(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	e := big.NewInt(65537)
+
+	tests := []struct {
+		name    string
+		x       *big.Int
+		inRange bool
+	}{
+		{"in range", x, true},
+		{"zero", big.NewInt(0), true},
+		{"N - 1", new(big.Int).Sub(N, big.NewInt(1)), true},
+		{"N", new(big.Int).Set(N), false},
+		{"between N and R", new(big.Int).Add(N, x), false},
+		{"wider than R", new(big.Int).Lsh(x, 2048), false},
+		{"negative", new(big.Int).Neg(x), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			wantMul := new(big.Int).Mul(tc.x, y)
+			wantMul.Mod(wantMul, N)
+			wantExp := new(big.Int).Exp(tc.x, e, N)
+
+			for _, impl := range montContexts(R, N, WithInputPolicy(InputPermissive)) {
+				if got := impl.m.Mul(tc.x, y); got.Cmp(wantMul) != 0 {
+					t.Errorf("%s permissive: Mul = %v, want %v", impl.name, got, wantMul)
+				}
+				if got, err := impl.m.MulChecked(y, tc.x); err != nil || got.Cmp(wantMul) != 0 {
+					t.Errorf("%s permissive: MulChecked = %v, %v, want %v", impl.name, got, err, wantMul)
+				}
+				if got, err := impl.m.ExpChecked(tc.x, e); err != nil || got.Cmp(wantExp) != 0 {
+					t.Errorf("%s permissive: ExpChecked = %v, %v, want %v", impl.name, got, err, wantExp)
+				}
+			}
+
+			for _, impl := range montContexts(R, N, WithInputPolicy(InputStrict)) {
+				_, errMul := impl.m.MulChecked(y, tc.x)
+				_, errExp := impl.m.ExpChecked(tc.x, e)
+				if tc.inRange {
+					if errMul != nil || errExp != nil {
+						t.Errorf("%s strict: in-range operand rejected: %v, %v", impl.name, errMul, errExp)
+					}
+					if got := impl.m.Mul(tc.x, y); got.Cmp(wantMul) != 0 {
+						t.Errorf("%s strict: Mul = %v, want %v", impl.name, got, wantMul)
+					}
+					continue
+				}
+				if !errors.Is(errMul, ErrOperandRange) || !errors.Is(errExp, ErrOperandRange) {
+					t.Errorf("%s strict: errors %v, %v, want ErrOperandRange", impl.name, errMul, errExp)
+				}
+				if !panics(func() { impl.m.Mul(tc.x, y) }) {
+					t.Errorf("%s strict: Mul did not panic with ErrOperandRange", impl.name)
+				}
+				if !panics(func() { impl.m.Exp(tc.x, e) }) {
+					t.Errorf("%s strict: Exp did not panic with ErrOperandRange", impl.name)
+				}
+			}
+		})
+	}
+}
+
+func TestInputPolicy_strictEntryPoints(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N, WithInputPolicy(InputStrict))
+	bad := new(big.Int).Neg(x)
+
+	tests := []struct {
+		name string
+		f    func()
+	}{
+		{"MulInto", func() { m.MulInto(new(big.Int), x, bad) }},
+		{"ToMont", func() { m.ToMont(bad) }},
+		{"ExpBatch", func() { m.ExpBatch([]*big.Int{x, bad}, y) }},
+		{"ExpConstantTime", func() { m.ExpConstantTime(N, y) }},
+		{"NewFixedBase", func() { m.NewFixedBase(bad, 64, 4) }},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if !panics(tc.f) {
+				t.Errorf("%s did not panic with ErrOperandRange", tc.name)
+			}
+		})
+	}
+
+	// A negative exponent is not an out-of-range operand
+	got, err := m.ExpChecked(x, big.NewInt(-1))
+	if want := new(big.Int).ModInverse(x, N); err != nil || got.Cmp(want) != 0 {
+		t.Errorf("ExpChecked(x, -1) = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestInputPolicy_permissiveProperty(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+
+	// Arbitrary signed operands of up to twice the width of R
+	err := quick.Check(func(xBytes, yBytes []byte, xNeg, yNeg bool) bool {
+		x := new(big.Int).SetBytes(xBytes)
+		y := new(big.Int).SetBytes(yBytes)
+		if xNeg {
+			x.Neg(x)
+		}
+		if yNeg {
+			y.Neg(y)
+		}
+		want := new(big.Int).Mul(x, y)
+		want.Mod(want, N)
+		return m.Mul(x, y).Cmp(want) == 0 && m.MulInto(new(big.Int), x, y).Cmp(want) == 0
+	}, &quick.Config{MaxCount: 200})
+	if err != nil {
+		t.Error(err)
+	}
+}
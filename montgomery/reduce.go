@@ -0,0 +1,156 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+)
+
+// Reduce returns t·R⁻¹ mod N: Montgomery reduction (REDC) of a product t
+// computed elsewhere, for callers with their own multiplier (Karatsuba,
+// a hardware unit) that only need the reduction step. Mul is
+// Reduce(x·y) on Montgomery-form operands.
+//
+// t must be in [0, N·R), which holds for any product x·y with x < N and
+// y < R. Values outside that range are handled by the context's
+// InputPolicy, with N·R as the bound: InputPermissive reduces t mod N·R,
+// which leaves t·R⁻¹ mod N unchanged.
+func (m *MontgomeryBitwise) Reduce(t *big.Int) *big.Int {
+	return m.reduce(new(big.Int).Set(m.cfg.input.reduceOperand(m.N, m.R, t)))
+}
+
+// reduce runs the bit-by-bit REDC on t, which it overwrites.
+func (m *MontgomeryBitwise) reduce(t *big.Int) *big.Int {
+	// Loop k times for Montgomery reduction where R = 2^k
+	for r := 1; r < m.R.BitLen(); r++ {
+		auditBranch("MontgomeryBitwise.reduce: low bit", t)
+		if t.Bit(0) == 1 {
+			t.Add(t, m.N)
+		}
+		t.Rsh(t, 1)
+	}
+	auditBranch("MontgomeryBitwise.reduce: final subtraction", t)
+	if t.Cmp(m.N) >= 0 {
+		t.Sub(t, m.N)
+	}
+	return t
+}
+
+// Reduce returns t·R⁻¹ mod N for t in [0, N·R). See
+// MontgomeryBitwise.Reduce.
+//
+// CIOS interleaves the reduction with the product, so this runs the
+// reduction half of its loop on its own: S passes that each add a multiple
+// of N clearing the lowest word and shift it out.
+func (m *MontgomeryCIOS) Reduce(t *big.Int) *big.Int {
+	T := new(big.Int).Set(m.cfg.input.reduceOperand(m.N, m.R, t))
+	for i := 0; i < m.S; i++ {
+		mm := limb(T.Bits(), 0) * m.NI
+		T.Add(T, new(big.Int).Mul(new(big.Int).SetUint64(mm), m.N))
+		T.Rsh(T, 64)
+	}
+	if T.Cmp(m.N) >= 0 {
+		T.Sub(T, m.N)
+	}
+	return T
+}
+
+// Reduce returns t·R⁻¹ mod N for t in [0, N·R). See
+// MontgomeryBitwise.Reduce.
+//
+// Moduli on the separated path reduce with two whole-number
+// multiplications (see redcSeparated); all others run montReduceWords.
+func (m *MontgomeryCIOSWords) Reduce(t *big.Int) *big.Int {
+	t = m.cfg.input.reduceOperand(m.N, m.R, t)
+	if m.np != nil {
+		return m.reduceSeparated(new(big.Int).Set(t))
+	}
+	z := make([]uint64, 3*m.S)
+	montReduceWords(z[:m.S], limbsPaddedInto(z[m.S:], t), m.nw, m.NI)
+	return tobigInt(z[:m.S])
+}
+
+// ReduceWords sets z = t·R⁻¹ mod N, where z has S limbs and t, the
+// double-width product, 2·S limbs in [0, N·R). A t outside that range is
+// handled by the context's InputPolicy, as for Reduce; the check compares
+// the high half of t with N. Moduli of up to wordsStackLimbs limbs run
+// without allocating a t in range. z may be the low half of t.
+func (m *MontgomeryCIOSWords) ReduceWords(z, t []uint64) {
+	m.checkWords("ReduceWords", z)
+	if len(t) != 2*m.S {
+		panic(fmt.Sprintf("montgomery: ReduceWords: product has %d limbs, want %d", len(t), 2*m.S))
+	}
+	var buf [2 * wordsStackLimbs]uint64
+	u := buf[:]
+	if m.S > wordsStackLimbs {
+		u = make([]uint64, 2*m.S)
+	}
+	if limbsLess(t[m.S:], m.nw) {
+		copy(u, t)
+	} else {
+		limbsPaddedInto(u, m.cfg.input.reduceOperand(m.N, m.R, tobigInt(t)))
+	}
+	montReduceWords(z, u, m.nw, m.NI)
+}
+
+// montReduceWords computes z = t·R⁻¹ mod N for t of exactly 2s words in
+// [0, N·R), where s = len(n), overwriting t. It is the reduction half of
+// separated operand scanning: each of the s passes adds the multiple of N
+// that clears the lowest live word, whose carry out is kept in top for the
+// next pass instead of being propagated to the end. The result is below
+// 2N and finishes with montMulWords' masked subtraction, so the sequence
+// of operations depends only on s. z must not overlap t or n.
+func montReduceWords(z, t, n []uint64, ni uint64) {
+	s := len(n)
+	z, t = z[:s], t[:2*s]
+	checkDisjoint("montReduceWords", z, t, n)
+	checkDisjoint("montReduceWords", t, n)
+
+	var top uint64
+	for i := range s {
+		m := t[i] * ni
+		var c uint64
+		for j := range s {
+			hi, lo := bits.Mul64(m, n[j])
+			lo, cc := bits.Add64(lo, t[i+j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[i+j] = lo
+			c = hi
+		}
+		t[i+s], top = bits.Add64(t[i+s], c, top)
+	}
+
+	var borrow uint64
+	for j := range s {
+		z[j], borrow = bits.Sub64(t[s+j], n[j], borrow)
+	}
+	// The sum is ≥ N iff the top carry is set or the subtraction did not borrow
+	keep := ctMask(top | (borrow ^ 1))
+	for j := range s {
+		z[j] = z[j]&keep | t[s+j]&^keep
+	}
+}
+
+// reduceOperand applies p to the operand t of Reduce, whose range is
+// [0, N·R) rather than [0, N). Callers must not modify the result, which may
+// be t.
+func (p InputPolicy) reduceOperand(n, r, t *big.Int) *big.Int {
+	k := uint(r.BitLen() - 1)
+	// t < 2^(bitlen(N)-1+k) ≤ N·R without forming N·R
+	if t.Sign() >= 0 && t.BitLen() < n.BitLen()+int(k) {
+		return t
+	}
+	nr := new(big.Int).Lsh(n, k)
+	if t.Sign() >= 0 && t.Cmp(nr) < 0 {
+		return t
+	}
+	if p == InputStrict {
+		if t.Sign() < 0 {
+			panic(fmt.Errorf("%w: Reduce operand is negative", ErrOperandRange))
+		}
+		panic(fmt.Errorf("%w: Reduce operand has %d bits, N·R has %d", ErrOperandRange, t.BitLen(), nr.BitLen()))
+	}
+	return nr.Mod(t, nr)
+}
@@ -0,0 +1,198 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+// reduceImpls returns every Reduce implementation for R and N: those of
+// montContexts, plus CIOSWords on its separated path and ReduceWords.
+func reduceImpls(R, N *big.Int, opts ...Option) map[string]func(t *big.Int) *big.Int {
+	impls := make(map[string]func(t *big.Int) *big.Int)
+	for _, c := range montContexts(R, N, opts...) {
+		impls[c.name] = c.m.Reduce
+	}
+	impls["CIOSWords/separated"] = NewMontgomeryCIOSWords(R, N, append(opts, WithReduction(ReductionMontgomerySeparated))...).Reduce
+	words := NewMontgomeryCIOSWords(R, N, opts...)
+	impls["ReduceWords"] = func(t *big.Int) *big.Int {
+		z := make([]uint64, words.S)
+		words.ReduceWords(z, limbsPadded(t, 2*words.S))
+		return words.FromWords(z)
+	}
+	return impls
+}
+
+func TestReduce(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	rInv := new(big.Int).ModInverse(R, N)
+	nr := new(big.Int).Mul(N, R)
+
+	tests := []struct {
+		name string
+		t    *big.Int
+	}{
+		{"product", new(big.Int).Mul(x, y)},
+		{"zero", big.NewInt(0)},
+		{"one", big.NewInt(1)},
+		{"R", new(big.Int).Set(R)},
+		{"N·R - 1", new(big.Int).Sub(nr, big.NewInt(1))},
+		{"(N - 1)²", new(big.Int).Mul(new(big.Int).Sub(N, big.NewInt(1)), new(big.Int).Sub(N, big.NewInt(1)))},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			want := new(big.Int).Mul(tc.t, rInv)
+			want.Mod(want, N)
+			tCopy := new(big.Int).Set(tc.t)
+			for name, reduce := range reduceImpls(R, N) {
+				if got := reduce(tc.t); got.Cmp(want) != 0 {
+					t.Errorf("%s: Reduce() = %v, want %v", name, got, want)
+				}
+				if tc.t.Cmp(tCopy) != 0 {
+					t.Fatalf("%s: Reduce modified its operand", name)
+				}
+			}
+		})
+	}
+}
+
+func TestReduce_mulMont(t *testing.T) {
+	t.Parallel()
+
+	// Reduce(a·b) is MulMont(a, b) for values in Montgomery form
+	for _, bitSize := range []int{64, 256, 1024} {
+		_, _, R, N := testParamsLarge(bitSize)
+		m := NewMontgomeryCIOSWords(R, N)
+		impls := reduceImpls(R, N)
+		err := quick.Check(func(xBytes, yBytes []byte) bool {
+			a := m.ToMont(new(big.Int).SetBytes(xBytes))
+			b := m.ToMont(new(big.Int).SetBytes(yBytes))
+			want := m.FromMont(m.MulMont(a, b))
+			for _, reduce := range impls {
+				if m.FromMont(MontElement{reduce(new(big.Int).Mul(a.val(), b.val()))}).Cmp(want) != 0 {
+					return false
+				}
+			}
+			return true
+		}, &quick.Config{MaxCount: 20})
+		if err != nil {
+			t.Errorf("%d bits: %v", bitSize, err)
+		}
+	}
+}
+
+func TestReduce_inputPolicy(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParamsLarge(256)
+	rInv := new(big.Int).ModInverse(R, N)
+	nr := new(big.Int).Mul(N, R)
+
+	// ReduceWords only sees operands that fit in 2·S limbs
+	tests := []struct {
+		name  string
+		t     *big.Int
+		limbs bool
+	}{
+		{"N·R", nr, true},
+		{"R²-1", new(big.Int).Sub(new(big.Int).Mul(R, R), big.NewInt(1)), true},
+		{"R²", new(big.Int).Mul(R, R), false},
+		{"negative", big.NewInt(-7), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			want := new(big.Int).Mul(tc.t, rInv)
+			want.Mod(want, N)
+			for name, reduce := range reduceImpls(R, N) {
+				if name == "ReduceWords" && !tc.limbs {
+					continue
+				}
+				if got := reduce(tc.t); got.Cmp(want) != 0 {
+					t.Errorf("%s: permissive Reduce() = %v, want %v", name, got, want)
+				}
+			}
+			for name, reduce := range reduceImpls(R, N, WithInputPolicy(InputStrict)) {
+				if name == "ReduceWords" && !tc.limbs {
+					continue
+				}
+				if !panics(func() { reduce(tc.t) }) {
+					t.Errorf("%s: strict Reduce() did not panic with ErrOperandRange", name)
+				}
+			}
+		})
+	}
+}
+
+func TestMontgomeryCIOSWords_ReduceWords(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParamsLarge(256)
+	m := NewMontgomeryCIOSWords(R, N)
+	tw := limbsPadded(new(big.Int).Mul(x, y), 2*m.S)
+	want := make([]uint64, m.S)
+	m.ReduceWords(want, tw)
+
+	// z may be the low half of t, which is left intact otherwise
+	tCopy := append([]uint64(nil), tw...)
+	m.ReduceWords(tCopy[:m.S], tCopy)
+	if m.FromWords(tCopy[:m.S]).Cmp(m.FromWords(want)) != 0 {
+		t.Error("ReduceWords(t[:S], t) differs from ReduceWords(z, t)")
+	}
+
+	tests := []struct {
+		name string
+		z, t []uint64
+	}{
+		{"short z", make([]uint64, m.S-1), tw},
+		{"short t", make([]uint64, m.S), tw[:2*m.S-1]},
+		{"single-width t", make([]uint64, m.S), tw[:m.S]},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			defer func() {
+				if recover() == nil {
+					t.Error("ReduceWords did not panic")
+				}
+			}()
+			m.ReduceWords(tc.z, tc.t)
+		})
+	}
+}
+
+// BenchmarkReduce compares the REDC step alone against MulMont, which also
+// forms the product.
+func BenchmarkReduce(b *testing.B) {
+	for _, bits := range []int{256, 2048} {
+		x, y, R, N := testParamsLarge(bits)
+		m := NewMontgomeryCIOSWords(R, N)
+		t := new(big.Int).Mul(x, y)
+		tw := limbsPadded(t, 2*m.S)
+		z := make([]uint64, m.S)
+		a, c := m.ToMont(x), m.ToMont(y)
+
+		b.Run(fmt.Sprintf("bits=%d/op=Reduce", bits), func(b *testing.B) {
+			for b.Loop() {
+				m.Reduce(t)
+			}
+		})
+		b.Run(fmt.Sprintf("bits=%d/op=ReduceWords", bits), func(b *testing.B) {
+			for b.Loop() {
+				m.ReduceWords(z, tw)
+			}
+		})
+		b.Run(fmt.Sprintf("bits=%d/op=MulMont", bits), func(b *testing.B) {
+			for b.Loop() {
+				m.MulMont(a, c)
+			}
+		})
+	}
+}
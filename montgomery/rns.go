@@ -0,0 +1,396 @@
+package montgomery
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"math/bits"
+)
+
+// RNSBase is a residue number system over two bases of pairwise coprime odd
+// moduli below 2^64, A = {a₀, …, aₖ₋₁} and B = {b₀, …, bₗ₋₁}, with every
+// constant needed to move values between them. An integer x is represented
+// by its residues x mod aᵢ, in which products and sums are channel-wise:
+// A and B return the SmallModuli that compute them, each with its
+// per-channel -n⁻¹ mod 2^64 and 2^128 mod n.
+//
+// The second base is for base extension, the step that RNS Montgomery
+// multiplication takes twice per product: AB converts residues in A to
+// residues in B and BA converts them back. Both hold the CRT and
+// mixed-radix tables for their direction, O(k·l) constants that NewRNSBase
+// derives with k-word divisions; MarshalBinary saves them.
+//
+// An RNSBase is immutable and safe for concurrent use.
+type RNSBase struct {
+	a, b   *SmallModuli
+	ab, ba *BaseExtension
+}
+
+// BaseExtension converts residues in one base of an RNSBase, the source
+// with moduli m₀, …, mₖ₋₁ and product M, into the other, the target with
+// moduli t₀, …, tₗ₋₁. For M̂ᵢ = M/mᵢ it holds
+//
+//	hatInv[i]     M̂ᵢ⁻¹ mod mᵢ
+//	hat[j·k+i]    M̂ᵢ mod tⱼ
+//	mrInv[…]      mᵢ⁻¹ mod mⱼ for i < j, at j(j-1)/2 + i
+//	radix[j·k+i]  m₀·…·mᵢ₋₁ mod tⱼ
+//
+// each constant c in Montgomery form c·2^64 mod its modulus, so that one
+// REDC of a residue with c multiplies it by c. The first two serve the CRT
+// extension of ExtendApprox, the last two mixed-radix conversion.
+type BaseExtension struct {
+	from, to                  *SmallModuli
+	hatInv, hat, mrInv, radix []uint64
+}
+
+// NewRNSBase precomputes the bases a and b and the extensions between them.
+// It returns an error wrapping ErrInvalidParameters if either base is empty
+// or has a modulus that is even or at most 1, and one wrapping
+// ErrNotInvertible if two moduli, in the same base or not, share a factor.
+func NewRNSBase(a, b []uint64) (*RNSBase, error) {
+	if len(a) == 0 || len(b) == 0 {
+		return nil, fmt.Errorf("%w: RNS bases need a modulus each, got %d and %d", ErrInvalidParameters, len(a), len(b))
+	}
+	sa, err := NewSmallModuli(a)
+	if err != nil {
+		return nil, fmt.Errorf("%w of base A", err)
+	}
+	sb, err := NewSmallModuli(b)
+	if err != nil {
+		return nil, fmt.Errorf("%w of base B", err)
+	}
+	all := append(sa.Moduli(), b...)
+	name := func(i int) string {
+		if i < len(a) {
+			return fmt.Sprintf("a[%d]", i)
+		}
+		return fmt.Sprintf("b[%d]", i-len(a))
+	}
+	for j := range all {
+		for i := range j {
+			if gcd64(all[i], all[j]) != 1 {
+				return nil, fmt.Errorf("%w: %s and %s share a factor", ErrNotInvertible, name(i), name(j))
+			}
+		}
+	}
+	return &RNSBase{a: sa, b: sb, ab: newBaseExtension(sa, sb), ba: newBaseExtension(sb, sa)}, nil
+}
+
+// newBaseExtension computes the tables from the moduli of from to those of
+// to, which must be pairwise coprime.
+func newBaseExtension(from, to *SmallModuli) *BaseExtension {
+	k, l := from.Len(), to.Len()
+	e := &BaseExtension{
+		from:   from,
+		to:     to,
+		hatInv: make([]uint64, k),
+		hat:    make([]uint64, l*k),
+		mrInv:  make([]uint64, k*(k-1)/2),
+		radix:  make([]uint64, l*k),
+	}
+	M := big.NewInt(1) // m₀·…·mᵢ₋₁ in the loop, then the product
+	for i, m := range from.n {
+		for j, t := range to.n {
+			e.radix[j*k+i] = toMont1(mod64(M, t), t)
+		}
+		M.Mul(M, new(big.Int).SetUint64(m))
+		for j := range i {
+			e.mrInv[i*(i-1)/2+j] = toMont1(inv64(from.n[j]%m, m), m)
+		}
+	}
+	hat := new(big.Int)
+	for i, m := range from.n {
+		hat.Quo(M, new(big.Int).SetUint64(m))
+		e.hatInv[i] = toMont1(inv64(mod64(hat, m), m), m)
+		for j, t := range to.n {
+			e.hat[j*k+i] = toMont1(mod64(hat, t), t)
+		}
+	}
+	return e
+}
+
+// A returns base A, for arithmetic on residues in it.
+func (r *RNSBase) A() *SmallModuli { return r.a }
+
+// B returns base B.
+func (r *RNSBase) B() *SmallModuli { return r.b }
+
+// AB returns the extension from base A to base B.
+func (r *RNSBase) AB() *BaseExtension { return r.ab }
+
+// BA returns the extension from base B to base A.
+func (r *RNSBase) BA() *BaseExtension { return r.ba }
+
+// Residues returns x mod aᵢ and x mod bⱼ for every modulus of the bases,
+// each in [0, modulus) whatever the sign of x.
+func (r *RNSBase) Residues(x *big.Int) (xa, xb []uint64) {
+	return residues(x, r.a.n), residues(x, r.b.n)
+}
+
+func residues(x *big.Int, moduli []uint64) []uint64 {
+	z := make([]uint64, len(moduli))
+	for i, n := range moduli {
+		z[i] = mod64(x, n)
+		if x.Sign() < 0 && z[i] != 0 {
+			z[i] = n - z[i]
+		}
+	}
+	return z
+}
+
+// Extend sets z to the residues in the target base of the x below the
+// source product M with residues x in the source base. It is exact, by
+// mixed-radix conversion: x = v₀ + v₁·m₀ + v₂·m₀m₁ + …, with digits vᵢ
+// below mᵢ, each needing i steps in channel i, and then the digits are
+// summed in every target channel, O(k² + k·l) REDCs in all.
+//
+// The residues must be below their moduli and are not checked. z and x
+// must not be the same slice.
+func (e *BaseExtension) Extend(z, x []uint64) {
+	e.checkLen("Extend", x, z)
+	v := e.digits(x)
+	k := len(v)
+	for j, t := range e.to.n {
+		ni, row := e.to.ni[j], e.radix[j*k:(j+1)*k]
+		var acc uint64
+		for i, d := range v {
+			acc = addMod1(acc, redc1(d, row[i], t, ni), t)
+		}
+		z[j] = acc
+	}
+}
+
+// ExtendApprox sets z to the residues in the target base of x + α·M for
+// some α in [0, k), with the CRT: x = Σ ξᵢ·M̂ᵢ - α·M for ξᵢ = xᵢ·M̂ᵢ⁻¹ mod mᵢ.
+// It costs k + k·l REDCs with no serial dependence between channels, about
+// half of Extend, and suits RNS Montgomery multiplication, whose first
+// extension tolerates the multiple of M.
+//
+// The residues must be below their moduli and are not checked. z and x
+// must not be the same slice.
+func (e *BaseExtension) ExtendApprox(z, x []uint64) {
+	e.checkLen("ExtendApprox", x, z)
+	k := len(x)
+	xi := make([]uint64, k)
+	redcLanes(xi, x, e.hatInv, e.from.n, e.from.ni)
+	for j, t := range e.to.n {
+		ni, row := e.to.ni[j], e.hat[j*k:(j+1)*k]
+		var acc uint64
+		for i, d := range xi {
+			acc = addMod1(acc, redc1(d, row[i], t, ni), t)
+		}
+		z[j] = acc
+	}
+}
+
+// Combine returns the x below the source product M with residues x in the
+// source base, by mixed-radix conversion.
+func (e *BaseExtension) Combine(x []uint64) *big.Int {
+	e.checkLen("Combine", x, nil)
+	v := e.digits(x)
+	z := new(big.Int)
+	m := new(big.Int)
+	for i := len(v) - 1; i >= 0; i-- {
+		z.Mul(z, m.SetUint64(e.from.n[i]))
+		z.Add(z, m.SetUint64(v[i]))
+	}
+	return z
+}
+
+// digits returns the mixed-radix digits of x: v₀ = x₀, and vⱼ is
+// (…((xⱼ - v₀)·m₀⁻¹ - v₁)·m₁⁻¹ - … - vⱼ₋₁)·mⱼ₋₁⁻¹ mod mⱼ. A REDC with a
+// Montgomery-form inverse takes a digit of any size, so vᵢ ≥ mⱼ needs no
+// reduction first.
+func (e *BaseExtension) digits(x []uint64) []uint64 {
+	v := make([]uint64, len(x))
+	for j, m := range e.from.n {
+		ni := e.from.ni[j]
+		t := x[j]
+		for i, inv := range e.mrInv[j*(j-1)/2 : j*(j+1)/2] {
+			t = subMod1(redc1(t, inv, m, ni), redc1(v[i], inv, m, ni), m)
+		}
+		v[j] = t
+	}
+	return v
+}
+
+// checkLen panics unless x has one element per source modulus and z, unless
+// nil, one per target modulus.
+func (e *BaseExtension) checkLen(op string, x, z []uint64) {
+	if len(x) != e.from.Len() {
+		panic(fmt.Sprintf("montgomery: BaseExtension.%s: operand has %d elements, want %d", op, len(x), e.from.Len()))
+	}
+	if z != nil && len(z) != e.to.Len() {
+		panic(fmt.Sprintf("montgomery: BaseExtension.%s: result has %d elements, want %d", op, len(z), e.to.Len()))
+	}
+}
+
+// rnsMagic and rnsVersion identify the serialized RNS base format:
+//
+//	magic    [4]byte "AVRN"
+//	version  uint8
+//	k        uint32   moduli in A
+//	l        uint32   moduli in B
+//	A        [k]uint64
+//	B        [l]uint64
+//
+// followed by the tables of AB (hatInv, hat, mrInv and radix; see
+// BaseExtension) and then those of BA, all integers little-endian.
+const (
+	rnsMagic   = "AVRN"
+	rnsVersion = 1
+	rnsHeader  = 4 + 1 + 4 + 4
+)
+
+// MarshalBinary encodes the bases and the extension tables in a versioned
+// binary format, so that a process can load them with UnmarshalBinary
+// instead of recomputing them.
+func (r *RNSBase) MarshalBinary() ([]byte, error) {
+	sections := [][]uint64{r.a.n, r.b.n}
+	for _, e := range []*BaseExtension{r.ab, r.ba} {
+		sections = append(sections, e.hatInv, e.hat, e.mrInv, e.radix)
+	}
+	n := 0
+	for _, s := range sections {
+		n += len(s)
+	}
+	buf := make([]byte, 0, rnsHeader+8*n)
+	buf = append(buf, rnsMagic...)
+	buf = append(buf, rnsVersion)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(r.a.Len()))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(r.b.Len()))
+	for _, s := range sections {
+		for _, word := range s {
+			buf = binary.LittleEndian.AppendUint64(buf, word)
+		}
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes an RNS base produced by MarshalBinary, replacing
+// r. The per-channel constants are recomputed, a few divisions each; the
+// tables are not.
+//
+// The data is checked for consistency (sizes, odd moduli, entries below
+// their modulus, the mixed-radix inverses and the radix products), but the
+// CRT tables are taken as they are; load RNS bases only from sources as
+// trusted as the code itself.
+func (r *RNSBase) UnmarshalBinary(data []byte) error {
+	if len(data) < rnsHeader || string(data[:4]) != rnsMagic {
+		return ErrContextFormat
+	}
+	if data[4] != rnsVersion {
+		return fmt.Errorf("%w: %d", ErrContextVersion, data[4])
+	}
+	k := uint64(binary.LittleEndian.Uint32(data[5:]))
+	l := uint64(binary.LittleEndian.Uint32(data[9:]))
+	body := data[rnsHeader:]
+	// Bounded by the body first so that forged sizes cannot overflow
+	n := uint64(len(body) / 8)
+	if len(body)%8 != 0 || k == 0 || l == 0 || k > n || l > n/k {
+		return ErrContextFormat
+	}
+	if 2*(k+l)+4*k*l+k*(k-1)/2+l*(l-1)/2 != n {
+		return ErrContextFormat
+	}
+	words := make([]uint64, n)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(body[8*i:])
+	}
+	next := func(m uint64) []uint64 {
+		s := words[:m:m]
+		words = words[m:]
+		return s
+	}
+	a, b := next(k), next(l)
+	for _, m := range append(a[:k:k], b...) {
+		if m <= 1 || m&1 == 0 {
+			return ErrContextFormat
+		}
+	}
+	sa, sb := newSmallModuli(a), newSmallModuli(b)
+	ab := &BaseExtension{from: sa, to: sb, hatInv: next(k), hat: next(k * l), mrInv: next(k * (k - 1) / 2), radix: next(k * l)}
+	ba := &BaseExtension{from: sb, to: sa, hatInv: next(l), hat: next(k * l), mrInv: next(l * (l - 1) / 2), radix: next(k * l)}
+	if !ab.consistent() || !ba.consistent() {
+		return ErrContextFormat
+	}
+	*r = RNSBase{a: sa, b: sb, ab: ab, ba: ba}
+	return nil
+}
+
+// consistent reports whether every entry of e's tables is below its
+// modulus, every mixed-radix inverse is one, and the radix products follow
+// from the source moduli, all with O(k·l) REDCs.
+func (e *BaseExtension) consistent() bool {
+	k := e.from.Len()
+	for i, m := range e.from.n {
+		if e.hatInv[i] >= m {
+			return false
+		}
+		ni := e.from.ni[i]
+		for j, inv := range e.mrInv[i*(i-1)/2 : i*(i+1)/2] {
+			if inv >= m || redc1(e.from.n[j], inv, m, ni) != 1 {
+				return false
+			}
+		}
+	}
+	for j, t := range e.to.n {
+		ni := e.to.ni[j]
+		hat, radix := e.hat[j*k:(j+1)*k], e.radix[j*k:(j+1)*k]
+		// radix[0] is 1 in Montgomery form, and each next one is the last
+		// times mᵢ
+		if radix[0] != bits.Rem64(1, 0, t) {
+			return false
+		}
+		for i := range k {
+			if hat[i] >= t || radix[i] >= t {
+				return false
+			}
+			// m mod t in Montgomery form is REDC(m, 2^128 mod t)
+			if i+1 < k && radix[i+1] != redc1(redc1(e.from.n[i], e.to.rr[j], t, ni), radix[i], t, ni) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// toMont1 returns x·2^64 mod n, for x < n.
+func toMont1(x, n uint64) uint64 { return bits.Rem64(x, 0, n) }
+
+// addMod1 returns x + y mod n for x, y < n, without branches.
+func addMod1(x, y, n uint64) uint64 {
+	s, c := bits.Add64(x, y, 0)
+	d, borrow := bits.Sub64(s, n, 0)
+	keep := ctMask(c | (borrow ^ 1))
+	return d&keep | s&^keep
+}
+
+// subMod1 returns x - y mod n for x, y < n, without branches.
+func subMod1(x, y, n uint64) uint64 {
+	d, borrow := bits.Sub64(x, y, 0)
+	return d + n&ctMask(borrow)
+}
+
+// mod64 returns |x| mod n, one Rem64 per limb.
+func mod64(x *big.Int, n uint64) uint64 {
+	words := x.Bits()
+	var r uint64
+	for i := (len(words)+limbWords-1)/limbWords - 1; i >= 0; i-- {
+		r = bits.Rem64(r, limb(words, i), n)
+	}
+	return r
+}
+
+// inv64 returns x⁻¹ mod n for x coprime to n.
+func inv64(x, n uint64) uint64 {
+	return new(big.Int).ModInverse(new(big.Int).SetUint64(x), new(big.Int).SetUint64(n)).Uint64()
+}
+
+// gcd64 returns the greatest common divisor of x and y.
+func gcd64(x, y uint64) uint64 {
+	for y != 0 {
+		x, y = y, x%y
+	}
+	return x
+}
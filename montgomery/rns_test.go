@@ -0,0 +1,329 @@
+package montgomery
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"testing/quick"
+)
+
+// testRNSPrimes returns k distinct primes of the given size, the same ones
+// for the same seed.
+func testRNSPrimes(k, bitSize int, seed uint64) []uint64 {
+	rng := rand.New(rand.NewPCG(seed, uint64(bitSize)))
+	primes := make([]uint64, 0, k)
+	for len(primes) < k {
+		p := rng.Uint64()>>(64-bitSize) | 1<<(bitSize-1) | 1
+		if new(big.Int).SetUint64(p).ProbablyPrime(20) && !slices.Contains(primes, p) {
+			primes = append(primes, p)
+		}
+	}
+	return primes
+}
+
+// testRNSBase returns a base of k 64-bit primes and one of l 63-bit primes,
+// which cannot share a modulus.
+func testRNSBase(t testing.TB, k, l int) *RNSBase {
+	t.Helper()
+	r, err := NewRNSBase(testRNSPrimes(k, 64, 1), testRNSPrimes(l, 63, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// product returns the product of moduli.
+func product(moduli []uint64) *big.Int {
+	p := big.NewInt(1)
+	for _, m := range moduli {
+		p.Mul(p, new(big.Int).SetUint64(m))
+	}
+	return p
+}
+
+// checkExtension checks both extensions of e on x below the source product
+// M, whose residues in the target base are want.
+func checkExtension(t *testing.T, e *BaseExtension, x *big.Int, xs, want []uint64) {
+	t.Helper()
+	M := product(e.from.n)
+	if got := e.Combine(xs); got.Cmp(x) != 0 {
+		t.Errorf("Combine = %v, want %v", got, x)
+	}
+	z := make([]uint64, len(want))
+	e.Extend(z, xs)
+	if !slices.Equal(z, want) {
+		t.Errorf("Extend(%v) = %v, want %v", x, z, want)
+	}
+	// ExtendApprox is off by α·M for one α below k, the same in every
+	// channel
+	e.ExtendApprox(z, xs)
+	y := new(big.Int).Set(x)
+	for range len(xs) {
+		if slices.Equal(z, residues(y, e.to.n)) {
+			return
+		}
+		y.Add(y, M)
+	}
+	t.Errorf("ExtendApprox(%v) = %v, not x + α·M for any α < %d", x, z, len(xs))
+}
+
+func TestRNSBase(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		a, b []uint64
+	}{
+		{"one each", []uint64{1<<64 - 59}, []uint64{1<<61 - 1}},
+		{"small", []uint64{3, 5, 7}, []uint64{11, 13}},
+		{"top bit set", testRNSPrimes(5, 64, 3), testRNSPrimes(4, 64, 4)},
+		{"unequal", testRNSPrimes(3, 40, 5), testRNSPrimes(17, 62, 6)},
+		{"lanes", testRNSPrimes(20, 64, 7), testRNSPrimes(20, 63, 8)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			r, err := NewRNSBase(tc.a, tc.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			A, B := product(tc.a), product(tc.b)
+			rng := rand.New(rand.NewPCG(uint64(len(tc.a)), uint64(len(tc.b))))
+			for _, M := range []*big.Int{A, B} {
+				// 0, 1, M-1 and random values below M
+				xs := []*big.Int{big.NewInt(0), big.NewInt(1), new(big.Int).Sub(M, big.NewInt(1))}
+				for range 10 {
+					x := new(big.Int)
+					for x.Cmp(M) < 0 {
+						x.Lsh(x, 64).Or(x, new(big.Int).SetUint64(rng.Uint64()))
+					}
+					xs = append(xs, x.Mod(x, M))
+				}
+				for _, x := range xs {
+					xa, xb := r.Residues(x)
+					if M == A {
+						checkExtension(t, r.AB(), x, xa, xb)
+					} else {
+						checkExtension(t, r.BA(), x, xb, xa)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRNSBase_property(t *testing.T) {
+	t.Parallel()
+
+	r := testRNSBase(t, 9, 10)
+	A := product(r.a.n)
+	err := quick.Check(func(xBytes []byte) bool {
+		x := new(big.Int).Mod(new(big.Int).SetBytes(xBytes), A)
+		xa, xb := r.Residues(x)
+		z := make([]uint64, len(xb))
+		r.AB().Extend(z, xa)
+		return slices.Equal(z, xb) && r.AB().Combine(xa).Cmp(x) == 0
+	}, &quick.Config{MaxCount: 200})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRNSBase_Residues(t *testing.T) {
+	t.Parallel()
+
+	r, err := NewRNSBase([]uint64{3, 1<<64 - 59}, []uint64{5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := new(big.Int).Lsh(big.NewInt(12345), 200)
+	for _, x := range []*big.Int{x, new(big.Int).Neg(x), big.NewInt(-3)} {
+		xa, xb := r.Residues(x)
+		got := slices.Concat(xa, xb)
+		for i, m := range slices.Concat(r.a.n, r.b.n) {
+			if want := new(big.Int).Mod(x, new(big.Int).SetUint64(m)); got[i] != want.Uint64() {
+				t.Errorf("Residues(%v) mod %d = %d, want %v", x, m, got[i], want)
+			}
+		}
+	}
+}
+
+func TestNewRNSBase_errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		a, b    []uint64
+		wantErr error
+	}{
+		{"empty A", nil, []uint64{3}, ErrInvalidParameters},
+		{"empty B", []uint64{3}, nil, ErrInvalidParameters},
+		{"even in A", []uint64{3, 4}, []uint64{5}, ErrEvenModulus},
+		{"one in B", []uint64{3}, []uint64{5, 1}, ErrModulusTooSmall},
+		{"shared within A", []uint64{3, 5, 9}, []uint64{7}, ErrNotInvertible},
+		{"shared across", []uint64{3, 5}, []uint64{7, 25}, ErrNotInvertible},
+		{"repeated", []uint64{1<<61 - 1}, []uint64{1<<61 - 1}, ErrNotInvertible},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := NewRNSBase(tc.a, tc.b); !errors.Is(err, tc.wantErr) {
+				t.Errorf("NewRNSBase(%v, %v) error = %v, want %v", tc.a, tc.b, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRNSBase_marshal(t *testing.T) {
+	t.Parallel()
+
+	r := testRNSBase(t, 6, 5)
+	data, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded RNSBase
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	for _, pair := range [][2]*BaseExtension{{r.ab, loaded.ab}, {r.ba, loaded.ba}} {
+		want, got := pair[0], pair[1]
+		if !slices.Equal(got.from.n, want.from.n) || !slices.Equal(got.from.ni, want.from.ni) ||
+			!slices.Equal(got.from.rr, want.from.rr) || !slices.Equal(got.hatInv, want.hatInv) ||
+			!slices.Equal(got.hat, want.hat) || !slices.Equal(got.mrInv, want.mrInv) ||
+			!slices.Equal(got.radix, want.radix) {
+			t.Errorf("loaded extension differs from the original")
+		}
+	}
+	again, err := loaded.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(again, data) {
+		t.Error("MarshalBinary after UnmarshalBinary changed the encoding")
+	}
+
+	// Offsets of single words in the body: A, B, then the tables of AB
+	const k, l = 6, 5
+	word := func(i int) int { return rnsHeader + 8*i }
+	tamper := func(off int, v byte) []byte {
+		d := slices.Clone(data)
+		d[off] ^= v
+		return d
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"empty", nil, ErrContextFormat},
+		{"bad magic", tamper(0, 1), ErrContextFormat},
+		{"version", tamper(4, 2), ErrContextVersion},
+		{"truncated", data[:len(data)-8], ErrContextFormat},
+		{"trailing", append(slices.Clone(data), make([]byte, 8)...), ErrContextFormat},
+		{"no moduli", slices.Concat(data[:5], make([]byte, 8)), ErrContextFormat},
+		{"huge k", tamper(8, 0x80), ErrContextFormat},
+		{"even modulus", tamper(word(0), 1), ErrContextFormat},
+		{"hatInv too large", slices.Concat(data[:word(k+l)], []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, data[word(k+l+1):]), ErrContextFormat},
+		{"mixed-radix inverse", tamper(word(k+l+k+l*k), 1), ErrContextFormat},
+		{"radix product", tamper(word(k+l+k+l*k+k*(k-1)/2+1), 1), ErrContextFormat},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var r RNSBase
+			if err := r.UnmarshalBinary(tc.data); !errors.Is(err, tc.wantErr) {
+				t.Errorf("UnmarshalBinary error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestBaseExtension_length(t *testing.T) {
+	t.Parallel()
+
+	r := testRNSBase(t, 3, 2)
+	x, z := make([]uint64, 3), make([]uint64, 2)
+	for name, f := range map[string]func(){
+		"Extend":           func() { r.AB().Extend(z, x[:2]) },
+		"ExtendApprox":     func() { r.AB().ExtendApprox(x, x) },
+		"Combine":          func() { r.BA().Combine(x) },
+		"Extend to A":      func() { r.BA().Extend(z, z) },
+		"ExtendApprox A→B": func() { r.AB().ExtendApprox(z[:1], x) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s with a wrong length did not panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+// BenchmarkRNSBase measures the two extensions and their math/big
+// equivalent, recombining the residues and reducing them again, for equal
+// bases of k 64-bit moduli; NewRNSBase is the cost UnmarshalBinary saves.
+func BenchmarkRNSBase(b *testing.B) {
+	for _, k := range []int{8, 32} {
+		r := testRNSBase(b, k, k)
+		x := new(big.Int).Sub(product(r.a.n), big.NewInt(12345))
+		xa, _ := r.Residues(x)
+		z := make([]uint64, k)
+		b.Run(fmt.Sprintf("k=%d/op=extend", k), func(b *testing.B) {
+			for b.Loop() {
+				r.AB().Extend(z, xa)
+			}
+		})
+		b.Run(fmt.Sprintf("k=%d/op=extendapprox", k), func(b *testing.B) {
+			for b.Loop() {
+				r.AB().ExtendApprox(z, xa)
+			}
+		})
+		b.Run(fmt.Sprintf("k=%d/op=bigint", k), func(b *testing.B) {
+			g, err := newGarner(bigModuli(r.a.n))
+			if err != nil {
+				b.Fatal(err)
+			}
+			rs := make([]*big.Int, k)
+			for i, v := range xa {
+				rs[i] = new(big.Int).SetUint64(v)
+			}
+			for b.Loop() {
+				residues(g.combine(rs), r.b.n)
+			}
+		})
+		a, bb := r.A().Moduli(), r.B().Moduli()
+		data, _ := r.MarshalBinary()
+		b.Run(fmt.Sprintf("k=%d/op=new", k), func(b *testing.B) {
+			for b.Loop() {
+				if _, err := NewRNSBase(a, bb); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("k=%d/op=unmarshal", k), func(b *testing.B) {
+			var loaded RNSBase
+			for b.Loop() {
+				if err := loaded.UnmarshalBinary(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func bigModuli(moduli []uint64) []*big.Int {
+	z := make([]*big.Int, len(moduli))
+	for i, m := range moduli {
+		z[i] = new(big.Int).SetUint64(m)
+	}
+	return z
+}
@@ -0,0 +1,264 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// RNSMontgomery multiplies mod an odd N in the residue number system of an
+// RNSBase, with the product A of base A as the Montgomery constant: an
+// element is a value congruent to x·A mod N, held as its residues in both
+// bases, k in A and then l in B, as one slice of k+l words.
+//
+// MulMont works channel by channel and has no carries between words
+// (Bajard, Didier and Kornerup; Kawamura et al.):
+//
+//	q = -x·y·N⁻¹ mod A              in base A
+//	q̂ = q + α·A, α < k              extended to B by ExtendApprox
+//	r = (x·y + q̂·N)·A⁻¹             in base B, exact since A divides the sum
+//	r                               extended back to A by Extend
+//
+// Every channel is an independent one-word REDC, which is what makes RNS
+// the route to SIMD, GPU and multi-core modular arithmetic. Here only the
+// laneWidth channels of a SmallModuli loop run side by side, and the
+// extensions cost O(k² + k·l) REDCs against the O(k) of the products, so on
+// one core RNSMontgomery is much slower than MontgomeryCIOSWords.
+//
+// r is not reduced below N. It stays below (k+1)·N, which NewRNSMontgomery
+// makes sure is small enough to feed MulMont again, so chains need no
+// comparison until FromMont.
+//
+// An RNSMontgomery is immutable and safe for concurrent use.
+type RNSMontgomery struct {
+	N    *big.Int // modulus (must be odd)
+	base *RNSBase
+	k, l int
+	nInv []uint64 // -N⁻¹·2^128 mod aᵢ, so that two REDCs multiply by -N⁻¹
+	nb   []uint64 // N mod bⱼ
+	aInv []uint64 // A⁻¹·2^128 mod bⱼ
+	aN   *big.Int // A mod N
+	one  []uint64 // 1 in both bases
+	cfg  config
+}
+
+// NewRNSMontgomery precomputes the constants for N in base. It returns an
+// error wrapping ErrInvalidParameters if N is even or at most 1, or if the
+// bases are too small for N: MulMont needs A ≥ (k+1)²·N and B ≥ (k+1)·N.
+// It returns one wrapping ErrNotInvertible if N shares a factor with a
+// modulus of base A. RNSBaseFor picks a base that fits.
+func NewRNSMontgomery(base *RNSBase, N *big.Int, opts ...Option) (*RNSMontgomery, error) {
+	switch {
+	case N == nil || N.Cmp(big.NewInt(1)) <= 0:
+		return nil, ErrModulusTooSmall
+	case N.Bit(0) == 0:
+		return nil, ErrEvenModulus
+	}
+	k, l := base.a.Len(), base.b.Len()
+	A, B := rnsProduct(base.a.n), rnsProduct(base.b.n)
+	bound := new(big.Int).Mul(big.NewInt(int64(k+1)), N)
+	if B.Cmp(bound) < 0 || A.Cmp(bound.Mul(bound, big.NewInt(int64(k+1)))) < 0 {
+		return nil, fmt.Errorf("%w: RNS bases of %d and %d bits are too small for a %d-bit N", ErrInvalidParameters, A.BitLen(), B.BitLen(), N.BitLen())
+	}
+	m := &RNSMontgomery{
+		N:    new(big.Int).Set(N),
+		base: base,
+		k:    k,
+		l:    l,
+		nInv: make([]uint64, k),
+		nb:   residues(N, base.b.n),
+		aInv: make([]uint64, l),
+		aN:   new(big.Int).Mod(A, N),
+		one:  make([]uint64, k+l),
+		cfg:  newConfig(opts),
+	}
+	for i, a := range base.a.n {
+		na := mod64(N, a)
+		if gcd64(na, a) != 1 {
+			return nil, fmt.Errorf("%w: N shares a factor with a[%d]", ErrNotInvertible, i)
+		}
+		m.nInv[i] = toMont1(toMont1(a-inv64(na, a), a), a)
+	}
+	for j, b := range base.b.n {
+		m.aInv[j] = toMont1(toMont1(inv64(mod64(A, b), b), b), b)
+	}
+	for i := range m.one {
+		m.one[i] = 1
+	}
+	return m, nil
+}
+
+// Len returns the number of words of an element, k+l.
+func (m *RNSMontgomery) Len() int { return m.k + m.l }
+
+// Base returns the RNS base.
+func (m *RNSMontgomery) Base() *RNSBase { return m.base }
+
+// Modulus returns N.
+func (m *RNSMontgomery) Modulus() *big.Int { return new(big.Int).Set(m.N) }
+
+// ToMont sets z to x·A mod N in both bases. Operands outside [0, N) are
+// handled by the context's InputPolicy.
+func (m *RNSMontgomery) ToMont(z []uint64, x *big.Int) {
+	m.checkLen("ToMont", z)
+	x = m.cfg.input.mustOperand(m.N, "ToMont", x)
+	m.setResidues(z, new(big.Int).Mod(new(big.Int).Mul(x, m.aN), m.N))
+}
+
+// FromMont returns x·A⁻¹ mod N, in [0, N), for an element x.
+func (m *RNSMontgomery) FromMont(x []uint64) *big.Int {
+	m.checkLen("FromMont", x)
+	z := make([]uint64, m.k+m.l)
+	m.MulMont(z, x, m.one)
+	return m.reduce(z)
+}
+
+// MulMont sets z to x·y·A⁻¹ mod N, below (k+1)·N, for elements x and y. z
+// may be x, y or both.
+func (m *RNSMontgomery) MulMont(z, x, y []uint64) {
+	m.checkLen("MulMont", z, x, y)
+	a, b, k := m.base.a, m.base.b, m.k
+	buf := make([]uint64, k+2*m.l)
+	q, qb, s := buf[:k], buf[k:k+m.l], buf[k+m.l:]
+
+	// q = -x·y·N⁻¹ mod A
+	redcLanes(q, x[:k], y[:k], a.n, a.ni)
+	redcLanes(q, q, m.nInv, a.n, a.ni)
+	m.base.ab.ExtendApprox(qb, q)
+
+	// (x·y + q̂·N)·A⁻¹ mod B, each REDC's 2⁻⁶⁴ undone by the 2^128 of aInv
+	redcLanes(s, x[k:], y[k:], b.n, b.ni)
+	redcLanes(qb, qb, m.nb, b.n, b.ni)
+	for j, bj := range b.n {
+		s[j] = addMod1(s[j], qb[j], bj)
+	}
+	redcLanes(z[k:], s, m.aInv, b.n, b.ni)
+	m.base.ba.Extend(z[:k], z[k:])
+}
+
+// Mul returns (x * y) mod N with a single MulMont, since
+// (x·A)·y·A⁻¹ = x·y. Operands outside [0, N) are handled by the context's
+// InputPolicy.
+func (m *RNSMontgomery) Mul(x, y *big.Int) *big.Int {
+	y = m.cfg.input.mustOperand(m.N, "Mul", y)
+	xm, ys := make([]uint64, m.k+m.l), make([]uint64, m.k+m.l)
+	m.ToMont(xm, x)
+	m.setResidues(ys, y)
+	m.MulMont(xm, xm, ys)
+	return m.reduce(xm)
+}
+
+// Exp returns base^exp mod N by square-and-multiply on elements, with the
+// semantics of big.Int.Exp: any base is reduced into [0, N), and a negative
+// exponent raises the inverse, or returns nil if there is none.
+func (m *RNSMontgomery) Exp(base, exp *big.Int) *big.Int {
+	g := new(big.Int).Mod(base, m.N)
+	if exp.Sign() < 0 {
+		if g.ModInverse(g, m.N) == nil {
+			return nil
+		}
+		exp = new(big.Int).Neg(exp)
+	}
+	gm, acc := make([]uint64, m.k+m.l), make([]uint64, m.k+m.l)
+	m.setResidues(gm, g.Mod(g.Mul(g, m.aN), m.N))
+	m.setResidues(acc, m.aN)
+	for i := exp.BitLen() - 1; i >= 0; i-- {
+		m.MulMont(acc, acc, acc)
+		if exp.Bit(i) == 1 {
+			m.MulMont(acc, acc, gm)
+		}
+	}
+	return m.FromMont(acc)
+}
+
+// setResidues sets z to the residues of x ≥ 0 in both bases.
+func (m *RNSMontgomery) setResidues(z []uint64, x *big.Int) {
+	for i, n := range m.base.a.n {
+		z[i] = mod64(x, n)
+	}
+	for j, n := range m.base.b.n {
+		z[m.k+j] = mod64(x, n)
+	}
+}
+
+// reduce returns the value of an element below (k+1)·N, and so below B,
+// mod N.
+func (m *RNSMontgomery) reduce(x []uint64) *big.Int {
+	z := m.base.ba.Combine(x[m.k:])
+	return z.Mod(z, m.N)
+}
+
+// checkLen panics unless every slice has k+l elements.
+func (m *RNSMontgomery) checkLen(op string, xs ...[]uint64) {
+	for _, x := range xs {
+		if len(x) != m.k+m.l {
+			panic(fmt.Sprintf("montgomery: RNSMontgomery.%s: operand has %d elements, want %d", op, len(x), m.k+m.l))
+		}
+	}
+}
+
+// RNSBaseFor returns an RNS base of 64-bit primes large enough for
+// NewRNSMontgomery with N: the largest primes below 2^64 that do not divide
+// N, for base A until A ≥ (k+1)²·N and then for base B until
+// B ≥ (k+1)·N.
+func RNSBaseFor(N *big.Int) (*RNSBase, error) {
+	if N == nil || N.Sign() <= 0 {
+		return nil, ErrModulusTooSmall
+	}
+	var a, b []uint64
+	A, B := big.NewInt(1), big.NewInt(1)
+	bound := new(big.Int)
+	for i := 0; ; i++ {
+		p := rnsPrime(i)
+		if mod64(N, p) == 0 {
+			continue
+		}
+		k := big.NewInt(int64(len(a) + 1))
+		if bound.Mul(bound.Mul(k, k), N); A.Cmp(bound) < 0 {
+			a = append(a, p)
+			A.Mul(A, new(big.Int).SetUint64(p))
+			continue
+		}
+		if bound.Mul(k, N); B.Cmp(bound) < 0 {
+			b = append(b, p)
+			B.Mul(B, new(big.Int).SetUint64(p))
+			continue
+		}
+		return NewRNSBase(a, b)
+	}
+}
+
+// rnsPrimes caches the primes below 2^64 in descending order, which
+// RNSBaseFor draws from.
+var rnsPrimes struct {
+	sync.Mutex
+	p []uint64
+}
+
+// rnsPrime returns the i-th largest prime below 2^64. ProbablyPrime(0) is
+// exact below 2^64.
+func rnsPrime(i int) uint64 {
+	rnsPrimes.Lock()
+	defer rnsPrimes.Unlock()
+	c := uint64(1<<64 - 1)
+	if n := len(rnsPrimes.p); n > 0 {
+		c = rnsPrimes.p[n-1] - 2
+	}
+	for x := new(big.Int); len(rnsPrimes.p) <= i; c -= 2 {
+		if x.SetUint64(c).ProbablyPrime(0) {
+			rnsPrimes.p = append(rnsPrimes.p, c)
+		}
+	}
+	return rnsPrimes.p[i]
+}
+
+// rnsProduct returns the product of moduli.
+func rnsProduct(moduli []uint64) *big.Int {
+	p := big.NewInt(1)
+	for _, n := range moduli {
+		p.Mul(p, new(big.Int).SetUint64(n))
+	}
+	return p
+}
+
+var _ ModMultiplier = (*RNSMontgomery)(nil)
@@ -0,0 +1,194 @@
+package montgomery
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestRNSMontgomery(t *testing.T) {
+	t.Parallel()
+
+	_, _, _, n256 := testParamsLarge(256)
+	_, _, _, n2048 := testParamsLarge(2048)
+	p0, p1 := new(big.Int).SetUint64(rnsPrime(0)), new(big.Int).SetUint64(rnsPrime(1))
+	tests := []struct {
+		name string
+		N    *big.Int
+	}{
+		{"three", big.NewInt(3)},
+		// a modulus RNSBaseFor would otherwise pick
+		{"largest prime", p0},
+		{"two base primes", new(big.Int).Mul(p0, p1)},
+		{"256", n256},
+		{"2048", n2048},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			base, err := RNSBaseFor(tc.N)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, err := NewRNSMontgomery(base, tc.N)
+			if err != nil {
+				t.Fatal(err)
+			}
+			N := tc.N
+			nMinus1 := new(big.Int).Sub(N, big.NewInt(1))
+			check := func(x, y *big.Int) bool {
+				want := new(big.Int).Mod(new(big.Int).Mul(x, y), N)
+				if got := m.Mul(x, y); got.Cmp(want) != 0 {
+					t.Errorf("Mul(%v, %v) = %v, want %v", x, y, got, want)
+					return false
+				}
+				// A chain of products that are never reduced below
+				// (k+1)·N
+				xm, ym := make([]uint64, m.Len()), make([]uint64, m.Len())
+				m.ToMont(xm, x)
+				m.ToMont(ym, y)
+				want.SetInt64(1)
+				acc := make([]uint64, m.Len())
+				m.ToMont(acc, want)
+				for i := range 8 {
+					m.MulMont(acc, acc, xm)
+					m.MulMont(acc, acc, acc)
+					m.MulMont(acc, ym, acc)
+					want.Mul(want, x).Mul(want, want).Mul(want, y).Mod(want, N)
+					if got := m.FromMont(acc); got.Cmp(want) != 0 {
+						t.Errorf("chain on %v, %v: step %d = %v, want %v", x, y, i, got, want)
+						return false
+					}
+				}
+				return true
+			}
+			check(nMinus1, nMinus1)
+			check(big.NewInt(0), nMinus1)
+			err = quick.Check(func(xBytes, yBytes []byte) bool {
+				x := new(big.Int).Mod(new(big.Int).SetBytes(xBytes), N)
+				y := new(big.Int).Mod(new(big.Int).SetBytes(yBytes), N)
+				return check(x, y)
+			}, &quick.Config{MaxCount: 20})
+			if err != nil {
+				t.Error(err)
+			}
+
+			e := new(big.Int).Sub(nMinus1, big.NewInt(1))
+			for _, x := range []*big.Int{big.NewInt(2), nMinus1, new(big.Int).Add(N, big.NewInt(5))} {
+				if got, want := m.Exp(x, e), new(big.Int).Exp(x, e, N); got.Cmp(want) != 0 {
+					t.Errorf("Exp(%v, %v) = %v, want %v", x, e, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRNSMontgomery_negativeExp(t *testing.T) {
+	t.Parallel()
+
+	N := big.NewInt(15)
+	base, err := RNSBaseFor(N)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewRNSMontgomery(base, N)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Exp(big.NewInt(2), big.NewInt(-1)); got.Cmp(big.NewInt(8)) != 0 {
+		t.Errorf("Exp(2, -1) = %v, want 8", got)
+	}
+	if got := m.Exp(big.NewInt(3), big.NewInt(-1)); got != nil {
+		t.Errorf("Exp(3, -1) = %v, want nil", got)
+	}
+}
+
+func TestNewRNSMontgomery_errors(t *testing.T) {
+	t.Parallel()
+
+	base := testRNSBase(t, 2, 2)
+	tests := []struct {
+		name string
+		N    *big.Int
+		want error
+	}{
+		{"even", big.NewInt(1 << 20), ErrEvenModulus},
+		{"one", big.NewInt(1), ErrModulusTooSmall},
+		// A is about 2^128, and needs to be (k+1)² = 9 times N
+		{"too large", new(big.Int).Lsh(big.NewInt(1), 125), ErrInvalidParameters},
+		{"shares a[1]", new(big.Int).SetUint64(base.a.n[1]), ErrNotInvertible},
+	}
+	for _, tc := range tests {
+		if tc.N.Bit(0) == 0 && tc.want != ErrEvenModulus {
+			tc.N.Add(tc.N, big.NewInt(1))
+		}
+		if _, err := NewRNSMontgomery(base, tc.N); !errors.Is(err, tc.want) {
+			t.Errorf("%s: error = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+	if _, err := NewRNSMontgomery(base, new(big.Int).Lsh(big.NewInt(1), 100).Add(new(big.Int).Lsh(big.NewInt(1), 100), big.NewInt(1))); err != nil {
+		t.Errorf("100-bit N: %v", err)
+	}
+}
+
+func TestRNSBaseFor(t *testing.T) {
+	t.Parallel()
+
+	for _, bitSize := range []int{64, 1024, 4096} {
+		_, _, _, N := testParamsLarge(bitSize)
+		base, err := RNSBaseFor(N)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k := int64(base.A().Len())
+		A, B := product(base.a.n), product(base.b.n)
+		bound := new(big.Int).Mul(big.NewInt(k+1), N)
+		if B.Cmp(bound) < 0 || A.Cmp(bound.Mul(bound, big.NewInt(k+1))) < 0 {
+			t.Errorf("%d bits: bases of %d and %d bits too small", bitSize, A.BitLen(), B.BitLen())
+		}
+		// One modulus fewer in A would have failed the bound
+		A.Quo(A, new(big.Int).SetUint64(base.a.n[k-1]))
+		if bound.Mul(big.NewInt(k), N).Mul(bound, big.NewInt(k)); A.Cmp(bound) >= 0 {
+			t.Errorf("%d bits: base A of %d moduli is larger than needed", bitSize, k)
+		}
+	}
+	for i := range 4 {
+		if p := new(big.Int).SetUint64(rnsPrime(i)); !p.ProbablyPrime(20) || p.BitLen() != 64 {
+			t.Errorf("rnsPrime(%d) = %v", i, p)
+		}
+	}
+}
+
+// BenchmarkRNSMontgomery compares MulMont, the full RNS Montgomery
+// multiplication with its two base extensions, with a REDC of
+// MontgomeryCIOSWords on the same modulus.
+func BenchmarkRNSMontgomery(b *testing.B) {
+	for _, bits := range []int{256, 1024, 2048} {
+		x, y, R, N := testParamsLarge(bits)
+		base, err := RNSBaseFor(N)
+		if err != nil {
+			b.Fatal(err)
+		}
+		m, err := NewRNSMontgomery(base, N)
+		if err != nil {
+			b.Fatal(err)
+		}
+		xm, ym := make([]uint64, m.Len()), make([]uint64, m.Len())
+		m.ToMont(xm, x)
+		m.ToMont(ym, y)
+		b.Run(fmt.Sprintf("bits=%d/impl=rns", bits), func(b *testing.B) {
+			for b.Loop() {
+				m.MulMont(xm, xm, ym)
+			}
+		})
+		w := NewMontgomeryCIOSWords(R, N)
+		xw, yw := w.ToWords(x), w.ToWords(y)
+		b.Run(fmt.Sprintf("bits=%d/impl=cioswords", bits), func(b *testing.B) {
+			for b.Loop() {
+				w.MulMontWords(xw, xw, yw)
+			}
+		})
+	}
+}
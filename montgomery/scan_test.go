@@ -0,0 +1,172 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"testing"
+	"testing/quick"
+)
+
+func TestScanMethod_kernels(t *testing.T) {
+	t.Parallel()
+
+	for _, method := range scanMethods {
+		t.Run(method.String(), func(t *testing.T) {
+			t.Parallel()
+			for _, bitSize := range []int{64, 128, 192, 256, 448, 1024, 2048} {
+				_, _, R, random := testParamsLarge(bitSize)
+				// just above R/2 and R-1: the moduli whose results most
+				// often need the final subtraction
+				low := new(big.Int).Add(new(big.Int).Rsh(R, 1), big.NewInt(1))
+				high := new(big.Int).Sub(R, big.NewInt(1))
+				for _, N := range []*big.Int{random, low, high} {
+					ref := NewMontgomeryCIOSWords(R, N)
+					s := ref.S
+					n := limbsPadded(N, s)
+					nMinus1 := limbsPadded(new(big.Int).Sub(N, big.NewInt(1)), s)
+					kernel := method.kernel()
+					scratch := make([]uint64, 2*s+2)
+
+					check := func(x, y []uint64, alias bool) bool {
+						want := make([]uint64, s)
+						montMulSlice(want, x, y, n, ref.NI, make([]uint64, s+2))
+						z := make([]uint64, s)
+						if alias {
+							z = slices.Clone(x)
+							x = z
+						}
+						kernel(z, x, y, n, ref.NI, scratch)
+						return slices.Equal(z, want)
+					}
+
+					// (N-1)² gives the largest columns and carries
+					if !check(nMinus1, nMinus1, false) || !check(nMinus1, make([]uint64, s), true) {
+						t.Errorf("%d bits, N = %x: differs from montMulSlice on N-1", bitSize, N)
+					}
+					z := slices.Clone(nMinus1)
+					kernel(z, z, z, n, ref.NI, scratch)
+					want := make([]uint64, s)
+					montMulSlice(want, nMinus1, nMinus1, n, ref.NI, make([]uint64, s+2))
+					if !slices.Equal(z, want) {
+						t.Errorf("%d bits, N = %x: z = x = y differs", bitSize, N)
+					}
+					err := quick.Check(func(xBytes, yBytes []byte, alias bool) bool {
+						x := new(big.Int).Mod(new(big.Int).SetBytes(xBytes), N)
+						y := new(big.Int).Mod(new(big.Int).SetBytes(yBytes), N)
+						return check(limbsPadded(x, s), limbsPadded(y, s), alias)
+					}, &quick.Config{MaxCount: 50})
+					if err != nil {
+						t.Errorf("%d bits, N = %x: %v", bitSize, N, err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestScanMethod_String(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		method ScanMethod
+		want   string
+	}{
+		{ScanSOS, "sos"},
+		{ScanCIOS, "scan-cios"},
+		{ScanFIOS, "fios"},
+		{ScanFIPS, "fips"},
+		{ScanCIHS, "cihs"},
+		{ScanMethod(99), "ScanMethod(99)"},
+	}
+	for _, tc := range tests {
+		if got := tc.method.String(); got != tc.want {
+			t.Errorf("ScanMethod(%d).String() = %q, want %q", uint8(tc.method), got, tc.want)
+		}
+	}
+}
+
+func TestMontgomeryScan(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	e := new(big.Int).Sub(N, big.NewInt(12345))
+	product := new(big.Int).Mod(new(big.Int).Mul(x, y), N)
+
+	for _, method := range scanMethods {
+		t.Run(method.String(), func(t *testing.T) {
+			t.Parallel()
+			m := NewMontgomeryScan(R, N, method)
+			tests := []struct {
+				name string
+				got  *big.Int
+				want *big.Int
+			}{
+				{"Mul", m.Mul(x, y), product},
+				{"Mul unreduced", m.Mul(new(big.Int).Add(x, N), y), product},
+				{"Exp", m.Exp(x, e), new(big.Int).Exp(x, e, N)},
+				{"Exp negative", m.Exp(x, big.NewInt(-3)), new(big.Int).Exp(new(big.Int).ModInverse(x, N), big.NewInt(3), N)},
+				{"Modulus", m.Modulus(), N},
+			}
+			for _, tc := range tests {
+				if tc.got.Cmp(tc.want) != 0 {
+					t.Errorf("%s: got %v, want %v", tc.name, tc.got, tc.want)
+				}
+			}
+			opened, err := Open(R, N, WithBackend(method.String()))
+			if err != nil {
+				t.Fatalf("Open(%q): %v", method, err)
+			}
+			if got := opened.Mul(x, y); got.Cmp(product) != 0 {
+				t.Errorf("Open(%q).Mul = %v, want %v", method, got, product)
+			}
+		})
+	}
+}
+
+func TestNewMontgomeryScanChecked(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	if _, err := NewMontgomeryScanChecked(R, N, ScanFIPS); err != nil {
+		t.Errorf("NewMontgomeryScanChecked() error = %v", err)
+	}
+	if _, err := NewMontgomeryScanChecked(new(big.Int).Lsh(R, 1), N, ScanFIPS); err == nil {
+		t.Error("NewMontgomeryScanChecked() accepted R that is not word aligned")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("NewMontgomeryScan accepted an unknown method")
+		}
+	}()
+	NewMontgomeryScan(R, N, ScanMethod(99))
+}
+
+// BenchmarkScan runs the five scanning methods of Koç, Acar and Kaliski
+// on the same inputs, with mulAccGo, the fused CIOS loop in Go, and the
+// same final subtraction for reference. The products are chained,
+// z = z·y, as in exponentiation.
+func BenchmarkScan(b *testing.B) {
+	for _, bits := range []int{256, 1024, 2048, 4096} {
+		x, y, R, N := testParamsLarge(bits)
+		m := NewMontgomeryCIOSWords(R, N)
+		s := m.S
+		z, ys := limbsPadded(x, s), limbsPadded(y, s)
+		t := make([]uint64, 2*s+2)
+
+		for _, method := range scanMethods {
+			kernel := method.kernel()
+			b.Run(fmt.Sprintf("bits=%d/impl=%v", bits, method), func(b *testing.B) {
+				for b.Loop() {
+					kernel(z, z, ys, m.nw, m.NI, t)
+				}
+			})
+		}
+		b.Run(fmt.Sprintf("bits=%d/impl=fused", bits), func(b *testing.B) {
+			for b.Loop() {
+				montgomeryImpl[uint64]{m.nw, m.NI}.mulAccGo(t, z, ys)
+				scanSubtract(z, t[:s], t[s], m.nw)
+			}
+		})
+	}
+}
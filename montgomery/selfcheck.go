@@ -0,0 +1,381 @@
+package montgomery
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"slices"
+	"sync/atomic"
+)
+
+var (
+	// ErrMismatch is matched by the *MismatchError a SelfCheck returns when
+	// two implementations disagree.
+	ErrMismatch = errors.New("montgomery: implementations disagree")
+	// ErrSelfTest is wrapped by the error a constructor reports when the
+	// self-test requested by WithSelfTest fails.
+	ErrSelfTest = errors.New("montgomery: self-test failed")
+)
+
+// MismatchError records the inputs and both results of a failed self-check.
+type MismatchError struct {
+	Op     string     // "Mul" or "Exp"
+	Inputs []*big.Int // x, y for Mul; base, exp for Exp
+	Got    *big.Int   // result of the checked implementation
+	Want   *big.Int   // result of the reference
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("montgomery: %s%v = %v, reference gives %v", e.Op, e.Inputs, e.Got, e.Want)
+}
+
+// Unwrap returns ErrMismatch.
+func (e *MismatchError) Unwrap() error { return ErrMismatch }
+
+// exponentiator is implemented by ModMultipliers with a native Exp, which
+// SelfCheck prefers over exponentiating through Mul.
+type exponentiator interface {
+	Exp(base, exp *big.Int) *big.Int
+}
+
+// SelfCheck is an opt-in paranoid mode: a sampled fraction of its Mul and
+// Exp calls are computed a second time by an independent reference and
+// compared, and a disagreement is returned as a *MismatchError instead of a
+// result. It is a runtime safety net for hand-written carry code and a bug
+// detector for production-like workloads.
+//
+// SelfCheck is safe for concurrent use if the wrapped implementations are.
+type SelfCheck struct {
+	m, ref  ModMultiplier
+	rate    float64
+	random  io.Reader
+	calls   atomic.Uint64
+	checked atomic.Uint64
+}
+
+// NewSelfCheck wraps m. With a nil reference, results are checked against
+// math/big; otherwise against reference, which should be a different
+// algorithm for the check to be meaningful (e.g. CIOS for CIOSWords).
+//
+// rate is the fraction of calls that are checked: 1 or more checks every
+// call, 0 or less none. Sampling is random, so a rate of 0.01 costs about 1%
+// extra work plus the reference's own overhead. The samples are drawn from
+// the reader of WithRandom, crypto/rand.Reader by default; other options
+// are ignored.
+func NewSelfCheck(m, reference ModMultiplier, rate float64, opts ...Option) *SelfCheck {
+	random := newConfig(opts).random
+	if random == nil {
+		random = crand.Reader
+	}
+	return &SelfCheck{m: m, ref: reference, rate: rate, random: random}
+}
+
+// WithRandom sets the source NewSelfCheck draws the calls it checks from,
+// and WithSelfTest its operands. Without it they are drawn from
+// crypto/rand.Reader; a deterministic source such as math/rand/v2's
+// ChaCha8 makes them reproducible in tests.
+// A SelfCheck used from several goroutines reads random concurrently, so
+// it must then be safe for concurrent use.
+func WithRandom(random io.Reader) Option {
+	return func(c *config) {
+		c.random = random
+	}
+}
+
+// Modulus returns N.
+func (c *SelfCheck) Modulus() *big.Int { return c.m.Modulus() }
+
+// Mul returns (x * y) mod N, or a *MismatchError if this call was sampled
+// and the reference disagrees. An error reading the random source is
+// returned as is.
+func (c *SelfCheck) Mul(x, y *big.Int) (*big.Int, error) {
+	got := c.m.Mul(x, y)
+	switch ok, err := c.sample(); {
+	case err != nil:
+		return nil, err
+	case !ok:
+		return got, nil
+	}
+	var want *big.Int
+	if c.ref != nil {
+		want = c.ref.Mul(x, y)
+	} else {
+		want = new(big.Int).Mul(x, y)
+		want.Mod(want, c.m.Modulus())
+	}
+	return c.compare("Mul", got, want, x, y)
+}
+
+// Exp returns base^exp mod N for exp ≥ 0, or a *MismatchError if this call
+// was sampled and the reference disagrees. Implementations without a native
+// Exp are exponentiated by square-and-multiply over Mul. An error reading
+// the random source is returned as is.
+func (c *SelfCheck) Exp(base, exp *big.Int) (*big.Int, error) {
+	got := expVia(c.m, base, exp)
+	switch ok, err := c.sample(); {
+	case err != nil:
+		return nil, err
+	case !ok:
+		return got, nil
+	}
+	var want *big.Int
+	if c.ref != nil {
+		want = expVia(c.ref, base, exp)
+	} else {
+		want = new(big.Int).Exp(base, exp, c.m.Modulus())
+	}
+	return c.compare("Exp", got, want, base, exp)
+}
+
+// Stats returns how many Mul and Exp calls were made and how many of them
+// were checked against the reference.
+func (c *SelfCheck) Stats() (calls, checked uint64) {
+	return c.calls.Load(), c.checked.Load()
+}
+
+// sample counts a call and reports whether it is checked, drawing a
+// uniform 64-bit value from the random source for a rate in (0, 1). A
+// failed read is not a sample and returns the error, with a nil result.
+func (c *SelfCheck) sample() (bool, error) {
+	c.calls.Add(1)
+	if c.rate <= 0 {
+		return false, nil
+	}
+	if c.rate < 1 {
+		var buf [8]byte
+		if _, err := io.ReadFull(c.random, buf[:]); err != nil {
+			return false, fmt.Errorf("montgomery: self-check sampling: %w", err)
+		}
+		if binary.LittleEndian.Uint64(buf[:]) >= uint64(c.rate*math.Exp2(64)) {
+			return false, nil
+		}
+	}
+	c.checked.Add(1)
+	return true, nil
+}
+
+func (c *SelfCheck) compare(op string, got, want *big.Int, inputs ...*big.Int) (*big.Int, error) {
+	if got.Cmp(want) != 0 {
+		in := make([]*big.Int, len(inputs))
+		for i, x := range inputs {
+			in[i] = new(big.Int).Set(x)
+		}
+		return nil, &MismatchError{Op: op, Inputs: in, Got: got, Want: want}
+	}
+	return got, nil
+}
+
+// expVia computes base^exp mod N with m's own Exp if it has one, or else
+// with left-to-right square-and-multiply over m.Mul.
+func expVia(m ModMultiplier, base, exp *big.Int) *big.Int {
+	if e, ok := m.(exponentiator); ok {
+		return e.Exp(base, exp)
+	}
+	N := m.Modulus()
+	b := new(big.Int).Mod(base, N)
+	acc := new(big.Int).Mod(big.NewInt(1), N)
+	for i := exp.BitLen() - 1; i >= 0; i-- {
+		acc = m.Mul(acc, acc)
+		if exp.Bit(i) == 1 {
+			acc = m.Mul(acc, b)
+		}
+	}
+	return acc
+}
+
+// WithSelfTest makes construction multiply rounds pairs of random operands
+// in [0, N) and compare each product with math/big. The plain constructors
+// do not validate R and N, and a wrong R or an even N only shows later as
+// silently wrong results; the self-test catches it, or a broken backend,
+// before the context is used. A handful of rounds suffices: wrong
+// parameters corrupt practically every product.
+//
+// The operands are read from the reader of WithRandom. A failure is an
+// error wrapping ErrSelfTest and a *MismatchError with the first failing
+// pair, or the error of the reader. Every constructor with an error result
+// returns it, Open, New, OpenNamed, Load, the Checked and the FromModulus
+// constructors among them; the plain constructors panic with it. Open
+// tests whatever its backend returns, registered out-of-tree backends
+// included. rounds ≤ 0 disables the test, the default.
+func WithSelfTest(rounds int) Option {
+	return func(c *config) {
+		c.selfTest = max(rounds, 0)
+	}
+}
+
+// selfTest runs the WithSelfTest check of cfg on m, with operands drawn
+// from the reader of WithRandom. An error reading it fails the test too.
+func selfTest(m ModMultiplier, cfg config) error {
+	random := cfg.random
+	if random == nil {
+		random = crand.Reader
+	}
+	N := m.Modulus()
+	for range cfg.selfTest {
+		x, err := crand.Int(random, N)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrSelfTest, err)
+		}
+		y, err := crand.Int(random, N)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrSelfTest, err)
+		}
+		want := new(big.Int).Mul(x, y)
+		want.Mod(want, N)
+		if got := m.Mul(x, y); got.Cmp(want) != 0 {
+			return fmt.Errorf("%w: %w", ErrSelfTest, &MismatchError{Op: "Mul", Inputs: []*big.Int{x, y}, Got: got, Want: want})
+		}
+	}
+	return nil
+}
+
+// mustSelfTest is selfTest for constructors without an error result.
+func mustSelfTest(m ModMultiplier, cfg config) {
+	if err := selfTest(m, cfg); err != nil {
+		panic(err)
+	}
+}
+
+// withoutSelfTest returns opts with the self-test turned off, and the
+// configuration they asked for, for constructors that build on a plain one
+// but run the test themselves to return its failure.
+func withoutSelfTest(opts []Option) ([]Option, config) {
+	return append(slices.Clip(opts), WithSelfTest(0)), newConfig(opts)
+}
+
+// Verify cross-checks m against math/big: the product of every pair of a
+// set of adversarial operands, then rounds products and squares of random
+// ones and, where m has an Exp of its own, exponentiations of both by short
+// exponents. The adversarial operands are the ones hand-written carry code
+// gets wrong: 0, 1, 2, N-1, N-2, ⌊N/2⌋, and values whose high words or low
+// words are zero, at several word boundaries. Random operands likewise have
+// a random number of words.
+//
+// It is meant for startup, for instance after enabling the assembly
+// kernels, to catch an implementation that is wrong on this machine before
+// it is trusted with data. Randomness is read from rng, or
+// crypto/rand.Reader when rng is nil. The result is the first disagreement
+// as a *MismatchError, an error from rng, or nil.
+func Verify(m ModMultiplier, rounds int, rng io.Reader) error {
+	if rng == nil {
+		rng = crand.Reader
+	}
+	N := m.Modulus()
+	edges := verifyOperands(N)
+	for _, x := range edges {
+		for _, y := range edges {
+			if err := verifyMul(m, N, x, y); err != nil {
+				return err
+			}
+		}
+	}
+	exps := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2), new(big.Int).SetUint64(1<<64 - 1)}
+	for _, x := range edges {
+		for _, e := range exps {
+			if err := verifyExp(m, N, x, e); err != nil {
+				return err
+			}
+		}
+	}
+
+	limit := new(big.Int).Lsh(big.NewInt(1), 64)
+	for range rounds {
+		x, err := verifyRandom(rng, N)
+		if err != nil {
+			return err
+		}
+		y, err := verifyRandom(rng, N)
+		if err != nil {
+			return err
+		}
+		e, err := crand.Int(rng, limit)
+		if err != nil {
+			return err
+		}
+		if err := verifyMul(m, N, x, y); err != nil {
+			return err
+		}
+		if err := verifyMul(m, N, x, x); err != nil {
+			return err
+		}
+		if err := verifyExp(m, N, x, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyOperands returns the adversarial operands of Verify, all in [0, N).
+// For a k-word boundary below N's length they are 2^(64k)-1 and 2^(64k),
+// and N-1 with the words above or below the boundary cleared.
+func verifyOperands(N *big.Int) []*big.Int {
+	one := big.NewInt(1)
+	nMinus1 := new(big.Int).Sub(N, one)
+	candidates := []*big.Int{
+		big.NewInt(0), one, big.NewInt(2),
+		nMinus1, new(big.Int).Sub(N, big.NewInt(2)), new(big.Int).Rsh(N, 1),
+	}
+	s := (N.BitLen() + 63) / 64
+	for _, k := range []int{1, 2, s / 2, s - 1} {
+		if k < 1 || k >= s {
+			continue
+		}
+		b := uint(64 * k)
+		pow := new(big.Int).Lsh(one, b)
+		candidates = append(candidates,
+			new(big.Int).Sub(pow, one),
+			pow,
+			new(big.Int).Mod(nMinus1, pow),
+			new(big.Int).Lsh(new(big.Int).Rsh(nMinus1, b), b),
+		)
+	}
+	var xs []*big.Int
+	for _, x := range candidates {
+		if x.Sign() >= 0 && x.Cmp(N) < 0 && !slices.ContainsFunc(xs, func(y *big.Int) bool { return x.Cmp(y) == 0 }) {
+			xs = append(xs, x)
+		}
+	}
+	return xs
+}
+
+// verifyRandom returns a random operand in [0, N) of a random number of
+// 64-bit words.
+func verifyRandom(rng io.Reader, N *big.Int) (*big.Int, error) {
+	s := (N.BitLen() + 63) / 64
+	k, err := crand.Int(rng, big.NewInt(int64(s)))
+	if err != nil {
+		return nil, err
+	}
+	bound := new(big.Int).Lsh(big.NewInt(1), uint(64*(k.Int64()+1)))
+	if bound.Cmp(N) > 0 {
+		bound = N
+	}
+	return crand.Int(rng, bound)
+}
+
+// verifyMul compares m.Mul(x, y) with math/big.
+func verifyMul(m ModMultiplier, N, x, y *big.Int) error {
+	want := new(big.Int).Mul(x, y)
+	want.Mod(want, N)
+	if got := m.Mul(x, y); got.Cmp(want) != 0 {
+		return &MismatchError{Op: "Mul", Inputs: []*big.Int{x, y}, Got: got, Want: want}
+	}
+	return nil
+}
+
+// verifyExp compares m's own Exp, if it has one, with math/big.
+func verifyExp(m ModMultiplier, N, base, exp *big.Int) error {
+	e, ok := m.(exponentiator)
+	if !ok {
+		return nil
+	}
+	want := new(big.Int).Exp(base, exp, N)
+	if got := e.Exp(base, exp); got.Cmp(want) != 0 {
+		return &MismatchError{Op: "Exp", Inputs: []*big.Int{base, exp}, Got: got, Want: want}
+	}
+	return nil
+}
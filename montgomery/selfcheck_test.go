@@ -0,0 +1,444 @@
+package montgomery
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	mrand "math/rand/v2"
+	"testing"
+	"testing/iotest"
+)
+
+// faultyMul wraps a ModMultiplier and corrupts results whose inputs are both
+// odd, mimicking a carry bug that only shows on some operands.
+type faultyMul struct {
+	ModMultiplier
+}
+
+func (f faultyMul) Mul(x, y *big.Int) *big.Int {
+	z := f.ModMultiplier.Mul(x, y)
+	if x.Bit(0) == 1 && y.Bit(0) == 1 {
+		z.Add(z, big.NewInt(1)).Mod(z, f.Modulus())
+	}
+	return z
+}
+
+// brokenMul wraps a ModMultiplier and corrupts every product, a bug that
+// any self-test round catches.
+type brokenMul struct {
+	ModMultiplier
+}
+
+func (b brokenMul) Mul(x, y *big.Int) *big.Int {
+	z := b.ModMultiplier.Mul(x, y)
+	return z.Add(z, big.NewInt(1)).Mod(z, b.Modulus())
+}
+
+func TestSelfCheck(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	words := NewMontgomeryCIOSWords(R, N)
+	cios := NewMontgomeryCIOS(R, N)
+	faulty := faultyMul{words}
+
+	tests := []struct {
+		name    string
+		m, ref  ModMultiplier
+		x, y    int64
+		wantErr error
+	}{
+		{"big.Int reference", words, nil, 3, 5, nil},
+		{"CIOS reference", words, cios, 3, 5, nil},
+		{"fault caught by big.Int", faulty, nil, 3, 5, ErrMismatch},
+		{"fault caught by CIOS", faulty, cios, 3, 5, ErrMismatch},
+		{"fault not triggered", faulty, cios, 2, 5, nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			c := NewSelfCheck(tc.m, tc.ref, 1)
+			x, y := big.NewInt(tc.x), big.NewInt(tc.y)
+			got, err := c.Mul(x, y)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Mul() error = %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && got.Int64() != tc.x*tc.y {
+				t.Errorf("Mul() = %v, want %d", got, tc.x*tc.y)
+			}
+			var mismatch *MismatchError
+			if errors.As(err, &mismatch) {
+				if mismatch.Op != "Mul" || mismatch.Want.Int64() != tc.x*tc.y || len(mismatch.Inputs) != 2 {
+					t.Errorf("MismatchError = %+v", mismatch)
+				}
+			}
+		})
+	}
+}
+
+func TestSelfCheck_Exp(t *testing.T) {
+	t.Parallel()
+
+	x, _, R, N := testParams2048()
+	words := NewMontgomeryCIOSWords(R, N)
+	e := big.NewInt(65537)
+	want := new(big.Int).Exp(x, e, N)
+
+	t.Run("native Exp", func(t *testing.T) {
+		t.Parallel()
+		got, err := NewSelfCheck(words, NewMontgomeryCIOS(R, N), 1).Exp(x, e)
+		if err != nil || got.Cmp(want) != 0 {
+			t.Errorf("Exp() = %v, %v; want %v", got, err, want)
+		}
+	})
+
+	t.Run("via Mul", func(t *testing.T) {
+		t.Parallel()
+		// faultyMul hides the native Exp, so it exponentiates through the
+		// corrupted Mul; 65537 squares odd values, so the fault fires.
+		_, err := NewSelfCheck(faultyMul{words}, nil, 1).Exp(big.NewInt(3), e)
+		if !errors.Is(err, ErrMismatch) {
+			t.Errorf("Exp() error = %v, want %v", err, ErrMismatch)
+		}
+	})
+}
+
+func TestSelfCheck_rate(t *testing.T) {
+	t.Parallel()
+
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+	m := NewMontgomeryCIOSWords(new(big.Int).Lsh(big.NewInt(1), 64), N64)
+	faulty := faultyMul{m}
+
+	tests := []struct {
+		name     string
+		rate     float64
+		min, max uint64
+	}{
+		{"never", 0, 0, 0},
+		{"negative", -1, 0, 0},
+		{"always", 1, 1000, 1000},
+		{"above one", 2, 1000, 1000},
+		{"half", 0.5, 350, 650},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			c := NewSelfCheck(faulty, nil, tc.rate, WithRandom(mrand.NewChaCha8([32]byte{'r', 'a', 't', 'e'})))
+			var mismatches uint64
+			for range 1000 {
+				if _, err := c.Mul(big.NewInt(3), big.NewInt(5)); err != nil {
+					mismatches++
+				}
+			}
+			calls, checked := c.Stats()
+			if calls != 1000 || checked < tc.min || checked > tc.max {
+				t.Errorf("Stats() = %d, %d; want 1000, [%d, %d]", calls, checked, tc.min, tc.max)
+			}
+			if mismatches != checked {
+				t.Errorf("mismatches = %d, want %d", mismatches, checked)
+			}
+		})
+	}
+}
+
+func TestSelfCheck_random(t *testing.T) {
+	t.Parallel()
+
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+	m := NewMontgomeryCIOSWords(new(big.Int).Lsh(big.NewInt(1), 64), N64)
+
+	// The same seed samples the same calls
+	checked := func() uint64 {
+		c := NewSelfCheck(m, nil, 0.3, WithRandom(mrand.NewChaCha8([32]byte{'s', 'e', 'e', 'd'})))
+		for range 200 {
+			if _, err := c.Mul(big.NewInt(3), big.NewInt(5)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		_, n := c.Stats()
+		return n
+	}
+	if a, b := checked(), checked(); a != b {
+		t.Errorf("checked = %d and %d with the same seed", a, b)
+	}
+
+	// A failing source is reported, not taken for an unsampled call
+	c := NewSelfCheck(m, nil, 0.5, WithRandom(iotest.ErrReader(io.ErrUnexpectedEOF)))
+	if got, err := c.Mul(big.NewInt(3), big.NewInt(5)); !errors.Is(err, io.ErrUnexpectedEOF) || got != nil {
+		t.Errorf("Mul() = %v, %v; want nil, %v", got, err, io.ErrUnexpectedEOF)
+	}
+	// Rates of 0 and 1 need no randomness
+	for _, rate := range []float64{0, 1} {
+		c := NewSelfCheck(m, nil, rate, WithRandom(iotest.ErrReader(io.ErrUnexpectedEOF)))
+		if _, err := c.Mul(big.NewInt(3), big.NewInt(5)); err != nil {
+			t.Errorf("rate %v: Mul() error = %v", rate, err)
+		}
+	}
+}
+
+// selfTestPanic returns the error f panics with, or nil.
+func selfTestPanic(f func()) (err error) {
+	defer func() {
+		err, _ = recover().(error)
+	}()
+	f()
+	return nil
+}
+
+func TestWithSelfTest(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	Rodd := new(big.Int).Lsh(R, 2)
+	Neven := new(big.Int).Add(N, big.NewInt(1))
+
+	params := []struct {
+		name string
+		R, N *big.Int
+		// bitwise is whether the bit-serial REDC fails the test too: it
+		// takes any power of two as R
+		wantErr, bitwise bool
+	}{
+		{"valid", R, N, false, false},
+		{"R not word aligned", Rodd, N, true, false},
+		{"even N", R, Neven, true, true},
+	}
+
+	for _, c := range montConstructors() {
+		for _, p := range params {
+			t.Run(c.name+"/"+p.name, func(t *testing.T) {
+				t.Parallel()
+				wantErr := p.wantErr && (c.name != "Bitwise" || p.bitwise)
+				err := selfTestPanic(func() { c.new(p.R, p.N, WithSelfTest(4)) })
+				if got := errors.Is(err, ErrSelfTest); got != wantErr {
+					t.Fatalf("panic = %v, want ErrSelfTest %v", err, wantErr)
+				}
+				var mismatch *MismatchError
+				if wantErr && (!errors.As(err, &mismatch) || mismatch.Op != "Mul" || len(mismatch.Inputs) != 2) {
+					t.Errorf("panic = %v, want a *MismatchError for Mul", err)
+				}
+				// Without the option the parameters are trusted
+				if err := selfTestPanic(func() { c.new(p.R, p.N) }); err != nil {
+					t.Errorf("without WithSelfTest: panic %v", err)
+				}
+			})
+		}
+	}
+}
+
+// TestWithSelfTest_errors covers the constructors that return the failure:
+// the Checked ones, which validate first, Open for a backend that ignores
+// the option, and Load for a context whose RR has been tampered with.
+func TestWithSelfTest_errors(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	data, _ := NewMontgomeryCIOSWords(R, N).MarshalBinary()
+	// RR is the last S words; any value below N passes Load's own checks
+	badRR := bytes.Clone(data)
+	badRR[len(badRR)-1] = 0
+
+	tests := []struct {
+		name    string
+		new     func(opts ...Option) (ModMultiplier, error)
+		wantErr error
+	}{
+		{"NewMontgomeryBitwiseChecked", func(opts ...Option) (ModMultiplier, error) { return NewMontgomeryBitwiseChecked(R, N, opts...) }, nil},
+		{"NewMontgomeryCIOSChecked", func(opts ...Option) (ModMultiplier, error) { return NewMontgomeryCIOSChecked(R, N, opts...) }, nil},
+		{"NewMontgomeryCIOSWordsChecked", func(opts ...Option) (ModMultiplier, error) { return NewMontgomeryCIOSWordsChecked(R, N, opts...) }, nil},
+		{"NewMontgomeryCTChecked", func(opts ...Option) (ModMultiplier, error) { return NewMontgomeryCTChecked(R, N, opts...) }, nil},
+		{"NewMontgomeryCarrySaveChecked", func(opts ...Option) (ModMultiplier, error) { return NewMontgomeryCarrySaveChecked(R, N, opts...) }, nil},
+		{"Checked, R not word aligned", func(opts ...Option) (ModMultiplier, error) {
+			return NewMontgomeryCIOSWordsChecked(new(big.Int).Lsh(R, 2), N, opts...)
+		}, ErrRNotWordAligned},
+		{"Open", func(opts ...Option) (ModMultiplier, error) { return Open(R, N, opts...) }, nil},
+		{"Open, faulty backend", func(opts ...Option) (ModMultiplier, error) {
+			return Open(R, N, append(opts, WithBackend("test-faulty"))...)
+		}, ErrSelfTest},
+		{"Load", func(opts ...Option) (ModMultiplier, error) { return Load(KindCIOSWords, data, opts...) }, nil},
+		{"Load, wrong RR", func(opts ...Option) (ModMultiplier, error) { return Load(KindCT, badRR, opts...) }, ErrSelfTest},
+		// A reader that fails fails the test, and FromModulus returns that
+		{"NewMontgomeryBitwiseFromModulus, failing random", func(opts ...Option) (ModMultiplier, error) {
+			return NewMontgomeryBitwiseFromModulus(N, append(opts, WithRandom(iotest.ErrReader(io.ErrUnexpectedEOF)))...)
+		}, ErrSelfTest},
+		{"NewMontgomeryCIOSFromModulus, failing random", func(opts ...Option) (ModMultiplier, error) {
+			return NewMontgomeryCIOSFromModulus(N, append(opts, WithRandom(iotest.ErrReader(io.ErrUnexpectedEOF)))...)
+		}, ErrSelfTest},
+		{"NewMontgomeryCIOSWordsFromModulus, failing random", func(opts ...Option) (ModMultiplier, error) {
+			return NewMontgomeryCIOSWordsFromModulus(N, append(opts, WithRandom(iotest.ErrReader(io.ErrUnexpectedEOF)))...)
+		}, ErrSelfTest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := tc.new(WithSelfTest(8)); !errors.Is(err, tc.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tc.wantErr)
+			}
+			// The default is not to test
+			if _, err := tc.new(); err != nil && !errors.Is(err, ErrInvalidParameters) {
+				t.Errorf("without WithSelfTest: error = %v", err)
+			}
+		})
+	}
+}
+
+// TestWithSelfTest_random checks that the operands come from the reader of
+// WithRandom: the same seed finds the same failing pair.
+func TestWithSelfTest_random(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	mismatch := func() *MismatchError {
+		_, err := Open(R, N, WithBackend("test-faulty"), WithSelfTest(1), WithRandom(mrand.NewChaCha8([32]byte{'s', 't'})))
+		var m *MismatchError
+		if !errors.As(err, &m) {
+			t.Fatalf("error = %v, want a *MismatchError", err)
+		}
+		return m
+	}
+	a, b := mismatch(), mismatch()
+	if a.Inputs[0].Cmp(b.Inputs[0]) != 0 || a.Inputs[1].Cmp(b.Inputs[1]) != 0 {
+		t.Errorf("inputs %v and %v with the same seed", a.Inputs, b.Inputs)
+	}
+}
+
+// BenchmarkWithSelfTest measures what the self-test adds to construction.
+func BenchmarkWithSelfTest(b *testing.B) {
+	_, _, R, N := testParams2048()
+	for _, rounds := range []int{0, 8} {
+		b.Run(fmt.Sprintf("bits=2048/rounds=%d", rounds), func(b *testing.B) {
+			for b.Loop() {
+				NewMontgomeryCIOSWords(R, N, WithSelfTest(rounds))
+			}
+		})
+	}
+}
+
+func BenchmarkSelfCheck(b *testing.B) {
+	x, y, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	for _, rate := range []float64{0, 0.01, 1} {
+		c := NewSelfCheck(m, nil, rate)
+		b.Run(big.NewFloat(rate).String(), func(b *testing.B) {
+			for b.Loop() {
+				if _, err := c.Mul(x, y); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// shortWordMul wraps a ModMultiplier and corrupts products with an operand
+// of one word above 1, as a kernel that mishandles leading zero words
+// would. Random full-size operands almost never expose it.
+type shortWordMul struct {
+	ModMultiplier
+}
+
+func (f shortWordMul) Mul(x, y *big.Int) *big.Int {
+	z := f.ModMultiplier.Mul(x, y)
+	if x.Cmp(big.NewInt(1)) > 0 && x.BitLen() <= 64 {
+		z.Add(z, big.NewInt(1)).Mod(z, f.Modulus())
+	}
+	return z
+}
+
+// brokenExp wraps a ModMultiplier with an Exp that is off by one for
+// exponents above 2.
+type brokenExp struct {
+	ModMultiplier
+}
+
+func (b brokenExp) Exp(base, exp *big.Int) *big.Int {
+	z := new(big.Int).Exp(base, exp, b.Modulus())
+	if exp.Cmp(big.NewInt(2)) > 0 {
+		z.Add(z, big.NewInt(1)).Mod(z, b.Modulus())
+	}
+	return z
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	for _, bits := range []int{64, 256, 2048} {
+		_, _, _, N := testParamsLarge(bits)
+		for _, kind := range []Kind{KindBitwise, KindCIOS, KindCIOSWords, KindCT} {
+			m, err := New(kind, N)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rng := mrand.NewChaCha8([32]byte{byte(bits)})
+			if err := Verify(m, 8, rng); err != nil {
+				t.Errorf("%d bits/%v: %v", bits, kind, err)
+			}
+		}
+	}
+	// Small and even moduli, and the default source of randomness
+	p, _ := testPrimes(256)
+	for _, N := range []*big.Int{big.NewInt(3), big.NewInt(2), new(big.Int).Lsh(p, 3)} {
+		m, err := New(KindCIOSWords, N)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Verify(m, 4, nil); err != nil {
+			t.Errorf("N = %v: %v", N, err)
+		}
+	}
+}
+
+func TestVerify_errors(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParamsLarge(256)
+	words := NewMontgomeryCIOSWords(R, N)
+	rng := func() *mrand.ChaCha8 { return mrand.NewChaCha8([32]byte{}) }
+	tests := []struct {
+		name   string
+		m      ModMultiplier
+		rounds int
+		rng    io.Reader
+		want   error
+	}{
+		{"every product", brokenMul{words}, 0, rng(), ErrMismatch},
+		{"odd operands", faultyMul{words}, 0, rng(), ErrMismatch},
+		// Found by the adversarial operands alone, with no random rounds
+		{"one-word operands", shortWordMul{words}, 0, rng(), ErrMismatch},
+		{"Exp", brokenExp{words}, 0, rng(), ErrMismatch},
+		{"rng", words, 1, failingReader{}, errNoEntropy},
+		{"rng unused without rounds", words, 0, failingReader{}, nil},
+	}
+	for _, tc := range tests {
+		err := Verify(tc.m, tc.rounds, tc.rng)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: Verify() = %v, want %v", tc.name, err, tc.want)
+		}
+		var mismatch *MismatchError
+		if errors.Is(tc.want, ErrMismatch) && errors.As(err, &mismatch) {
+			if mismatch.Got.Cmp(mismatch.Want) == 0 {
+				t.Errorf("%s: MismatchError with equal results %v", tc.name, mismatch.Got)
+			}
+		}
+	}
+}
+
+// BenchmarkVerify measures a startup check of 16 rounds.
+func BenchmarkVerify(b *testing.B) {
+	for _, bits := range []int{256, 2048} {
+		_, _, R, N := testParamsLarge(bits)
+		m := NewMontgomeryCIOSWords(R, N)
+		b.Run(fmt.Sprintf("bits=%d/impl=cioswords", bits), func(b *testing.B) {
+			rng := mrand.NewChaCha8([32]byte{})
+			for b.Loop() {
+				if err := Verify(m, 16, rng); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package montgomery
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// separatedThreshold is the number of 64-bit words in R (128 words = 8192
+// bits) from which MontgomeryCIOSWords switches from interleaved CIOS to the
+// separated product-then-reduce strategy.
+//
+// Interleaved CIOS is inherently quadratic: every word of y is multiplied
+// against every word of x and of N. From this size on, the VDF and time-lock
+// regime, the sub-quadratic multipliers behind big.Int.Mul (Karatsuba) win by
+// a growing margin. The threshold is conservative; BenchmarkRedcLarge shows
+// the crossover on the host at hand.
+const separatedThreshold = 128
+
+// fullInverse computes -N^(-1) mod R for the separated reduction.
+//
+// Unlike NI, which only covers the lowest word, this is the full-width
+// constant that lets REDC run as three whole-number multiplications. It is
+// lifted from NI by newtonRaphsonInverseWords rather than found with an
+// extended GCD.
+func fullInverse(N, R *big.Int) *big.Int {
+	s := R.BitLen() / 64
+	return tobigInt(newtonRaphsonInverseWords(limbsPadded(N, s), s))
+}
+
+// redcSeparated performs Montgomery reduction (x * y * R⁻¹) mod N by first
+// forming the full product and then reducing it as a separate step:
+//
+//	T = x * y
+//	m = (T mod R) * N' mod R   where N' = -N⁻¹ mod R
+//	t = (T + m * N) / R
+//
+// Each step is a plain multiplication, truncation or shift, so the whole
+// reduction inherits the sub-quadratic complexity of big.Int.Mul instead of
+// the quadratic word-by-word loop of CIOS. Since T + m*N ≡ 0 (mod R) the
+// division by R is an exact shift.
+func (m *MontgomeryCIOSWords) redcSeparated(x, y *big.Int) *big.Int {
+	return m.reduceSeparated(new(big.Int).Mul(x, y))
+}
+
+// reduceSeparated is the reduction half of redcSeparated, overwriting T.
+func (m *MontgomeryCIOSWords) reduceSeparated(T *big.Int) *big.Int {
+	k := uint(m.S * 64)
+
+	// m = (T mod R) * N' mod R
+	mm := lowBits(T, k)
+	mm.Mul(mm, m.np)
+	mm = lowBits(mm, k)
+
+	// t = (T + m * N) / R
+	mm.Mul(mm, m.N)
+	T.Add(T, mm)
+	T.Rsh(T, k)
+
+	if T.Cmp(m.N) >= 0 {
+		T.Sub(T, m.N)
+	}
+	return T
+}
+
+// lowBits returns x mod 2^k for x ≥ 0 and k a multiple of the word size,
+// without a division.
+func lowBits(x *big.Int, k uint) *big.Int {
+	words := x.Bits()
+	n := int(k / bits.UintSize)
+	if len(words) <= n {
+		return new(big.Int).Set(x)
+	}
+	low := make([]big.Word, n)
+	copy(low, words[:n])
+	return new(big.Int).SetBits(low)
+}
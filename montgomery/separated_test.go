@@ -0,0 +1,103 @@
+package montgomery
+
+import (
+	"math/big"
+	"math/rand/v2"
+	"testing"
+	"testing/quick"
+)
+
+// testParamsLarge returns deterministic pseudo-random operands and an odd
+// modulus of the given bit size (a multiple of 64) in the separated regime.
+func testParamsLarge(bitSize int) (x, y, R, N *big.Int) {
+	rng := rand.New(rand.NewPCG(uint64(bitSize), 0x5eed))
+	random := func() *big.Int {
+		words := make([]big.Word, bitSize/64)
+		for i := range words {
+			words[i] = big.Word(rng.Uint64())
+		}
+		return new(big.Int).SetBits(words)
+	}
+
+	N = random()
+	N.SetBit(N, bitSize-1, 1)
+	N.SetBit(N, 0, 1)
+	x = random()
+	x.Mod(x, N)
+	y = random()
+	y.Mod(y, N)
+	R = new(big.Int).Lsh(big.NewInt(1), uint(bitSize))
+	return
+}
+
+func TestMontgomeryCIOSWords_separated(t *testing.T) {
+	t.Parallel()
+
+	for _, bitSize := range []int{8192, 16384} {
+		x, y, R, N := testParamsLarge(bitSize)
+		m := NewMontgomeryCIOSWords(R, N)
+		if m.np == nil {
+			t.Fatalf("%d-bit modulus: separated path not selected", bitSize)
+		}
+
+		want := new(big.Int).Mod(new(big.Int).Mul(x, y), N)
+		if got := m.Mul(x, y); got.Cmp(want) != 0 {
+			t.Errorf("%d-bit Mul: got %v, want %v", bitSize, got, want)
+		}
+
+		e := big.NewInt(65537)
+		wantExp := new(big.Int).Exp(x, e, N)
+		if got := m.modExp(x, e); got.Cmp(wantExp) != 0 {
+			t.Errorf("%d-bit modExp: got %v, want %v", bitSize, got, wantExp)
+		}
+	}
+}
+
+func TestMontgomeryCIOSWords_separatedMatchesInterleaved(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParamsLarge(8192)
+	m := NewMontgomeryCIOSWords(R, N)
+
+	err := quick.Check(func(xBytes, yBytes []byte) bool {
+		x := new(big.Int).SetBytes(xBytes)
+		y := new(big.Int).SetBytes(yBytes)
+		x.Mod(x, N)
+		y.Mod(y, N)
+		return m.redcSeparated(x, y).Cmp(m.redcInterleaved(x, y)) == 0
+	}, &quick.Config{MaxCount: 50})
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_separatedThreshold(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	if m := NewMontgomeryCIOSWords(R, N); m.np != nil {
+		t.Error("2048-bit modulus: separated path selected; want interleaved CIOS")
+	}
+}
+
+// BenchmarkRedcLarge compares interleaved CIOS against the separated
+// product-then-reduce strategy in the VDF/time-lock size regime.
+func BenchmarkRedcLarge(b *testing.B) {
+	for _, bitSize := range []int{8192, 16384} {
+		x, y, R, N := testParamsLarge(bitSize)
+		m := NewMontgomeryCIOSWords(R, N)
+
+		b.Run(big.NewInt(int64(bitSize)).String()+"/Interleaved", func(b *testing.B) {
+			for b.Loop() {
+				m.redcInterleaved(x, y)
+			}
+		})
+
+		b.Run(big.NewInt(int64(bitSize)).String()+"/Separated", func(b *testing.B) {
+			for b.Loop() {
+				m.redcSeparated(x, y)
+			}
+		})
+	}
+}
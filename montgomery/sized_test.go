@@ -0,0 +1,104 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"testing"
+	"testing/quick"
+)
+
+// TestMontMulSized checks every fixed-size kernel against montMulSlice,
+// including the operands N-1 and 0 and moduli just above a power of two,
+// whose results most often need the final subtraction.
+func TestMontMulSized(t *testing.T) {
+	t.Parallel()
+
+	for _, k := range []int{4, 6, 8} {
+		t.Run(fmt.Sprintf("limbs=%d", k), func(t *testing.T) {
+			t.Parallel()
+			R := new(big.Int).Lsh(big.NewInt(1), uint(64*k))
+			_, _, _, random := testParamsLarge(64 * k)
+			low := new(big.Int).Rsh(R, 1)
+			low.Add(low, big.NewInt(1))
+			for _, N := range []*big.Int{random, low, new(big.Int).Sub(R, big.NewInt(1))} {
+				m := NewMontgomeryCIOSWords(R, N)
+				top := m.ToWords(new(big.Int).Sub(N, big.NewInt(1)))
+				check := func(x, y []uint64) bool {
+					got, want := make([]uint64, k), make([]uint64, k)
+					if !montMulSized(got, x, y, m.nw, m.NI) {
+						t.Fatalf("no kernel for %d limbs", k)
+					}
+					montMulSlice(want, x, y, m.nw, m.NI, make([]uint64, k+2))
+					return slices.Equal(got, want)
+				}
+				if !check(top, top) || !check(top, make([]uint64, k)) {
+					t.Errorf("N = %v: montMulSized differs from montMulSlice on N-1", N)
+				}
+				err := quick.Check(func(xBytes, yBytes []byte) bool {
+					x := m.ToWords(new(big.Int).Mod(new(big.Int).SetBytes(xBytes), N))
+					y := m.ToWords(new(big.Int).Mod(new(big.Int).SetBytes(yBytes), N))
+					return check(x, y)
+				}, nil)
+				if err != nil {
+					t.Errorf("N = %v: %v", N, err)
+				}
+			}
+		})
+	}
+}
+
+func TestMontMulSized_aliasing(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParamsLarge(256)
+	m := NewMontgomeryCIOSWords(R, N)
+	xw, yw := m.ToWords(x), m.ToWords(y)
+	want := make([]uint64, m.S)
+	montMulSlice(want, xw, yw, m.nw, m.NI, make([]uint64, m.S+2))
+
+	z := slices.Clone(xw)
+	montMulSized(z, z, yw, m.nw, m.NI)
+	if !slices.Equal(z, want) {
+		t.Errorf("z = x: got %v, want %v", z, want)
+	}
+	z = slices.Clone(xw)
+	montMulSized(z, z, z, m.nw, m.NI)
+	montMulSlice(want, xw, xw, m.nw, m.NI, make([]uint64, m.S+2))
+	if !slices.Equal(z, want) {
+		t.Errorf("z = x = y: got %v, want %v", z, want)
+	}
+}
+
+func TestMontMulSized_otherSizes(t *testing.T) {
+	t.Parallel()
+
+	for _, k := range []int{1, 2, 3, 5, 7, 9, 16} {
+		n := make([]uint64, k)
+		if montMulSized(n, n, n, n, 0) {
+			t.Errorf("montMulSized ran for %d limbs", k)
+		}
+	}
+}
+
+// BenchmarkMontMulSized chains the products, z = z·y, as exponentiation
+// does: each one waits for the last, so the benchmark measures latency.
+func BenchmarkMontMulSized(b *testing.B) {
+	for _, bitSize := range []int{256, 384, 512} {
+		x, y, R, N := testParamsLarge(bitSize)
+		m := NewMontgomeryCIOSWords(R, N)
+		z, yw := m.ToWords(x), m.ToWords(y)
+		t := make([]uint64, m.S+2)
+
+		b.Run(fmt.Sprintf("bits=%d/impl=sized", bitSize), func(b *testing.B) {
+			for b.Loop() {
+				montMulSized(z, z, yw, m.nw, m.NI)
+			}
+		})
+		b.Run(fmt.Sprintf("bits=%d/impl=slice", bitSize), func(b *testing.B) {
+			for b.Loop() {
+				montMulSlice(z, z, yw, m.nw, m.NI, t)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package solinas
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+)
+
+// ErrInvalidParameters is returned by NewPseudoMersenne for a k or c it
+// cannot reduce with.
+var ErrInvalidParameters = errors.New("solinas: invalid pseudo-Mersenne parameters")
+
+// maxPseudoBits is the largest k NewPseudoMersenne accepts, the limbs of
+// P-521.
+const maxPseudoBits = 64 * maxLimbs
+
+// NewPseudoMersenne returns the Field of p = 2^k - c, a pseudo-Mersenne
+// number such as 2^255 - 19 (Curve25519), 2^414 - 17 or 2^521 - 1. Since
+// 2^k ≡ c mod p, a product t = hi·2^k + lo reduces to lo + c·hi, one
+// single-word multiplication per limb, and repeating that fold a few times
+// leaves less than 2^k, at most one subtraction of p from the result.
+//
+// k must be in [2, 576] and c in [1, 2^(k/2)); the bound on c keeps the
+// folds to at most four. It returns an error wrapping
+// ErrInvalidParameters otherwise. p need not be prime, but Mul is only a
+// field multiplication when it is.
+func NewPseudoMersenne(k uint, c uint64) (*Field, error) {
+	if k < 2 || k > maxPseudoBits {
+		return nil, fmt.Errorf("%w: k = %d, want 2 to %d", ErrInvalidParameters, k, maxPseudoBits)
+	}
+	if c == 0 || k/2 < 64 && c >= 1<<(k/2) {
+		return nil, fmt.Errorf("%w: c = %d, want 1 to 2^%d - 1", ErrInvalidParameters, c, k/2)
+	}
+	p := new(big.Int).Lsh(big.NewInt(1), k)
+	p.Sub(p, new(big.Int).SetUint64(c))
+	f := &Field{
+		name:  fmt.Sprintf("2^%d-%d", k, c),
+		p:     p,
+		limbs: (int(k) + 63) / 64,
+		id:    pseudoMersenne,
+		k:     k,
+		c:     c,
+	}
+	f.pl = make([]uint64, f.limbs)
+	toLimbs(f.pl, p)
+	return f, nil
+}
+
+// pseudoMersenneOf returns k and c with N = 2^k - c for a c that
+// NewPseudoMersenne accepts, or false.
+func pseudoMersenneOf(N *big.Int) (k uint, c uint64, ok bool) {
+	k = uint(N.BitLen())
+	if k < 2 || k > maxPseudoBits {
+		return 0, 0, false
+	}
+	d := new(big.Int).Lsh(big.NewInt(1), k)
+	d.Sub(d, N)
+	if !d.IsUint64() || d.Sign() == 0 {
+		return 0, 0, false
+	}
+	c = d.Uint64()
+	if k/2 < 64 && c >= 1<<(k/2) {
+		return 0, 0, false
+	}
+	return k, c, true
+}
+
+// reducePseudo folds t = hi·2^k + lo into lo + c·hi until nothing is left
+// above 2^k, then subtracts p once if needed. The first fold leaves less
+// than 2^k·(1 + c), within one limb more than p, so later folds only look
+// at the two limbs from 2^k up, which shrink to at most c times their size
+// over 2^k each time.
+func (f *Field) reducePseudo(z, t []uint64) {
+	n := f.limbs
+	top := f.k - 64*uint(n-1) // bits of p in its top limb
+	var v, hi [2 * maxLimbs]uint64
+	copy(v[:], t)
+	for m := 2 * n; ; m = n + 1 {
+		// hi = v >> k, h limbs of it for v of m limbs
+		h := m - n + 1
+		var rest uint64
+		for i := range h {
+			w := v[n-1+i] >> top // 0 for top = 64
+			if j := n + i; j < m {
+				w |= v[j] << (64 - top)
+			}
+			hi[i] = w
+			rest |= w
+		}
+		if rest == 0 {
+			break
+		}
+		// v = (v mod 2^k) + c·hi, which fits in n+1 limbs
+		if top < 64 {
+			v[n-1] &= 1<<top - 1
+		}
+		clear(v[n:m])
+		var carry uint64
+		for i := range h {
+			ph, pl := bits.Mul64(hi[i], f.c)
+			var cc uint64
+			v[i], cc = bits.Add64(v[i], pl, 0)
+			v[i], carry = bits.Add64(v[i], carry, 0)
+			carry += ph + cc
+		}
+		for i := h; i <= n && carry != 0; i++ {
+			v[i], carry = bits.Add64(v[i], carry, 0)
+		}
+	}
+	var d [maxLimbs]uint64
+	var borrow uint64
+	for i := range n {
+		d[i], borrow = bits.Sub64(v[i], f.pl[i], borrow)
+	}
+	if borrow == 0 {
+		copy(z, d[:n])
+		return
+	}
+	copy(z, v[:n])
+}
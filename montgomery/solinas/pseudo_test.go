@@ -0,0 +1,156 @@
+package solinas
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/blck-snwmn/arithmetic-vault/montgomery"
+	"github.com/blck-snwmn/arithmetic-vault/montgomery/vaulttest"
+)
+
+func TestNewPseudoMersenne(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		k uint
+		c uint64
+	}{
+		{2, 1},
+		{5, 1},
+		{61, 1},
+		{64, 59},
+		{65, 3},
+		{127, 1},
+		{128, 1<<63 + 1}, // c as large as 2^(k/2) allows
+		{130, 5},         // Poly1305
+		{192, 1<<64 - 1},
+		{255, 19}, // Curve25519
+		{256, 189},
+		{383, 187},
+		{414, 17}, // Curve41417
+		{521, 1},
+		{576, 1<<64 - 1},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("2^%d-%d", tc.k, tc.c), func(t *testing.T) {
+			t.Parallel()
+			f, err := NewPseudoMersenne(tc.k, tc.c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := new(big.Int).Lsh(big.NewInt(1), tc.k)
+			p.Sub(p, new(big.Int).SetUint64(tc.c))
+			if f.Modulus().Cmp(p) != 0 || f.Name() != fmt.Sprintf("2^%d-%d", tc.k, tc.c) || f.Limbs() != (int(tc.k)+63)/64 {
+				t.Fatalf("Modulus() = %x, Name() = %s, Limbs() = %d", f.Modulus(), f.Name(), f.Limbs())
+			}
+			rng := rand.New(rand.NewPCG(uint64(tc.k), tc.c))
+			xs := operands(rng, p)
+			for i, x := range xs {
+				for _, y := range []*big.Int{x, xs[(i*7+3)%len(xs)]} {
+					want := new(big.Int).Mul(x, y)
+					want.Mod(want, p)
+					if got := f.Mul(x, y); got.Cmp(want) != 0 {
+						t.Fatalf("Mul(%x, %x) = %x, want %x", x, y, got, want)
+					}
+				}
+			}
+
+			// The extremes of ReduceWords: (p-1)², and p·(p-1) and the
+			// values around it
+			n := f.Limbs()
+			pm1 := new(big.Int).Sub(p, big.NewInt(1))
+			for _, v := range []*big.Int{
+				new(big.Int).Mul(pm1, pm1),
+				new(big.Int).Mul(p, pm1),
+				new(big.Int).Sub(new(big.Int).Mul(p, pm1), big.NewInt(1)),
+				new(big.Int).Lsh(big.NewInt(1), tc.k),
+				p,
+			} {
+				tw := make([]uint64, 2*n)
+				toLimbs(tw, v)
+				z := make([]uint64, n)
+				f.ReduceWords(z, tw)
+				if got, want := fromLimbs(z), new(big.Int).Mod(v, p); got.Cmp(want) != 0 {
+					t.Errorf("ReduceWords(%x) = %x, want %x", v, got, want)
+				}
+			}
+
+			if p.ProbablyPrime(20) {
+				vaulttest.TestField(t, vaulttest.ModField(f))
+			}
+			if g, err := ForModulus(p); err != nil || g.Modulus().Cmp(p) != 0 {
+				t.Errorf("ForModulus(%s) = %v, %v", f.Name(), g, err)
+			}
+		})
+	}
+}
+
+func TestNewPseudoMersenne_errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		k uint
+		c uint64
+	}{
+		{1, 1},
+		{577, 1},
+		{255, 0},
+		{5, 4},           // c ≥ 2^(k/2)
+		{64, 1<<32 + 15}, // c ≥ 2^32
+	}
+	for _, tc := range tests {
+		if _, err := NewPseudoMersenne(tc.k, tc.c); !errors.Is(err, ErrInvalidParameters) {
+			t.Errorf("NewPseudoMersenne(%d, %d): error = %v, want %v", tc.k, tc.c, err, ErrInvalidParameters)
+		}
+	}
+
+	// The named primes take precedence, and moduli far from a power of two
+	// have no form
+	p521, _ := Lookup("p521")
+	if f, err := ForModulus(p521.Modulus()); err != nil || f != p521 {
+		t.Errorf("ForModulus(2^521-1) = %v, %v, want the p521 field", f, err)
+	}
+	for _, name := range []string{"curve448", "babybear", "modp2048"} {
+		params, _ := montgomery.LookupParams(name)
+		if _, err := ForModulus(params.N); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("ForModulus(%s): error = %v, want %v", name, err, ErrUnsupported)
+		}
+	}
+}
+
+// BenchmarkPseudoMersenne compares Curve25519's field, 2^255 - 19, with a
+// Montgomery product of the same size, as BenchmarkMulWords does for the
+// NIST primes.
+func BenchmarkPseudoMersenne(b *testing.B) {
+	f, err := NewPseudoMersenne(255, 19)
+	if err != nil {
+		b.Fatal(err)
+	}
+	p := f.Modulus()
+	rng := rand.New(rand.NewPCG(1, 2))
+	xs := operands(rng, p)
+	x, y := make([]uint64, 4), make([]uint64, 4)
+	toLimbs(x, xs[len(xs)-1])
+	toLimbs(y, xs[len(xs)-2])
+	b.Run("bits=255/impl=pseudomersenne", func(b *testing.B) {
+		z := make([]uint64, 4)
+		copy(z, x)
+		for b.Loop() {
+			f.MulWords(z, z, y)
+		}
+	})
+	m, err := montgomery.NewMontgomeryCIOSWordsChecked(new(big.Int).Lsh(big.NewInt(1), 256), p)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("bits=255/impl=montgomery", func(b *testing.B) {
+		z := make([]uint64, 4)
+		copy(z, x)
+		for b.Loop() {
+			m.MulMontWords(z, z, y)
+		}
+	})
+}
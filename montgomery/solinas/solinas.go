@@ -0,0 +1,383 @@
+// Package solinas multiplies modulo the NIST curve primes and
+// pseudo-Mersenne numbers with their special-form reductions, the classic
+// alternative to Montgomery multiplication for curve fields.
+//
+// The primes P-192, P-224, P-256 and P-384 are generalized Mersenne
+// numbers, sums and differences of powers of 2^32 (Solinas, 1999), so a
+// double-width product reduces by adding and subtracting a fixed handful
+// of rearrangements of its own 32-bit words, as listed in FIPS 186-4,
+// appendix D.2. P-521 is the Mersenne prime 2^521 - 1, where the high half
+// of a product is simply added to the low half. NewPseudoMersenne extends
+// that to any 2^k - c with a small c, such as 2^255 - 19. None needs a
+// precomputed inverse or a change of representation: operands and results
+// are plain residues.
+//
+// A Field implements montgomery.ModMultiplier, so it can stand in for a
+// Montgomery context and be checked by vaulttest:
+//
+//	f, _ := solinas.Lookup("p256")
+//	z := f.Mul(x, y)
+//
+// The final corrections loop a data-dependent number of times, so a Field
+// is not constant time.
+package solinas
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"slices"
+
+	"github.com/blck-snwmn/arithmetic-vault/montgomery"
+)
+
+// ErrUnsupported is returned for a prime with no special-form reduction
+// here.
+var ErrUnsupported = errors.New("solinas: no special-form reduction for modulus")
+
+const (
+	maxLimbs = 9  // P-521
+	maxWords = 12 // P-384, in 32-bit words
+)
+
+// Field is multiplication modulo one special-form prime p. It is immutable
+// and safe for concurrent use.
+type Field struct {
+	name  string
+	p     *big.Int
+	limbs int      // 64-bit limbs of p
+	pl    []uint64 // p in 64-bit limbs, little-endian
+	pw    []uint32 // p in 32-bit words, little-endian, for the formulas
+	id    prime
+	k     uint // p = 2^k - c, for NewPseudoMersenne
+	c     uint64
+}
+
+// The reduction formulas of FIPS 186-4, appendix D.2, collected by result
+// word, least significant first: acc[j] is word j of the reduced value
+// before carries, a signed sum of the 32-bit words c of the double-width
+// input.
+
+// sumP192 is T + S1 + S2 + S3, written in 64-bit words in the standard.
+func sumP192(acc *[maxWords]int64, c *[2 * maxWords]int64) {
+	acc[0] = c[0] + c[6] + c[10]
+	acc[1] = c[1] + c[7] + c[11]
+	acc[2] = c[2] + c[6] + c[8] + c[10]
+	acc[3] = c[3] + c[7] + c[9] + c[11]
+	acc[4] = c[4] + c[8] + c[10]
+	acc[5] = c[5] + c[9] + c[11]
+}
+
+// sumP224 is T + S1 + S2 - D1 - D2.
+func sumP224(acc *[maxWords]int64, c *[2 * maxWords]int64) {
+	acc[0] = c[0] - c[7] - c[11]
+	acc[1] = c[1] - c[8] - c[12]
+	acc[2] = c[2] - c[9] - c[13]
+	acc[3] = c[3] + c[7] - c[10] + c[11]
+	acc[4] = c[4] + c[8] - c[11] + c[12]
+	acc[5] = c[5] + c[9] - c[12] + c[13]
+	acc[6] = c[6] + c[10] - c[13]
+}
+
+// sumP256 is T + 2S1 + 2S2 + S3 + S4 - D1 - D2 - D3 - D4.
+func sumP256(acc *[maxWords]int64, c *[2 * maxWords]int64) {
+	acc[0] = c[0] + c[8] + c[9] - c[11] - c[12] - c[13] - c[14]
+	acc[1] = c[1] + c[9] + c[10] - c[12] - c[13] - c[14] - c[15]
+	acc[2] = c[2] + c[10] + c[11] - c[13] - c[14] - c[15]
+	acc[3] = c[3] - c[8] - c[9] + 2*c[11] + 2*c[12] + c[13] - c[15]
+	acc[4] = c[4] - c[9] - c[10] + 2*c[12] + 2*c[13] + c[14]
+	acc[5] = c[5] - c[10] - c[11] + 2*c[13] + 2*c[14] + c[15]
+	acc[6] = c[6] - c[8] - c[9] + c[13] + 3*c[14] + 2*c[15]
+	acc[7] = c[7] + c[8] - c[10] - c[11] - c[12] - c[13] + 3*c[15]
+}
+
+// sumP384 is T + 2S1 + S2 + S3 + S4 + S5 + S6 - D1 - D2 - D3.
+func sumP384(acc *[maxWords]int64, c *[2 * maxWords]int64) {
+	acc[0] = c[0] + c[12] + c[20] + c[21] - c[23]
+	acc[1] = c[1] - c[12] + c[13] - c[20] + c[22] + c[23]
+	acc[2] = c[2] - c[13] + c[14] - c[21] + c[23]
+	acc[3] = c[3] + c[12] - c[14] + c[15] + c[20] + c[21] - c[22] - c[23]
+	acc[4] = c[4] + c[12] + c[13] - c[15] + c[16] + c[20] + 2*c[21] + c[22] - 2*c[23]
+	acc[5] = c[5] + c[13] + c[14] - c[16] + c[17] + c[21] + 2*c[22] + c[23]
+	acc[6] = c[6] + c[14] + c[15] - c[17] + c[18] + c[22] + 2*c[23]
+	acc[7] = c[7] + c[15] + c[16] - c[18] + c[19] + c[23]
+	acc[8] = c[8] + c[16] + c[17] - c[19] + c[20]
+	acc[9] = c[9] + c[17] + c[18] - c[20] + c[21]
+	acc[10] = c[10] + c[18] + c[19] - c[21] + c[22]
+	acc[11] = c[11] + c[19] + c[20] - c[22] + c[23]
+}
+
+// prime identifies a supported prime, to pick its reduction with a switch
+// rather than a function value, through which the word arrays would escape
+// to the heap.
+type prime uint8
+
+const (
+	p192 prime = iota
+	p224
+	p256
+	p384
+	p521
+	pseudoMersenne
+)
+
+// fields holds one Field per supported prime, built on first use of the
+// package.
+var fields = func() map[string]*Field {
+	m := make(map[string]*Field)
+	for id, name := range []string{"p192", "p224", "p256", "p384", "p521"} {
+		params, err := montgomery.LookupParams(name)
+		if err != nil {
+			panic(err)
+		}
+		p := params.N
+		f := &Field{name: name, p: p, limbs: (p.BitLen() + 63) / 64, id: prime(id)}
+		f.pl = make([]uint64, f.limbs)
+		toLimbs(f.pl, p)
+		f.pw = make([]uint32, (p.BitLen()+31)/32)
+		for i := range f.pw {
+			f.pw[i] = uint32(f.pl[i/2] >> (32 * (i % 2)))
+		}
+		m[name] = f
+	}
+	return m
+}()
+
+// Lookup returns the Field of the NIST prime registered under name with
+// montgomery.LookupParams: p192, p224, p256, p384 or p521. Other names
+// return an error wrapping ErrUnsupported.
+func Lookup(name string) (*Field, error) {
+	f, ok := fields[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnsupported, name)
+	}
+	return f, nil
+}
+
+// ForModulus returns the Field whose prime is N, or failing that the
+// NewPseudoMersenne field of N = 2^k - c, or an error wrapping
+// ErrUnsupported if N has neither form. It fits constructors that take a
+// modulus, such as a vaulttest.Impl.
+func ForModulus(N *big.Int) (*Field, error) {
+	for _, name := range Names() {
+		if f := fields[name]; f.p.Cmp(N) == 0 {
+			return f, nil
+		}
+	}
+	if k, c, ok := pseudoMersenneOf(N); ok {
+		return NewPseudoMersenne(k, c)
+	}
+	return nil, fmt.Errorf("%w %v", ErrUnsupported, N)
+}
+
+// Names returns the names Lookup accepts, sorted.
+func Names() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Name returns the name of the prime, as LookupParams knows it, or
+// "2^k-c" for a NewPseudoMersenne field.
+func (f *Field) Name() string { return f.name }
+
+// Modulus returns p.
+func (f *Field) Modulus() *big.Int { return new(big.Int).Set(f.p) }
+
+// Limbs returns the number of 64-bit limbs of p, the length of the
+// operands of MulWords.
+func (f *Field) Limbs() int { return f.limbs }
+
+// Mul returns (x * y) mod p. Operands outside [0, p) are reduced mod p
+// first, as a Montgomery context does under its default InputPermissive.
+func (f *Field) Mul(x, y *big.Int) *big.Int {
+	var xs, ys [maxLimbs]uint64
+	f.operand(xs[:f.limbs], x)
+	f.operand(ys[:f.limbs], y)
+	f.MulWords(xs[:f.limbs], xs[:f.limbs], ys[:f.limbs])
+	return fromLimbs(xs[:f.limbs])
+}
+
+// MulWords sets z = (x * y) mod p. x, y and z are Limbs() little-endian
+// 64-bit limbs with x and y in [0, p), which is not checked; z may be x, y
+// or both.
+func (f *Field) MulWords(z, x, y []uint64) {
+	f.checkLen("MulWords", f.limbs, z, x, y)
+	var t [2 * maxLimbs]uint64
+	mulWords(t[:2*f.limbs], x, y)
+	f.reduce(z, t[:2*f.limbs])
+}
+
+// ReduceWords sets z = t mod p for t in [0, p²), the product of two
+// residues. z has Limbs() limbs and t twice as many.
+func (f *Field) ReduceWords(z, t []uint64) {
+	f.checkLen("ReduceWords", f.limbs, z)
+	f.checkLen("ReduceWords", 2*f.limbs, t)
+	f.reduce(z, t)
+}
+
+func (f *Field) reduce(z, t []uint64) {
+	switch f.id {
+	case p521:
+		f.reduceMersenne(z, t)
+	case pseudoMersenne:
+		f.reducePseudo(z, t)
+	default:
+		f.reduceFormula(z, t)
+	}
+}
+
+// reduceFormula evaluates the formula in signed 64-bit accumulators,
+// propagates the carries, and then brings the result, now within a few
+// multiples of p, into [0, p).
+func (f *Field) reduceFormula(z, t []uint64) {
+	n := len(f.pw)
+	var c [2 * maxWords]int64
+	for i := range 2 * n {
+		c[i] = int64(uint32(t[i/2] >> (32 * (i % 2))))
+	}
+	var acc [maxWords]int64
+	switch f.id {
+	case p192:
+		sumP192(&acc, &c)
+	case p224:
+		sumP224(&acc, &c)
+	case p256:
+		sumP256(&acc, &c)
+	case p384:
+		sumP384(&acc, &c)
+	}
+	var w [maxWords]uint32
+	var top int64 // the signed word above w
+	for j := range n {
+		v := acc[j] + top
+		w[j] = uint32(v)
+		top = v >> 32
+	}
+	for top < 0 {
+		top += int64(addWords(w[:n], f.pw))
+	}
+	for top > 0 || !lessWords(w[:n], f.pw) {
+		top -= int64(subWords(w[:n], f.pw))
+	}
+	for i := range z {
+		z[i] = uint64(w[2*i]) | uint64(w[2*i+1])<<32
+	}
+}
+
+// reduceMersenne reduces modulo p = 2^521 - 1, where t = hi·2^521 + lo is
+// congruent to hi + lo: two such folds leave at most 2^521, and one
+// subtraction of p finishes.
+func (f *Field) reduceMersenne(z, t []uint64) {
+	const top = 521 % 64
+	var s [maxLimbs]uint64
+	var carry uint64
+	for i := range maxLimbs {
+		lo := t[i]
+		if i == maxLimbs-1 {
+			lo &= 1<<top - 1
+		}
+		hi := t[maxLimbs-1+i]>>top | t[maxLimbs+i]<<(64-top)
+		s[i], carry = bits.Add64(lo, hi, carry)
+	}
+	carry = s[maxLimbs-1] >> top
+	s[maxLimbs-1] &= 1<<top - 1
+	for i := range s {
+		s[i], carry = bits.Add64(s[i], 0, carry)
+	}
+	var d [maxLimbs]uint64
+	var borrow uint64
+	for i := range d {
+		d[i], borrow = bits.Sub64(s[i], f.pl[i], borrow)
+	}
+	if borrow == 0 {
+		s = d
+	}
+	copy(z, s[:])
+}
+
+// operand writes x mod p into z.
+func (f *Field) operand(z []uint64, x *big.Int) {
+	if x.Sign() < 0 || x.Cmp(f.p) >= 0 {
+		x = new(big.Int).Mod(x, f.p)
+	}
+	toLimbs(z, x)
+}
+
+func (f *Field) checkLen(op string, want int, xs ...[]uint64) {
+	for _, x := range xs {
+		if len(x) != want {
+			panic(fmt.Sprintf("solinas: %s: operand has %d limbs, want %d", op, len(x), want))
+		}
+	}
+}
+
+// mulWords sets t = x·y, schoolbook, for len(t) = 2·len(x).
+func mulWords(t, x, y []uint64) {
+	clear(t)
+	for i, xi := range x {
+		var carry uint64
+		for j, yj := range y {
+			hi, lo := bits.Mul64(xi, yj)
+			var c uint64
+			lo, c = bits.Add64(lo, t[i+j], 0)
+			hi += c
+			t[i+j], c = bits.Add64(lo, carry, 0)
+			carry = hi + c
+		}
+		t[i+len(y)] = carry
+	}
+}
+
+// addWords sets w += p and returns the carry.
+func addWords(w, p []uint32) uint32 {
+	var c uint32
+	for i := range w {
+		w[i], c = bits.Add32(w[i], p[i], c)
+	}
+	return c
+}
+
+// subWords sets w -= p and returns the borrow.
+func subWords(w, p []uint32) uint32 {
+	var b uint32
+	for i := range w {
+		w[i], b = bits.Sub32(w[i], p[i], b)
+	}
+	return b
+}
+
+// lessWords reports whether w < p.
+func lessWords(w, p []uint32) bool {
+	for i := len(w) - 1; i >= 0; i-- {
+		if w[i] != p[i] {
+			return w[i] < p[i]
+		}
+	}
+	return false
+}
+
+// toLimbs writes x, which must fit, into z little-endian. z may be up to
+// double width.
+func toLimbs(z []uint64, x *big.Int) {
+	var b [16 * maxLimbs]byte
+	x.FillBytes(b[:8*len(z)])
+	for i := range z {
+		z[i] = binary.BigEndian.Uint64(b[8*(len(z)-1-i):])
+	}
+}
+
+func fromLimbs(z []uint64) *big.Int {
+	var b [16 * maxLimbs]byte
+	for i, w := range z {
+		binary.BigEndian.PutUint64(b[8*(len(z)-1-i):], w)
+	}
+	return new(big.Int).SetBytes(b[:8*len(z)])
+}
+
+var _ montgomery.ModMultiplier = (*Field)(nil)
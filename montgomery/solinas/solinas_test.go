@@ -0,0 +1,199 @@
+package solinas
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/blck-snwmn/arithmetic-vault/montgomery"
+	"github.com/blck-snwmn/arithmetic-vault/montgomery/vaulttest"
+)
+
+// operands returns values of p that stress the carries and corrections:
+// 0, 1, p-1, p-2, powers of two at word boundaries, values made of
+// all-ones and all-zero 32-bit words, and random residues.
+func operands(rng *rand.Rand, p *big.Int) []*big.Int {
+	one := big.NewInt(1)
+	xs := []*big.Int{
+		new(big.Int), one,
+		new(big.Int).Sub(p, one),
+		new(big.Int).Sub(p, big.NewInt(2)),
+		new(big.Int).Rsh(p, 1),
+	}
+	for k := 32; k < p.BitLen(); k += 32 {
+		xs = append(xs, new(big.Int).Lsh(one, uint(k)))
+		xs = append(xs, new(big.Int).Sub(new(big.Int).Lsh(one, uint(k)), one))
+	}
+	words := (p.BitLen() + 31) / 32
+	for range 8 {
+		x := new(big.Int)
+		for range words {
+			x.Lsh(x, 32)
+			if rng.IntN(2) == 0 {
+				x.Or(x, big.NewInt(0xffffffff))
+			}
+		}
+		xs = append(xs, x.Mod(x, p))
+	}
+	for range 64 {
+		x := new(big.Int)
+		for range words {
+			x.Lsh(x, 32).Or(x, big.NewInt(int64(rng.Uint32())))
+		}
+		xs = append(xs, x.Mod(x, p))
+	}
+	return xs
+}
+
+func TestField(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range Names() {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			f, err := Lookup(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			params, err := montgomery.LookupParams(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := f.Modulus()
+			if p.Cmp(params.N) != 0 || f.Name() != name || f.Limbs() != (params.N.BitLen()+63)/64 {
+				t.Fatalf("Modulus() = %x, Name() = %s, Limbs() = %d", p, f.Name(), f.Limbs())
+			}
+			rng := rand.New(rand.NewPCG(uint64(p.BitLen()), 1))
+			xs := operands(rng, p)
+			for i, x := range xs {
+				for _, y := range []*big.Int{x, xs[(i*7+3)%len(xs)], xs[len(xs)-1-i]} {
+					want := new(big.Int).Mul(x, y)
+					want.Mod(want, p)
+					if got := f.Mul(x, y); got.Cmp(want) != 0 {
+						t.Fatalf("Mul(%x, %x) = %x, want %x", x, y, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestField_words(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range Names() {
+		f, _ := Lookup(name)
+		p := f.Modulus()
+		n := f.Limbs()
+		pm1 := new(big.Int).Sub(p, big.NewInt(1))
+		// (p-1)², the largest product, and the products next to multiples
+		// of p
+		for _, v := range []*big.Int{
+			new(big.Int).Mul(pm1, pm1),
+			new(big.Int).Mul(p, pm1),
+			new(big.Int).Sub(new(big.Int).Mul(p, pm1), big.NewInt(1)),
+			new(big.Int).Add(new(big.Int).Mul(p, big.NewInt(2)), big.NewInt(3)),
+			p,
+			new(big.Int),
+		} {
+			tw := make([]uint64, 2*n)
+			toLimbs(tw, v)
+			z := make([]uint64, n)
+			f.ReduceWords(z, tw)
+			if got, want := fromLimbs(z), new(big.Int).Mod(v, p); got.Cmp(want) != 0 {
+				t.Errorf("%s: ReduceWords(%x) = %x, want %x", name, v, got, want)
+			}
+		}
+
+		// z may alias both operands
+		x := make([]uint64, n)
+		toLimbs(x, pm1)
+		f.MulWords(x, x, x)
+		if got := fromLimbs(x); got.Cmp(big.NewInt(1)) != 0 {
+			t.Errorf("%s: MulWords(x, x, x) for x = p-1 = %x, want 1", name, got)
+		}
+
+		// Operands outside [0, p) are reduced
+		y := new(big.Int).Add(p, big.NewInt(5))
+		if got := f.Mul(big.NewInt(-1), y); got.Cmp(new(big.Int).Sub(p, big.NewInt(5))) != 0 {
+			t.Errorf("%s: Mul(-1, p+5) = %v, want p-5", name, got)
+		}
+	}
+}
+
+func TestField_laws(t *testing.T) {
+	t.Parallel()
+
+	impls := []vaulttest.Impl{{Name: "solinas", New: func(N *big.Int) (montgomery.ModMultiplier, error) {
+		return ForModulus(N)
+	}}}
+	impls = append(impls, vaulttest.Backends()...)
+	rng := rand.New(rand.NewPCG(5, 6))
+	for _, name := range Names() {
+		f, _ := Lookup(name)
+		vaulttest.TestField(t, vaulttest.ModField(f))
+		p := f.Modulus()
+		for _, x := range operands(rng, p)[:16] {
+			vaulttest.TestDifferential(t, impls, p, x, new(big.Int).Sub(p, x))
+		}
+	}
+}
+
+func TestLookup_errors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Lookup("curve25519"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Lookup(curve25519): error = %v, want %v", err, ErrUnsupported)
+	}
+	params, _ := montgomery.LookupParams("curve448")
+	if _, err := ForModulus(params.N); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("ForModulus(2^448-2^224-1): error = %v, want %v", err, ErrUnsupported)
+	}
+	if got, want := fmt.Sprint(Names()), "[p192 p224 p256 p384 p521]"; got != want {
+		t.Errorf("Names() = %s, want %s", got, want)
+	}
+	f, _ := Lookup("p256")
+	defer func() {
+		if recover() == nil {
+			t.Error("MulWords with short operands did not panic")
+		}
+	}()
+	f.MulWords(make([]uint64, 4), make([]uint64, 3), make([]uint64, 4))
+}
+
+// BenchmarkMulWords compares the special-form reduction with a Montgomery
+// product of the same size, MulMontWords on operands already in
+// Montgomery form, which is what a curve implementation would chain.
+func BenchmarkMulWords(b *testing.B) {
+	for _, name := range Names() {
+		f, _ := Lookup(name)
+		p := f.Modulus()
+		n := f.Limbs()
+		rng := rand.New(rand.NewPCG(1, 2))
+		xs := operands(rng, p)
+		x, y := make([]uint64, n), make([]uint64, n)
+		toLimbs(x, xs[len(xs)-1])
+		toLimbs(y, xs[len(xs)-2])
+		bits := p.BitLen()
+		b.Run(fmt.Sprintf("bits=%d/impl=solinas", bits), func(b *testing.B) {
+			z := make([]uint64, n)
+			copy(z, x)
+			for b.Loop() {
+				f.MulWords(z, z, y)
+			}
+		})
+		m, err := montgomery.NewMontgomeryCIOSWordsChecked(new(big.Int).Lsh(big.NewInt(1), uint(64*n)), p)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprintf("bits=%d/impl=montgomery", bits), func(b *testing.B) {
+			z := make([]uint64, n)
+			copy(z, x)
+			for b.Loop() {
+				m.MulMontWords(z, z, y)
+			}
+		})
+	}
+}
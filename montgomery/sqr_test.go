@@ -0,0 +1,107 @@
+package montgomery
+
+import (
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestMontgomeryCIOSWords_redcSqr(t *testing.T) {
+	t.Parallel()
+
+	x, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+	m64 := NewMontgomeryCIOSWords(new(big.Int).Lsh(big.NewInt(1), 64), N64)
+	xl, _, Rl, Nl := testParamsLarge(8192)
+	ml := NewMontgomeryCIOSWords(Rl, Nl)
+
+	tests := []struct {
+		name string
+		m    *MontgomeryCIOSWords
+		x    *big.Int
+	}{
+		{"2048-bit", m, x},
+		{"zero", m, big.NewInt(0)},
+		{"one", m, big.NewInt(1)},
+		{"short operand", m, new(big.Int).SetUint64(0xfedcba9876543210)},
+		{"N - 1", m, new(big.Int).Sub(N, big.NewInt(1))},
+		{"all ones below R", m, new(big.Int).Sub(R, big.NewInt(1))},
+		{"operand is RR", m, m.RR},
+		{"negative operand", m, new(big.Int).Neg(x)},
+		{"one word", m64, new(big.Int).Sub(N64, big.NewInt(1))},
+		{"separated", ml, xl},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			xCopy := new(big.Int).Set(tc.x)
+			want := tc.m.redcInterleaved(tc.x, tc.x)
+			if got := tc.m.redcSqr(tc.x); got.Cmp(want) != 0 {
+				t.Errorf("redcSqr() = %v, want %v", got, want)
+			}
+			if tc.x.Cmp(xCopy) != 0 {
+				t.Error("redcSqr modified its operand")
+			}
+			if got := tc.m.redcSqrInto(xCopy, xCopy); got.Cmp(want) != 0 {
+				t.Errorf("redcSqrInto(x, x) = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMontgomeryCIOSWords_Sqr(t *testing.T) {
+	t.Parallel()
+
+	for _, bitSize := range []int{64, 128, 192, 1024, 2048} {
+		_, _, R, N := testParamsLarge(bitSize)
+		m := NewMontgomeryCIOSWords(R, N)
+		err := quick.Check(func(words []uint64) bool {
+			x := new(big.Int).Mod(tobigInt(words), N)
+			want := new(big.Int).Mul(x, x)
+			want.Mod(want, N)
+			if m.Sqr(x).Cmp(want) != 0 {
+				return false
+			}
+			a := m.ToMont(x)
+			return m.SqrMont(a).Equal(m.MulMont(a, a))
+		}, &quick.Config{MaxCount: 50})
+		if err != nil {
+			t.Errorf("%d-bit: %v", bitSize, err)
+		}
+	}
+}
+
+// BenchmarkSqr compares the SOS squaring against Mul(x, x), and the effect
+// on a full exponentiation, where most REDCs are squarings.
+func BenchmarkSqr(b *testing.B) {
+	x, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	a := m.ToMont(x)
+
+	b.Run("SqrMont", func(b *testing.B) {
+		for b.Loop() {
+			m.SqrMont(a)
+		}
+	})
+	b.Run("MulMont", func(b *testing.B) {
+		for b.Loop() {
+			m.MulMont(a, a)
+		}
+	})
+
+	exp := new(big.Int).Sub(N, big.NewInt(1))
+	b.Run("Exp/sqr", func(b *testing.B) {
+		for b.Loop() {
+			m.Exp(x, exp)
+		}
+	})
+	b.Run("Exp/mul", func(b *testing.B) {
+		eng := m.engine()
+		eng.sqr = func(z, x *big.Int) *big.Int { return eng.redcInto(z, x, x) }
+		for b.Loop() {
+			expMont(eng, x, exp)
+		}
+	})
+}
@@ -0,0 +1,238 @@
+package montgomery
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+)
+
+// Squarer computes x^(2^t) mod n by t sequential Montgomery squarings, the
+// work of a Wesolowski or Pietrzak VDF and of a Rivest-Shamir-Wagner
+// time-lock puzzle, for moduli far beyond the sizes the CIOS kernels or
+// big.Int handle well.
+//
+// Every multiplication, including those of the separated REDC and of the
+// Newton iteration for -n⁻¹ mod R, runs on MulNTT, and the transforms of
+// n and -n⁻¹ are computed once. With WithScratchDir, the constants and the
+// scratch live in a memory-mapped file, and operands may be the Limbs of
+// LimbFiles, so nothing of the modulus's size is held on the heap.
+//
+// A Squarer is not safe for concurrent use: Square works in its scratch.
+type Squarer struct {
+	n, np, rr   []uint64 // n, -n⁻¹ mod R and R² mod n, s limbs each
+	acc         []uint64 // the running power, s limbs
+	t, u        []uint64 // products, 2s limbs each
+	nHat, npHat []uint64 // transforms of n and -n⁻¹ mod R
+	work        []uint64 // MulNTT scratch
+	file        *LimbFile
+}
+
+// WithScratchDir makes NewSquarer keep its constants and scratch, 40 to 70
+// times the size of the modulus, in a LimbFile created in dir rather than
+// on the heap. Close removes the file. Other constructors ignore the
+// option.
+func WithScratchDir(dir string) Option {
+	return func(c *config) {
+		c.scratchDir = dir
+	}
+}
+
+// NewSquarer precomputes the constants for the odd modulus n, given as
+// little-endian 64-bit limbs. High zero limbs are ignored, and n is copied.
+// It returns ErrEvenModulus or ErrModulusTooSmall for an unusable n, and
+// the error creating or mapping the scratch file.
+func NewSquarer(n []uint64, opts ...Option) (*Squarer, error) {
+	for len(n) > 0 && n[len(n)-1] == 0 {
+		n = n[:len(n)-1]
+	}
+	switch {
+	case len(n) == 0 || len(n) == 1 && n[0] <= 1:
+		return nil, ErrModulusTooSmall
+	case n[0]&1 == 0:
+		return nil, ErrEvenModulus
+	}
+	cfg := newConfig(opts)
+
+	s := len(n)
+	tn := nttLen(s, s)
+	size := 8*s + 4*tn
+	q := new(Squarer)
+	var buf []uint64
+	if cfg.scratchDir == "" {
+		buf = make([]uint64, size)
+	} else {
+		f, err := tempLimbFile(cfg.scratchDir, size)
+		if err != nil {
+			return nil, err
+		}
+		q.file, buf = f, f.Limbs()
+	}
+	next := func(k int) []uint64 {
+		b := buf[:k:k]
+		buf = buf[k:]
+		return b
+	}
+	q.n, q.np, q.rr, q.acc = next(s), next(s), next(s), next(s)
+	q.t, q.u = next(2*s), next(2*s)
+	q.nHat, q.npHat, q.work = next(tn), next(tn), next(2*tn)
+	copy(q.n, n)
+
+	q.negInverse()
+	nttLoad(q.nHat, q.n)
+	nttLoad(q.npHat, q.np)
+	q.rSquared()
+	return q, nil
+}
+
+// Close releases the scratch file, if any. The Squarer must not be used
+// afterwards.
+func (q *Squarer) Close() error {
+	if q.file == nil {
+		return nil
+	}
+	return q.file.Close()
+}
+
+// Len returns the number of limbs of n, and of the operands of Square.
+func (q *Squarer) Len() int { return len(q.n) }
+
+// Progress is called by SquareContext with the number of squarings done
+// so far.
+type Progress func(done uint64)
+
+// squareCheckLimbs is the operand size, in limbs, squared between two
+// cancellation checks and progress reports: SquareContext checks every
+// squareCheckLimbs/Len squarings, and after every one from that size on.
+const squareCheckLimbs = 1 << 16
+
+// Square sets z = x^(2^t) mod n. z and x have Len limbs and may be the same
+// slice; x must be below n, or Square returns an error wrapping
+// ErrOperandRange and leaves z untouched.
+func (q *Squarer) Square(z, x []uint64, t uint64) error {
+	return q.SquareContext(context.Background(), z, x, t, nil)
+}
+
+// SquareContext is Square with cancellation and progress reporting, for
+// runs of t squarings that take hours. ctx is checked, and progress called,
+// every squareCheckLimbs/Len squarings, and a cancelled run returns
+// ctx.Err() and leaves z untouched. progress may be nil.
+func (q *Squarer) SquareContext(ctx context.Context, z, x []uint64, t uint64, progress Progress) error {
+	s := len(q.n)
+	if len(z) != s || len(x) != s {
+		panic(fmt.Sprintf("montgomery: Squarer.Square: operands have %d and %d limbs, want %d", len(z), len(x), s))
+	}
+	if !limbsLess(x, q.n) {
+		return fmt.Errorf("%w: Square operand is not below n", ErrOperandRange)
+	}
+	interval := uint64(max(squareCheckLimbs/s, 1))
+	MulNTT(q.t, x, q.rr, q.work)
+	q.reduce(q.acc, q.t)
+	for done := uint64(1); done <= t; done++ {
+		MulNTT(q.t, q.acc, q.acc, q.work)
+		q.reduce(q.acc, q.t)
+		if done%interval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if progress != nil {
+				progress(done)
+			}
+		}
+	}
+	clear(q.t[copy(q.t, q.acc):])
+	q.reduce(z, q.t)
+	return nil
+}
+
+// reduce sets z = T·R⁻¹ mod n for T < n·R in 2s limbs, by the separated
+// REDC of reduceSeparated on limbs: m = (T mod R)·(-n⁻¹) mod R, then
+// z = (T + m·n)/R, below 2n before the final subtraction. z must not
+// overlap T, q.u or q.work.
+func (q *Squarer) reduce(z, T []uint64) {
+	s := len(q.n)
+	a := q.work[:len(q.nHat)]
+	nttLoad(a, T[:s])
+	for i := range a {
+		a[i] = nttMul(a[i], q.npHat[i])
+	}
+	nttStore(q.u[:s], a)
+	nttLoad(a, q.u[:s])
+	for i := range a {
+		a[i] = nttMul(a[i], q.nHat[i])
+	}
+	nttStore(q.u, a)
+
+	// The low halves sum to 0 mod R; only their carry is needed
+	var c uint64
+	for i := range s {
+		_, c = bits.Add64(T[i], q.u[i], c)
+	}
+	for i := range s {
+		z[i], c = bits.Add64(T[s+i], q.u[s+i], c)
+	}
+	if c != 0 || !limbsLess(z, q.n) {
+		subLimbs(z, q.n)
+	}
+}
+
+// negInverse sets q.np = -n⁻¹ mod R by Newton's iteration y ← y·(2 + n·y),
+// which doubles the correct limbs of y each step, starting from the one-limb
+// WordInverse.
+func (q *Squarer) negInverse() {
+	s := len(q.n)
+	q.np[0] = WordInverse(q.n[0], 64)
+	for k := 1; k < s; {
+		k2 := min(2*k, s)
+		MulNTT(q.t[:k2+k], q.n[:k2], q.np[:k], q.work)
+		for i, c := 0, uint64(2); c != 0 && i < k2; i++ {
+			q.t[i], c = bits.Add64(q.t[i], c, 0)
+		}
+		MulNTT(q.u[:k2+k], q.t[:k2], q.np[:k], q.work)
+		copy(q.np[:k2], q.u[:k2])
+		k = k2
+	}
+}
+
+// rSquared sets q.rr = R² mod n. It doubles 2^(b-1), n's top bit, up to
+// R mod n, the Montgomery form of 1, then raises the Montgomery form of 2
+// to the power 64s by squaring with reduce and doubling, so no step
+// divides by n.
+func (q *Squarer) rSquared() {
+	s := len(q.n)
+	b := 64*(s-1) + bits.Len64(q.n[s-1])
+	rr := q.rr
+	clear(rr)
+	rr[(b-1)/64] = 1 << ((b - 1) % 64)
+	for range 64*s - b + 2 { // R mod n, then doubled: 2·R mod n
+		q.double(rr)
+	}
+	e := uint64(64 * s)
+	for i := bits.Len64(e) - 2; i >= 0; i-- {
+		MulNTT(q.t, rr, rr, q.work)
+		q.reduce(rr, q.t)
+		if e>>i&1 == 1 {
+			q.double(rr)
+		}
+	}
+}
+
+// double sets x = 2x mod n for x < n.
+func (q *Squarer) double(x []uint64) {
+	var c uint64
+	for i, w := range x {
+		x[i] = w<<1 | c
+		c = w >> 63
+	}
+	if c != 0 || !limbsLess(x, q.n) {
+		subLimbs(x, q.n)
+	}
+}
+
+// subLimbs sets x -= y for little-endian limbs of equal length, discarding
+// the borrow out of the top limb.
+func subLimbs(x, y []uint64) {
+	var b uint64
+	for i := range x {
+		x[i], b = bits.Sub64(x[i], y[i], b)
+	}
+}
@@ -0,0 +1,221 @@
+package montgomery
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"math/rand/v2"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// randomBelow returns a random residue mod n in len(n) limbs.
+func randomBelow(rng *rand.Rand, n []uint64) []uint64 {
+	x := make([]uint64, len(n))
+	for i := range x {
+		x[i] = rng.Uint64()
+	}
+	return limbsPadded(new(big.Int).Mod(tobigInt(x), tobigInt(n)), len(n))
+}
+
+// squarerWant returns x^(2^t) mod n by math/big.
+func squarerWant(x, n []uint64, t uint64) *big.Int {
+	e := new(big.Int).Lsh(big.NewInt(1), uint(t))
+	return new(big.Int).Exp(tobigInt(x), e, tobigInt(n))
+}
+
+func TestSquarer(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(11, 12))
+	odd := func(s int) []uint64 {
+		n := make([]uint64, s)
+		for i := range n {
+			n[i] = rng.Uint64()
+		}
+		n[0] |= 1
+		return n
+	}
+
+	tests := []struct {
+		name string
+		n    []uint64
+		t    uint64
+	}{
+		{"one limb", odd(1), 100},
+		{"small one-limb modulus", []uint64{3}, 5},
+		{"top bit clear", []uint64{0xffffffffffffffc5, 0x1}, 64},
+		{"top bit set", []uint64{1<<64 - 1, 1<<64 - 1, 1<<64 - 1}, 40},
+		{"2048 bits", odd(32), 20},
+		{"odd limb count", odd(37), 10},
+		{"no squarings", odd(5), 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			q, err := NewSquarer(tc.n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer q.Close()
+			rng := rand.New(rand.NewPCG(uint64(len(tc.n)), tc.t))
+			for _, x := range [][]uint64{randomBelow(rng, tc.n), randomBelow(rng, tc.n), make([]uint64, len(tc.n))} {
+				want := squarerWant(x, tc.n, tc.t)
+				z := make([]uint64, q.Len())
+				if err := q.Square(z, x, tc.t); err != nil {
+					t.Fatal(err)
+				}
+				if got := tobigInt(z); got.Cmp(want) != 0 {
+					t.Errorf("Square(%x, %d) = %x, want %x", tobigInt(x), tc.t, got, want)
+				}
+				// In place
+				if err := q.Square(x, x, tc.t); err != nil {
+					t.Fatal(err)
+				}
+				if !slices.Equal(x, z) {
+					t.Errorf("in place: %x, want %x", tobigInt(x), want)
+				}
+			}
+		})
+	}
+}
+
+func TestSquarer_errors(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name string
+		n    []uint64
+		want error
+	}{
+		{"empty", nil, ErrModulusTooSmall},
+		{"one", []uint64{1, 0}, ErrModulusTooSmall},
+		{"even", []uint64{4, 1}, ErrEvenModulus},
+	} {
+		if _, err := NewSquarer(tc.n); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+
+	n := []uint64{0xffffffffffffffc5, 7}
+	q, err := NewSquarer(append(n, 0)) // high zero limbs are dropped
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Len() != 2 {
+		t.Errorf("Len = %d, want 2", q.Len())
+	}
+	z := []uint64{1, 2}
+	if err := q.Square(z, n, 1); !errors.Is(err, ErrOperandRange) {
+		t.Errorf("Square(n): err = %v, want ErrOperandRange", err)
+	}
+	if !slices.Equal(z, []uint64{1, 2}) {
+		t.Errorf("Square(n) wrote z = %x", z)
+	}
+}
+
+func TestSquarer_context(t *testing.T) {
+	t.Parallel()
+
+	n := []uint64{0xffffffffffffffc5}
+	q, err := NewSquarer(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	const interval = squareCheckLimbs
+	x := []uint64{3}
+
+	var reports []uint64
+	z := []uint64{7}
+	err = q.SquareContext(context.Background(), z, x, 2*interval+5, func(done uint64) { reports = append(reports, done) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := squarerWant(x, n, 2*interval+5); tobigInt(z).Cmp(want) != 0 {
+		t.Errorf("SquareContext = %x, want %x", tobigInt(z), want)
+	}
+	if want := []uint64{interval, 2 * interval}; !slices.Equal(reports, want) {
+		t.Errorf("progress reports %v, want %v", reports, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reports = nil
+	z = []uint64{7}
+	err = q.SquareContext(ctx, z, x, 3*interval, func(done uint64) {
+		reports = append(reports, done)
+		cancel()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled: err = %v, want context.Canceled", err)
+	}
+	if !slices.Equal(z, []uint64{7}) {
+		t.Errorf("cancelled: z = %x, want it untouched", z)
+	}
+	if want := []uint64{interval}; !slices.Equal(reports, want) {
+		t.Errorf("cancelled: progress reports %v, want %v", reports, want)
+	}
+}
+
+// TestSquarer_files runs a Squarer whose scratch, operand and result are
+// all memory-mapped files, the out-of-core configuration.
+func TestSquarer_files(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	rng := rand.New(rand.NewPCG(13, 14))
+	const s = 64
+	xf := createLimbFile(t, filepath.Join(dir, "x.limbs"), s)
+	defer xf.Close()
+	n := make([]uint64, s)
+	for i := range n {
+		n[i] = rng.Uint64()
+	}
+	n[0] |= 1
+	x := xf.Limbs()
+	copy(x, randomBelow(rng, n))
+	want := squarerWant(x, n, 50)
+
+	q, err := NewSquarer(n, WithScratchDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.file == nil {
+		t.Fatal("WithScratchDir: scratch is on the heap")
+	}
+	if err := q.Square(x, x, 50); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := tobigInt(x); got.Cmp(want) != 0 {
+		t.Errorf("Square = %x, want %x", got, want)
+	}
+	if entries, _ := filepath.Glob(filepath.Join(dir, "montgomery-*")); len(entries) != 0 {
+		t.Errorf("scratch left behind: %v", entries)
+	}
+}
+
+func BenchmarkSquarer(b *testing.B) {
+	rng := rand.New(rand.NewPCG(15, 16))
+	n := make([]uint64, 1<<12)
+	for i := range n {
+		n[i] = rng.Uint64()
+	}
+	n[0] |= 1
+	q, err := NewSquarer(n)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer q.Close()
+	x := make([]uint64, len(n))
+	x[0] = 3
+	for b.Loop() {
+		if err := q.Square(x, x, 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package montgomery
+
+import (
+	"math/big"
+	"slices"
+	"sync"
+)
+
+// WithTableCache makes Exp keep the window tables of the n most recently
+// used bases on the context, so that repeated exponentiations of the same
+// base skip the precomputation: the middle ground between plain Exp, which
+// rebuilds its table every call, and an explicit FixedBase or OddPowers.
+//
+// Cached bases use a sliding window over odd powers whatever the
+// WindowStrategy. Since the table is built once, its window is a bit wider
+// than Exp would pick for the exponent of the call that built it, unless
+// WithWindowSize sets it. Sparse exponents still take the
+// square-and-multiply path and never touch the cache. Each table holds
+// 2^(w-1) elements; the memory budget bounds one table, n how many are
+// kept. InvalidateTable and InvalidateTables drop entries, e.g. when a key
+// is rotated and its tables should not outlive it. n ≤ 0 disables the
+// cache, which is the default.
+func WithTableCache(n int) Option {
+	return func(c *config) {
+		c.tableCache = max(n, 0)
+	}
+}
+
+// tableCache holds OddPowers tables by base, evicting the least recently
+// used. It is shared by every call on one context and safe for concurrent
+// use; the tables themselves are immutable.
+type tableCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*OddPowers
+	lru     []string // keys, least recently used first
+}
+
+func newTableCache(size int) *tableCache {
+	if size <= 0 {
+		return nil
+	}
+	return &tableCache{size: size, entries: make(map[string]*OddPowers)}
+}
+
+// exp returns base^e mod N for base in [0, N) and e ≥ 0 from the cached
+// table of base, building and caching one first if needed.
+func (c *tableCache) exp(eng engine, base, e *big.Int) *big.Int {
+	key := string(base.Bytes())
+	c.mu.Lock()
+	p, ok := c.entries[key]
+	if ok {
+		c.touch(key)
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		// Build outside the lock; a concurrent miss on the same base
+		// builds an identical table, and the later one wins.
+		w := slidingWindow(eng, e)
+		if eng.window == 0 && (eng.maxEntries == 0 || 1<<w <= eng.maxEntries) {
+			// The table is paid for once, so it can afford another bit
+			w = min(w+1, maxWindow)
+		}
+		g := new(big.Int).Set(base)
+		p = &OddPowers{eng: eng, g: g, table: oddPowers(eng, eng.redc(g, eng.rr), w), w: w}
+
+		c.mu.Lock()
+		if _, ok := c.entries[key]; !ok && len(c.lru) == c.size {
+			delete(c.entries, c.lru[0])
+			c.lru = c.lru[1:]
+		}
+		c.entries[key] = p
+		c.touch(key)
+		c.mu.Unlock()
+	}
+	return p.Exp(e)
+}
+
+// touch moves key to the most recently used end of the LRU order. c.mu
+// must be held.
+func (c *tableCache) touch(key string) {
+	if i := slices.Index(c.lru, key); i >= 0 {
+		c.lru = slices.Delete(c.lru, i, i+1)
+	}
+	c.lru = append(c.lru, key)
+}
+
+// invalidate drops the table of base, or every table if base is nil. A nil
+// cache has nothing to drop.
+func (c *tableCache) invalidate(base *big.Int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if base == nil {
+		clear(c.entries)
+		c.lru = c.lru[:0]
+		return
+	}
+	key := string(base.Bytes())
+	if _, ok := c.entries[key]; ok {
+		delete(c.entries, key)
+		c.lru = slices.DeleteFunc(c.lru, func(k string) bool { return k == key })
+	}
+}
+
+// len returns the number of cached tables.
+func (c *tableCache) len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// InvalidateTable drops the cached window table of base, taken mod N, if
+// WithTableCache is enabled and it has one.
+func (m *MontgomeryBitwise) InvalidateTable(base *big.Int) {
+	m.cfg.tables.invalidate(new(big.Int).Mod(base, m.N))
+}
+
+// InvalidateTables drops every cached window table.
+func (m *MontgomeryBitwise) InvalidateTables() { m.cfg.tables.invalidate(nil) }
+
+// InvalidateTable drops the cached window table of base, taken mod N, if
+// WithTableCache is enabled and it has one.
+func (m *MontgomeryCIOS) InvalidateTable(base *big.Int) {
+	m.cfg.tables.invalidate(new(big.Int).Mod(base, m.N))
+}
+
+// InvalidateTables drops every cached window table.
+func (m *MontgomeryCIOS) InvalidateTables() { m.cfg.tables.invalidate(nil) }
+
+// InvalidateTable drops the cached window table of base, taken mod N, if
+// WithTableCache is enabled and it has one.
+func (m *MontgomeryCIOSWords) InvalidateTable(base *big.Int) {
+	m.cfg.tables.invalidate(new(big.Int).Mod(base, m.N))
+}
+
+// InvalidateTables drops every cached window table.
+func (m *MontgomeryCIOSWords) InvalidateTables() { m.cfg.tables.invalidate(nil) }
@@ -0,0 +1,157 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func TestWithTableCache(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	exps := []*big.Int{
+		new(big.Int).Rsh(y, 1792), new(big.Int).Sub(N, big.NewInt(2)), big.NewInt(65537),
+		big.NewInt(0), big.NewInt(-9), new(big.Int).Rsh(x, 1900),
+	}
+	configs := []struct {
+		name string
+		opts []Option
+	}{
+		{"auto", []Option{WithTableCache(2)}},
+		{"w=3", []Option{WithTableCache(2), WithWindowSize(3)}},
+		{"budget", []Option{WithTableCache(2), WithMemoryBudget(4 * 256)}},
+	}
+
+	for _, cfg := range configs {
+		for _, impl := range montContexts(R, N, cfg.opts...) {
+			t.Run(impl.name+"/"+cfg.name, func(t *testing.T) {
+				t.Parallel()
+				m := impl.m
+				// The same bases again and again, also unreduced
+				for _, base := range []*big.Int{x, y, new(big.Int).Add(x, N), x, big.NewInt(3), y} {
+					for _, e := range exps {
+						if impl.name == "Bitwise" && e.BitLen() > 300 {
+							continue
+						}
+						want := new(big.Int).Exp(base, e, N)
+						if got := m.Exp(base, e); got.Cmp(want) != 0 {
+							t.Fatalf("Exp(%v, %v) = %v, want %v", base, e, got, want)
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestWithTableCache_eviction(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	e := new(big.Int).Rsh(y, 1792)
+	z := new(big.Int).Add(x, y)
+	m := NewMontgomeryCIOSWords(R, N, WithTableCache(2))
+	cached := func(b *big.Int) bool {
+		_, ok := m.cfg.tables.entries[string(b.Bytes())]
+		return ok
+	}
+
+	m.Exp(x, e)
+	m.Exp(y, e)
+	m.Exp(x, e) // x is now the most recently used
+	m.Exp(z, e) // evicts y
+	if !cached(x) || cached(y) || !cached(z) || m.cfg.tables.len() != 2 {
+		t.Errorf("after x, y, x, z: x %t, y %t, z %t, want true, false, true", cached(x), cached(y), cached(z))
+	}
+
+	// Sparse exponents bypass the cache
+	m.Exp(y, big.NewInt(65537))
+	if cached(y) {
+		t.Error("Exp with a sparse exponent cached a table")
+	}
+
+	m.InvalidateTable(new(big.Int).Add(x, N))
+	if cached(x) || !cached(z) {
+		t.Errorf("after InvalidateTable(x+N): x %t, z %t, want false, true", cached(x), cached(z))
+	}
+	m.InvalidateTables()
+	if n := m.cfg.tables.len(); n != 0 {
+		t.Errorf("after InvalidateTables: %d tables, want 0", n)
+	}
+	if got, want := m.Exp(z, e), new(big.Int).Exp(z, e, N); got.Cmp(want) != 0 {
+		t.Errorf("Exp after invalidation = %v, want %v", got, want)
+	}
+}
+
+func TestWithTableCache_disabled(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	for _, n := range []int{0, -1} {
+		m := NewMontgomeryCIOSWords(R, N, WithTableCache(n))
+		m.Exp(x, y)
+		m.InvalidateTable(x)
+		m.InvalidateTables()
+		if m.cfg.tables != nil {
+			t.Errorf("WithTableCache(%d) created a cache", n)
+		}
+	}
+}
+
+func TestWithTableCache_sharedOptions(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	opts := []Option{WithTableCache(4)}
+	a := NewMontgomeryCIOSWords(R, N, opts...)
+	b := NewMontgomeryCIOS(R, N, opts...)
+	a.Exp(x, y)
+	if n := b.cfg.tables.len(); n != 0 {
+		t.Errorf("contexts built from the same options share a cache of %d tables", n)
+	}
+}
+
+func TestWithTableCache_concurrent(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N, WithTableCache(2))
+	bases := []*big.Int{x, y, new(big.Int).Add(x, y)}
+	e := new(big.Int).Rsh(N, 1792)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			for j := range 6 {
+				base := bases[(i+j)%len(bases)]
+				if got, want := m.Exp(base, e), new(big.Int).Exp(base, e, N); got.Cmp(want) != 0 {
+					t.Errorf("Exp(%v, e) = %v, want %v", base, got, want)
+				}
+				if j == 3 {
+					m.InvalidateTable(base)
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// BenchmarkTableCache measures repeated Exp of one base with and without
+// WithTableCache.
+func BenchmarkTableCache(b *testing.B) {
+	x, y, R, N := testParams2048()
+	for _, bits := range []int{256, 2048} {
+		e := new(big.Int).Rsh(y, uint(y.BitLen()-bits))
+		e.SetBit(e, bits-1, 1)
+		for _, cache := range []int{0, 4} {
+			m := NewMontgomeryCIOSWords(R, N, WithTableCache(cache))
+			b.Run(fmt.Sprintf("bits=%d/cache=%d", bits, cache), func(b *testing.B) {
+				for b.Loop() {
+					m.Exp(x, e)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"text/tabwriter"
+)
+
+// Op is the kind of a recorded exponentiation step.
+type Op uint8
+
+const (
+	OpSquare     Op = iota // Montgomery squaring
+	OpMultiply             // Montgomery multiplication of two distinct operands
+	OpTableStore           // write of one precomputed power into the table
+	OpTableLoad            // read of one precomputed power from the table
+	OpSwap                 // masked conditional swap of two registers
+)
+
+func (op Op) String() string {
+	switch op {
+	case OpSquare:
+		return "square"
+	case OpMultiply:
+		return "multiply"
+	case OpTableStore:
+		return "store"
+	case OpTableLoad:
+		return "load"
+	case OpSwap:
+		return "swap"
+	}
+	return fmt.Sprintf("Op(%d)", uint8(op))
+}
+
+// Event is one step of an exponentiation together with the number of
+// 64-bit words it reads and writes.
+type Event struct {
+	Op      Op
+	Read    int
+	Written int
+}
+
+// Strategy selects the exponentiation schedule recorded by Trace.
+type Strategy uint8
+
+const (
+	// StrategyBinary is left-to-right square-and-multiply, multiplying only
+	// for set exponent bits.
+	StrategyBinary Strategy = iota
+	// StrategyLadder is the Montgomery ladder: one multiplication and one
+	// squaring per bit, with the operands picked by masked swaps.
+	StrategyLadder
+	// StrategyWindow is the fixed-window schedule of ExpConstantTime.
+	StrategyWindow
+)
+
+func (s Strategy) String() string {
+	switch s {
+	case StrategyBinary:
+		return "binary"
+	case StrategyLadder:
+		return "ladder"
+	case StrategyWindow:
+		return "window"
+	}
+	return fmt.Sprintf("Strategy(%d)", uint8(s))
+}
+
+// ParseStrategy returns the Strategy whose String is name.
+func ParseStrategy(name string) (Strategy, error) {
+	for _, s := range []Strategy{StrategyBinary, StrategyLadder, StrategyWindow} {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("montgomery: unknown strategy %q", name)
+}
+
+// Trace is the exact operation sequence of one exponentiation.
+type Trace struct {
+	Strategy Strategy
+	Events   []Event
+	Result   *big.Int
+}
+
+// Trace computes base^exp mod N with strategy s and records every
+// operation it performs.
+//
+// The recorded sequence is the one the arithmetic actually executes, so two
+// traces of the same strategy for different exponents are a structural check
+// of a constant-time claim: an exponent-independent schedule produces
+// identical event sequences (see Compare). Result is returned so the trace
+// can be checked against big.Int.Exp.
+func (m *MontgomeryCIOSWords) Trace(s Strategy, base, exp *big.Int) Trace {
+	rec := &recorder{}
+	var result *big.Int
+	switch s {
+	case StrategyBinary:
+		result = m.expBinary(base, exp, rec)
+	case StrategyLadder:
+		result = m.expLadder(base, exp, rec)
+	case StrategyWindow:
+		result = m.expConstantTime(base, exp, rec)
+	default:
+		panic(fmt.Sprintf("montgomery: unknown strategy %v", s))
+	}
+	return Trace{Strategy: s, Events: rec.events, Result: result}
+}
+
+// Stats summarises a trace.
+type Stats struct {
+	Squarings       int
+	Multiplications int
+	TableStores     int
+	TableLoads      int
+	Swaps           int
+	WordsRead       int
+	WordsWritten    int
+}
+
+// Stats counts the operations and memory traffic of t.
+func (t Trace) Stats() Stats {
+	var st Stats
+	for _, e := range t.Events {
+		switch e.Op {
+		case OpSquare:
+			st.Squarings++
+		case OpMultiply:
+			st.Multiplications++
+		case OpTableStore:
+			st.TableStores++
+		case OpTableLoad:
+			st.TableLoads++
+		case OpSwap:
+			st.Swaps++
+		}
+		st.WordsRead += e.Read
+		st.WordsWritten += e.Written
+	}
+	return st
+}
+
+// Report is the comparison of two traces produced by Compare.
+type Report struct {
+	A, B Trace
+	// Divergence is the index of the first event at which the two
+	// sequences differ, or -1 if they are identical.
+	Divergence int
+}
+
+// Compare diffs two traces event by event.
+func Compare(a, b Trace) Report {
+	r := Report{A: a, B: b, Divergence: -1}
+	for i := range max(len(a.Events), len(b.Events)) {
+		if i >= len(a.Events) || i >= len(b.Events) || a.Events[i] != b.Events[i] {
+			r.Divergence = i
+			break
+		}
+	}
+	return r
+}
+
+// String renders the report as an aligned table of operation counts and
+// memory traffic followed by the divergence point.
+func (r Report) String() string {
+	var sb strings.Builder
+	stA, stB := r.A.Stats(), r.B.Stats()
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "\t%v\t%v\t\n", r.A.Strategy, r.B.Strategy)
+	rows := []struct {
+		name string
+		a, b int
+	}{
+		{"events", len(r.A.Events), len(r.B.Events)},
+		{"squarings", stA.Squarings, stB.Squarings},
+		{"multiplications", stA.Multiplications, stB.Multiplications},
+		{"table stores", stA.TableStores, stB.TableStores},
+		{"table loads", stA.TableLoads, stB.TableLoads},
+		{"swaps", stA.Swaps, stB.Swaps},
+		{"words read", stA.WordsRead, stB.WordsRead},
+		{"words written", stA.WordsWritten, stB.WordsWritten},
+	}
+	for _, row := range rows {
+		fmt.Fprintf(w, "%s\t%d\t%d\t\n", row.name, row.a, row.b)
+	}
+	w.Flush()
+	if r.Divergence < 0 {
+		sb.WriteString("operation sequences are identical\n")
+	} else {
+		fmt.Fprintf(&sb, "operation sequences diverge at event %d\n", r.Divergence)
+	}
+	return sb.String()
+}
+
+// recorder collects events. All methods are no-ops on a nil recorder, so
+// the untraced paths pay only a nil check.
+type recorder struct {
+	events []Event
+}
+
+// mul records a Montgomery multiplication or squaring of s-word operands:
+// x, y and N are read and the product written.
+func (r *recorder) mul(op Op, s int) {
+	if r != nil {
+		r.events = append(r.events, Event{Op: op, Read: 3 * s, Written: s})
+	}
+}
+
+// store records the scatter of one s-word entry into a table.
+func (r *recorder) store(s int) {
+	if r != nil {
+		r.events = append(r.events, Event{Op: OpTableStore, Read: s, Written: s})
+	}
+}
+
+// load records the gather of one s-word entry from a table of size
+// entries; a constant-time gather reads every entry.
+func (r *recorder) load(size, s int) {
+	if r != nil {
+		r.events = append(r.events, Event{Op: OpTableLoad, Read: size * s, Written: s})
+	}
+}
+
+// swap records a masked swap of two s-word registers.
+func (r *recorder) swap(s int) {
+	if r != nil {
+		r.events = append(r.events, Event{Op: OpSwap, Read: 2 * s, Written: 2 * s})
+	}
+}
+
+// expBinary is left-to-right square-and-multiply on limbs. It multiplies
+// only for set bits, so its schedule follows the exponent.
+func (m *MontgomeryCIOSWords) expBinary(base, exp *big.Int, rec *recorder) *big.Int {
+	s := m.S
+	if base.Sign() < 0 || base.Cmp(m.N) >= 0 {
+		base = new(big.Int).Mod(base, m.N)
+	}
+	t := make([]uint64, s+2)
+	n := limbsPadded(m.N, s)
+	rr := limbsPadded(m.RR, s)
+	one := limbsPadded(big.NewInt(1), s)
+
+	acc := make([]uint64, s)
+	montMulWords(acc, one, rr, n, m.NI, t)
+	rec.mul(OpMultiply, s)
+	baseMont := make([]uint64, s)
+	montMulWords(baseMont, limbsPadded(base, s), rr, n, m.NI, t)
+	rec.mul(OpMultiply, s)
+
+	for i := exp.BitLen() - 1; i >= 0; i-- {
+		montMulWords(acc, acc, acc, n, m.NI, t)
+		rec.mul(OpSquare, s)
+		auditBranch("expBinary: exponent bit", exp)
+		if exp.Bit(i) == 1 {
+			montMulWords(acc, acc, baseMont, n, m.NI, t)
+			rec.mul(OpMultiply, s)
+		}
+	}
+
+	montMulWords(acc, acc, one, n, m.NI, t)
+	rec.mul(OpMultiply, s)
+	return tobigInt(acc)
+}
+
+// ctSwap exchanges a and b when mask is all ones and leaves them unchanged
+// when it is zero, touching both in either case. a and b may be the same
+// slice (a no-op) but must not partially overlap.
+func ctSwap(a, b []uint64, mask uint64) {
+	checkInPlace("ctSwap", a, b)
+	for j := range a {
+		d := (a[j] ^ b[j]) & mask
+		a[j] ^= d
+		b[j] ^= d
+	}
+}
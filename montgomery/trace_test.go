@@ -0,0 +1,182 @@
+package montgomery
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestMontgomeryCIOSWords_Trace(t *testing.T) {
+	t.Parallel()
+
+	x, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+	m64 := NewMontgomeryCIOSWords(new(big.Int).Lsh(big.NewInt(1), 64), N64)
+
+	tests := []struct {
+		name string
+		m    *MontgomeryCIOSWords
+		base *big.Int
+		exp  *big.Int
+	}{
+		{"exp=0", m64, big.NewInt(12345), big.NewInt(0)},
+		{"exp=1", m64, big.NewInt(12345), big.NewInt(1)},
+		{"base >= N", m64, new(big.Int).Add(N64, big.NewInt(3)), big.NewInt(65537)},
+		{"exponent wider than R", m64, big.NewInt(3), new(big.Int).Lsh(big.NewInt(1), 100)},
+		{"2048-bit", m, x, new(big.Int).Sub(N, big.NewInt(1))},
+	}
+
+	for _, tc := range tests {
+		for _, s := range []Strategy{StrategyBinary, StrategyLadder, StrategyWindow} {
+			t.Run(tc.name+"/"+s.String(), func(t *testing.T) {
+				t.Parallel()
+				want := new(big.Int).Exp(tc.base, tc.exp, tc.m.N)
+				tr := tc.m.Trace(s, tc.base, tc.exp)
+				if tr.Result.Cmp(want) != 0 {
+					t.Errorf("Result = %v, want %v", tr.Result, want)
+				}
+				if tr.Strategy != s || len(tr.Events) == 0 {
+					t.Errorf("Trace() = strategy %v with %d events", tr.Strategy, len(tr.Events))
+				}
+			})
+		}
+	}
+}
+
+func TestMontgomeryCIOSWords_TraceShape(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	base := big.NewInt(7)
+	// Same bit length, very different Hamming weights
+	sparse := new(big.Int).Lsh(big.NewInt(1), 2047)
+	dense := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 2048), big.NewInt(1))
+
+	tests := []struct {
+		s         Strategy
+		identical bool
+	}{
+		{StrategyBinary, false},
+		{StrategyLadder, true},
+		{StrategyWindow, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.s.String(), func(t *testing.T) {
+			t.Parallel()
+			r := Compare(m.Trace(tc.s, base, sparse), m.Trace(tc.s, base, dense))
+			if got := r.Divergence < 0; got != tc.identical {
+				t.Errorf("identical schedules = %v (divergence %d), want %v", got, r.Divergence, tc.identical)
+			}
+		})
+	}
+}
+
+func TestTrace_Stats(t *testing.T) {
+	t.Parallel()
+
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+	m := NewMontgomeryCIOSWords(new(big.Int).Lsh(big.NewInt(1), 64), N64)
+
+	tests := []struct {
+		s    Strategy
+		exp  *big.Int
+		want Stats
+	}{
+		// 2 conversions in, 4 squarings, 2 multiplies for the set bits, 1 conversion out
+		{StrategyBinary, big.NewInt(0b1001), Stats{
+			Squarings: 4, Multiplications: 5, WordsRead: 27, WordsWritten: 9,
+		}},
+		// 64 bits, each with a multiply, a square and two swaps
+		{StrategyLadder, big.NewInt(0b1001), Stats{
+			Squarings: 64, Multiplications: 67, Swaps: 128,
+			WordsRead: 3*131 + 2*128, WordsWritten: 131 + 2*128,
+		}},
+		// 13 windows of 5 bits, 32 table entries, one load per window plus the initial one
+		{StrategyWindow, big.NewInt(0b1001), Stats{
+			Squarings: 65, Multiplications: 32 + 13 + 1, TableStores: 32, TableLoads: 14,
+			WordsRead: 3*(65+46) + 32 + 14*32, WordsWritten: 65 + 46 + 32 + 14,
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.s.String(), func(t *testing.T) {
+			t.Parallel()
+			if got := m.Trace(tc.s, big.NewInt(3), tc.exp).Stats(); got != tc.want {
+				t.Errorf("Stats() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	sq := Event{Op: OpSquare, Read: 3, Written: 1}
+	mul := Event{Op: OpMultiply, Read: 3, Written: 1}
+	tests := []struct {
+		name string
+		a, b []Event
+		want int
+	}{
+		{"identical", []Event{sq, mul}, []Event{sq, mul}, -1},
+		{"both empty", nil, nil, -1},
+		{"differ", []Event{sq, sq}, []Event{sq, mul}, 1},
+		{"prefix", []Event{sq}, []Event{sq, mul}, 1},
+		{"longer first", []Event{sq, mul, sq}, []Event{sq, mul}, 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			r := Compare(Trace{Events: tc.a}, Trace{Events: tc.b})
+			if r.Divergence != tc.want {
+				t.Errorf("Divergence = %d, want %d", r.Divergence, tc.want)
+			}
+		})
+	}
+}
+
+func TestReport_String(t *testing.T) {
+	t.Parallel()
+
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+	m := NewMontgomeryCIOSWords(new(big.Int).Lsh(big.NewInt(1), 64), N64)
+	e := big.NewInt(65537)
+
+	same := Compare(m.Trace(StrategyWindow, big.NewInt(2), e), m.Trace(StrategyWindow, big.NewInt(5), e)).String()
+	diff := Compare(m.Trace(StrategyLadder, big.NewInt(2), e), m.Trace(StrategyWindow, big.NewInt(2), e)).String()
+	for _, want := range []string{"window", "squarings", "words read", "are identical"} {
+		if !strings.Contains(same, want) {
+			t.Errorf("report missing %q:\n%s", want, same)
+		}
+	}
+	if !strings.Contains(diff, "ladder") || !strings.Contains(diff, "diverge at event 1") {
+		t.Errorf("unexpected report:\n%s", diff)
+	}
+}
+
+func TestParseStrategy(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []Strategy{StrategyBinary, StrategyLadder, StrategyWindow} {
+		if got, err := ParseStrategy(s.String()); err != nil || got != s {
+			t.Errorf("ParseStrategy(%q) = %v, %v", s, got, err)
+		}
+	}
+	if _, err := ParseStrategy("sliding"); err == nil {
+		t.Error("ParseStrategy(\"sliding\") succeeded")
+	}
+}
+
+func BenchmarkTrace(b *testing.B) {
+	x, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+	e := new(big.Int).Sub(N, big.NewInt(1))
+	for _, s := range []Strategy{StrategyBinary, StrategyLadder, StrategyWindow} {
+		b.Run(s.String(), func(b *testing.B) {
+			for b.Loop() {
+				m.Trace(s, x, e)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package montgomery
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	// ErrInvalidParameters is returned by Open and the Checked constructors
+	// when R and N cannot form a Montgomery context. The errors below wrap
+	// it with the specific reason, so errors.Is matches either.
+	ErrInvalidParameters = errors.New("montgomery: N must be odd and R a power of two greater than N")
+
+	// ErrEvenModulus is returned for an even N, which has no inverse mod R.
+	ErrEvenModulus = fmt.Errorf("%w: N is even", ErrInvalidParameters)
+	// ErrModulusTooSmall is returned for N ≤ 1.
+	ErrModulusTooSmall = fmt.Errorf("%w: N must be greater than 1", ErrInvalidParameters)
+	// ErrRNotPowerOfTwo is returned when R is not 2^k for some k ≥ 1.
+	ErrRNotPowerOfTwo = fmt.Errorf("%w: R is not a power of two", ErrInvalidParameters)
+	// ErrRTooSmall is returned for R ≤ N.
+	ErrRTooSmall = fmt.Errorf("%w: R must be greater than N", ErrInvalidParameters)
+	// ErrRNotWordAligned is returned by the word-based constructors when R
+	// is not 2^(64·s): their REDC divides by whole words, so any other R
+	// silently yields wrong products.
+	ErrRNotWordAligned = fmt.Errorf("%w: R must be 2^(64·s) for the word-based implementations", ErrInvalidParameters)
+	// ErrAMMBound is returned by the word-based Checked constructors with
+	// WithAlmostMontgomery when R < 4N.
+	ErrAMMBound = fmt.Errorf("%w: almost Montgomery multiplication needs R ≥ 4N", ErrInvalidParameters)
+)
+
+// validateParams checks R and N for any implementation and, with
+// wordAligned, that R is a whole number of 64-bit words.
+func validateParams(R, N *big.Int, wordAligned bool) error {
+	switch {
+	case N == nil || N.Cmp(big.NewInt(1)) <= 0:
+		return ErrModulusTooSmall
+	case N.Bit(0) == 0:
+		return ErrEvenModulus
+	case R == nil || R.Sign() <= 0 || R.BitLen() < 2 || R.BitLen()-1 != int(R.TrailingZeroBits()):
+		return ErrRNotPowerOfTwo
+	case R.Cmp(N) <= 0:
+		return ErrRTooSmall
+	case wordAligned && (R.BitLen()-1)%64 != 0:
+		return ErrRNotWordAligned
+	}
+	return nil
+}
+
+// NewMontgomeryBitwiseChecked is NewMontgomeryBitwise that first rejects
+// invalid R and N with an error wrapping ErrInvalidParameters.
+func NewMontgomeryBitwiseChecked(R, N *big.Int, opts ...Option) (*MontgomeryBitwise, error) {
+	if err := validateParams(R, N, false); err != nil {
+		return nil, err
+	}
+	opts, cfg := withoutSelfTest(opts)
+	m := NewMontgomeryBitwise(R, N, opts...)
+	if err := selfTest(m, cfg); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// NewMontgomeryCIOSChecked is NewMontgomeryCIOS that first rejects invalid
+// R and N, including R that is not a whole number of 64-bit words, with an
+// error wrapping ErrInvalidParameters.
+func NewMontgomeryCIOSChecked(R, N *big.Int, opts ...Option) (*MontgomeryCIOS, error) {
+	if err := validateParams(R, N, true); err != nil {
+		return nil, err
+	}
+	opts, cfg := withoutSelfTest(opts)
+	m := NewMontgomeryCIOS(R, N, opts...)
+	if err := selfTest(m, cfg); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// NewMontgomeryCIOSWordsChecked is NewMontgomeryCIOSWords that first
+// rejects invalid R and N, including R that is not a whole number of 64-bit
+// words, with an error wrapping ErrInvalidParameters.
+func NewMontgomeryCIOSWordsChecked(R, N *big.Int, opts ...Option) (*MontgomeryCIOSWords, error) {
+	if err := validateParams(R, N, true); err != nil {
+		return nil, err
+	}
+	if newConfig(opts).amm && !ammBound(R, N) {
+		return nil, ErrAMMBound
+	}
+	opts, cfg := withoutSelfTest(opts)
+	m := NewMontgomeryCIOSWords(R, N, opts...)
+	if err := selfTest(m, cfg); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ammBound reports whether 4N ≤ R, the condition for almost Montgomery
+// multiplication to keep its results below 2N.
+func ammBound(R, N *big.Int) bool {
+	return new(big.Int).Lsh(N, 2).Cmp(R) <= 0
+}
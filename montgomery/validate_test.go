@@ -0,0 +1,94 @@
+package montgomery
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestChecked(t *testing.T) {
+	t.Parallel()
+
+	pow2 := func(k uint) *big.Int { return new(big.Int).Lsh(big.NewInt(1), k) }
+	N := new(big.Int).Sub(pow2(127), big.NewInt(1)) // Mersenne prime
+	R := pow2(128)
+
+	tests := []struct {
+		name         string
+		R, N         *big.Int
+		wantErr      error // from every constructor
+		wantWordsErr error // from the word-based constructors, if different
+	}{
+		{name: "valid", R: R, N: N},
+		{name: "even N", R: R, N: new(big.Int).Add(N, big.NewInt(1)), wantErr: ErrEvenModulus},
+		{name: "N = 1", R: R, N: big.NewInt(1), wantErr: ErrModulusTooSmall},
+		{name: "N = 0", R: R, N: big.NewInt(0), wantErr: ErrModulusTooSmall},
+		{name: "negative N", R: R, N: big.NewInt(-7), wantErr: ErrModulusTooSmall},
+		{name: "nil N", R: R, N: nil, wantErr: ErrModulusTooSmall},
+		{name: "R not a power of two", R: new(big.Int).Add(R, big.NewInt(2)), N: N, wantErr: ErrRNotPowerOfTwo},
+		{name: "R = 1", R: big.NewInt(1), N: big.NewInt(3), wantErr: ErrRNotPowerOfTwo},
+		{name: "negative R", R: new(big.Int).Neg(R), N: N, wantErr: ErrRNotPowerOfTwo},
+		{name: "R = N + 1", R: pow2(127), N: N, wantErr: nil, wantWordsErr: ErrRNotWordAligned},
+		{name: "R < N", R: pow2(64), N: N, wantErr: ErrRTooSmall},
+		{name: "R not word aligned", R: pow2(130), N: N, wantErr: nil, wantWordsErr: ErrRNotWordAligned},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			wordsErr := tc.wantErr
+			if tc.wantWordsErr != nil {
+				wordsErr = tc.wantWordsErr
+			}
+
+			constructors := []struct {
+				name    string
+				new     func() (ModMultiplier, error)
+				wantErr error
+			}{
+				{"Bitwise", func() (ModMultiplier, error) { return NewMontgomeryBitwiseChecked(tc.R, tc.N) }, tc.wantErr},
+				{"CIOS", func() (ModMultiplier, error) { return NewMontgomeryCIOSChecked(tc.R, tc.N) }, wordsErr},
+				{"CIOSWords", func() (ModMultiplier, error) { return NewMontgomeryCIOSWordsChecked(tc.R, tc.N) }, wordsErr},
+			}
+			for _, c := range constructors {
+				m, err := c.new()
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("%s: error = %v, want %v", c.name, err, c.wantErr)
+				}
+				if err != nil {
+					if !errors.Is(err, ErrInvalidParameters) {
+						t.Errorf("%s: error %v does not wrap ErrInvalidParameters", c.name, err)
+					}
+					continue
+				}
+				x, y := big.NewInt(123456789), new(big.Int).Sub(tc.N, big.NewInt(5))
+				want := new(big.Int).Mul(x, y)
+				want.Mod(want, tc.N)
+				if got := m.Mul(x, y); got.Cmp(want) != 0 {
+					t.Errorf("%s: Mul() = %v, want %v", c.name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestChecked_almostMontgomery(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048() // N has 2048 bits, so R < 4N
+	_, _, _, NAMM := testParamsAMM()
+
+	if _, err := NewMontgomeryCIOSWordsChecked(R, N, WithAlmostMontgomery()); !errors.Is(err, ErrAMMBound) {
+		t.Errorf("CIOSWords with R < 4N: error = %v, want ErrAMMBound", err)
+	}
+	if _, err := NewMontgomeryCTChecked(R, N, WithAlmostMontgomery()); !errors.Is(err, ErrAMMBound) {
+		t.Errorf("CT with R < 4N: error = %v, want ErrAMMBound", err)
+	}
+	if !errors.Is(ErrAMMBound, ErrInvalidParameters) {
+		t.Error("ErrAMMBound does not wrap ErrInvalidParameters")
+	}
+	m, err := NewMontgomeryCIOSWordsChecked(R, NAMM, WithAlmostMontgomery())
+	if err != nil || !m.amm {
+		t.Errorf("CIOSWords with 4N ≤ R: %v, amm = %v", err, err == nil && m.amm)
+	}
+}
@@ -0,0 +1,72 @@
+package vaulttest
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/blck-snwmn/arithmetic-vault/montgomery"
+)
+
+// Impl is one implementation under differential test: a name for reports
+// and a constructor for a modulus N.
+type Impl struct {
+	Name string
+	New  func(N *big.Int) (montgomery.ModMultiplier, error)
+}
+
+// exponentiator is implemented by the contexts with an Exp of their own.
+type exponentiator interface {
+	Exp(base, exp *big.Int) *big.Int
+}
+
+// Backends returns an Impl for every backend registered with
+// montgomery.Register at the time of the call, so built-in, assembly and
+// out-of-tree backends are all compared. Each opens N with
+// montgomery.Open and the smallest R = 2^(64·s) above N.
+func Backends() []Impl {
+	var impls []Impl
+	for _, name := range montgomery.Backends() {
+		impls = append(impls, Impl{Name: name, New: func(N *big.Int) (montgomery.ModMultiplier, error) {
+			R := new(big.Int).Lsh(big.NewInt(1), uint(64*((N.BitLen()+63)/64)))
+			return montgomery.Open(R, N, montgomery.WithBackend(name))
+		}})
+	}
+	return impls
+}
+
+// TestDifferential feeds the same operands to every impl, built for the
+// modulus N ≥ 2, and reports through t each one whose Mul(x, y), or
+// Exp(x, y) where it has an Exp, differs from math/big, as well as each
+// that cannot be built for N. x and y are reduced mod N first.
+//
+// Unlike the law-based suites it needs no algebraic structure, only a
+// modulus and two operands, so it suits fuzz targets: the fuzzer picks
+// moduli and operands of every length, including the short and
+// leading-zero ones that limb-handling bugs hide behind.
+func TestDifferential(t testing.TB, impls []Impl, N, x, y *big.Int) {
+	t.Helper()
+	x, y = new(big.Int).Mod(x, N), new(big.Int).Mod(y, N)
+	product := new(big.Int).Mul(x, y)
+	product.Mod(product, N)
+	var power *big.Int
+	for _, impl := range impls {
+		m, err := impl.New(N)
+		if err != nil {
+			t.Errorf("%s: N = %v: %v", impl.Name, N, err)
+			continue
+		}
+		if got := m.Mul(x, y); got.Cmp(product) != 0 {
+			t.Errorf("%s: N = %v: Mul(%v, %v) = %v, want %v", impl.Name, N, x, y, got, product)
+		}
+		e, ok := m.(exponentiator)
+		if !ok {
+			continue
+		}
+		if power == nil {
+			power = new(big.Int).Exp(x, y, N)
+		}
+		if got := e.Exp(x, y); got.Cmp(power) != 0 {
+			t.Errorf("%s: N = %v: Exp(%v, %v) = %v, want %v", impl.Name, N, x, y, got, power)
+		}
+	}
+}
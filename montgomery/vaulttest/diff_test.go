@@ -0,0 +1,127 @@
+package vaulttest
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/blck-snwmn/arithmetic-vault/montgomery"
+)
+
+// FuzzDifferential compares every registered backend with math/big on
+// moduli and operands taken from the fuzzer's bytes, big-endian. Moduli are
+// capped at 1024 bits to keep Exp with a full-size exponent fast.
+//
+//	go test -run '^$' -fuzz FuzzDifferential ./vaulttest
+func FuzzDifferential(f *testing.F) {
+	p256, err := montgomery.LookupParams("p256")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, seed := range [][3][]byte{
+		{{3}, {2}, {2}},
+		{{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, {0xff}, {0x01, 0, 0, 0, 0, 0, 0, 0, 0}},
+		// 2^64+1: one word past a word boundary, with a short operand
+		{{1, 0, 0, 0, 0, 0, 0, 0, 1}, {1, 0, 0, 0, 0, 0, 0, 0, 0}, {5}},
+		{p256.N.Bytes(), new(big.Int).Sub(p256.N, big.NewInt(1)).Bytes(), {0x80}},
+		// even: an EvenCtx around the odd part
+		{{0x01, 0x00}, {0xff}, {0x7f}},
+	} {
+		f.Add(seed[0], seed[1], seed[2])
+	}
+	impls := Backends()
+	f.Fuzz(func(t *testing.T, nBytes, xBytes, yBytes []byte) {
+		if len(nBytes) > 128 {
+			t.Skip()
+		}
+		N := new(big.Int).SetBytes(nBytes)
+		if N.Cmp(big.NewInt(2)) < 0 {
+			t.Skip()
+		}
+		TestDifferential(t, impls, N, new(big.Int).SetBytes(xBytes), new(big.Int).SetBytes(yBytes))
+	})
+}
+
+// TestDifferential_moduli runs the differential check on seeded random
+// moduli on both sides of every word boundary up to 1025 bits, mostly odd,
+// with operands of random length.
+func TestDifferential_moduli(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(3, 4))
+	random := func(bits int) *big.Int {
+		x := new(big.Int)
+		for range (bits + 63) / 64 {
+			x.Lsh(x, 64).Or(x, new(big.Int).SetUint64(rng.Uint64()))
+		}
+		return x.Rsh(x, uint(64*((bits+63)/64)-bits))
+	}
+	impls := Backends()
+	for _, bits := range []int{2, 3, 8, 63, 64, 65, 127, 128, 129, 255, 256, 257, 511, 512, 513, 1023, 1024, 1025} {
+		for i := range 4 {
+			N := random(bits)
+			N.SetBit(N, bits-1, 1)
+			// one even modulus in four
+			N.SetBit(N, 0, uint(min(i, 1)))
+			if N.Cmp(big.NewInt(2)) < 0 {
+				continue
+			}
+			x, y := random(1+rng.IntN(bits)), random(1+rng.IntN(bits))
+			TestDifferential(t, impls, N, x, y)
+		}
+	}
+}
+
+func TestDifferential_catches(t *testing.T) {
+	t.Parallel()
+
+	N := big.NewInt(1_000_003)
+	good := Impl{Name: "cioswords", New: func(N *big.Int) (montgomery.ModMultiplier, error) {
+		return montgomery.New(montgomery.KindCIOSWords, N)
+	}}
+	tests := []struct {
+		name string
+		impl Impl
+		want string
+	}{
+		{"wrong square", Impl{Name: "square", New: func(N *big.Int) (montgomery.ModMultiplier, error) {
+			m, err := good.New(N)
+			return wrongSquare{m}, err
+		}}, "square: N = 1000003: Mul(12, 12)"},
+		{"wrong Exp", Impl{Name: "exp", New: func(N *big.Int) (montgomery.ModMultiplier, error) {
+			m, err := good.New(N)
+			return wrongExp{m}, err
+		}}, "exp: N = 1000003: Exp(12, 12)"},
+		{"constructor", Impl{Name: "broken", New: func(*big.Int) (montgomery.ModMultiplier, error) {
+			return nil, errors.New("no device")
+		}}, "broken: N = 1000003: no device"},
+	}
+	for _, tc := range tests {
+		rec := &recorder{TB: t}
+		TestDifferential(rec, []Impl{good, tc.impl}, N, big.NewInt(12), new(big.Int).Add(N, big.NewInt(12)))
+		if len(rec.errs) != 1 || !strings.HasPrefix(rec.errs[0], tc.want) {
+			t.Errorf("%s: reported %q, want one failure starting %q", tc.name, rec.errs, tc.want)
+		}
+	}
+
+	// Every registered backend takes part
+	names := make([]string, 0, len(Backends()))
+	for _, impl := range Backends() {
+		names = append(names, impl.Name)
+	}
+	if got, want := fmt.Sprint(names), fmt.Sprint(montgomery.Backends()); got != want {
+		t.Errorf("Backends() = %v, want %v", got, want)
+	}
+}
+
+// wrongExp is a context whose Exp is off by one.
+type wrongExp struct{ montgomery.ModMultiplier }
+
+func (w wrongExp) Exp(base, exp *big.Int) *big.Int {
+	z := new(big.Int).Exp(base, exp, w.Modulus())
+	z.Add(z, big.NewInt(1))
+	return z.Mod(z, w.Modulus())
+}
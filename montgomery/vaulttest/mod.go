@@ -0,0 +1,82 @@
+package vaulttest
+
+import (
+	"math/big"
+	"math/rand/v2"
+
+	"github.com/blck-snwmn/arithmetic-vault/montgomery"
+)
+
+// ModRing returns Z_N, for the modulus N of m, as a Ring whose
+// multiplication is m.Mul. Addition, negation and equality are computed
+// mod N with math/big, so every law that fails points at m.
+func ModRing(m montgomery.ModMultiplier) Ring[*big.Int] {
+	return modRing{m: m, n: m.Modulus()}
+}
+
+// ModField is ModRing for a prime N, with inverses from math/big: the law
+// x·x⁻¹ = 1 then checks the products of m against them.
+func ModField(m montgomery.ModMultiplier) Field[*big.Int] {
+	return modRing{m: m, n: m.Modulus()}
+}
+
+// UnitGroup returns the multiplicative group Z_N^* of units mod N, with
+// m.Mul as the group operation and inverses from math/big.
+func UnitGroup(m montgomery.ModMultiplier) Group[*big.Int] {
+	return unitGroup{modRing{m: m, n: m.Modulus()}}
+}
+
+type modRing struct {
+	m montgomery.ModMultiplier
+	n *big.Int
+}
+
+func (r modRing) Zero() *big.Int { return new(big.Int) }
+func (r modRing) One() *big.Int  { return big.NewInt(1) }
+
+func (r modRing) Add(x, y *big.Int) *big.Int {
+	z := new(big.Int).Add(x, y)
+	return z.Mod(z, r.n)
+}
+
+func (r modRing) Neg(x *big.Int) *big.Int {
+	z := new(big.Int).Neg(x)
+	return z.Mod(z, r.n)
+}
+
+func (r modRing) Mul(x, y *big.Int) *big.Int { return r.m.Mul(x, y) }
+
+func (r modRing) Equal(x, y *big.Int) bool { return x.Cmp(y) == 0 }
+
+func (r modRing) Inv(x *big.Int) (*big.Int, bool) {
+	z := new(big.Int).ModInverse(x, r.n)
+	return z, z != nil
+}
+
+// Random returns a residue in [0, N), from one more word than N has so
+// that the reduction leaves no visible bias.
+func (r modRing) Random(rng *rand.Rand) *big.Int {
+	words := make([]big.Word, len(r.n.Bits())+1)
+	for i := range words {
+		words[i] = big.Word(rng.Uint64())
+	}
+	z := new(big.Int).SetBits(words)
+	return z.Mod(z, r.n)
+}
+
+type unitGroup struct{ r modRing }
+
+func (g unitGroup) Identity() *big.Int          { return big.NewInt(1) }
+func (g unitGroup) Op(x, y *big.Int) *big.Int   { return g.r.Mul(x, y) }
+func (g unitGroup) Equal(x, y *big.Int) bool    { return x.Cmp(y) == 0 }
+func (g unitGroup) Inverse(x *big.Int) *big.Int { return new(big.Int).ModInverse(x, g.r.n) }
+
+// Random returns a unit, drawing residues until one is coprime to N.
+func (g unitGroup) Random(rng *rand.Rand) *big.Int {
+	for {
+		x := g.r.Random(rng)
+		if new(big.Int).GCD(nil, nil, x, g.r.n).Cmp(big.NewInt(1)) == 0 {
+			return x
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package vaulttest
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/blck-snwmn/arithmetic-vault/montgomery"
+)
+
+// TestBackends runs every registered backend through the suite: Z_p as a
+// field for the P-256 prime, and Z_N as a ring and Z_N^* as a group for a
+// composite N.
+func TestBackends(t *testing.T) {
+	t.Parallel()
+
+	params, err := montgomery.LookupParams("p256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := params.N
+	// 2^127-1 · (2^61-1)
+	composite := new(big.Int).Mul(
+		new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1)),
+		new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 61), big.NewInt(1)),
+	)
+	R := new(big.Int).Lsh(big.NewInt(1), 256)
+
+	for _, name := range montgomery.Backends() {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			open := func(N *big.Int) montgomery.ModMultiplier {
+				m, err := montgomery.Open(R, N, montgomery.WithBackend(name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return m
+			}
+			TestField(t, ModField(open(p)))
+			TestGroup(t, UnitGroup(open(p)))
+			m := open(composite)
+			TestRing(t, ModRing(m))
+			TestGroup(t, UnitGroup(m))
+		})
+	}
+}
+
+// wrongSquare is a ModMultiplier whose squares are off by one.
+type wrongSquare struct{ montgomery.ModMultiplier }
+
+func (w wrongSquare) Mul(x, y *big.Int) *big.Int {
+	z := w.ModMultiplier.Mul(x, y)
+	if x.Cmp(y) == 0 {
+		z.Add(z, big.NewInt(1))
+		z.Mod(z, w.Modulus())
+	}
+	return z
+}
+
+func TestModRing_catches(t *testing.T) {
+	t.Parallel()
+
+	params, err := montgomery.LookupParams("p256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := montgomery.New(montgomery.KindCIOSWords, params.N)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, run := range []func(testing.TB){
+		func(tb testing.TB) { TestRing(tb, ModRing(wrongSquare{m})) },
+		func(tb testing.TB) { TestField(tb, ModField(wrongSquare{m})) },
+		func(tb testing.TB) { TestGroup(tb, UnitGroup(wrongSquare{m})) },
+	} {
+		rec := &recorder{TB: t}
+		run(rec)
+		if len(rec.errs) == 0 {
+			t.Error("wrong squares passed the suite")
+		}
+	}
+}
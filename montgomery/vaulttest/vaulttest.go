@@ -0,0 +1,220 @@
+// Package vaulttest checks implementations of algebraic structures against
+// the laws that define them, in the manner of testing/fstest: a backend
+// registered with montgomery.Register, or any other type that can be put
+// behind Ring, Field or Group, proves itself with one call from its own
+// tests:
+//
+//	func TestBackend(t *testing.T) {
+//		m, err := montgomery.Open(R, N, montgomery.WithBackend("gpu"))
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		vaulttest.TestRing(t, vaulttest.ModRing(m))
+//	}
+//
+// Each law is checked on 0, 1 and -1, or the identity of a group, and on
+// random elements drawn from a fixed seed, so failures reproduce. The
+// built-in backends pass the same suite.
+package vaulttest
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+// Iterations is the number of random elements, pairs or triples each law
+// is checked on, besides the fixed ones.
+const Iterations = 64
+
+// Ring is a commutative ring with identity over elements of type E.
+// Operations return new values and leave their arguments unchanged.
+type Ring[E any] interface {
+	Zero() E
+	One() E
+	Add(x, y E) E
+	Neg(x E) E
+	Mul(x, y E) E
+	Equal(x, y E) bool
+	// Random returns an element drawn from rng.
+	Random(rng *rand.Rand) E
+}
+
+// Field is a Ring in which every element but zero has an inverse.
+type Field[E any] interface {
+	Ring[E]
+	// Inv returns x⁻¹, or false if x has no inverse.
+	Inv(x E) (E, bool)
+}
+
+// Group is a group over elements of type E, written multiplicatively. It
+// need not be abelian.
+type Group[E any] interface {
+	Identity() E
+	Op(x, y E) E
+	Inverse(x E) E
+	Equal(x, y E) bool
+	// Random returns an element drawn from rng.
+	Random(rng *rand.Rand) E
+}
+
+// TestRing checks that r satisfies the laws of a commutative ring with
+// identity, and reports every law it breaks through t.
+func TestRing[E any](t testing.TB, r Ring[E]) {
+	t.Helper()
+	c := &checker[E]{t: t, eq: r.Equal, rng: rand.New(rand.NewPCG(1, 2))}
+	c.fixed = []E{r.Zero(), r.One(), r.Neg(r.One())}
+	c.random = r.Random
+	c.ring(r)
+}
+
+// TestField checks the laws of TestRing and those of inverses: 1 ≠ 0,
+// x·x⁻¹ = 1 for every x ≠ 0, and no inverse for 0.
+func TestField[E any](t testing.TB, f Field[E]) {
+	t.Helper()
+	c := &checker[E]{t: t, eq: f.Equal, rng: rand.New(rand.NewPCG(1, 2))}
+	c.fixed = []E{f.Zero(), f.One(), f.Neg(f.One())}
+	c.random = f.Random
+	c.ring(f)
+
+	zero, one := f.Zero(), f.One()
+	if f.Equal(one, zero) {
+		t.Errorf("field: 1 = 0")
+	}
+	if _, ok := f.Inv(zero); ok {
+		t.Errorf("field: 0 has an inverse")
+	}
+	for i := range len(c.fixed) + Iterations {
+		x := c.pick(i)
+		if f.Equal(x, zero) {
+			continue
+		}
+		inv, ok := f.Inv(x)
+		if !ok {
+			t.Errorf("multiplicative inverse fails for x = %v: none found", x)
+			return
+		}
+		if got := f.Mul(x, inv); !f.Equal(got, one) {
+			t.Errorf("multiplicative inverse fails for x = %v: x·%v = %v, want 1", x, inv, got)
+			return
+		}
+	}
+}
+
+// TestGroup checks that g satisfies the laws of a group: associativity,
+// and a two-sided identity and inverses.
+func TestGroup[E any](t testing.TB, g Group[E]) {
+	t.Helper()
+	c := &checker[E]{t: t, eq: g.Equal, rng: rand.New(rand.NewPCG(1, 2))}
+	e := g.Identity()
+	c.fixed = []E{e}
+	c.random = g.Random
+
+	c.law3("associativity", func(x, y, z E) (E, E) {
+		return g.Op(g.Op(x, y), z), g.Op(x, g.Op(y, z))
+	})
+	c.law1("left identity", func(x E) (E, E) { return g.Op(e, x), x })
+	c.law1("right identity", func(x E) (E, E) { return g.Op(x, e), x })
+	c.law1("left inverse", func(x E) (E, E) { return g.Op(g.Inverse(x), x), e })
+	c.law1("right inverse", func(x E) (E, E) { return g.Op(x, g.Inverse(x)), e })
+}
+
+// checker runs laws on the fixed elements and on random ones, and reports
+// the first counterexample of each.
+type checker[E any] struct {
+	t      testing.TB
+	eq     func(x, y E) bool
+	rng    *rand.Rand
+	fixed  []E
+	random func(*rand.Rand) E
+}
+
+func (c *checker[E]) ring(r Ring[E]) {
+	c.t.Helper()
+	zero, one := r.Zero(), r.One()
+
+	c.law3("additive associativity", func(x, y, z E) (E, E) {
+		return r.Add(r.Add(x, y), z), r.Add(x, r.Add(y, z))
+	})
+	c.law2("additive commutativity", func(x, y E) (E, E) {
+		return r.Add(x, y), r.Add(y, x)
+	})
+	c.law1("additive identity", func(x E) (E, E) {
+		return r.Add(x, zero), x
+	})
+	c.law1("additive inverse", func(x E) (E, E) {
+		return r.Add(x, r.Neg(x)), zero
+	})
+	c.law3("multiplicative associativity", func(x, y, z E) (E, E) {
+		return r.Mul(r.Mul(x, y), z), r.Mul(x, r.Mul(y, z))
+	})
+	c.law2("multiplicative commutativity", func(x, y E) (E, E) {
+		return r.Mul(x, y), r.Mul(y, x)
+	})
+	c.law1("multiplicative identity", func(x E) (E, E) {
+		return r.Mul(x, one), x
+	})
+	c.law1("multiplication by zero", func(x E) (E, E) {
+		return r.Mul(x, zero), zero
+	})
+	c.law3("distributivity", func(x, y, z E) (E, E) {
+		return r.Mul(x, r.Add(y, z)), r.Add(r.Mul(x, y), r.Mul(x, z))
+	})
+}
+
+// law1 checks got = want for every fixed and random x.
+func (c *checker[E]) law1(name string, f func(x E) (got, want E)) {
+	c.t.Helper()
+	for i := range len(c.fixed) + Iterations {
+		x := c.pick(i)
+		if got, want := f(x); !c.eq(got, want) {
+			c.t.Errorf("%s fails for x = %v: got %v, want %v", name, x, got, want)
+			return
+		}
+	}
+}
+
+// law2 checks that both sides agree for every pair of fixed elements and
+// for random pairs.
+func (c *checker[E]) law2(name string, f func(x, y E) (lhs, rhs E)) {
+	c.t.Helper()
+	n := len(c.fixed)
+	for i := range n*n + Iterations {
+		var x, y E
+		if i < n*n {
+			x, y = c.fixed[i/n], c.fixed[i%n]
+		} else {
+			x, y = c.random(c.rng), c.random(c.rng)
+		}
+		if lhs, rhs := f(x, y); !c.eq(lhs, rhs) {
+			c.t.Errorf("%s fails for x = %v, y = %v: %v ≠ %v", name, x, y, lhs, rhs)
+			return
+		}
+	}
+}
+
+// law3 checks that both sides agree for every triple of fixed elements and
+// for random triples.
+func (c *checker[E]) law3(name string, f func(x, y, z E) (lhs, rhs E)) {
+	c.t.Helper()
+	n := len(c.fixed)
+	for i := range n*n*n + Iterations {
+		var x, y, z E
+		if i < n*n*n {
+			x, y, z = c.fixed[i/(n*n)], c.fixed[i/n%n], c.fixed[i%n]
+		} else {
+			x, y, z = c.random(c.rng), c.random(c.rng), c.random(c.rng)
+		}
+		if lhs, rhs := f(x, y, z); !c.eq(lhs, rhs) {
+			c.t.Errorf("%s fails for x = %v, y = %v, z = %v: %v ≠ %v", name, x, y, z, lhs, rhs)
+			return
+		}
+	}
+}
+
+// pick returns the i-th fixed element, then random ones.
+func (c *checker[E]) pick(i int) E {
+	if i < len(c.fixed) {
+		return c.fixed[i]
+	}
+	return c.random(c.rng)
+}
@@ -0,0 +1,157 @@
+package vaulttest
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// recorder is a testing.TB that collects the failures it is sent.
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+// zmod is Z_n on ints, with hooks to break it.
+type zmod struct {
+	n   int
+	mul func(x, y int) int // nil means correct
+	inv func(x int) (int, bool)
+}
+
+func (z zmod) Zero() int                 { return 0 }
+func (z zmod) One() int                  { return 1 % z.n }
+func (z zmod) Add(x, y int) int          { return (x + y) % z.n }
+func (z zmod) Neg(x int) int             { return (z.n - x) % z.n }
+func (z zmod) Equal(x, y int) bool       { return x == y }
+func (z zmod) Random(rng *rand.Rand) int { return rng.IntN(z.n) }
+
+func (z zmod) Mul(x, y int) int {
+	if z.mul != nil {
+		return z.mul(x, y)
+	}
+	return x * y % z.n
+}
+
+func (z zmod) Inv(x int) (int, bool) {
+	if z.inv != nil {
+		return z.inv(x)
+	}
+	for y := 1; y < z.n; y++ {
+		if x*y%z.n == 1 {
+			return y, true
+		}
+	}
+	return 0, false
+}
+
+// perm3 is S3, the smallest non-abelian group, as permutations of 0..2.
+type perm3 struct{}
+
+var s3 = [][3]int{{0, 1, 2}, {1, 0, 2}, {2, 1, 0}, {0, 2, 1}, {1, 2, 0}, {2, 0, 1}}
+
+func (perm3) Identity() [3]int { return s3[0] }
+
+func (perm3) Op(p, q [3]int) [3]int {
+	return [3]int{p[q[0]], p[q[1]], p[q[2]]}
+}
+
+func (perm3) Inverse(p [3]int) [3]int {
+	var q [3]int
+	for i, v := range p {
+		q[v] = i
+	}
+	return q
+}
+
+func (perm3) Equal(p, q [3]int) bool       { return p == q }
+func (perm3) Random(rng *rand.Rand) [3]int { return s3[rng.IntN(len(s3))] }
+
+func TestSuites_pass(t *testing.T) {
+	t.Parallel()
+
+	TestRing[int](t, zmod{n: 12})
+	TestField[int](t, zmod{n: 13})
+	TestGroup[[3]int](t, perm3{})
+}
+
+func TestSuites_catch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		run  func(testing.TB)
+		want []string // laws that must be reported
+	}{
+		{
+			name: "squares off by one",
+			run: func(tb testing.TB) {
+				TestRing[int](tb, zmod{n: 101, mul: func(x, y int) int {
+					if x == y {
+						return (x*y + 1) % 101
+					}
+					return x * y % 101
+				}})
+			},
+			want: []string{"multiplicative associativity", "multiplication by zero"},
+		},
+		{
+			name: "not commutative",
+			run: func(tb testing.TB) {
+				TestRing[int](tb, zmod{n: 101, mul: func(x, y int) int { return x * (y + y%2) % 101 }})
+			},
+			want: []string{"multiplicative commutativity"},
+		},
+		{
+			name: "not a field",
+			run:  func(tb testing.TB) { TestField[int](tb, zmod{n: 12}) },
+			want: []string{"multiplicative inverse"},
+		},
+		{
+			name: "zero invertible",
+			run: func(tb testing.TB) {
+				TestField[int](tb, zmod{n: 13, inv: func(x int) (int, bool) { return 0, true }})
+			},
+			want: []string{"0 has an inverse", "multiplicative inverse"},
+		},
+		{
+			name: "trivial ring as a field",
+			run:  func(tb testing.TB) { TestField[int](tb, zmod{n: 1}) },
+			want: []string{"1 = 0"},
+		},
+		{
+			name: "inverse off",
+			run:  func(tb testing.TB) { TestGroup[[3]int](tb, badInverse{}) },
+			want: []string{"left inverse", "right inverse"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rec := &recorder{TB: t}
+			tt.run(rec)
+			for _, law := range tt.want {
+				found := false
+				for _, e := range rec.errs {
+					found = found || strings.Contains(e, law)
+				}
+				if !found {
+					t.Errorf("%q not reported; got %q", law, rec.errs)
+				}
+			}
+		})
+	}
+}
+
+// badInverse is S3 with the inverse of the 3-cycles wrong: their inverse
+// is the other 3-cycle, not themselves.
+type badInverse struct{ perm3 }
+
+func (badInverse) Inverse(p [3]int) [3]int { return p }
@@ -0,0 +1,252 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Vector multiplies residues mod one odd N several at a time, one residue
+// per SIMD lane: a batch RSA verification, the preprocessing of a
+// multi-scalar multiplication, any loop of independent products under one
+// modulus.
+//
+// The scalar kernel cannot be spread over lanes, since every limb of a
+// CIOS row waits for the carry of the one below. Vector instead splits
+// residues into vecDigit-bit digits held in 64-bit lanes, so a digit
+// product leaves room to accumulate many more without carrying, and lays
+// out vecLanes residues interleaved: digit j of residue l is word
+// j·vecLanes + l of a pack. One vector instruction then advances the same
+// digit of every residue in the pack. This runs on amd64 CPUs with AVX2
+// (vector_amd64.s) and on arm64 with NEON (vector_arm64.s); elsewhere, and
+// under the purego tag, Vector keeps the layout but multiplies lane by lane
+// with the scalar kernel, which in Go is faster than emulating the lanes.
+//
+// The packed operands of Mul, MulMont, ToMont and FromMont are whole packs
+// of residues in [0, N), each digit below 2^vecDigit, as Pack produces and
+// every method leaves them. They are not checked beyond their length.
+// Outputs may be the same slice as any input. Montgomery form is under
+// R = 2^(vecDigit·s), for the s digits of a residue, not under the R of a
+// scalar context. A Vector is safe for concurrent use.
+type Vector struct {
+	n       *big.Int
+	s       int      // digits per residue
+	nd      []uint64 // N packed into every lane
+	ni      uint64   // -N⁻¹ mod 2^vecDigit
+	rr, one []uint64 // R² mod N and 1, packed into every lane
+
+	// The scalar kernel's constants: N as k limbs, NI mod 2^64 and
+	// 2^(128·k)·R⁻¹ mod N, whose REDC turns its 2^(-64·k) into R⁻¹
+	nl, fix []uint64
+	nli     uint64
+}
+
+const (
+	// vecLanes is the number of residues in a pack, the 64-bit lanes of
+	// an AVX2 register or of two NEON registers.
+	vecLanes = 4
+	// vecDigit is the digit width. A row adds below 2^(2·vecDigit+1) to a
+	// digit, so 28-bit digits take vecNormalize rows between carry passes.
+	vecDigit = 28
+	vecMask  = 1<<vecDigit - 1
+	// vecNormalize is the number of rows between carry passes, which keeps
+	// every digit below 2^vecDigit + 64·2^57 < 2^64.
+	vecNormalize = 64
+)
+
+// NewVector precomputes the constants for N. It returns an error wrapping
+// ErrEvenModulus or ErrModulusTooSmall for an N that is even or at most 1.
+func NewVector(N *big.Int) (*Vector, error) {
+	switch {
+	case N.Cmp(big.NewInt(1)) <= 0:
+		return nil, ErrModulusTooSmall
+	case N.Bit(0) == 0:
+		return nil, ErrEvenModulus
+	}
+	// R > 2N keeps the unreduced result, below 2N, within s digits
+	s := (N.BitLen() + 1 + vecDigit - 1) / vecDigit
+	v := &Vector{n: new(big.Int).Set(N), s: s}
+	v.nd = v.broadcast(N)
+	v.ni = WordInverse(N.Uint64(), vecDigit)
+	rr := new(big.Int).Lsh(big.NewInt(1), uint(2*vecDigit*s))
+	v.rr = v.broadcast(rr.Mod(rr, N))
+	v.one = v.broadcast(big.NewInt(1))
+
+	k := (N.BitLen() + 63) / 64
+	fix := new(big.Int).Lsh(big.NewInt(1), uint(128*k))
+	fix.Mul(fix, new(big.Int).ModInverse(new(big.Int).Lsh(big.NewInt(1), uint(vecDigit*s)), N))
+	v.nl, v.nli, v.fix = limbsPadded(N, k), WordInverse(N.Uint64(), 64), limbsPadded(fix.Mod(fix, N), k)
+	return v, nil
+}
+
+// Modulus returns N.
+func (v *Vector) Modulus() *big.Int { return new(big.Int).Set(v.n) }
+
+// Lanes returns the number of residues in a pack.
+func (v *Vector) Lanes() int { return vecLanes }
+
+// PackedLen returns the length of the packed slice holding k residues:
+// whole packs of s·Lanes words, the last one padded with zeros.
+func (v *Vector) PackedLen(k int) int {
+	return (k + vecLanes - 1) / vecLanes * v.packLen()
+}
+
+func (v *Vector) packLen() int { return v.s * vecLanes }
+
+// Pack returns xs in the packed layout. Residues outside [0, N) are
+// reduced mod N.
+func (v *Vector) Pack(xs []*big.Int) []uint64 {
+	p := make([]uint64, v.PackedLen(len(xs)))
+	for i, x := range xs {
+		x = InputPermissive.mustOperand(v.n, "Vector.Pack", x)
+		v.put(p[i/vecLanes*v.packLen():], i%vecLanes, x)
+	}
+	return p
+}
+
+// Unpack returns the first k residues of the packed slice p.
+func (v *Vector) Unpack(p []uint64, k int) []*big.Int {
+	v.checkLen("Unpack", p)
+	if k > len(p)/v.packLen()*vecLanes {
+		panic(fmt.Sprintf("montgomery: Vector.Unpack: %d residues requested from %d packs", k, len(p)/v.packLen()))
+	}
+	xs := make([]*big.Int, k)
+	for i := range xs {
+		xs[i] = v.get(p[i/vecLanes*v.packLen():], i%vecLanes)
+	}
+	return xs
+}
+
+// Mul sets z = x·y mod N lane by lane, with two passes each, since
+// REDC(REDC(x, y), R²) = x·y.
+func (v *Vector) Mul(z, x, y []uint64) {
+	v.checkLen("Mul", z, x, y)
+	t := v.scratch()
+	for i := 0; i < len(z); i += v.packLen() {
+		zp, xp, yp := z[i:i+v.packLen()], x[i:i+v.packLen()], y[i:i+v.packLen()]
+		v.redc(zp, xp, yp, t)
+		v.redc(zp, zp, v.rr, t)
+	}
+}
+
+// MulMont sets z = x·y·R⁻¹ mod N lane by lane, the product of values in
+// Montgomery form.
+func (v *Vector) MulMont(z, x, y []uint64) {
+	v.checkLen("MulMont", z, x, y)
+	t := v.scratch()
+	for i := 0; i < len(z); i += v.packLen() {
+		v.redc(z[i:i+v.packLen()], x[i:i+v.packLen()], y[i:i+v.packLen()], t)
+	}
+}
+
+// ToMont sets z = x·R mod N lane by lane, x in Montgomery form.
+func (v *Vector) ToMont(z, x []uint64) {
+	v.checkLen("ToMont", z, x)
+	t := v.scratch()
+	for i := 0; i < len(z); i += v.packLen() {
+		v.redc(z[i:i+v.packLen()], x[i:i+v.packLen()], v.rr, t)
+	}
+}
+
+// FromMont sets z = x·R⁻¹ mod N lane by lane, x out of Montgomery form.
+func (v *Vector) FromMont(z, x []uint64) {
+	v.checkLen("FromMont", z, x)
+	t := v.scratch()
+	for i := 0; i < len(z); i += v.packLen() {
+		v.redc(z[i:i+v.packLen()], x[i:i+v.packLen()], v.one, t)
+	}
+}
+
+// MulBatch computes x·y mod N for every pair in pairs, packing them,
+// multiplying a pack at a time and unpacking the results. Operands outside
+// [0, N) are reduced mod N.
+func (v *Vector) MulBatch(pairs [][2]*big.Int) []*big.Int {
+	xs, ys := make([]*big.Int, len(pairs)), make([]*big.Int, len(pairs))
+	for i, p := range pairs {
+		xs[i], ys[i] = p[0], p[1]
+	}
+	x, y := v.Pack(xs), v.Pack(ys)
+	v.Mul(x, x, y)
+	return v.Unpack(x, len(pairs))
+}
+
+// checkLen panics unless every slice holds the same whole number of packs.
+func (v *Vector) checkLen(op string, xs ...[]uint64) {
+	for _, x := range xs {
+		if len(x)%v.packLen() != 0 || len(x) != len(xs[0]) {
+			panic(fmt.Sprintf("montgomery: Vector.%s: operand has %d words, want the same multiple of %d", op, len(x), v.packLen()))
+		}
+	}
+}
+
+// broadcast returns x, below 2^(vecDigit·s), packed into every lane.
+func (v *Vector) broadcast(x *big.Int) []uint64 {
+	p := make([]uint64, v.packLen())
+	for l := range vecLanes {
+		v.put(p, l, x)
+	}
+	return p
+}
+
+// put stores x, below 2^(vecDigit·s), as lane l of the pack p.
+func (v *Vector) put(p []uint64, l int, x *big.Int) {
+	v.setLane(p, l, limbsPadded(x, v.limbs()))
+}
+
+// get returns lane l of the pack p.
+func (v *Vector) get(p []uint64, l int) *big.Int {
+	return tobigInt(v.lane(make([]uint64, v.limbs()), p, l))
+}
+
+// setLane stores the limbs w as lane l of the pack p.
+func (v *Vector) setLane(p []uint64, l int, w []uint64) {
+	for j := range v.s {
+		// Digit j straddles at most two limbs
+		bit := j * vecDigit
+		d := w[bit/64] >> (bit % 64)
+		if bit%64 > 64-vecDigit {
+			d |= w[bit/64+1] << (64 - bit%64)
+		}
+		p[j*vecLanes+l] = d & vecMask
+	}
+}
+
+// lane sets w, of v.limbs() limbs, to lane l of the pack p and returns it.
+func (v *Vector) lane(w, p []uint64, l int) []uint64 {
+	clear(w)
+	for j := range v.s {
+		bit := j * vecDigit
+		d := p[j*vecLanes+l]
+		w[bit/64] |= d << (bit % 64)
+		if bit%64 > 64-vecDigit {
+			w[bit/64+1] |= d >> (64 - bit%64)
+		}
+	}
+	return w
+}
+
+// limbs is the number of 64-bit limbs spanned by s digits.
+func (v *Vector) limbs() int { return (v.s*vecDigit + 63) / 64 }
+
+// scratch returns the working space of redc.
+func (v *Vector) scratch() []uint64 {
+	return make([]uint64, max(v.packLen(), 3*v.limbs()+len(v.nl)+2))
+}
+
+// redc sets the pack z = x·y·R⁻¹ mod N lane by lane, using t from
+// scratch. z may alias x or y.
+func (v *Vector) redc(z, x, y, t []uint64) {
+	if vecRedcAsm(z, x, y, t[:v.packLen()], v.nd, v.ni) {
+		return
+	}
+	// One lane at a time: z = REDC(REDC(x, y), fix) = x·y·R⁻¹ mod N
+	k, w := len(v.nl), v.limbs()
+	xl, yl, zl, tl := t[:w], t[w:2*w], t[2*w:3*w], t[3*w:]
+	clear(zl[k:])
+	for l := range vecLanes {
+		v.lane(xl, x, l)
+		v.lane(yl, y, l)
+		montMulWords(zl[:k], xl[:k], yl[:k], v.nl, v.nli, tl)
+		montMulWords(zl[:k], zl[:k], v.fix, v.nl, v.nli, tl)
+		v.setLane(z, l, zl)
+	}
+}
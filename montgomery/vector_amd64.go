@@ -0,0 +1,64 @@
+//go:build !purego
+
+package montgomery
+
+// useAVX2 reports whether the CPU has AVX2 and the OS saves the YMM
+// registers.
+var useAVX2 = hasAVX2()
+
+func hasAVX2() bool {
+	maxID, _, _, _ := cpuid(0, 0)
+	if maxID < 7 {
+		return false
+	}
+	_, _, ecx, _ := cpuid(1, 0)
+	const osxsave, avx = 1 << 27, 1 << 28
+	if ecx&osxsave == 0 || ecx&avx == 0 || xgetbv()&6 != 6 {
+		return false
+	}
+	_, ebx, _, _ := cpuid(7, 0)
+	return ebx&(1<<5) != 0
+}
+
+// xgetbv returns the low half of XCR0, whose bits 1 and 2 are set when the
+// OS saves the XMM and YMM registers.
+func xgetbv() uint32
+
+// vecRowsAVX2 runs one Montgomery row per pack of digits in y: every lane
+// of t becomes (t + x·y[i] + m·N)/2^vecDigit, with m chosen to make the
+// division exact. No carry is propagated but that out of digit 0.
+//
+//go:noescape
+func vecRowsAVX2(t, x, y, n []uint64, ni uint64)
+
+// vecCarryAVX2 propagates the carries of every lane of t, leaving each
+// digit below 2^vecDigit.
+//
+//go:noescape
+func vecCarryAVX2(t []uint64)
+
+// vecReduceAVX2 propagates the carries of t, whose lanes are below 2N, and
+// sets z to t mod N.
+//
+//go:noescape
+func vecReduceAVX2(z, t, n []uint64)
+
+// vecRedcAsm runs Vector.redc in assembly and reports true where the CPU
+// has AVX2, and otherwise returns false without doing anything. The rows
+// leave the digits of t unnormalized; a carry pass every vecNormalize rows
+// keeps them from overflowing.
+func vecRedcAsm(z, x, y, t, n []uint64, ni uint64) bool {
+	if !useAVX2 {
+		return false
+	}
+	s := len(n) / vecLanes
+	clear(t)
+	for i := 0; i < s; i += vecNormalize {
+		vecRowsAVX2(t, x, y[i*vecLanes:min(i+vecNormalize, s)*vecLanes], n, ni)
+		if i+vecNormalize < s {
+			vecCarryAVX2(t)
+		}
+	}
+	vecReduceAVX2(z, t, n)
+	return true
+}
@@ -0,0 +1,183 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// func xgetbv() uint32
+TEXT ·xgetbv(SB), NOSPLIT, $0-4
+	MOVL $0, CX
+	XGETBV
+	MOVL AX, ret+0(FP)
+	RET
+
+// func vecRowsAVX2(t, x, y, n []uint64, ni uint64)
+//
+// Each YMM register holds one digit of the four lanes of a pack, and a row
+// is vecRowsGo's: with yi the row's digit, t[j-1] = t[j] + x[j]·yi + m·N[j]
+// for every j, VPMULUDQ multiplying the low 32 bits of each lane. The loop
+// over j runs four digits per iteration through R11, R12 and R13, which
+// walk x, N and t, with plain displacements: indexed memory operands cost
+// the front end an extra micro-op each. R9 counts the rows and DX is the
+// number of digits.
+TEXT ·vecRowsAVX2(SB), NOSPLIT, $0-104
+	MOVQ t_base+0(FP), DI
+	MOVQ x_base+24(FP), SI
+	MOVQ y_base+48(FP), R8
+	MOVQ y_len+56(FP), R9
+	SHRQ $2, R9
+	MOVQ n_base+72(FP), R10
+	MOVQ n_len+80(FP), DX
+	SHRQ $2, DX
+
+	// Y15 = NI and Y14 = 2^28 - 1 in every lane
+	VPBROADCASTQ ni+96(FP), Y15
+	VPCMPEQQ     Y14, Y14, Y14
+	VPSRLQ       $36, Y14, Y14
+
+row:
+	// m = (t[0] + x[0]·yi)·NI mod 2^28, and Y13 the carry out of digit 0
+	VMOVDQU  (R8), Y0
+	ADDQ     $32, R8
+	VPMULUDQ (SI), Y0, Y1
+	VPADDQ   (DI), Y1, Y1
+	VPAND    Y14, Y1, Y2
+	VPMULUDQ Y15, Y2, Y2
+	VPAND    Y14, Y2, Y2
+	VPMULUDQ (R10), Y2, Y3
+	VPADDQ   Y3, Y1, Y1
+	VPSRLQ   $28, Y1, Y13
+
+	LEAQ 32(SI), R11
+	LEAQ 32(R10), R12
+	LEAQ 32(DI), R13
+	LEAQ -1(DX), CX
+	CMPQ CX, $4
+	JB   single
+
+quad:
+	VPMULUDQ (R11), Y0, Y4
+	VPMULUDQ (R12), Y2, Y5
+	VPMULUDQ 32(R11), Y0, Y6
+	VPMULUDQ 32(R12), Y2, Y7
+	VPMULUDQ 64(R11), Y0, Y8
+	VPMULUDQ 64(R12), Y2, Y9
+	VPMULUDQ 96(R11), Y0, Y10
+	VPMULUDQ 96(R12), Y2, Y11
+	VPADDQ   (R13), Y4, Y4
+	VPADDQ   32(R13), Y6, Y6
+	VPADDQ   64(R13), Y8, Y8
+	VPADDQ   96(R13), Y10, Y10
+	VPADDQ   Y5, Y4, Y4
+	VPADDQ   Y7, Y6, Y6
+	VPADDQ   Y9, Y8, Y8
+	VPADDQ   Y11, Y10, Y10
+	VMOVDQU  Y4, -32(R13)
+	VMOVDQU  Y6, (R13)
+	VMOVDQU  Y8, 32(R13)
+	VMOVDQU  Y10, 64(R13)
+	ADDQ     $128, R11
+	ADDQ     $128, R12
+	ADDQ     $128, R13
+	SUBQ     $4, CX
+	CMPQ     CX, $4
+	JAE      quad
+
+single:
+	TESTQ CX, CX
+	JZ    rowDone
+	VPMULUDQ (R11), Y0, Y4
+	VPMULUDQ (R12), Y2, Y5
+	VPADDQ   (R13), Y4, Y4
+	VPADDQ   Y5, Y4, Y4
+	VMOVDQU  Y4, -32(R13)
+	ADDQ     $32, R11
+	ADDQ     $32, R12
+	ADDQ     $32, R13
+	DECQ     CX
+	JMP      single
+
+rowDone:
+	// t[s-1] = 0, then t[0] += carry; R13 points past the end of t
+	VPXOR   Y4, Y4, Y4
+	VMOVDQU Y4, -32(R13)
+	VPADDQ  (DI), Y13, Y13
+	VMOVDQU Y13, (DI)
+
+	DECQ R9
+	JNZ  row
+	VZEROUPPER
+	RET
+
+// func vecCarryAVX2(t []uint64)
+//
+// vecCarry four lanes at a time: each digit takes the carry of the one
+// below it in Y1 and passes on all but its low 28 bits.
+TEXT ·vecCarryAVX2(SB), NOSPLIT, $0-24
+	MOVQ     t_base+0(FP), DI
+	MOVQ     t_len+8(FP), CX
+	SHRQ     $2, CX
+	VPCMPEQQ Y14, Y14, Y14
+	VPSRLQ   $36, Y14, Y14
+	VPXOR    Y1, Y1, Y1
+
+carry:
+	VPADDQ  (DI), Y1, Y0
+	VPSRLQ  $28, Y0, Y1
+	VPAND   Y14, Y0, Y0
+	VMOVDQU Y0, (DI)
+	ADDQ    $32, DI
+	DECQ    CX
+	JNZ     carry
+	VZEROUPPER
+	RET
+
+// func vecReduceAVX2(z, t, n []uint64)
+//
+// vecCarry on t, fused with the subtraction of N into z, whose borrow
+// chain runs in Y2; a second pass keeps t in the lanes that borrowed, that
+// is where t < N. No branch depends on the values.
+TEXT ·vecReduceAVX2(SB), NOSPLIT, $0-72
+	MOVQ     z_base+0(FP), R8
+	MOVQ     t_base+24(FP), DI
+	MOVQ     t_len+32(FP), CX
+	SHRQ     $2, CX
+	MOVQ     n_base+48(FP), R10
+	VPCMPEQQ Y14, Y14, Y14
+	VPSRLQ   $36, Y14, Y14
+	VPXOR    Y1, Y1, Y1
+	VPXOR    Y2, Y2, Y2
+	MOVQ     R8, R11
+	MOVQ     DI, R12
+	MOVQ     CX, DX
+
+subtract:
+	VPADDQ  (DI), Y1, Y0
+	VPSRLQ  $28, Y0, Y1
+	VPAND   Y14, Y0, Y0
+	VMOVDQU Y0, (DI)
+	VPSUBQ  (R10), Y0, Y3
+	VPSUBQ  Y2, Y3, Y3
+	VPSRLQ  $63, Y3, Y2
+	VPAND   Y14, Y3, Y3
+	VMOVDQU Y3, (R8)
+	ADDQ    $32, DI
+	ADDQ    $32, R10
+	ADDQ    $32, R8
+	DECQ    CX
+	JNZ     subtract
+
+	// Y2 = all ones in the lanes that borrowed
+	VPXOR  Y4, Y4, Y4
+	VPSUBQ Y2, Y4, Y2
+
+pick:
+	VMOVDQU (R11), Y3
+	VPAND   (R12), Y2, Y0
+	VPANDN  Y3, Y2, Y3
+	VPOR    Y0, Y3, Y3
+	VMOVDQU Y3, (R11)
+	ADDQ    $32, R11
+	ADDQ    $32, R12
+	DECQ    DX
+	JNZ     pick
+	VZEROUPPER
+	RET
@@ -0,0 +1,13 @@
+//go:build !purego
+
+package montgomery
+
+import "testing"
+
+func TestVectorAVX2(t *testing.T) {
+	t.Parallel()
+	if !useAVX2 {
+		t.Skip("CPU lacks AVX2")
+	}
+	testVectorKernels(t, vecRowsAVX2, vecCarryAVX2, vecReduceAVX2)
+}
@@ -0,0 +1,37 @@
+//go:build !purego
+
+package montgomery
+
+// vecRowsNEON is vecRowsAVX2 on NEON: every lane of t becomes
+// (t + x·y[i] + m·N)/2^vecDigit for each pack of digits in y, with no carry
+// propagated but that out of digit 0.
+//
+//go:noescape
+func vecRowsNEON(t, x, y, n []uint64, ni uint64)
+
+// vecCarryNEON propagates the carries of every lane of t, leaving each
+// digit below 2^vecDigit.
+//
+//go:noescape
+func vecCarryNEON(t []uint64)
+
+// vecReduceNEON propagates the carries of t, whose lanes are below 2N, and
+// sets z to t mod N.
+//
+//go:noescape
+func vecReduceNEON(z, t, n []uint64)
+
+// vecRedcAsm runs Vector.redc in assembly and reports true. Every ARMv8
+// core has Advanced SIMD, so there is no feature check.
+func vecRedcAsm(z, x, y, t, n []uint64, ni uint64) bool {
+	s := len(n) / vecLanes
+	clear(t)
+	for i := 0; i < s; i += vecNormalize {
+		vecRowsNEON(t, x, y[i*vecLanes:min(i+vecNormalize, s)*vecLanes], n, ni)
+		if i+vecNormalize < s {
+			vecCarryNEON(t)
+		}
+	}
+	vecReduceNEON(z, t, n)
+	return true
+}
@@ -0,0 +1,171 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// func vecRowsNEON(t, x, y, n []uint64, ni uint64)
+//
+// vecRowsAVX2 on 128-bit registers: a digit of the four lanes of a pack
+// loads into two registers, and UZP1 gathers the low 32 bits of its lanes
+// into one, the operand of UMLAL and UMLAL2, which multiply and accumulate
+// into the lanes of the low and high register. V16 holds yi and V18 m. In
+// the loop over j, R11, R12 and R13 walk x, N and t; R15 trails one digit
+// behind, since t[j] is stored to t[j-1]. R3 counts the rows.
+TEXT ·vecRowsNEON(SB), NOSPLIT, $0-104
+	MOVD t_base+0(FP), R0
+	MOVD x_base+24(FP), R1
+	MOVD y_base+48(FP), R2
+	MOVD y_len+56(FP), R3
+	LSR  $2, R3
+	MOVD n_base+72(FP), R4
+	MOVD n_len+80(FP), R5
+	LSR  $2, R5
+	MOVD ni+96(FP), R6
+
+	// V30 = NI and V29 = 2^28 - 1 in every 32-bit element
+	VDUP R6, V30.S4
+	MOVD $0x0fffffff, R7
+	VDUP R7, V29.S4
+
+row:
+	VLD1.P 32(R2), [V0.D2, V1.D2]
+	VUZP1  V1.S4, V0.S4, V16.S4
+
+	// a = t[0] + x[0]·yi in V4 and V5
+	VLD1    (R1), [V2.D2, V3.D2]
+	VUZP1   V3.S4, V2.S4, V17.S4
+	VLD1    (R0), [V4.D2, V5.D2]
+	VUMLAL  V16.S2, V17.S2, V4.D2
+	VUMLAL2 V16.S4, V17.S4, V5.D2
+
+	// m = a·NI mod 2^28
+	VUZP1 V5.S4, V4.S4, V18.S4
+	VMUL  V30.S4, V18.S4, V18.S4
+	VAND  V29.B16, V18.B16, V18.B16
+
+	// a += m·N[0], and V6 and V7 the carry out of digit 0
+	VLD1    (R4), [V2.D2, V3.D2]
+	VUZP1   V3.S4, V2.S4, V19.S4
+	VUMLAL  V18.S2, V19.S2, V4.D2
+	VUMLAL2 V18.S4, V19.S4, V5.D2
+	VUSHR   $28, V4.D2, V6.D2
+	VUSHR   $28, V5.D2, V7.D2
+
+	ADD  $32, R1, R11
+	ADD  $32, R4, R12
+	ADD  $32, R0, R13
+	MOVD R0, R15
+	SUB  $1, R5, R14
+	CBZ  R14, rowDone
+
+digit:
+	VLD1.P  32(R11), [V0.D2, V1.D2]
+	VLD1.P  32(R12), [V2.D2, V3.D2]
+	VLD1.P  32(R13), [V4.D2, V5.D2]
+	VUZP1   V1.S4, V0.S4, V20.S4
+	VUZP1   V3.S4, V2.S4, V21.S4
+	VUMLAL  V16.S2, V20.S2, V4.D2
+	VUMLAL2 V16.S4, V20.S4, V5.D2
+	VUMLAL  V18.S2, V21.S2, V4.D2
+	VUMLAL2 V18.S4, V21.S4, V5.D2
+	VST1.P  [V4.D2, V5.D2], 32(R15)
+	SUB     $1, R14
+	CBNZ    R14, digit
+
+rowDone:
+	// t[s-1] = 0, then t[0] += carry; R15 points at t[s-1]
+	STP  (ZR, ZR), (R15)
+	STP  (ZR, ZR), 16(R15)
+	VLD1 (R0), [V4.D2, V5.D2]
+	VADD V6.D2, V4.D2, V4.D2
+	VADD V7.D2, V5.D2, V5.D2
+	VST1 [V4.D2, V5.D2], (R0)
+
+	SUB  $1, R3
+	CBNZ R3, row
+	RET
+
+// func vecCarryNEON(t []uint64)
+//
+// vecCarry four lanes at a time: each digit takes the carry of the one
+// below it in V2 and V3 and passes on all but its low 28 bits.
+TEXT ·vecCarryNEON(SB), NOSPLIT, $0-24
+	MOVD t_base+0(FP), R0
+	MOVD t_len+8(FP), R1
+	LSR  $2, R1
+	MOVD $0x0fffffff, R7
+	VDUP R7, V28.D2
+	VEOR V2.B16, V2.B16, V2.B16
+	VEOR V3.B16, V3.B16, V3.B16
+	MOVD R0, R15
+
+carry:
+	VLD1.P 32(R0), [V0.D2, V1.D2]
+	VADD   V2.D2, V0.D2, V0.D2
+	VADD   V3.D2, V1.D2, V1.D2
+	VUSHR  $28, V0.D2, V2.D2
+	VUSHR  $28, V1.D2, V3.D2
+	VAND   V28.B16, V0.B16, V0.B16
+	VAND   V28.B16, V1.B16, V1.B16
+	VST1.P [V0.D2, V1.D2], 32(R15)
+	SUB    $1, R1
+	CBNZ   R1, carry
+	RET
+
+// func vecReduceNEON(z, t, n []uint64)
+//
+// vecCarry on t, fused with the subtraction of N into z, whose borrow
+// chain runs in V4 and V5; a second pass uses BIT to keep t in the lanes
+// that borrowed, that is where t < N. No branch depends on the values.
+TEXT ·vecReduceNEON(SB), NOSPLIT, $0-72
+	MOVD z_base+0(FP), R8
+	MOVD t_base+24(FP), R0
+	MOVD t_len+32(FP), R1
+	LSR  $2, R1
+	MOVD n_base+48(FP), R4
+	MOVD $0x0fffffff, R7
+	VDUP R7, V28.D2
+	VEOR V2.B16, V2.B16, V2.B16
+	VEOR V3.B16, V3.B16, V3.B16
+	VEOR V4.B16, V4.B16, V4.B16
+	VEOR V5.B16, V5.B16, V5.B16
+	MOVD R0, R15
+	MOVD R8, R11
+	MOVD R0, R12
+	MOVD R1, R9
+
+subtract:
+	VLD1.P 32(R0), [V0.D2, V1.D2]
+	VADD   V2.D2, V0.D2, V0.D2
+	VADD   V3.D2, V1.D2, V1.D2
+	VUSHR  $28, V0.D2, V2.D2
+	VUSHR  $28, V1.D2, V3.D2
+	VAND   V28.B16, V0.B16, V0.B16
+	VAND   V28.B16, V1.B16, V1.B16
+	VST1.P [V0.D2, V1.D2], 32(R15)
+	VLD1.P 32(R4), [V6.D2, V7.D2]
+	VSUB   V6.D2, V0.D2, V6.D2
+	VSUB   V7.D2, V1.D2, V7.D2
+	VSUB   V4.D2, V6.D2, V6.D2
+	VSUB   V5.D2, V7.D2, V7.D2
+	VUSHR  $63, V6.D2, V4.D2
+	VUSHR  $63, V7.D2, V5.D2
+	VAND   V28.B16, V6.B16, V6.B16
+	VAND   V28.B16, V7.B16, V7.B16
+	VST1.P [V6.D2, V7.D2], 32(R8)
+	SUB    $1, R1
+	CBNZ   R1, subtract
+
+	// V4 and V5 = all ones in the lanes that borrowed
+	VEOR V8.B16, V8.B16, V8.B16
+	VSUB V4.D2, V8.D2, V4.D2
+	VSUB V5.D2, V8.D2, V5.D2
+
+pick:
+	VLD1   (R11), [V0.D2, V1.D2]
+	VLD1.P 32(R12), [V2.D2, V3.D2]
+	VBIT   V4.B16, V2.B16, V0.B16
+	VBIT   V5.B16, V3.B16, V1.B16
+	VST1.P [V0.D2, V1.D2], 32(R11)
+	SUB    $1, R9
+	CBNZ   R9, pick
+	RET
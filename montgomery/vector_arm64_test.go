@@ -0,0 +1,10 @@
+//go:build !purego
+
+package montgomery
+
+import "testing"
+
+func TestVectorNEON(t *testing.T) {
+	t.Parallel()
+	testVectorKernels(t, vecRowsNEON, vecCarryNEON, vecReduceNEON)
+}
@@ -0,0 +1,107 @@
+//go:build (amd64 || arm64) && !purego
+
+package montgomery
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// vecRowsGo is vecRowsAVX2 and vecRowsNEON one lane at a time, the
+// reference for the assembly.
+func vecRowsGo(t, x, y, n []uint64, ni uint64) {
+	s := len(n) / vecLanes
+	for i := 0; i < len(y); i += vecLanes {
+		for l := range vecLanes {
+			yi := y[i+l]
+			a := t[l] + x[l]*yi
+			m := (a & vecMask) * ni & vecMask
+			c := (a + m*n[l]) >> vecDigit
+			for j := 1; j < s; j++ {
+				k := j*vecLanes + l
+				t[k-vecLanes] = t[k] + x[k]*yi + m*n[k]
+			}
+			t[(s-1)*vecLanes+l] = 0
+			t[l] += c
+		}
+	}
+}
+
+// vecCarryGo is the assembly carry pass one lane at a time.
+func vecCarryGo(t []uint64) {
+	for l := range vecLanes {
+		var c uint64
+		for k := l; k < len(t); k += vecLanes {
+			d := t[k] + c
+			t[k], c = d&vecMask, d>>vecDigit
+		}
+	}
+}
+
+// vecReduceGo is the assembly reduction one lane at a time.
+func vecReduceGo(z, t, n []uint64) {
+	vecCarryGo(t)
+	for l := range vecLanes {
+		var borrow uint64
+		for k := l; k < len(t); k += vecLanes {
+			d := t[k] - n[k] - borrow
+			z[k], borrow = d&vecMask, d>>63
+		}
+		if borrow != 0 {
+			for k := l; k < len(t); k += vecLanes {
+				z[k] = t[k]
+			}
+		}
+	}
+}
+
+// testVectorKernels checks a platform's assembly against the Go references
+// word for word: the rows from digits at the bound a carry pass leaves them
+// under, the carry pass from digits a full run of rows can reach.
+func testVectorKernels(t *testing.T, rows func(t, x, y, n []uint64, ni uint64), carry func(t []uint64), reduce func(z, t, n []uint64)) {
+	for _, s := range []int{1, 2, 5, 9, 74} {
+		t.Run(fmt.Sprintf("digits=%d", s), func(t *testing.T) {
+			t.Parallel()
+			rng := rand.New(rand.NewPCG(uint64(s), 0))
+			words := func(mask uint64) []uint64 {
+				d := make([]uint64, s*vecLanes)
+				for i := range d {
+					d[i] = rng.Uint64() & mask
+				}
+				return d
+			}
+			for range 20 {
+				x, n, y := words(vecMask), words(vecMask), words(vecMask)
+				y = y[:min(s, vecNormalize)*vecLanes]
+				ni := rng.Uint64() & vecMask
+				got := words(vecMask)
+				want := slices.Clone(got)
+				rows(got, x, y, n, ni)
+				vecRowsGo(want, x, y, n, ni)
+				if !slices.Equal(got, want) {
+					t.Fatal("rows differ from vecRowsGo")
+				}
+
+				got = words(1<<63 - 1)
+				want = slices.Clone(got)
+				carry(got)
+				vecCarryGo(want)
+				if !slices.Equal(got, want) {
+					t.Fatal("carry pass differs from vecCarryGo")
+				}
+
+				// Unnormalized digits whose lanes may land either side of n
+				got = words(1<<40 - 1)
+				gotZ, wantZ := make([]uint64, len(got)), make([]uint64, len(got))
+				want = slices.Clone(got)
+				reduce(gotZ, got, n)
+				vecReduceGo(wantZ, want, n)
+				if !slices.Equal(gotZ, wantZ) || !slices.Equal(got, want) {
+					t.Fatal("reduction differs from vecReduceGo")
+				}
+			}
+		})
+	}
+}
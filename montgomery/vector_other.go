@@ -0,0 +1,7 @@
+//go:build (!amd64 && !arm64) || purego
+
+package montgomery
+
+// vecRedcAsm reports false: without assembly, Vector runs the scalar
+// kernel lane by lane.
+func vecRedcAsm(z, x, y, t, n []uint64, ni uint64) bool { return false }
@@ -0,0 +1,180 @@
+package montgomery
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"testing"
+	"testing/quick"
+)
+
+// testVectorOperands returns k residues below N, the first one N-1.
+func testVectorOperands(rng *rand.Rand, N *big.Int, k int) []*big.Int {
+	xs := make([]*big.Int, k)
+	for i := range xs {
+		b := make([]byte, (N.BitLen()+7)/8)
+		for j := range b {
+			b[j] = byte(rng.Uint32())
+		}
+		xs[i] = new(big.Int).Mod(new(big.Int).SetBytes(b), N)
+	}
+	if k > 0 {
+		xs[0] = new(big.Int).Sub(N, big.NewInt(1))
+	}
+	return xs
+}
+
+func TestVector_Mul(t *testing.T) {
+	t.Parallel()
+
+	// 2048 and 4096 bits need more than vecNormalize rows
+	for _, bitSize := range []int{64, 256, 1024, 2048, 4096} {
+		for _, k := range []int{1, 4, 7} {
+			t.Run(fmt.Sprintf("bits=%d/k=%d", bitSize, k), func(t *testing.T) {
+				t.Parallel()
+				_, _, _, N := testParamsLarge(bitSize)
+				v, err := NewVector(N)
+				if err != nil {
+					t.Fatal(err)
+				}
+				rng := rand.New(rand.NewPCG(uint64(bitSize), uint64(k)))
+				xs, ys := testVectorOperands(rng, N, k), testVectorOperands(rng, N, k)
+
+				x, y := v.Pack(xs), v.Pack(ys)
+				z := make([]uint64, len(x))
+				v.Mul(z, x, y)
+
+				// The same products through Montgomery form, in place
+				v.ToMont(x, x)
+				v.ToMont(y, y)
+				v.MulMont(x, x, y)
+				v.FromMont(x, x)
+
+				for i, got := range v.Unpack(z, k) {
+					want := new(big.Int).Mul(xs[i], ys[i])
+					want.Mod(want, N)
+					if got.Cmp(want) != 0 {
+						t.Errorf("Mul lane %d = %v, want %v", i, got, want)
+					}
+					if mont := v.Unpack(x, k)[i]; mont.Cmp(want) != 0 {
+						t.Errorf("MulMont lane %d = %v, want %v", i, mont, want)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestVector_MulBatch(t *testing.T) {
+	t.Parallel()
+
+	for _, N := range []*big.Int{big.NewInt(3), bn254R, ed25519L} {
+		v, err := NewVector(N)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = quick.Check(func(pairs [][2][]byte) bool {
+			ps := make([][2]*big.Int, len(pairs))
+			for i, p := range pairs {
+				// Out-of-range operands are reduced
+				ps[i] = [2]*big.Int{new(big.Int).SetBytes(p[0]), new(big.Int).SetBytes(p[1])}
+			}
+			for i, got := range v.MulBatch(ps) {
+				want := new(big.Int).Mul(ps[i][0], ps[i][1])
+				if got.Cmp(want.Mod(want, N)) != 0 {
+					return false
+				}
+			}
+			return true
+		}, nil)
+		if err != nil {
+			t.Errorf("N = %v: %v", N, err)
+		}
+	}
+}
+
+func TestNewVector_errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		N    int64
+		want error
+	}{
+		{0, ErrModulusTooSmall},
+		{1, ErrModulusTooSmall},
+		{-7, ErrModulusTooSmall},
+		{10, ErrEvenModulus},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewVector(big.NewInt(tt.N)); !errors.Is(err, tt.want) {
+			t.Errorf("NewVector(%d) = %v, want %v", tt.N, err, tt.want)
+		}
+	}
+}
+
+func TestVector_checkLen(t *testing.T) {
+	t.Parallel()
+
+	v, err := NewVector(bn254R)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pack := v.PackedLen(1)
+	tests := []struct {
+		name string
+		f    func()
+	}{
+		{"partial pack", func() { v.MulMont(make([]uint64, pack-1), make([]uint64, pack-1), make([]uint64, pack-1)) }},
+		{"mismatched", func() { v.Mul(make([]uint64, pack), make([]uint64, 2*pack), make([]uint64, pack)) }},
+		{"unpack past the end", func() { v.Unpack(make([]uint64, pack), vecLanes+1) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			defer func() {
+				if recover() == nil {
+					t.Error("did not panic")
+				}
+			}()
+			tt.f()
+		})
+	}
+}
+
+// BenchmarkVector compares a pack of products with as many of the scalar
+// word-level kernel.
+func BenchmarkVector(b *testing.B) {
+	for _, bitSize := range []int{256, 1024, 2048, 4096} {
+		_, _, R, N := testParamsLarge(bitSize)
+		v, err := NewVector(N)
+		if err != nil {
+			b.Fatal(err)
+		}
+		rng := rand.New(rand.NewPCG(uint64(bitSize), 0))
+		xs, ys := testVectorOperands(rng, N, vecLanes), testVectorOperands(rng, N, vecLanes)
+		x, y := v.Pack(xs), v.Pack(ys)
+		z := make([]uint64, len(x))
+		b.Run(fmt.Sprintf("bits=%d/impl=vector", bitSize), func(b *testing.B) {
+			for b.Loop() {
+				v.MulMont(z, x, y)
+			}
+		})
+
+		w := NewMontgomeryCIOSWords(R, N)
+		xw, yw := make([][]uint64, vecLanes), make([][]uint64, vecLanes)
+		for i := range xw {
+			xw[i], yw[i] = w.ToWords(xs[i]), w.ToWords(ys[i])
+		}
+		zw := make([]uint64, w.S)
+		b.Run(fmt.Sprintf("bits=%d/impl=scalar", bitSize), func(b *testing.B) {
+			for b.Loop() {
+				for i := range xw {
+					w.MulMontWords(zw, xw[i], yw[i])
+				}
+			}
+		})
+	}
+}
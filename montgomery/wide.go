@@ -0,0 +1,68 @@
+package montgomery
+
+import "math/big"
+
+// ReduceWide computes x mod N for an x of any size using bit-by-bit
+// Montgomery reduction. See reduceWide for details.
+func (m *MontgomeryBitwise) ReduceWide(x *big.Int) *big.Int {
+	return reduceWide(m.engine(), x)
+}
+
+// ReduceWide computes x mod N for an x of any size using CIOS Montgomery
+// reduction. See reduceWide for details.
+func (m *MontgomeryCIOS) ReduceWide(x *big.Int) *big.Int {
+	return reduceWide(m.engine(), x)
+}
+
+// ReduceWide computes x mod N for an x of any size using CIOS Montgomery
+// reduction on []uint64 words. See reduceWide for details.
+func (m *MontgomeryCIOSWords) ReduceWide(x *big.Int) *big.Int {
+	return reduceWide(m.engine(), x)
+}
+
+// reduceWide computes x mod N in [0, N) for inputs much wider than the
+// modulus, such as hash outputs for hash-to-field, raw random bytes, or
+// products computed outside the package.
+//
+// x is split into k-bit chunks c_i (R = 2^k) so that x = Σ c_i * R^i, and
+// folded from the top with Horner's rule, one chunk per step:
+//
+//	acc = acc * R + c_i  (mod N)
+//
+// Both terms are single REDCs: REDC(acc, R² mod N) = acc * R mod N and
+// REDC(c_i, R mod N) = c_i mod N. The chunk is below R and the other factor
+// below N, so each product stays under R*N and every REDC lands in [0, N)
+// after its one conditional subtraction. A 16× wide input therefore costs
+// about 32 reductions and never a long division. Negative x are reduced as
+// -(|x| mod N) and mapped back into [0, N).
+func reduceWide(eng engine, x *big.Int) *big.Int {
+	redc, rr, N, k := eng.redc, eng.rr, eng.n, eng.k
+	if x.Sign() >= 0 && x.Cmp(N) < 0 {
+		return new(big.Int).Set(x)
+	}
+
+	one := big.NewInt(1)
+	rModN := redc(one, rr) // R mod N
+
+	// Split |x| into k-bit chunks, least significant first
+	mask := new(big.Int).Lsh(one, k)
+	mask.Sub(mask, one)
+	var chunks []*big.Int
+	for t := new(big.Int).Abs(x); t.Sign() > 0; t.Rsh(t, k) {
+		chunks = append(chunks, new(big.Int).And(t, mask))
+	}
+
+	acc := new(big.Int)
+	for i := len(chunks) - 1; i >= 0; i-- {
+		acc = redc(acc, rr)                  // acc * R mod N
+		acc.Add(acc, redc(chunks[i], rModN)) // + c_i mod N
+		if acc.Cmp(N) >= 0 {
+			acc.Sub(acc, N)
+		}
+	}
+
+	if x.Sign() < 0 && acc.Sign() != 0 {
+		acc.Sub(N, acc)
+	}
+	return acc
+}
@@ -0,0 +1,119 @@
+package montgomery
+
+import (
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestReduceWide(t *testing.T) {
+	t.Parallel()
+
+	x2048, y2048, R2048, N2048 := testParams2048()
+	N64, _ := new(big.Int).SetString("fffffffffffffffb", 16)
+	R64 := new(big.Int).Lsh(big.NewInt(1), 64)
+
+	// wide returns x repeated to the given number of multiples of its width
+	wide := func(x *big.Int, times int) *big.Int {
+		w := new(big.Int)
+		for range times {
+			w.Lsh(w, uint(x.BitLen()))
+			w.Or(w, x)
+		}
+		return w
+	}
+
+	tests := []struct {
+		name string
+		x    *big.Int
+		R    *big.Int
+		N    *big.Int
+	}{
+		{"zero", big.NewInt(0), R64, N64},
+		{"below N", big.NewInt(12345), R64, N64},
+		{"equals N", N64, R64, N64},
+		{"2N", new(big.Int).Lsh(N64, 1), R64, N64},
+		{"product of two residues", new(big.Int).Mul(new(big.Int).Sub(N64, big.NewInt(1)), new(big.Int).Sub(N64, big.NewInt(2))), R64, N64},
+		{"4x width", wide(new(big.Int).Sub(R64, big.NewInt(1)), 4), R64, N64},
+		{"16x width", wide(new(big.Int).Sub(R64, big.NewInt(1)), 16), R64, N64},
+		{"negative", new(big.Int).Neg(wide(big.NewInt(0x123456789abcdef), 5)), R64, N64},
+		{"negative multiple of N", new(big.Int).Neg(new(big.Int).Lsh(N64, 70)), R64, N64},
+		{"2048-bit 4x width", wide(x2048, 4), R2048, N2048},
+		{"2048-bit 16x width", wide(y2048, 16), R2048, N2048},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			want := new(big.Int).Mod(tc.x, tc.N)
+
+			t.Run("Bitwise", func(t *testing.T) {
+				t.Parallel()
+				m := NewMontgomeryBitwise(tc.R, tc.N)
+				if got := m.ReduceWide(tc.x); got.Cmp(want) != 0 {
+					t.Errorf("got %v, want %v", got, want)
+				}
+			})
+
+			t.Run("CIOS", func(t *testing.T) {
+				t.Parallel()
+				m := NewMontgomeryCIOS(tc.R, tc.N)
+				if got := m.ReduceWide(tc.x); got.Cmp(want) != 0 {
+					t.Errorf("got %v, want %v", got, want)
+				}
+			})
+
+			t.Run("CIOSWords", func(t *testing.T) {
+				t.Parallel()
+				m := NewMontgomeryCIOSWords(tc.R, tc.N)
+				if got := m.ReduceWide(tc.x); got.Cmp(want) != 0 {
+					t.Errorf("got %v, want %v", got, want)
+				}
+			})
+		})
+	}
+}
+
+func TestReduceWideProperty(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParams2048()
+	m := NewMontgomeryCIOSWords(R, N)
+
+	err := quick.Check(func(xBytes []byte, neg bool) bool {
+		// quick generates short slices; repeat them to reach several N widths
+		var buf []byte
+		for len(xBytes) > 0 && len(buf) < 16*256 {
+			buf = append(buf, xBytes...)
+		}
+		x := new(big.Int).SetBytes(buf)
+		if neg {
+			x.Neg(x)
+		}
+		return m.ReduceWide(x).Cmp(new(big.Int).Mod(x, N)) == 0
+	}, &quick.Config{MaxCount: 100})
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func BenchmarkReduceWide(b *testing.B) {
+	_, _, R, N := testParams2048()
+	x := new(big.Int).Lsh(big.NewInt(1), 16*2048)
+	x.Sub(x, big.NewInt(1))
+
+	b.Run("Montgomery/CIOSWords", func(b *testing.B) {
+		m := NewMontgomeryCIOSWords(R, N)
+		for b.Loop() {
+			m.ReduceWide(x)
+		}
+	})
+
+	b.Run("BigInt/Mod", func(b *testing.B) {
+		for b.Loop() {
+			new(big.Int).Mod(x, N)
+		}
+	})
+}
@@ -0,0 +1,181 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// maxWindow is the widest window WithWindowSize and NewOddPowers accept:
+// 2^16 table entries are already far past any speedup.
+const maxWindow = 16
+
+// WindowStrategy selects how Exp and ExpBatch split the exponent.
+type WindowStrategy uint8
+
+const (
+	// WindowFixed cuts the exponent into w-bit digits from a table of all
+	// 2^w powers, and is the default. Its sequence of squarings and
+	// multiplications depends only on the exponent's length and zero
+	// digits, which is what lets ExpBatch share it across bases.
+	WindowFixed WindowStrategy = iota
+	// WindowSliding lets windows start only at set bits, so that every
+	// digit is odd: the table holds the 2^(w-1) odd powers, and runs of
+	// zeros cost squarings only. It needs about n/(w+1) multiplications
+	// against n/w for a fixed window of the same width, and half the table.
+	WindowSliding
+)
+
+func (s WindowStrategy) String() string {
+	switch s {
+	case WindowFixed:
+		return "fixed"
+	case WindowSliding:
+		return "sliding"
+	}
+	return fmt.Sprintf("WindowStrategy(%d)", uint8(s))
+}
+
+// WithWindowStrategy sets how Exp and ExpBatch split the exponent; the
+// default is WindowFixed. ExpBatch keeps its fixed schedule either way.
+func WithWindowStrategy(s WindowStrategy) Option {
+	return func(c *config) {
+		c.window = s
+	}
+}
+
+// WithWindowSize fixes the window width of Exp and ExpBatch at k bits,
+// clamped to [1, 16], instead of choosing it from the exponent length. An
+// explicit width also disables the square-and-multiply fast path for sparse
+// exponents, so every exponentiation follows the chosen strategy. The
+// memory budget still takes precedence and may narrow the window. k ≤ 0
+// restores the automatic choice.
+func WithWindowSize(k int) Option {
+	return func(c *config) {
+		c.windowSize = min(max(k, 0), maxWindow)
+	}
+}
+
+// expSliding computes base^e mod N for e ≥ 0 with a sliding window over a
+// table of odd powers built for this call.
+func expSliding(eng engine, base, e *big.Int) *big.Int {
+	w := slidingWindow(eng, e)
+	return fromMontAcc(eng, expOdd(eng, oddPowers(eng, eng.redc(base, eng.rr), w), e, w))
+}
+
+// slidingWindow picks the sliding window width for e: WithWindowSize's, or
+// one more bit than a fixed window since the table is half the size, then
+// narrowed until the table fits the memory budget.
+func slidingWindow(eng engine, e *big.Int) int {
+	w := eng.window
+	if w == 0 {
+		w = windowSize(e.BitLen()) + 1
+	}
+	if eng.maxEntries > 0 {
+		for w > 1 && 1<<(w-1) > eng.maxEntries {
+			w--
+		}
+	}
+	return w
+}
+
+// oddPowers returns x, x³, …, x^(2^w - 1) for x in Montgomery form.
+func oddPowers(eng engine, x *big.Int, w int) []*big.Int {
+	table := make([]*big.Int, 1<<(w-1))
+	table[0] = x
+	if len(table) > 1 {
+		x2 := eng.sqr(new(big.Int), x)
+		for i := 1; i < len(table); i++ {
+			table[i] = eng.redc(table[i-1], x2)
+		}
+	}
+	return table
+}
+
+// expOdd returns x^e in Montgomery form, or nil for 1, from the odd powers
+// of x. Scanning from the top, a zero bit is one squaring; a set bit opens
+// a window reaching at most w bits down to the lowest set bit in reach, and
+// costs that many squarings and one multiplication by the window's odd
+// value.
+func expOdd(eng engine, table []*big.Int, e *big.Int, w int) *big.Int {
+	var acc *big.Int
+	for i := e.BitLen() - 1; i >= 0; {
+		auditBranch("expOdd: exponent bit", e)
+		if e.Bit(i) == 0 {
+			if acc != nil {
+				acc = eng.sqr(acc, acc)
+			}
+			i--
+			continue
+		}
+		j := max(i-w+1, 0)
+		for e.Bit(j) == 0 {
+			j++
+		}
+		var d uint
+		for b := i; b >= j; b-- {
+			d = d<<1 | e.Bit(b)
+			if acc != nil {
+				acc = eng.sqr(acc, acc)
+			}
+		}
+		acc = mulAcc(eng, acc, table[d>>1])
+		i = j - 1
+	}
+	return acc
+}
+
+// OddPowers is a table of the odd powers g, g³, …, g^(2^w - 1) of one base,
+// for exponentiating it repeatedly with a sliding window. Unlike FixedBase
+// it covers exponents of any size, works on every implementation and costs
+// only 2^(w-1) multiplications to build, but each Exp still squares once
+// per exponent bit.
+type OddPowers struct {
+	eng   engine
+	g     *big.Int
+	table []*big.Int
+	w     int
+}
+
+// NewOddPowers precomputes the odd powers of g for a window of w bits in
+// [1, 16], using bit-by-bit Montgomery reduction. See OddPowers.
+func (m *MontgomeryBitwise) NewOddPowers(g *big.Int, w int) *OddPowers {
+	return newOddPowers(m.engine(), g, w)
+}
+
+// NewOddPowers precomputes the odd powers of g for a window of w bits in
+// [1, 16], using CIOS Montgomery reduction. See OddPowers.
+func (m *MontgomeryCIOS) NewOddPowers(g *big.Int, w int) *OddPowers {
+	return newOddPowers(m.engine(), g, w)
+}
+
+// NewOddPowers precomputes the odd powers of g for a window of w bits in
+// [1, 16], using CIOS Montgomery reduction on []uint64 words. See
+// OddPowers.
+func (m *MontgomeryCIOSWords) NewOddPowers(g *big.Int, w int) *OddPowers {
+	return newOddPowers(m.engine(), g, w)
+}
+
+func newOddPowers(eng engine, g *big.Int, w int) *OddPowers {
+	if w < 1 || w > maxWindow {
+		panic(fmt.Sprintf("montgomery: odd-power window %d out of range [1, %d]", w, maxWindow))
+	}
+	g = new(big.Int).Set(eng.input.mustOperand(eng.n, "NewOddPowers", g))
+	return &OddPowers{eng: eng, g: g, table: oddPowers(eng, eng.redc(g, eng.rr), w), w: w}
+}
+
+// Base returns g reduced mod N.
+func (p *OddPowers) Base() *big.Int { return new(big.Int).Set(p.g) }
+
+// Window returns the window width the table was built for.
+func (p *OddPowers) Window() int { return p.w }
+
+// Exp returns g^e mod N. A negative e raises the inverse of g, or yields
+// nil if g is not invertible mod N, as with Exp. The table is only read,
+// so one OddPowers may serve concurrent calls.
+func (p *OddPowers) Exp(e *big.Int) *big.Int {
+	r := fromMontAcc(p.eng, expOdd(p.eng, p.table, new(big.Int).Abs(e), p.w))
+	if e.Sign() < 0 {
+		return r.ModInverse(r, p.eng.n)
+	}
+	return r
+}
@@ -0,0 +1,171 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestExp_windowOptions(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	ones := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 300), big.NewInt(1))
+	exps := []*big.Int{
+		big.NewInt(0), big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(65537),
+		new(big.Int).Lsh(big.NewInt(1), 1000), ones, y, new(big.Int).Sub(N, big.NewInt(1)),
+		big.NewInt(-5),
+	}
+	configs := []struct {
+		name string
+		opts []Option
+	}{
+		{"fixed/auto", nil},
+		{"fixed/w=1", []Option{WithWindowSize(1)}},
+		{"fixed/w=6", []Option{WithWindowSize(6)}},
+		{"sliding/auto", []Option{WithWindowStrategy(WindowSliding)}},
+		{"sliding/w=1", []Option{WithWindowStrategy(WindowSliding), WithWindowSize(1)}},
+		{"sliding/w=3", []Option{WithWindowStrategy(WindowSliding), WithWindowSize(3)}},
+		{"sliding/w=7", []Option{WithWindowStrategy(WindowSliding), WithWindowSize(7)}},
+		{"sliding/budget", []Option{WithWindowStrategy(WindowSliding), WithMemoryBudget(3 * 256)}},
+	}
+
+	for _, cfg := range configs {
+		for _, impl := range montContexts(R, N, cfg.opts...) {
+			t.Run(impl.name+"/"+cfg.name, func(t *testing.T) {
+				t.Parallel()
+				for _, e := range exps {
+					// The Bitwise reduction is slow; a few exponents suffice
+					if impl.name == "Bitwise" && e.BitLen() > 1000 {
+						continue
+					}
+					want := new(big.Int).Exp(x, e, N)
+					if got := impl.m.Exp(x, e); got.Cmp(want) != 0 {
+						t.Errorf("Exp(x, %v) = %v, want %v", e, got, want)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestWithWindowSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		k, want int
+	}{
+		{-3, 0}, {0, 0}, {1, 1}, {5, 5}, {16, 16}, {100, 16},
+	}
+	for _, tc := range tests {
+		if got := newConfig([]Option{WithWindowSize(tc.k)}).windowSize; got != tc.want {
+			t.Errorf("WithWindowSize(%d): window %d, want %d", tc.k, got, tc.want)
+		}
+	}
+}
+
+func TestWindowStrategy_String(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		s    WindowStrategy
+		want string
+	}{
+		{WindowFixed, "fixed"},
+		{WindowSliding, "sliding"},
+		{WindowStrategy(9), "WindowStrategy(9)"},
+	}
+	for _, tc := range tests {
+		if got := tc.s.String(); got != tc.want {
+			t.Errorf("String() = %q, want %q", got, tc.want)
+		}
+	}
+}
+
+func TestOddPowers(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParams2048()
+	exps := []*big.Int{
+		big.NewInt(0), big.NewInt(1), big.NewInt(6), big.NewInt(-7),
+		new(big.Int).Rsh(y, 1800), new(big.Int).Lsh(big.NewInt(3), 300),
+	}
+
+	for _, impl := range montContexts(R, N) {
+		for _, w := range []int{1, 2, 5} {
+			t.Run(fmt.Sprintf("%s/w=%d", impl.name, w), func(t *testing.T) {
+				t.Parallel()
+				p := impl.m.NewOddPowers(new(big.Int).Add(x, N), w)
+				if p.Base().Cmp(x) != 0 || p.Window() != w {
+					t.Errorf("Base(), Window() = %v, %d, want %v, %d", p.Base(), p.Window(), x, w)
+				}
+				for _, e := range exps {
+					want := new(big.Int).Exp(x, e, N)
+					if got := p.Exp(e); got.Cmp(want) != 0 {
+						t.Errorf("Exp(%v) = %v, want %v", e, got, want)
+					}
+				}
+			})
+		}
+	}
+
+	t.Run("not invertible", func(t *testing.T) {
+		t.Parallel()
+		p := NewMontgomeryCIOSWords(R, N).NewOddPowers(big.NewInt(0), 3)
+		if got := p.Exp(big.NewInt(-1)); got != nil {
+			t.Errorf("Exp(-1) of 0 = %v, want nil", got)
+		}
+	})
+
+	t.Run("window out of range", func(t *testing.T) {
+		t.Parallel()
+		m := NewMontgomeryCIOSWords(R, N)
+		for _, w := range []int{0, 17} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("NewOddPowers(g, %d) did not panic", w)
+					}
+				}()
+				m.NewOddPowers(x, w)
+			}()
+		}
+	})
+}
+
+// BenchmarkExpWindow compares the window strategies for a full-size
+// exponent, and the ways of exponentiating a fixed base with a 256-bit
+// exponent, as in Diffie-Hellman with a standard generator.
+func BenchmarkExpWindow(b *testing.B) {
+	x, y, R, N := testParams2048()
+	e := new(big.Int).Sub(N, big.NewInt(12345))
+
+	for _, s := range []WindowStrategy{WindowFixed, WindowSliding} {
+		m := NewMontgomeryCIOSWords(R, N, WithWindowStrategy(s))
+		b.Run(fmt.Sprintf("op=Exp/strategy=%v", s), func(b *testing.B) {
+			for b.Loop() {
+				m.Exp(x, e)
+			}
+		})
+	}
+
+	m := NewMontgomeryCIOSWords(R, N)
+	e256 := new(big.Int).Rsh(y, uint(y.BitLen()-256))
+	odd := m.NewOddPowers(x, 5)
+	fb := m.NewFixedBase(x, 256, 6)
+	b.Run("op=FixedBase/impl=Exp", func(b *testing.B) {
+		for b.Loop() {
+			m.Exp(x, e256)
+		}
+	})
+	b.Run("op=FixedBase/impl=OddPowers", func(b *testing.B) {
+		for b.Loop() {
+			odd.Exp(e256)
+		}
+	})
+	b.Run("op=FixedBase/impl=FixedBase", func(b *testing.B) {
+		for b.Loop() {
+			fb.Exp(e256)
+		}
+	})
+}
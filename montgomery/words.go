@@ -0,0 +1,172 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+)
+
+// The word-level API of MontgomeryCIOSWords works on operands held as
+// exactly S little-endian 64-bit limbs, the representation the CIOS kernel
+// uses internally, so a computation that keeps its values in limbs never
+// pays the frombigInt/tobigInt round-trips that dominate Mul for 256- and
+// 384-bit fields. Convert once with ToWords and back with FromWords.
+//
+// Operands must be in [0, N), and as at the big.Int entry points the
+// InputPolicy decides what happens to one that is not: the kernels would
+// otherwise return a value that is unreduced or simply wrong. The check is
+// a comparison with N from the top limb down, which almost always stops at
+// the first; a permissive reduction converts the operand to a big.Int and
+// divides. Outputs may be the same slice as any input (see alias.go), and
+// moduli of up to wordsStackLimbs limbs run without allocating operands in
+// range. Past the KAMM threshold (see WithKaratsuba) the
+// kernel is montMulWordsKaratsuba.
+
+// wordsStackLimbs is the largest S whose kernel scratch lives on the stack.
+const wordsStackLimbs = 16
+
+// ToWords returns x as S limbs. Operands outside [0, N) are handled by the
+// context's InputPolicy.
+func (m *MontgomeryCIOSWords) ToWords(x *big.Int) []uint64 {
+	return limbsPadded(m.cfg.input.mustOperand(m.N, "ToWords", x), m.S)
+}
+
+// FromWords returns the limbs x as a big.Int.
+func (m *MontgomeryCIOSWords) FromWords(x []uint64) *big.Int {
+	m.checkWords("FromWords", x)
+	return tobigInt(x)
+}
+
+// MulWords sets z = (x * y) mod N with two REDCs, since
+// REDC(REDC(x, y), R²) = x·y.
+func (m *MontgomeryCIOSWords) MulWords(z, x, y []uint64) {
+	m.checkWords("MulWords", z, x, y)
+	x, y = m.wordsOperand("MulWords", x), m.wordsOperand("MulWords", y)
+	if m.kamm {
+		t := make([]uint64, kammScratch(m.S))
+		montMulWordsKaratsuba(z, x, y, m.nw, m.NI, t)
+		montMulWordsKaratsuba(z, z, m.rrw, m.nw, m.NI, t)
+		return
+	}
+	var buf [wordsStackLimbs + 2]uint64
+	t := buf[:]
+	if m.S > wordsStackLimbs {
+		t = make([]uint64, m.S+2)
+	}
+	montMulWords(z, x, y, m.nw, m.NI, t)
+	montMulWords(z, z, m.rrw, m.nw, m.NI, t)
+}
+
+// MulMontWords sets z = (x * y * R⁻¹) mod N, the product of two values in
+// Montgomery form, with a single REDC.
+func (m *MontgomeryCIOSWords) MulMontWords(z, x, y []uint64) {
+	m.checkWords("MulMontWords", z, x, y)
+	m.mulMontWords(z, m.wordsOperand("MulMontWords", x), m.wordsOperand("MulMontWords", y))
+}
+
+// mulMontWords is MulMontWords on operands already checked.
+func (m *MontgomeryCIOSWords) mulMontWords(z, x, y []uint64) {
+	if m.kamm {
+		montMulWordsKaratsuba(z, x, y, m.nw, m.NI, make([]uint64, kammScratch(m.S)))
+		return
+	}
+	var buf [wordsStackLimbs + 2]uint64
+	t := buf[:]
+	if m.S > wordsStackLimbs {
+		t = make([]uint64, m.S+2)
+	}
+	montMulWords(z, x, y, m.nw, m.NI, t)
+}
+
+// ToMontWords sets z = x·R mod N, x in Montgomery form.
+func (m *MontgomeryCIOSWords) ToMontWords(z, x []uint64) {
+	m.checkWords("ToMontWords", z, x)
+	m.mulMontWords(z, m.wordsOperand("ToMontWords", x), m.rrw)
+}
+
+// FromMontWords sets z = x·R⁻¹ mod N, x out of Montgomery form.
+func (m *MontgomeryCIOSWords) FromMontWords(z, x []uint64) {
+	m.checkWords("FromMontWords", z, x)
+	m.mulMontWords(z, m.wordsOperand("FromMontWords", x), m.onew)
+}
+
+// AddWords sets z = (x + y) mod N. Addition is the same in and out of
+// Montgomery form, since x·R + y·R = (x+y)·R, so it serves both. Like the
+// CIOS kernel it always computes the subtraction of N and keeps the right
+// result by mask.
+func (m *MontgomeryCIOSWords) AddWords(z, x, y []uint64) {
+	m.checkWords("AddWords", z, x, y)
+	addModWords(z, m.wordsOperand("AddWords", x), m.wordsOperand("AddWords", y), m.nw)
+}
+
+// SubWords sets z = (x - y) mod N, in or out of Montgomery form as
+// AddWords.
+func (m *MontgomeryCIOSWords) SubWords(z, x, y []uint64) {
+	m.checkWords("SubWords", z, x, y)
+	subModWords(z, m.wordsOperand("SubWords", x), m.wordsOperand("SubWords", y), m.nw)
+}
+
+// NegWords sets z = -x mod N, in or out of Montgomery form as AddWords.
+func (m *MontgomeryCIOSWords) NegWords(z, x []uint64) {
+	m.checkWords("NegWords", z, x)
+	x = m.wordsOperand("NegWords", x)
+	var buf [wordsStackLimbs]uint64
+	zero := buf[:]
+	if m.S > wordsStackLimbs {
+		zero = make([]uint64, m.S)
+	}
+	subModWords(z, zero[:m.S], x, m.nw)
+}
+
+// addModWords sets z = x + y mod n for x, y < n of len(n) limbs. z may be
+// x or y.
+func addModWords(z, x, y, n []uint64) {
+	var c, b uint64
+	for i := range n {
+		z[i], c = bits.Add64(x[i], y[i], c)
+	}
+	// z - n, kept unless it borrows past the carry out of the sum
+	var buf [wordsStackLimbs]uint64
+	d := buf[:]
+	if len(n) > wordsStackLimbs {
+		d = make([]uint64, len(n))
+	}
+	for i := range n {
+		d[i], b = bits.Sub64(z[i], n[i], b)
+	}
+	keep := ctMask(c | (b ^ 1))
+	for i := range n {
+		z[i] = d[i]&keep | z[i]&^keep
+	}
+}
+
+// subModWords sets z = x - y mod n for x, y < n of len(n) limbs, adding n
+// back by mask. z may be x or y.
+func subModWords(z, x, y, n []uint64) {
+	var b, c uint64
+	for i := range n {
+		z[i], b = bits.Sub64(x[i], y[i], b)
+	}
+	mask := ctMask(b)
+	for i := range n {
+		z[i], c = bits.Add64(z[i], n[i]&mask, c)
+	}
+}
+
+// wordsOperand applies the InputPolicy to the S-limb operand x of op: x
+// itself if it is below N, else a reduced copy or a panic.
+func (m *MontgomeryCIOSWords) wordsOperand(op string, x []uint64) []uint64 {
+	if limbsLess(x, m.nw) {
+		return x
+	}
+	return limbsPadded(m.cfg.input.mustOperand(m.N, op, tobigInt(x)), m.S)
+}
+
+// checkWords panics unless every slice has exactly S limbs.
+func (m *MontgomeryCIOSWords) checkWords(op string, xs ...[]uint64) {
+	for _, x := range xs {
+		if len(x) != m.S {
+			panic(fmt.Sprintf("montgomery: %s: operand has %d limbs, want %d", op, len(x), m.S))
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package montgomery
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestMontgomeryCIOSWords_MulWords(t *testing.T) {
+	t.Parallel()
+
+	for _, bitSize := range []int{64, 256, 384, 1024, 2048} {
+		_, _, R, N := testParamsLarge(bitSize)
+		m := NewMontgomeryCIOSWords(R, N)
+		err := quick.Check(func(xBytes, yBytes []byte) bool {
+			x := new(big.Int).Mod(new(big.Int).SetBytes(xBytes), N)
+			y := new(big.Int).Mod(new(big.Int).SetBytes(yBytes), N)
+			want := new(big.Int).Mul(x, y)
+			want.Mod(want, N)
+
+			z := make([]uint64, m.S)
+			m.MulWords(z, m.ToWords(x), m.ToWords(y))
+
+			// The same product through Montgomery form
+			xm, ym := m.ToWords(x), m.ToWords(y)
+			m.ToMontWords(xm, xm)
+			m.ToMontWords(ym, ym)
+			m.MulMontWords(xm, xm, ym)
+			m.FromMontWords(xm, xm)
+
+			return m.FromWords(z).Cmp(want) == 0 && m.FromWords(xm).Cmp(want) == 0
+		}, &quick.Config{MaxCount: 50})
+		if err != nil {
+			t.Errorf("%d bits: %v", bitSize, err)
+		}
+	}
+}
+
+func TestMontgomeryCIOSWords_MulWordsAliasing(t *testing.T) {
+	t.Parallel()
+
+	x, y, R, N := testParamsLarge(256)
+	m := NewMontgomeryCIOSWords(R, N)
+	want := new(big.Int).Mul(x, y)
+	want.Mod(want, N)
+	wantSq := new(big.Int).Mul(x, x)
+	wantSq.Mod(wantSq, N)
+
+	tests := []struct {
+		name string
+		run  func(xs, ys []uint64) []uint64
+		want *big.Int
+	}{
+		{"z is x", func(xs, ys []uint64) []uint64 { m.MulWords(xs, xs, ys); return xs }, want},
+		{"z is y", func(xs, ys []uint64) []uint64 { m.MulWords(ys, xs, ys); return ys }, want},
+		{"z is x and y", func(xs, _ []uint64) []uint64 { m.MulWords(xs, xs, xs); return xs }, wantSq},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := m.FromWords(tc.run(m.ToWords(x), m.ToWords(y))); got.Cmp(tc.want) != 0 {
+				t.Errorf("MulWords() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMontgomeryCIOSWords_AddSubNegWords(t *testing.T) {
+	t.Parallel()
+
+	for _, bitSize := range []int{64, 256, 1088, 2048} {
+		_, _, R, N := testParamsLarge(bitSize)
+		// and an N just below R, where x + y overflows S limbs
+		for _, N := range []*big.Int{N, new(big.Int).Sub(R, big.NewInt(1))} {
+			m := NewMontgomeryCIOSWords(R, N)
+			check := func(x, y *big.Int, alias bool) bool {
+				ops := []struct {
+					name string
+					run  func(z, x, y []uint64) []uint64
+					want *big.Int
+				}{
+					{"AddWords", func(z, x, y []uint64) []uint64 { m.AddWords(z, x, y); return z }, new(big.Int).Add(x, y)},
+					{"SubWords", func(z, x, y []uint64) []uint64 { m.SubWords(z, x, y); return z }, new(big.Int).Sub(x, y)},
+					{"NegWords", func(z, x, _ []uint64) []uint64 { m.NegWords(z, x); return z }, new(big.Int).Neg(x)},
+				}
+				for _, op := range ops {
+					xs, ys := m.ToWords(x), m.ToWords(y)
+					z := make([]uint64, m.S)
+					if alias {
+						z = xs
+					}
+					if got := m.FromWords(op.run(z, xs, ys)); got.Cmp(op.want.Mod(op.want, N)) != 0 {
+						t.Errorf("%d bits: %s(%v, %v) = %v, want %v", bitSize, op.name, x, y, got, op.want)
+						return false
+					}
+				}
+				return true
+			}
+			nMinus1 := new(big.Int).Sub(N, big.NewInt(1))
+			for _, c := range [][2]*big.Int{{nMinus1, nMinus1}, {big.NewInt(0), nMinus1}, {nMinus1, big.NewInt(1)}, {big.NewInt(0), big.NewInt(0)}} {
+				check(c[0], c[1], false)
+				check(c[0], c[1], true)
+			}
+			err := quick.Check(func(xBytes, yBytes []byte, alias bool) bool {
+				x := new(big.Int).Mod(new(big.Int).SetBytes(xBytes), N)
+				y := new(big.Int).Mod(new(big.Int).SetBytes(yBytes), N)
+				return check(x, y, alias)
+			}, &quick.Config{MaxCount: 50})
+			if err != nil {
+				t.Errorf("%d bits: %v", bitSize, err)
+			}
+		}
+	}
+}
+
+func TestMontgomeryCIOSWords_wordsLength(t *testing.T) {
+	t.Parallel()
+
+	_, _, R, N := testParamsLarge(256)
+	m := NewMontgomeryCIOSWords(R, N)
+	short, ok := make([]uint64, m.S-1), make([]uint64, m.S)
+
+	tests := []struct {
+		name string
+		f    func()
+	}{
+		{"MulWords", func() { m.MulWords(ok, short, ok) }},
+		{"MulMontWords", func() { m.MulMontWords(short, ok, ok) }},
+		{"ToMontWords", func() { m.ToMontWords(ok, short) }},
+		{"FromMontWords", func() { m.FromMontWords(short, ok) }},
+		{"AddWords", func() { m.AddWords(ok, ok, short) }},
+		{"SubWords", func() { m.SubWords(short, ok, ok) }},
+		{"NegWords", func() { m.NegWords(ok, short) }},
+		{"FromWords", func() { m.FromWords(short) }},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s with a short operand did not panic", tc.name)
+				}
+			}()
+			tc.f()
+		})
+	}
+}
+
+func TestMontgomeryCIOSWords_wordsInputPolicy(t *testing.T) {
+	t.Parallel()
+
+	// N a little above R/4 leaves room for operands far past N, on which
+	// the kernel's single subtraction would not be enough
+	R := new(big.Int).Lsh(big.NewInt(1), 256)
+	N := new(big.Int).Rsh(R, 2)
+	N.Add(N, big.NewInt(1<<20+1))
+	rInv := new(big.Int).ModInverse(R, N)
+	m := NewMontgomeryCIOSWords(R, N)
+	strict := NewMontgomeryCIOSWords(R, N, WithInputPolicy(InputStrict))
+
+	ops := []struct {
+		name string
+		run  func(m *MontgomeryCIOSWords, z, x, y []uint64)
+		want func(x, y *big.Int) *big.Int
+	}{
+		{"MulWords", (*MontgomeryCIOSWords).MulWords, func(x, y *big.Int) *big.Int { return new(big.Int).Mul(x, y) }},
+		{"MulMontWords", (*MontgomeryCIOSWords).MulMontWords, func(x, y *big.Int) *big.Int { return new(big.Int).Mul(new(big.Int).Mul(x, y), rInv) }},
+		{"ToMontWords", func(m *MontgomeryCIOSWords, z, x, _ []uint64) { m.ToMontWords(z, x) }, func(x, _ *big.Int) *big.Int { return new(big.Int).Mul(x, R) }},
+		{"FromMontWords", func(m *MontgomeryCIOSWords, z, x, _ []uint64) { m.FromMontWords(z, x) }, func(x, _ *big.Int) *big.Int { return new(big.Int).Mul(x, rInv) }},
+	}
+	check := func(x, y *big.Int) bool {
+		for _, op := range ops {
+			xs, ys, z := limbsPadded(x, m.S), limbsPadded(y, m.S), make([]uint64, m.S)
+			op.run(m, z, xs, ys)
+			want := op.want(x, y)
+			if got := m.FromWords(z); got.Cmp(want.Mod(want, N)) != 0 {
+				t.Errorf("permissive %s(%v, %v) = %v, want %v", op.name, x, y, got, want)
+				return false
+			}
+			// Reduced copies leave the caller's operands alone
+			if tobigInt(xs).Cmp(x) != 0 || tobigInt(ys).Cmp(y) != 0 {
+				t.Errorf("permissive %s modified its operands", op.name)
+				return false
+			}
+			if x.Cmp(N) >= 0 && !panics(func() { op.run(strict, z, xs, ys) }) {
+				t.Errorf("strict %s(%v, %v) did not panic with ErrOperandRange", op.name, x, y)
+				return false
+			}
+		}
+		return true
+	}
+	rMinus1 := new(big.Int).Sub(R, big.NewInt(1))
+	check(rMinus1, rMinus1)
+	check(N, big.NewInt(1))
+	err := quick.Check(func(xBytes, yBytes [32]byte) bool {
+		return check(new(big.Int).SetBytes(xBytes[:]), new(big.Int).SetBytes(yBytes[:]))
+	}, &quick.Config{MaxCount: 50})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// FuzzMulWords compares the reduction strategies of MontgomeryCIOSWords
+// and MontgomeryCT with math/big on odd moduli and operands from the
+// fuzzer's bytes, big-endian, through both the word-level API and Mul on
+// big.Ints of every length. The differential check across all backends is
+// vaulttest.FuzzDifferential.
+//
+//	go test -run '^$' -fuzz FuzzMulWords
+func FuzzMulWords(f *testing.F) {
+	f.Add([]byte{3}, []byte{2}, []byte{2})
+	f.Add([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, []byte{1, 0, 0, 0, 0, 0, 0, 0, 0}, []byte{0xff})
+	_, _, _, N := testParamsLarge(512)
+	f.Add(N.Bytes(), new(big.Int).Sub(N, big.NewInt(1)).Bytes(), []byte{1})
+	f.Fuzz(func(t *testing.T, nBytes, xBytes, yBytes []byte) {
+		if len(nBytes) > 256 {
+			t.Skip()
+		}
+		N := new(big.Int).SetBytes(nBytes)
+		N.SetBit(N, 0, 1)
+		if N.BitLen() < 2 {
+			t.Skip()
+		}
+		R := wordAlignedR(N, nil)
+		x := new(big.Int).Mod(new(big.Int).SetBytes(xBytes), N)
+		y := new(big.Int).Mod(new(big.Int).SetBytes(yBytes), N)
+		want := new(big.Int).Mul(x, y)
+		want.Mod(want, N)
+
+		type wordsMultiplier interface {
+			ModMultiplier
+			MulWords(z, x, y []uint64)
+		}
+		for name, m := range map[string]wordsMultiplier{
+			"interleaved": NewMontgomeryCIOSWords(R, N, WithReduction(ReductionMontgomery), WithKaratsuba(0)),
+			"separated":   NewMontgomeryCIOSWords(R, N, WithReduction(ReductionMontgomerySeparated)),
+			"karatsuba":   NewMontgomeryCIOSWords(R, N, WithKaratsuba(1)),
+			"ct":          NewMontgomeryCT(R, N),
+		} {
+			if got := m.Mul(x, y); got.Cmp(want) != 0 {
+				t.Errorf("%s: N = %v: Mul(%v, %v) = %v, want %v", name, N, x, y, got, want)
+			}
+			s := (N.BitLen() + 63) / 64
+			z := make([]uint64, s)
+			m.MulWords(z, limbsPadded(x, s), limbsPadded(y, s))
+			if got := tobigInt(z); got.Cmp(want) != 0 {
+				t.Errorf("%s: N = %v: MulWords(%v, %v) = %v, want %v", name, N, x, y, got, want)
+			}
+		}
+	})
+}
+
+func BenchmarkMulWords(b *testing.B) {
+	for _, bitSize := range []int{256, 384, 2048} {
+		x, y, R, N := testParamsLarge(bitSize)
+		m := NewMontgomeryCIOSWords(R, N)
+		xs, ys := m.ToWords(x), m.ToWords(y)
+		z := make([]uint64, m.S)
+
+		b.Run(fmt.Sprintf("bits=%d/api=words", bitSize), func(b *testing.B) {
+			for b.Loop() {
+				m.MulWords(z, xs, ys)
+			}
+		})
+		b.Run(fmt.Sprintf("bits=%d/api=bigint", bitSize), func(b *testing.B) {
+			for b.Loop() {
+				m.Mul(x, y)
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package pollard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+)
+
+var (
+	// ErrNotPrime is returned by NewLogTable when p is not an odd prime.
+	ErrNotPrime = errors.New("pollard: p is not an odd prime")
+	// ErrNotGenerator is returned by NewLogTable when g does not generate
+	// Z_p^*.
+	ErrNotGenerator = errors.New("pollard: g does not generate Z_p^*")
+)
+
+// LogTable answers discrete logarithms to a fixed base g in Z_p^* by index
+// calculus, for primes p below 2^64.
+//
+// NewLogTable does the expensive part once. It collects relations
+// g^k ≡ Π pⱼ^eⱼ (mod p) over a factor base of the small primes pⱼ ≤ B,
+// that is k ≡ Σ eⱼ·log pⱼ (mod p-1), and solves that linear system for
+// the logs of the factor base modulo each prime power of p-1, joining them
+// by the CRT. Each Log query then only looks for one k with h·g^k smooth,
+// and reads off log h = Σ eⱼ·log pⱼ - k.
+//
+// A Pohlig–Hellman solver for an order-q subgroup generated by
+// γ = g^((p-1)/q) gets its leaf logs from the same table:
+// log_γ(h) = Log(h)/((p-1)/q) for h in that subgroup.
+//
+// A LogTable is immutable and safe for concurrent use.
+type LogTable struct {
+	p, g  uint64
+	base  []uint64 // the factor base, the primes up to B
+	logs  []uint64 // log_g of each base prime, mod p-1
+	order uint64   // p-1
+}
+
+// relation is g^k ≡ Π base[j]^e[j] (mod p).
+type relation struct {
+	k uint64
+	e []uint64
+}
+
+// NewLogTable precomputes the logs of a factor base to the base g mod p. p
+// must be an odd prime (ErrNotPrime) and g a generator of Z_p^*
+// (ErrNotGenerator); checking g needs the prime factors of p-1, which
+// NewLogTable finds with rho itself.
+//
+// The factor base holds the primes up to B = exp(√(ln p · ln ln p / 2)),
+// the usual balance between how rarely a residue is B-smooth and how many
+// relations a larger base needs. Precomputation takes about 25 ms for a
+// 32-bit p and 1.6 s at 48 bits, growing subexponentially.
+func NewLogTable(p, g uint64) (*LogTable, error) {
+	return NewLogTableContext(context.Background(), p, g)
+}
+
+// NewLogTableContext is NewLogTable with cancellation, for the larger p
+// where precomputation runs for minutes. ctx is checked every
+// progressInterval candidate relations and before each solve, and a
+// cancelled build returns ctx.Err().
+func NewLogTableContext(ctx context.Context, p, g uint64) (*LogTable, error) {
+	if p < 3 || p%2 == 0 || !new(big.Int).SetUint64(p).ProbablyPrime(20) {
+		return nil, ErrNotPrime
+	}
+	order := p - 1
+	factors := factorUint64(order)
+	g %= p
+	if g == 0 {
+		return nil, ErrNotGenerator
+	}
+	for _, f := range factors {
+		if powMod(g, order/f.q, p) == 1 {
+			return nil, ErrNotGenerator
+		}
+	}
+
+	lp := math.Log(float64(p))
+	bound := uint64(math.Exp(math.Sqrt(lp * math.Log(lp) / 2)))
+	t := &LogTable{p: p, g: g, base: primesUpTo(min(max(bound, 16), p-1)), order: order}
+
+	// Every relation carries a unit pivot for some column only with luck,
+	// so collect a margin over the base size and more on failure.
+	var rels []relation
+	k, y := uint64(0), uint64(1)
+	for want := len(t.base) + 20; ; want += len(t.base)/2 + 10 {
+		for len(rels) < want {
+			k++
+			if k%progressInterval == 0 {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+			}
+			y = mulMod(y, g, p)
+			if e, ok := t.factorSmooth(y); ok {
+				rels = append(rels, relation{k, e})
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if t.solve(rels, factors) {
+			return t, nil
+		}
+		if len(rels) > 8*len(t.base)+100 {
+			return nil, fmt.Errorf("%w: the relations do not determine the factor base", ErrNoLog)
+		}
+	}
+}
+
+// Log returns x in [0, p-1) with g^x ≡ h (mod p), or ErrNoLog if h is a
+// multiple of p. It tries h·g^k for k = 0, 1, … until one is smooth over
+// the factor base, which takes about as many tries as one relation did.
+func (t *LogTable) Log(h uint64) (uint64, error) {
+	return t.LogContext(context.Background(), h)
+}
+
+// LogContext is Log with cancellation: ctx is checked every
+// progressInterval tries, and a cancelled query returns ctx.Err().
+func (t *LogTable) LogContext(ctx context.Context, h uint64) (uint64, error) {
+	y := h % t.p
+	if y == 0 {
+		return 0, ErrNoLog
+	}
+	for k := uint64(0); ; k++ {
+		if k%progressInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return 0, err
+			}
+		}
+		if e, ok := t.factorSmooth(y); ok {
+			x := t.order - k%t.order
+			for j, ej := range e {
+				if ej != 0 {
+					x = addMod(x, mulMod(ej, t.logs[j], t.order), t.order)
+				}
+			}
+			return x % t.order, nil
+		}
+		y = mulMod(y, t.g, t.p)
+	}
+}
+
+// FactorBase returns the primes whose logs the table holds.
+func (t *LogTable) FactorBase() []uint64 {
+	return append([]uint64(nil), t.base...)
+}
+
+// factorSmooth returns the exponents of y over the factor base, or false if
+// y has a prime factor beyond it.
+func (t *LogTable) factorSmooth(y uint64) ([]uint64, bool) {
+	var e []uint64
+	for j, q := range t.base {
+		if y == 1 {
+			break
+		}
+		for y%q == 0 {
+			if e == nil {
+				e = make([]uint64, len(t.base))
+			}
+			e[j]++
+			y /= q
+		}
+	}
+	if y != 1 {
+		return nil, false
+	}
+	if e == nil {
+		e = make([]uint64, len(t.base))
+	}
+	return e, true
+}
+
+// solve sets t.logs from the relations, solving them modulo each prime
+// power of p-1 and recombining by the CRT. It reports false if some prime
+// power leaves a log undetermined, or a solution fails the check
+// g^log ≡ pⱼ.
+func (t *LogTable) solve(rels []relation, factors []primePower) bool {
+	x, mod := make([]*big.Int, len(t.base)), big.NewInt(1)
+	for j := range x {
+		x[j] = new(big.Int)
+	}
+	u := new(big.Int)
+	for _, f := range factors {
+		m := uint64(1)
+		for range f.e {
+			m *= f.q
+		}
+		r, ok := solveModPrimePower(rels, len(t.base), f.q, m)
+		if !ok {
+			return false
+		}
+		// x += mod · ((r - x)·mod⁻¹ mod m)
+		bm := new(big.Int).SetUint64(m)
+		inv := new(big.Int).ModInverse(mod, bm)
+		for j := range x {
+			u.Sub(u.SetUint64(r[j]), x[j])
+			u.Mul(u, inv).Mod(u, bm)
+			x[j].Add(x[j], u.Mul(u, mod))
+		}
+		mod.Mul(mod, bm)
+	}
+
+	t.logs = make([]uint64, len(t.base))
+	for j, q := range t.base {
+		t.logs[j] = x[j].Uint64()
+		if powMod(t.g, t.logs[j], t.p) != q {
+			return false
+		}
+	}
+	return true
+}
+
+// solveModPrimePower solves Σ e[j]·x[j] ≡ k (mod m), m = q^e, for the n
+// unknowns by Gauss–Jordan elimination. Z/q^e is not a field, so a pivot
+// must be a unit, an entry not divisible by q; it reports false when some
+// column has none.
+func solveModPrimePower(rels []relation, n int, q, m uint64) ([]uint64, bool) {
+	a := make([][]uint64, len(rels))
+	for i, r := range rels {
+		row := make([]uint64, n+1)
+		for j, e := range r.e {
+			row[j] = e % m
+		}
+		row[n] = r.k % m
+		a[i] = row
+	}
+
+	bm := new(big.Int).SetUint64(m)
+	for c := range n {
+		pivot := -1
+		for i := c; i < len(a); i++ {
+			if a[i][c]%q != 0 {
+				pivot = i
+				break
+			}
+		}
+		if pivot < 0 {
+			return nil, false
+		}
+		a[c], a[pivot] = a[pivot], a[c]
+
+		inv := new(big.Int).ModInverse(new(big.Int).SetUint64(a[c][c]), bm).Uint64()
+		for j := c; j <= n; j++ {
+			a[c][j] = mulMod(a[c][j], inv, m)
+		}
+		for i := range a {
+			if i == c || a[i][c] == 0 {
+				continue
+			}
+			f := a[i][c]
+			for j := c; j <= n; j++ {
+				a[i][j] = subMod(a[i][j], mulMod(f, a[c][j], m), m)
+			}
+		}
+	}
+
+	x := make([]uint64, n)
+	for j := range x {
+		x[j] = a[j][n]
+	}
+	return x, true
+}
+
+// primePower is q^e in a factorization.
+type primePower struct {
+	q uint64
+	e int
+}
+
+// factorUint64 returns the factorization of n ≥ 2: trial division up to
+// 2^16, then Floyd's rho for a composite cofactor, whose factors are all
+// larger.
+func factorUint64(n uint64) []primePower {
+	var fs []primePower
+	for d := uint64(2); d < 1<<16 && d*d <= n; d++ {
+		if n%d != 0 {
+			continue
+		}
+		f := primePower{q: d}
+		for n%d == 0 {
+			n /= d
+			f.e++
+		}
+		fs = append(fs, f)
+	}
+
+	var split func(n *big.Int)
+	split = func(n *big.Int) {
+		if n.ProbablyPrime(20) {
+			for i := range fs {
+				if fs[i].q == n.Uint64() {
+					fs[i].e++
+					return
+				}
+			}
+			fs = append(fs, primePower{q: n.Uint64(), e: 1})
+			return
+		}
+		for c := int64(1); ; c++ {
+			if d := inner_floydo(n, big.NewInt(c)); d.Cmp(n) != 0 {
+				split(d)
+				split(new(big.Int).Quo(n, d))
+				return
+			}
+		}
+	}
+	if n > 1 {
+		split(new(big.Int).SetUint64(n))
+	}
+	return fs
+}
+
+// primesUpTo returns the primes up to b by the sieve of Eratosthenes.
+func primesUpTo(b uint64) []uint64 {
+	composite := make([]bool, b+1)
+	var ps []uint64
+	for i := uint64(2); i <= b; i++ {
+		if composite[i] {
+			continue
+		}
+		ps = append(ps, i)
+		for j := i * i; j <= b; j += i {
+			composite[j] = true
+		}
+	}
+	return ps
+}
+
+func mulMod(a, b, m uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	_, r := bits.Div64(hi%m, lo, m)
+	return r
+}
+
+func addMod(a, b, m uint64) uint64 {
+	s, carry := bits.Add64(a, b, 0)
+	if carry != 0 || s >= m {
+		s -= m
+	}
+	return s
+}
+
+func subMod(a, b, m uint64) uint64 {
+	d, borrow := bits.Sub64(a, b, 0)
+	if borrow != 0 {
+		d += m
+	}
+	return d
+}
+
+func powMod(x, e, m uint64) uint64 {
+	r := uint64(1) % m
+	for ; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			r = mulMod(r, x, m)
+		}
+		x = mulMod(x, x, m)
+	}
+	return r
+}
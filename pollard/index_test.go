@@ -0,0 +1,220 @@
+package pollard
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"testing"
+)
+
+// testPrimitiveRoot returns the smallest prime p ≥ 2^bits with the smallest
+// generator g of Z_p^*.
+func testPrimitiveRoot(t testing.TB, bits int) (p, g uint64) {
+	t.Helper()
+	p = 1<<bits + 1
+	for !new(big.Int).SetUint64(p).ProbablyPrime(20) {
+		p += 2
+	}
+	factors := factorUint64(p - 1)
+	for g = 2; ; g++ {
+		if !slices.ContainsFunc(factors, func(f primePower) bool { return powMod(g, (p-1)/f.q, p) == 1 }) {
+			return p, g
+		}
+	}
+}
+
+func TestLogTable(t *testing.T) {
+	t.Parallel()
+
+	for _, bits := range []int{4, 16, 24, 32, 40} {
+		t.Run(fmt.Sprintf("bits=%d", bits), func(t *testing.T) {
+			t.Parallel()
+			p, g := testPrimitiveRoot(t, bits)
+			table, err := NewLogTable(p, g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, x := range []uint64{0, 1, 2, 12345, p / 3, p - 2} {
+				x %= p - 1
+				h := powMod(g, x, p)
+				got, err := table.Log(h)
+				if err != nil {
+					t.Fatalf("Log(%d) error = %v", h, err)
+				}
+				if got != x {
+					t.Errorf("Log(g^%d) = %d", x, got)
+				}
+			}
+			// h ≥ p is reduced first
+			if got, err := table.Log(p + g); err != nil || got != 1 {
+				t.Errorf("Log(p+g) = %d, %v, want 1", got, err)
+			}
+		})
+	}
+}
+
+func TestLogTable_exhaustive(t *testing.T) {
+	t.Parallel()
+
+	p, g := testPrimitiveRoot(t, 12)
+	table, err := NewLogTable(p, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := uint64(1)
+	for x := range p - 1 {
+		if got, err := table.Log(h); err != nil || got != x {
+			t.Fatalf("Log(g^%d) = %d, %v", x, got, err)
+		}
+		h = mulMod(h, g, p)
+	}
+}
+
+func TestNewLogTable_errors(t *testing.T) {
+	t.Parallel()
+
+	p, g := testPrimitiveRoot(t, 20)
+	tests := []struct {
+		name    string
+		p, g    uint64
+		wantErr error
+	}{
+		{name: "composite", p: 1001, g: 2, wantErr: ErrNotPrime},
+		{name: "even", p: 2, g: 1, wantErr: ErrNotPrime},
+		{name: "zero", p: 0, g: 1, wantErr: ErrNotPrime},
+		{name: "g = 0", p: p, g: 0, wantErr: ErrNotGenerator},
+		{name: "g = p", p: p, g: p, wantErr: ErrNotGenerator},
+		{name: "g = 1", p: p, g: 1, wantErr: ErrNotGenerator},
+		{name: "square", p: p, g: mulMod(g, g, p), wantErr: ErrNotGenerator},
+		{name: "valid", p: p, g: g},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := NewLogTable(tc.p, tc.g); !errors.Is(err, tc.wantErr) {
+				t.Errorf("NewLogTable() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLogTable_Log_errors(t *testing.T) {
+	t.Parallel()
+
+	p, g := testPrimitiveRoot(t, 20)
+	table, err := NewLogTable(p, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range []uint64{0, p, 3 * p} {
+		if _, err := table.Log(h); !errors.Is(err, ErrNoLog) {
+			t.Errorf("Log(%d) error = %v, want %v", h, err, ErrNoLog)
+		}
+	}
+}
+
+func TestLogTable_context(t *testing.T) {
+	t.Parallel()
+
+	p, g := testPrimitiveRoot(t, 20)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewLogTableContext(ctx, p, g); !errors.Is(err, context.Canceled) {
+		t.Errorf("NewLogTableContext error = %v, want %v", err, context.Canceled)
+	}
+	table, err := NewLogTableContext(context.Background(), p, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := table.LogContext(ctx, 5); !errors.Is(err, context.Canceled) {
+		t.Errorf("LogContext error = %v, want %v", err, context.Canceled)
+	}
+	x, err := table.LogContext(context.Background(), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := powMod(g, x, p); got != 5 {
+		t.Errorf("g^LogContext(5) = %d, want 5", got)
+	}
+}
+
+// TestLogTable_subgroup checks the Pohlig–Hellman leaf use: logs in an
+// order-q subgroup from the logs to a generator of the whole group.
+func TestLogTable_subgroup(t *testing.T) {
+	t.Parallel()
+
+	p, g := testPrimitiveRoot(t, 32)
+	table, err := NewLogTable(p, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range factorUint64(p - 1) {
+		gamma := powMod(g, (p-1)/f.q, p)
+		for _, x := range []uint64{0, 1, f.q - 1} {
+			l, err := table.Log(powMod(gamma, x, p))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := l / ((p - 1) / f.q); got != x {
+				t.Errorf("q = %d: log_γ(γ^%d) = %d", f.q, x, got)
+			}
+		}
+	}
+}
+
+func Test_factorUint64(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		n    uint64
+		want []primePower
+	}{
+		{n: 2, want: []primePower{{2, 1}}},
+		{n: 360, want: []primePower{{2, 3}, {3, 2}, {5, 1}}},
+		{n: 1<<61 - 1, want: []primePower{{1<<61 - 1, 1}}},
+		{n: 4294967291 * 4294967279, want: []primePower{{4294967279, 1}, {4294967291, 1}}},
+		{n: 8 * 1000003 * 1000003, want: []primePower{{2, 3}, {1000003, 2}}},
+		{n: 1<<64 - 1, want: []primePower{{3, 1}, {5, 1}, {17, 1}, {257, 1}, {641, 1}, {65537, 1}, {6700417, 1}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(fmt.Sprint(tc.n), func(t *testing.T) {
+			t.Parallel()
+			got := factorUint64(tc.n)
+			slices.SortFunc(got, func(a, b primePower) int { return cmp.Compare(a.q, b.q) })
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("factorUint64(%d) = %v, want %v", tc.n, got, tc.want)
+			}
+		})
+	}
+}
+
+// BenchmarkLogTable measures the precomputation and a single query.
+func BenchmarkLogTable(b *testing.B) {
+	for _, bits := range []int{24, 32, 40} {
+		p, g := testPrimitiveRoot(b, bits)
+		b.Run(fmt.Sprintf("op=NewLogTable/bits=%d", bits), func(b *testing.B) {
+			for b.Loop() {
+				if _, err := NewLogTable(p, g); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		table, err := NewLogTable(p, g)
+		if err != nil {
+			b.Fatal(err)
+		}
+		h := powMod(g, p/3, p)
+		b.Run(fmt.Sprintf("op=Log/bits=%d", bits), func(b *testing.B) {
+			for b.Loop() {
+				table.Log(h)
+				h = mulMod(h, g, p)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package pollard
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+	"sync"
+)
+
+// ErrNoLog is returned by LogParallel when h is not a power of g.
+var ErrNoLog = errors.New("pollard: no discrete logarithm found")
+
+// FactorParallel is FactorContext with the rho walks spread over workers
+// goroutines.
+//
+// Every worker runs its own Floyd walk with a fresh constant c drawn from
+// random (draws are serialized, so the set of constants is still determined
+// by the source). The first nontrivial factor cancels the remaining walkers,
+// and cancelling ctx stops all of them within progressInterval iterations.
+//
+// Factorization walkers are independent rather than sharing distinguished
+// points: a collision mod the unknown prime p is invisible in the walk values
+// mod n, so walkers could not recognise each other's points. LogParallel,
+// where values are compared exactly, does share them.
+func FactorParallel(ctx context.Context, random io.Reader, n *big.Int, workers int) (*big.Int, error) {
+	if n.Cmp(big.NewInt(4)) < 0 || n.ProbablyPrime(20) {
+		return nil, ErrNoFactor
+	}
+	if n.Bit(0) == 0 {
+		return new(big.Int).Set(two), nil
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		attempts int
+		factor   *big.Int
+		firstErr error
+	)
+	// next draws the constant for a new walk, or nil once the attempts
+	// are used up or the search is over.
+	next := func() *big.Int {
+		mu.Lock()
+		defer mu.Unlock()
+		if attempts >= maxAttempts || factor != nil || firstErr != nil {
+			return nil
+		}
+		attempts++
+		c, err := rand.Int(random, new(big.Int).Sub(n, three))
+		if err != nil {
+			firstErr = err
+			cancel()
+			return nil
+		}
+		return c.Add(c, one)
+	}
+
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Go(func() {
+			var iterations uint64
+			for c := next(); c != nil; c = next() {
+				d, err := floydoContext(ctx, n, c, nil, &iterations)
+				if err != nil {
+					return
+				}
+				if d.Cmp(n) != 0 {
+					mu.Lock()
+					if factor == nil {
+						factor = d
+					}
+					mu.Unlock()
+					cancel()
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	switch {
+	case factor != nil:
+		return factor, nil
+	case firstErr != nil:
+		return nil, firstErr
+	case parent.Err() != nil:
+		return nil, parent.Err()
+	}
+	return nil, ErrNoFactor
+}
+
+// rhoPartitions is the number of precomputed multipliers of the r-adding
+// walk used by LogParallel. Teske showed r = 16..20 behaves like a random walk.
+const rhoPartitions = 16
+
+// dlogWalk is a point y = g^a * h^b of a discrete-log rho walk.
+type dlogWalk struct {
+	y, a, b *big.Int
+}
+
+// dpStore is the distinguished-point store shared by all LogParallel walkers.
+type dpStore struct {
+	mu     sync.Mutex
+	points map[string]dlogWalk
+}
+
+// add records w and returns a previously stored walk that reached the
+// same distinguished point, if any.
+func (s *dpStore) add(w dlogWalk) (dlogWalk, bool) {
+	key := w.y.Text(16)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if other, ok := s.points[key]; ok {
+		return other, true
+	}
+	s.points[key] = w
+	return dlogWalk{}, false
+}
+
+// LogParallel returns x in [0, q) with g^x ≡ h (mod p), where g generates a
+// subgroup of prime order q of Z_p^*, using parallel Pollard rho with
+// distinguished points (van Oorschot–Wiener).
+//
+// Each of the workers goroutines runs an r-adding walk y → y * M_s(y) from a
+// random start g^a * h^b and stops at the first distinguished point, one
+// whose low bits are zero. Distinguished points go into a shared store; when
+// two walks with different b reach the same point, their exponents give the
+// logarithm. The expected total work is about sqrt(πq/2) steps split over
+// the workers, and cancelling ctx stops all walkers.
+//
+// If h is not in the subgroup generated by g the search runs until ctx is
+// done; callers should bound it with a deadline.
+func LogParallel(ctx context.Context, random io.Reader, g, h, p, q *big.Int, workers int) (*big.Int, error) {
+	if new(big.Int).Exp(g, q, p).Cmp(one) != 0 || new(big.Int).Exp(h, q, p).Cmp(one) != 0 {
+		return nil, ErrNoLog
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	randomExp := func() (*big.Int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return rand.Int(random, q)
+	}
+	point := func(a, b *big.Int) *big.Int {
+		y := new(big.Int).Exp(g, a, p)
+		return y.Mul(y, new(big.Int).Exp(h, b, p)).Mod(y, p)
+	}
+
+	// Walk multipliers M_j = g^α_j * h^β_j
+	type multiplier struct{ m, alpha, beta *big.Int }
+	var mults [rhoPartitions]multiplier
+	for j := range mults {
+		alpha, err := randomExp()
+		if err != nil {
+			return nil, err
+		}
+		beta, err := randomExp()
+		if err != nil {
+			return nil, err
+		}
+		mults[j] = multiplier{point(alpha, beta), alpha, beta}
+	}
+
+	// About one point in 2^dpBits is distinguished; walks that exceed 20
+	// times that length are assumed stuck in a cycle and restarted.
+	dpBits := uint(min(max(q.BitLen()/4, 0), 16))
+	maxSteps := 20 << dpBits
+	store := &dpStore{points: make(map[string]dlogWalk)}
+
+	var (
+		result   *big.Int
+		firstErr error
+	)
+	finish := func(x *big.Int, err error) {
+		mu.Lock()
+		if result == nil && firstErr == nil {
+			result, firstErr = x, err
+		}
+		mu.Unlock()
+		cancel()
+	}
+
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Go(func() {
+			for ctx.Err() == nil {
+				a, err := randomExp()
+				if err != nil {
+					finish(nil, err)
+					return
+				}
+				b, err := randomExp()
+				if err != nil {
+					finish(nil, err)
+					return
+				}
+				w := dlogWalk{point(a, b), a, b}
+
+				for step := 0; step < maxSteps; step++ {
+					if step%progressInterval == 0 && ctx.Err() != nil {
+						return
+					}
+					if w.y.TrailingZeroBits() >= dpBits {
+						if x, ok := collide(store, w, q); ok {
+							finish(x, nil)
+							return
+						}
+						break
+					}
+					mj := mults[new(big.Int).Rsh(w.y, dpBits).Uint64()%rhoPartitions]
+					w.y.Mul(w.y, mj.m).Mod(w.y, p)
+					w.a.Add(w.a, mj.alpha).Mod(w.a, q)
+					w.b.Add(w.b, mj.beta).Mod(w.b, q)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	if result != nil || firstErr != nil {
+		return result, firstErr
+	}
+	return nil, ctx.Err()
+}
+
+// collide stores the distinguished walk w and, if another walk already
+// reached the same point with a different b, solves
+// g^a * h^b = g^a' * h^b' for x = (a' - a) / (b - b') mod q.
+func collide(store *dpStore, w dlogWalk, q *big.Int) (*big.Int, bool) {
+	other, ok := store.add(w)
+	if !ok {
+		return nil, false
+	}
+	db := new(big.Int).Sub(w.b, other.b)
+	db.Mod(db, q)
+	if db.Sign() == 0 {
+		return nil, false
+	}
+	x := new(big.Int).Sub(other.a, w.a)
+	x.Mul(x, db.ModInverse(db, q))
+	return x.Mod(x, q), true
+}
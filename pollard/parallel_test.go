@@ -0,0 +1,172 @@
+package pollard
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestFactorParallel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		n       *big.Int
+		workers int
+		wantErr error
+	}{
+		{name: "8051 one worker", n: big.NewInt(8051), workers: 1},
+		{name: "8051", n: big.NewInt(8051), workers: 4},
+		{name: "even", n: big.NewInt(1 << 20), workers: 4},
+		{name: "31-bit primes", n: new(big.Int).Mul(big.NewInt(2147483647), big.NewInt(2147483629)), workers: 4},
+		{name: "zero workers", n: big.NewInt(1000003 * 999983), workers: 0},
+		{name: "prime", n: big.NewInt(1223), workers: 4, wantErr: ErrNoFactor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			d, err := FactorParallel(context.Background(), testRandom(3), tt.n, tt.workers)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FactorParallel(%v) error = %v, want %v", tt.n, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if d.Cmp(one) <= 0 || d.Cmp(tt.n) >= 0 || new(big.Int).Mod(tt.n, d).Sign() != 0 {
+				t.Errorf("FactorParallel(%v) = %v, want a nontrivial factor", tt.n, d)
+			}
+		})
+	}
+}
+
+func TestFactorParallel_cancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n := new(big.Int).Mul(big.NewInt(2147483647), big.NewInt(2147483629))
+	if _, err := FactorParallel(ctx, testRandom(3), n, 4); !errors.Is(err, context.Canceled) {
+		t.Errorf("FactorParallel() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+// testSafePrime returns p = 2q+1 with p and q prime and p ≥ 2^bits, together
+// with q and g = 4, a generator of the order-q subgroup of quadratic residues.
+func testSafePrime(t testing.TB, bits int) (p, q, g *big.Int) {
+	t.Helper()
+	q = new(big.Int).Lsh(one, uint(bits-1))
+	for {
+		q.Add(q, one)
+		if !q.ProbablyPrime(20) {
+			continue
+		}
+		p = new(big.Int).Lsh(q, 1)
+		p.Add(p, one)
+		if p.ProbablyPrime(20) {
+			return p, q, big.NewInt(4)
+		}
+	}
+}
+
+func TestLogParallel(t *testing.T) {
+	t.Parallel()
+
+	p, q, g := testSafePrime(t, 28)
+	tests := []struct {
+		name    string
+		x       *big.Int
+		workers int
+	}{
+		{name: "zero", x: big.NewInt(0), workers: 4},
+		{name: "one", x: big.NewInt(1), workers: 4},
+		{name: "one worker", x: big.NewInt(123456789), workers: 1},
+		{name: "four workers", x: big.NewInt(98765432), workers: 4},
+		{name: "q-1", x: new(big.Int).Sub(q, one), workers: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := new(big.Int).Exp(g, tt.x, p)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			got, err := LogParallel(ctx, testRandom(7), g, h, p, q, tt.workers)
+			if err != nil {
+				t.Fatalf("LogParallel() error = %v", err)
+			}
+			if want := new(big.Int).Mod(tt.x, q); got.Cmp(want) != 0 {
+				t.Errorf("LogParallel() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestLogParallel_errors(t *testing.T) {
+	t.Parallel()
+
+	p, q, g := testSafePrime(t, 20)
+
+	t.Run("not in subgroup", func(t *testing.T) {
+		t.Parallel()
+		// -1 is a quadratic non-residue since a safe prime p > 7 is 3 mod 4
+		h := new(big.Int).Sub(p, one)
+		if _, err := LogParallel(context.Background(), testRandom(7), g, h, p, q, 4); !errors.Is(err, ErrNoLog) {
+			t.Errorf("LogParallel() error = %v, want %v", err, ErrNoLog)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		h := new(big.Int).Exp(g, big.NewInt(12345), p)
+		if _, err := LogParallel(ctx, testRandom(7), g, h, p, q, 4); !errors.Is(err, context.Canceled) {
+			t.Errorf("LogParallel() error = %v, want %v", err, context.Canceled)
+		}
+	})
+}
+
+func Test_collide(t *testing.T) {
+	t.Parallel()
+
+	p, q, g := testSafePrime(t, 20)
+	x := big.NewInt(4242)
+	h := new(big.Int).Exp(g, x, p)
+	point := func(a, b int64) *big.Int {
+		y := new(big.Int).Exp(g, big.NewInt(a), p)
+		return y.Mul(y, new(big.Int).Exp(h, big.NewInt(b), p)).Mod(y, p)
+	}
+
+	store := &dpStore{points: make(map[string]dlogWalk)}
+	// g^(a + x*b) is the same point for (a, b) = (x, 0) and (0, 1)
+	w1 := dlogWalk{point(4242, 0), big.NewInt(4242), big.NewInt(0)}
+	w2 := dlogWalk{point(0, 1), big.NewInt(0), big.NewInt(1)}
+	if _, ok := collide(store, w1, q); ok {
+		t.Fatal("collide() on an empty store reported a collision")
+	}
+	if _, ok := collide(store, w1, q); ok {
+		t.Error("collide() with equal b reported a collision")
+	}
+	got, ok := collide(store, w2, q)
+	if !ok {
+		t.Fatal("collide() missed a collision")
+	}
+	if got.Cmp(x) != 0 {
+		t.Errorf("collide() = %v, want %v", got, x)
+	}
+}
+
+func BenchmarkLogParallel(b *testing.B) {
+	p, q, g := testSafePrime(b, 28)
+	h := new(big.Int).Exp(g, big.NewInt(98765432), p)
+	for b.Loop() {
+		if _, err := LogParallel(context.Background(), testRandom(7), g, h, p, q, 4); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
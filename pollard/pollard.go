@@ -1,12 +1,75 @@
 package pollard
 
-import "math/big"
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+)
 
 var (
-	zero = big.NewInt(0)
-	one  = big.NewInt(1)
+	zero  = big.NewInt(0)
+	one   = big.NewInt(1)
+	two   = big.NewInt(2)
+	three = big.NewInt(3)
 )
 
+// ErrNoFactor is returned when no nontrivial factor was found.
+var ErrNoFactor = errors.New("pollard: no nontrivial factor found")
+
+// maxAttempts bounds how many random polynomials Factor tries before giving up.
+const maxAttempts = 32
+
+// Progress is called by FactorContext every progressInterval iterations of
+// the rho walks with the total number of iterations so far.
+type Progress func(iterations uint64)
+
+// progressInterval is how many rho iterations pass between cancellation
+// checks and progress reports.
+const progressInterval = 1 << 10
+
+// Factor returns a nontrivial factor of the composite n.
+//
+// Each attempt runs Floyd's rho with f(x) = x² + c for a constant c drawn
+// uniformly from [1, n-3] using random; a walk whose cycle closes mod n at
+// the same time as mod p yields n itself and is retried with a fresh c.
+// Passing a deterministic source such as math/rand/v2's ChaCha8 makes the
+// result reproducible. Primes and n < 4 return ErrNoFactor.
+func Factor(random io.Reader, n *big.Int) (*big.Int, error) {
+	return FactorContext(context.Background(), random, n, nil)
+}
+
+// FactorContext is Factor with cancellation and progress reporting, for
+// embedding services that need to bound and observe a factorization. ctx is
+// checked every progressInterval iterations, and a cancelled search returns
+// ctx.Err(). progress may be nil.
+func FactorContext(ctx context.Context, random io.Reader, n *big.Int, progress Progress) (*big.Int, error) {
+	if n.Cmp(big.NewInt(4)) < 0 || n.ProbablyPrime(20) {
+		return nil, ErrNoFactor
+	}
+	if n.Bit(0) == 0 {
+		return new(big.Int).Set(two), nil
+	}
+
+	var iterations uint64
+	for range maxAttempts {
+		c, err := rand.Int(random, new(big.Int).Sub(n, three))
+		if err != nil {
+			return nil, err
+		}
+		c.Add(c, one)
+		d, err := floydoContext(ctx, n, c, progress, &iterations)
+		if err != nil {
+			return nil, err
+		}
+		if d.Cmp(n) != 0 {
+			return d, nil
+		}
+	}
+	return nil, ErrNoFactor
+}
+
 func gcd(l, r *big.Int) *big.Int {
 	for r.Cmp(zero) != 0 {
 		l, r = r, new(big.Int).Mod(l, r)
@@ -19,6 +82,14 @@ func floydo(n *big.Int) *big.Int {
 }
 
 func inner_floydo(n, c *big.Int) *big.Int {
+	var iterations uint64
+	d, _ := floydoContext(context.Background(), n, c, nil, &iterations)
+	return d
+}
+
+// floydoContext runs Floyd's rho walk for f(x) = x² + c, adding its
+// iterations to *iterations so progress stays cumulative across attempts.
+func floydoContext(ctx context.Context, n, c *big.Int, progress Progress, iterations *uint64) (*big.Int, error) {
 	f := func(x, n *big.Int) *big.Int {
 		sq := new(big.Int).Mul(x, x)
 		ad := sq.Add(sq, c)
@@ -39,6 +110,16 @@ func inner_floydo(n, c *big.Int) *big.Int {
 		x = f(x, n)
 		y = f(f(y, n), n)
 		d = gcd(subAbs(x, y), n)
+
+		*iterations++
+		if *iterations%progressInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if progress != nil {
+				progress(*iterations)
+			}
+		}
 	}
-	return d
+	return d, nil
 }
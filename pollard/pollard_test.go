@@ -1,7 +1,11 @@
 package pollard
 
 import (
+	"context"
+	"errors"
+	"io"
 	"math/big"
+	mrand "math/rand/v2"
 	"reflect"
 	"testing"
 )
@@ -103,3 +107,107 @@ func Test_floydo(t *testing.T) {
 		})
 	}
 }
+
+// testRandom returns a deterministic randomness source for reproducible tests.
+func testRandom(seed uint64) io.Reader {
+	return mrand.NewChaCha8([32]byte{byte(seed), byte(seed >> 8), byte(seed >> 16), byte(seed >> 24)})
+}
+
+func TestFactor(t *testing.T) {
+	t.Parallel()
+
+	p61 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 61), big.NewInt(1))
+	tests := []struct {
+		name    string
+		n       *big.Int
+		wantErr error
+	}{
+		{name: "15", n: big.NewInt(15)},
+		{name: "8051", n: big.NewInt(8051)},
+		{name: "even", n: big.NewInt(1 << 20)},
+		{name: "square of prime", n: big.NewInt(1223 * 1223)},
+		{name: "25", n: big.NewInt(25)},
+		{name: "semiprime with 61-bit factor", n: new(big.Int).Mul(p61, big.NewInt(1000003))},
+		{name: "prime", n: big.NewInt(1223), wantErr: ErrNoFactor},
+		{name: "one", n: big.NewInt(1), wantErr: ErrNoFactor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			d, err := Factor(testRandom(1), tt.n)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Factor(%v) error = %v, want %v", tt.n, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if d.Cmp(one) <= 0 || d.Cmp(tt.n) >= 0 || new(big.Int).Mod(tt.n, d).Sign() != 0 {
+				t.Errorf("Factor(%v) = %v, want a nontrivial factor", tt.n, d)
+			}
+		})
+	}
+}
+
+func TestFactor_deterministic(t *testing.T) {
+	t.Parallel()
+
+	n := big.NewInt(1000003 * 999983)
+	first, err := Factor(testRandom(42), n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 5 {
+		got, err := Factor(testRandom(42), n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Cmp(first) != 0 {
+			t.Errorf("Factor() with the same seed = %v, want %v", got, first)
+		}
+	}
+}
+
+func TestFactorContext(t *testing.T) {
+	t.Parallel()
+
+	// Two ~31-bit primes: rho needs tens of thousands of iterations
+	n := new(big.Int).Mul(big.NewInt(2147483647), big.NewInt(2147483629))
+
+	t.Run("progress", func(t *testing.T) {
+		t.Parallel()
+		var last uint64
+		d, err := FactorContext(context.Background(), testRandom(5), n, func(iterations uint64) {
+			if iterations <= last || iterations%progressInterval != 0 {
+				t.Errorf("progress(%d) after progress(%d)", iterations, last)
+			}
+			last = iterations
+		})
+		if err != nil {
+			t.Fatalf("FactorContext() error = %v", err)
+		}
+		if new(big.Int).Mod(n, d).Sign() != 0 {
+			t.Errorf("FactorContext() = %v, want a factor of %v", d, n)
+		}
+		if last == 0 {
+			t.Error("progress was never called")
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		var calls int
+		_, err := FactorContext(ctx, testRandom(5), n, func(uint64) {
+			calls++
+			cancel()
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("FactorContext() error = %v, want %v", err, context.Canceled)
+		}
+		if calls != 1 {
+			t.Errorf("progress calls = %d, want 1", calls)
+		}
+	})
+}
@@ -0,0 +1,223 @@
+package poly
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	// ErrModulus is returned for a coefficient modulus p < 2.
+	ErrModulus = errors.New("poly: modulus must be at least 2")
+	// ErrDivisor is returned when h is zero mod p or its leading coefficient
+	// is not invertible mod p.
+	ErrDivisor = errors.New("poly: divisor has no invertible leading coefficient")
+	// ErrNegativeExponent is returned for e < 0.
+	ErrNegativeExponent = errors.New("poly: negative exponent")
+)
+
+// quotientRing is Z/pZ[x]/(h): h reduced mod p, trimmed to its true degree,
+// and the inverse of its leading coefficient.
+type quotientRing struct {
+	h     []*big.Int
+	lcInv *big.Int
+	p     *big.Int
+}
+
+func newQuotientRing(h []*big.Int, p *big.Int) (*quotientRing, error) {
+	if p.Cmp(big.NewInt(2)) < 0 {
+		return nil, ErrModulus
+	}
+	h = reduce(h, p)
+	for len(h) > 0 && h[len(h)-1].Sign() == 0 {
+		h = h[:len(h)-1]
+	}
+	if len(h) == 0 {
+		return nil, ErrDivisor
+	}
+	lcInv := new(big.Int).ModInverse(h[len(h)-1], p)
+	if lcInv == nil {
+		return nil, ErrDivisor
+	}
+	return &quotientRing{h: h, lcInv: lcInv, p: p}, nil
+}
+
+// degree returns deg h, which is also the length of every reduced element.
+func (q *quotientRing) degree() int { return len(q.h) - 1 }
+
+// rem returns a mod (h, p) with exactly deg h coefficients.
+func (q *quotientRing) rem(a []*big.Int) []*big.Int {
+	n := q.degree()
+	r := reduce(a, q.p)
+	for len(r) < n {
+		r = append(r, new(big.Int))
+	}
+	c, t := new(big.Int), new(big.Int)
+	for i := len(r) - 1; i >= n; i-- {
+		c.Mul(r[i], q.lcInv)
+		c.Mod(c, q.p)
+		if c.Sign() == 0 {
+			continue
+		}
+		// r -= c * x^(i-n) * h, which clears r[i]
+		for j, hj := range q.h[:n] {
+			x := r[i-n+j]
+			x.Sub(x, t.Mul(c, hj))
+			x.Mod(x, q.p)
+		}
+	}
+	return r[:n]
+}
+
+func (q *quotientRing) mul(a, b []*big.Int) []*big.Int {
+	return q.rem(MulMod(a, b, q.p))
+}
+
+// one returns 1 mod h, which is 0 when h is a constant.
+func (q *quotientRing) one() []*big.Int {
+	return q.rem([]*big.Int{big.NewInt(1)})
+}
+
+// Rem returns a mod (h, p): the remainder of a divided by h, with
+// coefficients in [0, p). The result has exactly deg h coefficients, where
+// deg h is taken mod p, so high coefficients of h divisible by p are ignored.
+func Rem(a, h []*big.Int, p *big.Int) ([]*big.Int, error) {
+	q, err := newQuotientRing(h, p)
+	if err != nil {
+		return nil, err
+	}
+	return q.rem(a), nil
+}
+
+// MulRem returns a*b mod (h, p).
+func MulRem(a, b, h []*big.Int, p *big.Int) ([]*big.Int, error) {
+	q, err := newQuotientRing(h, p)
+	if err != nil {
+		return nil, err
+	}
+	return q.mul(a, b), nil
+}
+
+// PowRem returns a^e mod (h, p) by square-and-multiply.
+func PowRem(a []*big.Int, e *big.Int, h []*big.Int, p *big.Int) ([]*big.Int, error) {
+	if e.Sign() < 0 {
+		return nil, ErrNegativeExponent
+	}
+	q, err := newQuotientRing(h, p)
+	if err != nil {
+		return nil, err
+	}
+	return q.pow(q.rem(a), e), nil
+}
+
+func (q *quotientRing) pow(a []*big.Int, e *big.Int) []*big.Int {
+	r := q.one()
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		r = q.mul(r, r)
+		if e.Bit(i) == 1 {
+			r = q.mul(r, a)
+		}
+	}
+	return r
+}
+
+// Compose returns f(g(x)) mod (h, p) by Brent–Kung modular composition.
+//
+// Horner's rule would need deg f multiplications mod h. Brent–Kung splits f
+// into about √(deg f) blocks of m ≈ √(deg f) coefficients each, so that
+//
+//	f(g) = Σ_i f_i(g) · (g^m)^i,
+//
+// precomputes g^0, …, g^m mod h, and evaluates every block f_i(g) as a linear
+// combination of those powers: a matrix product of scalars, with no
+// polynomial multiplication at all. Horner's rule in g^m then combines the
+// blocks, for about 2√(deg f) multiplications mod h in total.
+func Compose(f, g, h []*big.Int, p *big.Int) ([]*big.Int, error) {
+	q, err := newQuotientRing(h, p)
+	if err != nil {
+		return nil, err
+	}
+	return q.compose(f, q.rem(g)), nil
+}
+
+func (q *quotientRing) compose(f, g []*big.Int) []*big.Int {
+	n := q.degree()
+	if len(f) == 0 {
+		return q.rem(nil)
+	}
+	m := 1
+	for m*m < len(f) {
+		m++
+	}
+
+	// powers[j] = g^j mod h for j in [0, m]
+	powers := make([][]*big.Int, m+1)
+	powers[0] = q.one()
+	for j := 1; j <= m; j++ {
+		powers[j] = q.mul(powers[j-1], g)
+	}
+
+	t := new(big.Int)
+	var r []*big.Int
+	for i := (len(f) - 1) / m; i >= 0; i-- {
+		// block = f_i(g) = Σ_j f[i*m+j] g^j, one row of the matrix product,
+		// reduced mod p once at the end
+		block := make([]*big.Int, n)
+		for k := range block {
+			block[k] = new(big.Int)
+		}
+		for j, c := range f[i*m : min((i+1)*m, len(f))] {
+			for k, x := range powers[j] {
+				block[k].Add(block[k], t.Mul(c, x))
+			}
+		}
+		if r == nil {
+			r = reduce(block, q.p)
+			continue
+		}
+		r = q.mul(r, powers[m])
+		for k := range r {
+			r[k].Add(r[k], block[k])
+			r[k].Mod(r[k], q.p)
+		}
+	}
+	return r
+}
+
+// Frobenius returns x^(p^d) mod (h, p) for a prime p and d ≥ 0.
+//
+// For prime p, a ↦ a^p is a ring homomorphism of Z/pZ[x]/(h), so
+// x^(p^(a+b)) = x^(p^a) composed with x^(p^b). After one exponentiation for
+// x^p, Frobenius doubles its way to d with modular compositions instead of
+// raising to p^d directly, the step that dominates distinct-degree
+// factorization.
+func Frobenius(h []*big.Int, p *big.Int, d int) ([]*big.Int, error) {
+	if d < 0 {
+		return nil, ErrNegativeExponent
+	}
+	q, err := newQuotientRing(h, p)
+	if err != nil {
+		return nil, err
+	}
+	return q.frobeniusX(q.pow(q.x(), p), d), nil
+}
+
+// x returns x mod h.
+func (q *quotientRing) x() []*big.Int {
+	return q.rem([]*big.Int{new(big.Int), big.NewInt(1)})
+}
+
+// frobeniusX returns x^(p^d) mod h given xp = x^p mod h.
+func (q *quotientRing) frobeniusX(xp []*big.Int, d int) []*big.Int {
+	r := q.x()
+	// step = x^(p^(2^i)) for bit i of d
+	step := xp
+	for ; d > 0; d >>= 1 {
+		if d&1 == 1 {
+			r = q.compose(r, step)
+		}
+		if d > 1 {
+			step = q.compose(step, step)
+		}
+	}
+	return r
+}
@@ -0,0 +1,165 @@
+package poly
+
+import (
+	"errors"
+	"math/big"
+	"math/rand/v2"
+	"testing"
+)
+
+// composeHorner is the reference for Compose: Horner's rule in g, one
+// multiplication mod h per coefficient of f.
+func composeHorner(f, g, h []*big.Int, p *big.Int) []*big.Int {
+	r, _ := Rem(nil, h, p)
+	for i := len(f) - 1; i >= 0; i-- {
+		r, _ = MulRem(r, g, h, p)
+		if len(r) > 0 {
+			r[0].Add(r[0], f[i])
+			r[0].Mod(r[0], p)
+		}
+	}
+	return r
+}
+
+func TestRem(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		a, h    []*big.Int
+		p       int64
+		want    []*big.Int
+		wantErr error
+	}{
+		// x³ + 2x + 1 = x(x² + 1) + (x + 1)
+		{"monic", ints(1, 2, 0, 1), ints(1, 0, 1), 7, ints(1, 1), nil},
+		// 2x² + 1 ≡ 2(x² + 1) - 1 ≡ 6 mod 7
+		{"non-monic divisor", ints(1, 0, 2), ints(3, 0, 3), 7, ints(6, 0), nil},
+		{"short dividend is padded", ints(5), ints(1, 1, 1), 7, ints(5, 0), nil},
+		{"leading coefficient vanishes mod p", ints(0, 3, 1), ints(1, 1, 7), 7, ints(5), nil},
+		{"negative coefficients", ints(-1, -1), ints(0, 0, 1), 5, ints(4, 4), nil},
+		{"constant divisor", ints(1, 2, 3), ints(4), 7, ints(), nil},
+		{"zero divisor", ints(1), ints(7, 14), 7, nil, ErrDivisor},
+		{"non-invertible leading coefficient", ints(1), ints(1, 2), 6, nil, ErrDivisor},
+		{"modulus too small", ints(1), ints(1, 1), 1, nil, ErrModulus},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Rem(tc.a, tc.h, big.NewInt(tc.p))
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Rem() error = %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && !equal(got, tc.want) {
+				t.Errorf("Rem() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPowRem(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(4, 4))
+	p := big.NewInt(1000003)
+	h := append(randomPoly(rng, 12, 20, false), big.NewInt(1))
+	a := randomPoly(rng, 12, 20, false)
+
+	want, _ := Rem([]*big.Int{big.NewInt(1)}, h, p)
+	for e := range 20 {
+		got, err := PowRem(a, big.NewInt(int64(e)), h, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equal(got, want) {
+			t.Fatalf("PowRem(a, %d) = %v, want %v", e, got, want)
+		}
+		want, _ = MulRem(want, a, h, p)
+	}
+
+	if _, err := PowRem(a, big.NewInt(-1), h, p); !errors.Is(err, ErrNegativeExponent) {
+		t.Errorf("PowRem(a, -1) error = %v, want %v", err, ErrNegativeExponent)
+	}
+}
+
+func TestCompose(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(5, 5))
+	p := big.NewInt(1000003)
+	P, _ := new(big.Int).SetString("ffffffffffffffffffffffffffffffff000000000000000000000001", 16)
+	tests := []struct {
+		name    string
+		f, g, h []*big.Int
+		p       *big.Int
+	}{
+		{"empty f", nil, ints(1, 2), ints(1, 0, 1), p},
+		{"constant f", ints(9), ints(1, 2), ints(1, 0, 1), p},
+		{"identity g", ints(3, 1, 4, 1, 5), ints(0, 1), ints(1, 1, 0, 0, 1), p},
+		{"g wider than h", ints(1, 2, 3), ints(1, 2, 3, 4, 5, 6), ints(2, 0, 1), p},
+		{"linear h", ints(1, 2, 3, 4), ints(5, 6), ints(1, 1), p},
+		{"constant h", ints(1, 2, 3, 4), ints(5, 6), ints(3), p},
+		{"perfect square block count", randomPoly(rng, 16, 20, false), randomPoly(rng, 8, 20, false), append(randomPoly(rng, 8, 20, false), big.NewInt(1)), p},
+		{"partial last block", randomPoly(rng, 50, 20, true), randomPoly(rng, 20, 20, true), append(randomPoly(rng, 20, 20, false), big.NewInt(3)), p},
+		{"large p", randomPoly(rng, 30, 224, false), randomPoly(rng, 10, 224, false), append(randomPoly(rng, 10, 224, false), big.NewInt(1)), P},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Compose(tc.f, tc.g, tc.h, tc.p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := composeHorner(tc.f, tc.g, tc.h, tc.p); !equal(got, want) {
+				t.Errorf("Compose() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestFrobenius(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(6, 6))
+	p := big.NewInt(101)
+	h := append(randomPoly(rng, 9, 7, false), big.NewInt(1))
+	x := ints(0, 1)
+	for d := range 8 {
+		got, err := Frobenius(h, p, d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := new(big.Int).Exp(p, big.NewInt(int64(d)), nil)
+		if want, _ := PowRem(x, e, h, p); !equal(got, want) {
+			t.Errorf("Frobenius(d=%d) = %v, want %v", d, got, want)
+		}
+	}
+
+	// x^(p^d) ≡ x mod h exactly when every irreducible factor of h has degree
+	// dividing d; x² + 1 is irreducible mod 7.
+	for d, want := range map[int]bool{1: false, 2: true, 3: false, 4: true} {
+		got, _ := Frobenius(ints(1, 0, 1), big.NewInt(7), d)
+		if equal(got, ints(0, 1)) != want {
+			t.Errorf("Frobenius(x²+1, 7, %d) = %v, fixes x: %v", d, got, !want)
+		}
+	}
+}
+
+// BenchmarkCompose compares Brent–Kung against Horner's rule for f, g and h
+// of degree 64 over a 61-bit prime.
+func BenchmarkCompose(b *testing.B) {
+	rng := rand.New(rand.NewPCG(7, 7))
+	p := new(big.Int).SetUint64(1<<61 - 1)
+	f, g := randomPoly(rng, 64, 61, false), randomPoly(rng, 64, 61, false)
+	h := append(randomPoly(rng, 64, 61, false), big.NewInt(1))
+	b.Run("BrentKung", func(b *testing.B) {
+		for b.Loop() {
+			Compose(f, g, h, p)
+		}
+	})
+	b.Run("Horner", func(b *testing.B) {
+		for b.Loop() {
+			composeHorner(f, g, h, p)
+		}
+	})
+}
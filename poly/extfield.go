@@ -0,0 +1,259 @@
+package poly
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	// ErrNotPrime is returned when the characteristic of an extension field
+	// is not prime.
+	ErrNotPrime = errors.New("poly: characteristic is not prime")
+	// ErrReducible is returned when the defining polynomial of an extension
+	// field is constant or not irreducible mod p.
+	ErrReducible = errors.New("poly: defining polynomial is not irreducible")
+)
+
+// ExtensionField is GF(p^k) = Z/pZ[x]/(h) for a prime p and a polynomial h
+// of degree k irreducible mod p.
+//
+// Elements are polynomials of degree below k, as in the rest of the package.
+// Methods accept any polynomial and reduce it mod (h, p) first, and always
+// return exactly k coefficients in [0, p).
+type ExtensionField struct {
+	q  *quotientRing
+	xp []*big.Int // x^p mod h, the Frobenius image of x
+}
+
+// NewExtensionField returns GF(p^k) defined by h. p must be prime and h
+// irreducible mod p; both are checked, p probabilistically and h with
+// Rabin's irreducibility test.
+func NewExtensionField(h []*big.Int, p *big.Int) (*ExtensionField, error) {
+	q, err := newQuotientRing(h, p)
+	if err != nil {
+		return nil, err
+	}
+	if !p.ProbablyPrime(20) {
+		return nil, ErrNotPrime
+	}
+	if q.degree() < 1 {
+		return nil, ErrReducible
+	}
+	f := &ExtensionField{q: q, xp: q.pow(q.x(), p)}
+	if !f.irreducible() {
+		return nil, ErrReducible
+	}
+	return f, nil
+}
+
+// irreducible reports whether h is irreducible by Rabin's test: h of degree
+// k is irreducible iff it divides x^(p^k) - x and shares no factor with
+// x^(p^(k/r)) - x for any prime r dividing k.
+func (f *ExtensionField) irreducible() bool {
+	q := f.q
+	k := q.degree()
+	x := q.x()
+	if !equal(q.frobeniusX(f.xp, k), x) {
+		return false
+	}
+	for _, r := range primeFactors(k) {
+		t := q.frobeniusX(f.xp, k/r)
+		t = sub(t, x, q.p)
+		if len(gcd(t, q.h, q.p)) > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// Degree returns k, the degree of the field over GF(p).
+func (f *ExtensionField) Degree() int { return f.q.degree() }
+
+// Characteristic returns p.
+func (f *ExtensionField) Characteristic() *big.Int { return new(big.Int).Set(f.q.p) }
+
+// Add returns a + b.
+func (f *ExtensionField) Add(a, b []*big.Int) []*big.Int {
+	r := f.q.rem(a)
+	for i, x := range f.q.rem(b) {
+		r[i].Add(r[i], x)
+		r[i].Mod(r[i], f.q.p)
+	}
+	return r
+}
+
+// Sub returns a - b.
+func (f *ExtensionField) Sub(a, b []*big.Int) []*big.Int {
+	return sub(f.q.rem(a), f.q.rem(b), f.q.p)
+}
+
+// Mul returns a * b.
+func (f *ExtensionField) Mul(a, b []*big.Int) []*big.Int { return f.q.mul(a, b) }
+
+// Exp returns a^e for e ≥ 0.
+func (f *ExtensionField) Exp(a []*big.Int, e *big.Int) []*big.Int {
+	if e.Sign() < 0 {
+		panic("poly: negative exponent")
+	}
+	return f.q.pow(f.q.rem(a), e)
+}
+
+// Inverse returns a⁻¹ = a^(p^k - 2), or nil if a is zero.
+func (f *ExtensionField) Inverse(a []*big.Int) []*big.Int {
+	a = f.q.rem(a)
+	if len(trim(a)) == 0 {
+		return nil
+	}
+	e := new(big.Int).Exp(f.q.p, big.NewInt(int64(f.Degree())), nil)
+	return f.q.pow(a, e.Sub(e, big.NewInt(2)))
+}
+
+// Frobenius returns a^(p^i), the i-th power of the Frobenius automorphism
+// applied to a. As a polynomial identity a^(p^i) = a(x^(p^i)), so it costs
+// one modular composition after computing x^(p^i), never an exponentiation
+// by p^i. i is taken mod k and may be negative.
+func (f *ExtensionField) Frobenius(a []*big.Int, i int) []*big.Int {
+	k := f.Degree()
+	i = ((i % k) + k) % k
+	return f.q.compose(a, f.q.frobeniusX(f.xp, i))
+}
+
+// Conjugates returns a, a^p, …, a^(p^(k-1)), the images of a under the
+// Galois group of GF(p^k) over GF(p).
+func (f *ExtensionField) Conjugates(a []*big.Int) [][]*big.Int {
+	c := make([][]*big.Int, f.Degree())
+	c[0] = f.q.rem(a)
+	for i := 1; i < len(c); i++ {
+		// a^(p^i) = (a^(p^(i-1)))^p = a^(p^(i-1))(x^p)
+		c[i] = f.q.compose(c[i-1], f.xp)
+	}
+	return c
+}
+
+// Trace returns Tr(a) = a + a^p + … + a^(p^(k-1)) in GF(p).
+func (f *ExtensionField) Trace(a []*big.Int) *big.Int {
+	var t []*big.Int
+	for _, c := range f.Conjugates(a) {
+		if t == nil {
+			t = c
+			continue
+		}
+		t = f.Add(t, c)
+	}
+	return t[0]
+}
+
+// Norm returns N(a) = a · a^p ⋯ a^(p^(k-1)) = a^((p^k-1)/(p-1)) in GF(p).
+func (f *ExtensionField) Norm(a []*big.Int) *big.Int {
+	n := f.q.one()
+	for _, c := range f.Conjugates(a) {
+		n = f.q.mul(n, c)
+	}
+	return n[0]
+}
+
+// CharPoly returns the characteristic polynomial of a, the monic
+// ∏ (X - a^(p^i)) over all k conjugates, with k+1 coefficients in GF(p).
+// Its X^(k-1) coefficient is -Tr(a) and its constant term (-1)^k N(a).
+func (f *ExtensionField) CharPoly(a []*big.Int) []*big.Int {
+	return f.fromRoots(f.Conjugates(a))
+}
+
+// MinPoly returns the minimal polynomial of a over GF(p): the monic
+// ∏ (X - a^(p^i)) over the d distinct conjugates, where d is the degree of
+// the smallest subfield containing a. d divides k, and the characteristic
+// polynomial is MinPoly(a)^(k/d).
+func (f *ExtensionField) MinPoly(a []*big.Int) []*big.Int {
+	c := f.Conjugates(a)
+	d := 1
+	for d < len(c) && !equal(c[d], c[0]) {
+		d++
+	}
+	return f.fromRoots(c[:d])
+}
+
+// fromRoots expands ∏ (X - r) over the given field elements. The product
+// is Galois-invariant whenever the roots are a union of conjugate orbits, so
+// its coefficients are constants and only their low coefficient is kept.
+func (f *ExtensionField) fromRoots(roots [][]*big.Int) []*big.Int {
+	// e[j] is the X^j coefficient, a field element
+	e := [][]*big.Int{f.q.one()}
+	for _, r := range roots {
+		next := make([][]*big.Int, len(e)+1)
+		next[len(e)] = e[len(e)-1]
+		for j := len(e) - 1; j >= 0; j-- {
+			t := f.Sub(nil, f.q.mul(r, e[j]))
+			if j > 0 {
+				t = f.Add(t, e[j-1])
+			}
+			next[j] = t
+		}
+		e = next
+	}
+	c := make([]*big.Int, len(e))
+	for j, x := range e {
+		c[j] = x[0]
+	}
+	return c
+}
+
+// sub returns a - b coefficient-wise mod p, as long as the longer operand.
+func sub(a, b []*big.Int, p *big.Int) []*big.Int {
+	r := make([]*big.Int, max(len(a), len(b)))
+	for i := range r {
+		r[i] = new(big.Int)
+		if i < len(a) {
+			r[i].Set(a[i])
+		}
+		if i < len(b) {
+			r[i].Sub(r[i], b[i])
+		}
+		r[i].Mod(r[i], p)
+	}
+	return r
+}
+
+// trim returns a without its high zero coefficients.
+func trim(a []*big.Int) []*big.Int {
+	for len(a) > 0 && a[len(a)-1].Sign() == 0 {
+		a = a[:len(a)-1]
+	}
+	return a
+}
+
+// gcd returns the monic greatest common divisor of a and b mod a prime p,
+// trimmed, by Euclid's algorithm.
+func gcd(a, b []*big.Int, p *big.Int) []*big.Int {
+	a, b = trim(reduce(a, p)), trim(reduce(b, p))
+	for len(b) > 0 {
+		q, _ := newQuotientRing(b, p)
+		a, b = b, trim(q.rem(a))
+	}
+	if len(a) == 0 {
+		return a
+	}
+	inv := new(big.Int).ModInverse(a[len(a)-1], p)
+	r := make([]*big.Int, len(a))
+	for i, x := range a {
+		r[i] = new(big.Int).Mul(x, inv)
+		r[i].Mod(r[i], p)
+	}
+	return r
+}
+
+// primeFactors returns the distinct prime factors of n > 0.
+func primeFactors(n int) []int {
+	var fs []int
+	for d := 2; d*d <= n; d++ {
+		if n%d == 0 {
+			fs = append(fs, d)
+			for n%d == 0 {
+				n /= d
+			}
+		}
+	}
+	if n > 1 {
+		fs = append(fs, n)
+	}
+	return fs
+}
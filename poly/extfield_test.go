@@ -0,0 +1,251 @@
+package poly
+
+import (
+	"errors"
+	"math/big"
+	"math/rand/v2"
+	"testing"
+	"testing/quick"
+)
+
+// aesField is GF(2^8) with the AES polynomial x^8 + x^4 + x^3 + x + 1.
+func aesField(t testing.TB) *ExtensionField {
+	t.Helper()
+	f, err := NewExtensionField(ints(1, 1, 0, 1, 1, 0, 0, 0, 1), big.NewInt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// evalAt evaluates the GF(p) polynomial c at the field element a.
+func evalAt(f *ExtensionField, c []*big.Int, a []*big.Int) []*big.Int {
+	r := f.Sub(nil, nil)
+	for i := len(c) - 1; i >= 0; i-- {
+		r = f.Add(f.Mul(r, a), []*big.Int{c[i]})
+	}
+	return r
+}
+
+func isZero(a []*big.Int) bool { return len(trim(a)) == 0 }
+
+func TestNewExtensionField(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		h       []*big.Int
+		p       int64
+		wantErr error
+	}{
+		{"x²+1 mod 7", ints(1, 0, 1), 7, nil},
+		{"AES", ints(1, 1, 0, 1, 1, 0, 0, 0, 1), 2, nil},
+		{"degree one", ints(3, 1), 5, nil},
+		{"non-monic", ints(3, 0, 3), 7, nil},
+		{"x²+1 mod 5 splits", ints(1, 0, 1), 5, ErrReducible},
+		// (x²+x+1)² mod 2 passes the first half of Rabin's test
+		{"square of irreducible", ints(1, 0, 1, 0, 1), 2, ErrReducible},
+		// (x²+x+1)(x³+x+1) mod 2 has no roots
+		{"product without roots", ints(1, 0, 0, 0, 1, 1), 2, ErrReducible},
+		{"constant", ints(3), 7, ErrReducible},
+		{"composite characteristic", ints(1, 0, 1), 9, ErrNotPrime},
+		{"zero polynomial", ints(0, 7), 7, ErrDivisor},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			f, err := NewExtensionField(tc.h, big.NewInt(tc.p))
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("NewExtensionField() error = %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && f.Degree() != len(trim(reduce(tc.h, big.NewInt(tc.p))))-1 {
+				t.Errorf("Degree() = %d", f.Degree())
+			}
+		})
+	}
+}
+
+func TestExtensionFieldArithmetic(t *testing.T) {
+	t.Parallel()
+
+	f, _ := NewExtensionField(ints(1, 0, 1), big.NewInt(7))
+	// (1 + 2i)(3 + 4i) = 3 + 10i + 8i² = -5 + 10i = 2 + 3i
+	if got := f.Mul(ints(1, 2), ints(3, 4)); !equal(got, ints(2, 3)) {
+		t.Errorf("Mul() = %v, want [2 3]", got)
+	}
+	if got := f.Add(ints(6, 6), ints(2, 3)); !equal(got, ints(1, 2)) {
+		t.Errorf("Add() = %v, want [1 2]", got)
+	}
+	if got := f.Sub(ints(1), ints(0, 1)); !equal(got, ints(1, 6)) {
+		t.Errorf("Sub() = %v, want [1 6]", got)
+	}
+	if got := f.Exp(ints(0, 1), big.NewInt(2)); !equal(got, ints(6, 0)) {
+		t.Errorf("Exp(i, 2) = %v, want [6 0]", got)
+	}
+	if f.Inverse(ints(0, 0)) != nil {
+		t.Error("Inverse(0) != nil")
+	}
+
+	aes := aesField(t)
+	// 0x53 and 0xca are inverses in the AES field
+	a := ints(1, 1, 0, 0, 1, 0, 1, 0)
+	if got := aes.Inverse(a); !equal(got, ints(0, 1, 0, 1, 0, 0, 1, 1)) {
+		t.Errorf("Inverse(0x53) = %v, want 0xca", got)
+	}
+}
+
+func TestExtensionFieldFrobenius(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(8, 8))
+	p := big.NewInt(1000003)
+	f, err := NewExtensionField(ints(11, 1, 0, 0, 0, 1), p) // x^5 + x + 11
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := randomPoly(rng, 5, 20, false)
+	for i := -5; i <= 6; i++ {
+		e := new(big.Int).Exp(p, big.NewInt(int64(((i%5)+5)%5)), nil)
+		if got, want := f.Frobenius(a, i), f.Exp(a, e); !equal(got, want) {
+			t.Errorf("Frobenius(a, %d) = %v, want a^(p^%d) = %v", i, got, i, want)
+		}
+	}
+	if got := f.Frobenius(f.Frobenius(a, 2), -2); !equal(got, f.Sub(a, nil)) {
+		t.Errorf("Frobenius(Frobenius(a, 2), -2) = %v, want a", got)
+	}
+	for i, c := range f.Conjugates(a) {
+		if want := f.Frobenius(a, i); !equal(c, want) {
+			t.Errorf("Conjugates()[%d] = %v, want %v", i, c, want)
+		}
+	}
+}
+
+func TestTraceNorm(t *testing.T) {
+	t.Parallel()
+
+	f, _ := NewExtensionField(ints(1, 0, 1), big.NewInt(7))
+	tests := []struct {
+		name     string
+		a        []*big.Int
+		trace    int64
+		norm     int64
+		charPoly []*big.Int
+		minPoly  []*big.Int
+	}{
+		// a + bi has trace 2a, norm a² + b², char poly X² - 2aX + a² + b²
+		{"i", ints(0, 1), 0, 1, ints(1, 0, 1), ints(1, 0, 1)},
+		{"3+2i", ints(3, 2), 6, 6, ints(6, 1, 1), ints(6, 1, 1)},
+		{"one", ints(1), 2, 1, ints(1, 5, 1), ints(6, 1)},
+		{"zero", nil, 0, 0, ints(0, 0, 1), ints(0, 1)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := f.Trace(tc.a); got.Int64() != tc.trace {
+				t.Errorf("Trace() = %v, want %d", got, tc.trace)
+			}
+			if got := f.Norm(tc.a); got.Int64() != tc.norm {
+				t.Errorf("Norm() = %v, want %d", got, tc.norm)
+			}
+			if got := f.CharPoly(tc.a); !equal(got, tc.charPoly) {
+				t.Errorf("CharPoly() = %v, want %v", got, tc.charPoly)
+			}
+			if got := f.MinPoly(tc.a); !equal(got, tc.minPoly) {
+				t.Errorf("MinPoly() = %v, want %v", got, tc.minPoly)
+			}
+		})
+	}
+}
+
+func TestTraceNormProperty(t *testing.T) {
+	t.Parallel()
+
+	p := big.NewInt(1000003)
+	f, err := NewExtensionField(ints(11, 1, 0, 0, 0, 1), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// (p^5 - 1) / (p - 1)
+	e := new(big.Int).Exp(p, big.NewInt(5), nil)
+	e.Sub(e, big.NewInt(1))
+	e.Div(e, new(big.Int).Sub(p, big.NewInt(1)))
+
+	err = quick.Check(func(x, y [5]uint32) bool {
+		a, b := make([]*big.Int, 5), make([]*big.Int, 5)
+		for i := range 5 {
+			a[i], b[i] = big.NewInt(int64(x[i])), big.NewInt(int64(y[i]))
+		}
+		tr := new(big.Int).Add(f.Trace(a), f.Trace(b))
+		if tr.Mod(tr, p).Cmp(f.Trace(f.Add(a, b))) != 0 {
+			return false
+		}
+		n := new(big.Int).Mul(f.Norm(a), f.Norm(b))
+		if n.Mod(n, p).Cmp(f.Norm(f.Mul(a, b))) != 0 {
+			return false
+		}
+		if !equal(f.Exp(a, e), f.Sub([]*big.Int{f.Norm(a)}, nil)) {
+			return false
+		}
+		return isZero(evalAt(f, f.CharPoly(a), a))
+	}, &quick.Config{MaxCount: 30})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMinPolySubfield(t *testing.T) {
+	t.Parallel()
+
+	f := aesField(t)
+	g := ints(1, 1) // x + 1 generates the multiplicative group of the AES field
+	tests := []struct {
+		name   string
+		e      int64
+		degree int
+	}{
+		{"generator", 1, 8},
+		{"GF(16)", 17, 4},
+		{"GF(4)", 85, 2},
+		{"GF(2)", 255, 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			a := f.Exp(g, big.NewInt(tc.e))
+			m := f.MinPoly(a)
+			if len(m)-1 != tc.degree {
+				t.Fatalf("MinPoly() has degree %d, want %d", len(m)-1, tc.degree)
+			}
+			if !isZero(evalAt(f, m, a)) {
+				t.Errorf("MinPoly(a) = %v does not vanish at a", m)
+			}
+			want := []*big.Int{big.NewInt(1)}
+			for range 8 / tc.degree {
+				want = MulMod(want, m, big.NewInt(2))
+			}
+			if got := f.CharPoly(a); !equal(got, want) {
+				t.Errorf("CharPoly() = %v, want MinPoly^%d = %v", got, 8/tc.degree, want)
+			}
+		})
+	}
+}
+
+func BenchmarkFrobenius(b *testing.B) {
+	rng := rand.New(rand.NewPCG(9, 9))
+	p := new(big.Int).SetUint64(1<<61 - 1)
+	h := append(randomPoly(rng, 31, 61, false), big.NewInt(1))
+	q, _ := newQuotientRing(h, p)
+	xp := q.pow(q.x(), p)
+	a := randomPoly(rng, 31, 61, false)
+	b.Run("Compose", func(b *testing.B) {
+		for b.Loop() {
+			q.compose(a, q.frobeniusX(xp, 7))
+		}
+	})
+	e := new(big.Int).Exp(p, big.NewInt(7), nil)
+	b.Run("Exp", func(b *testing.B) {
+		for b.Loop() {
+			q.pow(a, e)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package poly
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// MulKronecker returns a*b over the integers by Kronecker substitution.
+//
+// Both polynomials are evaluated at x = 2^k, which packs their coefficients
+// into one huge integer each, k bits per slot. A single big.Int.Mul, and with
+// it math/big's Karatsuba and Toom-3, then multiplies them, and the product's
+// slots are the coefficients of a*b. k is chosen so that no coefficient of
+// the product, each a sum of at most min(len(a), len(b)) products, can spill
+// into the next slot.
+//
+// Negative coefficients are allowed: a packed value is just Σ c_i 2^(k*i)
+// evaluated exactly, and unpacking reads every slot as a signed k-bit digit,
+// borrowing from the next slot when the digit is negative.
+func MulKronecker(a, b []*big.Int) []*big.Int {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	k := slotBits(a, b)
+	c := new(big.Int).Mul(pack(a, k), pack(b, k))
+	return unpack(c, k, len(a)+len(b)-1)
+}
+
+// slotBits returns the slot width k for the product of a and b: every
+// coefficient of a*b lies strictly between -2^(k-1) and 2^(k-1).
+func slotBits(a, b []*big.Int) uint {
+	terms := uint(bits.Len(uint(min(len(a), len(b)))))
+	// |c| ≤ terms * max|a| * max|b|, plus one bit for the sign
+	return uint(maxBitLen(a)+maxBitLen(b)) + terms + 1
+}
+
+func maxBitLen(a []*big.Int) int {
+	m := 0
+	for _, x := range a {
+		m = max(m, x.BitLen())
+	}
+	return m
+}
+
+// pack returns Σ a[i] 2^(k*i). Non-negative coefficients are OR-ed into one
+// word buffer at their slot offsets; negative ones go into a second buffer
+// that is subtracted at the end, so packing is linear in the total size.
+func pack(a []*big.Int, k uint) *big.Int {
+	n := (uint(len(a))*k + bits.UintSize - 1) / bits.UintSize
+	var pos, neg []big.Word
+	for i, x := range a {
+		switch x.Sign() {
+		case 1:
+			if pos == nil {
+				pos = make([]big.Word, n+1)
+			}
+			orShifted(pos, x.Bits(), uint(i)*k)
+		case -1:
+			if neg == nil {
+				neg = make([]big.Word, n+1)
+			}
+			orShifted(neg, x.Bits(), uint(i)*k)
+		}
+	}
+	p := new(big.Int).SetBits(pos)
+	if neg != nil {
+		p.Sub(p, new(big.Int).SetBits(neg))
+	}
+	return p
+}
+
+// orShifted ORs x << off into dst. The slots of pack never overlap, so OR
+// is addition here.
+func orShifted(dst, x []big.Word, off uint) {
+	w, s := off/bits.UintSize, off%bits.UintSize
+	for i, xi := range x {
+		dst[w+uint(i)] |= xi << s
+		if s != 0 {
+			dst[w+uint(i)+1] |= xi >> (bits.UintSize - s)
+		}
+	}
+}
+
+// unpack splits c = Σ d_i 2^(k*i) into n signed k-bit digits d_i with
+// |d_i| < 2^(k-1).
+func unpack(c *big.Int, k uint, n int) []*big.Int {
+	neg := c.Sign() < 0
+	words := c.Bits() // magnitude; the sign is applied to every digit at the end
+
+	out := make([]*big.Int, n)
+	half := new(big.Int).Lsh(big.NewInt(1), k-1)
+	full := new(big.Int).Lsh(big.NewInt(1), k)
+	borrow := false
+	for i := range n {
+		d := extract(words, uint(i)*k, k)
+		if borrow {
+			d.Add(d, big.NewInt(1))
+		}
+		borrow = d.Cmp(half) >= 0
+		if borrow {
+			d.Sub(d, full)
+		}
+		if neg {
+			d.Neg(d)
+		}
+		out[i] = d
+	}
+	return out
+}
+
+// extract returns bits [off, off+k) of the non-negative integer with
+// magnitude words.
+func extract(words []big.Word, off, k uint) *big.Int {
+	w, s := off/bits.UintSize, off%bits.UintSize
+	n := (k + bits.UintSize - 1) / bits.UintSize
+	out := make([]big.Word, n)
+	for i := range n {
+		j := w + i
+		if j >= uint(len(words)) {
+			break
+		}
+		out[i] = words[j] >> s
+		if s != 0 && j+1 < uint(len(words)) {
+			out[i] |= words[j+1] << (bits.UintSize - s)
+		}
+	}
+	// Clear the bits above k in the top word
+	if r := k % bits.UintSize; r != 0 {
+		out[n-1] &= 1<<r - 1
+	}
+	return new(big.Int).SetBits(out)
+}
@@ -0,0 +1,89 @@
+package poly
+
+import (
+	"math/big"
+	"math/rand/v2"
+	"testing"
+	"testing/quick"
+)
+
+func TestMulKronecker(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(1, 1))
+	big1 := new(big.Int).Lsh(big.NewInt(1), 200)
+	tests := []struct {
+		name string
+		a, b []*big.Int
+	}{
+		{"(1+x)^2", ints(1, 1), ints(1, 1)},
+		{"signed", ints(1, -1), ints(1, 1)},
+		{"negative leading product", ints(-5, -7), ints(3, 2)},
+		{"all negative", ints(-1, -2, -3), ints(-4, -5)},
+		{"zeros inside", ints(0, 0, 5, 0), ints(0, 3)},
+		{"all zero", ints(0, 0), ints(0, 0, 0)},
+		{"constant", ints(3), ints(1, 2, 3)},
+		{"wide coefficients", []*big.Int{big1, new(big.Int).Neg(big1), big.NewInt(1)}, []*big.Int{big1, big1}},
+		{"zero polynomial", nil, ints(1, 2)},
+		{"random unsigned", randomPoly(rng, 50, 128, false), randomPoly(rng, 70, 64, false)},
+		{"random signed", randomPoly(rng, 64, 256, true), randomPoly(rng, 64, 256, true)},
+		{"odd slot width", randomPoly(rng, 9, 37, true), randomPoly(rng, 5, 3, true)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			want := MulSchoolbook(tc.a, tc.b)
+			if got := MulKronecker(tc.a, tc.b); !equal(got, want) {
+				t.Errorf("MulKronecker() = %v, want %v", got, want)
+			}
+			if got := Mul(tc.a, tc.b); !equal(got, want) {
+				t.Errorf("Mul() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMulKroneckerProperty(t *testing.T) {
+	t.Parallel()
+
+	err := quick.Check(func(a, b []int64) bool {
+		pa, pb := ints(a...), ints(b...)
+		return equal(MulKronecker(pa, pb), MulSchoolbook(pa, pb))
+	}, &quick.Config{MaxCount: 500})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_extract(t *testing.T) {
+	t.Parallel()
+
+	x, _ := new(big.Int).SetString("123456789abcdef0fedcba9876543210", 16)
+	words := x.Bits()
+	for _, tc := range []struct{ off, k uint }{{0, 64}, {4, 8}, {60, 8}, {64, 64}, {100, 40}, {120, 16}, {200, 8}} {
+		want := new(big.Int).Rsh(x, tc.off)
+		want.And(want, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), tc.k), big.NewInt(1)))
+		if got := extract(words, tc.off, tc.k); got.Cmp(want) != 0 {
+			t.Errorf("extract(off=%d, k=%d) = %x, want %x", tc.off, tc.k, got, want)
+		}
+	}
+}
+
+// BenchmarkMul compares schoolbook against Kronecker substitution for
+// polynomials with 256-bit coefficients; the crossover sets kroneckerThreshold.
+func BenchmarkMul(b *testing.B) {
+	rng := rand.New(rand.NewPCG(3, 3))
+	for _, n := range []int{8, 16, 64, 256} {
+		x, y := randomPoly(rng, n, 256, false), randomPoly(rng, n, 256, false)
+		b.Run(big.NewInt(int64(n)).String()+"/Schoolbook", func(b *testing.B) {
+			for b.Loop() {
+				MulSchoolbook(x, y)
+			}
+		})
+		b.Run(big.NewInt(int64(n)).String()+"/Kronecker", func(b *testing.B) {
+			for b.Loop() {
+				MulKronecker(x, y)
+			}
+		})
+	}
+}
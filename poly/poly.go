@@ -0,0 +1,80 @@
+// Package poly provides arithmetic on polynomials with big-integer
+// coefficients: multiplication over Z or over Z/NZ, arithmetic in
+// Z/pZ[x]/(h) including Brent–Kung modular composition, and the finite
+// fields GF(p^k) with their trace, norm and Frobenius.
+//
+// A polynomial is a []*big.Int of coefficients, lowest degree first, so
+// []*big.Int{1, 0, 3} is 1 + 3x². The empty slice is the zero polynomial.
+// Results are never trimmed of high zero coefficients: the product of
+// polynomials with m and n coefficients always has m+n-1.
+package poly
+
+import "math/big"
+
+// kroneckerThreshold is the shorter operand length from which Mul switches
+// from schoolbook to Kronecker substitution. Below it the packing and
+// unpacking overhead outweighs the faster integer multiplication; see
+// BenchmarkMul.
+const kroneckerThreshold = 16
+
+// Mul returns the product a*b over the integers, choosing schoolbook
+// multiplication for short operands and Kronecker substitution otherwise.
+func Mul(a, b []*big.Int) []*big.Int {
+	if min(len(a), len(b)) < kroneckerThreshold {
+		return MulSchoolbook(a, b)
+	}
+	return MulKronecker(a, b)
+}
+
+// MulMod returns a*b with coefficients reduced into [0, N). Reducing the
+// inputs first keeps the packed integers, and with them the cost of
+// MulKronecker, bounded by the size of N.
+func MulMod(a, b []*big.Int, N *big.Int) []*big.Int {
+	c := Mul(reduce(a, N), reduce(b, N))
+	for _, x := range c {
+		x.Mod(x, N)
+	}
+	return c
+}
+
+// MulSchoolbook returns a*b over the integers with the quadratic algorithm,
+// one big-integer product per pair of coefficients.
+func MulSchoolbook(a, b []*big.Int) []*big.Int {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	c := make([]*big.Int, len(a)+len(b)-1)
+	for i := range c {
+		c[i] = new(big.Int)
+	}
+	t := new(big.Int)
+	for i, x := range a {
+		for j, y := range b {
+			c[i+j].Add(c[i+j], t.Mul(x, y))
+		}
+	}
+	return c
+}
+
+// reduce returns a copy of a with every coefficient in [0, N).
+func reduce(a []*big.Int, N *big.Int) []*big.Int {
+	r := make([]*big.Int, len(a))
+	for i, x := range a {
+		r[i] = new(big.Int).Mod(x, N)
+	}
+	return r
+}
+
+// equal reports whether a and b have the same coefficients, including any
+// high zeros.
+func equal(a, b []*big.Int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Cmp(b[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package poly
+
+import (
+	"math/big"
+	"math/rand/v2"
+	"testing"
+)
+
+func ints(xs ...int64) []*big.Int {
+	r := make([]*big.Int, len(xs))
+	for i, x := range xs {
+		r[i] = big.NewInt(x)
+	}
+	return r
+}
+
+// randomPoly returns n coefficients of up to bitSize bits, negative ones
+// included when signed is set.
+func randomPoly(rng *rand.Rand, n, bitSize int, signed bool) []*big.Int {
+	p := make([]*big.Int, n)
+	for i := range p {
+		words := make([]big.Word, (bitSize+63)/64)
+		for j := range words {
+			words[j] = big.Word(rng.Uint64())
+		}
+		x := new(big.Int).SetBits(words)
+		x.Rsh(x, uint(len(words)*64-bitSize))
+		if signed && rng.IntN(2) == 0 {
+			x.Neg(x)
+		}
+		p[i] = x
+	}
+	return p
+}
+
+func TestMulSchoolbook(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		a, b []*big.Int
+		want []*big.Int
+	}{
+		{"(1+x)^2", ints(1, 1), ints(1, 1), ints(1, 2, 1)},
+		{"(1-x)(1+x)", ints(1, -1), ints(1, 1), ints(1, 0, -1)},
+		{"constant", ints(3), ints(1, 2, 3), ints(3, 6, 9)},
+		{"zero polynomial", nil, ints(1, 2), nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := MulSchoolbook(tc.a, tc.b); !equal(got, tc.want) {
+				t.Errorf("MulSchoolbook() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMulMod(t *testing.T) {
+	t.Parallel()
+
+	N := big.NewInt(7)
+	// (6 + 5x)(3 + 4x) = 18 + 39x + 20x² ≡ 4 + 4x + 6x² (mod 7), with inputs
+	// given unreduced and negative
+	got := MulMod(ints(-1, 12), ints(3, -3), N)
+	if want := ints(4, 4, 6); !equal(got, want) {
+		t.Errorf("MulMod() = %v, want %v", got, want)
+	}
+
+	rng := rand.New(rand.NewPCG(2, 2))
+	P, _ := new(big.Int).SetString("ffffffffffffffffffffffffffffffff000000000000000000000001", 16)
+	a, b := randomPoly(rng, 40, 300, true), randomPoly(rng, 33, 300, true)
+	want := MulSchoolbook(a, b)
+	for _, x := range want {
+		x.Mod(x, P)
+	}
+	if got := MulMod(a, b, P); !equal(got, want) {
+		t.Error("MulMod() disagrees with reduced schoolbook product")
+	}
+}
@@ -1,6 +1,7 @@
 package rabin
 
 import (
+	"context"
 	"crypto/rand"
 	"io"
 	"math/big"
@@ -28,9 +29,32 @@ func IsPrime(n int64) (bool, error) {
 	return isPrime(rand.Reader, big.NewInt(n), 20)
 }
 
+// ProbablyPrime runs count rounds of the Miller-Rabin test on p, drawing the
+// witnesses from random. Passing a deterministic source such as
+// math/rand/v2's ChaCha8 makes the outcome reproducible in tests and fuzzing.
+func ProbablyPrime(random io.Reader, p *big.Int, count int) (bool, error) {
+	return isPrime(random, p, count)
+}
+
+// Progress is called by ProbablyPrimeContext after each round that p passes
+// with the number of rounds done so far and the total requested.
+type Progress func(done, total int)
+
+// ProbablyPrimeContext is ProbablyPrime with cancellation and progress
+// reporting. ctx is checked before every round, so a cancelled test returns
+// ctx.Err() after at most one more modular exponentiation chain. progress may
+// be nil.
+func ProbablyPrimeContext(ctx context.Context, random io.Reader, p *big.Int, count int, progress Progress) (bool, error) {
+	return isPrimeContext(ctx, random, p, count, progress)
+}
+
 func isPrime(random io.Reader, p *big.Int, count int) (bool, error) {
+	return isPrimeContext(context.Background(), random, p, count, nil)
+}
+
+func isPrimeContext(ctx context.Context, random io.Reader, p *big.Int, count int, progress Progress) (bool, error) {
 	p = new(big.Int).Set(p)
-	if p.Cmp(one) == 0 {
+	if p.Cmp(one) <= 0 {
 		return false, nil
 	}
 	if p.Cmp(two) == 0 {
@@ -52,6 +76,12 @@ func isPrime(random io.Reader, p *big.Int, count int) (bool, error) {
 
 out:
 	for i := 0; i < count; i++ {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		if progress != nil && i > 0 {
+			progress(i, count)
+		}
 		a.Set(zero)
 		for a.Cmp(zero) == 0 {
 			// a is not zero
@@ -79,5 +109,8 @@ out:
 		// does not satisfy the prime number condition
 		return false, nil
 	}
+	if progress != nil {
+		progress(count, count)
+	}
 	return true, nil
 }
@@ -1,9 +1,13 @@
 package rabin
 
 import (
+	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
+	"io"
 	"math/big"
+	mrand "math/rand/v2"
 	"reflect"
 	"testing"
 )
@@ -106,6 +110,106 @@ func Test_exp(t *testing.T) {
 	}
 }
 
+// testRandom returns a deterministic randomness source for reproducible tests.
+func testRandom(seed uint64) io.Reader {
+	return mrand.NewChaCha8([32]byte{byte(seed), byte(seed >> 8), byte(seed >> 16), byte(seed >> 24)})
+}
+
+func TestProbablyPrime(t *testing.T) {
+	tests := []struct {
+		name string
+		p    *big.Int
+		want bool
+	}{
+		{name: "negative", p: big.NewInt(-7), want: false},
+		{name: "zero", p: big.NewInt(0), want: false},
+		{name: "one", p: big.NewInt(1), want: false},
+		{name: "2", p: big.NewInt(2), want: true},
+		{name: "Carmichael 561", p: big.NewInt(561), want: false},
+		{name: "Carmichael 41041", p: big.NewInt(41041), want: false},
+		{name: "2^61-1", p: new(big.Int).Sub(new(big.Int).Lsh(one, 61), one), want: true},
+		{name: "2^61+1", p: new(big.Int).Add(new(big.Int).Lsh(one, 61), one), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ProbablyPrime(testRandom(7), tt.p, 20)
+			if err != nil {
+				t.Fatalf("ProbablyPrime() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ProbablyPrime(%v) = %v, want %v", tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestProbablyPrime_deterministic checks that a fixed seed reproduces the
+// same verdict on a composite that single rounds occasionally miss.
+func TestProbablyPrime_deterministic(t *testing.T) {
+	n := big.NewInt(2047) // strong pseudoprime to base 2
+	first, err := ProbablyPrime(testRandom(3), n, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 10 {
+		got, err := ProbablyPrime(testRandom(3), n, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != first {
+			t.Errorf("ProbablyPrime() with the same seed = %v, want %v", got, first)
+		}
+	}
+}
+
+func TestProbablyPrimeContext(t *testing.T) {
+	p := new(big.Int).Sub(new(big.Int).Lsh(one, 127), one)
+
+	t.Run("progress", func(t *testing.T) {
+		var done []int
+		ok, err := ProbablyPrimeContext(context.Background(), testRandom(1), p, 5, func(d, total int) {
+			if total != 5 {
+				t.Errorf("progress total = %d, want 5", total)
+			}
+			done = append(done, d)
+		})
+		if err != nil || !ok {
+			t.Fatalf("ProbablyPrimeContext() = %v, %v; want true, nil", ok, err)
+		}
+		if !reflect.DeepEqual(done, []int{1, 2, 3, 4, 5}) {
+			t.Errorf("progress calls = %v, want [1 2 3 4 5]", done)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := ProbablyPrimeContext(ctx, testRandom(1), p, 5, nil)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("ProbablyPrimeContext() error = %v, want %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("cancelled from progress", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		rounds := 0
+		_, err := ProbablyPrimeContext(ctx, testRandom(1), p, 50, func(done, _ int) {
+			rounds = done
+			if done == 3 {
+				cancel()
+			}
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("ProbablyPrimeContext() error = %v, want %v", err, context.Canceled)
+		}
+		if rounds != 3 {
+			t.Errorf("rounds before cancellation = %d, want 3", rounds)
+		}
+	})
+}
+
 func TestXxx(t *testing.T) {
 	// d := new(big.Int).Sub(big.NewInt(11), one)
 	// s := big.NewInt(0)
@@ -0,0 +1,235 @@
+// Package residue provides quadratic residue tables for small moduli and
+// Legendre symbols evaluated in bulk.
+//
+// Sieving algorithms ask the same two questions over and over: is n a
+// square modulo each prime q of a factor base, and is each of many values a
+// square modulo one large prime p. The first is a table lookup once n mod q
+// is known, and n mod q for a whole factor base costs one pass over n per
+// group of primes whose product fits in a word. The second reduces, by
+// multiplicativity of the symbol and quadratic reciprocity, to table lookups
+// for every small prime factor of the value, with a general Jacobi
+// computation left only for the cofactor.
+package residue
+
+import (
+	"math/big"
+	"math/bits"
+	"sync"
+)
+
+// Table records the squares modulo a small modulus m, one bit per residue.
+type Table struct {
+	m   uint64
+	set []uint64
+}
+
+// NewTable returns the table of squares modulo m ≥ 1. It costs m/2 + 1
+// squarings and m bits of memory.
+func NewTable(m uint64) *Table {
+	if m == 0 {
+		panic("residue: modulus must be positive")
+	}
+	t := &Table{m: m, set: make([]uint64, (m+63)/64)}
+	// x and m - x have the same square
+	for x := uint64(0); x <= m/2; x++ {
+		hi, lo := bits.Mul64(x, x)
+		_, s := bits.Div64(hi%m, lo, m)
+		t.set[s/64] |= 1 << (s % 64)
+	}
+	return t
+}
+
+// Modulus returns m.
+func (t *Table) Modulus() uint64 { return t.m }
+
+// IsSquare reports whether a is a square modulo m, 0 included.
+func (t *Table) IsSquare(a uint64) bool {
+	a %= t.m
+	return t.set[a/64]>>(a%64)&1 == 1
+}
+
+// Legendre returns the Legendre symbol (a/m) for a prime modulus m: 0 if m
+// divides a, 1 if a is a nonzero square mod m, -1 otherwise. For composite m
+// it still distinguishes squares from non-squares, but that is not the
+// Jacobi symbol.
+func (t *Table) Legendre(a uint64) int {
+	switch a %= t.m; {
+	case a == 0:
+		return 0
+	case t.IsSquare(a):
+		return 1
+	}
+	return -1
+}
+
+// group is a run of consecutive factor-base primes whose product q fits in
+// a word, so one pass over a big integer yields its residue for all of them.
+type group struct {
+	q      uint
+	lo, hi int
+}
+
+// FactorBase holds residue tables for all odd primes below a bound.
+type FactorBase struct {
+	primes []uint64
+	tables []*Table
+	groups []group
+}
+
+// NewFactorBase precomputes residue tables for the odd primes below bound.
+// Memory grows with the sum of the primes, about bound²/(2 ln bound) bits,
+// so bounds in the tens of thousands are practical.
+func NewFactorBase(bound uint64) *FactorBase {
+	fb := &FactorBase{}
+	composite := make([]bool, bound)
+	for i := uint64(3); i < bound; i += 2 {
+		if composite[i] {
+			continue
+		}
+		fb.primes = append(fb.primes, i)
+		fb.tables = append(fb.tables, NewTable(i))
+		for j := i * i; j < bound; j += 2 * i {
+			composite[j] = true
+		}
+	}
+
+	for lo := 0; lo < len(fb.primes); {
+		q := uint(fb.primes[lo])
+		hi := lo + 1
+		for ; hi < len(fb.primes); hi++ {
+			h, l := bits.Mul(q, uint(fb.primes[hi]))
+			if h != 0 {
+				break
+			}
+			q = l
+		}
+		fb.groups = append(fb.groups, group{q: q, lo: lo, hi: hi})
+		lo = hi
+	}
+	return fb
+}
+
+// Primes returns the odd primes of the factor base in increasing order.
+func (fb *FactorBase) Primes() []uint64 {
+	return append([]uint64(nil), fb.primes...)
+}
+
+// Symbols returns the Legendre symbol (n/q) for every prime q of the factor
+// base, in the order of Primes. n may be negative.
+func (fb *FactorBase) Symbols(n *big.Int) []int {
+	s := make([]int, len(fb.primes))
+	for _, g := range fb.groups {
+		r := remWord(n.Bits(), g.q)
+		for i := g.lo; i < g.hi; i++ {
+			q := fb.primes[i]
+			ri := uint64(r) % q
+			if n.Sign() < 0 && ri != 0 {
+				ri = q - ri
+			}
+			s[i] = fb.tables[i].Legendre(ri)
+		}
+	}
+	return s
+}
+
+// LegendreBatch returns the Legendre symbol (a/p) for every a in as and an
+// odd prime p.
+//
+// (q/p) is computed once for every factor-base prime q by quadratic
+// reciprocity, (q/p) = (p/q) · (-1)^((p-1)/2 · (q-1)/2), which is a table
+// lookup of p mod q. Each value a is then stripped of its sign and its
+// factors 2 and factor-base primes, and its symbol assembled from theirs;
+// only a cofactor that does not factor over the base costs a general Jacobi
+// computation. Smooth values, as produced by sieving, never need one.
+func (fb *FactorBase) LegendreBatch(as []*big.Int, p *big.Int) []int {
+	if p.Bit(0) == 0 || p.Cmp(big.NewInt(3)) < 0 {
+		panic("residue: LegendreBatch requires an odd prime p")
+	}
+	chi := fb.Symbols(p)
+	pMod4, pMod8 := p.Bits()[0]%4, p.Bits()[0]%8
+	for i, q := range fb.primes {
+		if pMod4 == 3 && q%4 == 3 {
+			chi[i] = -chi[i]
+		}
+	}
+	chi2 := -1
+	if pMod8 == 1 || pMod8 == 7 {
+		chi2 = 1
+	}
+
+	out := make([]int, len(as))
+	r, quo := new(big.Int), new(big.Int)
+	qb, rem := new(big.Int), new(big.Int)
+	for k, a := range as {
+		if rem.Mod(a, p).Sign() == 0 {
+			continue
+		}
+		// Strip a itself rather than a mod p, which would destroy its
+		// smoothness; the cofactor is reduced only for the final Jacobi.
+		sym := 1
+		r.Abs(a)
+		if a.Sign() < 0 && pMod4 == 3 {
+			sym = -1
+		}
+		if tz := r.TrailingZeroBits(); tz > 0 {
+			r.Rsh(r, tz)
+			if tz%2 == 1 {
+				sym *= chi2
+			}
+		}
+		for _, g := range fb.groups {
+			if r.BitLen() <= 1 {
+				break
+			}
+			rg := remWord(r.Bits(), g.q)
+			for i := g.lo; i < g.hi; i++ {
+				q := fb.primes[i]
+				if uint64(rg)%q != 0 {
+					continue
+				}
+				qb.SetUint64(q)
+				odd := false
+				for {
+					quo.QuoRem(r, qb, rem)
+					if rem.Sign() != 0 {
+						break
+					}
+					r, quo = quo, r
+					odd = !odd
+				}
+				if odd {
+					sym *= chi[i]
+				}
+			}
+		}
+		if r.BitLen() > 1 {
+			sym *= big.Jacobi(r.Mod(r, p), p)
+		}
+		out[k] = sym
+	}
+	return out
+}
+
+var defaultFactorBase = sync.OnceValue(func() *FactorBase {
+	return NewFactorBase(defaultBound)
+})
+
+// defaultBound is the factor-base bound of LegendreBatch: primes below
+// 2^10 fit six or more to a word-size group product.
+const defaultBound = 1 << 10
+
+// LegendreBatch returns (a/p) for every a in as and an odd prime p, using a
+// shared factor base of the odd primes below 2^10; see
+// FactorBase.LegendreBatch.
+func LegendreBatch(as []*big.Int, p *big.Int) []int {
+	return defaultFactorBase().LegendreBatch(as, p)
+}
+
+// remWord returns x mod d for the magnitude x of a big.Int.
+func remWord(x []big.Word, d uint) uint {
+	var r uint
+	for i := len(x) - 1; i >= 0; i-- {
+		_, r = bits.Div(r, uint(x[i]), d)
+	}
+	return r
+}
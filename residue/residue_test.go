@@ -0,0 +1,212 @@
+package residue
+
+import (
+	"math/big"
+	"math/rand/v2"
+	"testing"
+	"testing/quick"
+)
+
+func TestTable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		m       uint64
+		squares []uint64
+	}{
+		{"mod 1", 1, []uint64{0}},
+		{"mod 2", 2, []uint64{0, 1}},
+		{"mod 7", 7, []uint64{0, 1, 2, 4}},
+		{"mod 8", 8, []uint64{0, 1, 4}},
+		{"mod 11", 11, []uint64{0, 1, 3, 4, 5, 9}},
+		{"mod 64", 64, []uint64{0, 1, 4, 9, 16, 17, 25, 33, 36, 41, 49, 57}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			tbl := NewTable(tc.m)
+			want := make(map[uint64]bool)
+			for _, s := range tc.squares {
+				want[s] = true
+			}
+			for a := range tc.m {
+				if got := tbl.IsSquare(a); got != want[a] {
+					t.Errorf("IsSquare(%d) = %v, want %v", a, got, want[a])
+				}
+				if got := tbl.IsSquare(a + 3*tc.m); got != want[a] {
+					t.Errorf("IsSquare(%d) = %v, want %v", a+3*tc.m, got, want[a])
+				}
+			}
+		})
+	}
+}
+
+// TestTable_allocs checks that lookups are allocation-free.
+func TestTable_allocs(t *testing.T) {
+	tbl := NewTable(1009)
+	allocs := testing.AllocsPerRun(100, func() {
+		tbl.IsSquare(123456789)
+		tbl.Legendre(987654321)
+	})
+	if allocs != 0 {
+		t.Errorf("IsSquare and Legendre = %v allocs/op, want 0", allocs)
+	}
+}
+
+func TestTableLegendre(t *testing.T) {
+	t.Parallel()
+
+	for _, p := range []uint64{3, 5, 13, 101, 1009, 65537} {
+		tbl := NewTable(p)
+		for a := range 3 * p {
+			want := big.Jacobi(new(big.Int).SetUint64(a), new(big.Int).SetUint64(p))
+			if got := tbl.Legendre(a); got != want {
+				t.Fatalf("Table(%d).Legendre(%d) = %d, want %d", p, a, got, want)
+			}
+		}
+	}
+}
+
+func TestFactorBase(t *testing.T) {
+	t.Parallel()
+
+	fb := NewFactorBase(100)
+	want := []uint64{3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47, 53, 59, 61, 67, 71, 73, 79, 83, 89, 97}
+	got := fb.Primes()
+	if len(got) != len(want) {
+		t.Fatalf("Primes() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Primes() = %v, want %v", got, want)
+		}
+	}
+	if len(NewFactorBase(3).Primes()) != 0 {
+		t.Error("NewFactorBase(3) is not empty")
+	}
+}
+
+func TestSymbols(t *testing.T) {
+	t.Parallel()
+
+	fb := NewFactorBase(5000)
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890123456789012345678901234567890", 10)
+	for _, n := range []*big.Int{
+		big.NewInt(0), big.NewInt(1), big.NewInt(-1), big.NewInt(2),
+		big.NewInt(3 * 5 * 7), big.NewInt(-1000003), huge, new(big.Int).Neg(huge),
+	} {
+		got := fb.Symbols(n)
+		for i, q := range fb.Primes() {
+			want := big.Jacobi(new(big.Int).Mod(n, new(big.Int).SetUint64(q)), new(big.Int).SetUint64(q))
+			if got[i] != want {
+				t.Fatalf("Symbols(%v)[%d] = %d, want (n/%d) = %d", n, i, got[i], q, want)
+			}
+		}
+	}
+}
+
+func TestLegendreBatch(t *testing.T) {
+	t.Parallel()
+
+	p256, _ := new(big.Int).SetString("ffffffff00000001000000000000000000000000ffffffffffffffffffffffff", 16)
+	tests := []struct {
+		name string
+		p    *big.Int
+	}{
+		{"p = 3", big.NewInt(3)},
+		{"p ≡ 1 mod 8", big.NewInt(17)},
+		{"p ≡ 3 mod 8", big.NewInt(11)},
+		{"p ≡ 5 mod 8", big.NewInt(13)},
+		{"p ≡ 7 mod 8", big.NewInt(23)},
+		{"p inside factor base", big.NewInt(1009)},
+		{"P-256 prime", p256},
+	}
+	rng := rand.New(rand.NewPCG(1, 1))
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			as := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(-1), big.NewInt(2), big.NewInt(-8), tc.p, new(big.Int).Add(tc.p, big.NewInt(4))}
+			for range 50 {
+				as = append(as, smooth(rng), random(rng, tc.p.BitLen()+2))
+			}
+			got := LegendreBatch(as, tc.p)
+			for i, a := range as {
+				if want := big.Jacobi(new(big.Int).Mod(a, tc.p), tc.p); got[i] != want {
+					t.Errorf("LegendreBatch()[%d] = (%v/p) = %d, want %d", i, a, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestLegendreBatchProperty(t *testing.T) {
+	t.Parallel()
+
+	p := big.NewInt(1000003)
+	fb := NewFactorBase(200)
+	err := quick.Check(func(xs []int64) bool {
+		as := make([]*big.Int, len(xs))
+		for i, x := range xs {
+			as[i] = big.NewInt(x)
+		}
+		got := fb.LegendreBatch(as, p)
+		for i, a := range as {
+			if got[i] != big.Jacobi(new(big.Int).Mod(a, p), p) {
+				return false
+			}
+		}
+		return true
+	}, nil)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// random returns a random integer of at most n bits.
+func random(rng *rand.Rand, n int) *big.Int {
+	words := make([]big.Word, (n+63)/64)
+	for i := range words {
+		words[i] = big.Word(rng.Uint64())
+	}
+	x := new(big.Int).SetBits(words)
+	return x.Rsh(x, uint(64*len(words)-n))
+}
+
+// smooth returns a product of random primes below 2^10 with random signs.
+func smooth(rng *rand.Rand) *big.Int {
+	primes := defaultFactorBase().primes
+	x := big.NewInt(1)
+	for range rng.IntN(30) {
+		x.Mul(x, new(big.Int).SetUint64(primes[rng.IntN(len(primes))]))
+	}
+	x.Lsh(x, uint(rng.IntN(5)))
+	if rng.IntN(2) == 0 {
+		x.Neg(x)
+	}
+	return x
+}
+
+// BenchmarkLegendre compares LegendreBatch against one big.Jacobi per value
+// on 1000 smooth values modulo a 256-bit prime, the sieving workload.
+func BenchmarkLegendre(b *testing.B) {
+	rng := rand.New(rand.NewPCG(2, 2))
+	p, _ := new(big.Int).SetString("ffffffff00000001000000000000000000000000ffffffffffffffffffffffff", 16)
+	as := make([]*big.Int, 1000)
+	for i := range as {
+		as[i] = smooth(rng)
+	}
+	b.Run("Batch", func(b *testing.B) {
+		for b.Loop() {
+			LegendreBatch(as, p)
+		}
+	})
+	b.Run("Jacobi", func(b *testing.B) {
+		r := new(big.Int)
+		for b.Loop() {
+			for _, a := range as {
+				big.Jacobi(r.Mod(a, p), p)
+			}
+		}
+	})
+}
@@ -0,0 +1,65 @@
+// Package stein provides a modular inverse for word-size moduli based on
+// Stein's binary GCD algorithm.
+//
+// The binary extended GCD needs only shifts, subtractions and comparisons,
+// never a division, and with the swap and halving corrections done by masks
+// the loop body has no data-dependent branches apart from the loop
+// conditions themselves. That makes it a good fit for small moduli where
+// Fermat inversion (a^(n-2)) costs about 64 squarings and Newton iteration
+// only works modulo powers of two.
+package stein
+
+import "math/bits"
+
+// Inverse returns a⁻¹ mod n and true, or 0 and false when a has no inverse
+// mod n. The modulus n must be odd and greater than 1; for even n Inverse
+// returns false.
+//
+// The loop keeps the invariants x1*a ≡ u and x2*a ≡ v (mod n) with v odd:
+// u is made odd by stripping its trailing zeros (halving x1 alongside), the
+// pair is swapped by mask so that u ≥ v, and then u -= v. When u reaches 0,
+// v = gcd(a, n) and x2 is the inverse if that gcd is 1.
+func Inverse(a, n uint64) (uint64, bool) {
+	if n&1 == 0 || n == 1 {
+		return 0, false
+	}
+
+	u, v := a%n, n
+	x1, x2 := uint64(1), uint64(0)
+	for u != 0 {
+		// Make u odd; each halving of u halves x1 mod n too
+		tz := bits.TrailingZeros64(u)
+		u >>= tz
+		for range tz {
+			x1 = halve(x1, n)
+		}
+
+		// Swap so that u ≥ v, selected by the borrow of u - v
+		_, borrow := bits.Sub64(u, v, 0)
+		swap := -borrow
+		u, v = u^(swap&(u^v)), v^(swap&(u^v))
+		x1, x2 = x1^(swap&(x1^x2)), x2^(swap&(x1^x2))
+
+		u -= v
+		x1 = subMod(x1, x2, n)
+	}
+
+	if v != 1 {
+		return 0, false
+	}
+	return x2, true
+}
+
+// halve returns x/2 mod n for x in [0, n) and odd n.
+//
+// For odd x it computes (x + n) / 2 as x>>1 + n>>1 + 1, which cannot
+// overflow even when n is close to 2^64.
+func halve(x, n uint64) uint64 {
+	return x>>1 + (n>>1+1)&-(x&1)
+}
+
+// subMod returns (x - y) mod n for x, y in [0, n).
+func subMod(x, y, n uint64) uint64 {
+	d, borrow := bits.Sub64(x, y, 0)
+	return d + n&-borrow
+}
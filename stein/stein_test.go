@@ -0,0 +1,126 @@
+package stein
+
+import (
+	"math/big"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestInverse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		a      uint64
+		n      uint64
+		want   uint64
+		wantOK bool
+	}{
+		{"3^-1 mod 7", 3, 7, 5, true},
+		{"1^-1 mod 11", 1, 11, 1, true},
+		{"a greater than n", 10, 7, 5, true},
+		{"a = 0", 0, 7, 0, false},
+		{"a = n", 7, 7, 0, false},
+		{"not coprime", 6, 9, 0, false},
+		{"n = 1", 5, 1, 0, false},
+		{"even n", 3, 8, 0, false},
+		{"n = 2^64 - 1", 2, 0xffffffffffffffff, 0x8000000000000000, true},
+		{"n - 1 is self-inverse", 0xfffffffffffffffa, 0xfffffffffffffffb, 0xfffffffffffffffa, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := Inverse(tc.a, tc.n)
+			if got != tc.want || ok != tc.wantOK {
+				t.Errorf("Inverse(%d, %d) = (%d, %v); want (%d, %v)", tc.a, tc.n, got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+// TestInverse_smallExhaustive checks every pair with an odd modulus below 512.
+func TestInverse_smallExhaustive(t *testing.T) {
+	t.Parallel()
+
+	for n := uint64(3); n < 512; n += 2 {
+		for a := range n {
+			checkInverse(t, a, n)
+		}
+	}
+}
+
+// TestInverse_randomWords checks random full-width word pairs against
+// big.Int.ModInverse.
+func TestInverse_randomWords(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	for range 200000 {
+		n := rng.Uint64() | 1
+		if n == 1 {
+			continue
+		}
+		checkInverse(t, rng.Uint64(), n)
+	}
+}
+
+func checkInverse(t *testing.T, a, n uint64) {
+	t.Helper()
+
+	bn := new(big.Int).SetUint64(n)
+	want := new(big.Int).ModInverse(new(big.Int).SetUint64(a), bn)
+	got, ok := Inverse(a, n)
+	switch {
+	case want == nil && ok:
+		t.Fatalf("Inverse(%d, %d) = (%d, true); want no inverse", a, n, got)
+	case want != nil && !ok:
+		t.Fatalf("Inverse(%d, %d) = (_, false); want %v", a, n, want)
+	case want != nil && want.Uint64() != got:
+		t.Fatalf("Inverse(%d, %d) = %d; want %v", a, n, got, want)
+	}
+}
+
+func Test_halve(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(3, 4))
+	for range 10000 {
+		n := rng.Uint64() | 1
+		x := rng.Uint64() % n
+		h := halve(x, n)
+		// 2h ≡ x (mod n)
+		twice := new(big.Int).Lsh(new(big.Int).SetUint64(h), 1)
+		twice.Mod(twice, new(big.Int).SetUint64(n))
+		if h >= n || twice.Uint64() != x {
+			t.Fatalf("halve(%d, %d) = %d", x, n, h)
+		}
+	}
+}
+
+// TestInverse_allocs guards the word-only loop against heap allocations.
+func TestInverse_allocs(t *testing.T) {
+	const n = 0xffffffff00000001
+	if allocs := testing.AllocsPerRun(100, func() { Inverse(0x123456789abcdef, n) }); allocs != 0 {
+		t.Errorf("Inverse() = %v allocs/op, want 0", allocs)
+	}
+}
+
+func BenchmarkInverse(b *testing.B) {
+	const n = 0xffffffff00000001 // Goldilocks prime
+	a := uint64(0x123456789abcdef)
+
+	b.Run("Stein", func(b *testing.B) {
+		for b.Loop() {
+			Inverse(a, n)
+		}
+	})
+
+	b.Run("BigInt/ModInverse", func(b *testing.B) {
+		ba := new(big.Int).SetUint64(a)
+		bn := new(big.Int).SetUint64(n)
+		for b.Loop() {
+			new(big.Int).ModInverse(ba, bn)
+		}
+	})
+}
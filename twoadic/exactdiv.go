@@ -0,0 +1,115 @@
+package twoadic
+
+import (
+	"math/big"
+	"math/bits"
+	"unsafe"
+)
+
+// ExactDiv returns a / b for b that is known to divide a exactly.
+//
+// Jebelean observed that when the remainder is known to be zero, the quotient
+// is determined by the low bits alone: q = a * b⁻¹ mod 2^k, where k is the
+// bit length of the quotient. Only the low k bits of a and b are read, so
+// no remainder is ever formed. For multi-word divisors math/big's division
+// is heavily tuned and usually still wins; the large gain is for single-word
+// divisors, see ExactDivWord, which is what Toom-Cook interpolation (division
+// by 2, 3, 6, ...) and product-tree algorithms need.
+//
+// If b does not divide a the result is meaningless. ExactDiv panics if b is 0.
+func ExactDiv(a, b *big.Int) *big.Int {
+	if b.Sign() == 0 {
+		panic("twoadic: division by zero")
+	}
+	neg := a.Sign()*b.Sign() < 0
+	aa := new(big.Int).Abs(a)
+	bb := new(big.Int).Abs(b)
+
+	// Strip the common power of two so the divisor becomes odd
+	tz := bb.TrailingZeroBits()
+	aa.Rsh(aa, tz)
+	bb.Rsh(bb, tz)
+
+	k := aa.BitLen() - bb.BitLen() + 1
+	if k <= 0 {
+		return new(big.Int)
+	}
+
+	inv, err := Inverse(bb, uint(k))
+	if err != nil {
+		// unreachable: bb is odd after stripping trailing zeros
+		panic(err)
+	}
+	q := mod2k(aa, uint(k))
+	q.Mul(q, inv)
+	q = mod2k(q, uint(k))
+	if neg {
+		q.Neg(q)
+	}
+	return q
+}
+
+// ExactDivWord sets z = a / d for a single-word divisor d that is known to
+// divide a exactly, with a and z as little-endian 64-bit limbs. It returns z.
+//
+// This is Jebelean's word-by-word exact division: each quotient limb is the
+// current limb times d⁻¹ mod 2^64, and only the high half of q*d needs to be
+// carried into the next limb as a borrow. z must have len(a) limbs and may
+// be a itself for an in-place division, but must not otherwise overlap it.
+// ExactDivWord panics if d is 0 or z partially overlaps a.
+func ExactDivWord(z, a []uint64, d uint64) []uint64 {
+	if d == 0 {
+		panic("twoadic: division by zero")
+	}
+	z = z[:len(a)]
+	if overlap(z, a) && (len(a) == 0 || &z[0] != &a[0]) {
+		panic("twoadic: ExactDivWord output partially overlaps its input")
+	}
+
+	// Strip the power of two from d by shifting a right first
+	if s := uint(bits.TrailingZeros64(d)); s > 0 {
+		d >>= s
+		for i := range a {
+			hi := uint64(0)
+			if i+1 < len(a) {
+				hi = a[i+1] << (64 - s)
+			}
+			z[i] = a[i]>>s | hi
+		}
+		a = z
+	}
+
+	dinv := wordInverse(d)
+	borrow := uint64(0)
+	for i, s := range a {
+		l, b := bits.Sub64(s, borrow, 0)
+		q := l * dinv
+		z[i] = q
+		hi, _ := bits.Mul64(q, d)
+		borrow = hi + b
+	}
+	return z
+}
+
+// wordInverse computes d⁻¹ mod 2^64 for odd d with the same Newton
+// iteration as Inverse, unrolled for a single word.
+func wordInverse(d uint64) uint64 {
+	x := d            // 3 bits
+	x = x * (2 - d*x) // 6 bits
+	x = x * (2 - d*x) // 12 bits
+	x = x * (2 - d*x) // 24 bits
+	x = x * (2 - d*x) // 48 bits
+	x = x * (2 - d*x) // 96 bits
+	return x
+}
+
+// overlap reports whether x and y share at least one limb, comparing the
+// address ranges they cover, so slices whose capacity was cut with a 3-index
+// slice expression are caught too.
+func overlap(x, y []uint64) bool {
+	if len(x) == 0 || len(y) == 0 {
+		return false
+	}
+	xs, ys := uintptr(unsafe.Pointer(&x[0])), uintptr(unsafe.Pointer(&y[0]))
+	return xs < ys+uintptr(len(y))*8 && ys < xs+uintptr(len(x))*8
+}
@@ -0,0 +1,187 @@
+package twoadic
+
+import (
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestExactDiv(t *testing.T) {
+	t.Parallel()
+
+	p127 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+	tests := []struct {
+		name string
+		q    *big.Int
+		b    *big.Int
+	}{
+		{"divide by 3", big.NewInt(123456789), big.NewInt(3)},
+		{"divide by 1", big.NewInt(42), big.NewInt(1)},
+		{"even divisor", big.NewInt(987654321), big.NewInt(96)},
+		{"power of two divisor", big.NewInt(55), big.NewInt(1024)},
+		{"zero dividend", big.NewInt(0), big.NewInt(7)},
+		{"negative dividend", big.NewInt(-1234), big.NewInt(17)},
+		{"negative divisor", big.NewInt(1234), big.NewInt(-17)},
+		{"both negative", big.NewInt(-1234), big.NewInt(-17)},
+		{"wide operands", new(big.Int).Lsh(p127, 300), p127},
+		{"quotient 1", p127, big.NewInt(1)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			a := new(big.Int).Mul(tc.q, tc.b)
+			if got := ExactDiv(a, tc.b); got.Cmp(tc.q) != 0 {
+				t.Errorf("ExactDiv(%v, %v) = %v; want %v", a, tc.b, got, tc.q)
+			}
+		})
+	}
+}
+
+func TestExactDivProperty(t *testing.T) {
+	t.Parallel()
+
+	err := quick.Check(func(qBytes, bBytes []byte, negQ, negB bool) bool {
+		q := new(big.Int).SetBytes(qBytes)
+		b := new(big.Int).SetBytes(bBytes)
+		if b.Sign() == 0 {
+			b.SetInt64(1)
+		}
+		if negQ {
+			q.Neg(q)
+		}
+		if negB {
+			b.Neg(b)
+		}
+		a := new(big.Int).Mul(q, b)
+		return ExactDiv(a, b).Cmp(q) == 0
+	}, &quick.Config{MaxCount: 500})
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExactDivWord(t *testing.T) {
+	t.Parallel()
+
+	err := quick.Check(func(qWords []uint64, d uint64, inPlace bool) bool {
+		if d == 0 {
+			d = 3
+		}
+		q := toBig(qWords)
+		a := new(big.Int).Mul(q, new(big.Int).SetUint64(d))
+
+		limbs := fromBig(a, len(qWords)+1)
+		z := make([]uint64, len(limbs))
+		if inPlace {
+			z = limbs
+		}
+		got := toBig(ExactDivWord(z, limbs, d))
+		return got.Cmp(q) == 0
+	}, &quick.Config{MaxCount: 500})
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// TestExactDivWord_allocs checks that ExactDivWord writes only into the
+// caller's z.
+func TestExactDivWord_allocs(t *testing.T) {
+	a := []uint64{0x0123456789abcdef, 0xfedcba9876543210, 0x0f0f0f0f0f0f0f0f, 1}
+	z := make([]uint64, len(a))
+	for _, d := range []uint64{3, 12} { // odd, and with a power of two to strip
+		if allocs := testing.AllocsPerRun(100, func() { ExactDivWord(z, a, d) }); allocs != 0 {
+			t.Errorf("ExactDivWord(d = %d) = %v allocs/op, want 0", d, allocs)
+		}
+	}
+}
+
+func TestExactDivWord_overlap(t *testing.T) {
+	t.Parallel()
+
+	buf := []uint64{21, 0, 0, 0}
+	if got := ExactDivWord(buf[:2], buf[:2], 7); got[0] != 3 || got[1] != 0 {
+		t.Errorf("in place: got %v, want [3 0]", got)
+	}
+
+	// Disjoint parts of one array are fine
+	buf = []uint64{35, 0, 9, 9}
+	if got := ExactDivWord(buf[2:], buf[:2], 5); got[0] != 7 || got[1] != 0 {
+		t.Errorf("disjoint rows: got %v, want [7 0]", got)
+	}
+
+	// Cutting the capacity with a 3-index slice does not hide the overlap
+	for name, z := range map[string][]uint64{"shifted": buf[1:3], "capacity limited": buf[1:3:3]} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: no panic for a partially overlapping output", name)
+				}
+			}()
+			ExactDivWord(z, buf[:2:2], 5)
+		}()
+	}
+}
+
+func Test_wordInverse(t *testing.T) {
+	t.Parallel()
+
+	err := quick.Check(func(d uint64) bool {
+		d |= 1
+		return d*wordInverse(d) == 1
+	}, &quick.Config{MaxCount: 1000})
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// toBig converts little-endian 64-bit limbs to a big.Int.
+func toBig(words []uint64) *big.Int {
+	x := new(big.Int)
+	for i := len(words) - 1; i >= 0; i-- {
+		x.Lsh(x, 64)
+		x.Or(x, new(big.Int).SetUint64(words[i]))
+	}
+	return x
+}
+
+// fromBig converts x ≥ 0 to n little-endian 64-bit limbs.
+func fromBig(x *big.Int, n int) []uint64 {
+	words := make([]uint64, n)
+	mask := new(big.Int).SetUint64(^uint64(0))
+	t := new(big.Int).Set(x)
+	for i := range words {
+		words[i] = new(big.Int).And(t, mask).Uint64()
+		t.Rsh(t, 64)
+	}
+	return words
+}
+
+func BenchmarkExactDiv(b *testing.B) {
+	q := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 4096), big.NewInt(159))
+	d := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 2048), big.NewInt(1))
+	a := new(big.Int).Mul(q, d)
+
+	b.Run("ExactDiv", func(b *testing.B) {
+		for b.Loop() {
+			ExactDiv(a, d)
+		}
+	})
+
+	b.Run("BigInt/Quo", func(b *testing.B) {
+		for b.Loop() {
+			new(big.Int).Quo(a, d)
+		}
+	})
+
+	b.Run("ExactDivWord/3", func(b *testing.B) {
+		a3 := fromBig(new(big.Int).Mul(q, big.NewInt(3)), 65)
+		z := make([]uint64, len(a3))
+		for b.Loop() {
+			ExactDivWord(z, a3, 3)
+		}
+	})
+}
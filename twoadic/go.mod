@@ -0,0 +1,3 @@
+module github.com/blck-snwmn/arithmetic-vault/twoadic
+
+go 1.25.5
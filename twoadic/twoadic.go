@@ -0,0 +1,153 @@
+// Package twoadic provides arithmetic modulo 2^k at arbitrary precision.
+//
+// Powers of two are the one modulus where reduction is free (a mask), which
+// makes 2-adic Newton iteration the workhorse behind Montgomery's −N⁻¹ mod 2^64,
+// exact division and similar tricks. This package exposes those iterations
+// for any precision k instead of a fixed machine word.
+package twoadic
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	// ErrEven is returned when an odd value is required but an even one is given.
+	ErrEven = errors.New("twoadic: value must be odd")
+	// ErrNotSquare is returned by Sqrt when no square root exists mod 2^k.
+	ErrNotSquare = errors.New("twoadic: value is not a square mod 2^k")
+	// ErrNoRoot is returned by LiftRoot when the starting value is not a root mod 2.
+	ErrNoRoot = errors.New("twoadic: starting value is not a root mod 2")
+	// ErrSingularRoot is returned by LiftRoot when f'(r) is even and Newton's
+	// method cannot lift the root uniquely.
+	ErrSingularRoot = errors.New("twoadic: derivative at root is even")
+)
+
+var (
+	one   = big.NewInt(1)
+	three = big.NewInt(3)
+)
+
+// mod2k returns x mod 2^k in [0, 2^k), also for negative x.
+func mod2k(x *big.Int, k uint) *big.Int {
+	mask := new(big.Int).Lsh(one, k)
+	mask.Sub(mask, one)
+	// big.Int.And uses two's complement semantics for negative operands
+	return new(big.Int).And(x, mask)
+}
+
+// Inverse computes a⁻¹ mod 2^k for odd a.
+//
+// It uses the Newton iteration x = x * (2 - a*x), which doubles the number
+// of correct low bits per step. The seed x = a is already correct mod 8
+// because every odd square is 1 mod 8.
+func Inverse(a *big.Int, k uint) (*big.Int, error) {
+	if a.Bit(0) == 0 {
+		return nil, ErrEven
+	}
+	a = mod2k(a, k)
+	if k <= 3 {
+		return a, nil
+	}
+
+	x := mod2k(a, 3)
+	t := new(big.Int)
+	for prec := uint(3); prec < k; {
+		prec = min(2*prec, k)
+		// t = 2 - a*x, where only the low prec bits of a matter
+		t.Mul(mod2k(a, prec), x)
+		t.Sub(big.NewInt(2), t)
+		x.Mul(x, t)
+		x = mod2k(x, prec)
+	}
+	return x, nil
+}
+
+// Sqrt computes r with r² ≡ a (mod 2^k) for odd a.
+//
+// An odd a has a square root mod 2^k exactly when a ≡ 1 (mod 2^min(k, 3)).
+// The root is found via the Newton iteration y = y * (3 - a*y²) / 2 for the
+// inverse square root, after which r = a*y. Each step takes y from precision
+// p to 2p - 2; the halving costs the one bit. The result is one of the four
+// (for k ≥ 3) square roots; the others are −r and r + 2^(k-1) and −r + 2^(k-1).
+func Sqrt(a *big.Int, k uint) (*big.Int, error) {
+	if a.Bit(0) == 0 {
+		return nil, ErrEven
+	}
+	a = mod2k(a, k)
+	if mod2k(a, min(k, 3)).Cmp(mod2k(one, min(k, 3))) != 0 {
+		return nil, ErrNotSquare
+	}
+	if k <= 3 {
+		return mod2k(one, k), nil
+	}
+
+	// a*y² ≡ 1 (mod 2^prec) holds for y = 1 since a ≡ 1 (mod 8)
+	y := big.NewInt(1)
+	t := new(big.Int)
+	for prec := uint(3); prec < k; {
+		prec = min(2*prec-2, k)
+		// t = (3 - a*y²) / 2, computed with one guard bit for the halving
+		t.Mul(y, y)
+		t.Mul(t, a)
+		t.Sub(three, t)
+		t = mod2k(t, prec+1)
+		t.Rsh(t, 1)
+		y.Mul(y, t)
+		y = mod2k(y, prec)
+	}
+	return mod2k(y.Mul(y, a), k), nil
+}
+
+// LiftRoot lifts a simple root of the polynomial f from mod 2 to mod 2^k
+// (Hensel's lemma).
+//
+// Coefficients are given in ascending order, f[0] + f[1]x + f[2]x² + ...
+// The starting value r must satisfy f(r) ≡ 0 (mod 2) and f'(r) ≡ 1 (mod 2);
+// the Newton step r = r - f(r)/f'(r) then doubles the precision each round
+// and the lifted root is unique mod 2^k.
+func LiftRoot(f []*big.Int, r *big.Int, k uint) (*big.Int, error) {
+	df := derivative(f)
+	if eval(f, r).Bit(0) != 0 {
+		return nil, ErrNoRoot
+	}
+	if eval(df, r).Bit(0) == 0 {
+		return nil, ErrSingularRoot
+	}
+
+	r = mod2k(r, 1)
+	for prec := uint(1); prec < k; {
+		prec = min(2*prec, k)
+		inv, err := Inverse(eval(df, r), prec)
+		if err != nil {
+			return nil, err
+		}
+		step := eval(f, r)
+		step.Mul(step, inv)
+		r.Sub(r, step)
+		r = mod2k(r, prec)
+	}
+	return mod2k(r, k), nil
+}
+
+// eval evaluates f at x with Horner's rule.
+func eval(f []*big.Int, x *big.Int) *big.Int {
+	result := new(big.Int)
+	for i := len(f) - 1; i >= 0; i-- {
+		result.Mul(result, x)
+		result.Add(result, f[i])
+	}
+	return result
+}
+
+// derivative returns the formal derivative of f.
+func derivative(f []*big.Int) []*big.Int {
+	if len(f) <= 1 {
+		return nil
+	}
+	df := make([]*big.Int, len(f)-1)
+	for i := 1; i < len(f); i++ {
+		df[i-1] = new(big.Int).Mul(f[i], big.NewInt(int64(i)))
+	}
+	return df
+}
@@ -0,0 +1,222 @@
+package twoadic
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestInverse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		a    *big.Int
+		k    uint
+	}{
+		{"a=1 k=64", big.NewInt(1), 64},
+		{"a=3 k=1", big.NewInt(3), 1},
+		{"a=3 k=3", big.NewInt(3), 3},
+		{"a=0xabcdef0123456789 k=64", new(big.Int).SetUint64(0xabcdef0123456789), 64},
+		{"a=-7 k=100", big.NewInt(-7), 100},
+		{"a=2^64-1 k=4096", new(big.Int).SetUint64(0xffffffffffffffff), 4096},
+		{"a larger than 2^k", new(big.Int).Lsh(big.NewInt(12345), 80), 5},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			a := new(big.Int).Set(tc.a)
+			if a.Bit(0) == 0 {
+				a.Add(a, one)
+			}
+
+			inv, err := Inverse(a, tc.k)
+			if err != nil {
+				t.Fatalf("Inverse(%v, %d) error = %v", a, tc.k, err)
+			}
+			got := mod2k(new(big.Int).Mul(a, inv), tc.k)
+			if got.Cmp(mod2k(one, tc.k)) != 0 {
+				t.Errorf("a * Inverse(a) mod 2^%d = %v; want 1", tc.k, got)
+			}
+		})
+	}
+}
+
+func TestInverse_even(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Inverse(big.NewInt(10), 64); !errors.Is(err, ErrEven) {
+		t.Errorf("Inverse(10, 64) error = %v; want %v", err, ErrEven)
+	}
+}
+
+func TestInverseProperty(t *testing.T) {
+	t.Parallel()
+
+	err := quick.Check(func(aBytes []byte, k uint16) bool {
+		a := new(big.Int).SetBytes(aBytes)
+		a.SetBit(a, 0, 1)
+		kk := uint(k % 2048)
+
+		inv, err := Inverse(a, kk)
+		if err != nil {
+			return false
+		}
+		got := mod2k(new(big.Int).Mul(a, inv), kk)
+		return got.Cmp(mod2k(one, kk)) == 0
+	}, &quick.Config{MaxCount: 200})
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSqrt(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		a       *big.Int
+		k       uint
+		wantErr error
+	}{
+		{"a=1 k=64", big.NewInt(1), 64, nil},
+		{"a=9 k=64", big.NewInt(9), 64, nil},
+		{"a=17 k=64", big.NewInt(17), 64, nil},
+		{"a=-7 k=128", big.NewInt(-7), 128, nil},
+		{"a=5 k=2", big.NewInt(5), 2, nil},
+		{"a=3 k=1", big.NewInt(3), 1, nil},
+		{"a=3 k=2", big.NewInt(3), 2, ErrNotSquare},
+		{"a=5 k=3", big.NewInt(5), 3, ErrNotSquare},
+		{"a=7 k=64", big.NewInt(7), 64, ErrNotSquare},
+		{"a=8 k=64", big.NewInt(8), 64, ErrEven},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := Sqrt(tc.a, tc.k)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Sqrt(%v, %d) error = %v; want %v", tc.a, tc.k, err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := mod2k(new(big.Int).Mul(r, r), tc.k)
+			if want := mod2k(tc.a, tc.k); got.Cmp(want) != 0 {
+				t.Errorf("Sqrt(%v, %d)² = %v; want %v", tc.a, tc.k, got, want)
+			}
+		})
+	}
+}
+
+func TestSqrtProperty(t *testing.T) {
+	t.Parallel()
+
+	err := quick.Check(func(xBytes []byte, k uint16) bool {
+		// Squares of odd values are exactly the odd values that are 1 mod 8
+		x := new(big.Int).SetBytes(xBytes)
+		x.SetBit(x, 0, 1)
+		a := new(big.Int).Mul(x, x)
+		kk := uint(k % 2048)
+
+		r, err := Sqrt(a, kk)
+		if err != nil {
+			return false
+		}
+		got := mod2k(new(big.Int).Mul(r, r), kk)
+		return got.Cmp(mod2k(a, kk)) == 0
+	}, &quick.Config{MaxCount: 200})
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLiftRoot(t *testing.T) {
+	t.Parallel()
+
+	big3 := big.NewInt(3)
+	tests := []struct {
+		name    string
+		f       []*big.Int
+		r       *big.Int
+		k       uint
+		wantErr error
+	}{
+		{
+			// x - 3 has the root 3
+			name: "linear",
+			f:    []*big.Int{big.NewInt(-3), big.NewInt(1)},
+			r:    big.NewInt(1),
+			k:    64,
+		},
+		{
+			// x³ - 3 is a cube root of 3 mod 2^k
+			name: "cube root of 3",
+			f:    []*big.Int{new(big.Int).Neg(big3), big.NewInt(0), big.NewInt(0), big.NewInt(1)},
+			r:    big.NewInt(1),
+			k:    512,
+		},
+		{
+			// x² + x + 2 ≡ x(x + 1) mod 2: roots 0 and 1 are both simple
+			name: "quadratic root 0",
+			f:    []*big.Int{big.NewInt(2), big.NewInt(1), big.NewInt(1)},
+			r:    big.NewInt(0),
+			k:    256,
+		},
+		{
+			name:    "not a root",
+			f:       []*big.Int{big.NewInt(1), big.NewInt(1)},
+			r:       big.NewInt(0),
+			k:       64,
+			wantErr: ErrNoRoot,
+		},
+		{
+			// x² - 1 has f'(1) = 2, so Hensel's lemma does not apply
+			name:    "singular root",
+			f:       []*big.Int{big.NewInt(-1), big.NewInt(0), big.NewInt(1)},
+			r:       big.NewInt(1),
+			k:       64,
+			wantErr: ErrSingularRoot,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			root, err := LiftRoot(tc.f, tc.r, tc.k)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("LiftRoot() error = %v; want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := mod2k(eval(tc.f, root), tc.k); got.Sign() != 0 {
+				t.Errorf("f(LiftRoot()) mod 2^%d = %v; want 0", tc.k, got)
+			}
+		})
+	}
+}
+
+func BenchmarkInverse(b *testing.B) {
+	a := new(big.Int).Lsh(big.NewInt(1), 4095)
+	a.Sub(a, big.NewInt(1))
+
+	b.Run("Newton", func(b *testing.B) {
+		for b.Loop() {
+			_, _ = Inverse(a, 4096)
+		}
+	})
+
+	b.Run("BigInt/ModInverse", func(b *testing.B) {
+		m := new(big.Int).Lsh(big.NewInt(1), 4096)
+		for b.Loop() {
+			new(big.Int).ModInverse(a, m)
+		}
+	})
+}
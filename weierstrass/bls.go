@@ -0,0 +1,71 @@
+package weierstrass
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	// ErrNotInSubgroup is returned by ValidateSignature for a point outside
+	// the order-N subgroup.
+	ErrNotInSubgroup = errors.New("weierstrass: point is not in the prime-order subgroup")
+	// ErrSecretKey is returned by Sign for a private key outside [1, N).
+	ErrSecretKey = errors.New("weierstrass: secret key out of range [1, N)")
+)
+
+// The functions below are the G1 side of BLS signatures in the
+// minimal-signature-size variant: signatures are HashToCurve(msg) scaled by
+// the secret key and live in G1, public keys live in G2. PublicKey and
+// Verify, which need G2 and the pairing, are in pairing.go.
+
+// ClearCofactor maps p into the order-N subgroup by multiplying with HEff.
+func (p *Point) ClearCofactor() *Point {
+	return p.ScalarMult(p.c.HEff)
+}
+
+// IsInSubgroup reports whether N·p is the identity.
+func (p *Point) IsInSubgroup() bool {
+	return p.ScalarMult(p.c.N).IsIdentity()
+}
+
+// Sign returns the BLS signature sk·HashToCurve(msg, dst) for a secret key
+// sk ∈ [1, N).
+func (c *Curve) Sign(sk *big.Int, msg, dst []byte) (*Point, error) {
+	if sk.Sign() <= 0 || sk.Cmp(c.N) >= 0 {
+		return nil, ErrSecretKey
+	}
+	h, err := c.HashToCurve(msg, dst)
+	if err != nil {
+		return nil, err
+	}
+	return h.ScalarMult(sk), nil
+}
+
+// Aggregate returns the sum of the signatures, which verifies against the
+// aggregated public keys (same message) or the product of pairings
+// (distinct messages). The sum of no signatures is the identity.
+func (c *Curve) Aggregate(sigs ...*Point) *Point {
+	r := c.Identity()
+	for _, s := range sigs {
+		r = r.Add(s)
+	}
+	return r
+}
+
+// ValidateSignature decodes the affine coordinates of a signature and
+// accepts it only as an element of the order-N subgroup, the check
+// signatures need before aggregation or pairing. Affine coordinates cannot
+// express the identity, so it is rejected too.
+func (c *Curve) ValidateSignature(x, y *big.Int) (*Point, error) {
+	if x.Sign() < 0 || x.Cmp(c.P) >= 0 || y.Sign() < 0 || y.Cmp(c.P) >= 0 {
+		return nil, ErrNotOnCurve
+	}
+	p, err := c.NewPoint(x, y)
+	if err != nil {
+		return nil, err
+	}
+	if !p.IsInSubgroup() {
+		return nil, ErrNotInSubgroup
+	}
+	return p, nil
+}
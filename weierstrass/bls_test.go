@@ -0,0 +1,112 @@
+package weierstrass
+
+import (
+	"math/big"
+	"testing"
+)
+
+var blsDST = []byte("BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_NUL_")
+
+func TestSign(t *testing.T) {
+	t.Parallel()
+
+	c := BLS12381G1()
+	sk := big.NewInt(0x1234567)
+	sig, err := c.Sign(sk, []byte("hello"), blsDST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, _ := c.HashToCurve([]byte("hello"), blsDST)
+	if !sig.Equal(h.ScalarMult(sk)) {
+		t.Error("Sign() != sk·H(m)")
+	}
+	x, y := sig.Affine()
+	if _, err := c.ValidateSignature(x, y); err != nil {
+		t.Errorf("ValidateSignature() error = %v", err)
+	}
+
+	for _, bad := range []*big.Int{big.NewInt(0), big.NewInt(-1), c.N} {
+		if _, err := c.Sign(bad, []byte("hello"), blsDST); err != ErrSecretKey {
+			t.Errorf("Sign(%v) error = %v, want %v", bad, err, ErrSecretKey)
+		}
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	t.Parallel()
+
+	c := BLS12381G1()
+	msg := []byte("same message")
+	sks := []*big.Int{big.NewInt(3), big.NewInt(1000), hexInt("deadbeefcafe")}
+	sigs := make([]*Point, len(sks))
+	sum := new(big.Int)
+	for i, sk := range sks {
+		sigs[i], _ = c.Sign(sk, msg, blsDST)
+		sum.Add(sum, sk)
+	}
+	// Same message: the aggregate is the signature under the summed key
+	want, _ := c.Sign(sum, msg, blsDST)
+	if got := c.Aggregate(sigs...); !got.Equal(want) {
+		t.Error("Aggregate() != (Σ sk)·H(m)")
+	}
+	if !c.Aggregate().IsIdentity() {
+		t.Error("Aggregate() of nothing is not the identity")
+	}
+}
+
+func TestValidateSignature(t *testing.T) {
+	t.Parallel()
+
+	c := BLS12381G1()
+	// A point of E(Fp) outside G1: the mapped image of some u before
+	// cofactor clearing is almost never in the subgroup.
+	var outside *Point
+	for u := int64(1); ; u++ {
+		if p := c.MapToCurve(big.NewInt(u)); !p.IsInSubgroup() {
+			outside = p
+			break
+		}
+	}
+	ox, oy := outside.Affine()
+
+	tests := []struct {
+		name    string
+		x, y    *big.Int
+		wantErr error
+	}{
+		{"generator", c.Gx, c.Gy, nil},
+		{"off the curve", big.NewInt(1), big.NewInt(1), ErrNotOnCurve},
+		{"unreduced", new(big.Int).Add(c.Gx, c.P), c.Gy, ErrNotOnCurve},
+		{"outside G1", ox, oy, ErrNotInSubgroup},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := c.ValidateSignature(tc.x, tc.y); err != tc.wantErr {
+				t.Errorf("ValidateSignature() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestClearCofactor(t *testing.T) {
+	t.Parallel()
+
+	c := BLS12381G1()
+	for u := int64(1); u <= 3; u++ {
+		if !c.MapToCurve(big.NewInt(u)).ClearCofactor().IsInSubgroup() {
+			t.Errorf("ClearCofactor(map(%d)) is not in G1", u)
+		}
+	}
+}
+
+func BenchmarkAggregate(b *testing.B) {
+	c := BLS12381G1()
+	sigs := make([]*Point, 16)
+	for i := range sigs {
+		sigs[i], _ = c.Sign(big.NewInt(int64(i+1)), []byte("msg"), blsDST)
+	}
+	for b.Loop() {
+		c.Aggregate(sigs...)
+	}
+}
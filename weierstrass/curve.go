@@ -0,0 +1,206 @@
+// Package weierstrass provides arithmetic on short Weierstrass curves
+// y² = x³ + A·x + B over a prime field, hashing to those curves as specified
+// by RFC 9380, and BLS signatures over BLS12-381 with the optimal ate
+// pairing built on an Fp2/Fp6/Fp12 extension tower.
+//
+// Points are kept in affine coordinates with math/big and every addition
+// pays a field inversion. The code favors being easy to check against the
+// specifications over speed, and it is not constant time.
+package weierstrass
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrNotOnCurve is returned when coordinates do not satisfy the curve
+// equation.
+var ErrNotOnCurve = errors.New("weierstrass: point is not on the curve")
+
+// Curve is a short Weierstrass curve whose group of points has order H·N
+// for a prime N.
+type Curve struct {
+	Name   string
+	P      *big.Int // field prime
+	A, B   *big.Int // curve coefficients mod P
+	N      *big.Int // prime order of the main subgroup
+	H      *big.Int // cofactor
+	Gx, Gy *big.Int // generator of the order-N subgroup
+
+	// HEff is the scalar ClearCofactor multiplies by. It is H unless a
+	// cheaper scalar mapping every point into the order-N subgroup is known,
+	// like the 64-bit h_eff of BLS12-381 G1 in RFC 9380.
+	HEff *big.Int
+	// Z is the map-to-curve constant of RFC 9380: for the simplified SWU map
+	// when A·B ≠ 0 or the curve has an isogeny to map through, and for the
+	// Shallue–van de Woestijne map otherwise.
+	Z *big.Int
+
+	// iso, if set, is an isogeny onto the curve from one the simplified SWU
+	// map works on, which MapToCurve uses in place of a direct map.
+	iso *isogeny
+}
+
+// Point is an affine curve point or the point at infinity. Points are
+// immutable; all operations return new ones.
+type Point struct {
+	c    *Curve
+	x, y *big.Int
+	inf  bool
+}
+
+func hexInt(s string) *big.Int {
+	x, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("weierstrass: bad constant " + s)
+	}
+	return x
+}
+
+// P256 returns NIST P-256 (secp256r1) with the RFC 9380 SSWU constant
+// Z = -10.
+func P256() *Curve {
+	p := hexInt("ffffffff00000001000000000000000000000000ffffffffffffffffffffffff")
+	return &Curve{
+		Name: "P-256",
+		P:    p,
+		A:    new(big.Int).Sub(p, big.NewInt(3)),
+		B:    hexInt("5ac635d8aa3a93e7b3ebbd55769886bc651d06b0cc53b0f63bce3c3e27d2604b"),
+		N:    hexInt("ffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632551"),
+		H:    big.NewInt(1),
+		Gx:   hexInt("6b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c296"),
+		Gy:   hexInt("4fe342e2fe1a7f9b8ee7eb4a7c0f9e162bce33576b315ececbb6406837bf51f5"),
+		HEff: big.NewInt(1),
+		Z:    new(big.Int).Sub(p, big.NewInt(10)),
+	}
+}
+
+// BLS12381G1 returns the group G1 of the pairing-friendly curve BLS12-381,
+// y² = x³ + 4 over its 381-bit prime field. A = 0 rules out the simplified
+// SWU map on the curve itself, so hashing maps to the 11-isogenous curve of
+// RFC 9380 section 8.8.1 with Z = 11 and through the isogeny onto G1, as
+// the suite BLS12381G1_XMD:SHA-256_SSWU_RO_ that BLS implementations share.
+func BLS12381G1() *Curve {
+	iso := bls12381G1Isogeny()
+	return &Curve{
+		Name: "BLS12-381 G1",
+		P:    new(big.Int).Set(bls12381P),
+		A:    big.NewInt(0),
+		B:    big.NewInt(4),
+		N:    new(big.Int).Set(bls12381N),
+		H:    hexInt("396c8c005555e1568c00aaab0000aaab"),
+		Gx:   hexInt("17f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb"),
+		Gy:   hexInt("08b3f481e3aaa0f1a09e30ed741d8ae4fcf5e095d5d00af600db18cb2c04b3edd03cc744a2888ae40caa232946c5e7e1"),
+		// 1 - z for the curve parameter z = -0xd201000000010000
+		HEff: hexInt("d201000000010001"),
+		Z:    iso.src.Z,
+		iso:  iso,
+	}
+}
+
+// Identity returns the point at infinity.
+func (c *Curve) Identity() *Point { return &Point{c: c, inf: true} }
+
+// Generator returns the base point (Gx, Gy).
+func (c *Curve) Generator() *Point {
+	p, _ := c.NewPoint(c.Gx, c.Gy)
+	return p
+}
+
+// NewPoint returns the point (x, y), or ErrNotOnCurve. x and y are reduced
+// mod P.
+func (c *Curve) NewPoint(x, y *big.Int) (*Point, error) {
+	x, y = new(big.Int).Mod(x, c.P), new(big.Int).Mod(y, c.P)
+	y2 := new(big.Int).Mul(y, y)
+	if y2.Sub(y2, c.g(x)).Mod(y2, c.P).Sign() != 0 {
+		return nil, ErrNotOnCurve
+	}
+	return &Point{c: c, x: x, y: y}, nil
+}
+
+// g returns x³ + A·x + B mod P, the right-hand side of the curve equation.
+func (c *Curve) g(x *big.Int) *big.Int {
+	r := new(big.Int).Mul(x, x)
+	r.Add(r, c.A)
+	r.Mul(r, x)
+	r.Add(r, c.B)
+	return r.Mod(r, c.P)
+}
+
+// Affine returns the coordinates of p, or nil, nil for the point at
+// infinity.
+func (p *Point) Affine() (x, y *big.Int) {
+	if p.inf {
+		return nil, nil
+	}
+	return new(big.Int).Set(p.x), new(big.Int).Set(p.y)
+}
+
+// IsIdentity reports whether p is the point at infinity.
+func (p *Point) IsIdentity() bool { return p.inf }
+
+// Equal reports whether p and q are the same point.
+func (p *Point) Equal(q *Point) bool {
+	if p.inf || q.inf {
+		return p.inf == q.inf
+	}
+	return p.x.Cmp(q.x) == 0 && p.y.Cmp(q.y) == 0
+}
+
+// Neg returns -p = (x, -y).
+func (p *Point) Neg() *Point {
+	if p.inf {
+		return p
+	}
+	y := new(big.Int).Neg(p.y)
+	return &Point{c: p.c, x: p.x, y: y.Mod(y, p.c.P)}
+}
+
+// Add returns p + q by the chord-and-tangent rule.
+func (p *Point) Add(q *Point) *Point {
+	switch {
+	case p.inf:
+		return q
+	case q.inf:
+		return p
+	}
+	c := p.c
+	P := c.P
+	lambda := new(big.Int)
+	if p.x.Cmp(q.x) == 0 {
+		if lambda.Add(p.y, q.y).Mod(lambda, P).Sign() == 0 {
+			return c.Identity()
+		}
+		// Tangent: λ = (3x² + A) / 2y
+		lambda.Mul(p.x, p.x).Mul(lambda, big.NewInt(3)).Add(lambda, c.A)
+		den := new(big.Int).Lsh(p.y, 1)
+		lambda.Mul(lambda, den.ModInverse(den, P))
+	} else {
+		// Chord: λ = (y2 - y1) / (x2 - x1)
+		den := new(big.Int).Sub(q.x, p.x)
+		den.Mod(den, P)
+		lambda.Sub(q.y, p.y).Mul(lambda, den.ModInverse(den, P))
+	}
+	lambda.Mod(lambda, P)
+
+	x := new(big.Int).Mul(lambda, lambda)
+	x.Sub(x, p.x).Sub(x, q.x).Mod(x, P)
+	y := new(big.Int).Sub(p.x, x)
+	y.Mul(y, lambda).Sub(y, p.y).Mod(y, P)
+	return &Point{c: c, x: x, y: y}
+}
+
+// ScalarMult returns k·p by double-and-add; a negative k multiplies -p.
+func (p *Point) ScalarMult(k *big.Int) *Point {
+	if k.Sign() < 0 {
+		return p.Neg().ScalarMult(new(big.Int).Neg(k))
+	}
+	r := p.c.Identity()
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		r = r.Add(r)
+		if k.Bit(i) == 1 {
+			r = r.Add(p)
+		}
+	}
+	return r
+}
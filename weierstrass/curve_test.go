@@ -0,0 +1,140 @@
+package weierstrass
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestP256(t *testing.T) {
+	t.Parallel()
+
+	c := P256()
+	want := elliptic.P256().Params()
+	for _, tc := range []struct {
+		name      string
+		got, want *big.Int
+	}{
+		{"P", c.P, want.P},
+		{"B", c.B, want.B},
+		{"N", c.N, want.N},
+		{"Gx", c.Gx, want.Gx},
+		{"Gy", c.Gy, want.Gy},
+	} {
+		if tc.got.Cmp(tc.want) != 0 {
+			t.Errorf("%s = %x, want %x", tc.name, tc.got, tc.want)
+		}
+	}
+
+	// Cross-check scalar multiplication against crypto/elliptic
+	k := big.NewInt(0x1234567890abcdef)
+	wx, wy := elliptic.P256().ScalarBaseMult(k.Bytes())
+	x, y := c.Generator().ScalarMult(k).Affine()
+	if x.Cmp(wx) != 0 || y.Cmp(wy) != 0 {
+		t.Errorf("k·G = (%x, %x), want (%x, %x)", x, y, wx, wy)
+	}
+}
+
+func TestBLS12381G1(t *testing.T) {
+	t.Parallel()
+
+	c := BLS12381G1()
+	g := c.Generator()
+	if g == nil {
+		t.Fatal("generator is not on the curve")
+	}
+	if !g.ScalarMult(c.N).IsIdentity() {
+		t.Error("N·G is not the identity")
+	}
+
+	// #E(Fp) = p + 1 - t with trace t = z + 1, z = -0xd201000000010000
+	z := new(big.Int).Neg(hexInt("d201000000010000"))
+	order := new(big.Int).Add(c.P, big.NewInt(1))
+	order.Sub(order, z.Add(z, big.NewInt(1)))
+	if hn := new(big.Int).Mul(c.H, c.N); hn.Cmp(order) != 0 {
+		t.Errorf("H·N = %x, want p + 1 - t = %x", hn, order)
+	}
+
+	// RFC 9380 section 8.8.1 gives Z = 11 for the SSWU map on the
+	// 11-isogenous curve, and Z = -3 for the SvdW map on G1 itself
+	if c.Z.Cmp(big.NewInt(11)) != 0 {
+		t.Errorf("Z = %x, want 11", c.Z)
+	}
+	if z, want := c.findZSVDW(), new(big.Int).Sub(c.P, big.NewInt(3)); z.Cmp(want) != 0 {
+		t.Errorf("SvdW Z = %x, want -3", z)
+	}
+}
+
+func TestNewPoint(t *testing.T) {
+	t.Parallel()
+
+	c := P256()
+	tests := []struct {
+		name    string
+		x, y    *big.Int
+		wantErr error
+	}{
+		{"generator", c.Gx, c.Gy, nil},
+		{"unreduced generator", new(big.Int).Add(c.Gx, c.P), c.Gy, nil},
+		{"negated generator", c.Gx, new(big.Int).Neg(c.Gy), nil},
+		{"off the curve", big.NewInt(1), big.NewInt(1), ErrNotOnCurve},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := c.NewPoint(tc.x, tc.y); err != tc.wantErr {
+				t.Errorf("NewPoint() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestGroupLaw(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range []*Curve{P256(), BLS12381G1()} {
+		t.Run(c.Name, func(t *testing.T) {
+			t.Parallel()
+
+			g := c.Generator()
+			id := c.Identity()
+			if !g.Add(id).Equal(g) || !id.Add(g).Equal(g) {
+				t.Error("G + O != G")
+			}
+			if !g.Add(g.Neg()).IsIdentity() {
+				t.Error("G + (-G) is not the identity")
+			}
+			if !g.ScalarMult(big.NewInt(-3)).Equal(g.ScalarMult(big.NewInt(3)).Neg()) {
+				t.Error("(-3)·G != -(3·G)")
+			}
+
+			err := quick.Check(func(a, b uint32) bool {
+				ka, kb := big.NewInt(int64(a)), big.NewInt(int64(b))
+				pa, pb := g.ScalarMult(ka), g.ScalarMult(kb)
+				sum := pa.Add(pb)
+				if !sum.Equal(g.ScalarMult(new(big.Int).Add(ka, kb))) || !sum.Equal(pb.Add(pa)) {
+					return false
+				}
+				if sum.IsIdentity() {
+					return true
+				}
+				x, y := sum.Affine()
+				_, err := c.NewPoint(x, y)
+				return err == nil
+			}, &quick.Config{MaxCount: 20})
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func BenchmarkScalarMult(b *testing.B) {
+	c := P256()
+	g := c.Generator()
+	k := new(big.Int).Sub(c.N, big.NewInt(12345))
+	for b.Loop() {
+		g.ScalarMult(k)
+	}
+}
@@ -0,0 +1,130 @@
+package weierstrass
+
+import "math/big"
+
+// g2B is the coefficient of the BLS12-381 sextic twist E': y² = x³ + 4·ξ
+// over Fp2, whose order-N subgroup is G2.
+var g2B = fp2{big.NewInt(4), big.NewInt(4)}
+
+// bls12381N is the prime order N of G1, G2 and GT.
+var bls12381N = hexInt("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001")
+
+// G2Point is an affine point of the BLS12-381 twist E'(Fp2), or the point
+// at infinity. Coordinates are x = x0 + x1·u and y = y0 + y1·u. Like Point,
+// a G2Point is immutable.
+type G2Point struct {
+	x, y fp2
+	inf  bool
+}
+
+// G2Identity returns the point at infinity of E'.
+func G2Identity() *G2Point { return &G2Point{inf: true} }
+
+// G2Generator returns the standard generator of G2.
+func G2Generator() *G2Point {
+	q, _ := NewG2Point(
+		hexInt("024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8"),
+		hexInt("13e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e"),
+		hexInt("0ce5d527727d6e118cc9cdc6da2e351aadfd9baa8cbdd3a76d429a695160d12c923ac9cc3baca289e193548608b82801"),
+		hexInt("0606c4a02ea734cc32acd2b02bc28b99cb3e287e85a763af267492ab572e99ab3f370d275cec1da1aaa9075ff05f79be"),
+	)
+	return q
+}
+
+// NewG2Point returns the point (x0 + x1·u, y0 + y1·u) of E', or
+// ErrNotOnCurve. The coordinates are reduced mod P. The point may lie
+// outside G2; see IsInSubgroup.
+func NewG2Point(x0, x1, y0, y1 *big.Int) (*G2Point, error) {
+	x, y := newFp2(x0, x1), newFp2(y0, y1)
+	if !y.square().equal(x.square().mul(x).add(g2B)) {
+		return nil, ErrNotOnCurve
+	}
+	return &G2Point{x: x, y: y}, nil
+}
+
+// Affine returns the coordinates of q, or all nil for the point at
+// infinity.
+func (q *G2Point) Affine() (x0, x1, y0, y1 *big.Int) {
+	if q.inf {
+		return nil, nil, nil, nil
+	}
+	return new(big.Int).Set(q.x.c0), new(big.Int).Set(q.x.c1),
+		new(big.Int).Set(q.y.c0), new(big.Int).Set(q.y.c1)
+}
+
+// IsIdentity reports whether q is the point at infinity.
+func (q *G2Point) IsIdentity() bool { return q.inf }
+
+// Equal reports whether q and r are the same point.
+func (q *G2Point) Equal(r *G2Point) bool {
+	if q.inf || r.inf {
+		return q.inf == r.inf
+	}
+	return q.x.equal(r.x) && q.y.equal(r.y)
+}
+
+// Neg returns -q = (x, -y).
+func (q *G2Point) Neg() *G2Point {
+	if q.inf {
+		return q
+	}
+	return &G2Point{x: q.x, y: q.y.neg()}
+}
+
+// Add returns q + r by the chord-and-tangent rule.
+func (q *G2Point) Add(r *G2Point) *G2Point {
+	switch {
+	case q.inf:
+		return r
+	case r.inf:
+		return q
+	}
+	var lambda fp2
+	if q.x.equal(r.x) {
+		if q.y.add(r.y).isZero() {
+			return G2Identity()
+		}
+		lambda = q.tangentSlope()
+	} else {
+		lambda = q.chordSlope(r)
+	}
+	return q.addWithSlope(r, lambda)
+}
+
+// tangentSlope returns 3x² / 2y; A = 0 on the twist.
+func (q *G2Point) tangentSlope() fp2 {
+	x2 := q.x.square()
+	return x2.add(x2).add(x2).mul(q.y.add(q.y).inverse())
+}
+
+// chordSlope returns (y_r - y_q) / (x_r - x_q).
+func (q *G2Point) chordSlope(r *G2Point) fp2 {
+	return r.y.sub(q.y).mul(r.x.sub(q.x).inverse())
+}
+
+// addWithSlope returns q + r for the slope λ of the line through them.
+func (q *G2Point) addWithSlope(r *G2Point, lambda fp2) *G2Point {
+	x := lambda.square().sub(q.x).sub(r.x)
+	y := lambda.mul(q.x.sub(x)).sub(q.y)
+	return &G2Point{x: x, y: y}
+}
+
+// ScalarMult returns k·q by double-and-add; a negative k multiplies -q.
+func (q *G2Point) ScalarMult(k *big.Int) *G2Point {
+	if k.Sign() < 0 {
+		return q.Neg().ScalarMult(new(big.Int).Neg(k))
+	}
+	r := G2Identity()
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		r = r.Add(r)
+		if k.Bit(i) == 1 {
+			r = r.Add(q)
+		}
+	}
+	return r
+}
+
+// IsInSubgroup reports whether N·q is the identity, that is, q ∈ G2.
+func (q *G2Point) IsInSubgroup() bool {
+	return q.ScalarMult(bls12381N).IsIdentity()
+}
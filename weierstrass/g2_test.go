@@ -0,0 +1,71 @@
+package weierstrass
+
+import (
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestG2Generator(t *testing.T) {
+	t.Parallel()
+
+	g := G2Generator()
+	if g == nil {
+		t.Fatal("G2 generator is not on the twist")
+	}
+	if !g.IsInSubgroup() {
+		t.Error("N·g2 is not the identity")
+	}
+	if g.ScalarMult(new(big.Int).Sub(bls12381N, big.NewInt(1))).Equal(g) {
+		t.Error("(N-1)·g2 = g2")
+	}
+
+	x0, x1, y0, y1 := g.Affine()
+	if _, err := NewG2Point(x0, x1, y0, new(big.Int).Add(y1, big.NewInt(1))); err != ErrNotOnCurve {
+		t.Errorf("NewG2Point(off the twist) error = %v, want %v", err, ErrNotOnCurve)
+	}
+	if x0, _, _, _ := G2Identity().Affine(); x0 != nil {
+		t.Error("Affine() of the identity is not nil")
+	}
+}
+
+func TestG2GroupLaw(t *testing.T) {
+	t.Parallel()
+
+	g := G2Generator()
+	id := G2Identity()
+	if !g.Add(id).Equal(g) || !id.Add(g).Equal(g) {
+		t.Error("Q + O != Q")
+	}
+	if !g.Add(g.Neg()).IsIdentity() {
+		t.Error("Q + (-Q) is not the identity")
+	}
+	if !g.ScalarMult(big.NewInt(-3)).Equal(g.ScalarMult(big.NewInt(3)).Neg()) {
+		t.Error("(-3)·Q != -(3·Q)")
+	}
+
+	err := quick.Check(func(a, b uint16) bool {
+		ka, kb := big.NewInt(int64(a)), big.NewInt(int64(b))
+		pa, pb := g.ScalarMult(ka), g.ScalarMult(kb)
+		sum := pa.Add(pb)
+		if !sum.Equal(g.ScalarMult(new(big.Int).Add(ka, kb))) || !sum.Equal(pb.Add(pa)) {
+			return false
+		}
+		if sum.IsIdentity() {
+			return true
+		}
+		_, err := NewG2Point(sum.Affine())
+		return err == nil
+	}, &quick.Config{MaxCount: 10})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func BenchmarkG2ScalarMult(b *testing.B) {
+	g := G2Generator()
+	k := new(big.Int).Sub(bls12381N, big.NewInt(12345))
+	for b.Loop() {
+		g.ScalarMult(k)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/blck-snwmn/arithmetic-vault/weierstrass
+
+go 1.25.5
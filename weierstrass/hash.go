@@ -0,0 +1,77 @@
+package weierstrass
+
+import (
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"math/big"
+)
+
+// ErrExpandLength is returned when expand_message_xmd is asked for more
+// than 255 hash blocks or 65535 bytes.
+var ErrExpandLength = errors.New("weierstrass: requested expand_message output is too long")
+
+// securityBits is the target security level k of HashToField; each field
+// element is drawn from ceil((log2 p + k) / 8) uniform bytes, so its bias
+// is at most 2^-k.
+const securityBits = 128
+
+// ExpandMessageXMD implements expand_message_xmd of RFC 9380 section 5.3.1:
+// n uniform bytes from msg and the domain separation tag dst, using the
+// Merkle–Damgård hash h. A dst over 255 bytes is first hashed as the RFC
+// prescribes.
+func ExpandMessageXMD(h func() hash.Hash, msg, dst []byte, n int) ([]byte, error) {
+	H := h()
+	bLen, sLen := H.Size(), H.BlockSize()
+	ell := (n + bLen - 1) / bLen
+	if ell > 255 || n > 65535 || n < 0 {
+		return nil, ErrExpandLength
+	}
+	if len(dst) > 255 {
+		H.Write([]byte("H2C-OVERSIZE-DST-"))
+		H.Write(dst)
+		dst = H.Sum(nil)
+		H.Reset()
+	}
+	dstPrime := append(append([]byte(nil), dst...), byte(len(dst)))
+
+	// b_0 = H(Z_pad || msg || I2OSP(n, 2) || I2OSP(0, 1) || DST_prime)
+	H.Write(make([]byte, sLen))
+	H.Write(msg)
+	H.Write([]byte{byte(n >> 8), byte(n), 0})
+	H.Write(dstPrime)
+	b0 := H.Sum(nil)
+
+	out := make([]byte, 0, ell*bLen)
+	prev := make([]byte, bLen) // b_0 XOR 0 gives b_0 for the first block
+	for i := 1; i <= ell; i++ {
+		// b_i = H(strxor(b_0, b_(i-1)) || I2OSP(i, 1) || DST_prime)
+		H.Reset()
+		for j := range prev {
+			prev[j] ^= b0[j]
+		}
+		H.Write(prev)
+		H.Write([]byte{byte(i)})
+		H.Write(dstPrime)
+		prev = H.Sum(nil)
+		out = append(out, prev...)
+	}
+	return out[:n], nil
+}
+
+// HashToField implements hash_to_field of RFC 9380 section 5.2 for the
+// prime field of c with expand_message_xmd over SHA-256 at 128-bit
+// security: count elements of [0, P) derived from msg and dst.
+func (c *Curve) HashToField(msg, dst []byte, count int) ([]*big.Int, error) {
+	var m WideReduction
+	L := m.Len(c.P)
+	uniform, err := ExpandMessageXMD(sha256.New, msg, dst, count*L)
+	if err != nil {
+		return nil, err
+	}
+	u := make([]*big.Int, count)
+	for i := range u {
+		u[i], _ = m.Map(c.P, uniform[i*L:(i+1)*L])
+	}
+	return u, nil
+}
@@ -0,0 +1,94 @@
+package weierstrass
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExpandMessageXMD(t *testing.T) {
+	t.Parallel()
+
+	// RFC 9380 appendix K.1, expand_message_xmd(SHA-256), len_in_bytes = 0x20
+	dst := []byte("QUUX-V01-CS02-with-expander-SHA256-128")
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{"", "68a985b87eb6b46952128911f2a4412bbc302a9d759667f87f7a21d803f07235"},
+		{"abc", "d8ccab23b5985ccea865c6c97b6e5b8350e794e603b4b97902f53a8a0d605615"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.msg, func(t *testing.T) {
+			t.Parallel()
+			got, err := ExpandMessageXMD(sha256.New, []byte(tc.msg), dst, 0x20)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hex.EncodeToString(got) != tc.want {
+				t.Errorf("ExpandMessageXMD(%q) = %x, want %s", tc.msg, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExpandMessageXMD_lengths(t *testing.T) {
+	t.Parallel()
+
+	dst := []byte("DST")
+	long, err := ExpandMessageXMD(sha256.New, []byte("msg"), dst, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	short, err := ExpandMessageXMD(sha256.New, []byte("msg"), dst, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The length is hashed into b_0, so outputs of different lengths are
+	// unrelated rather than prefixes of each other.
+	if len(long) != 100 || len(short) != 40 || string(long[:40]) == string(short) {
+		t.Error("outputs of different lengths share a prefix")
+	}
+
+	if _, err := ExpandMessageXMD(sha256.New, nil, dst, 256*32); !errors.Is(err, ErrExpandLength) {
+		t.Errorf("256 blocks: error = %v, want %v", err, ErrExpandLength)
+	}
+
+	// An oversize DST is replaced by H("H2C-OVERSIZE-DST-" || DST)
+	big := []byte(strings.Repeat("x", 300))
+	h := sha256.Sum256(append([]byte("H2C-OVERSIZE-DST-"), big...))
+	got, _ := ExpandMessageXMD(sha256.New, []byte("msg"), big, 32)
+	want, _ := ExpandMessageXMD(sha256.New, []byte("msg"), h[:], 32)
+	if string(got) != string(want) {
+		t.Error("oversize DST is not hashed")
+	}
+}
+
+func TestHashToField(t *testing.T) {
+	t.Parallel()
+
+	// RFC 9380 appendix J.1.1, P256_XMD:SHA-256_SSWU_RO_, msg = ""
+	c := P256()
+	u, err := c.HashToField(nil, []byte("QUUX-V01-CS02-with-P256_XMD:SHA-256_SSWU_RO_"), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"ad5342c66a6dd0ff080df1da0ea1c04b96e0330dd89406465eeba11582515009",
+		"8c0f1d43204bd6f6ea70ae8013070a1518b43873bcd850aafa0a9e220e2eea5a",
+	}
+	for i := range want {
+		if u[i].Text(16) != want[i] {
+			t.Errorf("u[%d] = %x, want %s", i, u[i], want[i])
+		}
+	}
+}
+
+func BenchmarkExpandMessageXMD(b *testing.B) {
+	msg, dst := make([]byte, 64), []byte("QUUX-V01-CS02-with-expander-SHA256-128")
+	for b.Loop() {
+		ExpandMessageXMD(sha256.New, msg, dst, 128)
+	}
+}
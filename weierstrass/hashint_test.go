@@ -0,0 +1,128 @@
+package weierstrass
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+// distribution feeds m every possible input for n, which must be at most
+// two bytes long, and returns the statistical distance of the accepted
+// outputs from uniform on [0, n) and the fraction of inputs accepted.
+func distribution(t *testing.T, m IntegerMap, n *big.Int) (dist, accepted float64) {
+	t.Helper()
+	l := m.Len(n)
+	if l > 2 {
+		t.Fatalf("Len(%v) = %d, too long to enumerate", n, l)
+	}
+	counts := make([]int, n.Int64())
+	total := 0
+	b := make([]byte, l)
+	for v := range 1 << (8 * l) {
+		for i := range b {
+			b[i] = byte(v >> (8 * (l - 1 - i)))
+		}
+		x, ok := m.Map(n, b)
+		if !ok {
+			continue
+		}
+		if x.Sign() < 0 || x.Cmp(n) >= 0 {
+			t.Fatalf("Map(%v, %x) = %v, out of range", n, b, x)
+		}
+		counts[x.Int64()]++
+		total++
+	}
+	for _, c := range counts {
+		dist += math.Abs(float64(c)/float64(total) - 1/float64(len(counts)))
+	}
+	return dist / 2, float64(total) / float64(int(1)<<(8*l))
+}
+
+func TestIntegerMap_bias(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		m           IntegerMap
+		n           int64
+		minDist     float64 // the bias the map is documented to have
+		maxDist     float64
+		minAccepted float64
+	}{
+		// 181 ≈ 256/√2: the worst case of a one-byte reduction
+		{"simple/worst", SimpleMod{}, 181, 0.17, 3 - 2*math.Sqrt2 + 1e-9, 1},
+		{"simple/small", SimpleMod{}, 3, 1.0/384 - 1e-9, 1.0/384 + 1e-9, 1},
+		{"simple/power", SimpleMod{}, 256, 0, 0, 1},
+		{"wide/worst", WideReduction{SecurityBits: 8}, 181, 0, 1.0 / 256, 1},
+		{"wide/9bits", WideReduction{SecurityBits: 7}, 301, 0, 1.0 / 128, 1},
+		{"rejection/worst", RejectionSampling{}, 129, 0, 0, 129.0 / 256},
+		{"rejection/9bits", RejectionSampling{}, 301, 0, 0, 301.0 / 512},
+		{"rejection/byte", RejectionSampling{}, 255, 0, 0, 255.0 / 256},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dist, accepted := distribution(t, tt.m, big.NewInt(tt.n))
+			if dist < tt.minDist || dist > tt.maxDist {
+				t.Errorf("distance from uniform = %g, want in [%g, %g]", dist, tt.minDist, tt.maxDist)
+			}
+			if accepted < tt.minAccepted || accepted > 1 {
+				t.Errorf("accepted %g of inputs, want at least %g", accepted, tt.minAccepted)
+			}
+		})
+	}
+}
+
+func TestIntegerMap_Len(t *testing.T) {
+	t.Parallel()
+
+	p := P256().P
+	tests := []struct {
+		name string
+		m    IntegerMap
+		want int
+	}{
+		{"simple", SimpleMod{}, 32},
+		// L = 48 for P-256 in RFC 9380 section 8.2
+		{"wide/default", WideReduction{}, 48},
+		{"wide/64", WideReduction{SecurityBits: 64}, 40},
+		{"rejection", RejectionSampling{}, 32},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.m.Len(p); got != tt.want {
+				t.Errorf("Len(P-256) = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSampleInt(t *testing.T) {
+	t.Parallel()
+
+	// For the 9-bit n = 301, 0xffff masks to 511 and is rejected, and
+	// 0xfe05 masks to 5
+	inputs := [][]byte{{0xff, 0xff}, {0xfe, 0x05}}
+	calls := 0
+	next := func(l int) ([]byte, error) {
+		if l != 2 {
+			t.Fatalf("next(%d), want 2 bytes", l)
+		}
+		calls++
+		return inputs[calls-1], nil
+	}
+	x, err := SampleInt(RejectionSampling{}, big.NewInt(301), next)
+	if err != nil || x.Int64() != 5 || calls != 2 {
+		t.Errorf("SampleInt = %v, %v after %d calls, want 5 after 2", x, err, calls)
+	}
+
+	errDrained := errors.New("drained")
+	_, err = SampleInt(RejectionSampling{}, big.NewInt(129), func(int) ([]byte, error) {
+		return nil, errDrained
+	})
+	if !errors.Is(err, errDrained) {
+		t.Errorf("error = %v, want %v", err, errDrained)
+	}
+}
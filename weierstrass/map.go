@@ -0,0 +1,191 @@
+package weierstrass
+
+import "math/big"
+
+// HashToCurve implements the random-oracle hash_to_curve of RFC 9380
+// section 3: two field elements from HashToField, each mapped to the curve,
+// summed, and the sum moved into the order-N subgroup with ClearCofactor.
+// With the DST of an RFC suite, P256 reproduces P256_XMD:SHA-256_SSWU_RO_
+// and BLS12381G1 BLS12381G1_XMD:SHA-256_SSWU_RO_.
+func (c *Curve) HashToCurve(msg, dst []byte) (*Point, error) {
+	u, err := c.HashToField(msg, dst, 2)
+	if err != nil {
+		return nil, err
+	}
+	q := c.MapToCurve(u[0]).Add(c.MapToCurve(u[1]))
+	return q.ClearCofactor(), nil
+}
+
+// EncodeToCurve implements the nonuniform encode_to_curve of RFC 9380: one
+// field element, mapped and cofactor-cleared. It is cheaper than
+// HashToCurve, but its output covers only about half of the points and is
+// distinguishable from a random oracle.
+func (c *Curve) EncodeToCurve(msg, dst []byte) (*Point, error) {
+	u, err := c.HashToField(msg, dst, 1)
+	if err != nil {
+		return nil, err
+	}
+	return c.MapToCurve(u[0]).ClearCofactor(), nil
+}
+
+// MapToCurve maps the field element u to a curve point, not necessarily in
+// the order-N subgroup: by the simplified SWU map of RFC 9380 section
+// 6.6.2 when A·B ≠ 0, by that map to an isogenous curve followed by the
+// isogeny (section 6.6.3) when the curve has one, and by the
+// Shallue–van de Woestijne map of section 6.6.1 otherwise.
+func (c *Curve) MapToCurve(u *big.Int) *Point {
+	if c.iso != nil {
+		return c.iso.apply(c, c.iso.src.MapToCurve(u))
+	}
+	u = new(big.Int).Mod(u, c.P)
+	var x, y *big.Int
+	if c.A.Sign() != 0 && c.B.Sign() != 0 {
+		x, y = c.sswu(u)
+	} else {
+		x, y = c.svdw(u)
+	}
+	if sgn0(u) != sgn0(y) {
+		y.Sub(c.P, y).Mod(y, c.P)
+	}
+	return &Point{c: c, x: x, y: y}
+}
+
+// sswu returns x and a square root y of g(x), before the sign fix-up.
+func (c *Curve) sswu(u *big.Int) (x, y *big.Int) {
+	P := c.P
+	// tv1 = inv0(Z²·u⁴ + Z·u²)
+	zu2 := new(big.Int).Mul(u, u)
+	zu2.Mul(zu2, c.Z).Mod(zu2, P)
+	tv1 := new(big.Int).Mul(zu2, zu2)
+	tv1.Add(tv1, zu2)
+	tv1 = c.inv0(tv1)
+
+	x1 := new(big.Int)
+	if tv1.Sign() == 0 {
+		// x1 = B / (Z·A)
+		x1.Mul(c.Z, c.A)
+		x1.Mul(c.B, c.inv0(x1))
+	} else {
+		// x1 = (-B / A)·(1 + tv1)
+		x1.Neg(c.B).Mul(x1, c.inv0(c.A))
+		x1.Mul(x1, tv1.Add(tv1, big.NewInt(1)))
+	}
+	x1.Mod(x1, P)
+	if gx1 := c.g(x1); isSquare(gx1, P) {
+		return x1, new(big.Int).ModSqrt(gx1, P)
+	}
+	x2 := zu2.Mul(zu2, x1).Mod(zu2, P)
+	return x2, new(big.Int).ModSqrt(c.g(x2), P)
+}
+
+// svdw returns x and a square root y of g(x), before the sign fix-up.
+func (c *Curve) svdw(u *big.Int) (x, y *big.Int) {
+	P := c.P
+	c1, c2, c3, c4 := c.svdwConstants()
+	one := big.NewInt(1)
+
+	tv1 := new(big.Int).Mul(u, u)
+	tv1.Mul(tv1, c1).Mod(tv1, P)
+	tv2 := new(big.Int).Add(one, tv1)
+	tv1.Sub(one, tv1)
+	tv3 := c.inv0(new(big.Int).Mul(tv1, tv2))
+	// tv4 = u·tv1·tv3·c3
+	tv4 := new(big.Int).Mul(u, tv1)
+	tv4.Mul(tv4, tv3).Mul(tv4, c3).Mod(tv4, P)
+
+	x1 := new(big.Int).Sub(c2, tv4)
+	x1.Mod(x1, P)
+	if gx := c.g(x1); isSquare(gx, P) {
+		return x1, new(big.Int).ModSqrt(gx, P)
+	}
+	x2 := new(big.Int).Add(c2, tv4)
+	x2.Mod(x2, P)
+	if gx := c.g(x2); isSquare(gx, P) {
+		return x2, new(big.Int).ModSqrt(gx, P)
+	}
+	// x3 = (tv2²·tv3)²·c4 + Z
+	x3 := new(big.Int).Mul(tv2, tv2)
+	x3.Mul(x3, tv3).Mod(x3, P)
+	x3.Mul(x3, x3).Mul(x3, c4).Add(x3, c.Z).Mod(x3, P)
+	return x3, new(big.Int).ModSqrt(c.g(x3), P)
+}
+
+// svdwConstants returns the constants of RFC 9380 section 6.6.1:
+//
+//	c1 = g(Z)
+//	c2 = -Z / 2
+//	c3 = sqrt(-g(Z)·(3Z² + 4A)), the root with sgn0 = 0
+//	c4 = -4·g(Z) / (3Z² + 4A)
+func (c *Curve) svdwConstants() (c1, c2, c3, c4 *big.Int) {
+	P := c.P
+	c1 = c.g(c.Z)
+	c2 = new(big.Int).Neg(c.Z)
+	c2.Mul(c2, c.inv0(big.NewInt(2))).Mod(c2, P)
+
+	t := c.svdwT(c.Z)
+	c3 = new(big.Int).Mul(c1, t)
+	c3.Neg(c3).Mod(c3, P)
+	c3.ModSqrt(c3, P)
+	if sgn0(c3) == 1 {
+		c3.Sub(P, c3)
+	}
+	c4 = new(big.Int).Mul(c1, big.NewInt(-4))
+	c4.Mul(c4, c.inv0(t)).Mod(c4, P)
+	return c1, c2, c3, c4
+}
+
+// svdwT returns 3Z² + 4A mod P.
+func (c *Curve) svdwT(z *big.Int) *big.Int {
+	t := new(big.Int).Mul(z, z)
+	t.Mul(t, big.NewInt(3))
+	t.Add(t, new(big.Int).Lsh(c.A, 2))
+	return t.Mod(t, c.P)
+}
+
+// findZSVDW returns the Shallue–van de Woestijne constant Z chosen by the
+// search of RFC 9380 appendix H.1: the first of 1, -1, 2, -2, … with
+// g(Z) ≠ 0, -(3Z² + 4A) / (4·g(Z)) a nonzero square, and g(Z) or g(-Z/2)
+// a square.
+func (c *Curve) findZSVDW() *big.Int {
+	P := c.P
+	half := c.inv0(big.NewInt(2))
+	for ctr := int64(1); ; ctr++ {
+		for _, z := range []*big.Int{big.NewInt(ctr), big.NewInt(-ctr)} {
+			z.Mod(z, P)
+			gz := c.g(z)
+			if gz.Sign() == 0 {
+				continue
+			}
+			h := new(big.Int).Lsh(gz, 2)
+			h.Mul(c.svdwT(z), c.inv0(h))
+			h.Neg(h).Mod(h, P)
+			if h.Sign() == 0 || !isSquare(h, P) {
+				continue
+			}
+			mz2 := new(big.Int).Neg(z)
+			mz2.Mul(mz2, half).Mod(mz2, P)
+			if isSquare(gz, P) || isSquare(c.g(mz2), P) {
+				return z
+			}
+		}
+	}
+}
+
+// inv0 returns x⁻¹ mod P, or 0 for x ≡ 0.
+func (c *Curve) inv0(x *big.Int) *big.Int {
+	r := new(big.Int).Mod(x, c.P)
+	if r.Sign() == 0 {
+		return r
+	}
+	return r.ModInverse(r, c.P)
+}
+
+// isSquare reports whether x is a square mod the odd prime p, zero
+// included.
+func isSquare(x, p *big.Int) bool {
+	return big.Jacobi(new(big.Int).Mod(x, p), p) >= 0
+}
+
+// sgn0 is the sign of RFC 9380 section 4.1 for a prime field: the parity
+// of x in [0, P).
+func sgn0(x *big.Int) uint { return x.Bit(0) }
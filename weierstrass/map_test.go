@@ -0,0 +1,118 @@
+package weierstrass
+
+import (
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestHashToCurve(t *testing.T) {
+	t.Parallel()
+
+	p256, g1 := P256(), BLS12381G1()
+	// RFC 9380 appendix J.1.1, P256_XMD:SHA-256_SSWU_RO_
+	p256DST := []byte("QUUX-V01-CS02-with-P256_XMD:SHA-256_SSWU_RO_")
+	// RFC 9380 appendix J.9.1, BLS12381G1_XMD:SHA-256_SSWU_RO_
+	g1DST := []byte("QUUX-V01-CS02-with-BLS12381G1_XMD:SHA-256_SSWU_RO_")
+	tests := []struct {
+		c      *Curve
+		dst    []byte
+		msg    string
+		wx, wy string
+	}{
+		{p256, p256DST, "", "2c15230b26dbc6fc9a37051158c95b79656e17a1a920b11394ca91c44247d3e4", "8a7a74985cc5c776cdfe4b1f19884970453912e9d31528c060be9ab5c43e8415"},
+		{p256, p256DST, "abc", "0bb8b87485551aa43ed54f009230450b492fead5f1cc91658775dac4a3388a0f", "5c41b3d0731a27a7b14bc0bf0ccded2d8751f83493404c84a88e71ffd424212e"},
+		{g1, g1DST, "", "052926add2207b76ca4fa57a8734416c8dc95e24501772c814278700eed6d1e4e8cf62d9c09db0fac349612b759e79a1", "08ba738453bfed09cb546dbb0783dbb3a5f1f566ed67bb6be0e8c67e2e81a4cc68ee29813bb7994998f3eae0c9c6a265"},
+		{g1, g1DST, "abc", "03567bc5ef9c690c2ab2ecdf6a96ef1c139cc0b2f284dca0a9a7943388a49a3aee664ba5379a7655d3c68900be2f6903", "0b9c15f3fe6e5cf4211f346271d7b01c8f3b28be689c8429c85b67af215533311f0b8dfaaa154fa6b88176c229f2885d"},
+		{g1, g1DST, "abcdef0123456789", "11e0b079dea29a68f0383ee94fed1b940995272407e3bb916bbf268c263ddd57a6a27200a784cbc248e84f357ce82d98", "03a87ae2caf14e8ee52e51fa2ed8eefe80f02457004ba4d486d6aa1f517c0889501dc7413753f9599b099ebcbbd2d709"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.c.Name+"/"+tc.msg, func(t *testing.T) {
+			t.Parallel()
+			p, err := tc.c.HashToCurve([]byte(tc.msg), tc.dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			x, y := p.Affine()
+			if x.Cmp(hexInt(tc.wx)) != 0 || y.Cmp(hexInt(tc.wy)) != 0 {
+				t.Errorf("HashToCurve(%q) = (%x, %x), want (%s, %s)", tc.msg, x, y, tc.wx, tc.wy)
+			}
+		})
+	}
+}
+
+func TestHashToCurve_subgroup(t *testing.T) {
+	t.Parallel()
+
+	c := BLS12381G1()
+	dst := []byte("QUUX-V01-CS02-with-BLS12381G1_XMD:SHA-256_SSWU_RO_")
+	for _, msg := range []string{"", "abc", "abcdef0123456789"} {
+		p, err := c.HashToCurve([]byte(msg), dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.IsIdentity() || !p.IsInSubgroup() {
+			t.Errorf("HashToCurve(%q) is not a non-identity subgroup element", msg)
+		}
+		q, err := c.EncodeToCurve([]byte(msg), dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !q.IsInSubgroup() {
+			t.Errorf("EncodeToCurve(%q) is not in the subgroup", msg)
+		}
+	}
+}
+
+func TestMapToCurve(t *testing.T) {
+	t.Parallel()
+
+	// BLS12-381 G1 without its isogeny falls back to the SvdW map, with Z
+	// from the search of RFC 9380 appendix H.1
+	svdw := BLS12381G1()
+	svdw.Name, svdw.iso = "BLS12-381 G1 SvdW", nil
+	svdw.Z = svdw.findZSVDW()
+	g1 := BLS12381G1()
+	for _, c := range []*Curve{P256(), g1, g1.iso.src, svdw} {
+		t.Run(c.Name, func(t *testing.T) {
+			t.Parallel()
+
+			// u = 0 makes the SSWU denominator Z²u⁴ + Zu² vanish and must
+			// still land on the curve, through the inv0 convention.
+			special := []*big.Int{big.NewInt(0), big.NewInt(1), new(big.Int).Sub(c.P, big.NewInt(1))}
+			check := func(u *big.Int) bool {
+				p := c.MapToCurve(u)
+				x, y := p.Affine()
+				if _, err := c.NewPoint(x, y); err != nil {
+					return false
+				}
+				// The sign of y follows the sign of u, on the curve the map
+				// itself lands on
+				return c.iso != nil || sgn0(y) == sgn0(new(big.Int).Mod(u, c.P))
+			}
+			for _, u := range special {
+				if !check(u) {
+					t.Errorf("MapToCurve(%v) is not on the curve or has the wrong sign", u)
+				}
+			}
+			err := quick.Check(func(a, b uint64) bool {
+				u := new(big.Int).Lsh(new(big.Int).SetUint64(a), 64)
+				return check(u.Or(u, new(big.Int).SetUint64(b)))
+			}, &quick.Config{MaxCount: 50})
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func BenchmarkHashToCurve(b *testing.B) {
+	for _, c := range []*Curve{P256(), BLS12381G1()} {
+		b.Run(c.Name, func(b *testing.B) {
+			msg, dst := []byte("message"), []byte("DST")
+			for b.Loop() {
+				c.HashToCurve(msg, dst)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package weierstrass
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	// ErrNotBLS12381 is returned by the pairing functions for G1 points of
+	// a curve other than BLS12-381.
+	ErrNotBLS12381 = errors.New("weierstrass: pairing needs points of BLS12-381 G1")
+	// ErrPairingLength is returned by PairingCheck for slices of different
+	// lengths.
+	ErrPairingLength = errors.New("weierstrass: PairingCheck needs as many G1 as G2 points")
+)
+
+// blsZ is the absolute value of the BLS12-381 curve parameter
+// z = -0xd201000000010000, which drives the Miller loop. Its low Hamming
+// weight (six set bits) is why the loop has only five addition steps.
+var blsZ = hexInt("d201000000010000")
+
+// finalExponent is (p⁴ - p² + 1) / N, the hard part of the final
+// exponentiation; the easy part (p⁶ - 1)(p² + 1) is done with conjugation
+// and Frobenius maps.
+var finalExponent = func() *big.Int {
+	p2 := new(big.Int).Mul(bls12381P, bls12381P)
+	e := new(big.Int).Mul(p2, p2)
+	e.Sub(e, p2).Add(e, big.NewInt(1))
+	return e.Div(e, bls12381N)
+}()
+
+// GT is an element of the order-N subgroup of Fp12* that pairings land in.
+// It is immutable.
+type GT struct{ v fp12 }
+
+// Equal reports whether a and b are the same element.
+func (a *GT) Equal(b *GT) bool { return a.v.equal(b.v) }
+
+// IsOne reports whether a is the identity of GT.
+func (a *GT) IsOne() bool { return a.v.equal(fp12One()) }
+
+// Mul returns a·b.
+func (a *GT) Mul(b *GT) *GT { return &GT{a.v.mul(b.v)} }
+
+// Exp returns a^k; a negative k raises the inverse, which in GT is the
+// conjugate.
+func (a *GT) Exp(k *big.Int) *GT {
+	if k.Sign() < 0 {
+		return &GT{a.v.conj().exp(new(big.Int).Neg(k))}
+	}
+	return &GT{a.v.exp(k)}
+}
+
+// Pair computes the optimal ate pairing e(p, q) of BLS12-381: the Miller
+// loop f_{z,q}(p) followed by the final exponentiation to the power
+// (p¹² - 1) / N. It is bilinear, e(a·p, b·q) = e(p, q)^(ab), and
+// nondegenerate on G1 × G2. Either argument being the identity gives 1.
+//
+// p and q are not checked for subgroup membership; callers handling
+// untrusted points must do that first, as Verify does.
+func Pair(p *Point, q *G2Point) (*GT, error) {
+	f, err := millerLoop(p, q)
+	if err != nil {
+		return nil, err
+	}
+	return &GT{finalExponentiation(f)}, nil
+}
+
+// PairingCheck reports whether e(p₀, q₀)·e(p₁, q₁)·… = 1. The Miller loops
+// are multiplied together and share a single final exponentiation, which
+// dominates the cost of a pairing, so checking an equation of k pairings
+// costs far less than k calls to Pair.
+func PairingCheck(ps []*Point, qs []*G2Point) (bool, error) {
+	if len(ps) != len(qs) {
+		return false, ErrPairingLength
+	}
+	f := fp12One()
+	for i := range ps {
+		fi, err := millerLoop(ps[i], qs[i])
+		if err != nil {
+			return false, err
+		}
+		f = f.mul(fi)
+	}
+	return finalExponentiation(f).equal(fp12One()), nil
+}
+
+// millerLoop returns f_{z,q}(p) up to factors in proper subfields of Fp12,
+// which the final exponentiation maps to 1.
+//
+// The loop runs on the twist: T walks through multiples of q in E'(Fp2)
+// and only the line functions are lifted to Fp12. With the untwisting map
+// (x, y) ↦ (x·w⁻², y·w⁻³) and slope λ on E', the line through T evaluated
+// at p = (xP, yP) is yP - λ·xP·w⁻¹ + (λ·xT - yT)·w⁻³; scaled by w³ it is
+//
+//	(λ·xT - yT) - λ·xP·v + yP·v·w
+//
+// Vertical lines are dropped altogether, the usual denominator
+// elimination for even embedding degree.
+func millerLoop(p *Point, q *G2Point) (fp12, error) {
+	if p.c.P.Cmp(bls12381P) != 0 || p.c.A.Sign() != 0 || p.c.B.Cmp(big.NewInt(4)) != 0 {
+		return fp12{}, ErrNotBLS12381
+	}
+	if p.inf || q.inf {
+		return fp12One(), nil
+	}
+
+	f := fp12One()
+	t := q
+	for i := blsZ.BitLen() - 2; i >= 0; i-- {
+		lambda := t.tangentSlope()
+		f = f.square().mul(lineEval(t, lambda, p))
+		t = t.addWithSlope(t, lambda)
+		if blsZ.Bit(i) == 1 {
+			lambda = t.chordSlope(q)
+			f = f.mul(lineEval(t, lambda, p))
+			t = t.addWithSlope(q, lambda)
+		}
+	}
+	// z < 0: f_{-|z|} is the inverse of f_{|z|} up to a vertical line, and
+	// conjugation is the inverse once the final exponentiation is applied.
+	return f.conj(), nil
+}
+
+// lineEval returns the line through t with slope λ, evaluated at p and
+// scaled by w³ as described at millerLoop.
+func lineEval(t *G2Point, lambda fp2, p *Point) fp12 {
+	return fp12{
+		fp6{lambda.mul(t.x).sub(t.y), lambda.mulFp(p.x).neg(), fp2Zero()},
+		fp6{fp2Zero(), fp2{new(big.Int).Set(p.y), new(big.Int)}, fp2Zero()},
+	}
+}
+
+// finalExponentiation raises f to (p¹² - 1) / N, split as
+// (p⁶ - 1)·(p² + 1)·(p⁴ - p² + 1)/N. The easy part costs one inversion
+// and a few Frobenius maps; the hard part is a plain square-and-multiply
+// over finalExponent rather than the faster z-based addition chain.
+func finalExponentiation(f fp12) fp12 {
+	f = f.conj().mul(f.inverse())        // f^(p⁶ - 1)
+	f = f.frobenius().frobenius().mul(f) // f^(p² + 1)
+	return f.exp(finalExponent)
+}
+
+// PublicKey returns the BLS public key sk·g2 in G2 for a secret key sk.
+func PublicKey(sk *big.Int) *G2Point {
+	return G2Generator().ScalarMult(sk)
+}
+
+// Verify reports whether sig is a BLS signature of msg under pk, for the
+// scheme of Sign on BLS12-381 G1 with the same dst: that sig and pk are
+// non-identity elements of G1 and G2 and that e(sig, g2) = e(H(msg), pk),
+// checked as e(sig, -g2)·e(H(msg), pk) = 1 with one final exponentiation.
+func (c *Curve) Verify(pk *G2Point, msg, dst []byte, sig *Point) bool {
+	if sig.inf || pk.inf || !sig.IsInSubgroup() || !pk.IsInSubgroup() {
+		return false
+	}
+	h, err := c.HashToCurve(msg, dst)
+	if err != nil {
+		return false
+	}
+	ok, err := PairingCheck([]*Point{sig, h}, []*G2Point{G2Generator().Neg(), pk})
+	return err == nil && ok
+}
@@ -0,0 +1,190 @@
+package weierstrass
+
+import "math/big"
+
+// The extension tower of BLS12-381 used by the pairing:
+//
+//	Fp2  = Fp[u]  / (u² + 1)
+//	Fp6  = Fp2[v] / (v³ - ξ),  ξ = u + 1
+//	Fp12 = Fp6[w] / (w² - v)
+//
+// so w⁶ = ξ. Every element is kept reduced into [0, P) coefficient by
+// coefficient, which makes equality a plain comparison. As with the curve
+// arithmetic, each operation allocates and nothing is constant time.
+
+// bls12381P is the base field prime of BLS12-381.
+var bls12381P = hexInt("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab")
+
+func fpReduce(x *big.Int) *big.Int { return x.Mod(x, bls12381P) }
+
+// fp2 is c0 + c1·u.
+type fp2 struct{ c0, c1 *big.Int }
+
+func fp2Zero() fp2 { return fp2{new(big.Int), new(big.Int)} }
+func fp2One() fp2  { return fp2{big.NewInt(1), new(big.Int)} }
+
+func newFp2(c0, c1 *big.Int) fp2 {
+	return fp2{fpReduce(new(big.Int).Set(c0)), fpReduce(new(big.Int).Set(c1))}
+}
+
+func (a fp2) isZero() bool     { return a.c0.Sign() == 0 && a.c1.Sign() == 0 }
+func (a fp2) equal(b fp2) bool { return a.c0.Cmp(b.c0) == 0 && a.c1.Cmp(b.c1) == 0 }
+
+func (a fp2) add(b fp2) fp2 {
+	return fp2{fpReduce(new(big.Int).Add(a.c0, b.c0)), fpReduce(new(big.Int).Add(a.c1, b.c1))}
+}
+
+func (a fp2) sub(b fp2) fp2 {
+	return fp2{fpReduce(new(big.Int).Sub(a.c0, b.c0)), fpReduce(new(big.Int).Sub(a.c1, b.c1))}
+}
+
+func (a fp2) neg() fp2 {
+	return fp2{fpReduce(new(big.Int).Neg(a.c0)), fpReduce(new(big.Int).Neg(a.c1))}
+}
+
+// mul uses Karatsuba: three base-field products instead of four.
+func (a fp2) mul(b fp2) fp2 {
+	t0 := new(big.Int).Mul(a.c0, b.c0)
+	t1 := new(big.Int).Mul(a.c1, b.c1)
+	t2 := new(big.Int).Add(a.c0, a.c1)
+	t2.Mul(t2, new(big.Int).Add(b.c0, b.c1))
+	t2.Sub(t2, t0).Sub(t2, t1)
+	return fp2{fpReduce(t0.Sub(t0, t1)), fpReduce(t2)}
+}
+
+func (a fp2) square() fp2 { return a.mul(a) }
+
+// mulFp multiplies by an element of the base field.
+func (a fp2) mulFp(k *big.Int) fp2 {
+	return fp2{fpReduce(new(big.Int).Mul(a.c0, k)), fpReduce(new(big.Int).Mul(a.c1, k))}
+}
+
+// mulXi multiplies by ξ = u + 1: (c0 - c1) + (c0 + c1)·u.
+func (a fp2) mulXi() fp2 {
+	return fp2{fpReduce(new(big.Int).Sub(a.c0, a.c1)), fpReduce(new(big.Int).Add(a.c0, a.c1))}
+}
+
+// conj returns c0 - c1·u, which is also the Frobenius map a^p because
+// P ≡ 3 mod 4 makes u^p = -u.
+func (a fp2) conj() fp2 { return fp2{a.c0, fpReduce(new(big.Int).Neg(a.c1))} }
+
+// inverse returns a⁻¹ = conj(a) / (c0² + c1²), or 0 for a = 0.
+func (a fp2) inverse() fp2 {
+	n := new(big.Int).Mul(a.c0, a.c0)
+	n.Add(n, new(big.Int).Mul(a.c1, a.c1))
+	if fpReduce(n).Sign() == 0 {
+		return fp2Zero()
+	}
+	n.ModInverse(n, bls12381P)
+	return a.conj().mulFp(n)
+}
+
+func (a fp2) exp(k *big.Int) fp2 {
+	r := fp2One()
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		r = r.square()
+		if k.Bit(i) == 1 {
+			r = r.mul(a)
+		}
+	}
+	return r
+}
+
+// fp6 is c0 + c1·v + c2·v².
+type fp6 struct{ c0, c1, c2 fp2 }
+
+func fp6Zero() fp6 { return fp6{fp2Zero(), fp2Zero(), fp2Zero()} }
+func fp6One() fp6  { return fp6{fp2One(), fp2Zero(), fp2Zero()} }
+
+func (a fp6) isZero() bool { return a.c0.isZero() && a.c1.isZero() && a.c2.isZero() }
+func (a fp6) equal(b fp6) bool {
+	return a.c0.equal(b.c0) && a.c1.equal(b.c1) && a.c2.equal(b.c2)
+}
+
+func (a fp6) add(b fp6) fp6 { return fp6{a.c0.add(b.c0), a.c1.add(b.c1), a.c2.add(b.c2)} }
+func (a fp6) sub(b fp6) fp6 { return fp6{a.c0.sub(b.c0), a.c1.sub(b.c1), a.c2.sub(b.c2)} }
+func (a fp6) neg() fp6      { return fp6{a.c0.neg(), a.c1.neg(), a.c2.neg()} }
+
+// mul uses the three-way Karatsuba formulas: six Fp2 products instead of
+// nine, with v³ = ξ folding the high terms back down.
+func (a fp6) mul(b fp6) fp6 {
+	t0, t1, t2 := a.c0.mul(b.c0), a.c1.mul(b.c1), a.c2.mul(b.c2)
+	c0 := a.c1.add(a.c2).mul(b.c1.add(b.c2)).sub(t1).sub(t2).mulXi().add(t0)
+	c1 := a.c0.add(a.c1).mul(b.c0.add(b.c1)).sub(t0).sub(t1).add(t2.mulXi())
+	c2 := a.c0.add(a.c2).mul(b.c0.add(b.c2)).sub(t0).sub(t2).add(t1)
+	return fp6{c0, c1, c2}
+}
+
+// mulV multiplies by v: (c0, c1, c2) → (ξ·c2, c0, c1).
+func (a fp6) mulV() fp6 { return fp6{a.c2.mulXi(), a.c0, a.c1} }
+
+func (a fp6) inverse() fp6 {
+	t0 := a.c0.square().sub(a.c1.mul(a.c2).mulXi())
+	t1 := a.c2.square().mulXi().sub(a.c0.mul(a.c1))
+	t2 := a.c1.square().sub(a.c0.mul(a.c2))
+	n := a.c0.mul(t0).add(a.c2.mul(t1).add(a.c1.mul(t2)).mulXi())
+	ni := n.inverse()
+	return fp6{t0.mul(ni), t1.mul(ni), t2.mul(ni)}
+}
+
+// fp12 is c0 + c1·w.
+type fp12 struct{ c0, c1 fp6 }
+
+func fp12One() fp12 { return fp12{fp6One(), fp6Zero()} }
+
+func (a fp12) equal(b fp12) bool { return a.c0.equal(b.c0) && a.c1.equal(b.c1) }
+
+func (a fp12) mul(b fp12) fp12 {
+	t0, t1 := a.c0.mul(b.c0), a.c1.mul(b.c1)
+	c1 := a.c0.add(a.c1).mul(b.c0.add(b.c1)).sub(t0).sub(t1)
+	return fp12{t0.add(t1.mulV()), c1}
+}
+
+func (a fp12) square() fp12 { return a.mul(a) }
+
+// conj returns c0 - c1·w, which is a^(p⁶): the Frobenius map applied six
+// times. On the cyclotomic subgroup, where pairing values live after the
+// easy part of the final exponentiation, it is also the inverse.
+func (a fp12) conj() fp12 { return fp12{a.c0, a.c1.neg()} }
+
+// inverse returns (c0 - c1·w) / (c0² - v·c1²).
+func (a fp12) inverse() fp12 {
+	t := a.c0.mul(a.c0).sub(a.c1.mul(a.c1).mulV()).inverse()
+	return fp12{a.c0.mul(t), a.c1.mul(t).neg()}
+}
+
+// frobeniusGamma holds ξ^(i(p-1)/6) for i = 0..5. Writing a = Σ aᵢ·wⁱ with
+// aᵢ ∈ Fp2, a^p = Σ conj(aᵢ)·wⁱ·w^(i(p-1)) and w^(i(p-1)) = ξ^(i(p-1)/6),
+// which lies in Fp2 because P ≡ 1 mod 6.
+var frobeniusGamma = func() [6]fp2 {
+	e := new(big.Int).Sub(bls12381P, big.NewInt(1))
+	e.Div(e, big.NewInt(6))
+	g1 := fp2One().mulXi().exp(e)
+	var g [6]fp2
+	g[0] = fp2One()
+	for i := 1; i < 6; i++ {
+		g[i] = g[i-1].mul(g1)
+	}
+	return g
+}()
+
+// frobenius returns a^p. In w-powers, c0 holds the coefficients of w⁰, w²,
+// w⁴ and c1 those of w¹, w³, w⁵.
+func (a fp12) frobenius() fp12 {
+	g := &frobeniusGamma
+	return fp12{
+		fp6{a.c0.c0.conj(), a.c0.c1.conj().mul(g[2]), a.c0.c2.conj().mul(g[4])},
+		fp6{a.c1.c0.conj().mul(g[1]), a.c1.c1.conj().mul(g[3]), a.c1.c2.conj().mul(g[5])},
+	}
+}
+
+func (a fp12) exp(k *big.Int) fp12 {
+	r := fp12One()
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		r = r.square()
+		if k.Bit(i) == 1 {
+			r = r.mul(a)
+		}
+	}
+	return r
+}